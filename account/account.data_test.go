@@ -7,44 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var columns = []string{
-	"id",               //1
-	"uuid",             //2
-	"name",             //3
-	"alternate_name",   //4
-	"address",          //5
-	"name_from_source", //6
-	"portfolio_id",     //7
-	"source_id",        //8
-	"account_type_id",  //9
-	"description",      //10
-	"created_by",       //11
-	"created_at",       //12
-	"updated_by",       //13
-	"updated_at",       //14
-	"chain_id",         //15
-}
-
-var DBColumnsInsertAccounts = []string{
-	"uuid",             //1
-	"name",             //2
-	"alternate_name",   //3
-	"address",          //4
-	"name_from_source", //5
-	"portfolio_id",     //6
-	"source_id",        //7
-	"account_type_id",  //8
-	"description",      //9
-	"created_by",       //10
-	"created_at",       //11
-	"updated_by",       //12
-	"updated_at",       //13
-	"chain_id",         //14
-}
+var columns = AccountRepository.Columns()
+
+var DBColumnsInsertAccounts = AccountRepository.InsertColumns()
 
 var TestData1 = Account{
 	ID:             utils.Ptr[int](1),
@@ -85,27 +56,7 @@ var TestData2 = Account{
 var TestAllData = []Account{TestData1, TestData2}
 
 func AddAccountToMockRows(mock pgxmock.PgxPoolIface, dataList []Account) *pgxmock.Rows {
-	rows := mock.NewRows(columns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,
-			data.UUID,
-			data.Name,
-			data.AlternateName,
-			data.Address,
-			data.NameFromSource,
-			data.PortfolioID,
-			data.SourceID,
-			data.AccountTypeID,
-			data.Description,
-			data.CreatedBy,
-			data.CreatedAt,
-			data.UpdatedBy,
-			data.UpdatedAt,
-			data.ChainID,
-		)
-	}
-	return rows
+	return AccountRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetAccount(t *testing.T) {
@@ -141,8 +92,8 @@ func TestGetAccountForErrNoRows(t *testing.T) {
 	accountID := utils.Ptr[int](1)
 	mock.ExpectQuery("^SELECT (.+) FROM accounts WHERE id = ?").WithArgs(*accountID).WillReturnRows(noRows)
 	foundAccount, err := GetAccount(mock, accountID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAccount", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAccount != nil {
 		t.Errorf("Expected Account From Method GetAccount: to be empty but got this: %v", foundAccount)
@@ -226,8 +177,8 @@ func TestGetAccountByAddressForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM accounts WHERE address = ?").WithArgs(testAddress).WillReturnRows(noRows)
 	foundAccount, err := GetAccountByAddress(mock, testAddress)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAccountByAddress", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAccount != nil {
 		t.Errorf("Expected Account From Method GetAccountByAddress: to be empty but got this: %v", foundAccount)
@@ -310,8 +261,8 @@ func TestGetAccountByAlternateNameForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM accounts WHERE alternate_name = ?").WithArgs(testAlternateName).WillReturnRows(noRows)
 	foundAccount, err := GetAccountByAlternateName(mock, testAlternateName)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAccountByAlternateName", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAccount != nil {
 		t.Errorf("Expected Account From Method GetAccountByAlternateName: to be empty but got this: %v", foundAccount)
@@ -426,8 +377,8 @@ func TestGetAccountList(t *testing.T) {
 	defer mock.Close()
 	dataList := []Account{TestData1, TestData2}
 	mockRows := AddAccountToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM accounts").WillReturnRows(mockRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM accounts").WithArgs(ids).WillReturnRows(mockRows)
 	foundAccounts, err := GetAccountList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAccount", err)
@@ -449,8 +400,8 @@ func TestGetAccountListForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM accounts").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := make([]int, 0)
+	mock.ExpectQuery("^SELECT (.+) FROM accounts").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAccounts, err := GetAccountList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAccountList", err)
@@ -471,7 +422,7 @@ func TestGetAccountListForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	ids := []int{1, 2}
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM accounts").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM accounts").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundAccounts, err := GetAccountList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAccountList", err)
@@ -493,17 +444,17 @@ func TestUpdateAccount(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE accounts").WithArgs(
-		targetData.Name,
-		targetData.AlternateName,
-		targetData.Address,
-		targetData.NameFromSource,
-		targetData.PortfolioID,
-		targetData.SourceID,
-		targetData.AccountTypeID,
-		targetData.Description,
-		targetData.UpdatedBy,
-		targetData.ChainID,
-		targetData.ID,
+		targetData.Name,           //1
+		targetData.AlternateName,  //2
+		targetData.Address,        //3
+		targetData.NameFromSource, //4
+		targetData.PortfolioID,    //5
+		targetData.SourceID,       //6
+		targetData.AccountTypeID,  //7
+		targetData.Description,    //8
+		targetData.UpdatedBy,      //9
+		targetData.ChainID,        //10
+		targetData.ID,             //11
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateAccount(mock, &targetData)
@@ -560,17 +511,17 @@ func TestUpdateAccountOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE accounts").WithArgs(
-		targetData.Name,
-		targetData.AlternateName,
-		targetData.Address,
-		targetData.NameFromSource,
-		targetData.PortfolioID,
-		targetData.SourceID,
-		targetData.AccountTypeID,
-		targetData.Description,
-		targetData.UpdatedBy,
-		targetData.ChainID,
-		targetData.ID,
+		targetData.Name,           //1
+		targetData.AlternateName,  //2
+		targetData.Address,        //3
+		targetData.NameFromSource, //4
+		targetData.PortfolioID,    //5
+		targetData.SourceID,       //6
+		targetData.AccountTypeID,  //7
+		targetData.Description,    //8
+		targetData.UpdatedBy,      //9
+		targetData.ChainID,        //10
+		targetData.ID,             //11
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -593,16 +544,16 @@ func TestInsertAccount(t *testing.T) {
 	targetData.Name = "New Name"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO accounts").WithArgs(
-		targetData.Name,
-		targetData.AlternateName,
-		targetData.Address,
-		targetData.NameFromSource,
-		targetData.PortfolioID,
-		targetData.SourceID,
-		targetData.AccountTypeID,
-		targetData.Description,
-		targetData.CreatedBy,
-		targetData.ChainID,
+		targetData.Name,           //1
+		targetData.AlternateName,  //2
+		targetData.Address,        //3
+		targetData.NameFromSource, //4
+		targetData.PortfolioID,    //5
+		targetData.SourceID,       //6
+		targetData.AccountTypeID,  //7
+		targetData.Description,    //8
+		targetData.CreatedBy,      //9
+		targetData.ChainID,        //10
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 	newID, err := InsertAccount(mock, &targetData)
@@ -647,16 +598,16 @@ func TestInsertAccountOnFailure(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO accounts").WithArgs(
-		targetData.Name,
-		targetData.AlternateName,
-		targetData.Address,
-		targetData.NameFromSource,
-		targetData.PortfolioID,
-		targetData.SourceID,
-		targetData.AccountTypeID,
-		targetData.Description,
-		targetData.CreatedBy,
-		targetData.ChainID,
+		targetData.Name,           //1
+		targetData.AlternateName,  //2
+		targetData.Address,        //3
+		targetData.NameFromSource, //4
+		targetData.PortfolioID,    //5
+		targetData.SourceID,       //6
+		targetData.AccountTypeID,  //7
+		targetData.Description,    //8
+		targetData.CreatedBy,      //9
+		targetData.ChainID,        //10
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	newID, err := InsertAccount(mock, &targetData)
@@ -682,16 +633,16 @@ func TestInsertAccountOnFailureOnCommit(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO accounts").WithArgs(
-		targetData.Name,
-		targetData.AlternateName,
-		targetData.Address,
-		targetData.NameFromSource,
-		targetData.PortfolioID,
-		targetData.SourceID,
-		targetData.AccountTypeID,
-		targetData.Description,
-		targetData.CreatedBy,
-		targetData.ChainID,
+		targetData.Name,           //1
+		targetData.AlternateName,  //2
+		targetData.Address,        //3
+		targetData.NameFromSource, //4
+		targetData.PortfolioID,    //5
+		targetData.SourceID,       //6
+		targetData.AccountTypeID,  //7
+		targetData.Description,    //8
+		targetData.CreatedBy,      //9
+		targetData.ChainID,        //10
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -753,8 +704,8 @@ func TestGetAccountListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"account_type_id = 1", "chain_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM accounts").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("account_type_id", "1"), filter.Equal("chain_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM accounts").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundAccountList, err := GetAccountListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAccountListByPagination", err)
@@ -779,8 +730,8 @@ func TestGetAccountListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"account_type_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM accounts").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("account_type_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM accounts").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAccountList, err := GetAccountListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAccountListByPagination", err)
@@ -803,9 +754,9 @@ func TestGetAccountListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"account_type_id = 1"}
+	filters := filter.All(filter.Equal("account_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM accounts").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM accounts").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundAccountList, err := GetAccountListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAccountListByPagination", err)
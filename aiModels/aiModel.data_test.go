@@ -7,43 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",              //1
-	"uuid",            //2
-	"name",            //3
-	"alternate_name",  //4
-	"url",             //5
-	"ticker",          //6
-	"description",     //7
-	"ollama_name",     //8
-	"params_size",     //9
-	"quantiized_size", //10
-	"base_model_id",   //11
-	"created_by",      //12
-	"created_at",      //13
-	"updated_by",      //14
-	"updated_at",      //15
-}
-var DBColumnsInsertAIModels = []string{
-	"uuid",            //1
-	"name",            //2
-	"alternate_name",  //3
-	"url",             //4
-	"ticker",          //5
-	"description",     //6
-	"ollama_name",     //7
-	"params_size",     //8
-	"quantiized_size", //9
-	"base_model_id",   //10
-	"created_by",      //11
-	"created_at",      //12
-	"updated_by",      //13
-	"updated_at",      //14
-}
+var DBColumns = AIModelRepository.Columns()
+var DBColumnsInsertAIModels = AIModelRepository.InsertColumns()
 
 var TestData1 = AIModel{
 	ID:            utils.Ptr[int](1),                      //1
@@ -84,27 +55,7 @@ var TestData2 = AIModel{
 var TestAllData = []AIModel{TestData1, TestData2}
 
 func AddAIModelToMockRows(mock pgxmock.PgxPoolIface, dataList []AIModel) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.UUID,          //2
-			data.Name,          //3
-			data.AlternateName, //4
-			data.URL,           //5
-			data.Ticker,        //6
-			data.Description,   //7
-			data.OllamaName,    //8
-			data.ParamsSize,    //9
-			data.QuantizedSize, //10
-			data.BaseModelID,   //11
-			data.CreatedBy,     //12
-			data.CreatedAt,     //13
-			data.UpdatedBy,     //14
-			data.UpdatedAt,     //15
-		)
-	}
-	return rows
+	return AIModelRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetAIModel(t *testing.T) {
@@ -140,8 +91,8 @@ func TestGetAIModelForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WithArgs(aiModelID).WillReturnRows(noRows)
 	foundAIModel, err := GetAIModel(mock, &aiModelID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAIModel", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAIModel != nil {
 		t.Errorf("Expected AIModel From Method GetAIModel: to be empty but got this: %v", foundAIModel)
@@ -257,7 +208,7 @@ func TestGetAIModelList(t *testing.T) {
 	dataList := TestAllData
 	ids := []int{1, 2}
 	mockRows := AddAIModelToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WithArgs(ids).WillReturnRows(mockRows)
 	foundAIModelList, err := GetAIModelList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAIModelList", err)
@@ -279,7 +230,7 @@ func TestGetAIModelListForErr(t *testing.T) {
 	}
 	defer mock.Close()
 	ids := []int{1, 2}
-	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAIModelList, err := GetAIModelList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAIModelList", err)
@@ -300,7 +251,7 @@ func TestGetAIModelListForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	ids := []int{1, 2}
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundAIModel, err := GetAIModelList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAIModelList", err)
@@ -430,7 +381,6 @@ func TestInsertAIModel(t *testing.T) {
 		targetData.QuantizedSize, //8
 		targetData.BaseModelID,   //9
 		targetData.CreatedBy,     //10
-		targetData.CreatedBy,     //11
 	).WillReturnRows(pgxmock.NewRows([]string{"ai_model_id"}).AddRow(1))
 	mock.ExpectCommit()
 	aiModelID, err := InsertAIModel(mock, &targetData)
@@ -483,7 +433,6 @@ func TestInsertAIModelOnFailure(t *testing.T) {
 		targetData.QuantizedSize, //8
 		targetData.BaseModelID,   //9
 		targetData.CreatedBy,     //10
-		targetData.CreatedBy,     //11
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	aiModelID, err := InsertAIModel(mock, &targetData)
@@ -517,7 +466,6 @@ func TestInsertAIModelOnFailureOnCommit(t *testing.T) {
 		targetData.QuantizedSize, //8
 		targetData.BaseModelID,   //9
 		targetData.CreatedBy,     //10
-		targetData.CreatedBy,     //11
 	).WillReturnRows(pgxmock.NewRows([]string{"ai_model_id"}).AddRow(-1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -579,8 +527,8 @@ func TestGetAIModelListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"ticker = 'COIN'", "name = 'coingecko'"}
-	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("ticker", "COIN"), filter.Equal("name", "coingecko"))
+	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WithArgs("COIN", "coingecko", _start, _end-_start).WillReturnRows(mockRows)
 	foundAIModelList, err := GetAIModelListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAIModelListByPagination", err)
@@ -605,8 +553,8 @@ func TestGetAIModelListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"ticker = 'COIN'", "name = 'coingecko'"}
-	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("ticker", "COIN"), filter.Equal("name", "coingecko"))
+	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WithArgs("COIN", "coingecko").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAIModelList, err := GetAIModelListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAIModelListByPagination", err)
@@ -629,9 +577,9 @@ func TestGetAIModelListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"ticker = 'COIN'", "name = 'coingecko'"}
+	filters := filter.All(filter.Equal("ticker", "COIN"), filter.Equal("name", "coingecko"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM ai_models").WithArgs("COIN", "coingecko").WillReturnRows(differentModelRows)
 	foundAIModelList, err := GetAIModelListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAIModelListByPagination", err)
@@ -0,0 +1,169 @@
+package asset
+
+import (
+	"context"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/cache"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// AssetCache is a read-through cache in front of GetAsset,
+// GetAssetByContractAddress and GetAssetByTicker. Its methods have the same
+// signatures as the functions they wrap. Lookups that fail are not cached,
+// and the Update, Insert, Remove and Restore methods invalidate the asset they
+// change. Writes made around the cache are only seen once the TTL expires, or
+// after Invalidate.
+type AssetCache struct {
+	byID       *cache.LRU[int, Asset]
+	byContract *cache.LRU[string, Asset]
+	byTicker   *cache.LRU[string, Asset]
+}
+
+// AssetCacheStats are the stats of each lookup of an AssetCache.
+type AssetCacheStats struct {
+	ByID       cache.Stats
+	ByContract cache.Stats
+	ByTicker   cache.Stats
+}
+
+// NewAssetCache caches up to size assets per lookup for ttl.
+func NewAssetCache(size int, ttl time.Duration) *AssetCache {
+	return &AssetCache{
+		byID:       cache.NewLRU[int, Asset](size, ttl),
+		byContract: cache.NewLRU[string, Asset](size, ttl),
+		byTicker:   cache.NewLRU[string, Asset](size, ttl),
+	}
+}
+
+func (c *AssetCache) GetAsset(dbConnPgx utils.PgxIface, assetID *int) (*Asset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.GetAssetCtx(ctx, dbConnPgx, assetID)
+}
+
+func (c *AssetCache) GetAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int) (*Asset, error) {
+	return readThrough(c.byID, *assetID, func() (*Asset, error) {
+		return GetAssetCtx(ctx, dbConnPgx, assetID)
+	})
+}
+
+func (c *AssetCache) GetAssetByContractAddress(dbConnPgx utils.PgxIface, contractAddress string) (*Asset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.GetAssetByContractAddressCtx(ctx, dbConnPgx, contractAddress)
+}
+
+func (c *AssetCache) GetAssetByContractAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, contractAddress string) (*Asset, error) {
+	return readThrough(c.byContract, contractAddress, func() (*Asset, error) {
+		return GetAssetByContractAddressCtx(ctx, dbConnPgx, contractAddress)
+	})
+}
+
+func (c *AssetCache) GetAssetByTicker(dbConnPgx utils.PgxIface, ticker string) (*Asset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.GetAssetByTickerCtx(ctx, dbConnPgx, ticker)
+}
+
+func (c *AssetCache) GetAssetByTickerCtx(ctx context.Context, dbConnPgx utils.PgxIface, ticker string) (*Asset, error) {
+	return readThrough(c.byTicker, ticker, func() (*Asset, error) {
+		return GetAssetByTickerCtx(ctx, dbConnPgx, ticker)
+	})
+}
+
+func (c *AssetCache) UpdateAsset(dbConnPgx utils.PgxIface, asset *Asset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.UpdateAssetCtx(ctx, dbConnPgx, asset)
+}
+
+func (c *AssetCache) UpdateAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, asset *Asset) error {
+	defer c.Invalidate(asset.ID)
+	return UpdateAssetCtx(ctx, dbConnPgx, asset)
+}
+
+func (c *AssetCache) UpdateAssetIfUnchanged(dbConnPgx utils.PgxIface, asset *Asset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.UpdateAssetIfUnchangedCtx(ctx, dbConnPgx, asset)
+}
+
+func (c *AssetCache) UpdateAssetIfUnchangedCtx(ctx context.Context, dbConnPgx utils.PgxIface, asset *Asset) error {
+	defer c.Invalidate(asset.ID)
+	return UpdateAssetIfUnchangedCtx(ctx, dbConnPgx, asset)
+}
+
+func (c *AssetCache) InsertAsset(dbConnPgx utils.PgxIface, asset *Asset) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.InsertAssetCtx(ctx, dbConnPgx, asset)
+}
+
+// InsertAssetCtx also drops the contract address and ticker of asset, in case
+// they were cached for an asset that has since been removed.
+func (c *AssetCache) InsertAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, asset *Asset) (int, error) {
+	c.byContract.Delete(asset.ContractAddress)
+	c.byTicker.Delete(asset.Ticker)
+	return InsertAssetCtx(ctx, dbConnPgx, asset)
+}
+
+func (c *AssetCache) RemoveAsset(dbConnPgx utils.PgxIface, assetID *int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.RemoveAssetCtx(ctx, dbConnPgx, assetID)
+}
+
+func (c *AssetCache) RemoveAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int) error {
+	defer c.Invalidate(assetID)
+	return RemoveAssetCtx(ctx, dbConnPgx, assetID)
+}
+
+func (c *AssetCache) RestoreAsset(dbConnPgx utils.PgxIface, assetID *int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.RestoreAssetCtx(ctx, dbConnPgx, assetID)
+}
+
+func (c *AssetCache) RestoreAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int) error {
+	defer c.Invalidate(assetID)
+	return RestoreAssetCtx(ctx, dbConnPgx, assetID)
+}
+
+// Invalidate drops the asset with assetID from every lookup.
+func (c *AssetCache) Invalidate(assetID *int) {
+	if assetID == nil {
+		return
+	}
+	c.byID.Delete(*assetID)
+	sameID := func(_ string, a Asset) bool { return a.ID != nil && *a.ID == *assetID }
+	c.byContract.DeleteFunc(sameID)
+	c.byTicker.DeleteFunc(sameID)
+}
+
+// Purge drops every cached asset.
+func (c *AssetCache) Purge() {
+	c.byID.Purge()
+	c.byContract.Purge()
+	c.byTicker.Purge()
+}
+
+func (c *AssetCache) Stats() AssetCacheStats {
+	return AssetCacheStats{ByID: c.byID.Stats(), ByContract: c.byContract.Stats(), ByTicker: c.byTicker.Stats()}
+}
+
+// readThrough returns a copy of the cached asset for key, or else loads and
+// caches it. A load racing Invalidate is returned but not cached.
+func readThrough[K comparable](lru *cache.LRU[K, Asset], key K, load func() (*Asset, error)) (*Asset, error) {
+	asset, err := lru.GetOrLoad(key, func() (Asset, error) {
+		asset, err := load()
+		if err != nil {
+			return Asset{}, err
+		}
+		return *asset, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
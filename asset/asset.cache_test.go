@@ -0,0 +1,61 @@
+package asset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestAssetCacheGetAssetByContractAddress(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData2
+	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE contract_address = ?").WithArgs(targetData.ContractAddress).WillReturnRows(AddAssetToMockRows(mock, []Asset{targetData}))
+	c := NewAssetCache(10, time.Minute)
+	for i := 0; i < 3; i++ {
+		foundAsset, err := c.GetAssetByContractAddress(mock, targetData.ContractAddress)
+		if err != nil {
+			t.Fatalf("an error '%s' in GetAssetByContractAddress", err)
+		}
+		if cmp.Equal(*foundAsset, targetData) == false {
+			t.Errorf("Expected Asset From Method GetAssetByContractAddress: %v is different from actual %v", foundAsset, targetData)
+		}
+	}
+	if stats := c.Stats().ByContract; stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %+v", stats)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAssetCacheRemoveAssetInvalidates(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	c := NewAssetCache(10, time.Minute)
+	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE ticker = ?").WithArgs(targetData.Ticker).WillReturnRows(AddAssetToMockRows(mock, []Asset{targetData}))
+	if _, err := c.GetAssetByTicker(mock, targetData.Ticker); err != nil {
+		t.Fatalf("an error '%s' in GetAssetByTicker", err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE assets SET deleted_at").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	if err := c.RemoveAsset(mock, targetData.ID); err != nil {
+		t.Fatalf("an error '%s' in RemoveAsset", err)
+	}
+	if stats := c.Stats().ByTicker; stats.Size != 0 {
+		t.Errorf("expected the removed asset to be invalidated, got %+v", stats)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
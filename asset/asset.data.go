@@ -2,330 +2,124 @@ package asset
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
-	"time"
 
-	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 )
 
-func GetAsset(dbConnPgx utils.PgxIface, assetID *int) (*Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// AssetRepository implements the standard queries on the assets table. Only
+// the queries that join asset_sources or read the asset views are hand
+// written.
+var AssetRepository = repository.New[Asset]("assets", repository.SoftDelete())
+
+func GetAsset(dbConnPgx utils.PgxIface, assetID *int, opts ...repository.ReadOption) (*Asset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets 
-	WHERE id = $1`, *assetID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	// from https://stackoverflow.com/questions/61704842/how-to-scan-a-queryrow-into-a-struct-with-pgx
-	asset, err := pgx.CollectOneRow(row, pgx.RowToStructByName[Asset])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &asset, nil
+	return GetAssetCtx(ctx, dbConnPgx, assetID, opts...)
+}
+
+func GetAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int, opts ...repository.ReadOption) (*Asset, error) {
+	return AssetRepository.Get(ctx, dbConnPgx, *assetID, opts...)
 }
 
 // GetAssetByTicker : get asset by ticker
 func GetAssetByTicker(dbConnPgx utils.PgxIface, ticker string) (*Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets 
-	WHERE ticker = $1`, ticker)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAssetByTickerCtx(ctx, dbConnPgx, ticker)
+}
 
-	asset, err := pgx.CollectOneRow(row, pgx.RowToStructByName[Asset])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &asset, nil
+func GetAssetByTickerCtx(ctx context.Context, dbConnPgx utils.PgxIface, ticker string) (*Asset, error) {
+	return AssetRepository.SelectOne(ctx, dbConnPgx, `WHERE ticker = $1
+	AND deleted_at IS NULL`, ticker)
 }
 
 // GetAssetByContractAddress : get asset by contract address
 func GetAssetByContractAddress(dbConnPgx utils.PgxIface, contractAddress string) (*Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets 
-	WHERE contract_address = $1`, contractAddress)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAssetByContractAddressCtx(ctx, dbConnPgx, contractAddress)
+}
 
-	asset, err := pgx.CollectOneRow(row, pgx.RowToStructByName[Asset])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &asset, nil
+func GetAssetByContractAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, contractAddress string) (*Asset, error) {
+	return AssetRepository.SelectOne(ctx, dbConnPgx, `WHERE contract_address = $1
+	AND deleted_at IS NULL`, contractAddress)
 }
 
 // GetAssetByCusip : get asset by cusip
 func GetAssetByCusip(dbConnPgx utils.PgxIface, cusip string) (*Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets 
-	WHERE cusip = $1`, cusip)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAssetByCusipCtx(ctx, dbConnPgx, cusip)
+}
 
-	asset, err := pgx.CollectOneRow(row, pgx.RowToStructByName[Asset])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &asset, nil
+func GetAssetByCusipCtx(ctx context.Context, dbConnPgx utils.PgxIface, cusip string) (*Asset, error) {
+	return AssetRepository.SelectOne(ctx, dbConnPgx, `WHERE cusip = $1
+	AND deleted_at IS NULL`, cusip)
 }
 
 // GetAssetByBaseAndQuoteID : get asset by base and quote id
 func GetAssetByBaseAndQuoteID(dbConnPgx utils.PgxIface, baseAssetID *int, quoteAssetID *int) (*Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets 
-	WHERE base_asset_id = $1 
-	AND quote_asset_id = $2`,
-		*baseAssetID, *quoteAssetID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAssetByBaseAndQuoteIDCtx(ctx, dbConnPgx, baseAssetID, quoteAssetID)
+}
 
-	asset, err := pgx.CollectOneRow(row, pgx.RowToStructByName[Asset])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &asset, nil
+func GetAssetByBaseAndQuoteIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int, quoteAssetID *int) (*Asset, error) {
+	return AssetRepository.SelectOne(ctx, dbConnPgx, `WHERE base_asset_id = $1
+	AND quote_asset_id = $2
+	AND deleted_at IS NULL`, *baseAssetID, *quoteAssetID)
 }
 
 func GetGethImportAssets(dbConnPgx utils.PgxIface) ([]Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets 
-	WHERE import_geth = TRUE
-	`)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethImportAssetsCtx(ctx, dbConnPgx)
+}
 
-	assets, err := pgx.CollectRows(results, pgx.RowToStructByName[Asset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return assets, nil
+func GetGethImportAssetsCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]Asset, error) {
+	return AssetRepository.Select(ctx, dbConnPgx, `WHERE import_geth = TRUE
+	AND deleted_at IS NULL`)
 }
 
+// RemoveAsset marks the asset deleted, leaving the rows that reference it
+// intact; RestoreAsset undoes it.
 func RemoveAsset(dbConnPgx utils.PgxIface, assetID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveAsset DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM assets WHERE id = $1`
+	return RemoveAssetCtx(ctx, dbConnPgx, assetID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *assetID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int) error {
+	return AssetRepository.Remove(ctx, dbConnPgx, *assetID)
+}
+
+func RestoreAsset(dbConnPgx utils.PgxIface, assetID *int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return RestoreAssetCtx(ctx, dbConnPgx, assetID)
+}
+
+func RestoreAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int) error {
+	return AssetRepository.Restore(ctx, dbConnPgx, *assetID)
 }
 
 func GetCurrentTradingAssets(dbConnPgx utils.PgxIface) ([]Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetCurrentTradingAssetsCtx(ctx, dbConnPgx)
+}
+
+func GetCurrentTradingAssetsCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]Asset, error) {
 	results, err := dbConnPgx.Query(ctx, `SELECT 
 	id,
 	uuid, 
@@ -356,69 +150,37 @@ func GetCurrentTradingAssets(dbConnPgx utils.PgxIface) ([]Asset, error) {
 	total_supply
 	FROM public.get_current_assets`)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetCurrentTradingAssets", err, logging.Entity("get_current_assets"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	assets, err := pgx.CollectRows(results, pgx.RowToStructByName[Asset])
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		logging.ReturnedError(ctx, "GetCurrentTradingAssets", err, logging.Entity("get_current_assets"))
+		return nil, dberrors.Wrap(err)
 	}
 	return assets, nil
 
 }
 
 func GetCryptoAssets(dbConnPgx utils.PgxIface) ([]Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets
-	where asset_type_id = 1
-	`)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetCryptoAssetsCtx(ctx, dbConnPgx)
+}
 
-	assets, err := pgx.CollectRows(results, pgx.RowToStructByName[Asset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return assets, nil
+func GetCryptoAssetsCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]Asset, error) {
+	return AssetRepository.Select(ctx, dbConnPgx, `where asset_type_id = 1
+	AND deleted_at IS NULL`)
 }
 
 func GetAssetsByAssetTypeAndSource(dbConnPgx utils.PgxIface, assetTypeID *int, sourceID *int, excludeIgnoreMarketData bool) ([]AssetWithSources, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetAssetsByAssetTypeAndSourceCtx(ctx, dbConnPgx, assetTypeID, sourceID, excludeIgnoreMarketData)
+}
+
+func GetAssetsByAssetTypeAndSourceCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetTypeID *int, sourceID *int, excludeIgnoreMarketData bool) ([]AssetWithSources, error) {
 	sql := `SELECT 
 		assets.id,
 		assets.uuid, 
@@ -452,15 +214,17 @@ func GetAssetsByAssetTypeAndSource(dbConnPgx utils.PgxIface, assetTypeID *int, s
 		FROM assets assets
 		JOIN asset_sources assetSources ON assets.id = assetSources.asset_id
 		WHERE assets.asset_type_id = $1
-		AND assetSources.source_id = $2`
+		AND assetSources.source_id = $2
+		AND assets.deleted_at IS NULL
+		`
 	if excludeIgnoreMarketData {
 		sql += `AND ignore_market_data = FALSE
 		`
 	}
 	results, err := dbConnPgx.Query(ctx, sql, *assetTypeID, *sourceID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetAssetsByAssetTypeAndSource", err, logging.Entity("assets"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	assetsWithSources := make([]AssetWithSources, 0)
@@ -504,8 +268,12 @@ func GetAssetsByAssetTypeAndSource(dbConnPgx utils.PgxIface, assetTypeID *int, s
 }
 
 func GetCryptoAssetsBySourceId(dbConnPgx utils.PgxIface, sourceID *int, excludeIgnoreMarketData bool) ([]AssetWithSources, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetCryptoAssetsBySourceIdCtx(ctx, dbConnPgx, sourceID, excludeIgnoreMarketData)
+}
+
+func GetCryptoAssetsBySourceIdCtx(ctx context.Context, dbConnPgx utils.PgxIface, sourceID *int, excludeIgnoreMarketData bool) ([]AssetWithSources, error) {
 	sql := `SELECT 
 	assets.id,
 	assets.uuid, 
@@ -539,15 +307,17 @@ func GetCryptoAssetsBySourceId(dbConnPgx utils.PgxIface, sourceID *int, excludeI
 	FROM assets assets
 	JOIN asset_sources assetSources ON assets.id = assetSources.asset_id
 	WHERE assetSources.source_id = $1
-	AND assets.asset_type_id = 1`
+	AND assets.asset_type_id = 1
+	AND assets.deleted_at IS NULL
+	`
 	if excludeIgnoreMarketData {
 		sql += `AND ignore_market_data = FALSE
 		`
 	}
 	results, err := dbConnPgx.Query(ctx, sql, *sourceID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetCryptoAssetsBySourceId", err, logging.Entity("assets"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	assetsWithSources := make([]AssetWithSources, 0)
@@ -592,10 +362,16 @@ func GetCryptoAssetsBySourceId(dbConnPgx utils.PgxIface, sourceID *int, excludeI
 }
 
 func GetCryptoAssetsBySourceID(dbConnPgx utils.PgxIface, sourceID *int, excludeIgnoreMarketData bool) ([]Asset, error) {
-	assetsWithSources, err := GetCryptoAssetsBySourceId(dbConnPgx, sourceID, excludeIgnoreMarketData)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetCryptoAssetsBySourceIDCtx(ctx, dbConnPgx, sourceID, excludeIgnoreMarketData)
+}
+
+func GetCryptoAssetsBySourceIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, sourceID *int, excludeIgnoreMarketData bool) ([]Asset, error) {
+	assetsWithSources, err := GetCryptoAssetsBySourceIdCtx(ctx, dbConnPgx, sourceID, excludeIgnoreMarketData)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetCryptoAssetsBySourceID", err, logging.Entity("assets"))
+		return nil, dberrors.Wrap(err)
 	}
 	results := make([]Asset, 0)
 	for _, assetsWithSource := range assetsWithSources {
@@ -605,8 +381,12 @@ func GetCryptoAssetsBySourceID(dbConnPgx utils.PgxIface, sourceID *int, excludeI
 }
 
 func GetAssetWithSourceByAssetIdAndSourceID(dbConnPgx utils.PgxIface, assetID, sourceID *int, excludeIgnoreMarketData bool) (*AssetWithSources, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetAssetWithSourceByAssetIdAndSourceIDCtx(ctx, dbConnPgx, assetID, sourceID, excludeIgnoreMarketData)
+}
+
+func GetAssetWithSourceByAssetIdAndSourceIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID, sourceID *int, excludeIgnoreMarketData bool) (*AssetWithSources, error) {
 	query := `SELECT 
 	assets.id,
 	assets.uuid, 
@@ -641,7 +421,9 @@ func GetAssetWithSourceByAssetIdAndSourceID(dbConnPgx utils.PgxIface, assetID, s
 	JOIN asset_sources assetSources ON assets.id = assetSources.asset_id
 	WHERE 
 		assets.id = $1
-	AND assetSources.source_id = $2`
+	AND assetSources.source_id = $2
+	AND assets.deleted_at IS NULL
+	`
 	if excludeIgnoreMarketData {
 		query += `AND ignore_market_data = FALSE
 		`
@@ -679,18 +461,20 @@ func GetAssetWithSourceByAssetIdAndSourceID(dbConnPgx utils.PgxIface, assetID, s
 		&assetWithSources.SourceID,
 		&assetWithSources.SourceIdentifier,
 	)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
+	if err != nil {
+		logging.ReturnedError(ctx, "GetAssetWithSourceByAssetIdAndSourceID", err, logging.Entity("assets"))
+		return nil, dberrors.Wrap(err)
 	}
 	return assetWithSources, nil
 }
 
 func GetAssetWithSourceByAssetIdsAndSourceID(dbConnPgx utils.PgxIface, assetIDs []int, sourceID *int, excludeIgnoreMarketData bool) ([]AssetWithSources, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetAssetWithSourceByAssetIdsAndSourceIDCtx(ctx, dbConnPgx, assetIDs, sourceID, excludeIgnoreMarketData)
+}
+
+func GetAssetWithSourceByAssetIdsAndSourceIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetIDs []int, sourceID *int, excludeIgnoreMarketData bool) ([]AssetWithSources, error) {
 	query := `SELECT 
 	assets.id,
 	assets.uuid, 
@@ -725,15 +509,17 @@ func GetAssetWithSourceByAssetIdsAndSourceID(dbConnPgx utils.PgxIface, assetIDs
 	JOIN asset_sources assetSources ON assets.id = assetSources.asset_id
 	WHERE 
 	assets.id = ANY($1)
-	AND assetSources.source_id = $2`
+	AND assetSources.source_id = $2
+	AND assets.deleted_at IS NULL
+	`
 	if excludeIgnoreMarketData {
 		query += `AND ignore_market_data = FALSE
 		`
 	}
 	results, err := dbConnPgx.Query(ctx, query, pq.Array(assetIDs), *sourceID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetAssetWithSourceByAssetIdsAndSourceID", err, logging.Entity("assets"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	assetsWithSources := make([]AssetWithSources, 0)
@@ -776,187 +562,54 @@ func GetAssetWithSourceByAssetIdsAndSourceID(dbConnPgx utils.PgxIface, assetIDs
 	return assetsWithSources, nil
 }
 
-func GetAssetList(dbConnPgx utils.PgxIface, ids []int) ([]Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetAssetList(dbConnPgx utils.PgxIface, ids []int, opts ...repository.ReadOption) ([]Asset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets`
-	if len(ids) > 0 {
-		strIds := utils.SplitToString(ids, ",")
-		additionalQuery := fmt.Sprintf(` WHERE id IN (%s)`, strIds)
-		sql += additionalQuery
-	}
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAssetListCtx(ctx, dbConnPgx, ids, opts...)
+}
 
-	assets, err := pgx.CollectRows(results, pgx.RowToStructByName[Asset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return assets, nil
+func GetAssetListCtx(ctx context.Context, dbConnPgx utils.PgxIface, ids []int, opts ...repository.ReadOption) ([]Asset, error) {
+	return AssetRepository.GetList(ctx, dbConnPgx, ids, opts...)
 }
 
 func GetAssetsByChainId(dbConnPgx utils.PgxIface, chainID *int) ([]Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets
-	WHERE chain_id = $1`, *chainID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAssetsByChainIdCtx(ctx, dbConnPgx, chainID)
+}
 
-	assets, err := pgx.CollectRows(results, pgx.RowToStructByName[Asset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return assets, nil
+func GetAssetsByChainIdCtx(ctx context.Context, dbConnPgx utils.PgxIface, chainID *int) ([]Asset, error) {
+	return AssetRepository.Select(ctx, dbConnPgx, `WHERE chain_id = $1
+	AND deleted_at IS NULL`, *chainID)
 }
 
 // for refinedev
-func GetAssetListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetAssetListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group, opts ...repository.ReadOption) ([]Asset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetAssetListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters, opts...)
+}
 
-	sql := `SELECT 
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	cusip,
-	ticker,
-	base_asset_id,
-	quote_asset_id,
-	description,
-	asset_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	chain_id,
-	category_id,
-	sub_category_id,
-	is_default_quote,
-	ignore_market_data,
-	decimals,
-	contract_address,
-	starting_block_number,
-	import_geth,
-	import_geth_initial,
-	chainlink_usd_address,
-	chainlink_usd_chain_id,
-	total_supply
-	FROM assets
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-
-	assets, err := pgx.CollectRows(results, pgx.RowToStructByName[Asset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return assets, nil
+func GetAssetListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group, opts ...repository.ReadOption) ([]Asset, error) {
+	return AssetRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters, opts...)
 }
 
-func GetTotalAssetCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetTotalAssetCount(dbConnPgx utils.PgxIface, opts ...repository.ReadOption) (*int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalAssetCountCtx(ctx, dbConnPgx, opts...)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT COUNT(*) FROM assets`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalAssetCountCtx(ctx context.Context, dbConnPgx utils.PgxIface, opts ...repository.ReadOption) (*int, error) {
+	return AssetRepository.Count(ctx, dbConnPgx, opts...)
 }
 func GetDefaultQuoteAssetListBySourceID(dbConnPgx utils.PgxIface, sourceID *int) ([]AssetWithSources, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetDefaultQuoteAssetListBySourceIDCtx(ctx, dbConnPgx, sourceID)
+}
+
+func GetDefaultQuoteAssetListBySourceIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, sourceID *int) ([]AssetWithSources, error) {
 	results, err := dbConnPgx.Query(ctx, `SELECT 
 	assets.id,
 	assets.uuid, 
@@ -992,8 +645,8 @@ func GetDefaultQuoteAssetListBySourceID(dbConnPgx utils.PgxIface, sourceID *int)
 	WHERE assetSources.source_id = $1
 	`, *sourceID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetDefaultQuoteAssetListBySourceID", err, logging.Entity("get_default_quotes"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	assetWithSources := make([]AssetWithSources, 0)
@@ -1037,8 +690,12 @@ func GetDefaultQuoteAssetListBySourceID(dbConnPgx utils.PgxIface, sourceID *int)
 
 }
 func GetDefaultQuoteAssetList(dbConnPgx utils.PgxIface) ([]Asset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetDefaultQuoteAssetListCtx(ctx, dbConnPgx)
+}
+
+func GetDefaultQuoteAssetListCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]Asset, error) {
 	results, err := dbConnPgx.Query(ctx, `SELECT 
 	id,
 	uuid, 
@@ -1069,270 +726,58 @@ func GetDefaultQuoteAssetList(dbConnPgx utils.PgxIface) ([]Asset, error) {
 	total_supply
 	FROM get_default_quotes`)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetDefaultQuoteAssetList", err, logging.Entity("get_default_quotes"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	assets, err := pgx.CollectRows(results, pgx.RowToStructByName[Asset])
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		logging.ReturnedError(ctx, "GetDefaultQuoteAssetList", err, logging.Entity("get_default_quotes"))
+		return nil, dberrors.Wrap(err)
 	}
 	return assets, nil
 }
 
 func UpdateAsset(dbConnPgx utils.PgxIface, asset *Asset) error {
-	// if the asset id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if asset.ID == nil || *asset.ID == 0 {
-		return errors.New("asset has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateAsset DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `UPDATE assets SET 
-		name=$1,  
-		alternate_name=$2, 
-		cusip=$3,
-		ticker=$4,
-		base_asset_id=$5,
-		quote_asset_id=$6,
-		description=$7,
-		asset_type_id=$8,
-		updated_by=$9, 
-		updated_at=current_timestamp at time zone 'UTC',
-		chain_id = $10,
-		category_id = $11,
-		sub_category_id = $12,
-		is_default_quote = $13,
-		ignore_market_data = $14,
-		decimals = $15,
-		contract_address=$16,
-		starting_block_number=$17,
-		import_geth = $18,
-		import_geth_initial = $19,
-		chainlink_usd_address= $20,
-		chainlink_usd_chain_id= $21,
-		total_supply = $22
-		WHERE id=$23`
-
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		asset.Name,                //1
-		asset.AlternateName,       //2
-		asset.Cusip,               //3
-		asset.Ticker,              //4
-		asset.BaseAssetID,         //5
-		asset.QuoteAssetID,        //6
-		asset.Description,         //7
-		asset.AssetTypeID,         //8
-		asset.UpdatedBy,           //9
-		asset.ChainID,             //10
-		asset.CategoryID,          //11
-		asset.SubCategoryID,       //12
-		asset.IsDefaultQuote,      //13
-		asset.IgnoreMarketData,    //14
-		asset.Decimals,            //15
-		asset.ContractAddress,     //16
-		asset.StartingBlockNumber, //17
-		asset.ImportGeth,          //18
-		asset.ImportGethInitial,   //19
-		asset.ChainlinkUSDAddress, //20
-		asset.ChainlinkUSDChainID, //21
-		asset.TotalSupply,         //22
-		asset.ID,                  //23
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+	return UpdateAssetCtx(ctx, dbConnPgx, asset)
+}
+
+func UpdateAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, asset *Asset) error {
+	return AssetRepository.Update(ctx, dbConnPgx, asset)
+}
+
+// UpdateAssetIfUnchanged updates asset only if the row still has the UpdatedAt
+// asset was read with. Otherwise it returns a *dberrors.ConflictError[Asset]
+// holding the current row.
+func UpdateAssetIfUnchanged(dbConnPgx utils.PgxIface, asset *Asset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return UpdateAssetIfUnchangedCtx(ctx, dbConnPgx, asset)
+}
+
+func UpdateAssetIfUnchangedCtx(ctx context.Context, dbConnPgx utils.PgxIface, asset *Asset) error {
+	return AssetRepository.UpdateIfUnchanged(ctx, dbConnPgx, asset)
 }
 
 func InsertAsset(dbConnPgx utils.PgxIface, asset *Asset) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertAsset DbConn.Begin   %s", err.Error())
-		return -1, err
-	}
-	var insertID int
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO assets  
-	(
-		name, 
-		uuid,
-		alternate_name, 
-		cusip,
-		ticker,
-		base_asset_id,
-		quote_asset_id,
-		description,
-		asset_type_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		chain_id,
-		category_id,
-		sub_category_id,
-		is_default_quote,
-		ignore_market_data,
-		decimals,
-		contract_address,
-		starting_block_number,
-		import_geth,
-		import_geth_initial,
-		chainlink_usd_address,
-		chainlink_usd_chain_id,
-		total_supply
-		) VALUES (
-			$1,
-			uuid_generate_v4(), 
-			$2, 
-			$3, 
-			$4, 
-			$5,
-			$6,
-			$7,
-			$8,
-			$9,
-			current_timestamp at time zone 'UTC',
-			$9,
-			current_timestamp at time zone 'UTC',
-			$10,
-			$11,
-			$12,
-			$13,
-			$14,
-			$15,
-			$16,
-			$17,
-			$18,
-			$19,
-			$20,
-			$21,
-			$22
-		)
-		RETURNING id`,
-		asset.Name,                //1
-		asset.AlternateName,       //2
-		asset.Cusip,               //3
-		asset.Ticker,              //4
-		asset.BaseAssetID,         //5
-		asset.QuoteAssetID,        //6
-		asset.Description,         //7
-		asset.AssetTypeID,         //8
-		asset.CreatedBy,           //9
-		asset.ChainID,             //10
-		asset.CategoryID,          //11
-		asset.SubCategoryID,       //12
-		asset.IsDefaultQuote,      //13
-		asset.IgnoreMarketData,    //14
-		asset.Decimals,            //15
-		asset.ContractAddress,     //16
-		asset.StartingBlockNumber, //17
-		asset.ImportGeth,          //18
-		asset.ImportGethInitial,   //19
-		asset.ChainlinkUSDAddress, //20
-		asset.ChainlinkUSDChainID, //21
-		asset.TotalSupply,         //22
-	).Scan(&insertID)
+	return InsertAssetCtx(ctx, dbConnPgx, asset)
+}
 
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	return int(insertID), nil
+func InsertAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, asset *Asset) (int, error) {
+	return AssetRepository.Insert(ctx, dbConnPgx, asset)
 }
 
 func InsertAssets(dbConnPgx utils.PgxIface, assets []Asset) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range assets {
-		asset := assets[i]
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(asset.UUID)
-		row := []interface{}{
-			uuidString,                //1
-			asset.Name,                //2
-			asset.AlternateName,       //3
-			asset.Cusip,               //4
-			asset.Ticker,              //5
-			asset.BaseAssetID,         //6
-			asset.QuoteAssetID,        //7
-			asset.Description,         //8
-			asset.AssetTypeID,         //9
-			asset.CreatedBy,           //10
-			&now,                      //11
-			asset.CreatedBy,           //12
-			&now,                      //13
-			asset.ChainID,             //14
-			asset.CategoryID,          //15
-			asset.SubCategoryID,       //16
-			asset.IsDefaultQuote,      //17
-			asset.IgnoreMarketData,    //18
-			asset.Decimals,            //19
-			asset.ContractAddress,     //20
-			asset.StartingBlockNumber, //21
-			asset.ImportGeth,          //22
-			asset.ImportGethInitial,   //23
-			asset.ChainlinkUSDAddress, //24
-			asset.ChainlinkUSDChainID, //25
-			asset.TotalSupply,         //26
-
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"assets"},
-		[]string{
-			"uuid",                   //1
-			"name",                   //2
-			"alternate_name",         //3
-			"cusip",                  //4
-			"ticker",                 //5
-			"base_asset_id",          //6
-			"quote_asset_id",         //7
-			"description",            //8
-			"asset_type_id",          //9
-			"created_by",             //10
-			"created_at",             //11
-			"updated_by",             //12
-			"updated_at",             //13
-			"chain_id",               //14
-			"category_id",            //15
-			"sub_category_id",        //16
-			"is_default_quote",       //17
-			"ignore_market_data",     //18
-			"decimals",               //19
-			"contract_address",       //20
-			"starting_block_number",  //21
-			"import_geth",            //22
-			"import_geth_initial",    //23
-			"chainlink_usd_address",  //24
-			"chainlink_usd_chain_id", //25
-			"total_supply",           //26
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertAssets: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
+	return InsertAssetsCtx(ctx, dbConnPgx, assets)
+}
 
-	return nil
+func InsertAssetsCtx(ctx context.Context, dbConnPgx utils.PgxIface, assets []Asset) error {
+	_, err := AssetRepository.InsertMany(ctx, dbConnPgx, assets)
+	return dberrors.Wrap(err)
 }
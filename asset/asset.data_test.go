@@ -4,9 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -45,8 +49,8 @@ func TestGetAssetForErrNoRows(t *testing.T) {
 	assetID := 1
 	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE id = ?").WithArgs(assetID).WillReturnRows(noRows)
 	foundAsset, err := GetAsset(mock, &assetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAsset", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAsset != nil {
 		t.Errorf("Expected Asset From Method GetAsset: to be empty but got this: %v", foundAsset)
@@ -131,8 +135,8 @@ func TestGetAssetByTickerForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE ticker = ?").WithArgs(testTicker).WillReturnRows(noRows)
 	foundAsset, err := GetAssetByTicker(mock, testTicker)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetByTicker", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAsset != nil {
 		t.Errorf("Expected Asset From Method GetAssetByTicker: to be empty but got this: %v", foundAsset)
@@ -217,8 +221,8 @@ func TestGetAssetByContractAddressForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE contract_address = ?").WithArgs(testContractAddress).WillReturnRows(noRows)
 	foundAsset, err := GetAssetByContractAddress(mock, testContractAddress)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetByContractAddress", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAsset != nil {
 		t.Errorf("Expected Asset From Method GetAssetByContractAddress: to be empty but got this: %v", foundAsset)
@@ -303,8 +307,8 @@ func TestGetAssetByCusipForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE cusip = ?").WithArgs(testCusip).WillReturnRows(noRows)
 	foundAsset, err := GetAssetByCusip(mock, testCusip)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetByCusip", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAsset != nil {
 		t.Errorf("Expected Asset From Method GetAssetByCusip: to be empty but got this: %v", foundAsset)
@@ -390,8 +394,8 @@ func TestGetAssetByBaseAndQuoteIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM assets").WithArgs(*baseAssetID, *quoteAssetID).WillReturnRows(noRows)
 	foundAsset, err := GetAssetByBaseAndQuoteID(mock, baseAssetID, quoteAssetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetByBaseAndQuoteID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAsset != nil {
 		t.Errorf("Expected Asset From Method GetAssetByBaseAndQuoteID: to be empty but got this: %v", foundAsset)
@@ -517,7 +521,7 @@ func TestRemoveAsset(t *testing.T) {
 	defer mock.Close()
 	targetData := TestData1
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM assets WHERE id = ?").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("^UPDATE assets SET deleted_at").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = RemoveAsset(mock, targetData.ID)
 	if err != nil {
@@ -553,7 +557,7 @@ func TestRemoveAssetOnFailure(t *testing.T) {
 	defer mock.Close()
 	invalidID := utils.Ptr[int](-1)
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM assets WHERE id = ?").WithArgs(*invalidID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
+	mock.ExpectExec("^UPDATE assets SET deleted_at").WithArgs(*invalidID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 	mock.ExpectRollback()
 	err = RemoveAsset(mock, invalidID)
 	if err == nil {
@@ -564,6 +568,25 @@ func TestRemoveAssetOnFailure(t *testing.T) {
 	}
 }
 
+func TestRestoreAsset(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE assets SET deleted_at = NULL").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	err = RestoreAsset(mock, targetData.ID)
+	if err != nil {
+		t.Fatalf("an error '%s' in RestoreAsset", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetCurrentTradingAssets(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -876,8 +899,8 @@ func TestGetAssetWithSourceByAssetIdAndSourceIDForErrNoRows(t *testing.T) {
 	excludeIgnoreMarketData := true
 	mock.ExpectQuery("^SELECT (.+) FROM assets").WithArgs(*assetID, *sourceID).WillReturnRows(noRows)
 	foundAsset, err := GetAssetWithSourceByAssetIdAndSourceID(mock, assetID, sourceID, excludeIgnoreMarketData)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetWithSourceByAssetIdAndSourceID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAsset != nil {
 		t.Errorf("Expected Asset From Method GetAssetWithSourceByAssetIdAndSourceID: to be empty but got this: %v", foundAsset)
@@ -967,10 +990,10 @@ func TestGetAssetList(t *testing.T) {
 	defer mock.Close()
 	dataList := []Asset{TestData1, TestData2}
 	mockRows := AddAssetToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE").WillReturnRows(mockRows)
 	ids := make([]int, 0)
 	ids = append(ids, *TestData1.ID)
 	ids = append(ids, *TestData2.ID)
+	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE").WithArgs(ids).WillReturnRows(mockRows)
 	foundAssets, err := GetAssetList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetList", err)
@@ -989,6 +1012,25 @@ func TestGetAssetList(t *testing.T) {
 	}
 }
 
+func TestGetAssetListExcludesDeleted(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE deleted_at IS NULL$").WillReturnRows(AddAssetToMockRows(mock, TestAllData))
+	if _, err = GetAssetList(mock, nil); err != nil {
+		t.Fatalf("an error '%s' in GetAssetList", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE id = ANY\\(\\$1\\)$").WithArgs([]int{1, 2}).WillReturnRows(AddAssetToMockRows(mock, TestAllData))
+	if _, err = GetAssetList(mock, []int{1, 2}, repository.IncludeDeleted()); err != nil {
+		t.Fatalf("an error '%s' in GetAssetList", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetAssetListForErr(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -996,7 +1038,7 @@ func TestGetAssetListForErr(t *testing.T) {
 	}
 	defer mock.Close()
 	assetIDs := []int{-1, -2}
-	mock.ExpectQuery("^SELECT (.+) FROM assets").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM assets").WithArgs(assetIDs).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAssets, err := GetAssetList(mock, assetIDs)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetList", err)
@@ -1017,7 +1059,7 @@ func TestGetAssetListForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	assetIDs := []int{-1, -2}
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM assets").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM assets").WithArgs(assetIDs).WillReturnRows(differentModelRows)
 	foundAssets, err := GetAssetList(mock, assetIDs)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetList", err)
@@ -1109,8 +1151,8 @@ func TestGetAssetListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"asset_type_id = 1", "import_geth = TRUE"}
-	mock.ExpectQuery("^SELECT (.+) FROM assets").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("asset_type_id", "1"), filter.Equal("import_geth", "TRUE"))
+	mock.ExpectQuery("^SELECT (.+) FROM assets").WithArgs(int64(1), true, _start, _end-_start).WillReturnRows(mockRows)
 	foundAssets, err := GetAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetListByPagination", err)
@@ -1127,6 +1169,26 @@ func TestGetAssetListByPagination(t *testing.T) {
 	}
 }
 
+func TestGetAssetListByPaginationIncludeDeleted(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	filters := filter.All(filter.Equal("asset_type_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE deleted_at IS NULL AND \\(asset_type_id = \\$1\\)").WithArgs(int64(1)).WillReturnRows(AddAssetToMockRows(mock, TestAllData))
+	if _, err = GetAssetListByPagination(mock, nil, nil, "", "", filters); err != nil {
+		t.Fatalf("an error '%s' in GetAssetListByPagination", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM assets WHERE \\(asset_type_id = \\$1\\)").WithArgs(int64(1)).WillReturnRows(AddAssetToMockRows(mock, TestAllData))
+	if _, err = GetAssetListByPagination(mock, nil, nil, "", "", filters, repository.IncludeDeleted()); err != nil {
+		t.Fatalf("an error '%s' in GetAssetListByPagination", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetAssetListByPaginationForErr(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -1137,8 +1199,8 @@ func TestGetAssetListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"asset_type_id = 1", "import_geth = TRUE"}
-	mock.ExpectQuery("^SELECT (.+) FROM assets").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("asset_type_id", "1"), filter.Equal("import_geth", "TRUE"))
+	mock.ExpectQuery("^SELECT (.+) FROM assets").WithArgs(int64(1), true).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAssets, err := GetAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetListByPagination", err)
@@ -1161,9 +1223,9 @@ func TestGetAssetListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"asset_type_id = 1"}
+	filters := filter.All(filter.Equal("asset_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM assets").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM assets").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundAssets, err := GetAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetListByPagination", err)
@@ -1367,6 +1429,54 @@ func TestUpdateAsset(t *testing.T) {
 	}
 }
 
+func TestUpdateAssetIfUnchanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	newUpdatedAt := targetData.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE assets").WithArgs(
+		targetData.Name,                //1
+		targetData.AlternateName,       //2
+		targetData.Cusip,               //3
+		targetData.Ticker,              //4
+		targetData.BaseAssetID,         //5
+		targetData.QuoteAssetID,        //6
+		targetData.Description,         //7
+		targetData.AssetTypeID,         //8
+		targetData.UpdatedBy,           //9
+		targetData.ChainID,             //10
+		targetData.CategoryID,          //11
+		targetData.SubCategoryID,       //12
+		targetData.IsDefaultQuote,      //13
+		targetData.IgnoreMarketData,    //14
+		targetData.Decimals,            //15
+		targetData.ContractAddress,     //16
+		targetData.StartingBlockNumber, //17
+		targetData.ImportGeth,          //18
+		targetData.ImportGethInitial,   //19
+		targetData.ChainlinkUSDAddress, //20
+		targetData.ChainlinkUSDChainID, //21
+		targetData.TotalSupply,         //22
+		targetData.ID,                  //23
+		targetData.UpdatedAt,           //24
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}).AddRow(newUpdatedAt))
+	mock.ExpectCommit()
+	err = UpdateAssetIfUnchanged(mock, &targetData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpdateAssetIfUnchanged", err)
+	}
+	if !targetData.UpdatedAt.Equal(newUpdatedAt) {
+		t.Errorf("expected UpdatedAt to be %v, got %v", newUpdatedAt, targetData.UpdatedAt)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestUpdateAssetOnFailureAtParameter(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
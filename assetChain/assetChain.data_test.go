@@ -1,10 +1,13 @@
 package assetchain
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
@@ -30,15 +33,7 @@ var TestFeed2 = AssetChain{
 
 var TestFeeds = []AssetChain{TestFeed1, TestFeed2}
 
-var columns = []string{
-	"asset_id",
-	"chain_id",
-	"chainlink_data_feed_contract_address",
-	"created_by",
-	"created_at",
-	"updated_by",
-	"updated_at",
-}
+var columns = AssetChainRepository.Columns()
 
 func ptr(i int) *int { return &i }
 
@@ -73,8 +68,8 @@ func TestGetAssetChainForNoRows(t *testing.T) {
 	chainID := 999
 	mock.ExpectQuery("^SELECT (.+) FROM asset_chains").WithArgs(assetID, chainID).WillReturnRows(pgxmock.NewRows(columns))
 	found, err := GetAssetChain(mock, &assetID, &chainID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetChain", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if found != nil {
 		t.Errorf("Expected nil, got: %v", found)
@@ -91,7 +86,7 @@ func TestGetAssetChainList(t *testing.T) {
 	}
 	defer mock.Close()
 	mockRows := AddAssetChainToMockRows(mock, TestFeeds)
-	mock.ExpectQuery("^SELECT (.+) FROM asset_chains").WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM asset_chains WHERE asset_id = ANY\\(\\$1\\) AND chain_id = ANY\\(\\$2\\)").WithArgs([]int{1, 2}, []int{1}).WillReturnRows(mockRows)
 	feeds, err := GetAssetChainList(mock, []int{1, 2}, []int{1})
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetChainList", err)
@@ -117,7 +112,6 @@ func TestInsertAssetChain(t *testing.T) {
 		target.ChainID,
 		target.ChainlinkDataFeedContractAddress,
 		target.CreatedBy,
-		target.UpdatedBy,
 	).WillReturnRows(pgxmock.NewRows([]string{"asset_id", "chain_id"}).AddRow(*target.AssetID, *target.ChainID))
 	mock.ExpectCommit()
 	err = InsertAssetChain(mock, &target)
@@ -173,19 +167,7 @@ func TestRemoveAssetChain(t *testing.T) {
 }
 
 func AddAssetChainToMockRows(mock pgxmock.PgxPoolIface, dataList []AssetChain) *pgxmock.Rows {
-	rows := mock.NewRows(columns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.AssetID,
-			data.ChainID,
-			data.ChainlinkDataFeedContractAddress,
-			data.CreatedBy,
-			data.CreatedAt,
-			data.UpdatedBy,
-			data.UpdatedAt,
-		)
-	}
-	return rows
+	return AssetChainRepository.AddToMockRows(mock, dataList)
 }
 
 func TestInsertAssetChains(t *testing.T) {
@@ -214,9 +196,9 @@ func TestGetAssetChainListByPagination(t *testing.T) {
 	mock.ExpectQuery("^SELECT (.+) FROM asset_chains").WillReturnRows(mockRows)
 	_start := 0
 	_end := 10
-	_order := "asset_id"
-	_sort := "ASC"
-	filters := []string{}
+	_order := "ASC"
+	_sort := "asset_id"
+	var filters *filter.Group
 	feeds, err := GetAssetChainListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetChainListByPagination", err)
@@ -7,39 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var columns = []string{
-	"source_id",         //1
-	"asset_id",          //2
-	"uuid",              //3
-	"name",              //4
-	"alternate_name",    //5
-	"source_identifier", //6
-	"description",       //7
-	"source_data",       //8
-	"created_by",        //9
-	"created_at",        //10
-	"updated_by",        //11
-	"updated_at",        //12
-}
-
-var DBColumnsInsertAssetSources = []string{
-	"source_id",         //1
-	"asset_id",          //2
-	"uuid",              //3
-	"name",              //4
-	"alternate_name",    //5
-	"source_identifier", //6
-	"description",       //7
-	"source_data",       //8
-	"created_by",        //9
-	"created_at",        //10
-	"updated_by",        //11
-	"updated_at",        //12
-}
+var columns = AssetSourceRepository.Columns()
+
+var DBColumnsInsertAssetSources = AssetSourceRepository.InsertColumns()
 
 var TestData1 = AssetSource{
 	SourceID:         utils.Ptr[int](1),
@@ -73,24 +49,7 @@ var TestData2 = AssetSource{
 var TestAllData = []AssetSource{TestData1, TestData2}
 
 func AddAssetSourceToMockRows(mock pgxmock.PgxPoolIface, dataList []AssetSource) *pgxmock.Rows {
-	rows := mock.NewRows(columns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.SourceID,         //1
-			data.AssetID,          //2
-			data.UUID,             //3
-			data.Name,             //4
-			data.AlternateName,    //5
-			data.SourceIdentifier, //6
-			data.Description,      //7
-			data.SourceData,       //8
-			data.CreatedBy,        //9
-			data.CreatedAt,        //10
-			data.UpdatedBy,        //11
-			data.UpdatedAt,        //12
-		)
-	}
-	return rows
+	return AssetSourceRepository.AddToMockRows(mock, dataList)
 }
 func TestGetAllAssetSourceBySourceAndAssetType(t *testing.T) {
 	mock, err := pgxmock.NewPool()
@@ -199,8 +158,8 @@ func TestGetAssetSourceForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(sourceID, assetID).WillReturnRows(noRows)
 	foundAssetSource, err := GetAssetSource(mock, &sourceID, &assetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetSource", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAssetSource != nil {
 		t.Errorf("Expected AssetSource From Method GetAssetSource: to be empty but got this: %v", foundAssetSource)
@@ -288,8 +247,8 @@ func TestGetAssetSourceByTickerForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(sourceID, sourceIdentifier).WillReturnRows(noRows)
 	foundAssetSource, err := GetAssetSourceByTicker(mock, &sourceID, sourceIdentifier)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetSourceByTicker", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAssetSource != nil {
 		t.Errorf("Expected AssetSource From Method GetAssetSourceByTicker: to be empty but got this: %v", foundAssetSource)
@@ -411,7 +370,7 @@ func TestGetAssetSourceList(t *testing.T) {
 	mockRows := AddAssetSourceToMockRows(mock, dataList)
 	assetIds := []int{1, 2}
 	sourceIds := []int{1}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(assetIds, sourceIds).WillReturnRows(mockRows)
 	foundAssetSources, err := GetAssetSourceList(mock, assetIds, sourceIds)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetSourceList", err)
@@ -435,7 +394,7 @@ func TestGetAssetSourceListForErr(t *testing.T) {
 	defer mock.Close()
 	assetIds := []int{-1, -2}
 	sourceIds := []int{1}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(assetIds, sourceIds).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAssetSources, err := GetAssetSourceList(mock, assetIds, sourceIds)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetSourceList", err)
@@ -457,7 +416,7 @@ func TestGetAssetSourceListForCollectRowsErr(t *testing.T) {
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
 	assetIds := []int{-1, -2}
 	sourceIds := []int{1}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(assetIds, sourceIds).WillReturnRows(differentModelRows)
 	foundAssetSources, err := GetAssetSourceList(mock, assetIds, sourceIds)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetSourceList", err)
@@ -731,10 +690,10 @@ func TestGetAssetSourceListByPagination(t *testing.T) {
 	mockRows := AddAssetSourceToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "source_id"
 	_order := "ASC"
-	filters := []string{"source_id = 1", "asset_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("source_id", "1"), filter.Equal("asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundAssetSources, err := GetAssetSourceListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetSourceListByPagination", err)
@@ -758,10 +717,10 @@ func TestGetAssetSourceListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "source_id"
 	_order := "ASC"
-	filters := []string{"source_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("source_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAssetSources, err := GetAssetSourceListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetSourceListByPagination", err)
@@ -782,11 +741,11 @@ func TestGetAssetSourceListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "source_id"
 	_order := "ASC"
-	filters := []string{"source_id = 1"}
+	filters := filter.All(filter.Equal("source_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM asset_sources").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundAssetSources, err := GetAssetSourceListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetSourceListByPagination", err)
@@ -4,43 +4,20 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/shopspring/decimal"
 )
 
-var columns = []string{
-	"tax_id",            //1
-	"asset_id",          //2
-	"uuid",              //3
-	"name",              //4
-	"alternate_name",    //5
-	"tax_rate_override", //6
-	"tax_rate_type_id",  //7
-	"description",       //8
-	"created_by",        //9
-	"created_at",        //10
-	"updated_by",        //11
-	"updated_at",        //12
-}
-
-var DBColumnsInsertAssetTaxes = []string{
-	"tax_id",            //1
-	"asset_id",          //2
-	"uuid",              //3
-	"name",              //4
-	"alternate_name",    //5
-	"tax_rate_override", //6
-	"tax_rate_type_id",  //7
-	"description",       //8
-	"created_by",        //9
-	"created_at",        //10
-	"updated_by",        //11
-	"updated_at",        //12
-}
+var columns = AssetTaxRepository.Columns()
+
+var DBColumnsInsertAssetTaxes = AssetTaxRepository.InsertColumns()
 
 var TestData1 = AssetTax{
 	TaxID:           utils.Ptr[int](1),
@@ -74,24 +51,7 @@ var TestData2 = AssetTax{
 var TestAllData = []AssetTax{TestData1, TestData2}
 
 func AddAssetTaxToMockRows(mock pgxmock.PgxPoolIface, dataList []AssetTax) *pgxmock.Rows {
-	rows := mock.NewRows(columns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.TaxID,           //1
-			data.AssetID,         //2
-			data.UUID,            //3
-			data.Name,            //4
-			data.AlternateName,   //5
-			data.TaxRateOverride, //6
-			data.TaxRateTypeID,   //8
-			data.Description,     //7
-			data.CreatedBy,       //9
-			data.CreatedAt,       //10
-			data.UpdatedBy,       //11
-			data.UpdatedAt,       //12
-		)
-	}
-	return rows
+	return AssetTaxRepository.AddToMockRows(mock, dataList)
 }
 func TestGetAllAssetTaxesByTaxType(t *testing.T) {
 	mock, err := pgxmock.NewPool()
@@ -194,8 +154,8 @@ func TestGetAssetTaxForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WithArgs(taxID, assetID).WillReturnRows(noRows)
 	foundAssetTax, err := GetAssetTax(mock, &taxID, &assetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetAssetTax", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundAssetTax != nil {
 		t.Errorf("Expected AssetTax From Method GetAssetTax: to be empty but got this: %v", foundAssetTax)
@@ -318,7 +278,7 @@ func TestGetAssetTaxList(t *testing.T) {
 	mockRows := AddAssetTaxToMockRows(mock, dataList)
 	assetIds := []int{1, 2}
 	taxIds := []int{1, 2}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WithArgs(assetIds, taxIds).WillReturnRows(mockRows)
 	foundAssetTaxes, err := GetAssetTaxList(mock, assetIds, taxIds)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetTaxList", err)
@@ -342,7 +302,7 @@ func TestGetAssetTaxListForErr(t *testing.T) {
 	defer mock.Close()
 	assetIds := []int{-1, -2}
 	taxIds := []int{1}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WithArgs(assetIds, taxIds).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAssetTaxes, err := GetAssetTaxList(mock, assetIds, taxIds)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetTaxList", err)
@@ -364,7 +324,7 @@ func TestGetAssetTaxListForCollectRowsErr(t *testing.T) {
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
 	assetIds := []int{-1, -2}
 	taxIds := []int{1}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WithArgs(assetIds, taxIds).WillReturnRows(differentModelRows)
 	foundAssetTaxes, err := GetAssetTaxList(mock, assetIds, taxIds)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetTaxList", err)
@@ -470,6 +430,76 @@ func TestUpdateAssetTaxOnFailure(t *testing.T) {
 	}
 }
 
+func TestUpdateAssetTaxIfUnchanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	newUpdatedAt := targetData.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE asset_taxes").WithArgs(
+		targetData.Name,            //1
+		targetData.AlternateName,   //2
+		targetData.TaxRateOverride, //3
+		targetData.TaxRateTypeID,   //4
+		targetData.Description,     //5
+		targetData.UpdatedBy,       //6
+		targetData.TaxID,           //7
+		targetData.AssetID,         //8
+		targetData.UpdatedAt,       //9
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}).AddRow(newUpdatedAt))
+	mock.ExpectCommit()
+	err = UpdateAssetTaxIfUnchanged(mock, &targetData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpdateAssetTaxIfUnchanged", err)
+	}
+	if !targetData.UpdatedAt.Equal(newUpdatedAt) {
+		t.Errorf("expected UpdatedAt to be %v, got %v", newUpdatedAt, targetData.UpdatedAt)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateAssetTaxIfUnchangedOnConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	current := TestData1
+	current.Description = "edited elsewhere"
+	current.UpdatedAt = TestData1.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE asset_taxes").WithArgs(
+		targetData.Name,            //1
+		targetData.AlternateName,   //2
+		targetData.TaxRateOverride, //3
+		targetData.TaxRateTypeID,   //4
+		targetData.Description,     //5
+		targetData.UpdatedBy,       //6
+		targetData.TaxID,           //7
+		targetData.AssetID,         //8
+		targetData.UpdatedAt,       //9
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}))
+	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes(.+)FOR SHARE$").WithArgs(targetData.TaxID, targetData.AssetID).WillReturnRows(AddAssetTaxToMockRows(mock, []AssetTax{current}))
+	mock.ExpectRollback()
+	err = UpdateAssetTaxIfUnchanged(mock, &targetData)
+	var conflict *dberrors.ConflictError[AssetTax]
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a *dberrors.ConflictError[AssetTax], got '%v'", err)
+	}
+	if cmp.Equal(*conflict.Current, current) == false {
+		t.Errorf("Expected the current AssetTax %v, got %v", current, conflict.Current)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestInsertAssetTax(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -639,10 +669,10 @@ func TestGetAssetTaxListByPagination(t *testing.T) {
 	mockRows := AddAssetTaxToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "tax_id"
 	_order := "ASC"
-	filters := []string{"tax_id = 1", "asset_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("tax_id", "1"), filter.Equal("asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundAssetTaxes, err := GetAssetTaxListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAssetTaxListByPagination", err)
@@ -666,10 +696,10 @@ func TestGetAssetTaxListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "tax_id"
 	_order := "ASC"
-	filters := []string{"tax_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("tax_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundAssetTaxes, err := GetAssetTaxListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetTaxListByPagination", err)
@@ -690,11 +720,11 @@ func TestGetAssetTaxListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "tax_id"
 	_order := "ASC"
-	filters := []string{"tax_id = 1"}
+	filters := filter.All(filter.Equal("tax_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM asset_taxes").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundAssetTaxes, err := GetAssetTaxListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAssetTaxListByPagination", err)
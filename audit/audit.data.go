@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// AuditRepository implements the standard queries on the audits table. The
+// rows are written by the audit_row trigger, see migration 0007_audits.
+var AuditRepository = repository.New[Audit]("audits")
+
+// ActorSetting is the transaction setting the audit trigger reads the actor
+// from.
+const ActorSetting = repository.ActorSetting
+
+// GetAuditHistory returns the changes recorded for the row of tableName with
+// the given id, oldest first.
+func GetAuditHistory(dbConnPgx utils.PgxIface, tableName string, entityID *int) ([]Audit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetAuditHistoryCtx(ctx, dbConnPgx, tableName, entityID)
+}
+
+func GetAuditHistoryCtx(ctx context.Context, dbConnPgx utils.PgxIface, tableName string, entityID *int) ([]Audit, error) {
+	if entityID == nil {
+		return nil, dberrors.InvalidInput("audit history needs an entity ID")
+	}
+	return AuditRepository.Select(ctx, dbConnPgx, `WHERE table_name = $1 AND entity_id = $2 ORDER BY id`, tableName, *entityID)
+}
+
+// GetAuditHistoryByUUID returns the changes recorded for the row of tableName
+// with the given uuid, oldest first. Use it for tables without an id column
+// such as asset_taxes.
+func GetAuditHistoryByUUID(dbConnPgx utils.PgxIface, tableName, entityUUID string) ([]Audit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetAuditHistoryByUUIDCtx(ctx, dbConnPgx, tableName, entityUUID)
+}
+
+func GetAuditHistoryByUUIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, tableName, entityUUID string) ([]Audit, error) {
+	return AuditRepository.Select(ctx, dbConnPgx, `WHERE table_name = $1 AND entity_uuid = $2::uuid ORDER BY id`, tableName, entityUUID)
+}
+
+// SetActor records actor as the author of the changes made by the rest of the
+// transaction dbConnPgx belongs to. Without it the trigger falls back to the
+// updated_by / created_by column of the row, which says nothing about who
+// deleted it.
+func SetActor(ctx context.Context, dbConnPgx utils.PgxIface, actor string) error {
+	if _, err := dbConnPgx.Exec(ctx, `SELECT set_config($1, $2, true)`, ActorSetting, actor); err != nil {
+		logging.ReturnedError(ctx, "SetActor", err, logging.Entity("audits"))
+		return dberrors.Wrap(err)
+	}
+	return nil
+}
+
+// WithActor runs fn in a transaction whose changes are audited as made by
+// actor:
+//
+//	err := audit.WithActor(ctx, dbConnPgx, user, func(tx utils.PgxIface) error {
+//		return asset.RemoveAssetCtx(ctx, tx, &assetID)
+//	})
+func WithActor(ctx context.Context, dbConnPgx utils.PgxIface, actor string, fn func(tx utils.PgxIface) error) error {
+	return utils.WithTx(ctx, dbConnPgx, func(tx utils.PgxIface) error {
+		if err := SetActor(ctx, tx, actor); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
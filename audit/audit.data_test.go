@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/migrations"
+	structuredvalue "github.com/kfukue/lyle-labs-libraries/v2/structuredValue"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var TestData1 = Audit{
+	ID:          utils.Ptr[int](1),
+	UUID:        "01ef85e8-2c26-441e-8c7f-71d79518ad72",
+	TableName:   TableAssets,
+	EntityID:    utils.Ptr[int](2),
+	EntityUUID:  utils.Ptr[string]("880607ab-2833-4ad7-a231-b983a61c7b39"),
+	AuditTypeID: utils.Ptr[int](utils.CREATE_AUDIT_TYPE_STRUCTURED_VALUE_ID),
+	Operation:   OperationInsert,
+	Actor:       "SYSTEM",
+	AfterValues: Attrs{"id": float64(2), "name": "Ethereum", "ticker": "ETH"},
+	CreatedAt:   utils.SampleCreatedAtTime,
+}
+
+var TestData2 = Audit{
+	ID:           utils.Ptr[int](2),
+	UUID:         "4f0d5f1b-7b8f-4a43-9a43-2f3ea1f2b0c6",
+	TableName:    TableAssets,
+	EntityID:     utils.Ptr[int](2),
+	EntityUUID:   utils.Ptr[string]("880607ab-2833-4ad7-a231-b983a61c7b39"),
+	AuditTypeID:  utils.Ptr[int](utils.UPDATE_AUDIT_TYPE_STRUCTURED_VALUE_ID),
+	Operation:    OperationUpdate,
+	Actor:        "kfukue",
+	BeforeValues: Attrs{"name": "Ethereum"},
+	AfterValues:  Attrs{"name": "Ether"},
+	CreatedAt:    utils.SampleCreatedAtTime,
+}
+
+var TestAllData = []Audit{TestData1, TestData2}
+
+func TestGetAuditHistory(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	entityID := 2
+	mockRows := AuditRepository.AddToMockRows(mock, TestAllData)
+	mock.ExpectQuery("^SELECT (.+) FROM audits").WithArgs(TableAssets, entityID).WillReturnRows(mockRows)
+	foundAudits, err := GetAuditHistory(mock, TableAssets, &entityID)
+	if err != nil {
+		t.Fatalf("an error '%s' in GetAuditHistory", err)
+	}
+	if cmp.Equal(foundAudits, TestAllData) == false {
+		t.Errorf("Expected Audits From Method GetAuditHistory: %v is different from actual %v", foundAudits, TestAllData)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetAuditHistoryForErr(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	entityID := -1
+	mock.ExpectQuery("^SELECT (.+) FROM audits").WithArgs(TableAssets, entityID).WillReturnError(errors.New("Random SQL Error"))
+	foundAudits, err := GetAuditHistory(mock, TableAssets, &entityID)
+	if err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if len(foundAudits) != 0 {
+		t.Errorf("Expected Audits From Method GetAuditHistory: to be empty but got this: %v", foundAudits)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetAuditHistoryNilEntityID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	foundAudits, err := GetAuditHistory(mock, TableAssets, nil)
+	if !errors.Is(err, dberrors.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	if len(foundAudits) != 0 {
+		t.Errorf("Expected Audits From Method GetAuditHistory: to be empty but got this: %v", foundAudits)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetAuditHistoryByUUID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	entityUUID := *TestData1.EntityUUID
+	mockRows := AuditRepository.AddToMockRows(mock, TestAllData)
+	mock.ExpectQuery("^SELECT (.+) FROM audits").WithArgs(TableAssets, entityUUID).WillReturnRows(mockRows)
+	foundAudits, err := GetAuditHistoryByUUID(mock, TableAssets, entityUUID)
+	if err != nil {
+		t.Fatalf("an error '%s' in GetAuditHistoryByUUID", err)
+	}
+	if cmp.Equal(foundAudits, TestAllData) == false {
+		t.Errorf("Expected Audits From Method GetAuditHistoryByUUID: %v is different from actual %v", foundAudits, TestAllData)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^SELECT set_config").WithArgs(ActorSetting, "kfukue").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec("^DELETE FROM assets").WithArgs(2).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+	err = WithActor(context.Background(), mock, "kfukue", func(tx utils.PgxIface) error {
+		_, err := tx.Exec(context.Background(), `DELETE FROM assets WHERE id = $1`, 2)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("an error '%s' in WithActor", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithActorOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^SELECT set_config").WithArgs(ActorSetting, "kfukue").WillReturnError(errors.New("Random SQL Error"))
+	mock.ExpectRollback()
+	called := false
+	err = WithActor(context.Background(), mock, "kfukue", func(tx utils.PgxIface) error {
+		called = true
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if called {
+		t.Errorf("expected fn not to run when the actor cannot be set")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+// The trigger looks the audit types up by name; keep the names in step with
+// the structured value registry.
+func TestAuditTriggersUseAuditTypes(t *testing.T) {
+	all, err := migrations.All()
+	if err != nil {
+		t.Fatalf("an error '%s' in migrations.All", err)
+	}
+	for _, migration := range all {
+		if migration.Name != "audit_type_lookup" {
+			continue
+		}
+		for _, want := range []string{
+			fmt.Sprintf("svt.name = '%s'", structuredvalue.AuditTypeCreate.Type),
+			fmt.Sprintf("audit_type_id('%s')", structuredvalue.AuditTypeCreate.Name),
+			fmt.Sprintf("audit_type_id('%s')", structuredvalue.AuditTypeUpdate.Name),
+			fmt.Sprintf("audit_type_id('%s')", structuredvalue.AuditTypeDelete.Name),
+			fmt.Sprintf("('%s'), ('%s'), ('%s')", structuredvalue.AuditTypeCreate.Name, structuredvalue.AuditTypeUpdate.Name, structuredvalue.AuditTypeDelete.Name),
+		} {
+			if !strings.Contains(migration.Up, want) {
+				t.Errorf("expected migration %d_%s to contain %s", migration.Version, migration.Name, want)
+			}
+		}
+		if strings.Contains(migration.Down, "audit_row('") {
+			t.Errorf("expected migration %d_%s to look the audit types up on the way down", migration.Version, migration.Name)
+		}
+		for _, table := range []string{TableAssets, TableTaxes, TableAssetTaxes, TableLiquidityPools, TableChains, TableExchanges} {
+			trigger := fmt.Sprintf("ON %s\n  FOR EACH ROW EXECUTE FUNCTION audit_row();", table)
+			if !strings.Contains(migration.Up, trigger) {
+				t.Errorf("expected migration %d_%s to audit %s", migration.Version, migration.Name, table)
+			}
+		}
+		return
+	}
+	t.Fatalf("expected an audit_type_lookup migration")
+}
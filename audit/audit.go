@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Audit is one recorded change to a row of an audited table.
+type Audit struct {
+	ID           *int      `json:"id" db:"id"`                      //1
+	UUID         string    `json:"uuid" db:"uuid"`                  //2
+	TableName    string    `json:"tableName" db:"table_name"`       //3
+	EntityID     *int      `json:"entityId" db:"entity_id"`         //4
+	EntityUUID   *string   `json:"entityUuid" db:"entity_uuid"`     //5
+	AuditTypeID  *int      `json:"auditTypeId" db:"audit_type_id"`  //6
+	Operation    string    `json:"operation" db:"operation"`        //7
+	Actor        string    `json:"actor" db:"actor"`                //8
+	BeforeValues Attrs     `json:"beforeValues" db:"before_values"` //9
+	AfterValues  Attrs     `json:"afterValues" db:"after_values"`   //10
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`       //11
+}
+
+// Operations recorded in Audit.Operation.
+const (
+	OperationInsert = "INSERT"
+	OperationUpdate = "UPDATE"
+	OperationDelete = "DELETE"
+)
+
+// The audited tables.
+const (
+	TableAssets         = "assets"
+	TableTaxes          = "taxes"
+	TableAssetTaxes     = "asset_taxes"
+	TableLiquidityPools = "liquidity_pools"
+	TableChains         = "chains"
+	TableExchanges      = "exchanges"
+)
+
+// Attrs holds the column values of a row keyed by column name. For updates
+// only the changed columns are recorded.
+type Attrs map[string]interface{}
+
+func (a Attrs) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+func (a *Attrs) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	}
+	return errors.New("type assertion to []byte failed")
+}
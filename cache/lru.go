@@ -0,0 +1,185 @@
+// Package cache provides the size-bounded LRU with TTL behind the read-through
+// caches of the data packages, e.g. asset.AssetCache.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats counts the lookups of a cache.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// HitRatio returns the share of lookups that were hits, or 0 before any.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// LRU holds at most size entries, evicting the least recently used first.
+// Entries older than the TTL are treated as missing. It is safe for
+// concurrent use.
+type LRU[K comparable, V any] struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[K]*list.Element
+	stats   Stats
+	// loads are the keys GetOrLoad is loading. Delete bumps the generation
+	// of its key and DeleteFunc and Purge, which cannot tell which loads
+	// they affect, bump epoch; a load that sees either change is not cached.
+	loads map[K]*load
+	epoch uint64
+}
+
+type load struct {
+	running    int
+	generation uint64
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// NewLRU returns an LRU of size entries. A ttl of 0 never expires them.
+func NewLRU[K comparable, V any](size int, ttl time.Duration) *LRU[K, V] {
+	if size < 1 {
+		size = 1
+	}
+	return &LRU[K, V]{size: size, ttl: ttl, now: time.Now, ll: list.New(), entries: map[K]*list.Element{}, loads: map[K]*load{}}
+}
+
+// Get returns the value of key and whether it was cached and fresh.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if c.ttl == 0 || c.now().Before(e.expires) {
+			c.ll.MoveToFront(el)
+			c.stats.Hits++
+			return e.value, true
+		}
+		c.remove(el)
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// GetOrLoad returns the value of key, or else calls fn outside the lock and
+// caches what it returns. The value is not cached when fn fails, or when key
+// was deleted while fn ran, so a load cannot bring back a value invalidated
+// after it started.
+func (c *LRU[K, V]) GetOrLoad(key K, fn func() (V, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+	c.mu.Lock()
+	l, ok := c.loads[key]
+	if !ok {
+		l = &load{}
+		c.loads[key] = l
+	}
+	l.running++
+	generation, epoch := l.generation, c.epoch
+	c.mu.Unlock()
+
+	value, err := fn()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l.running--
+	if l.running == 0 {
+		delete(c.loads, key)
+	}
+	if err == nil && l.generation == generation && c.epoch == epoch {
+		c.set(key, value)
+	}
+	return value, err
+}
+
+// Set caches value under key.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value)
+}
+
+func (c *LRU[K, V]) set(key K, value V) {
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.ll.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete drops key.
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if l, ok := c.loads[key]; ok {
+		l.generation++
+	}
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+// DeleteFunc drops every entry for which match returns true.
+func (c *LRU[K, V]) DeleteFunc(match func(K, V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epoch++
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*entry[K, V])
+		if match(e.key, e.value) {
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+// Purge drops every entry. The stats are kept.
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epoch++
+	c.ll.Init()
+	c.entries = map[K]*list.Element{}
+}
+
+// Stats returns the lookup counts so far and the current size.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Size = c.ll.Len()
+	return s
+}
+
+func (c *LRU[K, V]) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.entries, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected a to be kept, got %v %v", v, ok)
+	}
+	s := c.Stats()
+	if s.Hits != 2 || s.Misses != 1 || s.Evictions != 1 || s.Size != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	c := NewLRU[string, int](10, time.Minute)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	c.Set("a", 1)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a to be cached")
+	}
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a to have expired")
+	}
+	if s := c.Stats(); s.Size != 0 {
+		t.Errorf("expected the expired entry to be dropped, got size %d", s.Size)
+	}
+}
+
+func TestLRUDeleteFunc(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 1)
+	c.DeleteFunc(func(_ string, v int) bool { return v == 1 })
+	if s := c.Stats(); s.Size != 1 {
+		t.Errorf("expected 1 entry left, got %d", s.Size)
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("expected b to be kept")
+	}
+}
+
+func TestLRUGetOrLoad(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return 1, nil
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := c.GetOrLoad("a", load); err != nil || v != 1 {
+			t.Fatalf("expected 1, got %v %v", v, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("expected a single load, got %d", loads)
+	}
+	if _, err := c.GetOrLoad("b", func() (int, error) { return 0, errors.New("not found") }); err == nil {
+		t.Error("expected the load error")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected a failed load not to be cached")
+	}
+}
+
+// A value loaded before an invalidation must not be cached after it.
+func TestLRUGetOrLoadRacingDelete(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+	for name, invalidate := range map[string]func(){
+		"Delete":     func() { c.Delete("a") },
+		"DeleteFunc": func() { c.DeleteFunc(func(_ string, v int) bool { return v == 1 }) },
+		"Purge":      c.Purge,
+	} {
+		v, err := c.GetOrLoad("a", func() (int, error) {
+			invalidate()
+			return 1, nil
+		})
+		if err != nil || v != 1 {
+			t.Fatalf("%s: expected the loaded value, got %v %v", name, v, err)
+		}
+		if _, ok := c.Get("a"); ok {
+			t.Errorf("%s: expected the stale load not to be cached", name)
+		}
+		if len(c.loads) != 0 {
+			t.Errorf("%s: expected no loads left, got %d", name, len(c.loads))
+		}
+	}
+	if _, err := c.GetOrLoad("a", func() (int, error) { return 2, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c.Get("a"); !ok || v != 2 {
+		t.Errorf("expected the next load to be cached, got %v %v", v, ok)
+	}
+}
@@ -1,76 +1,44 @@
 package chain
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var columns = []string{
-	"id",                 //1
-	"uuid",               //2
-	"base_asset_id",      //3
-	"name",               //4
-	"alternate_name",     //5
-	"address",            //6
-	"chain_type_id",      //7
-	"description",        //8
-	"created_by",         //9
-	"created_at",         //10
-	"updated_by",         //11
-	"updated_at",         //12
-	"rpc_url",            //13
-	"chain_id",           //14
-	"block_explorer_url", //15
-	"rpc_url_dev",        //16
-	"rpc_url_prod",       //17
-	"rpc_url_archive",    //18
-}
-
-var DBColumnsInsertChains = []string{
-	"uuid",               //1
-	"base_asset_id",      //2
-	"name",               //3
-	"alternate_name",     //4
-	"address",            //5
-	"chain_type_id",      //6
-	"description",        //7
-	"created_by",         //8
-	"created_at",         //9
-	"updated_by",         //10
-	"updated_at",         //11
-	"rpc_url",            //12
-	"chain_id",           //13
-	"block_explorer_url", //14
-	"rpc_url_dev",        //15
-	"rpc_url_prod",       //16
-	"rpc_url_archive",    //17
-}
+var columns = ChainRepository.Columns()
+
+var DBColumnsInsertChains = ChainRepository.InsertColumns()
 
 var TestData1 = Chain{
-	ID:               utils.Ptr[int](1),
-	UUID:             "880607ab-2833-4ad7-a231-b983a61c7b39",
-	BaseAssetID:      utils.Ptr[int](2),
-	Name:             "Ethereum",
-	AlternateName:    "ETH",
-	Address:          "",
-	ChainTypeID:      utils.Ptr[int](19),
-	Description:      "",
-	CreatedBy:        "SYSTEM",
-	CreatedAt:        utils.SampleCreatedAtTime,
-	UpdatedBy:        "SYSTEM",
-	UpdatedAt:        utils.SampleCreatedAtTime,
-	RpcURL:           "ws://erigon.dappnode:8545",
-	ChainID:          utils.Ptr[int](1),
-	BlockExplorerURL: "https://etherscan.io/",
-	RpcURLDev:        "ws://erigon.dappnode:8545",
-	RpcURLProd:       "ws://erigon.dappnode:8545",
-	RpcURLArchive:    "ws://erigon.dappnode:8545",
+	ID:                utils.Ptr[int](1),
+	UUID:              "880607ab-2833-4ad7-a231-b983a61c7b39",
+	BaseAssetID:       utils.Ptr[int](2),
+	Name:              "Ethereum",
+	AlternateName:     "ETH",
+	Address:           "",
+	ChainTypeID:       utils.Ptr[int](19),
+	Description:       "",
+	CreatedBy:         "SYSTEM",
+	CreatedAt:         utils.SampleCreatedAtTime,
+	UpdatedBy:         "SYSTEM",
+	UpdatedAt:         utils.SampleCreatedAtTime,
+	RpcURL:            "ws://erigon.dappnode:8545",
+	ChainID:           utils.Ptr[int](1),
+	BlockExplorerURL:  "https://etherscan.io/",
+	RpcURLDev:         "ws://erigon.dappnode:8545",
+	RpcURLProd:        "ws://erigon.dappnode:8545",
+	RpcURLArchive:     "ws://erigon.dappnode:8545",
+	ConfirmationDepth: utils.Ptr[int](64),
 }
 
 var TestData2 = Chain{
@@ -96,31 +64,7 @@ var TestData2 = Chain{
 var TestAllData = []Chain{TestData1, TestData2}
 
 func AddChainToMockRows(mock pgxmock.PgxPoolIface, dataList []Chain) *pgxmock.Rows {
-	rows := mock.NewRows(columns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,               //1
-			data.UUID,             //2
-			data.BaseAssetID,      //3
-			data.Name,             //4
-			data.AlternateName,    //5
-			data.Address,          //6
-			data.ChainTypeID,      //8
-			data.Description,      //7
-			data.CreatedBy,        //9
-			data.CreatedAt,        //10
-			data.UpdatedBy,        //11
-			data.UpdatedAt,        //12
-			data.RpcURL,           //13
-			data.ChainID,          //14
-			data.BlockExplorerURL, //15
-			data.RpcURLDev,        //16
-			data.RpcURLProd,       //17
-			data.RpcURLArchive,    //18
-
-		)
-	}
-	return rows
+	return ChainRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetChain(t *testing.T) {
@@ -156,8 +100,8 @@ func TestGetChainForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(chainID).WillReturnRows(noRows)
 	foundChain, err := GetChain(mock, &chainID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetChain", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundChain != nil {
 		t.Errorf("Expected Chain From Method GetChain: to be empty but got this: %v", foundChain)
@@ -209,6 +153,28 @@ func TestGetChainForCollectRowsErr(t *testing.T) {
 	}
 }
 
+func TestGetChainCtxForCanceledContext(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData2
+	dataList := []Chain{targetData}
+	chainID := targetData.ChainID
+	mockRows := AddChainToMockRows(mock, dataList)
+	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(*chainID).WillReturnRows(mockRows).WillDelayFor(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	foundChain, err := GetChainCtx(ctx, mock, chainID)
+	if err == nil {
+		t.Fatalf("expected an error '%s' in GetChainCtx", err)
+	}
+	if foundChain != nil {
+		t.Errorf("Expected Chain From Method GetChainCtx: to be empty but got this: %v", foundChain)
+	}
+}
+
 func TestGetChainByAddress(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -242,8 +208,8 @@ func TestGetChainByAddressForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(chainAddress).WillReturnRows(noRows)
 	foundChain, err := GetChainByAddress(mock, chainAddress)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetChainByAddress", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundChain != nil {
 		t.Errorf("Expected Chain From Method GetChainByAddress: to be empty but got this: %v", foundChain)
@@ -328,8 +294,8 @@ func TestGetChainByAlternateNameForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(chainAlternateName).WillReturnRows(noRows)
 	foundChain, err := GetChainByAlternateName(mock, chainAlternateName)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetChainByAlternateName", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundChain != nil {
 		t.Errorf("Expected Chain From Method GetChainByAlternateName: to be empty but got this: %v", foundChain)
@@ -390,7 +356,7 @@ func TestRemoveChain(t *testing.T) {
 	targetData := TestData1
 	chainID := targetData.ChainID
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM chains").WithArgs(*chainID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("^UPDATE chains SET deleted_at").WithArgs(*chainID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = RemoveChain(mock, chainID)
 	if err != nil {
@@ -426,7 +392,7 @@ func TestRemoveChainOnFailure(t *testing.T) {
 	defer mock.Close()
 	chainID := -1
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM chains").WithArgs(chainID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
+	mock.ExpectExec("^UPDATE chains SET deleted_at").WithArgs(chainID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 	mock.ExpectRollback()
 	err = RemoveChain(mock, &chainID)
 	if err == nil {
@@ -437,6 +403,25 @@ func TestRemoveChainOnFailure(t *testing.T) {
 	}
 }
 
+func TestRestoreChain(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE chains SET deleted_at = NULL").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	err = RestoreChain(mock, targetData.ID)
+	if err != nil {
+		t.Fatalf("an error '%s' in RestoreChain", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetChainList(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -446,7 +431,7 @@ func TestGetChainList(t *testing.T) {
 	dataList := []Chain{TestData1, TestData2}
 	mockRows := AddChainToMockRows(mock, dataList)
 	chainIDs := []int{1, 2}
-	mock.ExpectQuery("^SELECT (.+) FROM chains").WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(chainIDs).WillReturnRows(mockRows)
 	foundChains, err := GetChainList(mock, chainIDs)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetChainList", err)
@@ -469,7 +454,7 @@ func TestGetChainListForErr(t *testing.T) {
 	}
 	defer mock.Close()
 	chainIDs := []int{-1, -2}
-	mock.ExpectQuery("^SELECT (.+) FROM chains").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(chainIDs).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundChains, err := GetChainList(mock, chainIDs)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetChainList", err)
@@ -490,7 +475,7 @@ func TestGetChainListForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
 	chainIDs := []int{-1, -2}
-	mock.ExpectQuery("^SELECT (.+) FROM chains").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(chainIDs).WillReturnRows(differentModelRows)
 	foundChains, err := GetChainList(mock, chainIDs)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetChainList", err)
@@ -515,8 +500,8 @@ func TestGetChainListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_type_id = 1", "base_asset_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM chains").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("chain_type_id", "1"), filter.Equal("base_asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundChains, err := GetChainListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetChainListByPagination", err)
@@ -542,8 +527,8 @@ func TestGetChainListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_type_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM chains").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("chain_type_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundChains, err := GetChainListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetChainListByPagination", err)
@@ -556,6 +541,29 @@ func TestGetChainListByPaginationForErr(t *testing.T) {
 	}
 }
 
+func TestGetChainListByPaginationForInvalidSort(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	_start := 1
+	_end := 10
+	_sort := "id; DROP TABLE chains"
+	_order := "ASC"
+	filters := filter.All(filter.Equal("chain_type_id", "1"))
+	foundChains, err := GetChainListByPagination(mock, &_start, &_end, _order, _sort, filters)
+	if !errors.Is(err, filter.ErrUnknownField) {
+		t.Fatalf("expected filter.ErrUnknownField in GetChainListByPagination but got '%s'", err)
+	}
+	if len(foundChains) != 0 {
+		t.Errorf("Expected From Method GetChainListByPagination: to be empty but got this: %v", foundChains)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetChainListByPaginationForCollectRowsErr(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -566,9 +574,9 @@ func TestGetChainListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_type_id = 1"}
+	filters := filter.All(filter.Equal("chain_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM chains").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM chains").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundChains, err := GetChainListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetChainListByPagination", err)
@@ -628,20 +636,21 @@ func TestUpdateChain(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE chains").WithArgs(
-		targetData.Name,             //1
-		targetData.AlternateName,    //2
-		targetData.Address,          //3
-		targetData.ChainTypeID,      //4
-		targetData.Description,      //5
-		targetData.UpdatedBy,        //6
-		targetData.BaseAssetID,      //7
-		targetData.RpcURL,           //8
-		targetData.ChainID,          //9
-		targetData.BlockExplorerURL, //10
-		targetData.RpcURLDev,        //11
-		targetData.RpcURLProd,       //12
-		targetData.RpcURLArchive,    //13
-		targetData.ID,               //14
+		targetData.BaseAssetID,       //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.Address,           //4
+		targetData.ChainTypeID,       //5
+		targetData.Description,       //6
+		targetData.UpdatedBy,         //7
+		targetData.RpcURL,            //8
+		targetData.ChainID,           //9
+		targetData.BlockExplorerURL,  //10
+		targetData.RpcURLDev,         //11
+		targetData.RpcURLProd,        //12
+		targetData.RpcURLArchive,     //13
+		targetData.ConfirmationDepth, //14
+		targetData.ID,                //15
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateChain(mock, &targetData)
@@ -698,20 +707,21 @@ func TestUpdateChainOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE chains").WithArgs(
-		targetData.Name,             //1
-		targetData.AlternateName,    //2
-		targetData.Address,          //3
-		targetData.ChainTypeID,      //4
-		targetData.Description,      //5
-		targetData.UpdatedBy,        //6
-		targetData.BaseAssetID,      //7
-		targetData.RpcURL,           //8
-		targetData.ChainID,          //9
-		targetData.BlockExplorerURL, //10
-		targetData.RpcURLDev,        //11
-		targetData.RpcURLProd,       //12
-		targetData.RpcURLArchive,    //13
-		targetData.ID,               //14
+		targetData.BaseAssetID,       //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.Address,           //4
+		targetData.ChainTypeID,       //5
+		targetData.Description,       //6
+		targetData.UpdatedBy,         //7
+		targetData.RpcURL,            //8
+		targetData.ChainID,           //9
+		targetData.BlockExplorerURL,  //10
+		targetData.RpcURLDev,         //11
+		targetData.RpcURLProd,        //12
+		targetData.RpcURLArchive,     //13
+		targetData.ConfirmationDepth, //14
+		targetData.ID,                //15
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -734,19 +744,20 @@ func TestInsertChain(t *testing.T) {
 	targetData.Name = "New Name"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO chains").WithArgs(
-		targetData.Name,             //1
-		targetData.AlternateName,    //2
-		targetData.Address,          //3
-		targetData.ChainTypeID,      //4
-		targetData.Description,      //5
-		targetData.UpdatedBy,        //6
-		targetData.BaseAssetID,      //7
-		targetData.RpcURL,           //8
-		targetData.ChainID,          //9
-		targetData.BlockExplorerURL, //10
-		targetData.RpcURLDev,        //11
-		targetData.RpcURLProd,       //12
-		targetData.RpcURLArchive,    //13
+		targetData.BaseAssetID,       //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.Address,           //4
+		targetData.ChainTypeID,       //5
+		targetData.Description,       //6
+		targetData.CreatedBy,         //7
+		targetData.RpcURL,            //8
+		targetData.ChainID,           //9
+		targetData.BlockExplorerURL,  //10
+		targetData.RpcURLDev,         //11
+		targetData.RpcURLProd,        //12
+		targetData.RpcURLArchive,     //13
+		targetData.ConfirmationDepth, //14
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 	chainID, err := InsertChain(mock, &targetData)
@@ -790,19 +801,20 @@ func TestInsertChainOnFailure(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO chains").WithArgs(
-		targetData.Name,             //1
-		targetData.AlternateName,    //2
-		targetData.Address,          //3
-		targetData.ChainTypeID,      //4
-		targetData.Description,      //5
-		targetData.UpdatedBy,        //6
-		targetData.BaseAssetID,      //7
-		targetData.RpcURL,           //8
-		targetData.ChainID,          //9
-		targetData.BlockExplorerURL, //10
-		targetData.RpcURLDev,        //11
-		targetData.RpcURLProd,       //12
-		targetData.RpcURLArchive,    //13
+		targetData.BaseAssetID,       //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.Address,           //4
+		targetData.ChainTypeID,       //5
+		targetData.Description,       //6
+		targetData.CreatedBy,         //7
+		targetData.RpcURL,            //8
+		targetData.ChainID,           //9
+		targetData.BlockExplorerURL,  //10
+		targetData.RpcURLDev,         //11
+		targetData.RpcURLProd,        //12
+		targetData.RpcURLArchive,     //13
+		targetData.ConfirmationDepth, //14
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	chainID, err := InsertChain(mock, &targetData)
@@ -828,19 +840,20 @@ func TestInsertChainOnFailureOnCommit(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO chains").WithArgs(
-		targetData.Name,             //1
-		targetData.AlternateName,    //2
-		targetData.Address,          //3
-		targetData.ChainTypeID,      //4
-		targetData.Description,      //5
-		targetData.UpdatedBy,        //6
-		targetData.BaseAssetID,      //7
-		targetData.RpcURL,           //8
-		targetData.ChainID,          //9
-		targetData.BlockExplorerURL, //10
-		targetData.RpcURLDev,        //11
-		targetData.RpcURLProd,       //12
-		targetData.RpcURLArchive,    //13
+		targetData.BaseAssetID,       //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.Address,           //4
+		targetData.ChainTypeID,       //5
+		targetData.Description,       //6
+		targetData.CreatedBy,         //7
+		targetData.RpcURL,            //8
+		targetData.ChainID,           //9
+		targetData.BlockExplorerURL,  //10
+		targetData.RpcURLDev,         //11
+		targetData.RpcURLProd,        //12
+		targetData.RpcURLArchive,     //13
+		targetData.ConfirmationDepth, //14
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -51,3 +51,12 @@ ALTER TABLE chains
   ADD COLUMN rpc_url_archive VARCHAR(255) NULL
   COMMIT
 -- end 2024-05-14
+
+
+-- new columns 2026-10-18
+ROLLBACK
+START TRANSACTION;
+ALTER TABLE chains
+  ADD COLUMN confirmation_depth INT NULL
+  COMMIT
+-- end 2026-10-18
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by structs that check themselves after Bind.
+type Validator interface {
+	Validate() error
+}
+
+// MissingKeysError lists the required keys no source set.
+type MissingKeysError struct {
+	Keys []string
+}
+
+func (e *MissingKeysError) Error() string {
+	return "config: missing required keys: " + strings.Join(e.Keys, ", ")
+}
+
+// Bind sets the fields of the struct dst points to from v. Fields are bound by
+// their `config` tag:
+//
+//	type DB struct {
+//		User     string        `config:"DB_USER,required"`
+//		Password string        `config:"DB_PASS,required=production"`
+//		Port     int           `config:"DB_PORT" default:"5432"`
+//		Timeout  time.Duration `config:"DB_STATEMENT_TIMEOUT"`
+//	}
+//
+// required makes the key mandatory in every profile, required=a|b only in
+// profiles a and b. Strings, bools, integers, floats, time.Duration and comma
+// separated []string are supported. Untagged struct fields are bound
+// recursively. All the missing keys are reported together in a
+// *MissingKeysError, along with any parse errors and the error of Validate
+// when dst, or a nested struct, is a Validator.
+func (v *Values) Bind(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("config: Bind needs a pointer to a struct")
+	}
+	var missing []string
+	errs := v.bindStruct(rv.Elem(), &missing)
+	if len(missing) > 0 {
+		errs = append([]error{&MissingKeysError{Keys: missing}}, errs...)
+	}
+	return errors.Join(errs...)
+}
+
+// Bind binds dst from Default.
+func Bind(dst interface{}) error {
+	v, err := Default()
+	if err != nil {
+		return err
+	}
+	return v.Bind(dst)
+}
+
+func (v *Values) bindStruct(rv reflect.Value, missing *[]string) []error {
+	var errs []error
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("config")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+				errs = append(errs, v.bindStruct(rv.Field(i), missing)...)
+			}
+			continue
+		}
+		key, required := parseTag(tag, v.profile)
+		value, ok := v.Lookup(key)
+		if !ok || value == "" {
+			if def, hasDefault := field.Tag.Lookup("default"); hasDefault {
+				value, ok = def, true
+			}
+		}
+		if !ok || value == "" {
+			if required {
+				*missing = append(*missing, key)
+			}
+			continue
+		}
+		if err := setField(rv.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("config: %s: %w", key, err))
+		}
+	}
+	if len(errs) == 0 && len(*missing) == 0 && rv.CanAddr() {
+		if validator, ok := rv.Addr().Interface().(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// parseTag returns the key of tag and whether it is required in profile.
+func parseTag(tag, profile string) (string, bool) {
+	key, options, _ := strings.Cut(tag, ",")
+	for _, option := range strings.Split(options, ",") {
+		name, profiles, hasProfiles := strings.Cut(option, "=")
+		if name != "required" {
+			continue
+		}
+		if !hasProfiles {
+			return key, true
+		}
+		for _, p := range strings.Split(profiles, "|") {
+			if p == profile {
+				return key, true
+			}
+		}
+	}
+	return key, false
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		var parts []string
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		field.Set(reflect.ValueOf(parts))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Package config loads settings once from layered sources and binds them into
+// typed structs. From lowest to highest precedence the layers are:
+//
+//   - defaults given to Load and `default` struct tags,
+//   - an optional YAML file, whose `profiles` section overrides the top level
+//     for the active profile,
+//   - the .env file, if there is one,
+//   - the process environment.
+//
+// Keys are upper snake case as in the environment. Nested YAML keys are joined
+// with underscores, so `db: {user: lyle}` sets DB_USER. Nothing in this
+// package exits the process: missing files are skipped and every other
+// problem is returned as an error.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Profiles selected by APP_ENV.
+const (
+	ProfileLocal      = "local"
+	ProfileLocalGeth  = "LOCAL_GETH"
+	ProfileProduction = "production"
+)
+
+// ProfileKey is the key that selects the profile.
+const ProfileKey = "APP_ENV"
+
+// Options says where Load reads from.
+type Options struct {
+	Defaults map[string]string
+	// EnvFile is the .env file, ".env" when empty. It is skipped if missing.
+	EnvFile string
+	// YAMLFile is read when set, and must then exist.
+	YAMLFile string
+	// Environ replaces os.Environ, mostly for tests.
+	Environ []string
+}
+
+// Values are the merged settings of every source.
+type Values struct {
+	profile string
+	values  map[string]string
+}
+
+// Load reads and merges the sources described by opts.
+func Load(opts Options) (*Values, error) {
+	values := map[string]string{}
+	for k, v := range opts.Defaults {
+		values[normalizeKey(k)] = v
+	}
+
+	environ := opts.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+	env := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	envFile := opts.EnvFile
+	if envFile == "" {
+		envFile = ".env"
+	}
+	dotEnv, err := godotenv.Read(envFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("config: reading %s: %w", envFile, err)
+	}
+
+	// The profile decides which YAML section applies, so it has to be known
+	// before the YAML file is read.
+	profile := firstNonEmpty(env[ProfileKey], dotEnv[ProfileKey], values[ProfileKey], ProfileLocal)
+
+	if opts.YAMLFile != "" {
+		fromYAML, err := readYAML(opts.YAMLFile, profile)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fromYAML {
+			values[k] = v
+		}
+		profile = firstNonEmpty(env[ProfileKey], dotEnv[ProfileKey], values[ProfileKey], ProfileLocal)
+	}
+	for k, v := range dotEnv {
+		values[k] = v
+	}
+	for k, v := range env {
+		values[k] = v
+	}
+	values[ProfileKey] = profile
+	return &Values{profile: profile, values: values}, nil
+}
+
+// readYAML flattens file into keys, applying profiles.<profile> on top.
+func readYAML(file, profile string) (map[string]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("config: parsing %s: %w", file, err)
+	}
+	profiles, _ := doc["profiles"].(map[string]interface{})
+	delete(doc, "profiles")
+	values := map[string]string{}
+	flatten("", doc, values)
+	if section, ok := profiles[profile].(map[string]interface{}); ok {
+		flatten("", section, values)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, node map[string]interface{}, into map[string]string) {
+	for k, v := range node {
+		key := normalizeKey(k)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flatten(key, v, into)
+		case []interface{}:
+			parts := make([]string, len(v))
+			for i, item := range v {
+				parts[i] = fmt.Sprint(item)
+			}
+			into[key] = strings.Join(parts, ",")
+		case nil:
+			into[key] = ""
+		default:
+			into[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+func normalizeKey(k string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(k))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Profile returns the active profile.
+func (v *Values) Profile() string {
+	return v.profile
+}
+
+// IsProduction reports whether the production profile is active.
+func (v *Values) IsProduction() bool {
+	return v.profile == ProfileProduction
+}
+
+// Lookup returns the value of key and whether any source set it.
+func (v *Values) Lookup(key string) (string, bool) {
+	value, ok := v.values[normalizeKey(key)]
+	return value, ok
+}
+
+// Get returns the value of key, or "" when no source set it.
+func (v *Values) Get(key string) string {
+	value, _ := v.Lookup(key)
+	return value
+}
+
+// Keys returns every key that is set, sorted.
+func (v *Values) Keys() []string {
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var (
+	defaultMu     sync.Mutex
+	defaultValues *Values
+	defaultErr    error
+)
+
+// Default returns the Values loaded from .env and the environment, and the
+// YAML file in CONFIG_FILE if set, loading them on first use.
+func Default() (*Values, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultValues == nil && defaultErr == nil {
+		defaultValues, defaultErr = Load(Options{YAMLFile: os.Getenv("CONFIG_FILE")})
+	}
+	return defaultValues, defaultErr
+}
+
+// Reset forgets the Values of Default, so they are loaded again.
+func Reset() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultValues, defaultErr = nil, nil
+}
+
+// Get returns the value of key in Default, or "" if it is unset or the
+// sources could not be loaded.
+func Get(key string) string {
+	v, err := Default()
+	if err != nil {
+		return os.Getenv(key)
+	}
+	return v.Get(key)
+}
+
+// Lookup returns the value of key in Default and whether it is set, falling
+// back to the environment if the sources could not be loaded.
+func Lookup(key string) (string, bool) {
+	v, err := Default()
+	if err != nil {
+		return os.LookupEnv(key)
+	}
+	return v.Lookup(key)
+}
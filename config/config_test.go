@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testYAML = `
+db:
+  host: yaml-host
+  port: 5433
+  name: assetdb
+chains: [1, 137]
+profiles:
+  production:
+    db:
+      host: prod-host
+`
+
+func writeFiles(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "config.yaml")
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(yamlFile, []byte(testYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(envFile, []byte("DB_PORT=5434\nDB_USER=dotenv\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return yamlFile, envFile
+}
+
+func TestLoadLayers(t *testing.T) {
+	yamlFile, envFile := writeFiles(t)
+	values, err := Load(Options{
+		Defaults: map[string]string{"DB_HOST": "localhost", "DB_SSL_MODE": "disable"},
+		EnvFile:  envFile,
+		YAMLFile: yamlFile,
+		Environ:  []string{"DB_USER=env"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"DB_HOST":     "yaml-host",
+		"DB_PORT":     "5434",
+		"DB_USER":     "env",
+		"DB_SSL_MODE": "disable",
+		"CHAINS":      "1,137",
+		"APP_ENV":     ProfileLocal,
+	}
+	for key, value := range want {
+		if got := values.Get(key); got != value {
+			t.Errorf("Get(%q) = %q, want %q", key, got, value)
+		}
+	}
+	if values.IsProduction() {
+		t.Error("expected the local profile")
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	yamlFile, _ := writeFiles(t)
+	values, err := Load(Options{
+		EnvFile:  filepath.Join(t.TempDir(), "missing.env"),
+		YAMLFile: yamlFile,
+		Environ:  []string{"APP_ENV=production"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !values.IsProduction() || values.Get("DB_HOST") != "prod-host" || values.Get("DB_PORT") != "5433" {
+		t.Errorf("expected the production section to apply, got host %q port %q", values.Get("DB_HOST"), values.Get("DB_PORT"))
+	}
+}
+
+func TestLoadMissingYAML(t *testing.T) {
+	if _, err := Load(Options{YAMLFile: filepath.Join(t.TempDir(), "missing.yaml"), Environ: []string{}}); err == nil {
+		t.Error("expected an error for a missing YAML file")
+	}
+}
+
+type dbSettings struct {
+	Host     string        `config:"DB_HOST,required"`
+	Port     int           `config:"DB_PORT" default:"5432"`
+	Password string        `config:"DB_PASS,required=production|LOCAL_GETH"`
+	Timeout  time.Duration `config:"DB_STATEMENT_TIMEOUT" default:"30s"`
+}
+
+type settings struct {
+	DB       dbSettings
+	Chains   []string `config:"CHAINS"`
+	Debug    bool     `config:"DEBUG"`
+	APIToken string   `config:"API_TOKEN,required"`
+}
+
+func (s *settings) Validate() error {
+	if len(s.Chains) == 0 {
+		return errors.New("at least one chain required")
+	}
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	values, err := Load(Options{
+		EnvFile: filepath.Join(t.TempDir(), "missing.env"),
+		Environ: []string{"DB_HOST=db", "CHAINS=1, 137", "DEBUG=true", "API_TOKEN=token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s settings
+	if err := values.Bind(&s); err != nil {
+		t.Fatal(err)
+	}
+	want := settings{
+		DB:       dbSettings{Host: "db", Port: 5432, Timeout: 30 * time.Second},
+		Chains:   []string{"1", "137"},
+		Debug:    true,
+		APIToken: "token",
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Bind() = %+v, want %+v", s, want)
+	}
+}
+
+func TestBindReportsEveryProblem(t *testing.T) {
+	values, err := Load(Options{
+		EnvFile: filepath.Join(t.TempDir(), "missing.env"),
+		Environ: []string{"APP_ENV=production", "DB_PORT=abc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s settings
+	err = values.Bind(&s)
+	var missing *MissingKeysError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingKeysError, got %v", err)
+	}
+	if want := []string{"DB_HOST", "DB_PASS", "API_TOKEN"}; !reflect.DeepEqual(missing.Keys, want) {
+		t.Errorf("missing keys = %v, want %v", missing.Keys, want)
+	}
+	if err.Error() == missing.Error() {
+		t.Errorf("expected the DB_PORT parse error to be reported too, got %v", err)
+	}
+}
+
+func TestBindValidate(t *testing.T) {
+	values, err := Load(Options{
+		EnvFile: filepath.Join(t.TempDir(), "missing.env"),
+		Environ: []string{"DB_HOST=db", "API_TOKEN=token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s settings
+	if err := values.Bind(&s); err == nil || err.Error() != "at least one chain required" {
+		t.Errorf("expected the Validate error, got %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+	"github.com/kfukue/lyle-labs-libraries/v2/secrets"
+)
+
+const (
+	DefaultPort            = 5432
+	DefaultDBName          = "assetdb"
+	DefaultSSLMode         = "verify-ca"
+	DefaultSocketDir       = "/cloudsql"
+	DefaultMaxConns        = 50
+	DefaultMaxConnLifetime = 10 * time.Minute
+
+	sslRootCertFile = "server-ca.pem"
+	sslCertFile     = "client-cert.pem"
+	sslKeyFile      = "client-key.pem"
+)
+
+// Config describes how to connect to the database. Set either Host, or
+// SocketDir and InstanceConnectionName for a Cloud SQL unix socket.
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+
+	// SSLMode is left out of the connection string when empty, as are the
+	// certificate files.
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	SocketDir              string
+	InstanceConnectionName string
+
+	MinConns        int32
+	MaxConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+
+	// StatementTimeout sets the statement_timeout of every connection. Zero
+	// keeps the server default.
+	StatementTimeout time.Duration
+	ApplicationName  string
+}
+
+// ConfigOption sets a field of a Config.
+type ConfigOption func(*Config)
+
+func WithHost(host string, port int) ConfigOption {
+	return func(c *Config) { c.Host, c.Port = host, port }
+}
+
+func WithCredentials(user, password string) ConfigOption {
+	return func(c *Config) { c.User, c.Password = user, password }
+}
+
+func WithDBName(name string) ConfigOption {
+	return func(c *Config) { c.DBName = name }
+}
+
+// WithSSL sets the ssl mode and the certificate files, all of which may be
+// empty.
+func WithSSL(mode, rootCert, cert, key string) ConfigOption {
+	return func(c *Config) { c.SSLMode, c.SSLRootCert, c.SSLCert, c.SSLKey = mode, rootCert, cert, key }
+}
+
+// WithSSLCertDir uses server-ca.pem, client-cert.pem and client-key.pem in dir.
+func WithSSLCertDir(mode, dir string) ConfigOption {
+	return WithSSL(mode, filepath.Join(dir, sslRootCertFile), filepath.Join(dir, sslCertFile), filepath.Join(dir, sslKeyFile))
+}
+
+// WithSocket connects through the Cloud SQL unix socket of instance in dir.
+func WithSocket(dir, instance string) ConfigOption {
+	return func(c *Config) { c.SocketDir, c.InstanceConnectionName = dir, instance }
+}
+
+func WithPoolSize(minConns, maxConns int32) ConfigOption {
+	return func(c *Config) { c.MinConns, c.MaxConns = minConns, maxConns }
+}
+
+func WithConnLifetime(maxLifetime, maxIdleTime time.Duration) ConfigOption {
+	return func(c *Config) { c.MaxConnLifetime, c.MaxConnIdleTime = maxLifetime, maxIdleTime }
+}
+
+func WithStatementTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) { c.StatementTimeout = d }
+}
+
+func WithApplicationName(name string) ConfigOption {
+	return func(c *Config) { c.ApplicationName = name }
+}
+
+// NewConfig returns the default Config with opts applied.
+func NewConfig(opts ...ConfigOption) Config {
+	c := Config{
+		Port:            DefaultPort,
+		DBName:          DefaultDBName,
+		MaxConns:        DefaultMaxConns,
+		MaxConnLifetime: DefaultMaxConnLifetime,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// ConfigFromEnv builds a Config from config.Default, the way SetupDatabase
+// always has for the profile:
+//
+//   - production: user, password and Cloud SQL instance from the secrets at
+//     USER_SECRET_PATH, DB_SECRET_PATH and INSTANCE_SECRET_PATH, through the
+//     socket in DB_SOCKET_DIR.
+//   - LOCAL_GETH: DB_USER, DB_PASS and DB_NAME_DEV on GETH_HOST_PATH without ssl.
+//   - anything else: the same, on the host in the secret at HOST_SECRET_PATH,
+//     verifying the certificates in SSL_CERT_FILE_PATH.
+//
+// Secrets are read with secrets.Get, so SECRET_PROVIDER picks where from.
+//
+// DB_PORT, DB_MIN_CONNS, DB_MAX_CONNS, DB_MAX_CONN_LIFETIME,
+// DB_MAX_CONN_IDLE_TIME, DB_STATEMENT_TIMEOUT and DB_APPLICATION_NAME override
+// the defaults, and opts override the environment.
+func ConfigFromEnv(opts ...ConfigOption) (Config, error) {
+	values, err := config.Default()
+	if err != nil {
+		return Config{}, err
+	}
+	c := NewConfig()
+	switch values.Profile() {
+	case config.ProfileProduction:
+		fromSecrets := map[string]*string{
+			"USER_SECRET_PATH":     &c.User,
+			"DB_SECRET_PATH":       &c.Password,
+			"INSTANCE_SECRET_PATH": &c.InstanceConnectionName,
+		}
+		for key, field := range fromSecrets {
+			value, err := secretFromEnv(values, key)
+			if err != nil {
+				return Config{}, err
+			}
+			*field = value
+		}
+		c.SocketDir = DefaultSocketDir
+		if dir, ok := values.Lookup("DB_SOCKET_DIR"); ok {
+			c.SocketDir = dir
+		}
+	case config.ProfileLocalGeth:
+		c.User, c.Password, c.DBName = values.Get("DB_USER"), values.Get("DB_PASS"), values.Get("DB_NAME_DEV")
+		c.Host = values.Get("GETH_HOST_PATH")
+	default:
+		c.User, c.Password, c.DBName = values.Get("DB_USER"), values.Get("DB_PASS"), values.Get("DB_NAME_DEV")
+		host, err := secretFromEnv(values, "HOST_SECRET_PATH")
+		if err != nil {
+			return Config{}, err
+		}
+		c.Host = host
+		WithSSLCertDir(DefaultSSLMode, values.Get("SSL_CERT_FILE_PATH"))(&c)
+	}
+	if err := c.overrideFromEnv(values); err != nil {
+		return Config{}, err
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c, nil
+}
+
+func secretFromEnv(values *config.Values, key string) (string, error) {
+	path := values.Get(key)
+	if path == "" {
+		return "", fmt.Errorf("database: environment variable %s not set", key)
+	}
+	value, err := secrets.Get(context.Background(), path)
+	if err != nil {
+		return "", fmt.Errorf("database: reading secret %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (c *Config) overrideFromEnv(values *config.Values) error {
+	ints := map[string]func(int64){
+		"DB_PORT":      func(v int64) { c.Port = int(v) },
+		"DB_MIN_CONNS": func(v int64) { c.MinConns = int32(v) },
+		"DB_MAX_CONNS": func(v int64) { c.MaxConns = int32(v) },
+	}
+	for key, set := range ints {
+		if s := values.Get(key); s != "" {
+			v, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				return fmt.Errorf("database: %s: %w", key, err)
+			}
+			set(v)
+		}
+	}
+	durations := map[string]*time.Duration{
+		"DB_MAX_CONN_LIFETIME":  &c.MaxConnLifetime,
+		"DB_MAX_CONN_IDLE_TIME": &c.MaxConnIdleTime,
+		"DB_STATEMENT_TIMEOUT":  &c.StatementTimeout,
+	}
+	for key, field := range durations {
+		if s := values.Get(key); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("database: %s: %w", key, err)
+			}
+			*field = d
+		}
+	}
+	if name := values.Get("DB_APPLICATION_NAME"); name != "" {
+		c.ApplicationName = name
+	}
+	return nil
+}
+
+// Validate reports the settings Connect cannot do without.
+func (c Config) Validate() error {
+	var errs []error
+	if c.Host == "" && (c.SocketDir == "" || c.InstanceConnectionName == "") {
+		errs = append(errs, errors.New("host, or socket dir and instance connection name, required"))
+	}
+	if c.User == "" {
+		errs = append(errs, errors.New("user required"))
+	}
+	if c.DBName == "" {
+		errs = append(errs, errors.New("database name required"))
+	}
+	if c.MaxConns < 0 || c.MinConns < 0 || (c.MaxConns > 0 && c.MinConns > c.MaxConns) {
+		errs = append(errs, fmt.Errorf("invalid pool size %d-%d", c.MinConns, c.MaxConns))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("database: invalid config: %w", err)
+	}
+	return nil
+}
+
+// DSN returns the keyword/value connection string for c. It holds the
+// password, so don't log it.
+func (c Config) DSN() string {
+	host, port := c.Host, c.Port
+	if host == "" {
+		host, port = c.SocketDir+"/"+c.InstanceConnectionName, 0
+	}
+	params := [][2]string{
+		{"host", host},
+		{"user", c.User},
+		{"password", c.Password},
+		{"dbname", c.DBName},
+	}
+	if port != 0 {
+		params = append(params, [2]string{"port", strconv.Itoa(port)})
+	}
+	if c.SSLMode != "" {
+		params = append(params, [2]string{"sslmode", c.SSLMode})
+	}
+	for _, p := range [][2]string{{"sslrootcert", c.SSLRootCert}, {"sslcert", c.SSLCert}, {"sslkey", c.SSLKey}} {
+		if p[1] != "" {
+			params = append(params, p)
+		}
+	}
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, p[0]+"="+quoteDSNValue(p[1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func quoteDSNValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+)
+
+func TestConfigDSN(t *testing.T) {
+	cfg := NewConfig(
+		WithHost("db.internal", 6432),
+		WithCredentials("lyle", "p@ss word's"),
+		WithSSLCertDir("verify-ca", "/certs"),
+	)
+	want := `host=db.internal user=lyle password='p@ss word\'s' dbname=assetdb port=6432 sslmode=verify-ca sslrootcert=/certs/server-ca.pem sslcert=/certs/client-cert.pem sslkey=/certs/client-key.pem`
+	if got := cfg.DSN(); got != want {
+		t.Errorf("DSN() = %q, want %q", got, want)
+	}
+
+	cfg = NewConfig(WithSocket("/cloudsql", "project:region:instance"), WithCredentials("lyle", "secret"))
+	want = `host=/cloudsql/project:region:instance user=lyle password=secret dbname=assetdb`
+	if got := cfg.DSN(); got != want {
+		t.Errorf("DSN() = %q, want %q", got, want)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := NewConfig().Validate(); err == nil {
+		t.Error("expected an error for a config without host or user")
+	}
+	if err := NewConfig(WithHost("localhost", 5432), WithCredentials("lyle", ""), WithPoolSize(10, 5)).Validate(); err == nil {
+		t.Error("expected an error for min conns above max conns")
+	}
+	if err := NewConfig(WithHost("localhost", 5432), WithCredentials("lyle", "")).Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("APP_ENV", "LOCAL_GETH")
+	t.Setenv("GETH_HOST_PATH", "geth-db")
+	t.Setenv("DB_USER", "lyle")
+	t.Setenv("DB_PASS", "secret")
+	t.Setenv("DB_NAME_DEV", "assetdb_dev")
+	t.Setenv("DB_MAX_CONNS", "20")
+	t.Setenv("DB_STATEMENT_TIMEOUT", "30s")
+	config.Reset()
+	defer config.Reset()
+	cfg, err := ConfigFromEnv(WithApplicationName("indexer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "geth-db" || cfg.Port != DefaultPort || cfg.User != "lyle" || cfg.DBName != "assetdb_dev" || cfg.SSLMode != "" {
+		t.Errorf("unexpected connection settings %+v", cfg)
+	}
+	if cfg.MaxConns != 20 || cfg.StatementTimeout != 30*time.Second || cfg.ApplicationName != "indexer" {
+		t.Errorf("unexpected pool settings %+v", cfg)
+	}
+
+	poolConfig, err := poolConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poolConfig.MaxConns != 20 || poolConfig.ConnConfig.RuntimeParams["statement_timeout"] != "30000" || poolConfig.ConnConfig.RuntimeParams["application_name"] != "indexer" {
+		t.Errorf("unexpected pool config %+v", poolConfig)
+	}
+
+	t.Setenv("DB_MAX_CONNS", "many")
+	config.Reset()
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("expected an error for an invalid DB_MAX_CONNS")
+	}
+}
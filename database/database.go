@@ -4,113 +4,129 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
-	"path/filepath"
+	"log/slog"
+	"strconv"
 	"time"
 
 	pgxdecimal "github.com/jackc/pgx-shopspring-decimal"
 	pgx5 "github.com/jackc/pgx/v5"
 	pgxpool5 "github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/joho/godotenv"
-	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
 	_ "github.com/lib/pq"
 )
 
-const (
-	port        = 5432
-	dbname      = "assetdb"
-	sslmode     = "verify-ca"
-	sslrootcert = `server-ca.pem`
-	sslcert     = `client-cert.pem`
-	sslkey      = `client-key.pem`
-)
-
 var DbConn *sql.DB
 var DbConnPgx *pgxpool5.Pool
 
-// func init() {
-// 	DbConn, DbConnPgx, _ = SetupDatabase()
-// }
+// DB is an open connection pool. SQL shares the connections of Pool.
+type DB struct {
+	Pool *pgxpool5.Pool
+	SQL  *sql.DB
+}
+
+// PoolStats is a snapshot of the pool.
+type PoolStats struct {
+	TotalConns           int32
+	AcquiredConns        int32
+	IdleConns            int32
+	ConstructingConns    int32
+	MaxConns             int32
+	AcquireCount         int64
+	AcquireDuration      time.Duration
+	EmptyAcquireCount    int64
+	CanceledAcquireCount int64
+}
 
-// SetupDatabase
-func SetupDatabase() (*sql.DB, *pgxpool5.Pool, error) {
-	godotenv.Load()
-	var err error
-	dbURI := "host=%s port=%d user=%s password=%s dbname=%s sslmode=%s  sslrootcert=%s sslcert=%s sslkey=%s"
-	socketDir, isSet := os.LookupEnv("DB_SOCKET_DIR")
-	if !isSet {
-		socketDir = "/cloudsql"
+// Connect opens a pool as described by cfg and pings the database.
+func Connect(ctx context.Context, cfg Config) (*DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
-	if utils.GetEnv() == "production" {
-		log.Println("In production!")
-		dbSecretPath := utils.MustGetenv("DB_SECRET_PATH")
-		// Set each value dynamically w/ Sprintf
-		password, err := utils.AccessSecretVersion(dbSecretPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		userSecretPath := utils.MustGetenv("USER_SECRET_PATH")
-		user, err := utils.AccessSecretVersion(userSecretPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		instanceSecretPath := utils.MustGetenv("INSTANCE_SECRET_PATH")
-		instanceConnectionName, err := utils.AccessSecretVersion(instanceSecretPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		dbURI = fmt.Sprintf("user=%s password=%s database=%s host=%s/%s", user, password, dbname, socketDir, instanceConnectionName)
-		if err != nil {
-			log.Fatal(fmt.Errorf("sql.Open: %v", err))
-		}
-	} else {
-		dir := utils.GoDotEnvVariable("SSL_CERT_FILE_PATH")
-		filepath.Abs(dir)
-		fmt.Println("in base : " + dir)
-		user := utils.GoDotEnvVariable("DB_USER")
-		password := utils.GoDotEnvVariable("DB_PASS")
-		dbname := utils.GoDotEnvVariable("DB_NAME_DEV")
-		hostSecretPath := utils.GoDotEnvVariable("HOST_SECRET_PATH")
-		var host string
-		if utils.GetEnv() == "LOCAL_GETH" {
-			host = utils.GoDotEnvVariable("GETH_HOST_PATH")
-			dbURI = "host=%s port=%d user=%s password=%s dbname=%s"
-			dbURI = fmt.Sprintf(dbURI, host, port, user, password, dbname)
-		} else {
-			host, err = utils.AccessSecretVersion(hostSecretPath)
-			if err != nil {
-				log.Fatal(err)
-			}
-			dbURI = fmt.Sprintf(dbURI, host, port, user, password, dbname, sslmode, filepath.Join(dir, sslrootcert), filepath.Join(dir, sslcert), filepath.Join(dir, sslkey))
-		}
+	poolConfig, err := poolConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
-	DbConn, err := sql.Open("pgx", dbURI)
-	// DbConn, err = sql.Open("postgres", connStr)
+	pool, err := pgxpool5.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("database: creating pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("database: ping: %w", err)
 	}
+	logging.Logger().InfoContext(ctx, "database connected",
+		slog.String("host", poolConfig.ConnConfig.Host),
+		slog.String("dbname", cfg.DBName),
+		slog.Int("max_conns", int(poolConfig.MaxConns)),
+	)
+	return &DB{Pool: pool, SQL: stdlib.OpenDBFromPool(pool)}, nil
+}
 
+func poolConfig(cfg Config) (*pgxpool5.Config, error) {
+	config, err := pgxpool5.ParseConfig(cfg.DSN())
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("database: parsing config: %w", err)
 	}
-	err = DbConn.Ping()
-	if err != nil {
-		panic(err)
+	if cfg.MaxConns > 0 {
+		config.MaxConns = cfg.MaxConns
+	}
+	config.MinConns = cfg.MinConns
+	if cfg.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = cfg.MaxConnIdleTime
 	}
-	DbConn.SetMaxOpenConns(5000)
-	DbConn.SetMaxIdleConns(5000)
-	DbConn.SetConnMaxLifetime(600 * time.Second)
-	config5, err := pgxpool5.ParseConfig(dbURI)
+	if cfg.StatementTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	if cfg.ApplicationName != "" {
+		config.ConnConfig.RuntimeParams["application_name"] = cfg.ApplicationName
+	}
+	config.AfterConnect = afterConnect
+	return config, nil
+}
+
+// Close closes SQL and waits for the connections of Pool to be released.
+func (d *DB) Close() {
+	d.SQL.Close()
+	d.Pool.Close()
+}
+
+// Stats returns a snapshot of the pool.
+func (d *DB) Stats() PoolStats {
+	s := d.Pool.Stat()
+	return PoolStats{
+		TotalConns:           s.TotalConns(),
+		AcquiredConns:        s.AcquiredConns(),
+		IdleConns:            s.IdleConns(),
+		ConstructingConns:    s.ConstructingConns(),
+		MaxConns:             s.MaxConns(),
+		AcquireCount:         s.AcquireCount(),
+		AcquireDuration:      s.AcquireDuration(),
+		EmptyAcquireCount:    s.EmptyAcquireCount(),
+		CanceledAcquireCount: s.CanceledAcquireCount(),
+	}
+}
+
+// SetupDatabase connects with ConfigFromEnv and sets DbConn and DbConnPgx.
+// New code should use Connect.
+func SetupDatabase() (*sql.DB, *pgxpool5.Pool, error) {
+	cfg, err := ConfigFromEnv()
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
-	config5.AfterConnect = func(ctx context.Context, conn *pgx5.Conn) error {
-		pgxdecimal.Register(conn.TypeMap())
-		return nil
+	db, err := Connect(context.Background(), cfg)
+	if err != nil {
+		return nil, nil, err
 	}
-	DbConnPgx, err = pgxpool5.NewWithConfig(context.Background(), config5)
+	DbConn, DbConnPgx = db.SQL, db.Pool
+	return db.SQL, db.Pool, nil
+}
 
-	return DbConn, DbConnPgx, nil
+// afterConnect registers the shopspring decimal type on every new connection.
+func afterConnect(ctx context.Context, conn *pgx5.Conn) error {
+	pgxdecimal.Register(conn.TypeMap())
+	return nil
 }
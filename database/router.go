@@ -0,0 +1,312 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pgx5 "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxpool5 "github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+const (
+	// DefaultMaxReplicationLag is how far behind the primary a replica may be
+	// before reads stop going to it.
+	DefaultMaxReplicationLag = 30 * time.Second
+	// DefaultHealthCheckInterval is how often Start checks the replicas.
+	DefaultHealthCheckInterval = 10 * time.Second
+)
+
+// replicationLagQuery returns how many seconds the replica is behind. A
+// replica that has replayed everything it received is not lagging, however
+// long ago the last write on the primary was.
+const replicationLagQuery = `SELECT COALESCE(CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END, 0)::float8`
+
+// Router is a utils.PgxIface that sends everything to the primary, except the
+// read-only Query and QueryRow calls of a context marked with WithReplica,
+// which go to healthy replicas in turn. Those reads fall back to the primary
+// when no replica is healthy. Replicas are assumed healthy until CheckHealth,
+// or the loop started by Start, finds otherwise.
+type Router struct {
+	primary  utils.PgxIface
+	replicas []*replica
+	next     atomic.Uint64
+
+	maxLag   time.Duration
+	interval time.Duration
+
+	stop sync.Once
+	done chan struct{}
+}
+
+type replica struct {
+	db      utils.PgxIface
+	healthy atomic.Bool
+
+	mu        sync.Mutex
+	lag       time.Duration
+	err       error
+	checkedAt time.Time
+}
+
+// ReplicaStatus is the result of the last health check of a replica.
+type ReplicaStatus struct {
+	Healthy   bool
+	Lag       time.Duration
+	Err       error
+	CheckedAt time.Time
+}
+
+// RouterOption customises a Router created with NewRouter.
+type RouterOption func(*Router)
+
+// WithMaxReplicationLag marks replicas further than d behind the primary as
+// unhealthy.
+func WithMaxReplicationLag(d time.Duration) RouterOption {
+	return func(r *Router) { r.maxLag = d }
+}
+
+// WithHealthCheckInterval sets how often Start checks the replicas.
+func WithHealthCheckInterval(d time.Duration) RouterOption {
+	return func(r *Router) { r.interval = d }
+}
+
+// NewRouter returns a Router over primary and replicas.
+func NewRouter(primary utils.PgxIface, replicas []utils.PgxIface, opts ...RouterOption) *Router {
+	r := &Router{
+		primary:  primary,
+		maxLag:   DefaultMaxReplicationLag,
+		interval: DefaultHealthCheckInterval,
+		done:     make(chan struct{}),
+	}
+	for _, db := range replicas {
+		rep := &replica{db: db}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// SetupRouter connects to the replicas at replicaURIs, checks them once and
+// starts checking them in the background. The router owns the replica pools
+// and the primary: Close closes them all.
+func SetupRouter(ctx context.Context, primary utils.PgxIface, replicaURIs []string, opts ...RouterOption) (*Router, error) {
+	var replicas []utils.PgxIface
+	for _, uri := range replicaURIs {
+		config, err := pgxpool5.ParseConfig(uri)
+		if err != nil {
+			closeAll(replicas)
+			return nil, err
+		}
+		config.AfterConnect = afterConnect
+		pool, err := pgxpool5.NewWithConfig(ctx, config)
+		if err != nil {
+			closeAll(replicas)
+			return nil, err
+		}
+		replicas = append(replicas, pool)
+	}
+	r := NewRouter(primary, replicas, opts...)
+	r.CheckHealth(ctx)
+	r.Start()
+	return r, nil
+}
+
+// ReplicaURIs returns the comma separated connection strings of the
+// DB_REPLICA_URIS config key.
+func ReplicaURIs() []string {
+	var uris []string
+	for _, uri := range strings.Split(config.Get("DB_REPLICA_URIS"), ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+func closeAll(dbs []utils.PgxIface) {
+	for _, db := range dbs {
+		db.Close()
+	}
+}
+
+type routeKey struct{}
+
+// WithReplica returns a context whose read-only queries may go to a replica.
+// Use it only for reads that tolerate replication lag: a replica may not have
+// the rows written just before, by this process or another.
+func WithReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, true)
+}
+
+// WithPrimary returns a context whose reads go to the primary, undoing a
+// WithReplica further up.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, false)
+}
+
+// Primary returns the connection writes go to.
+func (r *Router) Primary() utils.PgxIface {
+	return r.primary
+}
+
+// Start checks the replicas every health check interval until Close.
+func (r *Router) Start() {
+	if len(r.replicas) == 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+				r.CheckHealth(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// CheckHealth pings every replica and measures its replication lag.
+func (r *Router) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i, rep := range r.replicas {
+		wg.Add(1)
+		go func(i int, rep *replica) {
+			defer wg.Done()
+			lag, err := replicationLag(ctx, rep.db)
+			if err == nil && lag > r.maxLag {
+				err = fmt.Errorf("replication lag %s exceeds %s", lag, r.maxLag)
+			}
+			rep.mu.Lock()
+			rep.lag, rep.err, rep.checkedAt = lag, err, time.Now()
+			rep.mu.Unlock()
+			if was := rep.healthy.Swap(err == nil); was != (err == nil) {
+				if err != nil {
+					logging.Logger().WarnContext(ctx, "replica unhealthy", slog.Int("replica", i), logging.Err(err))
+				} else {
+					logging.Logger().InfoContext(ctx, "replica healthy", slog.Int("replica", i), logging.Duration(lag))
+				}
+			}
+		}(i, rep)
+	}
+	wg.Wait()
+}
+
+func replicationLag(ctx context.Context, db utils.PgxIface) (time.Duration, error) {
+	if err := db.Ping(ctx); err != nil {
+		return 0, err
+	}
+	var seconds float64
+	if err := db.QueryRow(ctx, replicationLagQuery).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Status returns the state of each replica, in the order they were given.
+func (r *Router) Status() []ReplicaStatus {
+	status := make([]ReplicaStatus, len(r.replicas))
+	for i, rep := range r.replicas {
+		rep.mu.Lock()
+		status[i] = ReplicaStatus{Healthy: rep.healthy.Load(), Lag: rep.lag, Err: rep.err, CheckedAt: rep.checkedAt}
+		rep.mu.Unlock()
+	}
+	return status
+}
+
+// reader returns the connection a read-only sql should run on.
+func (r *Router) reader(ctx context.Context, sql string) utils.PgxIface {
+	if replica, _ := ctx.Value(routeKey{}).(bool); !replica || len(r.replicas) == 0 || !isReadOnly(sql) {
+		return r.primary
+	}
+	start := r.next.Add(1) - 1
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep.db
+		}
+	}
+	return r.primary
+}
+
+// isReadOnly reports whether sql is a plain SELECT, or a WITH query without a
+// data-modifying statement, that takes no row locks and calls none of the
+// functions that only work on, or change state of, the primary.
+func isReadOnly(sql string) bool {
+	s := strings.Join(strings.Fields(strings.ToUpper(sql)), " ")
+	switch {
+	case strings.HasPrefix(s, "SELECT"):
+	case strings.HasPrefix(s, "WITH"):
+		for _, keyword := range []string{"INSERT ", "UPDATE ", "DELETE ", "MERGE "} {
+			if strings.Contains(s, keyword) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	for _, keyword := range []string{"FOR UPDATE", "FOR NO KEY UPDATE", "FOR SHARE", "FOR KEY SHARE"} {
+		if strings.Contains(s, keyword) {
+			return false
+		}
+	}
+	s = strings.ReplaceAll(s, " (", "(")
+	for _, function := range []string{"NEXTVAL(", "SETVAL(", "PG_ADVISORY_", "PG_TRY_ADVISORY_", "PG_NOTIFY(", "TXID_CURRENT(", "PG_CURRENT_XACT_ID("} {
+		if strings.Contains(s, function) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Router) Begin(ctx context.Context) (pgx5.Tx, error) {
+	return r.primary.Begin(ctx)
+}
+
+func (r *Router) CopyFrom(ctx context.Context, tableName pgx5.Identifier, columnNames []string, rowSrc pgx5.CopyFromSource) (int64, error) {
+	return r.primary.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+func (r *Router) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return r.primary.Exec(ctx, sql, arguments...)
+}
+
+func (r *Router) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx5.Row {
+	return r.reader(ctx, sql).QueryRow(ctx, sql, args...)
+}
+
+func (r *Router) Query(ctx context.Context, sql string, args ...interface{}) (pgx5.Rows, error) {
+	return r.reader(ctx, sql).Query(ctx, sql, args...)
+}
+
+func (r *Router) Ping(ctx context.Context) error {
+	return r.primary.Ping(ctx)
+}
+
+// Close stops the health checks and closes the primary and the replicas.
+func (r *Router) Close() {
+	r.stop.Do(func() { close(r.done) })
+	for _, rep := range r.replicas {
+		rep.db.Close()
+	}
+	r.primary.Close()
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func newMocks(t *testing.T, n int) []pgxmock.PgxPoolIface {
+	t.Helper()
+	mocks := make([]pgxmock.PgxPoolIface, n)
+	for i := range mocks {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mocks[i] = mock
+	}
+	return mocks
+}
+
+func TestRouterRoutesReadsToReplicas(t *testing.T) {
+	mocks := newMocks(t, 3)
+	primary, replica1, replica2 := mocks[0], mocks[1], mocks[2]
+	router := NewRouter(primary, []utils.PgxIface{replica1, replica2})
+	ctx := WithReplica(context.Background())
+
+	replica1.ExpectQuery("^SELECT id FROM assets$").WillReturnRows(replica1.NewRows([]string{"id"}).AddRow(1))
+	replica2.ExpectQuery("^SELECT COUNT").WillReturnRows(replica2.NewRows([]string{"count"}).AddRow(1))
+	primary.ExpectExec("^UPDATE assets").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	primary.ExpectQuery("^INSERT INTO assets").WillReturnRows(primary.NewRows([]string{"id"}).AddRow(2))
+	primary.ExpectQuery("^SELECT id FROM assets WHERE id = 1 FOR UPDATE$").WillReturnRows(primary.NewRows([]string{"id"}).AddRow(1))
+	primary.ExpectQuery("^SELECT name FROM assets$").WillReturnRows(primary.NewRows([]string{"name"}).AddRow("a"))
+	primary.ExpectQuery("^SELECT name FROM assets$").WillReturnRows(primary.NewRows([]string{"name"}).AddRow("a"))
+	primary.ExpectBegin()
+
+	rows, err := router.Query(ctx, "SELECT id FROM assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	var n int
+	if err := router.QueryRow(ctx, "SELECT COUNT(*) FROM assets").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := router.Exec(ctx, "UPDATE assets SET name = 'a'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.QueryRow(ctx, "INSERT INTO assets (name) VALUES ('b') RETURNING id").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.QueryRow(ctx, "SELECT id FROM assets WHERE id = 1 FOR UPDATE").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	var name string
+	if err := router.QueryRow(WithPrimary(ctx), "SELECT name FROM assets").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.QueryRow(context.Background(), "SELECT name FROM assets").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := router.Begin(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i, mock := range mocks {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("mock %d: there were unfulfilled expectations: %s", i, err)
+		}
+	}
+}
+
+func TestRouterHealthCheck(t *testing.T) {
+	mocks := newMocks(t, 3)
+	primary, lagging, down := mocks[0], mocks[1], mocks[2]
+	router := NewRouter(primary, []utils.PgxIface{lagging, down}, WithMaxReplicationLag(time.Minute))
+	ctx := WithReplica(context.Background())
+
+	lagging.ExpectPing()
+	lagging.ExpectQuery("^SELECT COALESCE").WillReturnRows(lagging.NewRows([]string{"lag"}).AddRow(float64(120)))
+	down.ExpectPing().WillReturnError(errors.New("connection refused"))
+	router.CheckHealth(ctx)
+
+	status := router.Status()
+	if status[0].Healthy || status[0].Lag != 2*time.Minute || status[0].Err == nil {
+		t.Errorf("expected the lagging replica to be unhealthy, got %+v", status[0])
+	}
+	if status[1].Healthy || status[1].Err == nil {
+		t.Errorf("expected the replica that is down to be unhealthy, got %+v", status[1])
+	}
+
+	primary.ExpectQuery("^SELECT id FROM assets$").WillReturnRows(primary.NewRows([]string{"id"}).AddRow(1))
+	rows, err := router.Query(ctx, "SELECT id FROM assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	lagging.ExpectPing()
+	lagging.ExpectQuery("^SELECT COALESCE").WillReturnRows(lagging.NewRows([]string{"lag"}).AddRow(float64(1)))
+	down.ExpectPing().WillReturnError(errors.New("connection refused"))
+	router.CheckHealth(ctx)
+	if !router.Status()[0].Healthy {
+		t.Errorf("expected the replica to recover, got %+v", router.Status()[0])
+	}
+	lagging.ExpectQuery("^SELECT id FROM assets$").WillReturnRows(lagging.NewRows([]string{"id"}).AddRow(1))
+	rows, err = router.Query(ctx, "SELECT id FROM assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	for i, mock := range mocks {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("mock %d: there were unfulfilled expectations: %s", i, err)
+		}
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SELECT * FROM assets", true},
+		{"\n\t\tselect count(*) from geth_swaps", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"WITH t AS (DELETE FROM assets RETURNING id) SELECT * FROM t", false},
+		{"SELECT * FROM assets FOR UPDATE", false},
+		{"SELECT nextval('assets_id_seq')", false},
+		{"SELECT * FROM assets\n\tFOR  UPDATE", false},
+		{"SELECT pg_advisory_lock($1)", false},
+		{"SELECT pg_try_advisory_xact_lock ($1)", false},
+		{"SELECT pg_notify('changes', $1)", false},
+		{"INSERT INTO assets (name) VALUES ($1) RETURNING id", false},
+		{"UPDATE assets SET name = $1", false},
+	}
+	for _, tt := range tests {
+		if got := isReadOnly(tt.sql); got != tt.want {
+			t.Errorf("isReadOnly(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package dberrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// The kinds of failure the data functions report. Test for them with
+// errors.Is; the driver error stays reachable through errors.Is/errors.As, so
+// callers needing the details can still get at *pgconn.PgError.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrTimeout      = errors.New("timeout")
+)
+
+// SQLSTATE codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeNotNullViolation     = "23502"
+	codeForeignKeyViolation  = "23503"
+	codeUniqueViolation      = "23505"
+	codeCheckViolation       = "23514"
+	codeExclusionViolation   = "23P01"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+	codeLockNotAvailable     = "55P03"
+	codeQueryCanceled        = "57014"
+	classDataException       = "22"
+)
+
+// Error is a database error classified as one of ErrNotFound, ErrConflict,
+// ErrInvalidInput or ErrTimeout.
+type Error struct {
+	// Kind is the sentinel the error matches.
+	Kind error
+	// Code is the SQLSTATE reported by Postgres, if any.
+	Code string
+	// Constraint is the violated constraint, if any.
+	Constraint string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Wrap classifies err. pgx.ErrNoRows becomes ErrNotFound; unique, exclusion
+// and foreign key violations, serialization failures and deadlocks become
+// ErrConflict; not-null and check violations and data exceptions (bad input
+// syntax, out of range values, ...) become ErrInvalidInput; cancelled
+// statements, lock timeouts and expired or cancelled contexts become
+// ErrTimeout, as Postgres reports a statement cancelled by the client and one
+// that ran into statement_timeout with the same code. Other errors, and errors
+// already classified, are returned unchanged.
+func Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	wrapped := &Error{Err: err}
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		wrapped.Kind = ErrNotFound
+	case errors.As(err, &pgErr):
+		wrapped.Code = pgErr.Code
+		wrapped.Constraint = pgErr.ConstraintName
+		wrapped.Kind = kindOf(pgErr.Code)
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || pgconn.Timeout(err):
+		wrapped.Kind = ErrTimeout
+	}
+	if wrapped.Kind == nil {
+		return err
+	}
+	return wrapped
+}
+
+func kindOf(code string) error {
+	switch code {
+	case codeUniqueViolation, codeExclusionViolation, codeForeignKeyViolation,
+		codeSerializationFailure, codeDeadlockDetected:
+		return ErrConflict
+	case codeNotNullViolation, codeCheckViolation:
+		return ErrInvalidInput
+	case codeQueryCanceled, codeLockNotAvailable:
+		return ErrTimeout
+	}
+	if len(code) == 5 && code[:2] == classDataException {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// InvalidInput returns an ErrInvalidInput error for arguments rejected before
+// reaching the database, e.g. a missing ID.
+func InvalidInput(format string, a ...interface{}) error {
+	return &Error{Kind: ErrInvalidInput, Err: fmt.Errorf(format, a...)}
+}
+
+// NilIfNotFound restores the former nil-on-missing behaviour of the single
+// row getters for callers that treat a missing row as a normal outcome:
+//
+//	found, err := dberrors.NilIfNotFound(asset.GetAssetByContractAddress(dbConnPgx, address))
+//	if found == nil && err == nil { ... }
+func NilIfNotFound[T any](entity *T, err error) (*T, error) {
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return entity, err
+}
+
+// IgnoreNotFound returns nil when err is ErrNotFound and err otherwise.
+func IgnoreNotFound(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ConflictError is returned by the Update*IfUnchanged functions when the row
+// was modified after the caller read it. Current is the row as it is now, so
+// the caller can show it or merge the edits. It matches ErrConflict.
+type ConflictError[T any] struct {
+	Current *T
+}
+
+func (e *ConflictError[T]) Error() string {
+	return ErrConflict.Error() + ": row was modified since it was read"
+}
+
+func (e *ConflictError[T]) Is(target error) bool {
+	return target == ErrConflict
+}
@@ -0,0 +1,99 @@
+package dberrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestWrap(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want error
+	}{
+		"no rows":           {pgx.ErrNoRows, ErrNotFound},
+		"wrapped no rows":   {fmt.Errorf("scan: %w", pgx.ErrNoRows), ErrNotFound},
+		"unique violation":  {&pgconn.PgError{Code: "23505", ConstraintName: "assets_pkey"}, ErrConflict},
+		"foreign key":       {&pgconn.PgError{Code: "23503"}, ErrConflict},
+		"serialization":     {&pgconn.PgError{Code: "40001"}, ErrConflict},
+		"not null":          {&pgconn.PgError{Code: "23502"}, ErrInvalidInput},
+		"invalid text":      {&pgconn.PgError{Code: "22P02"}, ErrInvalidInput},
+		"query canceled":    {&pgconn.PgError{Code: "57014"}, ErrTimeout},
+		"deadline exceeded": {context.DeadlineExceeded, ErrTimeout},
+		"context canceled":  {context.Canceled, ErrTimeout},
+		"wrapped canceled":  {fmt.Errorf("query: %w", context.Canceled), ErrTimeout},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Wrap(test.err)
+			if !errors.Is(err, test.want) {
+				t.Errorf("expected error '%s', got '%v'", test.want, err)
+			}
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected '%v' to wrap '%v'", err, test.err)
+			}
+		})
+	}
+}
+
+func TestWrapKeepsPgError(t *testing.T) {
+	err := Wrap(&pgconn.PgError{Code: "23505", ConstraintName: "assets_pkey"})
+	var classified *Error
+	if !errors.As(err, &classified) {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if classified.Code != "23505" || classified.Constraint != "assets_pkey" {
+		t.Errorf("unexpected code %q and constraint %q", classified.Code, classified.Constraint)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		t.Errorf("expected the *pgconn.PgError to stay reachable")
+	}
+	if Wrap(err) != err {
+		t.Errorf("expected an already classified error to be returned unchanged")
+	}
+}
+
+func TestWrapUnclassified(t *testing.T) {
+	if Wrap(nil) != nil {
+		t.Errorf("expected nil")
+	}
+	err := errors.New("Random SQL Error")
+	if Wrap(err) != err {
+		t.Errorf("expected unclassified error to be returned unchanged")
+	}
+	pgErr := &pgconn.PgError{Code: "42P01"}
+	if Wrap(pgErr) != error(pgErr) {
+		t.Errorf("expected undefined table error to be returned unchanged")
+	}
+}
+
+func TestNilIfNotFound(t *testing.T) {
+	value := 1
+	found, err := NilIfNotFound(&value, Wrap(pgx.ErrNoRows))
+	if found != nil || err != nil {
+		t.Errorf("expected nil, nil got %v, %v", found, err)
+	}
+	found, err = NilIfNotFound(&value, nil)
+	if found != &value || err != nil {
+		t.Errorf("expected value, nil got %v, %v", found, err)
+	}
+	other := errors.New("Random SQL Error")
+	if _, err = NilIfNotFound(&value, other); err != other {
+		t.Errorf("expected '%v', got '%v'", other, err)
+	}
+	if IgnoreNotFound(Wrap(pgx.ErrNoRows)) != nil || IgnoreNotFound(other) != other {
+		t.Errorf("unexpected IgnoreNotFound result")
+	}
+}
+
+func TestInvalidInput(t *testing.T) {
+	err := InvalidInput("%s has invalid ID", "asset")
+	if !errors.Is(err, ErrInvalidInput) || err.Error() != "invalid input: asset has invalid ID" {
+		t.Errorf("unexpected error '%v'", err)
+	}
+}
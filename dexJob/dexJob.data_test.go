@@ -7,47 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
-	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var columns = []string{
-	"id",                 //1
-	"job_id",             //2
-	"uuid",               //3
-	"name",               //4
-	"alternate_name",     //5
-	"start_date",         //6
-	"end_date",           //7
-	"description",        //8
-	"status_id",          //9
-	"chain_id",           //10
-	"exchange_id",        //11
-	"transaction_hashes", //12
-	"created_by",         //13
-	"created_at",         //14
-	"updated_by",         //15
-	"updated_at",         //16
-}
+var columns = DexTxnJobRepository.Columns()
 
-var columnsInsertList = []string{
-	"job_id",             //1
-	"uuid",               //2
-	"name",               //3
-	"alternate_name",     //4
-	"start_date",         //5
-	"end_date",           //6
-	"description",        //7
-	"status_id",          //8
-	"chain_id",           //9
-	"exchange_id",        //10
-	"transaction_hashes", //11
-	"created_by",         //12
-	"created_at",         //13
-	"updated_by",         //14
-	"updated_at",         //15
-}
+var columnsInsertList = DexTxnJobRepository.InsertColumns()
 var testTxns = []string{"0x1706fb8bf07d31852bbb0e5d1c8b0378c60b87a1fdccc36eab706603d67522d4", "0x0dc5e228f2520f74abfab4a97867dbf54e5bfc73e5a1d2a68aa79420ae1dd611"}
 
 var TestData1 = DexTxnJob{
@@ -90,29 +58,7 @@ var TestData2 = DexTxnJob{
 var TestAllData = []DexTxnJob{TestData1, TestData2}
 
 func AddDexTxnJobToMockRows(mock pgxmock.PgxPoolIface, dataList []DexTxnJob) *pgxmock.Rows {
-	rows := mock.NewRows(columns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                //1
-			data.JobID,             //2
-			data.UUID,              //3
-			data.Name,              //4
-			data.AlternateName,     //5
-			data.StartDate,         //6
-			data.EndDate,           //8
-			data.Description,       //7
-			data.StatusID,          //9
-			data.ChainID,           //10
-			data.ExchangeID,        //11
-			data.TransactionHashes, //12
-			data.CreatedBy,         //13
-			data.CreatedAt,         //14
-			data.UpdatedBy,         //15
-			data.UpdatedAt,         //16
-
-		)
-	}
-	return rows
+	return DexTxnJobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetDexTxnJob(t *testing.T) {
@@ -148,8 +94,8 @@ func TestGetDexTxnJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM dex_txn_jobs").WithArgs(dexTxnID).WillReturnRows(noRows)
 	foundDexTxnJob, err := GetDexTxnJob(mock, &dexTxnID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetDexTxnJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundDexTxnJob != nil {
 		t.Errorf("Expected DexTxnJob From Method GetDexTxnJob: to be empty but got this: %v", foundDexTxnJob)
@@ -397,17 +343,18 @@ func TestUpdateDexTxnJob(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE dex_txn_jobs").WithArgs(
-		targetData.Name,                        //1
-		targetData.AlternateName,               //2
-		targetData.StartDate,                   //3
-		targetData.EndDate,                     //4
-		targetData.Description,                 //5
-		targetData.StatusID,                    //6
-		targetData.ChainID,                     //7
-		targetData.ExchangeID,                  //8
-		pq.Array(targetData.TransactionHashes), //9
-		targetData.UpdatedBy,                   //10
-		targetData.ID,                          //11
+		targetData.JobID,             //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.StartDate,         //4
+		targetData.EndDate,           //5
+		targetData.Description,       //6
+		targetData.StatusID,          //7
+		targetData.ChainID,           //8
+		targetData.ExchangeID,        //9
+		targetData.TransactionHashes, //10
+		targetData.UpdatedBy,         //11
+		targetData.ID,                //12
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateDexTxnJob(mock, &targetData)
@@ -465,17 +412,18 @@ func TestUpdateDexTxnJobOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE dex_txn_jobs").WithArgs(
-		targetData.Name,                        //1
-		targetData.AlternateName,               //2
-		targetData.StartDate,                   //3
-		targetData.EndDate,                     //4
-		targetData.Description,                 //5
-		targetData.StatusID,                    //6
-		targetData.ChainID,                     //7
-		targetData.ExchangeID,                  //8
-		pq.Array(targetData.TransactionHashes), //9
-		targetData.UpdatedBy,                   //10
-		targetData.ID,                          //11
+		targetData.JobID,             //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.StartDate,         //4
+		targetData.EndDate,           //5
+		targetData.Description,       //6
+		targetData.StatusID,          //7
+		targetData.ChainID,           //8
+		targetData.ExchangeID,        //9
+		targetData.TransactionHashes, //10
+		targetData.UpdatedBy,         //11
+		targetData.ID,                //12
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -498,17 +446,17 @@ func TestInsertDexTxnJob(t *testing.T) {
 	targetData.Name = "New Name"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO dex_txn_jobs").WithArgs(
-		targetData.JobID,                       //1
-		targetData.Name,                        //2
-		targetData.AlternateName,               //3
-		targetData.StartDate,                   //4
-		targetData.EndDate,                     //5
-		targetData.Description,                 //6
-		targetData.StatusID,                    //7
-		targetData.ChainID,                     //8
-		targetData.ExchangeID,                  //9
-		pq.Array(targetData.TransactionHashes), //10
-		targetData.CreatedBy,                   //11
+		targetData.JobID,             //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.StartDate,         //4
+		targetData.EndDate,           //5
+		targetData.Description,       //6
+		targetData.StatusID,          //7
+		targetData.ChainID,           //8
+		targetData.ExchangeID,        //9
+		targetData.TransactionHashes, //10
+		targetData.CreatedBy,         //11
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 	chainID, err := InsertDexTxnJob(mock, &targetData)
@@ -553,17 +501,17 @@ func TestInsertDexTxnJobOnFailure(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO dex_txn_jobs").WithArgs(
-		targetData.JobID,                       //1
-		targetData.Name,                        //2
-		targetData.AlternateName,               //3
-		targetData.StartDate,                   //4
-		targetData.EndDate,                     //5
-		targetData.Description,                 //6
-		targetData.StatusID,                    //7
-		targetData.ChainID,                     //8
-		targetData.ExchangeID,                  //9
-		pq.Array(targetData.TransactionHashes), //10
-		targetData.CreatedBy,                   //11
+		targetData.JobID,             //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.StartDate,         //4
+		targetData.EndDate,           //5
+		targetData.Description,       //6
+		targetData.StatusID,          //7
+		targetData.ChainID,           //8
+		targetData.ExchangeID,        //9
+		targetData.TransactionHashes, //10
+		targetData.CreatedBy,         //11
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	chainID, err := InsertDexTxnJob(mock, &targetData)
@@ -589,17 +537,17 @@ func TestInsertDexTxnJobOnFailureOnCommit(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO dex_txn_jobs").WithArgs(
-		targetData.JobID,                       //1
-		targetData.Name,                        //2
-		targetData.AlternateName,               //3
-		targetData.StartDate,                   //4
-		targetData.EndDate,                     //5
-		targetData.Description,                 //6
-		targetData.StatusID,                    //7
-		targetData.ChainID,                     //8
-		targetData.ExchangeID,                  //9
-		pq.Array(targetData.TransactionHashes), //10
-		targetData.CreatedBy,                   //11
+		targetData.JobID,             //1
+		targetData.Name,              //2
+		targetData.AlternateName,     //3
+		targetData.StartDate,         //4
+		targetData.EndDate,           //5
+		targetData.Description,       //6
+		targetData.StatusID,          //7
+		targetData.ChainID,           //8
+		targetData.ExchangeID,        //9
+		targetData.TransactionHashes, //10
+		targetData.CreatedBy,         //11
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -661,8 +609,8 @@ func TestGetDexTxnJobListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"job_id = 1", "chain_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM dex_txn_jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("job_id", "1"), filter.Equal("chain_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM dex_txn_jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundChains, err := GetDexTxnJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetDexTxnJobListByPagination", err)
@@ -688,8 +636,8 @@ func TestGetDexTxnJobListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"job_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM dex_txn_jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("job_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM dex_txn_jobs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundChains, err := GetDexTxnJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetDexTxnJobListByPagination", err)
@@ -712,9 +660,9 @@ func TestGetDexTxnJobListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"job_id = 1"}
+	filters := filter.All(filter.Equal("job_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM dex_txn_jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM dex_txn_jobs").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundChains, err := GetDexTxnJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetDexTxnJobListByPagination", err)
@@ -1,59 +1,72 @@
 package discord
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"sync"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/secrets"
 )
 
 const (
 	DISCORD_PATH = "DISCORD_LYLE_PATH"
 )
 
+// DiscordClientSession is the session SendMessage uses. It is created on the
+// first SendMessage unless set before.
 var DiscordClientSession *discordgo.Session
 
-func init() {
-	initializeClient()
-}
+var sessionMu sync.Mutex
 
-func initializeClient() {
-	var err error
+// session returns DiscordClientSession, creating it when it is nil. A failed
+// attempt is retried on the next call.
+func session() (*discordgo.Session, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	if DiscordClientSession != nil {
+		return DiscordClientSession, nil
+	}
 	botToken, err := GetDiscordBotToken()
-	DiscordClientSession, err = discordgo.New("Bot " + botToken)
 	if err != nil {
-		log.Fatalf("Invalid bot parameters: %v", err)
+		return nil, err
+	}
+	s, err := discordgo.New("Bot " + botToken)
+	if err != nil {
+		err = fmt.Errorf("discord: invalid bot parameters: %w", err)
+		logging.ReturnedError(context.Background(), "session", err)
+		return nil, err
 	}
+	DiscordClientSession = s
+	return s, nil
 }
 
 func GetDiscordBotToken() (string, error) {
-	if utils.GetEnv() == "production" {
-		log.Println("In production!")
-		discordPath := utils.MustGetenv(DISCORD_PATH)
-		// Set each value dynamically w/ Sprintf
-		token, err := utils.AccessSecretVersion(discordPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return token, nil
-	} else {
-		discordPath := utils.GoDotEnvVariable(DISCORD_PATH)
-		token, err := utils.AccessSecretVersion(discordPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return token, nil
+	discordPath := config.Get(DISCORD_PATH)
+	if discordPath == "" {
+		err := fmt.Errorf("%s not set", DISCORD_PATH)
+		logging.ReturnedError(context.Background(), "GetDiscordBotToken", err)
+		return "", err
 	}
+	token, err := secrets.Get(context.Background(), discordPath)
+	if err != nil {
+		logging.ReturnedError(context.Background(), "GetDiscordBotToken", err)
+		return "", err
+	}
+	return token, nil
 }
 
 func SendMessage(channelID string, message string) error {
-	if DiscordClientSession == nil {
-		initializeClient()
+	s, err := session()
+	if err != nil {
+		return err
 	}
-	DiscordClientSession.Identify.Intents = discordgo.IntentGuildMembers
-	_, err := DiscordClientSession.ChannelMessageSend(channelID, message)
+	s.Identify.Intents = discordgo.IntentGuildMembers
+	_, err = s.ChannelMessageSend(channelID, message)
 	if err != nil {
-		log.Fatal(err)
+		logging.ReturnedError(context.Background(), "SendMessage", err)
 		return err
 	}
 	return nil
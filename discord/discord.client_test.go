@@ -0,0 +1,19 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+)
+
+func TestSendMessageWithoutConfig(t *testing.T) {
+	t.Setenv(DISCORD_PATH, "")
+	config.Reset()
+	defer config.Reset()
+	if err := SendMessage("channel", "message"); err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if DiscordClientSession != nil {
+		t.Errorf("expected no session without a bot token")
+	}
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// EventRepository implements the standard queries on the change_events table.
+// The rows are written by the notify_change and notify_changes triggers, see
+// migrations 0009_change_events and 0015_change_events_statement_triggers.
+var EventRepository = repository.New[Event]("change_events")
+
+// GetEventsSince returns the events after the cursor after that pass filters,
+// in cursor order. Only the events of transactions older than the xmin of the
+// query's snapshot are returned: those transactions have all ended, so no
+// event can appear before the last one returned any more. Events of newer
+// transactions are returned by a later call, once every transaction that
+// started before them has ended.
+func GetEventsSince(dbConnPgx utils.PgxIface, after Cursor, filters Filter) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetEventsSinceCtx(ctx, dbConnPgx, after, filters)
+}
+
+func GetEventsSinceCtx(ctx context.Context, dbConnPgx utils.PgxIface, after Cursor, filters Filter) ([]Event, error) {
+	conditions := []string{
+		"(xact_id, id) > ($1, $2)",
+		"xact_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint",
+	}
+	args := []interface{}{after.XactID, after.ID}
+	if len(filters.Entities) > 0 {
+		args = append(args, filters.Entities)
+		conditions = append(conditions, fmt.Sprintf("entity = ANY($%d)", len(args)))
+	}
+	if len(filters.Operations) > 0 {
+		args = append(args, filters.Operations)
+		conditions = append(conditions, fmt.Sprintf("operation = ANY($%d)", len(args)))
+	}
+	if len(filters.BaseAssetIDs) > 0 {
+		args = append(args, filters.BaseAssetIDs)
+		conditions = append(conditions, fmt.Sprintf("base_asset_id = ANY($%d)", len(args)))
+	}
+	return EventRepository.Select(ctx, dbConnPgx, "WHERE "+strings.Join(conditions, " AND ")+" ORDER BY xact_id, id", args...)
+}
+
+// GetCurrentCursor returns a cursor after the events of every transaction
+// older than the oldest running one. Starting from it may replay a few events
+// committed just before, but never skips one committed after.
+func GetCurrentCursor(dbConnPgx utils.PgxIface) (Cursor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetCurrentCursorCtx(ctx, dbConnPgx)
+}
+
+func GetCurrentCursorCtx(ctx context.Context, dbConnPgx utils.PgxIface) (Cursor, error) {
+	var xmin int64
+	if err := dbConnPgx.QueryRow(ctx, `SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`).Scan(&xmin); err != nil {
+		logging.ReturnedError(ctx, "GetCurrentCursor", err, logging.Entity("change_events"))
+		return Cursor{}, dberrors.Wrap(err)
+	}
+	return Cursor{XactID: xmin}, nil
+}
+
+// PruneEvents deletes the events created before before and returns how many
+// were deleted. Subscribers that were disconnected for longer cannot catch up
+// on them any more.
+func PruneEvents(dbConnPgx utils.PgxIface, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return PruneEventsCtx(ctx, dbConnPgx, before)
+}
+
+func PruneEventsCtx(ctx context.Context, dbConnPgx utils.PgxIface, before time.Time) (int64, error) {
+	tx, err := dbConnPgx.Begin(ctx)
+	if err != nil {
+		logging.ReturnedError(ctx, "PruneEvents: begin", err, logging.Entity("change_events"))
+		return 0, dberrors.Wrap(err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM change_events WHERE created_at < $1`, before)
+	if err != nil {
+		tx.Rollback(ctx)
+		logging.ReturnedError(ctx, "PruneEvents", err, logging.Entity("change_events"))
+		return 0, dberrors.Wrap(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, dberrors.Wrap(err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,201 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kfukue/lyle-labs-libraries/v2/migrations"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var TestData1 = Event{
+	ID:          6,
+	Entity:      EntityGethSwaps,
+	Operation:   OperationInsert,
+	EntityID:    utils.Ptr[int](100),
+	EntityUUID:  utils.Ptr[string]("880607ab-2833-4ad7-a231-b983a61c7b39"),
+	BaseAssetID: utils.Ptr[int](1),
+	BlockNumber: utils.Ptr[uint64](19000000),
+	CreatedAt:   utils.SampleCreatedAtTime,
+	XactID:      91,
+}
+
+var TestData2 = Event{
+	ID:         7,
+	Entity:     EntityJobs,
+	Operation:  OperationUpdate,
+	EntityID:   utils.Ptr[int](3),
+	EntityUUID: utils.Ptr[string]("01ef85e8-2c26-441e-8c7f-71d79518ad72"),
+	CreatedAt:  utils.SampleCreatedAtTime,
+	XactID:     91,
+}
+
+var TestAllData = []Event{TestData1, TestData2}
+
+func TestGetEventsSince(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	mockRows := EventRepository.AddToMockRows(mock, TestAllData)
+	mock.ExpectQuery("^SELECT (.+) FROM change_events\\s+WHERE \\(xact_id, id\\) > \\(\\$1, \\$2\\) AND xact_id < pg_snapshot_xmin\\(pg_current_snapshot\\(\\)\\)(.+) ORDER BY xact_id, id").
+		WithArgs(int64(90), int64(5)).WillReturnRows(mockRows)
+	foundEvents, err := GetEventsSince(mock, Cursor{XactID: 90, ID: 5}, Filter{})
+	if err != nil {
+		t.Fatalf("an error '%s' in GetEventsSince", err)
+	}
+	if cmp.Equal(foundEvents, TestAllData) == false {
+		t.Errorf("Expected Events From Method GetEventsSince: %v is different from actual %v", foundEvents, TestAllData)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetEventsSinceWithFilters(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	filters := Filter{Entities: []string{EntityGethSwaps}, Operations: []string{OperationInsert}, BaseAssetIDs: []int{1}}
+	mockRows := EventRepository.AddToMockRows(mock, []Event{TestData1})
+	mock.ExpectQuery("^SELECT (.+) FROM change_events\\s+WHERE (.+) AND entity = ANY\\(\\$3\\) AND operation = ANY\\(\\$4\\) AND base_asset_id = ANY\\(\\$5\\) ORDER BY xact_id, id").WithArgs(
+		int64(90), int64(5), filters.Entities, filters.Operations, filters.BaseAssetIDs,
+	).WillReturnRows(mockRows)
+	if _, err = GetEventsSince(mock, Cursor{XactID: 90, ID: 5}, filters); err != nil {
+		t.Fatalf("an error '%s' in GetEventsSince", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetCurrentCursor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectQuery("^SELECT pg_snapshot_xmin\\(pg_current_snapshot\\(\\)\\)").WillReturnRows(mock.NewRows([]string{"xmin"}).AddRow(int64(92)))
+	cursor, err := GetCurrentCursor(mock)
+	if err != nil {
+		t.Fatalf("an error '%s' in GetCurrentCursor", err)
+	}
+	if cursor != (Cursor{XactID: 92}) {
+		t.Errorf("expected the cursor before transaction 92, got %+v", cursor)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCursorBefore(t *testing.T) {
+	tests := []struct {
+		c, other Cursor
+		want     bool
+	}{
+		{Cursor{XactID: 90, ID: 9}, Cursor{XactID: 91, ID: 8}, true},
+		{Cursor{XactID: 91, ID: 8}, Cursor{XactID: 90, ID: 9}, false},
+		{Cursor{XactID: 91, ID: 8}, Cursor{XactID: 91, ID: 9}, true},
+		{Cursor{XactID: 91, ID: 9}, Cursor{XactID: 91, ID: 9}, false},
+	}
+	for _, test := range tests {
+		if got := test.c.Before(test.other); got != test.want {
+			t.Errorf("expected %+v.Before(%+v) to be %v", test.c, test.other, test.want)
+		}
+	}
+}
+
+func TestPruneEvents(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	before := utils.SampleCreatedAtTime.Add(-24 * time.Hour)
+	mock.ExpectBegin()
+	mock.ExpectExec("^DELETE FROM change_events WHERE created_at < \\$1").WithArgs(before).WillReturnResult(pgxmock.NewResult("DELETE", 12))
+	mock.ExpectCommit()
+	pruned, err := PruneEvents(mock, before)
+	if err != nil {
+		t.Fatalf("an error '%s' in PruneEvents", err)
+	}
+	if pruned != 12 {
+		t.Errorf("expected 12 pruned events, got %d", pruned)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPruneEventsOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^DELETE FROM change_events").WithArgs(utils.SampleCreatedAtTime).WillReturnError(fmt.Errorf("Random SQL Error"))
+	mock.ExpectRollback()
+	if _, err = PruneEventsCtx(context.Background(), mock, utils.SampleCreatedAtTime); err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrationNotifiesEntities(t *testing.T) {
+	all, err := migrations.All()
+	if err != nil {
+		t.Fatalf("an error '%s' in migrations.All", err)
+	}
+	for _, migration := range all {
+		if migration.Name != "change_events" {
+			continue
+		}
+		if !strings.Contains(migration.Up, fmt.Sprintf("pg_notify('%s'", Channel)) {
+			t.Errorf("expected migration %d_%s to notify on %s", migration.Version, migration.Name, Channel)
+		}
+		for _, entity := range []string{EntityGethSwaps, EntityGethTransfers, EntityGethTrades, EntityMarketData, EntityJobs} {
+			if !strings.Contains(migration.Up, fmt.Sprintf("ON %s\n  FOR EACH ROW EXECUTE FUNCTION notify_change(", entity)) {
+				t.Errorf("expected migration %d_%s to notify changes of %s", migration.Version, migration.Name, entity)
+			}
+		}
+		return
+	}
+	t.Fatalf("expected a change_events migration")
+}
+
+// The bulk written tables notify once per statement.
+func TestMigrationNotifiesStatements(t *testing.T) {
+	all, err := migrations.All()
+	if err != nil {
+		t.Fatalf("an error '%s' in migrations.All", err)
+	}
+	for _, migration := range all {
+		if migration.Name != "change_events_statement_triggers" {
+			continue
+		}
+		if !strings.Contains(migration.Up, fmt.Sprintf("pg_notify('%s'", Channel)) {
+			t.Errorf("expected migration %d_%s to notify on %s", migration.Version, migration.Name, Channel)
+		}
+		for _, entity := range []string{EntityGethSwaps, EntityGethTransfers} {
+			for _, operation := range []string{OperationInsert, OperationUpdate, OperationDelete} {
+				trigger := fmt.Sprintf("AFTER %s ON %s\n", operation, entity)
+				if !strings.Contains(migration.Up, trigger) {
+					t.Errorf("expected migration %d_%s to notify %s of %s", migration.Version, migration.Name, operation, entity)
+				}
+			}
+		}
+		return
+	}
+	t.Fatalf("expected a change_events_statement_triggers migration")
+}
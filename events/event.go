@@ -0,0 +1,82 @@
+package events
+
+import "time"
+
+// Event is one change to a row of a table with a notify_change or
+// notify_changes trigger.
+type Event struct {
+	ID          int64     `json:"id" db:"id"`                     //1
+	Entity      string    `json:"entity" db:"entity"`             //2
+	Operation   string    `json:"operation" db:"operation"`       //3
+	EntityID    *int      `json:"entityId" db:"entity_id"`        //4
+	EntityUUID  *string   `json:"entityUuid" db:"entity_uuid"`    //5
+	BaseAssetID *int      `json:"baseAssetId" db:"base_asset_id"` //6
+	BlockNumber *uint64   `json:"blockNumber" db:"block_number"`  //7
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`      //8
+	XactID      int64     `json:"xactId" db:"xact_id"`            //9
+}
+
+// Cursor is a position in the change_events table. Events are ordered by the
+// transaction that wrote them, then by id, which unlike the id alone is an
+// order no committed event can later be inserted before; see GetEventsSince.
+type Cursor struct {
+	XactID int64
+	ID     int64
+}
+
+// Cursor returns the position of e.
+func (e Event) Cursor() Cursor {
+	return Cursor{XactID: e.XactID, ID: e.ID}
+}
+
+// Before reports whether c comes before other.
+func (c Cursor) Before(other Cursor) bool {
+	return c.XactID < other.XactID || (c.XactID == other.XactID && c.ID < other.ID)
+}
+
+// Operations recorded in Event.Operation.
+const (
+	OperationInsert = "INSERT"
+	OperationUpdate = "UPDATE"
+	OperationDelete = "DELETE"
+)
+
+// The tables that emit events.
+const (
+	EntityGethSwaps     = "geth_swaps"
+	EntityGethTransfers = "geth_transfers"
+	EntityGethTrades    = "geth_trades"
+	EntityMarketData    = "market_data"
+	EntityJobs          = "jobs"
+)
+
+// Filter selects the events a subscriber receives. Empty fields match
+// everything.
+type Filter struct {
+	Entities     []string
+	Operations   []string
+	BaseAssetIDs []int
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Event) bool {
+	if len(f.Entities) > 0 && !contains(f.Entities, e.Entity) {
+		return false
+	}
+	if len(f.Operations) > 0 && !contains(f.Operations, e.Operation) {
+		return false
+	}
+	if len(f.BaseAssetIDs) > 0 && (e.BaseAssetID == nil || !contains(f.BaseAssetIDs, *e.BaseAssetID)) {
+		return false
+	}
+	return true
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
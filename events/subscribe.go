@@ -0,0 +1,200 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// Channel is the NOTIFY channel of the notify_change and notify_changes
+// triggers.
+const Channel = "lyle_changes"
+
+var (
+	DefaultReconnectDelay = 5 * time.Second
+	DefaultBuffer         = 100
+	DefaultPollInterval   = time.Second
+)
+
+// Conn is the dedicated connection a Subscriber listens on. *pgx.Conn
+// implements it.
+type Conn interface {
+	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
+	WaitForNotification(context.Context) (*pgconn.Notification, error)
+	Close(context.Context) error
+}
+
+// Subscriber streams change events. Notifications on Channel only wake it up:
+// the events are read from the change_events table with GetEventsSince, on
+// every notification of a matching event and whenever it (re)connects, so a
+// subscription sees every event once and in cursor order, including those of
+// transactions that committed out of id order.
+type Subscriber struct {
+	// DB runs the catch-up queries.
+	DB utils.PgxIface
+	// Connect opens the listening connection. It is called again after the
+	// connection is lost.
+	Connect func(ctx context.Context) (Conn, error)
+	// ReconnectDelay is the wait before reconnecting.
+	ReconnectDelay time.Duration
+	// Buffer is the capacity of the channel returned by Subscribe.
+	Buffer int
+	// PollInterval is how often the table is read again while a notified
+	// event is held back by an older transaction that is still running.
+	PollInterval time.Duration
+	// After makes Subscribe start after this cursor instead of at
+	// GetCurrentCursor, e.g. to resume from a stored position.
+	After Cursor
+}
+
+// NewSubscriber returns a Subscriber that queries pool and listens on
+// connections taken out of it.
+func NewSubscriber(pool *pgxpool.Pool) *Subscriber {
+	return &Subscriber{
+		DB: pool,
+		Connect: func(ctx context.Context) (Conn, error) {
+			conn, err := pool.Acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			// LISTEN must not leak into the pool
+			return conn.Hijack(), nil
+		},
+		ReconnectDelay: DefaultReconnectDelay,
+		Buffer:         DefaultBuffer,
+		PollInterval:   DefaultPollInterval,
+	}
+}
+
+// Subscribe returns the events passing filters. The channel is closed when
+// ctx is done.
+func (s *Subscriber) Subscribe(ctx context.Context, filters Filter) <-chan Event {
+	events := make(chan Event, s.Buffer)
+	started := s.After != (Cursor{})
+	sub := &subscription{Subscriber: s, filters: filters, events: events, cursor: s.After, notified: s.After, started: started}
+	go sub.run(ctx)
+	return events
+}
+
+// subscription is the state of one Subscribe call. cursor is the last event
+// delivered and notified the last matching event announced on Channel; while
+// cursor is before notified the table is polled. started is set once cursor
+// is known, from After or the first connection.
+type subscription struct {
+	*Subscriber
+	filters  Filter
+	events   chan<- Event
+	cursor   Cursor
+	notified Cursor
+	started  bool
+}
+
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.events)
+	for {
+		err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logging.Error(ctx, "Subscribe: connection lost", err, logging.Entity("change_events"), logging.Duration(s.ReconnectDelay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.ReconnectDelay):
+		}
+	}
+}
+
+func (s *subscription) listen(ctx context.Context) error {
+	conn, err := s.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	// listen before catching up so nothing falls between the two
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	if s.started {
+		if err := s.catchUp(ctx); err != nil {
+			return err
+		}
+	} else {
+		if s.cursor, err = GetCurrentCursorCtx(ctx, s.DB); err != nil {
+			return err
+		}
+		s.notified = s.cursor
+		s.started = true
+	}
+	for {
+		notification, err := s.wait(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if notification != nil {
+			var event Event
+			if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+				logging.Error(ctx, "Subscribe: invalid payload", err, logging.Entity("change_events"))
+				continue
+			}
+			if !s.filters.Match(event) || !s.notified.Before(event.Cursor()) {
+				continue
+			}
+			s.notified = event.Cursor()
+		}
+		if err := s.catchUp(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// wait returns the next notification, or nil once PollInterval passed while
+// a notified event has not been delivered yet.
+func (s *subscription) wait(ctx context.Context, conn Conn) (*pgconn.Notification, error) {
+	if !s.cursor.Before(s.notified) || s.PollInterval <= 0 {
+		return conn.WaitForNotification(ctx)
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, s.PollInterval)
+	defer cancel()
+	notification, err := conn.WaitForNotification(pollCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(pollCtx.Err(), context.DeadlineExceeded) {
+		return nil, nil
+	}
+	return notification, err
+}
+
+// catchUp delivers the events after cursor that GetEventsSince returns.
+func (s *subscription) catchUp(ctx context.Context) error {
+	missed, err := GetEventsSinceCtx(ctx, s.DB, s.cursor, s.filters)
+	if err != nil {
+		return err
+	}
+	logging.Debug(ctx, "Subscribe: caught up", logging.Entity("change_events"), logging.Rows(int64(len(missed))))
+	for _, event := range missed {
+		if err := s.deliver(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *subscription) deliver(ctx context.Context, event Event) error {
+	if s.filters.Match(event) {
+		select {
+		case s.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.cursor = event.Cursor()
+	if s.notified.Before(s.cursor) {
+		s.notified = s.cursor
+	}
+	return nil
+}
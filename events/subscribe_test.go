@@ -0,0 +1,189 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// fakeConn delivers notifications until they run out and then reports the
+// connection as lost, or with hold waits for ctx.
+type fakeConn struct {
+	notifications chan *pgconn.Notification
+	listened      string
+	hold          bool
+}
+
+func newFakeConn(t *testing.T, events ...Event) *fakeConn {
+	conn := &fakeConn{notifications: make(chan *pgconn.Notification, len(events))}
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("an error '%s' in json.Marshal", err)
+		}
+		conn.notifications <- &pgconn.Notification{Channel: Channel, Payload: string(payload)}
+	}
+	close(conn.notifications)
+	return conn
+}
+
+func (c *fakeConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	c.listened = sql
+	return pgconn.NewCommandTag("LISTEN"), nil
+}
+
+func (c *fakeConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case notification, ok := <-c.notifications:
+		if ok {
+			return notification, nil
+		}
+		if !c.hold {
+			return nil, errors.New("connection reset by peer")
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeConn) Close(context.Context) error {
+	return nil
+}
+
+func newTestSubscriber(db utils.PgxIface, conns ...*fakeConn) *Subscriber {
+	return &Subscriber{
+		DB: db,
+		Connect: func(ctx context.Context) (Conn, error) {
+			if len(conns) == 0 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			conn := conns[0]
+			conns = conns[1:]
+			return conn, nil
+		},
+		Buffer:       10,
+		PollInterval: 10 * time.Millisecond,
+	}
+}
+
+func receive(t *testing.T, events <-chan Event, n int) []int64 {
+	ids := make([]int64, 0, n)
+	for len(ids) < n {
+		select {
+		case event := <-events:
+			ids = append(ids, event.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d events, got %v", n, ids)
+		}
+	}
+	return ids
+}
+
+func TestSubscribe(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	swap := func(id, xactID int64) Event {
+		event := TestData1
+		event.ID, event.XactID = id, xactID
+		return event
+	}
+	job := TestData2
+	job.ID, job.XactID = 7, 101
+	filters := Filter{Entities: []string{EntityGethSwaps}}
+	first := newFakeConn(t, swap(6, 101), job)
+	// 9 is notified while 8, written before it by an older transaction, is
+	// uncommitted: the table is polled until 8 is returned too
+	second := newFakeConn(t, swap(9, 103))
+	second.hold = true
+	eventsSince := "^SELECT (.+) FROM change_events\\s+WHERE \\(xact_id, id\\) > \\(\\$1, \\$2\\) AND xact_id < pg_snapshot_xmin(.+) AND entity = ANY\\(\\$3\\)"
+	mock.ExpectQuery("^SELECT pg_snapshot_xmin").WillReturnRows(mock.NewRows([]string{"xmin"}).AddRow(int64(100)))
+	mock.ExpectQuery(eventsSince).WithArgs(int64(100), int64(0), filters.Entities).WillReturnRows(EventRepository.AddToMockRows(mock, []Event{swap(6, 101)}))
+	// reconnected
+	mock.ExpectQuery(eventsSince).WithArgs(int64(101), int64(6), filters.Entities).WillReturnRows(EventRepository.AddToMockRows(mock, []Event{}))
+	// notified of 9
+	mock.ExpectQuery(eventsSince).WithArgs(int64(101), int64(6), filters.Entities).WillReturnRows(EventRepository.AddToMockRows(mock, []Event{}))
+	// polled
+	mock.ExpectQuery(eventsSince).WithArgs(int64(101), int64(6), filters.Entities).WillReturnRows(EventRepository.AddToMockRows(mock, []Event{swap(8, 102), swap(9, 103)}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := newTestSubscriber(mock, first, second).Subscribe(ctx, filters)
+	ids := receive(t, events, 3)
+	if ids[0] != 6 || ids[1] != 8 || ids[2] != 9 {
+		t.Errorf("expected events [6 8 9], got %v", ids)
+	}
+	if first.listened != "LISTEN "+Channel || second.listened != "LISTEN "+Channel {
+		t.Errorf("expected every connection to listen on %s", Channel)
+	}
+	cancel()
+	for range events {
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSubscribeAfterCursor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectQuery("^SELECT (.+) FROM change_events\\s+WHERE \\(xact_id, id\\) > \\(\\$1, \\$2\\) AND xact_id < pg_snapshot_xmin(.+) ORDER BY xact_id, id").
+		WithArgs(int64(90), int64(5)).WillReturnRows(EventRepository.AddToMockRows(mock, TestAllData))
+	conn := newFakeConn(t)
+	conn.hold = true
+	subscriber := newTestSubscriber(mock, conn)
+	subscriber.After = Cursor{XactID: 90, ID: 5}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := subscriber.Subscribe(ctx, Filter{})
+	ids := receive(t, events, 2)
+	if ids[0] != TestData1.ID || ids[1] != TestData2.ID {
+		t.Errorf("expected the missed events, got %v", ids)
+	}
+	cancel()
+	for range events {
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	tests := map[string]struct {
+		filter Filter
+		want   bool
+	}{
+		"empty":             {Filter{}, true},
+		"entity":            {Filter{Entities: []string{EntityGethSwaps, EntityGethTransfers}}, true},
+		"other entity":      {Filter{Entities: []string{EntityJobs}}, false},
+		"operation":         {Filter{Operations: []string{OperationDelete}}, false},
+		"base asset":        {Filter{BaseAssetIDs: []int{1}}, true},
+		"other base asset":  {Filter{BaseAssetIDs: []int{2}}, false},
+		"entity and asset":  {Filter{Entities: []string{EntityGethSwaps}, BaseAssetIDs: []int{1}}, true},
+		"entity, not asset": {Filter{Entities: []string{EntityGethSwaps}, BaseAssetIDs: []int{3}}, false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.filter.Match(TestData1); got != test.want {
+				t.Errorf("expected Match to be %v, got %v", test.want, got)
+			}
+		})
+	}
+	if (Filter{BaseAssetIDs: []int{1}}).Match(TestData2) {
+		t.Errorf("expected an event without base asset not to match a base asset filter")
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -77,49 +79,12 @@ var TestData2 = Exchange{
 var TestAllData = []Exchange{TestData1, TestData2}
 
 func AddExchangeToMockRows(mock pgxmock.PgxPoolIface, dataList []Exchange) *pgxmock.Rows {
-	rows := mock.NewRows(columns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,             //1
-			data.UUID,           //2
-			data.Name,           //3
-			data.AlternateName,  //4
-			data.ExchangeTypeID, //5
-			data.Url,            //6
-			data.StartDate,      //7
-			data.EndDate,        //8
-			data.Description,    //9
-			data.CreatedBy,      //10
-			data.CreatedAt,      //11
-			data.UpdatedBy,      //12
-			data.UpdatedAt,      //13
-
-		)
-	}
-	return rows
+	return ExchangeRepository.AddToMockRows(mock, dataList)
 }
 
-var columnsExchangeChains = []string{
-	"uuid",        //1
-	"exchange_id", //2
-	"chain_id",    //3
-	"description", //4
-	"created_by",  //5
-	"created_at",  //6
-	"updated_by",  //7
-	"updated_at",  //8
-}
+var columnsExchangeChains = ExchangeChainRepository.Columns()
 
-var columnsInsertExchangeChains = []string{
-	"uuid",        //1
-	"exchange_id", //2
-	"chain_id",    //3
-	"description", //4
-	"created_by",  //5
-	"created_at",  //6
-	"updated_by",  //7
-	"updated_at",  //8
-}
+var columnsInsertExchangeChains = ExchangeChainRepository.InsertColumns()
 
 var data1ExchangeChain = ExchangeChain{
 	UUID:        "880607ab-2833-4ad7-a231-b983a61c7b39",
@@ -145,21 +110,7 @@ var data2ExchangeChain = ExchangeChain{
 var allDataExchangeEchains = []ExchangeChain{data1ExchangeChain, data2ExchangeChain}
 
 func AddExchangeChainToMockRows(mock pgxmock.PgxPoolIface, dataList []ExchangeChain) *pgxmock.Rows {
-	rows := mock.NewRows(columnsExchangeChains)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.UUID,        //1
-			data.ExchangeID,  //2
-			data.ChainID,     //3
-			data.Description, //4
-			data.CreatedBy,   //5
-			data.CreatedAt,   //6
-			data.UpdatedBy,   //7
-			data.UpdatedAt,   //8
-
-		)
-	}
-	return rows
+	return ExchangeChainRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetExchange(t *testing.T) {
@@ -195,8 +146,8 @@ func TestGetExchangeForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(columns)
 	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WithArgs(exchangeID).WillReturnRows(noRows)
 	foundExchange, err := GetExchange(mock, &exchangeID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetExchange", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundExchange != nil {
 		t.Errorf("Expected Exchange From Method GetExchange: to be empty but got this: %v", foundExchange)
@@ -257,7 +208,7 @@ func TestRemoveExchange(t *testing.T) {
 	targetData := TestData1
 	exchangeID := targetData.ID
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM exchanges").WithArgs(*exchangeID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("^UPDATE exchanges SET deleted_at").WithArgs(*exchangeID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = RemoveExchange(mock, exchangeID)
 	if err != nil {
@@ -293,7 +244,7 @@ func TestRemoveExchangeOnFailure(t *testing.T) {
 	defer mock.Close()
 	exchangeID := -1
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM exchanges").WithArgs(exchangeID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
+	mock.ExpectExec("^UPDATE exchanges SET deleted_at").WithArgs(exchangeID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 	mock.ExpectRollback()
 	err = RemoveExchange(mock, &exchangeID)
 	if err == nil {
@@ -303,6 +254,25 @@ func TestRemoveExchangeOnFailure(t *testing.T) {
 		t.Errorf("There awere unfulfilled expectations: %s", err)
 	}
 }
+
+func TestRestoreExchange(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE exchanges SET deleted_at = NULL").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	err = RestoreExchange(mock, targetData.ID)
+	if err != nil {
+		t.Fatalf("an error '%s' in RestoreExchange", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
 func TestGetExchangeList(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -312,7 +282,7 @@ func TestGetExchangeList(t *testing.T) {
 	dataList := []Exchange{TestData1, TestData2}
 	mockRows := AddExchangeToMockRows(mock, dataList)
 	ids := []int{*TestData1.ID, *TestData2.ID}
-	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WithArgs(ids).WillReturnRows(mockRows)
 	foundExchanges, err := GetExchangeList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetExchangeList", err)
@@ -335,7 +305,7 @@ func TestGetExchangeListForErr(t *testing.T) {
 	}
 	defer mock.Close()
 	ids := []int{*TestData1.ID, *TestData2.ID}
-	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundExchanges, err := GetExchangeList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetExchangeList", err)
@@ -356,7 +326,7 @@ func TestGetExchangeListForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
 	ids := []int{*TestData1.ID, *TestData2.ID}
-	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundExchanges, err := GetExchangeList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetExchangeList", err)
@@ -586,7 +556,7 @@ func TestUpdateExchangeOnFailure(t *testing.T) {
 		targetData.EndDate,        //6
 		targetData.Description,    //7
 		targetData.UpdatedBy,      //8
-		targetData.ID,             //91
+		targetData.ID,             //9
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -853,7 +823,7 @@ func TestInsertExchangeChain(t *testing.T) {
 		targetData.ChainID,     //3
 		targetData.Description, //4
 		targetData.CreatedBy,   //5
-	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	).WillReturnRows(pgxmock.NewRows([]string{"exchange_id", "chain_id"}).AddRow(*targetData.ExchangeID, *targetData.ChainID))
 	mock.ExpectCommit()
 	chainID, err := InsertExchangeChain(mock, &targetData)
 	if chainID < 0 {
@@ -930,7 +900,7 @@ func TestInsertExchangeChainOnFailureOnCommit(t *testing.T) {
 		targetData.ChainID,     //3
 		targetData.Description, //4
 		targetData.CreatedBy,   //5
-	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	).WillReturnRows(pgxmock.NewRows([]string{"exchange_id", "chain_id"}).AddRow(*targetData.ExchangeID, *targetData.ChainID))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	chainID, err := InsertExchangeChain(mock, &targetData)
@@ -991,8 +961,8 @@ func TestGetExchangeListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"exchange_type_id = 1", "id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("exchange_type_id", "1"), filter.Equal("id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundExcchangeList, err := GetExchangeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetExchangeListByPagination", err)
@@ -1017,8 +987,8 @@ func TestGetExchangeListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"exchange_type_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("exchange_type_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundExcchangeList, err := GetExchangeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetExchangeListByPagination", err)
@@ -1041,9 +1011,9 @@ func TestGetExchangeListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"exchange_type_id = 1"}
+	filters := filter.All(filter.Equal("exchange_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM exchanges").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundExcchangeList, err := GetExchangeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetExchangeListByPagination", err)
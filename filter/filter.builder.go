@@ -0,0 +1,233 @@
+package filter
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+var timeLayouts = []string{time.RFC3339Nano, utils.LayoutPostgres, utils.LayoutISO}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Paginate returns the WHERE, ORDER BY and OFFSET/LIMIT clauses shared by the
+// Get*ListByPagination functions together with their bind parameters. Every
+// field and sort name is checked against columns, and all values are passed as
+// $n parameters. _start and _end follow refine: both must be positive for the
+// OFFSET/LIMIT to be applied, and _end must not be before _start.
+func Paginate(columns Columns, _start, _end *int, _order, _sort string, where *Group) (string, []interface{}, error) {
+	return PaginateScoped(columns, "", _start, _end, _order, _sort, where)
+}
+
+// PaginateScoped is Paginate with scope, a trusted condition such as
+// "deleted_at IS NULL", ANDed with the filters. An empty scope adds nothing.
+func PaginateScoped(columns Columns, scope string, _start, _end *int, _order, _sort string, where *Group) (string, []interface{}, error) {
+	clause, args, err := Where(columns, where, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if scope != "" {
+		if clause == "" {
+			clause = " WHERE " + scope + " "
+		} else {
+			clause = " WHERE " + scope + " AND " + strings.TrimPrefix(clause, " WHERE ")
+		}
+	}
+	orderBy, err := OrderBy(columns, _sort, _order)
+	if err != nil {
+		return "", nil, err
+	}
+	clause += orderBy
+	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
+		if *_end < *_start {
+			return "", nil, fmt.Errorf("%w: _end %d is before _start %d", ErrInvalidValue, *_end, *_start)
+		}
+		pageSize := *_end - *_start
+		args = append(args, *_start, pageSize)
+		clause += fmt.Sprintf(" OFFSET $%d LIMIT $%d ", len(args)-1, len(args))
+	}
+	return clause, args, nil
+}
+
+// Where renders g as a " WHERE ..." clause. Placeholders are numbered after
+// the parameters already in args so the clause can be appended to a query
+// that has its own bind parameters.
+func Where(columns Columns, g *Group, args []interface{}) (string, []interface{}, error) {
+	if g.IsEmpty() {
+		return "", args, nil
+	}
+	clause, args, err := g.build(columns, args)
+	if err != nil {
+		return "", nil, err
+	}
+	return " WHERE " + clause + " ", args, nil
+}
+
+// OrderBy validates refine's _sort and _order (both may be comma separated
+// lists) and renders them as an ORDER BY clause.
+func OrderBy(columns Columns, _sort, _order string) (string, error) {
+	if _order == "" || _sort == "" {
+		return "", nil
+	}
+	fields := strings.Split(_sort, ",")
+	orders := strings.Split(_order, ",")
+	terms := make([]string, 0, len(fields))
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if !columns.Has(field) {
+			return "", fmt.Errorf("%w: %q", ErrUnknownField, field)
+		}
+		order := orders[len(orders)-1]
+		if i < len(orders) {
+			order = orders[i]
+		}
+		order = strings.ToUpper(strings.TrimSpace(order))
+		if order != "ASC" && order != "DESC" {
+			return "", fmt.Errorf("%w: %q", ErrInvalidOrder, order)
+		}
+		terms = append(terms, field+" "+order)
+	}
+	return " ORDER BY " + strings.Join(terms, ", ") + " ", nil
+}
+
+// Validate reports the first field or operator in g that is not allowed by
+// columns, without rendering any SQL.
+func (g *Group) Validate(columns Columns) error {
+	_, _, err := g.build(columns, nil)
+	return err
+}
+
+func (g *Group) build(columns Columns, args []interface{}) (string, []interface{}, error) {
+	logic := g.Logic
+	if logic == "" {
+		logic = And
+	} else if logic != And && logic != Or {
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidOperator, logic)
+	}
+	parts := make([]string, 0, len(g.Conditions)+len(g.Groups))
+	for _, condition := range g.Conditions {
+		var part string
+		var err error
+		part, args, err = condition.build(columns, args)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, part)
+	}
+	for _, child := range g.Groups {
+		if child.IsEmpty() {
+			continue
+		}
+		var part string
+		var err error
+		part, args, err = child.build(columns, args)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, " "+string(logic)+" ") + ")", args, nil
+}
+
+func (c Condition) build(columns Columns, args []interface{}) (string, []interface{}, error) {
+	t, ok := columns[c.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnknownField, c.Field)
+	}
+	if len(c.Values) == 0 {
+		return "", nil, fmt.Errorf("%w: no value for %s", ErrInvalidValue, c.Field)
+	}
+	placeholder := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if c.Op == Like {
+		column := c.Field
+		if indirect(t).Kind() != reflect.String {
+			column += "::text"
+		}
+		pattern := "%" + likeEscaper.Replace(c.Values[0]) + "%"
+		return fmt.Sprintf(`%s LIKE %s ESCAPE '\'`, column, placeholder(pattern)), args, nil
+	}
+	values := make([]interface{}, 0, len(c.Values))
+	for _, raw := range c.Values {
+		v, err := convert(t, strings.TrimSpace(raw))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %s=%q: %v", ErrInvalidValue, c.Field, raw, err)
+		}
+		values = append(values, v)
+	}
+	switch c.Op {
+	case Eq, Ne, Gte, Lte:
+		if len(values) > 1 && (c.Op == Eq || c.Op == Ne) {
+			break
+		}
+		if len(values) > 1 {
+			return "", nil, fmt.Errorf("%w: %s accepts a single value", ErrInvalidValue, c.Field)
+		}
+		symbol := map[Operator]string{Eq: "=", Ne: "<>", Gte: ">=", Lte: "<="}[c.Op]
+		return fmt.Sprintf("%s %s %s", c.Field, symbol, placeholder(values[0])), args, nil
+	case In:
+	default:
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidOperator, c.Op)
+	}
+	placeholders := make([]string, 0, len(values))
+	for _, v := range values {
+		placeholders = append(placeholders, placeholder(v))
+	}
+	keyword := "IN"
+	if c.Op == Ne {
+		keyword = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s (%s)", c.Field, keyword, strings.Join(placeholders, ", ")), args, nil
+}
+
+// likeEscaper makes the wildcards of a Like value match literally, as the
+// value is matched as a substring and not as a pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// convert parses a query string value into the Go type the column scans into
+// so pgx encodes it with the right OID.
+func convert(t reflect.Type, raw string) (interface{}, error) {
+	t = indirect(t)
+	if t == reflect.TypeOf(time.Time{}) {
+		for _, layout := range timeLayouts {
+			if parsed, err := time.Parse(layout, raw); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported time format")
+	}
+	if reflect.PointerTo(t).Implements(scannerType) {
+		v := reflect.New(t)
+		if err := v.Interface().(sql.Scanner).Scan(raw); err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	}
+	return nil, fmt.Errorf("column type %s is not filterable", t)
+}
@@ -0,0 +1,155 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/shopspring/decimal"
+)
+
+type testEntity struct {
+	ID          *int             `db:"id"`
+	Name        string           `db:"name"`
+	Ticker      string           `db:"ticker"`
+	ChainID     *int             `db:"chain_id"`
+	IsDefault   *bool            `db:"is_default"`
+	BlockNumber *uint64          `db:"block_number"`
+	TotalSupply *decimal.Decimal `db:"total_supply"`
+	TopicsStr   []string         `db:"topics_str"`
+	CreatedAt   time.Time        `db:"created_at"`
+	Ignored     string           `db:"-"`
+	NoTag       string
+}
+
+func TestColumnsOf(t *testing.T) {
+	columns := ColumnsOf[testEntity]()
+	for _, name := range []string{"id", "name", "ticker", "chain_id", "is_default", "block_number", "total_supply", "topics_str", "created_at"} {
+		if !columns.Has(name) {
+			t.Errorf("ColumnsOf[testEntity]() is missing %s", name)
+		}
+	}
+	if len(columns) != 9 {
+		t.Errorf("ColumnsOf[testEntity]() = %d columns; want 9", len(columns))
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	where := All(
+		Equal("chain_id", "1"),
+		Contains("name", "eth"),
+		OneOf("id", "1", "2"),
+		GreaterOrEqual("created_at", "2022-08-05"),
+		NotEqual("is_default", "true"),
+	)
+	where.Groups = []*Group{Any(Equal("ticker", "ETH"), LessOrEqual("block_number", "100"))}
+	_start, _end := 20, 30
+	sql, args, err := Paginate(ColumnsOf[testEntity](), &_start, &_end, "desc,asc", "name,id", where)
+	if err != nil {
+		t.Fatalf("an error '%s' in Paginate", err)
+	}
+	expectedSQL := " WHERE (chain_id = $1 AND name LIKE $2 ESCAPE '\\' AND id IN ($3, $4) AND created_at >= $5 AND is_default <> $6 AND (ticker = $7 OR block_number <= $8))  ORDER BY name DESC, id ASC  OFFSET $9 LIMIT $10 "
+	if sql != expectedSQL {
+		t.Errorf("Paginate sql = %q; want %q", sql, expectedSQL)
+	}
+	createdAt, _ := time.Parse(utils.LayoutISO, "2022-08-05")
+	expectedArgs := []interface{}{int64(1), "%eth%", int64(1), int64(2), createdAt, true, "ETH", uint64(100), 20, 10}
+	if !cmp.Equal(args, expectedArgs) {
+		t.Errorf("Paginate args = %v; want %v", args, expectedArgs)
+	}
+}
+
+func TestPaginateWithoutFilters(t *testing.T) {
+	_start, _end := 0, 10
+	sql, args, err := Paginate(ColumnsOf[testEntity](), &_start, &_end, "", "", nil)
+	if err != nil {
+		t.Fatalf("an error '%s' in Paginate", err)
+	}
+	if sql != "" || len(args) != 0 {
+		t.Errorf("Paginate = %q, %v; want empty", sql, args)
+	}
+}
+
+func TestPaginateScoped(t *testing.T) {
+	_start, _end := 20, 30
+	sql, args, err := PaginateScoped(ColumnsOf[testEntity](), "deleted_at IS NULL", &_start, &_end, "asc", "id", Any(Equal("ticker", "ETH"), Equal("ticker", "BTC")))
+	if err != nil {
+		t.Fatalf("an error '%s' in PaginateScoped", err)
+	}
+	expectedSQL := " WHERE deleted_at IS NULL AND (ticker = $1 OR ticker = $2)  ORDER BY id ASC  OFFSET $3 LIMIT $4 "
+	if sql != expectedSQL {
+		t.Errorf("PaginateScoped sql = %q; want %q", sql, expectedSQL)
+	}
+	if len(args) != 4 {
+		t.Errorf("PaginateScoped args = %v", args)
+	}
+	sql, _, err = PaginateScoped(ColumnsOf[testEntity](), "deleted_at IS NULL", nil, nil, "", "", nil)
+	if err != nil {
+		t.Fatalf("an error '%s' in PaginateScoped", err)
+	}
+	if sql != " WHERE deleted_at IS NULL " {
+		t.Errorf("PaginateScoped sql = %q", sql)
+	}
+}
+
+func TestWhereContinuesArgs(t *testing.T) {
+	sql, args, err := Where(ColumnsOf[testEntity](), All(Contains("chain_id", "1"), Equal("total_supply", "1.5")), []interface{}{"existing"})
+	if err != nil {
+		t.Fatalf("an error '%s' in Where", err)
+	}
+	if sql != ` WHERE (chain_id::text LIKE $2 ESCAPE '\' AND total_supply = $3) ` {
+		t.Errorf("Where sql = %q", sql)
+	}
+	if len(args) != 3 || !args[2].(*decimal.Decimal).Equal(decimal.RequireFromString("1.5")) {
+		t.Errorf("Where args = %v", args)
+	}
+}
+
+func TestWhereEscapesLike(t *testing.T) {
+	sql, args, err := Where(ColumnsOf[testEntity](), All(Contains("name", `5%_a\b`)), nil)
+	if err != nil {
+		t.Fatalf("an error '%s' in Where", err)
+	}
+	if sql != ` WHERE (name LIKE $1 ESCAPE '\') ` {
+		t.Errorf("Where sql = %q", sql)
+	}
+	if len(args) != 1 || args[0] != `%5\%\_a\\b%` {
+		t.Errorf("Where args = %v", args)
+	}
+}
+
+func TestPaginateErrors(t *testing.T) {
+	columns := ColumnsOf[testEntity]()
+	tests := []struct {
+		name   string
+		order  string
+		sort   string
+		where  *Group
+		target error
+	}{
+		{"unknown field", "", "", All(Equal("name; DROP TABLE assets", "1")), ErrUnknownField},
+		{"unknown sort", "ASC", "id; DROP TABLE assets", nil, ErrUnknownField},
+		{"invalid order", "ASC;", "id", nil, ErrInvalidOrder},
+		{"invalid int", "", "", All(Equal("chain_id", "1 OR 1=1")), ErrInvalidValue},
+		{"invalid time", "", "", All(Equal("created_at", "yesterday")), ErrInvalidValue},
+		{"not filterable", "", "", All(Equal("topics_str", "a")), ErrInvalidValue},
+		{"multiple gte", "", "", All(Condition{Field: "id", Op: Gte, Values: []string{"1", "2"}}), ErrInvalidValue},
+		{"invalid operator", "", "", All(Condition{Field: "id", Op: "gt", Values: []string{"1"}}), ErrInvalidOperator},
+		{"invalid logic", "", "", &Group{Logic: "XOR", Conditions: []Condition{Equal("id", "1")}}, ErrInvalidOperator},
+	}
+	for _, test := range tests {
+		_, _, err := Paginate(columns, nil, nil, test.order, test.sort, test.where)
+		if !errors.Is(err, test.target) {
+			t.Errorf("%s: expected %v but got '%v'", test.name, test.target, err)
+		}
+	}
+	_start, _end := 20, 10
+	if _, _, err := Paginate(columns, &_start, &_end, "", "", nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("_end before _start: expected ErrInvalidValue but got '%v'", err)
+	}
+	if err := All(Equal("missing", "1")).Validate(columns); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Validate: expected ErrUnknownField but got '%v'", err)
+	}
+}
@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Columns is the whitelist of column names an entity can be filtered and
+// sorted by, together with the Go type each column scans into.
+type Columns map[string]reflect.Type
+
+var columnsCache sync.Map
+
+// ColumnsOf returns the columns of T taken from its `db` struct tags, the same
+// tags pgx.RowToStructByName uses to scan the entity.
+func ColumnsOf[T any]() Columns {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if cached, ok := columnsCache.Load(t); ok {
+		return cached.(Columns)
+	}
+	columns := Columns{}
+	collectColumns(t, columns)
+	columnsCache.Store(t, columns)
+	return columns
+}
+
+func collectColumns(t reflect.Type, columns Columns) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("db")
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			collectColumns(field.Type, columns)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		columns[name] = field.Type
+	}
+}
+
+// NewColumns builds a whitelist from a plain list of column names (e.g. a
+// package's DBColumns). Values of these columns are bound as strings.
+func NewColumns(names []string) Columns {
+	columns := Columns{}
+	for _, name := range names {
+		columns[name] = reflect.TypeOf("")
+	}
+	return columns
+}
+
+func (c Columns) Has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
@@ -0,0 +1,200 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+)
+
+// Operator is the comparison applied by a Condition. The values match the
+// suffixes refine sends in its query strings (name_like, id_in, ...).
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	Ne   Operator = "ne"
+	Gte  Operator = "gte"
+	Lte  Operator = "lte"
+	Like Operator = "like"
+	In   Operator = "in"
+)
+
+// Logic joins the members of a Group.
+type Logic string
+
+const (
+	And Logic = "AND"
+	Or  Logic = "OR"
+)
+
+// The filter errors match dberrors.ErrInvalidInput, so callers can report
+// them as bad requests.
+var (
+	ErrUnknownField    = dberrors.InvalidInput("filter: unknown field")
+	ErrInvalidOperator = dberrors.InvalidInput("filter: invalid operator")
+	ErrInvalidValue    = dberrors.InvalidInput("filter: invalid value")
+	ErrInvalidOrder    = dberrors.InvalidInput("filter: invalid order")
+)
+
+var operators = []Operator{Eq, Ne, Gte, Lte, Like, In}
+
+// reserved query string keys handled by the pagination arguments rather than
+// turned into conditions
+var reservedKeys = []string{"_start", "_end", "_sort", "_order", "_logic"}
+
+// Condition is a single comparison of a column against one or more values.
+// Values are kept as strings and converted to the column's Go type when the
+// SQL is built.
+type Condition struct {
+	Field  string   `json:"field"`
+	Op     Operator `json:"operator"`
+	Values []string `json:"values"`
+}
+
+// Group is a list of conditions and nested groups joined by Logic (AND when
+// empty).
+type Group struct {
+	Logic      Logic       `json:"logic"`
+	Conditions []Condition `json:"conditions"`
+	Groups     []*Group    `json:"groups"`
+}
+
+func Equal(field string, values ...string) Condition {
+	return Condition{Field: field, Op: Eq, Values: values}
+}
+
+func NotEqual(field string, values ...string) Condition {
+	return Condition{Field: field, Op: Ne, Values: values}
+}
+
+func GreaterOrEqual(field, value string) Condition {
+	return Condition{Field: field, Op: Gte, Values: []string{value}}
+}
+
+func LessOrEqual(field, value string) Condition {
+	return Condition{Field: field, Op: Lte, Values: []string{value}}
+}
+
+func Contains(field, value string) Condition {
+	return Condition{Field: field, Op: Like, Values: []string{value}}
+}
+
+func OneOf(field string, values ...string) Condition {
+	return Condition{Field: field, Op: In, Values: values}
+}
+
+// All returns a group matching rows that satisfy every condition.
+func All(conditions ...Condition) *Group {
+	return &Group{Logic: And, Conditions: conditions}
+}
+
+// Any returns a group matching rows that satisfy at least one condition.
+func Any(conditions ...Condition) *Group {
+	return &Group{Logic: Or, Conditions: conditions}
+}
+
+func (g *Group) IsEmpty() bool {
+	if g == nil {
+		return true
+	}
+	if len(g.Conditions) > 0 {
+		return false
+	}
+	for _, child := range g.Groups {
+		if !child.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse turns refine style query strings into a Group.
+//
+//	name_like=eth&chain_id=1            name LIKE '%eth%' AND chain_id = 1
+//	id=1&id=2 or id_in=1,2              id IN (1, 2)
+//	_logic=or&name_like=eth&ticker=ETH  name LIKE '%eth%' OR ticker = 'ETH'
+//	chain_id=1&or[name_like]=eth&or[ticker_like]=eth
+//	                                    chain_id = 1 AND (name LIKE .. OR ticker LIKE ..)
+//
+// Keys starting with an underscore other than _logic are ignored so the whole
+// URL query can be passed in. Field names are not checked here; they are
+// validated against the entity's columns when the SQL is built.
+func Parse(query map[string][]string) (*Group, error) {
+	root := &Group{Logic: And}
+	if logic, ok := query["_logic"]; ok && len(logic) > 0 {
+		l, err := parseLogic(logic[0])
+		if err != nil {
+			return nil, err
+		}
+		root.Logic = l
+	}
+	nested := map[Logic]*Group{}
+	// sort the keys so the generated SQL (and its bind parameters) is stable
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := query[key]
+		if strings.HasPrefix(key, "_") || len(values) == 0 {
+			continue
+		}
+		target := root
+		if open := strings.Index(key, "["); open > 0 && strings.HasSuffix(key, "]") {
+			l, err := parseLogic(key[:open])
+			if err != nil {
+				return nil, err
+			}
+			if nested[l] == nil {
+				nested[l] = &Group{Logic: l}
+			}
+			target = nested[l]
+			key = key[open+1 : len(key)-1]
+		}
+		condition, err := parseCondition(key, values)
+		if err != nil {
+			return nil, err
+		}
+		target.Conditions = append(target.Conditions, condition)
+	}
+	for _, l := range []Logic{And, Or} {
+		if nested[l] != nil {
+			root.Groups = append(root.Groups, nested[l])
+		}
+	}
+	return root, nil
+}
+
+func parseLogic(s string) (Logic, error) {
+	switch strings.ToUpper(s) {
+	case string(And):
+		return And, nil
+	case string(Or):
+		return Or, nil
+	}
+	return "", fmt.Errorf("%w: %q is not and/or", ErrInvalidOperator, s)
+}
+
+func parseCondition(key string, values []string) (Condition, error) {
+	field, op := key, Eq
+	for _, candidate := range operators {
+		if suffix := "_" + string(candidate); strings.HasSuffix(key, suffix) && len(key) > len(suffix) {
+			field, op = strings.TrimSuffix(key, suffix), candidate
+			break
+		}
+	}
+	if op == In && len(values) == 1 {
+		values = strings.Split(values[0], ",")
+	}
+	if len(values) > 1 && op == Eq {
+		// refine sends id=1&id=2 for "one of"
+		op = In
+	}
+	if len(values) > 1 && op != In && op != Ne {
+		return Condition{}, fmt.Errorf("%w: %s accepts a single value", ErrInvalidValue, key)
+	}
+	return Condition{Field: field, Op: op, Values: values}, nil
+}
@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	query := map[string][]string{
+		"_start":        {"0"},
+		"_end":          {"10"},
+		"name_like":     {"eth"},
+		"chain_id":      {"1"},
+		"id":            {"1", "2"},
+		"decimals_gte":  {"6"},
+		"ticker_in":     {"ETH,BTC"},
+		"or[name_like]": {"wrapped"},
+		"or[ticker]":    {"WETH"},
+	}
+	result, err := Parse(query)
+	if err != nil {
+		t.Fatalf("an error '%s' in Parse", err)
+	}
+	expected := &Group{
+		Logic: And,
+		Conditions: []Condition{
+			{Field: "chain_id", Op: Eq, Values: []string{"1"}},
+			{Field: "decimals", Op: Gte, Values: []string{"6"}},
+			{Field: "id", Op: In, Values: []string{"1", "2"}},
+			{Field: "name", Op: Like, Values: []string{"eth"}},
+			{Field: "ticker", Op: In, Values: []string{"ETH", "BTC"}},
+		},
+		Groups: []*Group{
+			{Logic: Or, Conditions: []Condition{
+				{Field: "name", Op: Like, Values: []string{"wrapped"}},
+				{Field: "ticker", Op: Eq, Values: []string{"WETH"}},
+			}},
+		},
+	}
+	if !cmp.Equal(result, expected) {
+		t.Errorf("Parse(%v) = %v; want %v", query, result, expected)
+	}
+}
+
+func TestParseLogic(t *testing.T) {
+	result, err := Parse(map[string][]string{"_logic": {"or"}, "name_ne": {"test"}})
+	if err != nil {
+		t.Fatalf("an error '%s' in Parse", err)
+	}
+	if result.Logic != Or {
+		t.Errorf("Parse logic = %s; want %s", result.Logic, Or)
+	}
+	if _, err := Parse(map[string][]string{"_logic": {"xor"}}); !errors.Is(err, ErrInvalidOperator) {
+		t.Errorf("expected ErrInvalidOperator but got '%v'", err)
+	}
+	if _, err := Parse(map[string][]string{"not[name]": {"test"}}); !errors.Is(err, ErrInvalidOperator) {
+		t.Errorf("expected ErrInvalidOperator but got '%v'", err)
+	}
+	if _, err := Parse(map[string][]string{"decimals_lte": {"1", "2"}}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue but got '%v'", err)
+	}
+}
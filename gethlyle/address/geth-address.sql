@@ -21,4 +21,5 @@ CREATE TABLE geth_addresses
 
 
 -- create index
-CREATE INDEX geth_addresses_address_str ON geth_addresses(address_str);
\ No newline at end of file
+CREATE INDEX geth_addresses_address_str ON geth_addresses(address_str);
+CREATE UNIQUE INDEX geth_addresses_lower_address_str ON geth_addresses(lower(address_str));
\ No newline at end of file
@@ -7,6 +7,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -45,8 +47,8 @@ func TestGetGethAddressForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(gethAddressID).WillReturnRows(noRows)
 	foundGethAddress, err := GetGethAddress(mock, &gethAddressID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethAddress", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethAddress != nil {
 		t.Errorf("Expected GethAddress From Method GetGethAddress: to be empty but got this: %v", foundGethAddress)
@@ -131,8 +133,8 @@ func TestGetGethAddressByAddressStrForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(addressStr).WillReturnRows(noRows)
 	foundGethAddress, err := GetGethAddressByAddressStr(mock, addressStr)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethAddressByAddressStr", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethAddress != nil {
 		t.Errorf("Expected GethAddress From Method GetGethAddressByAddressStr: to be empty but got this: %v", foundGethAddress)
@@ -625,8 +627,8 @@ func TestGetGethAddressListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"address_type_id = 1", "address_str='test'"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("address_type_id", "1"), filter.Equal("address_str", "test"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(int64(1), "test", _start, _end-_start).WillReturnRows(mockRows)
 	foundGethAddressList, err := GetGethAddressListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethAddressListByPagination", err)
@@ -651,8 +653,8 @@ func TestGetGethAddressListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"address_type_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("address_type_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethAddressList, err := GetGethAddressListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethAddressListByPagination", err)
@@ -675,9 +677,9 @@ func TestGetGethAddressListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"address_type_id = 1"}
+	filters := filter.All(filter.Equal("address_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethAddressList, err := GetGethAddressListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethAddressListByPagination", err)
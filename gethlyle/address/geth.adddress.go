@@ -3,13 +3,15 @@ package gethlyleaddresses
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gofrs/uuid"
 	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	gethlyleclient "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/client"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	structuredvalue "github.com/kfukue/lyle-labs-libraries/v2/structuredValue"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 )
 
@@ -28,15 +30,21 @@ type GethAddress struct {
 }
 
 func CreateOrGetContractAddressFromAsset(dbConnPgx utils.PgxIface, asset *asset.Asset) (*GethAddress, error) {
-	contractAddress, err := GetGethAddressByAddressStr(dbConnPgx, asset.ContractAddress)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return CreateOrGetContractAddressFromAssetCtx(ctx, dbConnPgx, asset)
+}
+
+func CreateOrGetContractAddressFromAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, asset *asset.Asset) (*GethAddress, error) {
+	contractAddress, err := dberrors.NilIfNotFound(GetGethAddressByAddressStrCtx(ctx, dbConnPgx, asset.ContractAddress))
 	if err != nil {
-		log.Printf("Failed GetGethAddressByAddressStr: %v\n", err.Error())
+		logging.ReturnedError(ctx, "CreateOrGetContractAddressFromAsset: GetGethAddressByAddressStr", err, logging.Entity("geth_addresses"))
 		return nil, err
 	}
 	// add as new address (contract) if doesn't exists
 	if contractAddress == nil {
 		contractName := fmt.Sprintf("Contract : %s", asset.Name)
-		contractTypeID := utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID
+		contractTypeID := structuredvalue.ID(structuredvalue.AddressTypeContract, utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID)
 		newContractAddress := GethAddress{
 			Name:          contractName,
 			AlternateName: contractName,
@@ -44,9 +52,9 @@ func CreateOrGetContractAddressFromAsset(dbConnPgx utils.PgxIface, asset *asset.
 			AddressTypeID: &contractTypeID,
 			CreatedBy:     utils.SYSTEM_NAME,
 		}
-		contractAddressId, err := InsertGethAddress(dbConnPgx, &newContractAddress)
+		contractAddressId, err := InsertGethAddressCtx(ctx, dbConnPgx, &newContractAddress)
 		if err != nil {
-			log.Printf("Failed in CreateOrGetContractAddressFromAsset :  InsertGethAddress: %v\n", err.Error())
+			logging.ReturnedError(ctx, "CreateOrGetContractAddressFromAsset: InsertGethAddress", err, logging.Entity("geth_addresses"))
 			return nil, err
 		}
 		newContractAddress.ID = &contractAddressId
@@ -55,17 +63,27 @@ func CreateOrGetContractAddressFromAsset(dbConnPgx utils.PgxIface, asset *asset.
 	return contractAddress, nil
 }
 
+// CreateOrGetAddress looks the address up and inserts it when missing, like the
+// other CreateOrGet functions. The lookup and the insert are separate
+// statements, so concurrent callers can conflict on the unique address index;
+// prefer ResolveGethAddresses for batches and concurrent callers.
 func CreateOrGetAddress(dbConnPgx utils.PgxIface, gethAddress *GethAddress) (*GethAddress, error) {
-	address, err := GetGethAddressByAddressStr(dbConnPgx, gethAddress.AddressStr)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return CreateOrGetAddressCtx(ctx, dbConnPgx, gethAddress)
+}
+
+func CreateOrGetAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethAddress *GethAddress) (*GethAddress, error) {
+	address, err := dberrors.NilIfNotFound(GetGethAddressByAddressStrCtx(ctx, dbConnPgx, gethAddress.AddressStr))
 	if err != nil {
-		log.Printf("Failed GetGethAddressByAddressStr: %v\n", err.Error())
+		logging.ReturnedError(ctx, "CreateOrGetAddress: GetGethAddressByAddressStr", err, logging.Entity("geth_addresses"))
 		return nil, err
 	}
 	// add as new address (contract) if doesn't exists
 	if address == nil {
-		contractAddressId, err := InsertGethAddress(dbConnPgx, gethAddress)
+		contractAddressId, err := InsertGethAddressCtx(ctx, dbConnPgx, gethAddress)
 		if err != nil {
-			log.Printf("Failed CreateOrGetAddress : InsertGethAddress: %v\n", err.Error())
+			logging.ReturnedError(ctx, "CreateOrGetAddress: InsertGethAddress", err, logging.Entity("geth_addresses"))
 			return nil, err
 		}
 		gethAddress.ID = &contractAddressId
@@ -74,15 +92,21 @@ func CreateOrGetAddress(dbConnPgx utils.PgxIface, gethAddress *GethAddress) (*Ge
 }
 
 func CreateOrGetEOAAddress(dbConnPgx utils.PgxIface, addressStr string) (*GethAddress, error) {
-	eoaAddress, err := GetGethAddressByAddressStr(dbConnPgx, addressStr)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return CreateOrGetEOAAddressCtx(ctx, dbConnPgx, addressStr)
+}
+
+func CreateOrGetEOAAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, addressStr string) (*GethAddress, error) {
+	eoaAddress, err := dberrors.NilIfNotFound(GetGethAddressByAddressStrCtx(ctx, dbConnPgx, addressStr))
 	if err != nil {
-		log.Printf("Failed GetGethAddressByAddressStr: %v\n", err.Error())
+		logging.ReturnedError(ctx, "CreateOrGetEOAAddress: GetGethAddressByAddressStr", err, logging.Entity("geth_addresses"))
 		return nil, err
 	}
 	// add as new address (contract) if doesn't exists
 	if eoaAddress == nil {
 		contractName := fmt.Sprintf("EOA: %s", addressStr)
-		contractTypeID := utils.EOA_ADDRESS_TYPE_STRUCTURED_VALUE_ID
+		contractTypeID := structuredvalue.ID(structuredvalue.AddressTypeEOA, utils.EOA_ADDRESS_TYPE_STRUCTURED_VALUE_ID)
 		newContractAddress := GethAddress{
 			Name:          contractName,
 			AlternateName: contractName,
@@ -90,9 +114,9 @@ func CreateOrGetEOAAddress(dbConnPgx utils.PgxIface, addressStr string) (*GethAd
 			AddressTypeID: &contractTypeID,
 			CreatedBy:     utils.SYSTEM_NAME,
 		}
-		contractAddressId, err := InsertGethAddress(dbConnPgx, &newContractAddress)
+		contractAddressId, err := InsertGethAddressCtx(ctx, dbConnPgx, &newContractAddress)
 		if err != nil {
-			log.Printf("Failed CreateOrGetEOAAddress : InsertGethAddress: %v\n", err.Error())
+			logging.ReturnedError(ctx, "CreateOrGetEOAAddress: InsertGethAddress", err, logging.Entity("geth_addresses"))
 			return nil, err
 		}
 		newContractAddress.ID = &contractAddressId
@@ -102,15 +126,21 @@ func CreateOrGetEOAAddress(dbConnPgx utils.PgxIface, addressStr string) (*GethAd
 }
 
 func CreateOrGetContractAddress(dbConnPgx utils.PgxIface, addressStr string) (*GethAddress, error) {
-	contractAddress, err := GetGethAddressByAddressStr(dbConnPgx, addressStr)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return CreateOrGetContractAddressCtx(ctx, dbConnPgx, addressStr)
+}
+
+func CreateOrGetContractAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, addressStr string) (*GethAddress, error) {
+	contractAddress, err := dberrors.NilIfNotFound(GetGethAddressByAddressStrCtx(ctx, dbConnPgx, addressStr))
 	if err != nil {
-		log.Printf("Failed GetGethAddressByAddressStr: %v\n", err.Error())
+		logging.ReturnedError(ctx, "CreateOrGetContractAddress: GetGethAddressByAddressStr", err, logging.Entity("geth_addresses"))
 		return nil, err
 	}
 	// add as new address (contract) if doesn't exists
 	if contractAddress == nil {
 		contractName := fmt.Sprintf("Contract: %s", addressStr)
-		contractTypeID := utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID
+		contractTypeID := structuredvalue.ID(structuredvalue.AddressTypeContract, utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID)
 		newContractAddress := GethAddress{
 			Name:          contractName,
 			AlternateName: contractName,
@@ -118,9 +148,9 @@ func CreateOrGetContractAddress(dbConnPgx utils.PgxIface, addressStr string) (*G
 			AddressTypeID: &contractTypeID,
 			CreatedBy:     utils.SYSTEM_NAME,
 		}
-		contractAddressId, err := InsertGethAddress(dbConnPgx, &newContractAddress)
+		contractAddressId, err := InsertGethAddressCtx(ctx, dbConnPgx, &newContractAddress)
 		if err != nil {
-			log.Printf("Failed CreateOrGetContractAddress: InsertGethAddress: %v\n", err.Error())
+			logging.ReturnedError(ctx, "CreateOrGetContractAddress: InsertGethAddress", err, logging.Entity("geth_addresses"))
 			return nil, err
 		}
 		newContractAddress.ID = &contractAddressId
@@ -134,16 +164,15 @@ func CreateGethAddress(addressStr string, isEOA bool) (*GethAddress, error) {
 	var contractTypeID int
 	gethAddressUUID, err := uuid.NewV4()
 	if err != nil {
-		msg := fmt.Sprintf("error: uuid.NewV4(), during CreateContractAddress : %s", err.Error())
-		log.Printf("Failed CreateGethAddress: %v\n", msg)
+		logging.ReturnedError(context.Background(), "CreateGethAddress: uuid.NewV4", err, logging.Entity("geth_addresses"))
 		return nil, err
 	}
 	if isEOA {
 		addressName = fmt.Sprintf("EOA: %s", addressStr)
-		contractTypeID = utils.EOA_ADDRESS_TYPE_STRUCTURED_VALUE_ID
+		contractTypeID = structuredvalue.ID(structuredvalue.AddressTypeEOA, utils.EOA_ADDRESS_TYPE_STRUCTURED_VALUE_ID)
 	} else {
 		addressName = fmt.Sprintf("Contract: %s", addressStr)
-		contractTypeID = utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID
+		contractTypeID = structuredvalue.ID(structuredvalue.AddressTypeContract, utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID)
 	}
 
 	gethAddress := GethAddress{
@@ -157,19 +186,24 @@ func CreateGethAddress(addressStr string, isEOA bool) (*GethAddress, error) {
 	return &gethAddress, nil
 }
 
-// TODO: skip test (need to mock ethclient)
-func CreateEOAOrContractAddress(dbConnPgx utils.PgxIface, addressStr string, cl *ethclient.Client) (*GethAddress, error) {
-	address, err := GetGethAddressByAddressStr(dbConnPgx, addressStr)
+func CreateEOAOrContractAddress(dbConnPgx utils.PgxIface, addressStr string, cl gethlyleclient.ChainReader) (*GethAddress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return CreateEOAOrContractAddressCtx(ctx, dbConnPgx, addressStr, cl)
+}
+
+func CreateEOAOrContractAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, addressStr string, cl gethlyleclient.ChainReader) (*GethAddress, error) {
+	address, err := dberrors.NilIfNotFound(GetGethAddressByAddressStrCtx(ctx, dbConnPgx, addressStr))
 	if err != nil {
-		log.Printf("Failed GetGethAddressByAddressStr: %v\n", err.Error())
+		logging.ReturnedError(ctx, "CreateEOAOrContractAddress: GetGethAddressByAddressStr", err, logging.Entity("geth_addresses"))
 		return nil, err
 	}
 	// add as new address (contract) if doesn't exists
 	if address == nil {
 		address := common.HexToAddress(addressStr)
-		codeAtResult, err := cl.CodeAt(context.Background(), address, nil)
+		codeAtResult, err := cl.CodeAt(ctx, address, nil)
 		if err != nil {
-			log.Printf("Failed CreateEOAOrContractAddress:  CodeAt: %v\n", err.Error())
+			logging.ReturnedError(ctx, "CreateEOAOrContractAddress: CodeAt", err, logging.Entity("geth_addresses"))
 			return nil, err
 		}
 		//if result len is 0 EOA otherwise contract
@@ -183,7 +217,7 @@ func CreateEOAOrContractAddress(dbConnPgx utils.PgxIface, addressStr string, cl
 		}
 		gethAddress, err = CreateGethAddress(addressStr, isEOA)
 		if err != nil {
-			log.Printf("Failed CreateEOAOrContractAddress->CreateGethAddress: %v\n", err.Error())
+			logging.ReturnedError(ctx, "CreateEOAOrContractAddress: CreateGethAddress", err, logging.Entity("geth_addresses"))
 			return nil, err
 		}
 		return gethAddress, nil
@@ -9,6 +9,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
 	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	gethlyleclient "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/client"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
@@ -541,6 +542,63 @@ func TestCreateOrGetContractAddressFromNewAddressForErr(t *testing.T) {
 	}
 }
 
+// CreateEOAOrContractAddress
+func TestCreateEOAOrContractAddress(t *testing.T) {
+	fake, err := gethlyleclient.LoadFake("testdata/chain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// test address TestData1 is EOA, TestData2 a contract with code in the fixture
+	for _, targetData := range []GethAddress{TestData1, TestData2} {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+		}
+		mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(targetData.AddressStr).WillReturnRows(pgxmock.NewRows(DBColumns))
+		foundGethAddress, err := CreateEOAOrContractAddress(mock, targetData.AddressStr, fake)
+		if err != nil {
+			t.Fatalf("an error '%s' in CreateEOAOrContractAddress", err)
+		}
+		newData := GethAddress{
+			UUID:          foundGethAddress.UUID,
+			Name:          targetData.Name,
+			AlternateName: targetData.AlternateName,
+			AddressStr:    targetData.AddressStr,
+			AddressTypeID: targetData.AddressTypeID,
+			CreatedBy:     utils.SYSTEM_NAME,
+		}
+		if cmp.Equal(*foundGethAddress, newData) == false {
+			t.Errorf("Expected GethAddress From Method CreateEOAOrContractAddress: %v is different from actual %v", foundGethAddress, newData)
+		}
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("There awere unfulfilled expectations: %s", err)
+		}
+		mock.Close()
+	}
+}
+
+func TestCreateEOAOrContractAddressFromExistingAddress(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData2
+	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(targetData.AddressStr).WillReturnRows(AddGethAddressToMockRows(mock, []GethAddress{targetData}))
+	// the address is not looked up on chain
+	fake := gethlyleclient.NewFake(&gethlyleclient.Fixture{})
+	foundGethAddress, err := CreateEOAOrContractAddress(mock, targetData.AddressStr, fake)
+	if err != nil {
+		t.Fatalf("an error '%s' in CreateEOAOrContractAddress", err)
+	}
+	if cmp.Equal(*foundGethAddress, targetData) == false {
+		t.Errorf("Expected GethAddress From Method CreateEOAOrContractAddress: %v is different from actual %v", foundGethAddress, targetData)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 // CreateGethAddress
 func TestCreateGethAddressFromNewConrtractAddress(t *testing.T) {
 	mock, err := pgxmock.NewPool()
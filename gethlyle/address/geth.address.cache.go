@@ -0,0 +1,95 @@
+package gethlyleaddresses
+
+import (
+	"context"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/cache"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// GethAddressCache is a read-through cache in front of
+// GetGethAddressByAddressStr, with the same signatures. Addresses are keyed by
+// NormalizeAddressStr, like the lookup matches them. Lookups that fail are not
+// cached, and the Update, Insert and Remove methods invalidate the address
+// they change.
+type GethAddressCache struct {
+	byAddress *cache.LRU[string, GethAddress]
+}
+
+// NewGethAddressCache caches up to size addresses for ttl.
+func NewGethAddressCache(size int, ttl time.Duration) *GethAddressCache {
+	return &GethAddressCache{byAddress: cache.NewLRU[string, GethAddress](size, ttl)}
+}
+
+func (c *GethAddressCache) GetGethAddressByAddressStr(dbConnPgx utils.PgxIface, addressStr string) (*GethAddress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.GetGethAddressByAddressStrCtx(ctx, dbConnPgx, addressStr)
+}
+
+func (c *GethAddressCache) GetGethAddressByAddressStrCtx(ctx context.Context, dbConnPgx utils.PgxIface, addressStr string) (*GethAddress, error) {
+	gethAddress, err := c.byAddress.GetOrLoad(NormalizeAddressStr(addressStr), func() (GethAddress, error) {
+		gethAddress, err := GetGethAddressByAddressStrCtx(ctx, dbConnPgx, addressStr)
+		if err != nil {
+			return GethAddress{}, err
+		}
+		return *gethAddress, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &gethAddress, nil
+}
+
+func (c *GethAddressCache) UpdateGethAddress(dbConnPgx utils.PgxIface, gethAddress *GethAddress) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.UpdateGethAddressCtx(ctx, dbConnPgx, gethAddress)
+}
+
+// UpdateGethAddressCtx drops the address under both its old and new string.
+func (c *GethAddressCache) UpdateGethAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethAddress *GethAddress) error {
+	defer c.byAddress.Delete(NormalizeAddressStr(gethAddress.AddressStr))
+	defer c.Invalidate(gethAddress.ID)
+	return UpdateGethAddressCtx(ctx, dbConnPgx, gethAddress)
+}
+
+func (c *GethAddressCache) InsertGethAddress(dbConnPgx utils.PgxIface, gethAddress *GethAddress) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.InsertGethAddressCtx(ctx, dbConnPgx, gethAddress)
+}
+
+func (c *GethAddressCache) InsertGethAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethAddress *GethAddress) (int, error) {
+	c.byAddress.Delete(NormalizeAddressStr(gethAddress.AddressStr))
+	return InsertGethAddressCtx(ctx, dbConnPgx, gethAddress)
+}
+
+func (c *GethAddressCache) RemoveGethAddress(dbConnPgx utils.PgxIface, gethAddressID *int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return c.RemoveGethAddressCtx(ctx, dbConnPgx, gethAddressID)
+}
+
+func (c *GethAddressCache) RemoveGethAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethAddressID *int) error {
+	defer c.Invalidate(gethAddressID)
+	return RemoveGethAddressCtx(ctx, dbConnPgx, gethAddressID)
+}
+
+// Invalidate drops the address with gethAddressID.
+func (c *GethAddressCache) Invalidate(gethAddressID *int) {
+	if gethAddressID == nil {
+		return
+	}
+	c.byAddress.DeleteFunc(func(_ string, a GethAddress) bool { return a.ID != nil && *a.ID == *gethAddressID })
+}
+
+// Purge drops every cached address.
+func (c *GethAddressCache) Purge() {
+	c.byAddress.Purge()
+}
+
+func (c *GethAddressCache) Stats() cache.Stats {
+	return c.byAddress.Stats()
+}
@@ -0,0 +1,49 @@
+package gethlyleaddresses
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestGethAddressCache(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData2
+	addressStr := targetData.AddressStr
+	c := NewGethAddressCache(10, time.Minute)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(addressStr).WillReturnRows(AddGethAddressToMockRows(mock, []GethAddress{targetData}))
+	// the second lookup differs in case only and is a hit
+	for _, lookup := range []string{addressStr, strings.ToUpper(addressStr)} {
+		foundGethAddress, err := c.GetGethAddressByAddressStr(mock, lookup)
+		if err != nil {
+			t.Fatalf("an error '%s' in GetGethAddressByAddressStr", err)
+		}
+		if cmp.Equal(*foundGethAddress, targetData) == false {
+			t.Errorf("Expected GethAddress From Method GetGethAddressByAddressStr: %v is different from actual %v", foundGethAddress, targetData)
+		}
+	}
+	if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %+v", stats)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("^DELETE FROM geth_addresses").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+	if err := c.RemoveGethAddress(mock, targetData.ID); err != nil {
+		t.Fatalf("an error '%s' in RemoveGethAddress", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses").WithArgs(addressStr).WillReturnRows(AddGethAddressToMockRows(mock, []GethAddress{targetData}))
+	if _, err := c.GetGethAddressByAddressStr(mock, addressStr); err != nil {
+		t.Fatalf("an error '%s' in GetGethAddressByAddressStr", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,156 @@
+package gethlyleaddresses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	structuredvalue "github.com/kfukue/lyle-labs-libraries/v2/structuredValue"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+var ErrInvalidAddressSpec = errors.New("gethlyleaddresses: invalid address spec")
+
+// AddressSpec describes an address to create when it does not exist yet. Name
+// and AlternateName are only used for new rows; existing rows are returned as
+// they are.
+type AddressSpec struct {
+	AddressStr    string
+	Name          string
+	AlternateName string
+	Description   string
+	AddressTypeID *int
+}
+
+// EOASpec returns the spec CreateOrGetEOAAddress uses for addressStr.
+func EOASpec(addressStr string) AddressSpec {
+	name := fmt.Sprintf("EOA: %s", addressStr)
+	typeID := structuredvalue.ID(structuredvalue.AddressTypeEOA, utils.EOA_ADDRESS_TYPE_STRUCTURED_VALUE_ID)
+	return AddressSpec{AddressStr: addressStr, Name: name, AlternateName: name, AddressTypeID: &typeID}
+}
+
+// ContractSpec returns the spec CreateOrGetContractAddress uses for addressStr.
+func ContractSpec(addressStr string) AddressSpec {
+	name := fmt.Sprintf("Contract: %s", addressStr)
+	typeID := structuredvalue.ID(structuredvalue.AddressTypeContract, utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID)
+	return AddressSpec{AddressStr: addressStr, Name: name, AlternateName: name, AddressTypeID: &typeID}
+}
+
+// NormalizeAddressStr returns the form addresses are matched and keyed by.
+func NormalizeAddressStr(addressStr string) string {
+	return strings.ToLower(strings.TrimSpace(addressStr))
+}
+
+func ResolveGethAddresses(dbConnPgx utils.PgxIface, specs []AddressSpec) (map[string]GethAddress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return ResolveGethAddressesCtx(ctx, dbConnPgx, specs)
+}
+
+// ResolveGethAddressesCtx returns the row of every spec, keyed by
+// NormalizeAddressStr, creating the missing ones. Addresses match regardless
+// of case and the first spec of an address wins. Missing rows are inserted in
+// one statement with ON CONFLICT on the unique lower(address_str) index, so
+// concurrent callers get the same ids instead of duplicates.
+func ResolveGethAddressesCtx(ctx context.Context, dbConnPgx utils.PgxIface, specs []AddressSpec) (map[string]GethAddress, error) {
+	resolved := map[string]GethAddress{}
+	if len(specs) == 0 {
+		return resolved, nil
+	}
+	var keys, addressStrs, names, alternateNames, descriptions []string
+	var typeIDs []int
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		key := NormalizeAddressStr(spec.AddressStr)
+		if key == "" || spec.AddressTypeID == nil {
+			err := fmt.Errorf("%w: %q needs an address and an address type", ErrInvalidAddressSpec, spec.AddressStr)
+			logging.ReturnedError(ctx, "ResolveGethAddresses", err, logging.Entity("geth_addresses"))
+			return nil, err
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+		addressStrs = append(addressStrs, strings.TrimSpace(spec.AddressStr))
+		names = append(names, spec.Name)
+		alternateNames = append(alternateNames, spec.AlternateName)
+		descriptions = append(descriptions, spec.Description)
+		typeIDs = append(typeIDs, *spec.AddressTypeID)
+	}
+	err := utils.WithTx(ctx, dbConnPgx, func(tx utils.PgxIface) error {
+		if _, err := tx.Exec(ctx, `INSERT INTO geth_addresses
+		(
+			uuid,
+			name,
+			alternate_name,
+			description,
+			address_str,
+			address_type_id,
+			created_by,
+			created_at,
+			updated_by,
+			updated_at
+		)
+		SELECT
+			uuid_generate_v4(),
+			name,
+			alternate_name,
+			description,
+			address_str,
+			address_type_id,
+			$6,
+			current_timestamp at time zone 'UTC',
+			$6,
+			current_timestamp at time zone 'UTC'
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::int[])
+			AS specs(address_str, name, alternate_name, description, address_type_id)
+		ON CONFLICT ((lower(address_str))) DO NOTHING`,
+			addressStrs,       //1
+			names,             //2
+			alternateNames,    //3
+			descriptions,      //4
+			typeIDs,           //5
+			utils.SYSTEM_NAME, //6
+		); err != nil {
+			return err
+		}
+		// a separate statement, so rows committed concurrently by the
+		// conflicting inserts above are visible
+		rows, err := tx.Query(ctx, `SELECT
+		id,
+		uuid,
+		name,
+		alternate_name,
+		description,
+		address_str,
+		address_type_id,
+		created_by,
+		created_at,
+		updated_by,
+		updated_at
+		FROM geth_addresses
+		WHERE lower(address_str) = ANY($1)
+		`, keys)
+		if err != nil {
+			return err
+		}
+		gethAddresses, err := pgx.CollectRows(rows, pgx.RowToStructByName[GethAddress])
+		if err != nil {
+			return err
+		}
+		for _, gethAddress := range gethAddresses {
+			resolved[NormalizeAddressStr(gethAddress.AddressStr)] = gethAddress
+		}
+		return nil
+	})
+	if err != nil {
+		logging.ReturnedError(ctx, "ResolveGethAddresses", err, logging.Entity("geth_addresses"), logging.Rows(int64(len(keys))))
+		return nil, dberrors.Wrap(err)
+	}
+	return resolved, nil
+}
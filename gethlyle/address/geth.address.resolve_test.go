@@ -0,0 +1,90 @@
+package gethlyleaddresses
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestResolveGethAddresses(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	existing := TestData1
+	created := TestData2
+	eoa := EOASpec(strings.ToLower(existing.AddressStr))
+	contract := ContractSpec(created.AddressStr)
+	// the second spec of an address is dropped
+	specs := []AddressSpec{eoa, contract, EOASpec(existing.AddressStr)}
+	keys := []string{NormalizeAddressStr(existing.AddressStr), NormalizeAddressStr(created.AddressStr)}
+	mock.ExpectBegin()
+	mock.ExpectExec("^INSERT INTO geth_addresses").WithArgs(
+		[]string{eoa.AddressStr, contract.AddressStr},
+		[]string{eoa.Name, contract.Name},
+		[]string{eoa.AlternateName, contract.AlternateName},
+		[]string{"", ""},
+		[]int{*eoa.AddressTypeID, *contract.AddressTypeID},
+		utils.SYSTEM_NAME,
+	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_addresses WHERE lower").WithArgs(keys).WillReturnRows(AddGethAddressToMockRows(mock, []GethAddress{existing, created}))
+	mock.ExpectCommit()
+	resolved, err := ResolveGethAddresses(mock, specs)
+	if err != nil {
+		t.Fatalf("an error '%s' in ResolveGethAddresses", err)
+	}
+	if len(resolved) != 2 {
+		t.Fatalf("expected 2 addresses, got %d", len(resolved))
+	}
+	if cmp.Equal(resolved[keys[0]], existing) == false {
+		t.Errorf("Expected GethAddress From Method ResolveGethAddresses: %v is different from actual %v", resolved[keys[0]], existing)
+	}
+	if cmp.Equal(resolved[keys[1]], created) == false {
+		t.Errorf("Expected GethAddress From Method ResolveGethAddresses: %v is different from actual %v", resolved[keys[1]], created)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestResolveGethAddressesForInvalidSpec(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	_, err = ResolveGethAddresses(mock, []AddressSpec{{AddressStr: TestData1.AddressStr}})
+	if !errors.Is(err, ErrInvalidAddressSpec) {
+		t.Fatalf("expected ErrInvalidAddressSpec, got %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestResolveGethAddressesForErr(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^INSERT INTO geth_addresses").WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), utils.SYSTEM_NAME).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectRollback()
+	resolved, err := ResolveGethAddresses(mock, []AddressSpec{EOASpec(TestData1.AddressStr)})
+	if err == nil {
+		t.Fatalf("expected an error '%s' in ResolveGethAddresses", err)
+	}
+	if resolved != nil {
+		t.Errorf("Expected GethAddress From Method ResolveGethAddresses: to be empty but got this: %v", resolved)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,16 @@
+COMMIT
+BEGIN TRANSACTION;
+DROP TABLE IF EXISTS geth_blocks CASCADE;
+
+CREATE TABLE geth_blocks
+(
+  chain_id INT NOT NULL,
+  block_number NUMERIC NOT NULL,
+  block_hash VARCHAR(66) NOT NULL,
+  parent_hash VARCHAR(66) NOT NULL,
+  block_date timestamp NULL,
+  created_at timestamp NOT NULL,
+  PRIMARY KEY(chain_id, block_number),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
+COMMIT
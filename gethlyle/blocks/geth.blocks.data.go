@@ -0,0 +1,201 @@
+package gethlyleblocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// GethBlockRepository implements the standard queries on the geth_blocks
+// table, keyed on the chain and the block number. UpsertGethBlocks and the
+// rollback are hand written.
+var GethBlockRepository = repository.New[GethBlock]("geth_blocks",
+	repository.Key("chain_id", "block_number"),
+)
+
+func GetGethBlock(dbConnPgx utils.PgxIface, chainID *int, blockNumber uint64) (*GethBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetGethBlockCtx(ctx, dbConnPgx, chainID, blockNumber)
+}
+
+func GetGethBlockCtx(ctx context.Context, dbConnPgx utils.PgxIface, chainID *int, blockNumber uint64) (*GethBlock, error) {
+	return GethBlockRepository.Get(ctx, dbConnPgx, repository.CompositeKey{*chainID, blockNumber})
+}
+
+func GetGethBlocksFromChainIDBetweenBlockNumbers(dbConnPgx utils.PgxIface, chainID *int, startBlockNumber, endBlockNumber uint64) ([]GethBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetGethBlocksFromChainIDBetweenBlockNumbersCtx(ctx, dbConnPgx, chainID, startBlockNumber, endBlockNumber)
+}
+
+// GetGethBlocksFromChainIDBetweenBlockNumbersCtx returns the blocks of the
+// chain from startBlockNumber to endBlockNumber, newest first.
+func GetGethBlocksFromChainIDBetweenBlockNumbersCtx(ctx context.Context, dbConnPgx utils.PgxIface, chainID *int, startBlockNumber, endBlockNumber uint64) ([]GethBlock, error) {
+	return GethBlockRepository.Select(ctx, dbConnPgx, `WHERE chain_id = $1
+	AND block_number BETWEEN $2 AND $3
+	ORDER BY block_number DESC`, *chainID, startBlockNumber, endBlockNumber)
+}
+
+func UpsertGethBlocks(dbConnPgx utils.PgxIface, gethBlocks []GethBlock) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return UpsertGethBlocksCtx(ctx, dbConnPgx, gethBlocks)
+}
+
+// UpsertGethBlocksCtx stores the hashes of gethBlocks, replacing the ones
+// stored for the same chain and block number.
+func UpsertGethBlocksCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethBlocks []GethBlock) error {
+	if len(gethBlocks) == 0 {
+		return nil
+	}
+	var chainIDs []int
+	var blockNumbers []uint64
+	var blockHashes, parentHashes []string
+	var blockDates []*time.Time
+	for _, gethBlock := range gethBlocks {
+		if gethBlock.ChainID == nil || gethBlock.BlockNumber == nil {
+			return dberrors.InvalidInput("geth block needs a chain and a block number")
+		}
+		chainIDs = append(chainIDs, *gethBlock.ChainID)
+		blockNumbers = append(blockNumbers, *gethBlock.BlockNumber)
+		blockHashes = append(blockHashes, gethBlock.BlockHash)
+		parentHashes = append(parentHashes, gethBlock.ParentHash)
+		blockDates = append(blockDates, gethBlock.BlockDate)
+	}
+	_, err := dbConnPgx.Exec(ctx, `INSERT INTO geth_blocks
+	(
+		chain_id,
+		block_number,
+		block_hash,
+		parent_hash,
+		block_date,
+		created_at
+	)
+	SELECT
+		chain_id,
+		block_number,
+		block_hash,
+		parent_hash,
+		block_date,
+		current_timestamp at time zone 'UTC'
+	FROM unnest($1::int[], $2::numeric[], $3::text[], $4::text[], $5::timestamp[])
+		AS blocks(chain_id, block_number, block_hash, parent_hash, block_date)
+	ON CONFLICT (chain_id, block_number) DO UPDATE SET
+		block_hash = EXCLUDED.block_hash,
+		parent_hash = EXCLUDED.parent_hash,
+		block_date = EXCLUDED.block_date,
+		created_at = EXCLUDED.created_at`,
+		chainIDs,     //1
+		blockNumbers, //2
+		blockHashes,  //3
+		parentHashes, //4
+		blockDates,   //5
+	)
+	if err != nil {
+		logging.ReturnedError(ctx, "UpsertGethBlocks", err, logging.Entity("geth_blocks"), logging.Rows(int64(len(gethBlocks))))
+		return dberrors.Wrap(err)
+	}
+	return nil
+}
+
+func RemoveGethBlocksFromChainIDBeforeBlockNumber(dbConnPgx utils.PgxIface, chainID *int, blockNumber uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return RemoveGethBlocksFromChainIDBeforeBlockNumberCtx(ctx, dbConnPgx, chainID, blockNumber)
+}
+
+func RemoveGethBlocksFromChainIDBeforeBlockNumberCtx(ctx context.Context, dbConnPgx utils.PgxIface, chainID *int, blockNumber uint64) error {
+	if chainID == nil {
+		return dberrors.InvalidInput("removing blocks needs a chain")
+	}
+	if _, err := dbConnPgx.Exec(ctx, `DELETE FROM geth_blocks WHERE chain_id = $1 AND block_number < $2`, *chainID, blockNumber); err != nil {
+		logging.ReturnedError(ctx, "RemoveGethBlocksFromChainIDBeforeBlockNumber", err, logging.Entity("geth_blocks"), logging.ID(chainID), logging.BlockNumber(&blockNumber))
+		return dberrors.Wrap(err)
+	}
+	return nil
+}
+
+func RollbackGethChain(dbConnPgx utils.PgxIface, chainID *int, ancestorBlockNumber uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return RollbackGethChainCtx(ctx, dbConnPgx, chainID, ancestorBlockNumber)
+}
+
+// RollbackGethChainCtx deletes what was indexed on the chain after
+// ancestorBlockNumber, the last block the indexed and the canonical chain
+// share, in one transaction: the swaps, transfers and transactions of the
+// orphaned blocks, the trades built from those swaps and transfers, and the
+// block hashes. The checkpoints of the chain's jobs are moved back to
+// ancestorBlockNumber so they index the canonical blocks again; jobs without
+// a chain are not rewound, which is why the indexer refuses them when it
+// detects reorgs.
+func RollbackGethChainCtx(ctx context.Context, dbConnPgx utils.PgxIface, chainID *int, ancestorBlockNumber uint64) error {
+	if chainID == nil {
+		return dberrors.InvalidInput("rollback needs a chain")
+	}
+	err := utils.WithTx(ctx, dbConnPgx, func(tx utils.PgxIface) error {
+		// a trade spanning the ancestor is deleted whole, trades are rebuilt
+		// from the swaps
+		rows, err := tx.Query(ctx, `SELECT ts.geth_trade_id
+			FROM geth_trade_swaps ts
+			JOIN geth_swaps s ON s.id = ts.geth_swap_id
+			WHERE s.chain_id = $1 AND s.block_number > $2
+		UNION
+		SELECT tt.geth_trade_id
+			FROM geth_trade_transfers tt
+			JOIN geth_transfers t ON t.id = tt.geth_transfer_id
+			WHERE t.chain_id = $1 AND t.block_number > $2`, *chainID, ancestorBlockNumber)
+		if err != nil {
+			return err
+		}
+		tradeIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return err
+		}
+		if len(tradeIDs) > 0 {
+			for _, sql := range []string{
+				`DELETE FROM geth_trade_swaps WHERE geth_trade_id = ANY($1)`,
+				`DELETE FROM geth_trade_transfers WHERE geth_trade_id = ANY($1)`,
+				`DELETE FROM geth_trades WHERE id = ANY($1)`,
+			} {
+				if _, err := tx.Exec(ctx, sql, tradeIDs); err != nil {
+					return err
+				}
+			}
+		}
+		for _, sql := range []string{
+			`DELETE FROM geth_swaps WHERE chain_id = $1 AND block_number > $2`,
+			`DELETE FROM geth_transfers WHERE chain_id = $1 AND block_number > $2`,
+			`DELETE FROM geth_miners_transactions WHERE transaction_id IN
+				(SELECT id FROM geth_transactions WHERE chain_id = $1 AND block_number > $2)`,
+			`DELETE FROM geth_transactions WHERE chain_id = $1 AND block_number > $2`,
+			`DELETE FROM geth_blocks WHERE chain_id = $1 AND block_number > $2`,
+		} {
+			if _, err := tx.Exec(ctx, sql, *chainID, ancestorBlockNumber); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(ctx, `UPDATE geth_process_jobs SET
+			end_block_number = $2,
+			updated_by = $3,
+			updated_at = current_timestamp at time zone 'UTC'
+			WHERE chain_id = $1 AND end_block_number > $2`, *chainID, ancestorBlockNumber, utils.SYSTEM_NAME)
+		return err
+	})
+	if err != nil {
+		logging.ReturnedError(ctx, "RollbackGethChain", err, logging.Entity("geth_blocks"), logging.ID(chainID), logging.BlockNumber(&ancestorBlockNumber))
+		return dberrors.Wrap(err)
+	}
+	logging.Info(ctx, "RollbackGethChain: rolled back", logging.ID(chainID), logging.BlockNumber(&ancestorBlockNumber))
+	return nil
+}
@@ -0,0 +1,230 @@
+package gethlyleblocks
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var DBColumns = GethBlockRepository.Columns()
+
+var TestData1 = GethBlock{
+	ChainID:     utils.Ptr[int](1),
+	BlockNumber: utils.Ptr[uint64](20000000),
+	BlockHash:   "0xd24fd73f794058a3807db926d8898c6481e902b7edb91ce0d479d6760f276183",
+	ParentHash:  "0x9b8b5d8a0d2a2ba1f4f7ba6e6c5cf1b0d8c7f1b1c2a4b1e1f3c8d9b1b2c3d4e5",
+	BlockDate:   utils.Ptr(utils.SampleCreatedAtTime),
+	CreatedAt:   utils.SampleCreatedAtTime,
+}
+
+var TestData2 = GethBlock{
+	ChainID:     utils.Ptr[int](1),
+	BlockNumber: utils.Ptr[uint64](19999999),
+	BlockHash:   "0x9b8b5d8a0d2a2ba1f4f7ba6e6c5cf1b0d8c7f1b1c2a4b1e1f3c8d9b1b2c3d4e5",
+	ParentHash:  "0x1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f809",
+	BlockDate:   utils.Ptr(utils.SampleCreatedAtTime.Add(-12 * time.Second)),
+	CreatedAt:   utils.SampleCreatedAtTime,
+}
+
+var TestAllData = []GethBlock{TestData1, TestData2}
+
+func AddGethBlockToMockRows(mock pgxmock.PgxPoolIface, dataList []GethBlock) *pgxmock.Rows {
+	return GethBlockRepository.AddToMockRows(mock, dataList)
+}
+
+func TestGetGethBlock(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mockRows := AddGethBlockToMockRows(mock, []GethBlock{targetData})
+	mock.ExpectQuery("^SELECT (.+) FROM geth_blocks").WithArgs(*targetData.ChainID, *targetData.BlockNumber).WillReturnRows(mockRows)
+	foundGethBlock, err := GetGethBlock(mock, targetData.ChainID, *targetData.BlockNumber)
+	if err != nil {
+		t.Fatalf("an error '%s' in GetGethBlock", err)
+	}
+	if cmp.Equal(*foundGethBlock, targetData) == false {
+		t.Errorf("Expected GethBlock From Method GetGethBlock: %v is different from actual %v", foundGethBlock, targetData)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGethBlockForErrNoRows(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	chainID := 1
+	mock.ExpectQuery("^SELECT (.+) FROM geth_blocks").WithArgs(chainID, uint64(1)).WillReturnRows(pgxmock.NewRows(DBColumns))
+	foundGethBlock, err := GetGethBlock(mock, &chainID, 1)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
+	}
+	if foundGethBlock != nil {
+		t.Errorf("Expected GethBlock From Method GetGethBlock: to be empty but got this: %v", foundGethBlock)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGethBlocksFromChainIDBetweenBlockNumbers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	chainID := 1
+	mockRows := AddGethBlockToMockRows(mock, TestAllData)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_blocks").WithArgs(chainID, uint64(19999990), uint64(20000000)).WillReturnRows(mockRows)
+	gethBlocks, err := GetGethBlocksFromChainIDBetweenBlockNumbers(mock, &chainID, 19999990, 20000000)
+	if err != nil {
+		t.Fatalf("an error '%s' in GetGethBlocksFromChainIDBetweenBlockNumbers", err)
+	}
+	if cmp.Equal(gethBlocks, TestAllData) == false {
+		t.Errorf("Expected GethBlocks From Method GetGethBlocksFromChainIDBetweenBlockNumbers: %v is different from actual %v", gethBlocks, TestAllData)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpsertGethBlocks(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectExec("^INSERT INTO geth_blocks").WithArgs(
+		[]int{1, 1}, //1
+		[]uint64{*TestData1.BlockNumber, *TestData2.BlockNumber}, //2
+		[]string{TestData1.BlockHash, TestData2.BlockHash},       //3
+		[]string{TestData1.ParentHash, TestData2.ParentHash},     //4
+		[]*time.Time{TestData1.BlockDate, TestData2.BlockDate},   //5
+	).WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	if err = UpsertGethBlocks(mock, TestAllData); err != nil {
+		t.Fatalf("an error '%s' in UpsertGethBlocks", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpsertGethBlocksOnFailureAtParameter(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	targetData.BlockNumber = nil
+	err = UpsertGethBlocks(mock, []GethBlock{targetData})
+	if !errors.Is(err, dberrors.ErrInvalidInput) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrInvalidInput, err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRemoveGethBlocksFromChainIDBeforeBlockNumber(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	chainID := 1
+	mock.ExpectExec("^DELETE FROM geth_blocks").WithArgs(chainID, uint64(19999936)).WillReturnResult(pgxmock.NewResult("DELETE", 10))
+	if err = RemoveGethBlocksFromChainIDBeforeBlockNumber(mock, &chainID, 19999936); err != nil {
+		t.Fatalf("an error '%s' in RemoveGethBlocksFromChainIDBeforeBlockNumber", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func expectRollback(mock pgxmock.PgxPoolIface, chainID int, ancestor uint64, tradeIDs ...int) {
+	tradeRows := pgxmock.NewRows([]string{"geth_trade_id"})
+	for _, id := range tradeIDs {
+		tradeRows.AddRow(id)
+	}
+	mock.ExpectQuery("^SELECT ts.geth_trade_id FROM geth_trade_swaps").WithArgs(chainID, ancestor).WillReturnRows(tradeRows)
+	if len(tradeIDs) > 0 {
+		mock.ExpectExec("^DELETE FROM geth_trade_swaps").WithArgs(tradeIDs).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		mock.ExpectExec("^DELETE FROM geth_trade_transfers").WithArgs(tradeIDs).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec("^DELETE FROM geth_trades").WithArgs(tradeIDs).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	}
+	for _, table := range []string{"geth_swaps", "geth_transfers", "geth_miners_transactions", "geth_transactions", "geth_blocks"} {
+		mock.ExpectExec("^DELETE FROM "+table+" WHERE").WithArgs(chainID, ancestor).WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	}
+	mock.ExpectExec("^UPDATE geth_process_jobs SET end_block_number").WithArgs(chainID, ancestor, utils.SYSTEM_NAME).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+}
+
+func TestRollbackGethChain(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	chainID := 1
+	mock.ExpectBegin()
+	expectRollback(mock, chainID, 19999990, 7, 8)
+	mock.ExpectCommit()
+	if err = RollbackGethChain(mock, &chainID, 19999990); err != nil {
+		t.Fatalf("an error '%s' in RollbackGethChain", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRollbackGethChainWithoutTrades(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	chainID := 1
+	mock.ExpectBegin()
+	expectRollback(mock, chainID, 19999990)
+	mock.ExpectCommit()
+	if err = RollbackGethChain(mock, &chainID, 19999990); err != nil {
+		t.Fatalf("an error '%s' in RollbackGethChain", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRollbackGethChainOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	chainID := 1
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT ts.geth_trade_id").WithArgs(chainID, uint64(19999990)).WillReturnRows(pgxmock.NewRows([]string{"geth_trade_id"}))
+	mock.ExpectExec("^DELETE FROM geth_swaps").WithArgs(chainID, uint64(19999990)).WillReturnError(fmt.Errorf("Random SQL Error"))
+	mock.ExpectRollback()
+	if err = RollbackGethChain(mock, &chainID, 19999990); err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if err = RollbackGethChain(mock, nil, 19999990); !errors.Is(err, dberrors.ErrInvalidInput) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrInvalidInput, err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
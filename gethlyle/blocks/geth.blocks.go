@@ -0,0 +1,44 @@
+// Package gethlyleblocks stores the hashes of indexed blocks and rolls the
+// indexed rows of a chain back when it reorganizes.
+package gethlyleblocks
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/kfukue/lyle-labs-libraries/v2/chain"
+)
+
+// DefaultConfirmationDepth is the confirmation depth of chains that have none.
+var DefaultConfirmationDepth uint64 = 64
+
+type GethBlock struct {
+	ChainID     *int       `json:"chainId" db:"chain_id"`         //1
+	BlockNumber *uint64    `json:"blockNumber" db:"block_number"` //2
+	BlockHash   string     `json:"blockHash" db:"block_hash"`     //3
+	ParentHash  string     `json:"parentHash" db:"parent_hash"`   //4
+	BlockDate   *time.Time `json:"blockDate" db:"block_date"`     //5
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`     //6
+}
+
+// NewGethBlock returns the GethBlock of header on the chain chainID.
+func NewGethBlock(chainID *int, header *types.Header) GethBlock {
+	blockNumber := header.Number.Uint64()
+	blockDate := time.Unix(int64(header.Time), 0).UTC()
+	return GethBlock{
+		ChainID:     chainID,
+		BlockNumber: &blockNumber,
+		BlockHash:   header.Hash().Hex(),
+		ParentHash:  header.ParentHash.Hex(),
+		BlockDate:   &blockDate,
+	}
+}
+
+// ConfirmationDepth returns the number of blocks of c that can still be
+// reorganized; deeper blocks are final.
+func ConfirmationDepth(c *chain.Chain) uint64 {
+	if c == nil || c.ConfirmationDepth == nil || *c.ConfirmationDepth < 0 {
+		return DefaultConfirmationDepth
+	}
+	return uint64(*c.ConfirmationDepth)
+}
@@ -0,0 +1,79 @@
+package gethlyleblocks
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// HeaderReader reads block headers, e.g. a gethlyleclient.ChainReader.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// DetectReorg reports whether the block checkpoint of the chain, the last one
+// indexed, was reorganized away. It compares the parent hash of the next block
+// with the stored hash of checkpoint. When they differ it returns the common
+// ancestor: the newest stored block of the last depth blocks that is still
+// canonical, or checkpoint-depth, which is final. It returns nil when the
+// chain was not reorganized or no hash of checkpoint is stored.
+func DetectReorg(ctx context.Context, dbConnPgx utils.PgxIface, headers HeaderReader, chainID *int, checkpoint, depth uint64) (*uint64, error) {
+	stored, err := dberrors.NilIfNotFound(GetGethBlockCtx(ctx, dbConnPgx, chainID, checkpoint))
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	canonical, err := canonicalHash(ctx, headers, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	if canonical == stored.BlockHash {
+		return nil, nil
+	}
+
+	var ancestor uint64
+	if checkpoint > depth {
+		ancestor = checkpoint - depth
+	}
+	if checkpoint > 0 {
+		gethBlocks, err := GetGethBlocksFromChainIDBetweenBlockNumbersCtx(ctx, dbConnPgx, chainID, ancestor+1, checkpoint-1)
+		if err != nil {
+			return nil, err
+		}
+		for _, gethBlock := range gethBlocks {
+			header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(*gethBlock.BlockNumber))
+			if err != nil {
+				return nil, err
+			}
+			if header.Hash().Hex() == gethBlock.BlockHash {
+				ancestor = *gethBlock.BlockNumber
+				break
+			}
+		}
+	}
+	logging.Info(ctx, "DetectReorg: chain reorganized", logging.ID(chainID), logging.BlockNumber(&checkpoint),
+		"ancestor", ancestor, "stored_hash", stored.BlockHash, "canonical_hash", canonical)
+	return &ancestor, nil
+}
+
+// canonicalHash returns the hash of the canonical block number, the parent
+// hash of the next block, or the hash of number itself when it is the head.
+func canonicalHash(ctx context.Context, headers HeaderReader, number uint64) (string, error) {
+	next, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(number+1))
+	if err == nil {
+		return next.ParentHash.Hex(), nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return "", err
+	}
+	header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return "", err
+	}
+	return header.Hash().Hex(), nil
+}
@@ -0,0 +1,106 @@
+package gethlyleblocks
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+type fakeHeaders map[uint64]*types.Header
+
+func (f fakeHeaders) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := f[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+// fork returns headers with blocks from..to replaced by the blocks of fork.
+func fork(headers fakeHeaders, from, to uint64, fork byte) fakeHeaders {
+	forked := fakeHeaders{}
+	for n, header := range headers {
+		if n < from {
+			forked[n] = header
+		}
+	}
+	for n := from; n <= to; n++ {
+		var parentHash common.Hash
+		if parent, ok := forked[n-1]; ok {
+			parentHash = parent.Hash()
+		}
+		forked[n] = &types.Header{Number: new(big.Int).SetUint64(n), ParentHash: parentHash, Time: n * 12, Difficulty: big.NewInt(0), Extra: []byte{fork}}
+	}
+	return forked
+}
+
+func storedBlock(chainID int, header *types.Header) GethBlock {
+	gethBlock := NewGethBlock(&chainID, header)
+	gethBlock.CreatedAt = *gethBlock.BlockDate
+	return gethBlock
+}
+
+func TestDetectReorg(t *testing.T) {
+	chainID := 1
+	indexed := fork(fakeHeaders{}, 0, 100, 0)
+	canonical := fork(indexed, 97, 110, 1)
+
+	tests := []struct {
+		name     string
+		headers  fakeHeaders
+		stored   []GethBlock
+		window   []GethBlock
+		expected *uint64
+	}{
+		{name: "not stored"},
+		{name: "canonical", headers: fork(indexed, 101, 105, 0), stored: []GethBlock{storedBlock(chainID, indexed[100])}},
+		{name: "canonical head", headers: indexed, stored: []GethBlock{storedBlock(chainID, indexed[100])}},
+		{
+			name:     "reorganized",
+			headers:  canonical,
+			stored:   []GethBlock{storedBlock(chainID, indexed[100])},
+			window:   []GethBlock{storedBlock(chainID, indexed[98]), storedBlock(chainID, indexed[96])},
+			expected: utils.Ptr[uint64](96),
+		},
+		{
+			name:    "reorganized past stored blocks",
+			headers: canonical,
+			stored:  []GethBlock{storedBlock(chainID, indexed[100])},
+			window:  []GethBlock{storedBlock(chainID, indexed[98])},
+			// blocks deeper than the confirmation depth are final
+			expected: utils.Ptr[uint64](100 - 10),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mock.Close()
+			mock.ExpectQuery("^SELECT (.+) FROM geth_blocks").WithArgs(chainID, uint64(100)).WillReturnRows(AddGethBlockToMockRows(mock, tc.stored))
+			if tc.expected != nil {
+				mock.ExpectQuery("^SELECT (.+) FROM geth_blocks").WithArgs(chainID, uint64(91), uint64(99)).WillReturnRows(AddGethBlockToMockRows(mock, tc.window))
+			}
+			ancestor, err := DetectReorg(context.Background(), mock, tc.headers, &chainID, 100, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tc.expected == nil && ancestor != nil:
+				t.Errorf("expected no reorg, got ancestor %d", *ancestor)
+			case tc.expected != nil && (ancestor == nil || *ancestor != *tc.expected):
+				t.Errorf("expected ancestor %d, got %v", *tc.expected, ancestor)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("There awere unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Package gethlyleclient defines ChainReader, the node access the gethlyle
+// packages need, and a Fake that serves recorded fixtures so code using it is
+// tested without a node.
+package gethlyleclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ChainReader reads blocks, logs and state from a node. *ethclient.Client
+// implements it, and so does the Client of go-ethereum's simulated backend
+// (ethclient/simulated), which is not imported here as it pulls in a whole
+// node.
+type ChainReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+var _ ChainReader = (*ethclient.Client)(nil)
+
+// Dial connects to the node at rawURL.
+func Dial(ctx context.Context, rawURL string) (ChainReader, error) {
+	return ethclient.DialContext(ctx, rawURL)
+}
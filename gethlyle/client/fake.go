@@ -0,0 +1,224 @@
+package gethlyleclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Fixture is what a Fake serves, usually recorded from a node by a Recorder.
+// State (codes and balances) is the same at every block.
+type Fixture struct {
+	Codes    map[common.Address]hexutil.Bytes `json:"codes,omitempty"`
+	Balances map[common.Address]*hexutil.Big  `json:"balances,omitempty"`
+	Calls    []Call                           `json:"calls,omitempty"`
+	Blocks   []Block                          `json:"blocks,omitempty"`
+	Logs     []types.Log                      `json:"logs,omitempty"`
+	Receipts []*types.Receipt                 `json:"receipts,omitempty"`
+}
+
+// Call is the result of an eth_call to To with Data.
+type Call struct {
+	To     common.Address `json:"to"`
+	Data   hexutil.Bytes  `json:"data"`
+	Result hexutil.Bytes  `json:"result"`
+}
+
+// Block is a block of a Fixture. Blocks only read by HeaderByNumber have no
+// transactions.
+type Block struct {
+	Header       *types.Header        `json:"header"`
+	Transactions []*types.Transaction `json:"transactions,omitempty"`
+}
+
+// LoadFixture reads a Fixture saved as JSON.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("gethlyleclient: %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes f as JSON.
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Fake is a ChainReader serving a Fixture. What the fixture does not hold is
+// ethereum.NotFound, except for codes and balances, which are empty.
+type Fake struct {
+	// MaxLogs makes FilterLogs fail like a node when more logs match; zero
+	// means no limit.
+	MaxLogs int
+
+	mu      sync.Mutex
+	fixture *Fixture
+	queries []ethereum.FilterQuery
+}
+
+var _ ChainReader = (*Fake)(nil)
+
+// NewFake returns a Fake serving fixture.
+func NewFake(fixture *Fixture) *Fake {
+	return &Fake{fixture: fixture}
+}
+
+// LoadFake returns a Fake serving the Fixture saved at path.
+func LoadFake(path string) (*Fake, error) {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFake(fixture), nil
+}
+
+// Queries returns the queries FilterLogs was called with.
+func (f *Fake) Queries() []ethereum.FilterQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.queries)
+}
+
+func (f *Fake) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return f.fixture.Codes[account], nil
+}
+
+func (f *Fake) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if balance, ok := f.fixture.Balances[account]; ok {
+		return new(big.Int).Set(balance.ToInt()), nil
+	}
+	return new(big.Int), nil
+}
+
+func (f *Fake) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	for _, call := range f.fixture.Calls {
+		if msg.To != nil && call.To == *msg.To && bytes.Equal(call.Data, msg.Data) {
+			return call.Result, nil
+		}
+	}
+	return nil, fmt.Errorf("gethlyleclient: no recorded call to %v with %x", msg.To, msg.Data)
+}
+
+func (f *Fake) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, q)
+	f.mu.Unlock()
+	from, to := uint64(0), f.latest()
+	if q.FromBlock != nil {
+		from = q.FromBlock.Uint64()
+	}
+	if q.ToBlock != nil {
+		to = q.ToBlock.Uint64()
+	}
+	logs := []types.Log{}
+	for _, l := range f.fixture.Logs {
+		if q.BlockHash != nil {
+			if l.BlockHash != *q.BlockHash {
+				continue
+			}
+		} else if l.BlockNumber < from || l.BlockNumber > to {
+			continue
+		}
+		if matches(l, q) {
+			logs = append(logs, l)
+		}
+	}
+	if f.MaxLogs > 0 && len(logs) > f.MaxLogs {
+		return nil, fmt.Errorf("query returned more than %d results", f.MaxLogs)
+	}
+	return logs, nil
+}
+
+// matches reports whether l is from one of the addresses of q and has one of
+// the topics of q at every position.
+func matches(l types.Log, q ethereum.FilterQuery) bool {
+	if len(q.Addresses) > 0 && !slices.Contains(q.Addresses, l.Address) {
+		return false
+	}
+	if len(q.Topics) > len(l.Topics) {
+		return false
+	}
+	for i, topics := range q.Topics {
+		if len(topics) > 0 && !slices.Contains(topics, l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Fake) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	block, err := f.block(number)
+	if err != nil {
+		return nil, err
+	}
+	return types.CopyHeader(block.Header), nil
+}
+
+func (f *Fake) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	block, err := f.block(number)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(block.Header).WithBody(types.Body{Transactions: block.Transactions}), nil
+}
+
+func (f *Fake) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	for _, receipt := range f.fixture.Receipts {
+		if receipt.TxHash == txHash {
+			return receipt, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+// block returns the block number, or the latest one when number is nil.
+func (f *Fake) block(number *big.Int) (*Block, error) {
+	var found *Block
+	for i := range f.fixture.Blocks {
+		block := &f.fixture.Blocks[i]
+		switch {
+		case number == nil:
+			if found == nil || block.Header.Number.Cmp(found.Header.Number) > 0 {
+				found = block
+			}
+		case block.Header.Number.Cmp(number) == 0:
+			return block, nil
+		}
+	}
+	if found == nil {
+		return nil, ethereum.NotFound
+	}
+	return found, nil
+}
+
+// latest returns the number of the latest block, or of the latest log when
+// there are no blocks.
+func (f *Fake) latest() uint64 {
+	if block, err := f.block(nil); err == nil {
+		return block.Header.Number.Uint64()
+	}
+	var latest uint64
+	for _, l := range f.fixture.Logs {
+		latest = max(latest, l.BlockNumber)
+	}
+	return latest
+}
@@ -0,0 +1,125 @@
+package gethlyleclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	token    = common.HexToAddress("0x40762e9b87aa6457f069925a86352d13339cb68f")
+	pool     = common.HexToAddress("0x5281e311734869c64ca60ef047fd87759397efe6")
+	transfer = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	swap     = common.HexToHash("0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822")
+)
+
+func testFixture() *Fixture {
+	var blocks []Block
+	var parentHash common.Hash
+	for n := int64(100); n <= 103; n++ {
+		header := &types.Header{Number: big.NewInt(n), ParentHash: parentHash, Time: uint64(n * 12), Difficulty: big.NewInt(0)}
+		parentHash = header.Hash()
+		blocks = append(blocks, Block{Header: header})
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, To: &token, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)})
+	blocks[1].Transactions = []*types.Transaction{tx}
+	return &Fixture{
+		Codes:    map[common.Address]hexutil.Bytes{token: {0x60, 0x80}},
+		Balances: map[common.Address]*hexutil.Big{pool: (*hexutil.Big)(big.NewInt(42))},
+		Calls:    []Call{{To: token, Data: hexutil.Bytes{0x31, 0x3c, 0xe5, 0x67}, Result: common.LeftPadBytes([]byte{18}, 32)}},
+		Blocks:   blocks,
+		Logs: []types.Log{
+			{Address: token, Topics: []common.Hash{transfer}, BlockNumber: 100, BlockHash: blocks[0].Header.Hash(), Index: 0},
+			{Address: pool, Topics: []common.Hash{swap}, BlockNumber: 101, BlockHash: blocks[1].Header.Hash(), Index: 0},
+			{Address: token, Topics: []common.Hash{transfer}, BlockNumber: 101, BlockHash: blocks[1].Header.Hash(), Index: 1},
+			{Address: token, Topics: []common.Hash{transfer}, BlockNumber: 103, BlockHash: blocks[3].Header.Hash(), Index: 0},
+		},
+		Receipts: []*types.Receipt{{TxHash: tx.Hash(), Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(101), Logs: []*types.Log{}}},
+	}
+}
+
+func TestFakeFilterLogs(t *testing.T) {
+	ctx := context.Background()
+	fixture := testFixture()
+	fake := NewFake(fixture)
+	tests := []struct {
+		name     string
+		q        ethereum.FilterQuery
+		expected int
+	}{
+		{name: "all", q: ethereum.FilterQuery{}, expected: 4},
+		{name: "range", q: ethereum.FilterQuery{FromBlock: big.NewInt(101), ToBlock: big.NewInt(102)}, expected: 2},
+		{name: "address", q: ethereum.FilterQuery{Addresses: []common.Address{token}}, expected: 3},
+		{name: "topic", q: ethereum.FilterQuery{Topics: [][]common.Hash{{swap}}}, expected: 1},
+		{name: "any topic", q: ethereum.FilterQuery{Topics: [][]common.Hash{{swap, transfer}}}, expected: 4},
+		{name: "too many topics", q: ethereum.FilterQuery{Topics: [][]common.Hash{{transfer}, {}}}, expected: 0},
+		{name: "block hash", q: ethereum.FilterQuery{BlockHash: &fixture.Logs[1].BlockHash}, expected: 2},
+	}
+	for _, tc := range tests {
+		logs, err := fake.FilterLogs(ctx, tc.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != tc.expected {
+			t.Errorf("%s: expected %d logs, got %d", tc.name, tc.expected, len(logs))
+		}
+	}
+	if len(fake.Queries()) != len(tests) {
+		t.Errorf("expected %d queries, got %d", len(tests), len(fake.Queries()))
+	}
+	fake.MaxLogs = 2
+	if _, err := fake.FilterLogs(ctx, ethereum.FilterQuery{}); err == nil {
+		t.Error("expected too many results")
+	}
+}
+
+func TestFakeChain(t *testing.T) {
+	ctx := context.Background()
+	fixture := testFixture()
+	fake := NewFake(fixture)
+
+	header, err := fake.HeaderByNumber(ctx, nil)
+	if err != nil || header.Number.Int64() != 103 {
+		t.Fatalf("expected the latest header 103, got %v, %v", header, err)
+	}
+	if _, err := fake.HeaderByNumber(ctx, big.NewInt(104)); !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	block, err := fake.BlockByNumber(ctx, big.NewInt(101))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Hash() != fixture.Blocks[1].Header.Hash() || len(block.Transactions()) != 1 {
+		t.Errorf("unexpected block %v", block)
+	}
+	receipt, err := fake.TransactionReceipt(ctx, block.Transactions()[0].Hash())
+	if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Errorf("unexpected receipt %v, %v", receipt, err)
+	}
+	if _, err := fake.TransactionReceipt(ctx, common.Hash{}); !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	if code, _ := fake.CodeAt(ctx, token, nil); len(code) == 0 {
+		t.Error("expected the token to have code")
+	}
+	if code, _ := fake.CodeAt(ctx, pool, nil); len(code) != 0 {
+		t.Error("expected an account without code")
+	}
+	if balance, _ := fake.BalanceAt(ctx, pool, nil); balance.Int64() != 42 {
+		t.Errorf("expected a balance of 42, got %d", balance)
+	}
+	result, err := fake.CallContract(ctx, ethereum.CallMsg{To: &token, Data: []byte{0x31, 0x3c, 0xe5, 0x67}}, nil)
+	if err != nil || new(big.Int).SetBytes(result).Int64() != 18 {
+		t.Errorf("unexpected call result %x, %v", result, err)
+	}
+	if _, err := fake.CallContract(ctx, ethereum.CallMsg{To: &pool}, nil); err == nil {
+		t.Error("expected an unrecorded call to fail")
+	}
+}
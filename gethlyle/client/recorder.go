@@ -0,0 +1,157 @@
+package gethlyleclient
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Recorder is a ChainReader recording the answers of a node into a Fixture,
+// for a Fake to serve them later. Errors are not recorded.
+type Recorder struct {
+	reader ChainReader
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+var _ ChainReader = (*Recorder)(nil)
+
+// NewRecorder returns a Recorder reading from reader.
+func NewRecorder(reader ChainReader) *Recorder {
+	return &Recorder{
+		reader: reader,
+		fixture: Fixture{
+			Codes:    map[common.Address]hexutil.Bytes{},
+			Balances: map[common.Address]*hexutil.Big{},
+		},
+	}
+}
+
+// Fixture returns what was recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fixture := r.fixture
+	fixture.Codes = make(map[common.Address]hexutil.Bytes, len(r.fixture.Codes))
+	for account, code := range r.fixture.Codes {
+		fixture.Codes[account] = code
+	}
+	fixture.Balances = make(map[common.Address]*hexutil.Big, len(r.fixture.Balances))
+	for account, balance := range r.fixture.Balances {
+		fixture.Balances[account] = balance
+	}
+	fixture.Calls = slices.Clone(r.fixture.Calls)
+	fixture.Blocks = slices.Clone(r.fixture.Blocks)
+	fixture.Logs = slices.Clone(r.fixture.Logs)
+	fixture.Receipts = slices.Clone(r.fixture.Receipts)
+	return &fixture
+}
+
+func (r *Recorder) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	code, err := r.reader.CodeAt(ctx, account, blockNumber)
+	if err == nil {
+		r.mu.Lock()
+		r.fixture.Codes[account] = code
+		r.mu.Unlock()
+	}
+	return code, err
+}
+
+func (r *Recorder) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	balance, err := r.reader.BalanceAt(ctx, account, blockNumber)
+	if err == nil {
+		r.mu.Lock()
+		r.fixture.Balances[account] = (*hexutil.Big)(new(big.Int).Set(balance))
+		r.mu.Unlock()
+	}
+	return balance, err
+}
+
+func (r *Recorder) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	result, err := r.reader.CallContract(ctx, msg, blockNumber)
+	if err == nil && msg.To != nil {
+		r.mu.Lock()
+		if !slices.ContainsFunc(r.fixture.Calls, func(c Call) bool { return c.To == *msg.To && bytes.Equal(c.Data, msg.Data) }) {
+			r.fixture.Calls = append(r.fixture.Calls, Call{To: *msg.To, Data: msg.Data, Result: result})
+		}
+		r.mu.Unlock()
+	}
+	return result, err
+}
+
+// FilterLogs records the logs returned; a Fake answers queries for other
+// ranges with the recorded logs in them.
+func (r *Recorder) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := r.reader.FilterLogs(ctx, q)
+	if err == nil {
+		r.mu.Lock()
+		for _, l := range logs {
+			if !slices.ContainsFunc(r.fixture.Logs, func(recorded types.Log) bool {
+				return recorded.TxHash == l.TxHash && recorded.Index == l.Index && recorded.BlockHash == l.BlockHash
+			}) {
+				r.fixture.Logs = append(r.fixture.Logs, l)
+			}
+		}
+		slices.SortFunc(r.fixture.Logs, func(a, b types.Log) int {
+			if a.BlockNumber != b.BlockNumber {
+				return cmp.Compare(a.BlockNumber, b.BlockNumber)
+			}
+			return cmp.Compare(a.Index, b.Index)
+		})
+		r.mu.Unlock()
+	}
+	return logs, err
+}
+
+func (r *Recorder) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, err := r.reader.HeaderByNumber(ctx, number)
+	if err == nil {
+		r.recordBlock(Block{Header: types.CopyHeader(header)})
+	}
+	return header, err
+}
+
+func (r *Recorder) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	block, err := r.reader.BlockByNumber(ctx, number)
+	if err == nil {
+		r.recordBlock(Block{Header: block.Header(), Transactions: block.Transactions()})
+	}
+	return block, err
+}
+
+func (r *Recorder) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := r.reader.TransactionReceipt(ctx, txHash)
+	if err == nil {
+		r.mu.Lock()
+		if !slices.ContainsFunc(r.fixture.Receipts, func(recorded *types.Receipt) bool { return recorded.TxHash == txHash }) {
+			r.fixture.Receipts = append(r.fixture.Receipts, receipt)
+		}
+		r.mu.Unlock()
+	}
+	return receipt, err
+}
+
+// recordBlock records block, replacing a recorded header of the same number
+// unless block only has the header.
+func (r *Recorder) recordBlock(block Block) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.fixture.Blocks, func(recorded Block) bool {
+		return recorded.Header.Number.Cmp(block.Header.Number) == 0
+	})
+	switch {
+	case i < 0:
+		r.fixture.Blocks = append(r.fixture.Blocks, block)
+	case block.Transactions != nil:
+		r.fixture.Blocks[i] = block
+	}
+}
@@ -0,0 +1,61 @@
+package gethlyleclient
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	node := NewFake(testFixture())
+	recorder := NewRecorder(node)
+	read := func(cr ChainReader) []interface{} {
+		logs, err := cr.FilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{token}, FromBlock: big.NewInt(100), ToBlock: big.NewInt(101)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		header, err := cr.HeaderByNumber(ctx, big.NewInt(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, err := cr.BlockByNumber(ctx, big.NewInt(101))
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipt, err := cr.TransactionReceipt(ctx, block.Transactions()[0].Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, _ := cr.CodeAt(ctx, token, nil)
+		balance, _ := cr.BalanceAt(ctx, pool, nil)
+		result, err := cr.CallContract(ctx, ethereum.CallMsg{To: &token, Data: []byte{0x31, 0x3c, 0xe5, 0x67}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []interface{}{len(logs), logs[0].TxHash, header.Hash(), block.Hash(), receipt.Status, code, balance.String(), result}
+	}
+	recorded := read(recorder)
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := recorder.Fixture().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	fake, err := LoadFake(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed := read(fake); !cmp.Equal(recorded, replayed) {
+		t.Errorf("replayed %v, recorded %v", replayed, recorded)
+	}
+	// only what was read is recorded
+	fixture := recorder.Fixture()
+	if len(fixture.Logs) != 2 || len(fixture.Blocks) != 2 || len(fixture.Blocks[1].Transactions) != 1 {
+		t.Errorf("unexpected fixture %+v", fixture)
+	}
+}
@@ -0,0 +1,89 @@
+// Package gethlyledecoder turns raw event logs into the rows of the gethlyle
+// packages, e.g. Uniswap Swap logs into gethlyleswaps.GethSwap.
+package gethlyledecoder
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrUnknownEvent is returned for logs that are not one of the events
+	// handled by the decoder.
+	ErrUnknownEvent    = errors.New("gethlyledecoder: unknown event")
+	ErrMalformedLog    = errors.New("gethlyledecoder: malformed log")
+	ErrUnknownAsset    = errors.New("gethlyledecoder: base asset is not part of the pool")
+	ErrMissingDecimals = errors.New("gethlyledecoder: asset has no decimals")
+)
+
+// PricePrecision is the number of decimal places prices are rounded to.
+var PricePrecision int32 = 36
+
+// Event is an event signature and its topic.
+type Event struct {
+	Signature string
+	Topic     common.Hash
+}
+
+// NewEvent returns the Event of signature, e.g. "Sync(uint112,uint112)".
+func NewEvent(signature string) Event {
+	return Event{Signature: signature, Topic: crypto.Keccak256Hash([]byte(signature))}
+}
+
+// is reports whether vLog is an e event.
+func (e Event) is(vLog *types.Log) bool {
+	return len(vLog.Topics) > 0 && vLog.Topics[0] == e.Topic
+}
+
+// arguments returns the non-indexed arguments of an event, all of which must
+// unpack to *big.Int (uint/int wider than 64 bits or of an odd size).
+func arguments(typeNames ...string) abi.Arguments {
+	args := make(abi.Arguments, len(typeNames))
+	for i, typeName := range typeNames {
+		typ, err := abi.NewType(typeName, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		args[i] = abi.Argument{Type: typ}
+	}
+	return args
+}
+
+func unpackBigInts(args abi.Arguments, vLog *types.Log) ([]*big.Int, error) {
+	values, err := args.Unpack(vLog.Data)
+	if err != nil {
+		return nil, errors.Join(ErrMalformedLog, err)
+	}
+	ints := make([]*big.Int, len(values))
+	for i, v := range values {
+		n, ok := v.(*big.Int)
+		if !ok {
+			return nil, ErrMalformedLog
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// topicAddress returns the address in topic i of vLog.
+func topicAddress(vLog *types.Log, i int) (common.Address, error) {
+	if len(vLog.Topics) <= i {
+		return common.Address{}, ErrMalformedLog
+	}
+	return common.BytesToAddress(vLog.Topics[i].Bytes()), nil
+}
+
+// adjusted returns the raw amount n in units of a.
+func adjusted(n *big.Int, a *asset.Asset) (decimal.Decimal, error) {
+	if a.Decimals == nil {
+		return decimal.Decimal{}, ErrMissingDecimals
+	}
+	return decimal.NewFromBigInt(n, -int32(*a.Decimals)), nil
+}
@@ -0,0 +1,174 @@
+package gethlyledecoder
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	gethlyleswaps "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/swaps"
+	liquiditypool "github.com/kfukue/lyle-labs-libraries/v2/liquidityPool"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	SwapV2Event = NewEvent("Swap(address,uint256,uint256,uint256,uint256,address)")
+	SwapV3Event = NewEvent("Swap(address,address,int256,int256,uint160,uint128,int24)")
+
+	swapV2Arguments = arguments("uint256", "uint256", "uint256", "uint256")
+	swapV3Arguments = arguments("int256", "int256", "uint160", "uint128", "int24")
+
+	// q192 is 2^192, the scale of sqrtPriceX96 squared.
+	q192 = new(big.Int).Lsh(big.NewInt(1), 192)
+)
+
+// Swap is a decoded Swap log.
+type Swap struct {
+	gethlyleswaps.GethSwap
+	// PoolPrice is the price of the pool after a V3 swap, from sqrtPriceX96,
+	// in the same unit as Price. It is nil for V2 swaps.
+	PoolPrice    *decimal.Decimal
+	SqrtPriceX96 *big.Int
+}
+
+// DecodeSwaps decodes the Uniswap V2 and V3 Swap logs of pool in logs. Logs
+// of other contracts or events and removed logs are skipped.
+func DecodeSwaps(logs []types.Log, pool *liquiditypool.LiquidityPoolWithTokens) ([]Swap, error) {
+	swaps := []Swap{}
+	for i := range logs {
+		vLog := &logs[i]
+		if vLog.Removed || !strings.EqualFold(vLog.Address.Hex(), pool.PairAddress) {
+			continue
+		}
+		swap, err := DecodeSwap(vLog, pool)
+		if errors.Is(err, ErrUnknownEvent) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		swaps = append(swaps, *swap)
+	}
+	return swaps, nil
+}
+
+// DecodeSwap decodes a Uniswap V2 or V3 Swap log of pool.
+//
+// Token0Amount and Token1Amount are raw amounts seen from the maker: positive
+// for the token received from the pool, negative for the token paid. The maker
+// is the recipient of the swap (to for V2). IsBuy is true when the maker
+// received the base asset of the pool, and Price is the amount of the other
+// token paid or received per base asset, adjusted by the decimals of both.
+func DecodeSwap(vLog *types.Log, pool *liquiditypool.LiquidityPoolWithTokens) (*Swap, error) {
+	var swap Swap
+	var token0Amount, token1Amount *big.Int
+	switch {
+	case SwapV2Event.is(vLog):
+		// amount0In, amount1In, amount0Out, amount1Out
+		amounts, err := unpackBigInts(swapV2Arguments, vLog)
+		if err != nil {
+			return nil, err
+		}
+		token0Amount = new(big.Int).Sub(amounts[2], amounts[0])
+		token1Amount = new(big.Int).Sub(amounts[3], amounts[1])
+		swap.TopicsStr = []string{SwapV2Event.Signature}
+	case SwapV3Event.is(vLog):
+		// amount0, amount1, sqrtPriceX96, liquidity, tick; the amounts are
+		// the changes of the pool's balances
+		values, err := unpackBigInts(swapV3Arguments, vLog)
+		if err != nil {
+			return nil, err
+		}
+		token0Amount = new(big.Int).Neg(values[0])
+		token1Amount = new(big.Int).Neg(values[1])
+		swap.SqrtPriceX96 = values[2]
+		swap.TopicsStr = []string{SwapV3Event.Signature}
+	default:
+		return nil, ErrUnknownEvent
+	}
+	maker, err := topicAddress(vLog, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	token0IsBase, err := baseIsToken0(pool)
+	if err != nil {
+		return nil, err
+	}
+	amount0, err := adjusted(token0Amount, &pool.Token0)
+	if err != nil {
+		return nil, err
+	}
+	amount1, err := adjusted(token1Amount, &pool.Token1)
+	if err != nil {
+		return nil, err
+	}
+	baseAmount, quoteAmount := amount0, amount1
+	if !token0IsBase {
+		baseAmount, quoteAmount = amount1, amount0
+	}
+	if !baseAmount.IsZero() {
+		price := quoteAmount.Abs().DivRound(baseAmount.Abs(), PricePrecision)
+		swap.Price = &price
+	}
+	if swap.SqrtPriceX96 != nil && swap.SqrtPriceX96.Sign() > 0 {
+		poolPrice := sqrtPriceX96ToPrice(swap.SqrtPriceX96, *pool.Token0.Decimals, *pool.Token1.Decimals)
+		if !token0IsBase && !poolPrice.IsZero() {
+			poolPrice = decimal.NewFromInt(1).DivRound(poolPrice, PricePrecision)
+		}
+		swap.PoolPrice = &poolPrice
+	}
+
+	blockNumber := vLog.BlockNumber
+	indexNumber := vLog.Index
+	isBuy := baseAmount.IsPositive()
+	token0 := decimal.NewFromBigInt(token0Amount, 0)
+	token1 := decimal.NewFromBigInt(token1Amount, 0)
+	swap.ChainID = pool.ChainID
+	swap.ExchangeID = pool.ExchangeID
+	swap.BlockNumber = &blockNumber
+	swap.IndexNumber = &indexNumber
+	swap.TxnHash = vLog.TxHash.Hex()
+	swap.MakerAddress = maker.Hex()
+	swap.IsBuy = &isBuy
+	swap.PairAddress = vLog.Address.Hex()
+	swap.LiquidityPoolID = pool.ID
+	swap.Token0AssetId = pool.Token0.ID
+	swap.Token1AssetId = pool.Token1.ID
+	swap.Token0Amount = &token0
+	swap.Token1Amount = &token1
+	swap.BaseAssetID = pool.BaseAssetID
+	swap.CreatedBy = utils.SYSTEM_NAME
+	swap.UpdatedBy = utils.SYSTEM_NAME
+	return &swap, nil
+}
+
+// GethSwaps returns the GethSwap of every swap.
+func GethSwaps(swaps []Swap) []gethlyleswaps.GethSwap {
+	gethSwaps := make([]gethlyleswaps.GethSwap, len(swaps))
+	for i := range swaps {
+		gethSwaps[i] = swaps[i].GethSwap
+	}
+	return gethSwaps
+}
+
+// sqrtPriceX96ToPrice returns the price of token0 in token1 encoded by
+// sqrtPriceX96, adjusted by the decimals of both.
+func sqrtPriceX96ToPrice(sqrtPriceX96 *big.Int, decimals0, decimals1 int) decimal.Decimal {
+	squared := new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96)
+	return decimal.NewFromBigInt(squared, int32(decimals0-decimals1)).DivRound(decimal.NewFromBigInt(q192, 0), PricePrecision)
+}
+
+func baseIsToken0(pool *liquiditypool.LiquidityPoolWithTokens) (bool, error) {
+	switch {
+	case pool.BaseAssetID == nil:
+		return false, fmt.Errorf("%w: pool %s has no base asset", ErrUnknownAsset, pool.PairAddress)
+	case pool.Token0.ID != nil && *pool.Token0.ID == *pool.BaseAssetID:
+		return true, nil
+	case pool.Token1.ID != nil && *pool.Token1.ID == *pool.BaseAssetID:
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: pool %s, base asset %d", ErrUnknownAsset, pool.PairAddress, *pool.BaseAssetID)
+}
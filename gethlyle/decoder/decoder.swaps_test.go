@@ -0,0 +1,129 @@
+package gethlyledecoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	liquiditypool "github.com/kfukue/lyle-labs-libraries/v2/liquidityPool"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/shopspring/decimal"
+)
+
+// usdcWETHPool returns the USDC/WETH pool at pairAddress with WETH as the base
+// asset.
+func usdcWETHPool(pairAddress string) *liquiditypool.LiquidityPoolWithTokens {
+	return &liquiditypool.LiquidityPoolWithTokens{
+		LiquidityPool: liquiditypool.LiquidityPool{
+			ID:          utils.Ptr(7),
+			PairAddress: pairAddress,
+			ChainID:     utils.Ptr(1),
+			ExchangeID:  utils.Ptr(2),
+			BaseAssetID: utils.Ptr(2),
+		},
+		Token0: asset.Asset{ID: utils.Ptr(1), Ticker: "USDC", Decimals: utils.Ptr(6), ContractAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+		Token1: asset.Asset{ID: utils.Ptr(2), Ticker: "WETH", Decimals: utils.Ptr(18), ContractAddress: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"},
+	}
+}
+
+func assertDecimal(t *testing.T, field string, got *decimal.Decimal, want string) {
+	t.Helper()
+	if got == nil || !got.Equal(decimal.RequireFromString(want)) {
+		t.Errorf("expected %s %s, got %v", field, want, got)
+	}
+}
+
+func TestDecodeSwapsV2(t *testing.T) {
+	pool := usdcWETHPool("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+	swaps, err := DecodeSwaps(loadLogs(t, "uniswap_v2_swaps.json"), pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the Sync log and the removed Swap log are skipped
+	if len(swaps) != 2 {
+		t.Fatalf("expected 2 swaps, got %d", len(swaps))
+	}
+	sell := swaps[0]
+	if sell.MakerAddress != "0xFC3d170c29581E60861Ac2b500b098722d9861e9" {
+		t.Errorf("expected the to address as maker, got %s", sell.MakerAddress)
+	}
+	if *sell.IsBuy {
+		t.Error("expected selling WETH not to be a buy")
+	}
+	assertDecimal(t, "Token0Amount", sell.Token0Amount, "3000000000")
+	assertDecimal(t, "Token1Amount", sell.Token1Amount, "-1000000000000000000")
+	assertDecimal(t, "Price", sell.Price, "3000")
+	if sell.PoolPrice != nil {
+		t.Errorf("expected no pool price for V2, got %v", sell.PoolPrice)
+	}
+	if *sell.BlockNumber != 17387265 || *sell.IndexNumber != 76 || sell.TxnHash != "0x67775b7b31ff14d7a52c883e5ffe1a10cbdacb28c59728c5a78948863aa31b3b" {
+		t.Errorf("unexpected position %d/%d %s", *sell.BlockNumber, *sell.IndexNumber, sell.TxnHash)
+	}
+	if *sell.LiquidityPoolID != 7 || *sell.BaseAssetID != 2 || *sell.Token0AssetId != 1 || *sell.Token1AssetId != 2 {
+		t.Errorf("expected the ids of the pool, got %+v", sell.GethSwap)
+	}
+	if sell.TopicsStr[0] != "Swap(address,uint256,uint256,uint256,uint256,address)" {
+		t.Errorf("unexpected topics %v", sell.TopicsStr)
+	}
+
+	buy := swaps[1]
+	if !*buy.IsBuy {
+		t.Error("expected buying WETH to be a buy")
+	}
+	assertDecimal(t, "Token0Amount", buy.Token0Amount, "-1500000000")
+	assertDecimal(t, "Token1Amount", buy.Token1Amount, "500000000000000000")
+	assertDecimal(t, "Price", buy.Price, "3000")
+}
+
+func TestDecodeSwapsV3(t *testing.T) {
+	pool := usdcWETHPool("0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640")
+	swaps, err := DecodeSwaps(loadLogs(t, "uniswap_v3_swaps.json"), pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the V2 log of another pair is skipped
+	if len(swaps) != 1 {
+		t.Fatalf("expected 1 swap, got %d", len(swaps))
+	}
+	swap := swaps[0]
+	if !*swap.IsBuy {
+		t.Error("expected buying WETH to be a buy")
+	}
+	assertDecimal(t, "Token0Amount", swap.Token0Amount, "-2000000000")
+	assertDecimal(t, "Token1Amount", swap.Token1Amount, "800000000000000000")
+	assertDecimal(t, "Price", swap.Price, "2500")
+	assertDecimal(t, "PoolPrice", swap.PoolPrice, "2500")
+
+	// quoted the other way round with USDC as the base asset
+	pool.BaseAssetID = utils.Ptr(1)
+	swaps, err = DecodeSwaps(loadLogs(t, "uniswap_v3_swaps.json"), pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *swaps[0].IsBuy {
+		t.Error("expected paying USDC not to be a buy")
+	}
+	assertDecimal(t, "Price", swaps[0].Price, "0.0004")
+	assertDecimal(t, "PoolPrice", swaps[0].PoolPrice, "0.0004")
+}
+
+func TestDecodeSwapErrors(t *testing.T) {
+	logs := loadLogs(t, "uniswap_v2_swaps.json")
+	pool := usdcWETHPool("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+	if _, err := DecodeSwap(&logs[0], pool); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent for a Sync log, got %v", err)
+	}
+	pool.BaseAssetID = utils.Ptr(99)
+	if _, err := DecodeSwap(&logs[1], pool); !errors.Is(err, ErrUnknownAsset) {
+		t.Errorf("expected ErrUnknownAsset, got %v", err)
+	}
+	pool = usdcWETHPool(pool.PairAddress)
+	pool.Token0.Decimals = nil
+	if _, err := DecodeSwap(&logs[1], pool); !errors.Is(err, ErrMissingDecimals) {
+		t.Errorf("expected ErrMissingDecimals, got %v", err)
+	}
+	logs[1].Data = logs[1].Data[:64]
+	if _, err := DecodeSwap(&logs[1], usdcWETHPool(pool.PairAddress)); !errors.Is(err, ErrMalformedLog) {
+		t.Errorf("expected ErrMalformedLog, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package gethlyledecoder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	gethlyletransactions "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/transactions"
+	gethlyletransfers "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/transfers"
+	structuredvalue "github.com/kfukue/lyle-labs-libraries/v2/structuredValue"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	TransferEvent = NewEvent("Transfer(address,address,uint256)")
+
+	transferArguments = arguments("uint256")
+
+	ErrUnknownToken  = errors.New("gethlyledecoder: token is not in the asset lookup")
+	ErrNoNativeAsset = errors.New("gethlyledecoder: no native asset")
+)
+
+// CallFrame is a call of the trace returned by debug_traceTransaction with
+// the callTracer.
+type CallFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value,omitempty"`
+	Error string         `json:"error,omitempty"`
+	Calls []CallFrame    `json:"calls,omitempty"`
+}
+
+// TransferDecoder turns logs, transactions and call traces of a chain into
+// GethTransfer rows. StatusID and GethProcessJobID are left to the caller.
+type TransferDecoder struct {
+	ChainID *int
+	// BaseAssetID is set on every transfer, e.g. the asset a job processes.
+	// When nil the asset of each transfer is used.
+	BaseAssetID *int
+	// Assets resolves token contract addresses to assets, see
+	// asset.CreateLookupByContractAddressFromAssetList.
+	Assets map[string]*asset.Asset
+	// NativeAsset is the asset of native and internal transfers, e.g. ETH.
+	NativeAsset *asset.Asset
+}
+
+// NewTransferDecoder returns a TransferDecoder resolving tokens among assets.
+func NewTransferDecoder(chainID *int, assets []asset.Asset, nativeAsset *asset.Asset) *TransferDecoder {
+	return &TransferDecoder{
+		ChainID:     chainID,
+		Assets:      asset.CreateLookupByContractAddressFromAssetList(assets),
+		NativeAsset: nativeAsset,
+	}
+}
+
+// ERC20Transfers decodes the ERC-20 Transfer logs of known tokens in logs.
+// Logs of other events, of tokens missing from Assets and removed logs are
+// skipped, as are ERC-721 Transfer logs, which index the token id.
+func (d *TransferDecoder) ERC20Transfers(logs []types.Log) ([]gethlyletransfers.GethTransfer, error) {
+	transfers := []gethlyletransfers.GethTransfer{}
+	for i := range logs {
+		if logs[i].Removed {
+			continue
+		}
+		transfer, err := d.ERC20Transfer(&logs[i])
+		if errors.Is(err, ErrUnknownEvent) || errors.Is(err, ErrUnknownToken) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *transfer)
+	}
+	return transfers, nil
+}
+
+// ERC20Transfer decodes an ERC-20 Transfer log. Amount is the raw amount.
+func (d *TransferDecoder) ERC20Transfer(vLog *types.Log) (*gethlyletransfers.GethTransfer, error) {
+	if !TransferEvent.is(vLog) || len(vLog.Topics) != 3 {
+		return nil, ErrUnknownEvent
+	}
+	token, ok := d.Assets[strings.ToLower(vLog.Address.Hex())]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownToken, vLog.Address.Hex())
+	}
+	values, err := unpackBigInts(transferArguments, vLog)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := topicAddress(vLog, 1)
+	to, _ := topicAddress(vLog, 2)
+	blockNumber := vLog.BlockNumber
+	indexNumber := vLog.Index
+	amount := decimal.NewFromBigInt(values[0], 0)
+	transfer := d.newTransfer(token, structuredvalue.TransferTypeERC20, utils.TRANSFER_TYPE_ERC20_STRUCTURED_VALUE_ID)
+	transfer.TokenAddress = vLog.Address.Hex()
+	transfer.BlockNumber = &blockNumber
+	transfer.IndexNumber = &indexNumber
+	transfer.TxnHash = vLog.TxHash.Hex()
+	transfer.SenderAddress = from.Hex()
+	transfer.ToAddress = to.Hex()
+	transfer.Amount = &amount
+	transfer.TopicsStr = []string{TransferEvent.Signature}
+	return transfer, nil
+}
+
+// NativeTransfer returns the value sent by txn, or nil when it sent none.
+// Value is in units of NativeAsset, Amount is the raw amount like the other
+// transfers. IndexNumber is the index of the transaction in the block.
+func (d *TransferDecoder) NativeTransfer(txn *gethlyletransactions.GethTransaction) (*gethlyletransfers.GethTransfer, error) {
+	if txn.Value == nil || !txn.Value.IsPositive() {
+		return nil, nil
+	}
+	if d.NativeAsset == nil {
+		return nil, ErrNoNativeAsset
+	}
+	if d.NativeAsset.Decimals == nil {
+		return nil, ErrMissingDecimals
+	}
+	to := txn.ToAddress
+	if to == "" {
+		// contract creation
+		to = txn.InteractedContractAddress
+	}
+	amount := txn.Value.Shift(int32(*d.NativeAsset.Decimals))
+	transfer := d.newTransfer(d.NativeAsset, structuredvalue.TransferTypeNative, utils.TRANSFER_TYPE_NATIVE_STRUCTURED_VALUE_ID)
+	transfer.TokenAddress = d.NativeAsset.ContractAddress
+	transfer.BlockNumber = txn.BlockNumber
+	transfer.IndexNumber = txn.IndexNumber
+	transfer.TransferDate = txn.TxnDate
+	transfer.TxnHash = txn.TxnHash
+	transfer.SenderAddress = txn.FromAddress
+	transfer.ToAddress = to
+	transfer.Amount = &amount
+	return transfer, nil
+}
+
+// InternalTransfers returns the value moved by the calls made within txn,
+// from its callTracer trace. The top-level call is NativeTransfer's. Only
+// CALL, CREATE, CREATE2 and SELFDESTRUCT frames move value; reverted frames
+// and the calls below them are skipped. IndexNumber numbers the transfers in
+// trace order, starting at 1.
+func (d *TransferDecoder) InternalTransfers(txn *gethlyletransactions.GethTransaction, trace *CallFrame) ([]gethlyletransfers.GethTransfer, error) {
+	if d.NativeAsset == nil {
+		return nil, ErrNoNativeAsset
+	}
+	transfers := []gethlyletransfers.GethTransfer{}
+	if trace.Error != "" {
+		return transfers, nil
+	}
+	var walk func(frames []CallFrame)
+	walk = func(frames []CallFrame) {
+		for i := range frames {
+			frame := &frames[i]
+			if frame.Error != "" {
+				continue
+			}
+			if movesValue(frame) {
+				indexNumber := uint(len(transfers) + 1)
+				amount := decimal.NewFromBigInt(frame.Value.ToInt(), 0)
+				transfer := d.newTransfer(d.NativeAsset, structuredvalue.TransferTypeInternal, utils.TRANSFER_TYPE_INTERNAL_STRUCTURED_VALUE_ID)
+				transfer.TokenAddress = d.NativeAsset.ContractAddress
+				transfer.BlockNumber = txn.BlockNumber
+				transfer.IndexNumber = &indexNumber
+				transfer.TransferDate = txn.TxnDate
+				transfer.TxnHash = txn.TxnHash
+				transfer.SenderAddress = frame.From.Hex()
+				transfer.ToAddress = frame.To.Hex()
+				transfer.Amount = &amount
+				transfers = append(transfers, *transfer)
+			}
+			walk(frame.Calls)
+		}
+	}
+	walk(trace.Calls)
+	return transfers, nil
+}
+
+func movesValue(frame *CallFrame) bool {
+	switch strings.ToUpper(frame.Type) {
+	case "CALL", "CREATE", "CREATE2", "SELFDESTRUCT":
+		return frame.Value != nil && frame.Value.ToInt().Sign() > 0
+	}
+	return false
+}
+
+func (d *TransferDecoder) newTransfer(a *asset.Asset, transferType structuredvalue.Key, fallbackTypeID int) *gethlyletransfers.GethTransfer {
+	transferTypeID := structuredvalue.ID(transferType, fallbackTypeID)
+	baseAssetID := d.BaseAssetID
+	if baseAssetID == nil {
+		baseAssetID = a.ID
+	}
+	return &gethlyletransfers.GethTransfer{
+		ChainID:        d.ChainID,
+		AssetID:        a.ID,
+		BaseAssetID:    baseAssetID,
+		TransferTypeID: &transferTypeID,
+		CreatedBy:      utils.SYSTEM_NAME,
+		UpdatedBy:      utils.SYSTEM_NAME,
+	}
+}
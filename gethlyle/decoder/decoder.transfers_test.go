@@ -0,0 +1,187 @@
+package gethlyledecoder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	gethlyletransactions "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/transactions"
+	gethlyletransfers "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/transfers"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var ethAsset = asset.Asset{ID: utils.Ptr(3), Ticker: "ETH", Decimals: utils.Ptr(18)}
+
+func newTestTransferDecoder() *TransferDecoder {
+	return NewTransferDecoder(utils.Ptr(1), []asset.Asset{
+		{ID: utils.Ptr(1), Ticker: "USDC", Decimals: utils.Ptr(6), ContractAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+		{ID: utils.Ptr(2), Ticker: "WETH", Decimals: utils.Ptr(18), ContractAddress: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"},
+	}, &ethAsset)
+}
+
+func TestERC20Transfers(t *testing.T) {
+	d := newTestTransferDecoder()
+	d.BaseAssetID = utils.Ptr(2)
+	transfers, err := d.ERC20Transfers(loadLogs(t, "erc20_transfers.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the ERC-721 transfer and the DAI transfer, missing from the lookup, are
+	// skipped
+	if len(transfers) != 2 {
+		t.Fatalf("expected 2 transfers, got %d", len(transfers))
+	}
+	usdc := transfers[0]
+	if *usdc.AssetID != 1 || *usdc.BaseAssetID != 2 || *usdc.ChainID != 1 {
+		t.Errorf("unexpected ids %+v", usdc)
+	}
+	if usdc.SenderAddress != "0xFC3d170c29581E60861Ac2b500b098722d9861e9" || usdc.ToAddress != "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc" {
+		t.Errorf("unexpected addresses %s -> %s", usdc.SenderAddress, usdc.ToAddress)
+	}
+	assertDecimal(t, "Amount", usdc.Amount, "1500000000")
+	if *usdc.IndexNumber != 139 || *usdc.BlockNumber != 17387265 {
+		t.Errorf("unexpected position %d/%d", *usdc.BlockNumber, *usdc.IndexNumber)
+	}
+	if *usdc.TransferTypeID != utils.TRANSFER_TYPE_ERC20_STRUCTURED_VALUE_ID {
+		t.Errorf("expected the ERC20 transfer type, got %d", *usdc.TransferTypeID)
+	}
+	assertDecimal(t, "Amount", transfers[1].Amount, "500000000000000000")
+	if *transfers[1].AssetID != 2 {
+		t.Errorf("expected WETH, got %d", *transfers[1].AssetID)
+	}
+
+	logs := loadLogs(t, "erc20_transfers.json")
+	if _, err := d.ERC20Transfer(&logs[3]); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("expected ErrUnknownToken, got %v", err)
+	}
+}
+
+func TestNativeTransfer(t *testing.T) {
+	var txn gethlyletransactions.GethTransaction
+	loadJSON(t, "geth_transaction.json", &txn)
+	d := newTestTransferDecoder()
+	transfer, err := d.NativeTransfer(&txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDecimal(t, "Amount", transfer.Amount, "10000000000000000")
+	if *transfer.AssetID != 3 || *transfer.BaseAssetID != 3 || *transfer.IndexNumber != 12 {
+		t.Errorf("unexpected transfer %+v", transfer)
+	}
+	if transfer.SenderAddress != txn.FromAddress || transfer.ToAddress != txn.ToAddress || transfer.TransferDate != txn.TxnDate {
+		t.Errorf("expected the addresses and date of the transaction, got %+v", transfer)
+	}
+	if *transfer.TransferTypeID != utils.TRANSFER_TYPE_NATIVE_STRUCTURED_VALUE_ID {
+		t.Errorf("expected the native transfer type, got %d", *transfer.TransferTypeID)
+	}
+
+	txn.Value = nil
+	if transfer, err := d.NativeTransfer(&txn); transfer != nil || err != nil {
+		t.Errorf("expected no transfer without value, got %v %v", transfer, err)
+	}
+}
+
+func TestInternalTransfers(t *testing.T) {
+	var txn gethlyletransactions.GethTransaction
+	var trace CallFrame
+	loadJSON(t, "geth_transaction.json", &txn)
+	loadJSON(t, "call_trace.json", &trace)
+	d := newTestTransferDecoder()
+	transfers, err := d.InternalTransfers(&txn, &trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the delegate, static, zero value and reverted calls are skipped
+	want := []struct {
+		to     string
+		amount string
+	}{
+		{"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "10000000000000000"},
+		{"0x9C2F2a8F3B6B1d4e5a7C9e0b2d4f6a8C0e2B4D6F", "1000000000000000"},
+		{"0x00000000000124d994209fbB955E0217B5C2ECA1", "100000000000000"},
+	}
+	if len(transfers) != len(want) {
+		t.Fatalf("expected %d transfers, got %d", len(want), len(transfers))
+	}
+	for i, w := range want {
+		if transfers[i].ToAddress != w.to {
+			t.Errorf("transfer %d: expected to %s, got %s", i, w.to, transfers[i].ToAddress)
+		}
+		assertDecimal(t, "Amount", transfers[i].Amount, w.amount)
+		if *transfers[i].IndexNumber != uint(i+1) {
+			t.Errorf("transfer %d: expected index %d, got %d", i, i+1, *transfers[i].IndexNumber)
+		}
+		if *transfers[i].TransferTypeID != utils.TRANSFER_TYPE_INTERNAL_STRUCTURED_VALUE_ID {
+			t.Errorf("expected the internal transfer type, got %d", *transfers[i].TransferTypeID)
+		}
+	}
+
+	d.NativeAsset = nil
+	if _, err := d.InternalTransfers(&txn, &trace); !errors.Is(err, ErrNoNativeAsset) {
+		t.Errorf("expected ErrNoNativeAsset, got %v", err)
+	}
+}
+
+// The native, internal and ERC-20 transfers of a transaction share index
+// numbers; the transfer type keeps their natural keys apart.
+func TestUpsertTransfersOfOneTransaction(t *testing.T) {
+	var txn gethlyletransactions.GethTransaction
+	var trace CallFrame
+	loadJSON(t, "geth_transaction.json", &txn)
+	loadJSON(t, "call_trace.json", &trace)
+	logs := loadLogs(t, "erc20_transfers.json")[:2]
+	// log 1 and 12 have the numbers of the first internal and the native
+	// transfer
+	for i, index := range []uint{1, 12} {
+		logs[i].TxHash = common.HexToHash(txn.TxnHash)
+		logs[i].Index = index
+	}
+	d := newTestTransferDecoder()
+	native, err := d.NativeTransfer(&txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal, err := d.InternalTransfers(&txn, &trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	erc20, err := d.ERC20Transfers(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfers := append(append([]gethlyletransfers.GethTransfer{*native}, internal...), erc20...)
+	keys := map[string]bool{}
+	for _, transfer := range transfers {
+		keys[fmt.Sprintf("%d/%s/%d/%d/%d", *transfer.ChainID, transfer.TxnHash, *transfer.BlockNumber, *transfer.TransferTypeID, *transfer.IndexNumber)] = true
+	}
+	if len(transfers) != 6 || len(keys) != len(transfers) {
+		t.Fatalf("expected 6 transfers with distinct keys, got %d transfers and %d keys", len(transfers), len(keys))
+	}
+
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^CREATE TEMP TABLE tmp_geth_transfers").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"tmp_geth_transfers"}, gethlyletransfers.DBColumnsInsertGethTransfers).WillReturnResult(int64(len(transfers)))
+	mock.ExpectQuery("^WITH merged AS (.+) ON CONFLICT \\(chain_id, txn_hash, block_number, transfer_type_id, index_number\\)").
+		WillReturnRows(pgxmock.NewRows([]string{"inserted", "updated"}).AddRow(int64(len(transfers)), int64(0)))
+	mock.ExpectExec("^DROP TABLE tmp_geth_transfers").WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mock.ExpectCommit()
+	inserted, updated, err := gethlyletransfers.UpsertGethTransfers(mock, transfers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted != int64(len(transfers)) || updated != 0 {
+		t.Errorf("expected %d inserted, got %d inserted and %d updated", len(transfers), inserted, updated)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,37 @@
+package gethlyledecoder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func loadJSON(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadLogs reads the logs of a testdata fixture, in the JSON format of
+// eth_getLogs.
+func loadLogs(t *testing.T, name string) []types.Log {
+	t.Helper()
+	var logs []types.Log
+	loadJSON(t, name, &logs)
+	return logs
+}
+
+func TestNewEvent(t *testing.T) {
+	want := "0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"
+	if got := SwapV2Event.Topic.Hex(); got != want {
+		t.Errorf("expected topic %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,169 @@
+package gethlyleindexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+)
+
+// tooManyResultsMessages are the errors nodes and providers return when a
+// range holds more logs than they serve at once.
+var tooManyResultsMessages = []string{
+	"query returned more than",
+	"response size exceeded",
+	"response size should not",
+	"log response size",
+	"block range is too wide",
+	"block range too large",
+	"exceed maximum block range",
+	"range too large",
+	"too many results",
+	"limit exceeded",
+}
+
+// IsTooManyResults reports whether err says a log query must be narrowed.
+func IsTooManyResults(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range tooManyResultsMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// chunkSizer is the size of the next chunk. It halves when a range has too
+// many results and grows back by half after ranges fetched at once.
+type chunkSizer struct {
+	mu   sync.Mutex
+	size uint64
+	max  uint64
+}
+
+func newChunkSizer(size, max uint64) *chunkSizer {
+	return &chunkSizer{size: size, max: max}
+}
+
+func (s *chunkSizer) get() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// shrink makes the next chunks at most half of a range that failed.
+func (s *chunkSizer) shrink(failed uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = max(min(s.size, failed/2), 1)
+}
+
+func (s *chunkSizer) grow() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = min(s.size+max(s.size/2, 1), s.max)
+}
+
+type fetched struct {
+	chunk Chunk
+	// logs holds the logs of each handler
+	logs [][]types.Log
+	// header is the header of the last block of chunk when reorgs are
+	// detected
+	header *types.Header
+	err    error
+}
+
+func (ix *Indexer) fetch(ctx context.Context, sizer *chunkSizer, chunk Chunk) fetched {
+	f := fetched{chunk: chunk, logs: make([][]types.Log, len(ix.handlers))}
+	if ix.headers != nil {
+		// before the logs: if the chain reorganizes in between, the stored
+		// hash is the old one and the next run re-indexes the chunk
+		f.err = ix.retry(ctx, chunk, func() (err error) {
+			f.header, err = ix.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(chunk.To))
+			return err
+		})
+		if f.err != nil {
+			return f
+		}
+	}
+	split := false
+	for i, h := range ix.handlers {
+		logs, wasSplit, err := ix.fetchRange(ctx, sizer, h, chunk)
+		if err != nil {
+			f.err = err
+			return f
+		}
+		f.logs[i] = logs
+		split = split || wasSplit
+	}
+	if !split {
+		sizer.grow()
+	}
+	return f
+}
+
+// fetchRange fetches the logs of h in chunk, halving the range as long as the
+// node reports too many results.
+func (ix *Indexer) fetchRange(ctx context.Context, sizer *chunkSizer, h Handler, chunk Chunk) ([]types.Log, bool, error) {
+	q := h.FilterQuery()
+	q.BlockHash = nil
+	q.FromBlock = new(big.Int).SetUint64(chunk.From)
+	q.ToBlock = new(big.Int).SetUint64(chunk.To)
+	var logs []types.Log
+	err := ix.retry(ctx, chunk, func() (err error) {
+		logs, err = ix.client.FilterLogs(ctx, q)
+		return err
+	})
+	if err == nil {
+		return logs, false, nil
+	}
+	if !IsTooManyResults(err) {
+		return nil, false, err
+	}
+	if chunk.From == chunk.To {
+		return nil, true, fmt.Errorf("gethlyleindexer: block %d has too many logs: %w", chunk.From, err)
+	}
+	sizer.shrink(chunk.size())
+	mid := chunk.From + chunk.size()/2 - 1
+	logging.Debug(ctx, "Run: splitting chunk", logging.BlockNumber(chunk.From), logging.BlockNumber(chunk.To))
+	first, _, err := ix.fetchRange(ctx, sizer, h, Chunk{From: chunk.From, To: mid})
+	if err != nil {
+		return nil, true, err
+	}
+	second, _, err := ix.fetchRange(ctx, sizer, h, Chunk{From: mid + 1, To: chunk.To})
+	if err != nil {
+		return nil, true, err
+	}
+	return append(first, second...), true, nil
+}
+
+// retry calls fn until it succeeds, doubling the delay between attempts.
+// Too many results errors are returned at once, retrying cannot fix them.
+func (ix *Indexer) retry(ctx context.Context, chunk Chunk, fn func() error) error {
+	delay := ix.retryDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || IsTooManyResults(err) {
+			return err
+		}
+		if attempt >= ix.retries || ctx.Err() != nil {
+			return fmt.Errorf("gethlyleindexer: fetching blocks %d-%d: %w", chunk.From, chunk.To, err)
+		}
+		logging.Debug(ctx, "Run: retrying fetch", logging.BlockNumber(chunk.From), logging.Err(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,381 @@
+// Package gethlyleindexer indexes the logs of a block range into the database,
+// one GethProcessJob at a time. The range is split into chunks that adapt to
+// the limits of the RPC node; chunks are fetched concurrently, decoded and
+// persisted by Handlers in block order, and every committed chunk moves the
+// job's checkpoint, so a job that stopped is resumed where it left off.
+package gethlyleindexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/kfukue/lyle-labs-libraries/v2/chain"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	gethlyleblocks "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/blocks"
+	gethlylejobs "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/jobs"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	structuredvalue "github.com/kfukue/lyle-labs-libraries/v2/structuredValue"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+var ErrInvalidJob = errors.New("gethlyleindexer: invalid job")
+
+// LogFetcher fetches logs, e.g. a gethlyleclient.ChainReader.
+type LogFetcher interface {
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Chunk is an inclusive block range.
+type Chunk struct {
+	From uint64
+	To   uint64
+}
+
+func (c Chunk) size() uint64 {
+	return c.To - c.From + 1
+}
+
+// Handler decodes and persists the logs it asks for.
+type Handler interface {
+	// FilterQuery returns the addresses and topics of the logs to fetch; the
+	// Indexer sets the block range.
+	FilterQuery() ethereum.FilterQuery
+	// Handle persists the logs of chunk in tx, the transaction that also
+	// checkpoints job. The logs are in block and log index order.
+	Handle(ctx context.Context, tx utils.PgxIface, job *gethlylejobs.GethProcessJob, chunk Chunk, logs []types.Log) error
+}
+
+type handlerFunc struct {
+	query  ethereum.FilterQuery
+	handle func(ctx context.Context, tx utils.PgxIface, job *gethlylejobs.GethProcessJob, chunk Chunk, logs []types.Log) error
+}
+
+func (h handlerFunc) FilterQuery() ethereum.FilterQuery { return h.query }
+
+func (h handlerFunc) Handle(ctx context.Context, tx utils.PgxIface, job *gethlylejobs.GethProcessJob, chunk Chunk, logs []types.Log) error {
+	return h.handle(ctx, tx, job, chunk, logs)
+}
+
+// NewHandler returns a Handler fetching the logs matching query.
+func NewHandler(query ethereum.FilterQuery, handle func(ctx context.Context, tx utils.PgxIface, job *gethlylejobs.GethProcessJob, chunk Chunk, logs []types.Log) error) Handler {
+	return handlerFunc{query: query, handle: handle}
+}
+
+// Indexer runs GethProcessJobs.
+type Indexer struct {
+	db       utils.PgxIface
+	client   LogFetcher
+	handlers []Handler
+
+	chunkSize    uint64
+	maxChunkSize uint64
+	concurrency  int
+	retries      int
+	retryDelay   time.Duration
+
+	chain   *chain.Chain
+	headers gethlyleblocks.HeaderReader
+}
+
+type Option func(*Indexer)
+
+// WithChunkSize sets the number of blocks of the first chunk and the most a
+// chunk grows to. It defaults to 2000 and 10000.
+func WithChunkSize(initial, max uint64) Option {
+	return func(ix *Indexer) {
+		ix.chunkSize, ix.maxChunkSize = initial, max
+	}
+}
+
+// WithConcurrency sets how many chunks are fetched at once, 4 by default.
+func WithConcurrency(n int) Option {
+	return func(ix *Indexer) {
+		ix.concurrency = n
+	}
+}
+
+// WithRetries sets how often a failed fetch is retried, doubling delay each
+// time. Results that exceed the node's limits are split instead.
+func WithRetries(n int, delay time.Duration) Option {
+	return func(ix *Indexer) {
+		ix.retries, ix.retryDelay = n, delay
+	}
+}
+
+// WithReorgDetection makes Run store the hash of the last block of every
+// chunk of c and, before indexing, roll back the blocks orphaned by a reorg
+// since the last run. See gethlyleblocks.DetectReorg.
+func WithReorgDetection(c *chain.Chain, headers gethlyleblocks.HeaderReader) Option {
+	return func(ix *Indexer) {
+		ix.chain, ix.headers = c, headers
+	}
+}
+
+// New returns an Indexer fetching logs from client for handlers and persisting
+// them in db.
+func New(db utils.PgxIface, client LogFetcher, handlers []Handler, opts ...Option) *Indexer {
+	ix := &Indexer{
+		db:           db,
+		client:       client,
+		handlers:     handlers,
+		chunkSize:    2000,
+		maxChunkSize: 10000,
+		concurrency:  4,
+		retries:      3,
+		retryDelay:   time.Second,
+	}
+	for _, opt := range opts {
+		opt(ix)
+	}
+	if ix.chunkSize < 1 {
+		ix.chunkSize = 1
+	}
+	if ix.maxChunkSize < ix.chunkSize {
+		ix.maxChunkSize = ix.chunkSize
+	}
+	if ix.concurrency < 1 {
+		ix.concurrency = 1
+	}
+	if ix.chain == nil || ix.chain.ID == nil {
+		ix.headers = nil
+	}
+	return ix
+}
+
+// JobSpec describes the jobs Resume creates.
+type JobSpec struct {
+	Name          string
+	ImportTypeID  *int
+	AssetID       *int
+	ChainID       *int
+	JobCategoryID *int
+	// StartBlock is the first block of the first job; the next jobs start
+	// after the last one.
+	StartBlock uint64
+}
+
+// Resume indexes up to toBlock for spec. With reorg detection spec.ChainID
+// must be the Indexer's chain. The last job of spec's import type
+// and asset is continued from its checkpoint unless it succeeded, in which
+// case a new job starts after it. It returns the job, or the last one when
+// there is nothing to index.
+func (ix *Indexer) Resume(ctx context.Context, spec JobSpec, toBlock uint64) (*gethlylejobs.GethProcessJob, error) {
+	if spec.ImportTypeID == nil || spec.AssetID == nil {
+		return nil, fmt.Errorf("%w: the spec needs an import type and an asset", ErrInvalidJob)
+	}
+	if err := ix.checkChain(spec.ChainID); err != nil {
+		return nil, err
+	}
+	last, err := dberrors.NilIfNotFound(gethlylejobs.GetLastGethProcessJobByImportTypeIDAndAssetIDCtx(ctx, ix.db, spec.ImportTypeID, spec.AssetID))
+	if err != nil {
+		logging.ReturnedError(ctx, "Resume: GetLastGethProcessJobByImportTypeIDAndAssetID", err, logging.Entity("geth_process_jobs"))
+		return nil, err
+	}
+	if last != nil && !isSuccess(last) {
+		logging.Info(ctx, "Resume: continuing job", logging.JobID(last.ID), logging.BlockNumber(last.EndBlockNumber))
+		return last, ix.Run(ctx, last, toBlock)
+	}
+	startBlock := spec.StartBlock
+	if last != nil && last.EndBlockNumber != nil {
+		startBlock = *last.EndBlockNumber + 1
+	}
+	if startBlock > toBlock {
+		return last, nil
+	}
+	runningID := structuredvalue.ID(structuredvalue.JobStatusRunning, utils.RUNNING_STRUCTURED_VALUE_ID)
+	job := &gethlylejobs.GethProcessJob{
+		Name:             spec.Name,
+		AlternateName:    spec.Name,
+		StartDate:        time.Now().UTC(),
+		StatusID:         &runningID,
+		JobCategoryID:    spec.JobCategoryID,
+		ImportTypeID:     spec.ImportTypeID,
+		ChainID:          spec.ChainID,
+		StartBlockNumber: &startBlock,
+		CreatedBy:        utils.SYSTEM_NAME,
+		UpdatedBy:        utils.SYSTEM_NAME,
+		AssetID:          spec.AssetID,
+	}
+	jobID, err := gethlylejobs.InsertGethProcessJobCtx(ctx, ix.db, job)
+	if err != nil {
+		logging.ReturnedError(ctx, "Resume: InsertGethProcessJob", err, logging.Entity("geth_process_jobs"))
+		return nil, err
+	}
+	job.ID = &jobID
+	return job, ix.Run(ctx, job, toBlock)
+}
+
+// Run indexes job from the block after its checkpoint (EndBlockNumber, or
+// StartBlockNumber when it has none) up to toBlock. The job is marked running,
+// then succeeded with EndBlockNumber toBlock, or failed with the checkpoint
+// of the last committed chunk. With reorg detection, job must be of the
+// Indexer's chain; when the block before the first one was orphaned the chain
+// is rolled back to the common ancestor and job starts after it.
+func (ix *Indexer) Run(ctx context.Context, job *gethlylejobs.GethProcessJob, toBlock uint64) error {
+	if job.ID == nil || job.StartBlockNumber == nil {
+		return fmt.Errorf("%w: the job needs an id and a start block", ErrInvalidJob)
+	}
+	if err := ix.checkChain(job.ChainID); err != nil {
+		return err
+	}
+	from := *job.StartBlockNumber
+	if job.EndBlockNumber != nil && *job.EndBlockNumber >= from {
+		from = *job.EndBlockNumber + 1
+	}
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).With(logging.JobID(job.ID)))
+	if ix.headers != nil && from > 0 {
+		ancestor, err := ix.rollbackReorg(ctx, from-1)
+		if err != nil {
+			return err
+		}
+		if ancestor != nil {
+			from = *ancestor + 1
+			job.EndBlockNumber = ancestor
+			if from < *job.StartBlockNumber {
+				job.StartBlockNumber = &from
+			}
+		}
+	}
+	if err := ix.setStatus(ctx, job, structuredvalue.JobStatusRunning, utils.RUNNING_STRUCTURED_VALUE_ID, false); err != nil {
+		return err
+	}
+	if from <= toBlock {
+		if err := ix.run(ctx, job, Chunk{From: from, To: toBlock}); err != nil {
+			logging.ReturnedError(ctx, "Run", err, logging.Entity("geth_process_jobs"), logging.BlockNumber(job.EndBlockNumber))
+			// the job's context may be done, the status is recorded regardless
+			statusCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gethlylejobs.DefaultTimeout)
+			defer cancel()
+			if statusErr := ix.setStatus(statusCtx, job, structuredvalue.JobStatusFailed, utils.FAILED_STRUCTURED_VALUE_ID, true); statusErr != nil {
+				return errors.Join(err, statusErr)
+			}
+			return err
+		}
+	}
+	if ix.headers != nil {
+		if depth := gethlyleblocks.ConfirmationDepth(ix.chain); toBlock > depth {
+			if err := gethlyleblocks.RemoveGethBlocksFromChainIDBeforeBlockNumberCtx(ctx, ix.db, ix.chain.ID, toBlock-depth); err != nil {
+				return err
+			}
+		}
+	}
+	job.EndBlockNumber = &toBlock
+	return ix.setStatus(ctx, job, structuredvalue.JobStatusSuccess, utils.SUCCESS_STRUCTURED_VALUE_ID, true)
+}
+
+// rollbackReorg rolls the chain back when checkpoint was reorganized away and
+// returns the common ancestor, or nil.
+func (ix *Indexer) rollbackReorg(ctx context.Context, checkpoint uint64) (*uint64, error) {
+	ancestor, err := gethlyleblocks.DetectReorg(ctx, ix.db, ix.headers, ix.chain.ID, checkpoint, gethlyleblocks.ConfirmationDepth(ix.chain))
+	if err != nil {
+		logging.ReturnedError(ctx, "Run: DetectReorg", err, logging.Entity("geth_blocks"), logging.BlockNumber(checkpoint))
+		return nil, err
+	}
+	if ancestor == nil {
+		return nil, nil
+	}
+	if err := gethlyleblocks.RollbackGethChainCtx(ctx, ix.db, ix.chain.ID, *ancestor); err != nil {
+		return nil, err
+	}
+	return ancestor, nil
+}
+
+// run fetches up to concurrency chunks ahead and commits them in order.
+func (ix *Indexer) run(ctx context.Context, job *gethlylejobs.GethProcessJob, blocks Chunk) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sizer := newChunkSizer(ix.chunkSize, ix.maxChunkSize)
+	next := blocks.From
+	var pending []chan fetched
+	launch := func() bool {
+		if next > blocks.To {
+			return false
+		}
+		chunk := Chunk{From: next, To: min(next+sizer.get()-1, blocks.To)}
+		next = chunk.To + 1
+		result := make(chan fetched, 1)
+		go func() { result <- ix.fetch(ctx, sizer, chunk) }()
+		pending = append(pending, result)
+		return true
+	}
+	for len(pending) < ix.concurrency {
+		if !launch() {
+			break
+		}
+	}
+	for len(pending) > 0 {
+		f := <-pending[0]
+		pending = pending[1:]
+		if f.err != nil {
+			return f.err
+		}
+		if err := ix.commit(ctx, job, f); err != nil {
+			return err
+		}
+		launch()
+	}
+	return nil
+}
+
+// commit persists a chunk and moves the checkpoint in one transaction.
+func (ix *Indexer) commit(ctx context.Context, job *gethlylejobs.GethProcessJob, f fetched) error {
+	start := time.Now()
+	var rows int64
+	err := utils.WithTx(ctx, ix.db, func(tx utils.PgxIface) error {
+		for i, h := range ix.handlers {
+			rows += int64(len(f.logs[i]))
+			if err := h.Handle(ctx, tx, job, f.chunk, f.logs[i]); err != nil {
+				return err
+			}
+		}
+		if f.header != nil {
+			if err := gethlyleblocks.UpsertGethBlocksCtx(ctx, tx, []gethlyleblocks.GethBlock{gethlyleblocks.NewGethBlock(ix.chain.ID, f.header)}); err != nil {
+				return err
+			}
+		}
+		return gethlylejobs.CheckpointGethProcessJobCtx(ctx, tx, job.ID, f.chunk.To)
+	})
+	if err != nil {
+		return err
+	}
+	endBlockNumber := f.chunk.To
+	job.EndBlockNumber = &endBlockNumber
+	logging.Debug(ctx, "Run: committed chunk", logging.BlockNumber(endBlockNumber), logging.Rows(rows), logging.Duration(time.Since(start)))
+	return nil
+}
+
+func (ix *Indexer) setStatus(ctx context.Context, job *gethlylejobs.GethProcessJob, status structuredvalue.Key, fallbackID int, done bool) error {
+	statusID := structuredvalue.ID(status, fallbackID)
+	job.StatusID = &statusID
+	job.UpdatedBy = utils.SYSTEM_NAME
+	if done {
+		now := time.Now().UTC()
+		job.EndDate = &now
+	} else {
+		job.EndDate = nil
+	}
+	if err := gethlylejobs.UpdateGethProcessJobCtx(ctx, ix.db, job); err != nil {
+		logging.ReturnedError(ctx, "Run: UpdateGethProcessJob", err, logging.Entity("geth_process_jobs"))
+		return err
+	}
+	return nil
+}
+
+func isSuccess(job *gethlylejobs.GethProcessJob) bool {
+	return job.StatusID != nil && *job.StatusID == structuredvalue.ID(structuredvalue.JobStatusSuccess, utils.SUCCESS_STRUCTURED_VALUE_ID)
+}
+
+// checkChain refuses jobs of another chain, or without one, when reorgs are
+// detected: a rollback only rewinds the jobs of the rolled back chain.
+func (ix *Indexer) checkChain(chainID *int) error {
+	if ix.headers == nil {
+		return nil
+	}
+	if chainID == nil || *chainID != *ix.chain.ID {
+		return fmt.Errorf("%w: reorg detection needs jobs of chain %d", ErrInvalidJob, *ix.chain.ID)
+	}
+	return nil
+}
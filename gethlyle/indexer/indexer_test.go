@@ -0,0 +1,372 @@
+package gethlyleindexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/kfukue/lyle-labs-libraries/v2/chain"
+	gethlyleblocks "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/blocks"
+	gethlyleclient "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/client"
+	gethlylejobs "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/jobs"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var jobColumns = []string{
+	"id", "uuid", "name", "alternate_name", "start_date", "end_date", "description", "status_id", "job_category_id",
+	"import_type_id", "chain_id", "start_block_number", "end_block_number", "created_by", "created_at", "updated_by", "updated_at", "asset_id",
+}
+
+// fakeFetcher serves logs like a node limited to maxResults logs per query.
+type fakeFetcher struct {
+	mu         sync.Mutex
+	logs       []types.Log
+	maxResults int
+	failFrom   uint64
+	jitter     bool
+	calls      []Chunk
+}
+
+func (f *fakeFetcher) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	chunk := Chunk{From: q.FromBlock.Uint64(), To: q.ToBlock.Uint64()}
+	f.mu.Lock()
+	f.calls = append(f.calls, chunk)
+	f.mu.Unlock()
+	if f.jitter {
+		time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+	}
+	if f.failFrom != 0 && chunk.To >= f.failFrom {
+		return nil, errors.New("connection reset by peer")
+	}
+	var logs []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber >= chunk.From && l.BlockNumber <= chunk.To {
+			logs = append(logs, l)
+		}
+	}
+	if f.maxResults > 0 && len(logs) > f.maxResults {
+		return nil, fmt.Errorf("query returned more than %d results", f.maxResults)
+	}
+	return logs, nil
+}
+
+func logsInBlocks(from, to uint64) []types.Log {
+	var logs []types.Log
+	for b := from; b <= to; b++ {
+		logs = append(logs, types.Log{Address: common.HexToAddress("0x01"), BlockNumber: b})
+	}
+	return logs
+}
+
+// recordingHandler keeps the logs and chunks it was handed.
+type recordingHandler struct {
+	logs   []types.Log
+	chunks []Chunk
+}
+
+func (h *recordingHandler) FilterQuery() ethereum.FilterQuery {
+	return ethereum.FilterQuery{Addresses: []common.Address{common.HexToAddress("0x01")}}
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, tx utils.PgxIface, job *gethlylejobs.GethProcessJob, chunk Chunk, logs []types.Log) error {
+	h.logs = append(h.logs, logs...)
+	h.chunks = append(h.chunks, chunk)
+	return nil
+}
+
+func anyArgs(n int) []interface{} {
+	args := make([]interface{}, n)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	return args
+}
+
+func expectUpdateJob(mock pgxmock.PgxPoolIface) {
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE geth_process_jobs SET name").WithArgs(anyArgs(14)...).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+}
+
+func expectChunk(mock pgxmock.PgxPoolIface, jobID int, to uint64) {
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE geth_process_jobs SET end_block_number").WithArgs(to, jobID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+}
+
+func TestResumeAdaptsChunks(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	importTypeID, assetID := 83, 535
+	lastEnd := uint64(99)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(importTypeID, assetID).
+		WillReturnRows(mock.NewRows(jobColumns).AddRow(utils.Ptr(1), "uuid-1", "swaps", "swaps", utils.SampleCreatedAtTime, &utils.SampleCreatedAtTime, "",
+			utils.Ptr(utils.SUCCESS_STRUCTURED_VALUE_ID), utils.Ptr(utils.LIVE_JOB_CATEGORY_STRUCTURED_VALUE_ID), &importTypeID, utils.Ptr(1),
+			utils.Ptr(uint64(1)), &lastEnd, "SYSTEM", utils.SampleCreatedAtTime, "SYSTEM", utils.SampleCreatedAtTime, &assetID))
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO geth_process_jobs").WithArgs(anyArgs(13)...).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectCommit()
+	expectUpdateJob(mock)
+	// 100-199 holds too many logs and is fetched in quarters, the chunks then
+	// grow back
+	for _, to := range []uint64{199, 224, 261, 299} {
+		expectChunk(mock, 2, to)
+	}
+	expectUpdateJob(mock)
+
+	fetcher := &fakeFetcher{logs: append(logsInBlocks(100, 199), logsInBlocks(250, 250)...), maxResults: 30}
+	handler := &recordingHandler{}
+	ix := New(mock, fetcher, []Handler{handler}, WithChunkSize(100, 100), WithConcurrency(1))
+	job, err := ix.Resume(context.Background(), JobSpec{Name: "swaps", ImportTypeID: &importTypeID, AssetID: &assetID}, 299)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *job.ID != 2 || *job.StartBlockNumber != 100 || *job.EndBlockNumber != 299 || *job.StatusID != utils.SUCCESS_STRUCTURED_VALUE_ID {
+		t.Errorf("unexpected job %+v", job)
+	}
+	if len(handler.logs) != 101 {
+		t.Fatalf("expected 101 logs, got %d", len(handler.logs))
+	}
+	for i := 1; i < len(handler.logs); i++ {
+		if handler.logs[i].BlockNumber < handler.logs[i-1].BlockNumber {
+			t.Fatalf("logs out of order at %d", i)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRunFailsAndResumesFromCheckpoint(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	job := &gethlylejobs.GethProcessJob{ID: utils.Ptr(3), StartBlockNumber: utils.Ptr(uint64(100)), EndBlockNumber: utils.Ptr(uint64(149))}
+	expectUpdateJob(mock)
+	expectChunk(mock, 3, 179)
+	expectUpdateJob(mock)
+
+	fetcher := &fakeFetcher{logs: logsInBlocks(100, 199), failFrom: 180}
+	ix := New(mock, fetcher, []Handler{&recordingHandler{}}, WithChunkSize(30, 30), WithConcurrency(1), WithRetries(1, time.Millisecond))
+	if err := ix.Run(context.Background(), job, 199); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+	if fetcher.calls[0].From != 150 {
+		t.Errorf("expected to resume after the checkpoint, got %d", fetcher.calls[0].From)
+	}
+	// the failing chunk was tried twice
+	if len(fetcher.calls) != 3 {
+		t.Errorf("expected 3 fetches, got %v", fetcher.calls)
+	}
+	if *job.EndBlockNumber != 179 || *job.StatusID != utils.FAILED_STRUCTURED_VALUE_ID {
+		t.Errorf("expected the job to fail at 179, got %d status %d", *job.EndBlockNumber, *job.StatusID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRunCommitsConcurrentChunksInOrder(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	job := &gethlylejobs.GethProcessJob{ID: utils.Ptr(4), StartBlockNumber: utils.Ptr(uint64(1))}
+	expectUpdateJob(mock)
+	for to := uint64(10); to <= 100; to += 10 {
+		expectChunk(mock, 4, to)
+	}
+	expectUpdateJob(mock)
+
+	handler := &recordingHandler{}
+	fetcher := &fakeFetcher{logs: logsInBlocks(1, 100), jitter: true}
+	ix := New(mock, fetcher, []Handler{handler}, WithChunkSize(10, 10), WithConcurrency(4))
+	if err := ix.Run(context.Background(), job, 100); err != nil {
+		t.Fatal(err)
+	}
+	for i, chunk := range handler.chunks {
+		if chunk.From != uint64(i*10+1) {
+			t.Fatalf("expected chunk %d to start at %d, got %v", i, i*10+1, chunk)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// fakeHeaders is a chain of headers, reorganized from a block by fork.
+type fakeHeaders map[uint64]*types.Header
+
+func (f fakeHeaders) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := f[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+func (f fakeHeaders) fork(from, to uint64, fork byte) fakeHeaders {
+	forked := fakeHeaders{}
+	for n, header := range f {
+		if n < from {
+			forked[n] = header
+		}
+	}
+	for n := from; n <= to; n++ {
+		var parentHash common.Hash
+		if parent, ok := forked[n-1]; ok {
+			parentHash = parent.Hash()
+		}
+		forked[n] = &types.Header{Number: new(big.Int).SetUint64(n), ParentHash: parentHash, Time: n * 12, Difficulty: big.NewInt(0), Extra: []byte{fork}}
+	}
+	return forked
+}
+
+func blockRows(mock pgxmock.PgxPoolIface, chainID int, headers ...*types.Header) *pgxmock.Rows {
+	rows := mock.NewRows([]string{"chain_id", "block_number", "block_hash", "parent_hash", "block_date", "created_at"})
+	for _, header := range headers {
+		b := gethlyleblocks.NewGethBlock(&chainID, header)
+		rows.AddRow(b.ChainID, b.BlockNumber, b.BlockHash, b.ParentHash, b.BlockDate, *b.BlockDate)
+	}
+	return rows
+}
+
+func TestRunRollsBackReorganizedBlocks(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	chainID := 1
+	indexed := fakeHeaders{}.fork(0, 149, 0)
+	canonical := indexed.fork(145, 200, 1)
+	job := &gethlylejobs.GethProcessJob{ID: utils.Ptr(5), ChainID: &chainID, StartBlockNumber: utils.Ptr(uint64(100)), EndBlockNumber: utils.Ptr(uint64(149))}
+
+	// 149 was orphaned, of the stored blocks 144 is still canonical
+	mock.ExpectQuery("^SELECT (.+) FROM geth_blocks").WithArgs(chainID, uint64(149)).WillReturnRows(blockRows(mock, chainID, indexed[149]))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_blocks").WithArgs(chainID, uint64(140), uint64(148)).
+		WillReturnRows(blockRows(mock, chainID, indexed[147], indexed[144]))
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT ts.geth_trade_id").WithArgs(chainID, uint64(144)).WillReturnRows(mock.NewRows([]string{"geth_trade_id"}))
+	for i := 0; i < 5; i++ {
+		mock.ExpectExec("^DELETE FROM").WithArgs(chainID, uint64(144)).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	}
+	mock.ExpectExec("^UPDATE geth_process_jobs SET end_block_number").WithArgs(chainID, uint64(144), utils.SYSTEM_NAME).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	expectUpdateJob(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec("^INSERT INTO geth_blocks").WithArgs(
+		[]int{chainID}, []uint64{169}, []string{canonical[169].Hash().Hex()}, []string{canonical[168].Hash().Hex()}, pgxmock.AnyArg(),
+	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("^UPDATE geth_process_jobs SET end_block_number").WithArgs(uint64(169), 5).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("^DELETE FROM geth_blocks").WithArgs(chainID, uint64(169-10)).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	expectUpdateJob(mock)
+
+	fetcher := &fakeFetcher{logs: logsInBlocks(100, 169)}
+	handler := &recordingHandler{}
+	c := &chain.Chain{ID: &chainID, ConfirmationDepth: utils.Ptr(10)}
+	ix := New(mock, fetcher, []Handler{handler}, WithChunkSize(30, 30), WithConcurrency(1), WithReorgDetection(c, canonical))
+	if err := ix.Run(context.Background(), job, 169); err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.calls[0].From != 145 || len(handler.logs) != 25 {
+		t.Errorf("expected to re-index from 145, got %v and %d logs", fetcher.calls, len(handler.logs))
+	}
+	if *job.StartBlockNumber != 100 || *job.EndBlockNumber != 169 {
+		t.Errorf("unexpected job blocks %d-%d", *job.StartBlockNumber, *job.EndBlockNumber)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReorgDetectionRefusesJobsOfOtherChains(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	chainID, otherChainID := 1, 2
+	c := &chain.Chain{ID: &chainID}
+	ix := New(mock, &fakeFetcher{}, []Handler{&recordingHandler{}}, WithReorgDetection(c, fakeHeaders{}.fork(0, 10, 0)))
+	for _, jobChainID := range []*int{nil, &otherChainID} {
+		job := &gethlylejobs.GethProcessJob{ID: utils.Ptr(7), ChainID: jobChainID, StartBlockNumber: utils.Ptr(uint64(1))}
+		if err := ix.Run(context.Background(), job, 10); !errors.Is(err, ErrInvalidJob) {
+			t.Errorf("expected ErrInvalidJob for chain %v, got %v", jobChainID, err)
+		}
+		spec := JobSpec{ImportTypeID: utils.Ptr(1), AssetID: utils.Ptr(2), ChainID: jobChainID}
+		if _, err := ix.Resume(context.Background(), spec, 10); !errors.Is(err, ErrInvalidJob) {
+			t.Errorf("expected ErrInvalidJob for spec of chain %v, got %v", jobChainID, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRunWithRecordedChain(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	job := &gethlylejobs.GethProcessJob{ID: utils.Ptr(6), StartBlockNumber: utils.Ptr(uint64(1))}
+	expectUpdateJob(mock)
+	expectChunk(mock, 6, 60)
+	expectUpdateJob(mock)
+
+	pool := common.HexToAddress("0x01")
+	fake := gethlyleclient.NewFake(&gethlyleclient.Fixture{Logs: append(logsInBlocks(1, 40), types.Log{Address: common.HexToAddress("0x02"), BlockNumber: 50})})
+	fake.MaxLogs = 25
+	var logs []types.Log
+	handler := NewHandler(ethereum.FilterQuery{Addresses: []common.Address{pool}},
+		func(ctx context.Context, tx utils.PgxIface, job *gethlylejobs.GethProcessJob, chunk Chunk, chunkLogs []types.Log) error {
+			logs = append(logs, chunkLogs...)
+			return nil
+		})
+	ix := New(mock, fake, []Handler{handler}, WithChunkSize(60, 60), WithConcurrency(1))
+	if err := ix.Run(context.Background(), job, 60); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 40 {
+		t.Errorf("expected the 40 logs of the pool, got %d", len(logs))
+	}
+	// 1-60 and 1-30 have too many logs
+	if queries := fake.Queries(); len(queries) != 5 {
+		t.Errorf("expected 5 queries, got %d", len(queries))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestIsTooManyResults(t *testing.T) {
+	for _, msg := range []string{
+		"query returned more than 10000 results",
+		"Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range",
+		"exceed maximum block range: 5000",
+	} {
+		if !IsTooManyResults(errors.New(msg)) {
+			t.Errorf("expected %q to be too many results", msg)
+		}
+	}
+	if IsTooManyResults(errors.New("connection refused")) || IsTooManyResults(nil) {
+		t.Error("expected other errors not to be too many results")
+	}
+}
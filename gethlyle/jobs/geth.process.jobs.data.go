@@ -2,417 +2,157 @@ package gethlylejobs
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
-	"time"
 
-	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
+	structuredvalue "github.com/kfukue/lyle-labs-libraries/v2/structuredValue"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 )
 
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// GethProcessJobRepository implements the standard queries on the
+// geth_process_jobs table.
+var GethProcessJobRepository = repository.New[GethProcessJob]("geth_process_jobs")
+
 func GetGethProcessJob(dbConnPgx utils.PgxIface, gethProcessJobID *int) (*GethProcessJob, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT 
-	id,  
-	uuid, 
-	name,
-	alternate_name,
-	start_date,
-	end_date,
-	description,
-	status_id,
-	job_category_id,
-	import_type_id,
-	chain_id,
-	start_block_number,
-	end_block_number,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	asset_id
-	FROM geth_process_jobs 
-	WHERE id = $1
-	`, *gethProcessJobID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	// from https://stackoverflow.com/questions/61704842/how-to-scan-a-queryrow-into-a-struct-with-pgx
+	return GetGethProcessJobCtx(ctx, dbConnPgx, gethProcessJobID)
+}
 
-	gethProcessJob, err := pgx.CollectOneRow(row, pgx.RowToStructByName[GethProcessJob])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &gethProcessJob, nil
+func GetGethProcessJobCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethProcessJobID *int) (*GethProcessJob, error) {
+	return GethProcessJobRepository.Get(ctx, dbConnPgx, *gethProcessJobID)
 }
 
 func GetLatestGethProcessJobByImportTypeIDAndAssetID(dbConnPgx utils.PgxIface, importTypeID, assetID *int) (*GethProcessJob, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT 
-	id,  
-	uuid, 
-	name,
-	alternate_name,
-	start_date,
-	end_date,
-	description,
-	status_id,
-	job_category_id,
-	import_type_id,
-	chain_id,
-	start_block_number,
-	end_block_number,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	asset_id
-	FROM geth_process_jobs 
-	WHERE import_type_id = $1
+	return GetLatestGethProcessJobByImportTypeIDAndAssetIDCtx(ctx, dbConnPgx, importTypeID, assetID)
+}
+
+func GetLatestGethProcessJobByImportTypeIDAndAssetIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, importTypeID, assetID *int) (*GethProcessJob, error) {
+	return GethProcessJobRepository.SelectOne(ctx, dbConnPgx, `WHERE import_type_id = $1
 	AND asset_id = $2
 	-- needs to be success
 	AND status_id =$3
 	ORDER BY id desc
-	LIMIT 1
-	`, *importTypeID, *assetID, utils.SUCCESS_STRUCTURED_VALUE_ID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	LIMIT 1`, *importTypeID, *assetID, structuredvalue.ID(structuredvalue.JobStatusSuccess, utils.SUCCESS_STRUCTURED_VALUE_ID))
+}
 
-	gethProcessJob, err := pgx.CollectOneRow(row, pgx.RowToStructByName[GethProcessJob])
+// GetLastGethProcessJobByImportTypeIDAndAssetID returns the newest job of
+// importTypeID and assetID whatever its status, e.g. a job that failed or
+// stopped while running.
+func GetLastGethProcessJobByImportTypeIDAndAssetID(dbConnPgx utils.PgxIface, importTypeID, assetID *int) (*GethProcessJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetLastGethProcessJobByImportTypeIDAndAssetIDCtx(ctx, dbConnPgx, importTypeID, assetID)
+}
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &gethProcessJob, nil
+func GetLastGethProcessJobByImportTypeIDAndAssetIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, importTypeID, assetID *int) (*GethProcessJob, error) {
+	return GethProcessJobRepository.SelectOne(ctx, dbConnPgx, `WHERE import_type_id = $1
+	AND asset_id = $2
+	ORDER BY id desc
+	LIMIT 1`, *importTypeID, *assetID)
 }
 
 func GetGethProcessJobList(dbConnPgx utils.PgxIface) ([]GethProcessJob, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-	id,  
-	uuid, 
-	name,
-	alternate_name,
-	start_date,
-	end_date,
-	description,
-	status_id,
-	job_category_id,
-	import_type_id,
-	chain_id,
-	start_block_number,
-	end_block_number,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at,
-	asset_id
-	FROM geth_process_jobs`)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethProcessJobListCtx(ctx, dbConnPgx)
+}
 
-	gethProcessJobs, err := pgx.CollectRows(results, pgx.RowToStructByName[GethProcessJob])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return gethProcessJobs, nil
+func GetGethProcessJobListCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]GethProcessJob, error) {
+	return GethProcessJobRepository.GetList(ctx, dbConnPgx, nil)
 }
 
 func RemoveGethProcessJob(dbConnPgx utils.PgxIface, gethProcessJobID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveGethProcessJob DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM geth_process_jobs WHERE id = $1`
+	return RemoveGethProcessJobCtx(ctx, dbConnPgx, gethProcessJobID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *gethProcessJobID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveGethProcessJobCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethProcessJobID *int) error {
+	return GethProcessJobRepository.Remove(ctx, dbConnPgx, *gethProcessJobID)
 }
 
 func UpdateGethProcessJob(dbConnPgx utils.PgxIface, gethProcessJob *GethProcessJob) error {
-	// if the gethProcessJob id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if gethProcessJob.ID == nil || *gethProcessJob.ID == 0 {
-		return errors.New("gethProcessJob has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateGethProcessJob DbConn.Begin   %s", err.Error())
-		return err
+	return UpdateGethProcessJobCtx(ctx, dbConnPgx, gethProcessJob)
+}
+
+func UpdateGethProcessJobCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethProcessJob *GethProcessJob) error {
+	return GethProcessJobRepository.Update(ctx, dbConnPgx, gethProcessJob)
+}
+
+// CheckpointGethProcessJob records endBlockNumber as the last block the job
+// has committed. It never moves the checkpoint back. Run it in the transaction
+// that writes the rows of the blocks, so both are committed together.
+func CheckpointGethProcessJob(dbConnPgx utils.PgxIface, gethProcessJobID *int, endBlockNumber uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return CheckpointGethProcessJobCtx(ctx, dbConnPgx, gethProcessJobID, endBlockNumber)
+}
+
+func CheckpointGethProcessJobCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethProcessJobID *int, endBlockNumber uint64) error {
+	if gethProcessJobID == nil || *gethProcessJobID == 0 {
+		return dberrors.InvalidInput("gethProcessJob has invalid ID")
 	}
 	sql := `UPDATE geth_process_jobs SET 
-		name=$1,
-		alternate_name=$2,
-		start_date=$3,
-		end_date=$4,
-		description=$5,
-		status_id=$6,
-		job_category_id=$7,
-		import_type_id=$8,
-		chain_id=$9,
-		start_block_number=$10,
-		end_block_number=$11,
-		updated_by=$12, 
-		updated_at=current_timestamp at time zone 'UTC',
-		asset_id =$13
-		WHERE id=$14 `
-
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		gethProcessJob.Name,             //1
-		gethProcessJob.AlternateName,    //2
-		gethProcessJob.StartDate,        //3
-		gethProcessJob.EndDate,          //4
-		gethProcessJob.Description,      //5
-		gethProcessJob.StatusID,         //6
-		gethProcessJob.JobCategoryID,    //7
-		gethProcessJob.ImportTypeID,     //8
-		gethProcessJob.ChainID,          //9
-		gethProcessJob.StartBlockNumber, //10
-		gethProcessJob.EndBlockNumber,   //11
-		gethProcessJob.UpdatedBy,        //12
-		gethProcessJob.AssetID,          //13
-		gethProcessJob.ID,               //14
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
+		end_block_number = $1,
+		updated_at = current_timestamp at time zone 'UTC'
+		WHERE id = $2
+		AND (end_block_number IS NULL OR end_block_number < $1)`
+	if _, err := dbConnPgx.Exec(ctx, sql, endBlockNumber, *gethProcessJobID); err != nil {
+		logging.ReturnedError(ctx, "CheckpointGethProcessJob", err, logging.Entity("geth_process_jobs"), logging.JobID(gethProcessJobID), logging.BlockNumber(endBlockNumber))
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
-
+	return nil
 }
 
 func InsertGethProcessJob(dbConnPgx utils.PgxIface, gethProcessJob *GethProcessJob) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertGethProcessJob DbConn.Begin   %s", err.Error())
-		return -1, err
-	}
-	var ID int
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO geth_process_jobs  
-	(
-		uuid, 
-		name,
-		alternate_name,
-		start_date,
-		end_date,
-		description,
-		status_id,
-		job_category_id,
-		import_type_id,
-		chain_id,
-		start_block_number,
-		end_block_number,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		asset_id
-		) VALUES (
-			uuid_generate_v4(),
-			$1,
-			$2,
-			$3,
-			$4,
-			$5,
-			$6,
-			$7,
-			$8,
-			$9,
-			$10,
-			$11,
-			$12,
-			current_timestamp at time zone 'UTC',
-			$12,
-			current_timestamp at time zone 'UTC',
-			$13
-		)
-		RETURNING id`,
-		gethProcessJob.Name,             //1
-		gethProcessJob.AlternateName,    //2
-		gethProcessJob.StartDate,        //3
-		gethProcessJob.EndDate,          //4
-		gethProcessJob.Description,      //5
-		gethProcessJob.StatusID,         //6
-		gethProcessJob.JobCategoryID,    //7
-		gethProcessJob.ImportTypeID,     //8
-		gethProcessJob.ChainID,          //9
-		gethProcessJob.StartBlockNumber, //10
-		gethProcessJob.EndBlockNumber,   //11
-		gethProcessJob.CreatedBy,        //12
-		gethProcessJob.AssetID,          //13
-	).Scan(&ID)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	return int(ID), nil
+	return InsertGethProcessJobCtx(ctx, dbConnPgx, gethProcessJob)
+}
+
+func InsertGethProcessJobCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethProcessJob *GethProcessJob) (int, error) {
+	return GethProcessJobRepository.Insert(ctx, dbConnPgx, gethProcessJob)
 }
 
 func InsertGethProcessJobList(dbConnPgx utils.PgxIface, gethProcessJobList []GethProcessJob) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range gethProcessJobList {
-		gethProcessJob := gethProcessJobList[i]
-
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(gethProcessJob.UUID)
-		row := []interface{}{
-			uuidString,                       //1
-			gethProcessJob.Name,              //2
-			gethProcessJob.AlternateName,     //3
-			&gethProcessJob.StartDate,        //4
-			&gethProcessJob.EndDate,          //5
-			gethProcessJob.Description,       //6
-			*gethProcessJob.StatusID,         //7
-			*gethProcessJob.JobCategoryID,    //8
-			*gethProcessJob.ImportTypeID,     //9
-			*gethProcessJob.ChainID,          //10
-			*gethProcessJob.StartBlockNumber, //11
-			*gethProcessJob.EndBlockNumber,   //12
-			gethProcessJob.CreatedBy,         //13
-			&gethProcessJob.CreatedAt,        //14
-			gethProcessJob.CreatedBy,         //15
-			&now,                             //16
-			*gethProcessJob.AssetID,          //17
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"geth_process_jobs"},
-		[]string{
-			"uuid",               //1
-			"name",               //2
-			"alternate_name",     //3
-			"start_date",         //4
-			"end_date",           //5
-			"description",        //6
-			"status_id",          //7
-			"job_category_id",    //8
-			"import_type_id",     //9
-			"chain_id",           //10
-			"start_block_number", //11
-			"end_block_number",   //12
-			"created_by",         //13
-			"created_at",         //14
-			"updated_by",         //15
-			"updated_at",         //16
-			"asset_id",           //17
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertGethProcessJobList: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
+	return InsertGethProcessJobListCtx(ctx, dbConnPgx, gethProcessJobList)
+}
 
-	return nil
+func InsertGethProcessJobListCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethProcessJobList []GethProcessJob) error {
+	_, err := GethProcessJobRepository.InsertMany(ctx, dbConnPgx, gethProcessJobList)
+	return dberrors.Wrap(err)
 }
 
 // for refinedev
-func GetGethProcessJobListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]GethProcessJob, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetGethProcessJobListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]GethProcessJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT 
-		id,  
-		uuid, 
-		name,
-		alternate_name,
-		start_date,
-		end_date,
-		description,
-		status_id,
-		job_category_id,
-		import_type_id,
-		chain_id,
-		start_block_number,
-		end_block_number,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		asset_id
-		FROM geth_process_jobs
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethProcessJobListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
+}
 
-	gethProcessJobList, err := pgx.CollectRows(results, pgx.RowToStructByName[GethProcessJob])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return gethProcessJobList, nil
+func GetGethProcessJobListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]GethProcessJob, error) {
+	return GethProcessJobRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
 }
 
 func GetTotalGethProcessJobCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalGethProcessJobCountCtx(ctx, dbConnPgx)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT COUNT(*) FROM geth_process_jobs`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalGethProcessJobCountCtx(ctx context.Context, dbConnPgx utils.PgxIface) (*int, error) {
+	return GethProcessJobRepository.Count(ctx, dbConnPgx)
 }
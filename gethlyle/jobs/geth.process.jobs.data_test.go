@@ -8,49 +8,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                 //1
-	"uuid",               //2
-	"name",               //3
-	"alternate_name",     //4
-	"start_date",         //5
-	"end_date",           //6
-	"description",        //7
-	"status_id",          //8
-	"job_category_id",    //9
-	"import_type_id",     //10
-	"chain_id",           //11
-	"start_block_number", //12
-	"end_block_number",   //13
-	"created_by",         //14
-	"created_at",         //15
-	"updated_by",         //16
-	"updated_at",         //17
-	"asset_id",           //18
-}
-var DBColumnsInsertGethProcessJobList = []string{
-	"uuid",               //1
-	"name",               //2
-	"alternate_name",     //3
-	"start_date",         //4
-	"end_date",           //5
-	"description",        //6
-	"status_id",          //7
-	"job_category_id",    //8
-	"import_type_id",     //9
-	"chain_id",           //10
-	"start_block_number", //11
-	"end_block_number",   //12
-	"created_by",         //13
-	"created_at",         //14
-	"updated_by",         //15
-	"updated_at",         //16
-	"asset_id",           //17
-}
+var DBColumns = GethProcessJobRepository.Columns()
+var DBColumnsInsertGethProcessJobList = GethProcessJobRepository.InsertColumns()
 
 var TestData1 = GethProcessJob{
 	ID:               utils.Ptr[int](1),
@@ -96,30 +61,7 @@ var TestData2 = GethProcessJob{
 var TestAllData = []GethProcessJob{TestData1, TestData2}
 
 func AddGethProcessJobToMockRows(mock pgxmock.PgxPoolIface, dataList []GethProcessJob) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,               //1
-			data.UUID,             //2
-			data.Name,             //3
-			data.AlternateName,    //4
-			data.StartDate,        //5
-			data.EndDate,          //6
-			data.Description,      //7
-			data.StatusID,         //8
-			data.JobCategoryID,    //9
-			data.ImportTypeID,     //10
-			data.ChainID,          //11
-			data.StartBlockNumber, //12
-			data.EndBlockNumber,   //13
-			data.CreatedBy,        //14
-			data.CreatedAt,        //15
-			data.UpdatedBy,        //16
-			data.UpdatedAt,        //17
-			data.AssetID,          //18
-		)
-	}
-	return rows
+	return GethProcessJobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetGethProcessJob(t *testing.T) {
@@ -155,8 +97,8 @@ func TestGetGethProcessJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(gethProcessJobID).WillReturnRows(noRows)
 	foundGethProcessJob, err := GetGethProcessJob(mock, &gethProcessJobID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethProcessJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethProcessJob != nil {
 		t.Errorf("Expected GethProcessJob From Method GetGethProcessJob: to be empty but got this: %v", foundGethProcessJob)
@@ -243,8 +185,8 @@ func TestGetLatestGethProcessJobByImportTypeIDAndAssetIDForErrNoRows(t *testing.
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(importTypeID, assetID, utils.SUCCESS_STRUCTURED_VALUE_ID).WillReturnRows(noRows)
 	foundGethProcessJob, err := GetLatestGethProcessJobByImportTypeIDAndAssetID(mock, &importTypeID, &assetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetLatestGethProcessJobByImportTypeIDAndAssetID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethProcessJob != nil {
 		t.Errorf("Expected GethProcessJob From Method GetLatestGethProcessJobByImportTypeIDAndAssetID: to be empty but got this: %v", foundGethProcessJob)
@@ -640,7 +582,7 @@ func TestInsertGethProcessJobOnFailureOnCommit(t *testing.T) {
 		targetData.StartBlockNumber, //10
 		targetData.EndBlockNumber,   //11
 		targetData.CreatedBy,        //12
-		targetData.AssetID,          //136
+		targetData.AssetID,          //13
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -702,8 +644,8 @@ func TestGetGethProcessJobListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"job_category_id = 1", "status_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("job_category_id", "1"), filter.Equal("status_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethProcessJobList, err := GetGethProcessJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethProcessJobListByPagination", err)
@@ -728,8 +670,8 @@ func TestGetGethProcessJobListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"status_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("status_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethProcessJobList, err := GetGethProcessJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethProcessJobListByPagination", err)
@@ -752,9 +694,9 @@ func TestGetGethProcessJobListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"status_id = 1"}
+	filters := filter.All(filter.Equal("status_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethProcessJobList, err := GetGethProcessJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethProcessJobListByPagination", err)
@@ -805,3 +747,66 @@ func TestGetTotalGethProcessJobCountForErr(t *testing.T) {
 		t.Errorf("There awere unfulfilled expectations: %s", err)
 	}
 }
+
+func TestGetLastGethProcessJobByImportTypeIDAndAssetID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	importTypeID := targetData.ImportTypeID
+	assetID := targetData.AssetID
+	mockRows := AddGethProcessJobToMockRows(mock, []GethProcessJob{targetData})
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(*importTypeID, *assetID).WillReturnRows(mockRows)
+	foundGethProcessJob, err := GetLastGethProcessJobByImportTypeIDAndAssetID(mock, importTypeID, assetID)
+	if err != nil {
+		t.Fatalf("an error '%s' in GetLastGethProcessJobByImportTypeIDAndAssetID", err)
+	}
+	if cmp.Equal(*foundGethProcessJob, targetData) == false {
+		t.Errorf("Expected GethProcessJob From Method GetLastGethProcessJobByImportTypeIDAndAssetID: %v is different from actual %v", foundGethProcessJob, targetData)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetLastGethProcessJobByImportTypeIDAndAssetIDForErrNoRows(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	importTypeID := 1
+	assetID := 2
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_jobs").WithArgs(importTypeID, assetID).WillReturnRows(pgxmock.NewRows(DBColumns))
+	foundGethProcessJob, err := GetLastGethProcessJobByImportTypeIDAndAssetID(mock, &importTypeID, &assetID)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound in GetLastGethProcessJobByImportTypeIDAndAssetID, got %v", err)
+	}
+	if foundGethProcessJob != nil {
+		t.Errorf("Expected GethProcessJob From Method GetLastGethProcessJobByImportTypeIDAndAssetID: to be empty but got this: %v", foundGethProcessJob)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCheckpointGethProcessJob(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	jobID := 3
+	mock.ExpectExec("^UPDATE geth_process_jobs SET end_block_number").WithArgs(uint64(17387265), jobID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	if err := CheckpointGethProcessJob(mock, &jobID, 17387265); err != nil {
+		t.Fatalf("an error '%s' in CheckpointGethProcessJob", err)
+	}
+	if err := CheckpointGethProcessJob(mock, nil, 17387265); !errors.Is(err, dberrors.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput without a job id, got %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
@@ -7,37 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                  //1
-	"geth_process_job_id", //2
-	"uuid",                //3
-	"name",                //4
-	"alternate_name",      //5
-	"description",         //6
-	"status_id",           //7
-	"topic_str",           //8
-	"created_by",          //9
-	"created_at",          //10
-	"updated_by",          //11
-	"updated_at",          //12
-}
-var DBColumnsInsertGethProcessJobTopicList = []string{
-	"geth_process_job_id", //1
-	"uuid",                //2
-	"name",                //3
-	"alternate_name",      //4
-	"description",         //5
-	"status_id",           //6
-	"topic_str",           //7
-	"created_by",          //8
-	"created_at",          //9
-	"updated_by",          //10
-	"updated_at",          //11
-}
+var DBColumns = GethProcessJobTopicRepository.Columns()
+var DBColumnsInsertGethProcessJobTopicList = GethProcessJobTopicRepository.InsertColumns()
 
 var TestData1 = GethProcessJobTopic{
 	ID:            utils.Ptr[int](1),
@@ -69,24 +46,7 @@ var TestData2 = GethProcessJobTopic{
 var TestAllData = []GethProcessJobTopic{TestData1, TestData2}
 
 func AddGethProcessJobTopicToMockRows(mock pgxmock.PgxPoolIface, dataList []GethProcessJobTopic) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,               //1
-			data.GethProcessJobID, //2
-			data.UUID,             //3
-			data.Name,             //4
-			data.AlternateName,    //5
-			data.Description,      //6
-			data.StatusID,         //7
-			data.TopicStr,         //8
-			data.CreatedBy,        //9
-			data.CreatedAt,        //10
-			data.UpdatedBy,        //11
-			data.UpdatedAt,        //12
-		)
-	}
-	return rows
+	return GethProcessJobTopicRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetGethProcessJobTopic(t *testing.T) {
@@ -122,8 +82,8 @@ func TestGetGethProcessJobTopicForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_process_job_topics").WithArgs(gethProcessJobTopicID).WillReturnRows(noRows)
 	foundGethProcessJobTopic, err := GetGethProcessJobTopic(mock, &gethProcessJobTopicID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethProcessJobTopic", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethProcessJobTopic != nil {
 		t.Errorf("Expected GethProcessJobTopic From Method GetGethProcessJobTopic: to be empty but got this: %v", foundGethProcessJobTopic)
@@ -530,8 +490,8 @@ func TestGetGethProcessJobTopicListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"geth_process_job_id = 1", "name='test'"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_job_topics").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("geth_process_job_id", "1"), filter.Equal("name", "test"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_job_topics").WithArgs(int64(1), "test", _start, _end-_start).WillReturnRows(mockRows)
 	foundGethProcessJobTopicList, err := GetGethProcessJobTopicListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethProcessJobTopicListByPagination", err)
@@ -556,8 +516,8 @@ func TestGetGethProcessJobTopicListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"geth_process_job_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_job_topics").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("geth_process_job_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_job_topics").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethProcessJobTopicList, err := GetGethProcessJobTopicListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethProcessJobTopicListByPagination", err)
@@ -580,9 +540,9 @@ func TestGetGethProcessJobTopicListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"geth_process_job_id = 1"}
+	filters := filter.All(filter.Equal("geth_process_job_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_job_topics").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_job_topics").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethProcessJobTopicList, err := GetGethProcessJobTopicListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethProcessJobTopicListByPagination", err)
@@ -7,56 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
-	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                  //1
-	"geth_process_job_id", //2
-	"uuid",                //3
-	"name",                //4
-	"alternate_name",      //5
-	"start_date",          //6
-	"end_date",            //7
-	"description",         //8
-	"status_id",           //9
-	"job_category_id",     //10
-	"asset_id",            //11
-	"chain_id",            //12
-	"txn_hash",            //13
-	"address_id",          //14
-	"block_number",        //15
-	"index_number",        //16
-	"topics_str",          //17
-	"created_by",          //18
-	"created_at",          //19
-	"updated_by",          //20
-	"updated_at",          //21
-}
-var DBColumnsInsertGethProcessVlogJobList = []string{
-	"geth_process_job_id", //1
-	"uuid",                //2
-	"name",                //3
-	"alternate_name",      //4
-	"start_date",          //5
-	"end_date",            //6
-	"description",         //7
-	"status_id",           //8
-	"job_category_id",     //9
-	"asset_id",            //10
-	"chain_id",            //11
-	"txn_hash",            //12
-	"address_id",          //13
-	"block_number",        //14
-	"index_number",        //15
-	"topics_str",          //16
-	"created_by",          //17
-	"created_at",          //18
-	"updated_by",          //19
-	"updated_at",          //20
-}
+var DBColumns = GethProcessVlogJobRepository.Columns()
+var DBColumnsInsertGethProcessVlogJobList = GethProcessVlogJobRepository.InsertColumns()
 
 var TestData1 = GethProcessVlogJob{
 	ID:             utils.Ptr[int](1),
@@ -106,33 +64,7 @@ var TestData2 = GethProcessVlogJob{
 var TestAllData = []GethProcessVlogJob{TestData1, TestData2}
 
 func AddGethProcessVlogJobToMockRows(mock pgxmock.PgxPoolIface, dataList []GethProcessVlogJob) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,               //1
-			data.GethProcessJobID, //2
-			data.UUID,             //3
-			data.Name,             //4
-			data.AlternateName,    //5
-			data.StartDate,        //6
-			data.EndDate,          //7
-			data.Description,      //8
-			data.StatusID,         //9
-			data.JobCategoryID,    //10
-			data.AssetID,          //11
-			data.ChainID,          //12
-			data.TxnHash,          //13
-			data.AddressID,        //14
-			data.BlockNumber,      //15
-			data.IndexNumber,      //16
-			data.TopicsStrArray,   //17
-			data.CreatedBy,        //18
-			data.CreatedAt,        //19
-			data.UpdatedBy,        //20
-			data.UpdatedAt,        //21
-		)
-	}
-	return rows
+	return GethProcessVlogJobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetGethProcessVlogJob(t *testing.T) {
@@ -168,8 +100,8 @@ func TestGetGethProcessVlogJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_process_vlog_jobs").WithArgs(gethProcessVlogJobID).WillReturnRows(noRows)
 	foundGethProcessVlogJob, err := GetGethProcessVlogJob(mock, &gethProcessVlogJobID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethProcessVlogJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethProcessVlogJob != nil {
 		t.Errorf("Expected GethProcessVlogJob From Method GetGethProcessVlogJob: to be empty but got this: %v", foundGethProcessVlogJob)
@@ -348,23 +280,23 @@ func TestUpdateGethProcessVlogJob(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_process_vlog_jobs").WithArgs(
-		targetData.GethProcessJobID,         //1
-		targetData.Name,                     //2
-		targetData.AlternateName,            //3
-		targetData.StartDate,                //4
-		targetData.EndDate,                  //5
-		targetData.Description,              //6
-		targetData.StatusID,                 //7
-		targetData.JobCategoryID,            //8
-		targetData.AssetID,                  //9
-		targetData.ChainID,                  //10
-		targetData.TxnHash,                  //11
-		targetData.AddressID,                //12
-		targetData.BlockNumber,              //13
-		targetData.IndexNumber,              //14
-		pq.Array(targetData.TopicsStrArray), //15
-		targetData.UpdatedBy,                //16
-		targetData.ID,                       //17
+		targetData.GethProcessJobID, //1
+		targetData.Name,             //2
+		targetData.AlternateName,    //3
+		targetData.StartDate,        //4
+		targetData.EndDate,          //5
+		targetData.Description,      //6
+		targetData.StatusID,         //7
+		targetData.JobCategoryID,    //8
+		targetData.AssetID,          //9
+		targetData.ChainID,          //10
+		targetData.TxnHash,          //11
+		targetData.AddressID,        //12
+		targetData.BlockNumber,      //13
+		targetData.IndexNumber,      //14
+		targetData.TopicsStrArray,   //15
+		targetData.UpdatedBy,        //16
+		targetData.ID,               //17
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateGethProcessVlogJob(mock, &targetData)
@@ -423,23 +355,23 @@ func TestUpdateGethProcessVlogJobOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_process_vlog_jobs").WithArgs(
-		targetData.GethProcessJobID,         //1
-		targetData.Name,                     //2
-		targetData.AlternateName,            //3
-		targetData.StartDate,                //4
-		targetData.EndDate,                  //5
-		targetData.Description,              //6
-		targetData.StatusID,                 //7
-		targetData.JobCategoryID,            //8
-		targetData.AssetID,                  //9
-		targetData.ChainID,                  //10
-		targetData.TxnHash,                  //11
-		targetData.AddressID,                //12
-		targetData.BlockNumber,              //13
-		targetData.IndexNumber,              //14
-		pq.Array(targetData.TopicsStrArray), //15
-		targetData.UpdatedBy,                //16
-		targetData.ID,                       //17
+		targetData.GethProcessJobID, //1
+		targetData.Name,             //2
+		targetData.AlternateName,    //3
+		targetData.StartDate,        //4
+		targetData.EndDate,          //5
+		targetData.Description,      //6
+		targetData.StatusID,         //7
+		targetData.JobCategoryID,    //8
+		targetData.AssetID,          //9
+		targetData.ChainID,          //10
+		targetData.TxnHash,          //11
+		targetData.AddressID,        //12
+		targetData.BlockNumber,      //13
+		targetData.IndexNumber,      //14
+		targetData.TopicsStrArray,   //15
+		targetData.UpdatedBy,        //16
+		targetData.ID,               //17
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -640,8 +572,8 @@ func TestGetGethProcessVlogJobListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"job_category_id = 1", "asset_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_vlog_jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("job_category_id", "1"), filter.Equal("asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_vlog_jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethProcessVlogJobList, err := GetGethProcessVlogJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethProcessVlogJobListByPagination", err)
@@ -666,8 +598,8 @@ func TestGetGethProcessVlogJobListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"job_category_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_vlog_jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("job_category_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_vlog_jobs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethProcessVlogJobList, err := GetGethProcessVlogJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethProcessVlogJobListByPagination", err)
@@ -690,9 +622,9 @@ func TestGetGethProcessVlogJobListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"job_category_id = 1"}
+	filters := filter.All(filter.Equal("job_category_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_process_vlog_jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_process_vlog_jobs").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethProcessVlogJobList, err := GetGethProcessVlogJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethProcessVlogJobListByPagination", err)
@@ -757,23 +689,23 @@ func TestUpdateFailedGethProcessVlogJob(t *testing.T) {
 	newDescription := fmt.Sprintf("%s \n %s", targetData.Description, msg)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_process_vlog_jobs").WithArgs(
-		targetData.GethProcessJobID,         //1
-		targetData.Name,                     //2
-		targetData.AlternateName,            //3
-		targetData.StartDate,                //4
-		targetData.EndDate,                  //5
-		newDescription,                      //6
-		&newStatusID,                        //7
-		targetData.JobCategoryID,            //8
-		targetData.AssetID,                  //9
-		targetData.ChainID,                  //10
-		targetData.TxnHash,                  //11
-		targetData.AddressID,                //12
-		targetData.BlockNumber,              //13
-		targetData.IndexNumber,              //14
-		pq.Array(targetData.TopicsStrArray), //15
-		targetData.UpdatedBy,                //16
-		targetData.ID,                       //17
+		targetData.GethProcessJobID, //1
+		targetData.Name,             //2
+		targetData.AlternateName,    //3
+		targetData.StartDate,        //4
+		targetData.EndDate,          //5
+		newDescription,              //6
+		&newStatusID,                //7
+		targetData.JobCategoryID,    //8
+		targetData.AssetID,          //9
+		targetData.ChainID,          //10
+		targetData.TxnHash,          //11
+		targetData.AddressID,        //12
+		targetData.BlockNumber,      //13
+		targetData.IndexNumber,      //14
+		targetData.TopicsStrArray,   //15
+		targetData.UpdatedBy,        //16
+		targetData.ID,               //17
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateFailedGethProcessVlogJob(mock, &targetData, msg, doUpdate)
@@ -801,23 +733,23 @@ func TestUpdateFailedGethProcessVlogJobOnFailure(t *testing.T) {
 	newDescription := fmt.Sprintf("%s \n %s", targetData.Description, msg)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_process_vlog_jobs").WithArgs(
-		targetData.GethProcessJobID,         //1
-		targetData.Name,                     //2
-		targetData.AlternateName,            //3
-		targetData.StartDate,                //4
-		targetData.EndDate,                  //5
-		newDescription,                      //6
-		&newStatusID,                        //7
-		targetData.JobCategoryID,            //8
-		targetData.AssetID,                  //9
-		targetData.ChainID,                  //10
-		targetData.TxnHash,                  //11
-		targetData.AddressID,                //12
-		targetData.BlockNumber,              //13
-		targetData.IndexNumber,              //14
-		pq.Array(targetData.TopicsStrArray), //15
-		targetData.UpdatedBy,                //16
-		targetData.ID,                       //17
+		targetData.GethProcessJobID, //1
+		targetData.Name,             //2
+		targetData.AlternateName,    //3
+		targetData.StartDate,        //4
+		targetData.EndDate,          //5
+		newDescription,              //6
+		&newStatusID,                //7
+		targetData.JobCategoryID,    //8
+		targetData.AssetID,          //9
+		targetData.ChainID,          //10
+		targetData.TxnHash,          //11
+		targetData.AddressID,        //12
+		targetData.BlockNumber,      //13
+		targetData.IndexNumber,      //14
+		targetData.TopicsStrArray,   //15
+		targetData.UpdatedBy,        //16
+		targetData.ID,               //17
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
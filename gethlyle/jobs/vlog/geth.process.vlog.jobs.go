@@ -1,10 +1,12 @@
 package gethlylevlogjobs
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	structuredvalue "github.com/kfukue/lyle-labs-libraries/v2/structuredValue"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 )
 
@@ -34,14 +36,19 @@ type GethProcessVlogJob struct {
 }
 
 func UpdateFailedGethProcessVlogJob(dbConnPgx utils.PgxIface, gethProcessVlogJob *GethProcessVlogJob, msg string, doUpdate bool) error {
-	failedStatus := utils.FAILED_STRUCTURED_VALUE_ID
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return UpdateFailedGethProcessVlogJobCtx(ctx, dbConnPgx, gethProcessVlogJob, msg, doUpdate)
+}
+
+func UpdateFailedGethProcessVlogJobCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethProcessVlogJob *GethProcessVlogJob, msg string, doUpdate bool) error {
+	failedStatus := structuredvalue.ID(structuredvalue.JobStatusFailed, utils.FAILED_STRUCTURED_VALUE_ID)
 	gethProcessVlogJob.StatusID = &failedStatus
 	gethProcessVlogJob.Description = fmt.Sprintf("%s \n %s", gethProcessVlogJob.Description, msg)
 	if doUpdate {
-		err := UpdateGethProcessVlogJob(dbConnPgx, gethProcessVlogJob)
+		err := UpdateGethProcessVlogJobCtx(ctx, dbConnPgx, gethProcessVlogJob)
 		if err != nil {
-			msg = fmt.Sprintf("Failed in UpdateGethProcessVlogJob, err %v", err)
-			log.Println(msg)
+			logging.ReturnedError(ctx, "UpdateFailedGethProcessVlogJob", err, logging.Entity("geth_process_vlog_jobs"), logging.JobID(gethProcessVlogJob.ID))
 			return err
 		}
 	}
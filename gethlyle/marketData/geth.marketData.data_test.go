@@ -7,71 +7,16 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/shopspring/decimal"
 )
 
-var DBColumns = []string{
-	"id",                  //1
-	"uuid",                //2
-	"name",                //3
-	"alternate_name",      //4
-	"start_date",          //5
-	"end_date",            //6
-	"asset_id",            //7
-	"open_usd",            //8
-	"close_usd",           //9
-	"high_usd",            //10
-	"low_usd",             //11
-	"price_usd",           //12
-	"volume_usd",          //13
-	"market_cap_usd",      //14
-	"ticker",              //15
-	"description",         //16
-	"interval_id",         //17
-	"market_data_type_id", //18
-	"source_id",           //19
-	"total_supply",        //20
-	"max_supply",          //21
-	"circulating_supply",  //22
-	"sparkline_7d",        //23
-	"created_by",          //24
-	"created_at",          //25
-	"updated_by",          //26
-	"updated_at",          //27
-	"geth_process_job_id", //28
-}
-var DBColumnsInsertGethMarketDataList = []string{
-	"uuid",                //1
-	"name",                //2
-	"alternate_name",      //3
-	"start_date",          //4
-	"end_date",            //5
-	"asset_id",            //6
-	"open_usd",            //7
-	"close_usd",           //8
-	"high_usd",            //9
-	"low_usd",             //10
-	"price_usd",           //11
-	"volume_usd",          //12
-	"market_cap_usd",      //13
-	"ticker",              //14
-	"description",         //15
-	"interval_id",         //16
-	"market_data_type_id", //17
-	"source_id",           //18
-	"total_supply",        //19
-	"max_supply",          //20
-	"circulating_supply",  //21
-	"sparkline_7d",        //22
-	"created_by",          //23
-	"created_at",          //24
-	"updated_by",          //25
-	"updated_at",          //26
-	"geth_process_job_id", //27
-}
+var DBColumns = GethMarketDataRepository.Columns()
+var DBColumnsInsertGethMarketDataList = GethMarketDataRepository.InsertColumns()
 var fakeSparklineData = []decimal.Decimal{
 	decimal.NewFromFloat(1.0),
 	decimal.NewFromFloat(2.0),
@@ -146,40 +91,7 @@ var TestData2 = GethMarketData{
 var TestAllData = []GethMarketData{TestData1, TestData2}
 
 func AddGethMarketDataToMockRows(mock pgxmock.PgxPoolIface, dataList []GethMarketData) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                //1
-			data.UUID,              //2
-			data.Name,              //3
-			data.AlternateName,     //4
-			data.StartDate,         //5
-			data.EndDate,           //6
-			data.AssetID,           //7
-			data.OpenUSD,           //8
-			data.CloseUSD,          //9
-			data.HighUSD,           //10
-			data.LowUSD,            //11
-			data.PriceUSD,          //12
-			data.VolumeUSD,         //13
-			data.MarketCapUSD,      //14
-			data.Ticker,            //15
-			data.Description,       //16
-			data.IntervalID,        //17
-			data.MarketDataTypeID,  //18
-			data.SourceID,          //19
-			data.TotalSupply,       //20
-			data.MaxSupply,         //21
-			data.CirculatingSupply, //22
-			data.Sparkline7d,       //23
-			data.CreatedBy,         //24
-			data.CreatedAt,         //25
-			data.UpdatedBy,         //26
-			data.UpdatedAt,         //27
-			data.GethProcessJobID,  //28
-		)
-	}
-	return rows
+	return GethMarketDataRepository.AddToMockRows(mock, dataList)
 }
 func TestGetMinAndMaxDatesFromGethMarketByAssetID(t *testing.T) {
 	mock, err := pgxmock.NewPool()
@@ -220,8 +132,8 @@ func TestGetMinAndMaxDatesFromGethMarketByAssetIDForErrNoRows(t *testing.T) {
 	noRows := mock.NewRows([]string{"min_date", "max_date"})
 	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(assetID, marketDataTypeID).WillReturnRows(noRows)
 	startDateResult, endDateResult, err := GetMinAndMaxDatesFromGethMarketByAssetID(mock, &assetID, &marketDataTypeID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetMinAndMaxDatesFromGethMarketByAssetID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if startDateResult != nil {
 		t.Errorf("startDateResult From Method GetMinAndMaxDatesFromGethMarketByAssetID: to be empty but got this: %v", startDateResult)
@@ -291,8 +203,8 @@ func TestGetGethMarketDataForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(marketDataID).WillReturnRows(noRows)
 	foundMarketData, err := GetGethMarketData(mock, &marketDataID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethMarketData", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundMarketData != nil {
 		t.Errorf("Expected GethMarketData From Method GetGethMarketData: to be empty but got this: %v", foundMarketData)
@@ -383,8 +295,8 @@ func TestGetGethMarketDataByAssetIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(startDateStr, assetID, marketDataTypeID).WillReturnRows(noRows)
 	foundMarketData, err := GetGethMarketDataByAssetID(mock, &startDate, &assetID, &marketDataTypeID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethMarketDataByAssetID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundMarketData != nil {
 		t.Errorf("Expected GethMarketData From Method GetGethMarketDataByAssetID: to be empty but got this: %v", foundMarketData)
@@ -695,8 +607,8 @@ func TestGetGethMarketDataList(t *testing.T) {
 	defer mock.Close()
 	dataList := []GethMarketData{TestData1, TestData2}
 	mockRows := AddGethMarketDataToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WillReturnRows(mockRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(ids).WillReturnRows(mockRows)
 	foundMarketDataList, err := GetGethMarketDataList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethMarketDataList", err)
@@ -720,7 +632,7 @@ func TestGetGethMarketDataListForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	ids := []int{1, 2}
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundMarketDataList, err := GetGethMarketDataList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethMarketDataList", err)
@@ -894,30 +806,30 @@ func TestUpdateGethMarketData(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.UpdatedBy,             //22
-		targetData.GethProcessJobID,      //23
-		targetData.ID,                    //24
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.UpdatedBy,         //22
+		targetData.GethProcessJobID,  //23
+		targetData.ID,                //24
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateGethMarketData(mock, &targetData)
@@ -976,30 +888,30 @@ func TestUpdateGethMarketDataOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.UpdatedBy,             //22
-		targetData.GethProcessJobID,      //23
-		targetData.ID,                    //24
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.UpdatedBy,         //22
+		targetData.GethProcessJobID,  //23
+		targetData.ID,                //24
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -1022,29 +934,29 @@ func TestInsertGethMarketData(t *testing.T) {
 	targetData.Name = "New Name"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.CreatedBy,             //22
-		targetData.GethProcessJobID,      //23
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.CreatedBy,         //22
+		targetData.GethProcessJobID,  //23
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 	marketDataID, err := InsertGethMarketData(mock, &targetData)
@@ -1089,29 +1001,29 @@ func TestInsertGethMarketDataOnFailure(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.CreatedBy,             //22
-		targetData.GethProcessJobID,      //23
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.CreatedBy,         //22
+		targetData.GethProcessJobID,  //23
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	marketDataID, err := InsertGethMarketData(mock, &targetData)
@@ -1137,29 +1049,29 @@ func TestInsertGethMarketDataOnFailureOnCommit(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.CreatedBy,             //22
-		targetData.GethProcessJobID,      //23
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.CreatedBy,         //22
+		targetData.GethProcessJobID,  //23
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -1221,8 +1133,8 @@ func TestGetGethMarketDataListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"asset_id = 1", "source_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("asset_id", "1"), filter.Equal("source_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundMarketDataList, err := GetGethMarketDataListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethMarketDataListByPagination", err)
@@ -1247,8 +1159,8 @@ func TestGetGethMarketDataListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"asset_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("asset_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundMarketDataList, err := GetGethMarketDataListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethMarketDataListByPagination", err)
@@ -1271,9 +1183,9 @@ func TestGetGethMarketDataListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"asset_id = 1"}
+	filters := filter.All(filter.Equal("asset_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_market_data").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundMarketDataList, err := GetGethMarketDataListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethMarketDataListByPagination", err)
@@ -7,51 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                    //1
-	"uuid",                  //2
-	"name",                  //3
-	"alternate_name",        //4
-	"chain_id",              //5
-	"exchange_id",           //6
-	"starting_block_number", //7
-	"created_txn_hash",      //8
-	"last_block_number",     //9
-	"contract_address",      //10
-	"contract_address_id",   //11
-	"developer_address",     //12
-	"developer_address_id",  //13
-	"mining_asset_id",       //14
-	"description",           //15
-	"created_by",            //16
-	"created_at",            //17
-	"updated_by",            //18
-	"updated_at",            //19
-}
-var DBColumnsInsertGethMiners = []string{
-	"uuid",                  //1
-	"name",                  //2
-	"alternate_name",        //3
-	"chain_id",              //4
-	"exchange_id",           //5
-	"starting_block_number", //6
-	"created_txn_hash",      //7
-	"last_block_number",     //8
-	"contract_address",      //9
-	"contract_address_id",   //10
-	"developer_address",     //11
-	"developer_address_id",  //12
-	"mining_asset_id",       //13
-	"description",           //14
-	"created_by",            //15
-	"created_at",            //16
-	"updated_by",            //17
-	"updated_at",            //18
-}
+var DBColumns = GethMinerRepository.Columns()
+var DBColumnsInsertGethMiners = GethMinerRepository.InsertColumns()
 
 var TestData1 = GethMiner{
 	ID:            utils.Ptr[int](1),
@@ -100,31 +63,7 @@ var TestData2 = GethMiner{
 var TestAllData = []GethMiner{TestData1, TestData2}
 
 func AddGethMinerToMockRows(mock pgxmock.PgxPoolIface, dataList []GethMiner) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                  //1
-			data.UUID,                //2
-			data.Name,                //3
-			data.AlternateName,       //4
-			data.ChainID,             //5
-			data.ExchangeID,          //6
-			data.StartingBlockNumber, //7
-			data.CreatedTxnHash,      //8
-			data.LastBlockNumber,     //9
-			data.ContractAddress,     //10
-			data.ContractAddressID,   //11
-			data.DeveloperAddress,    //12
-			data.DeveloperAddressID,  //13
-			data.MiningAssetID,       //14
-			data.Description,         //15
-			data.CreatedBy,           //16
-			data.CreatedAt,           //17
-			data.UpdatedBy,           //18
-			data.UpdatedAt,           //19
-		)
-	}
-	return rows
+	return GethMinerRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetGethMiner(t *testing.T) {
@@ -160,8 +99,8 @@ func TestGetGethMinerForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_miners").WithArgs(gethMinerID).WillReturnRows(noRows)
 	foundGethMiner, err := GetGethMiner(mock, &gethMinerID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethMiner", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethMiner != nil {
 		t.Errorf("Expected GethMiner From Method GetGethMiner: to be empty but got this: %v", foundGethMiner)
@@ -774,8 +713,8 @@ func TestGetGethMinerListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1", "exchange_id= 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("chain_id", "1"), filter.Equal("exchange_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethMinerList, err := GetGethMinerListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethMinerListByPagination", err)
@@ -800,8 +739,8 @@ func TestGetGethMinerListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"exchange_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("exchange_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethMinerList, err := GetGethMinerListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethMinerListByPagination", err)
@@ -824,9 +763,9 @@ func TestGetGethMinerListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"exchange_id = 1"}
+	filters := filter.All(filter.Equal("exchange_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethMinerList, err := GetGethMinerListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethMinerListByPagination", err)
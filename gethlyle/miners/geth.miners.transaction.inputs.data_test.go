@@ -7,34 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumnsTransactionInputs = []string{
-	"miner_id",             //1
-	"transaction_input_id", //2
-	"uuid",                 //3
-	"name",                 //4
-	"alternate_name",       //5
-	"description",          //6
-	"created_by",           //7
-	"created_at",           //8
-	"updated_by",           //9
-	"updated_at",           //10
-}
-var DBColumnsInsertGethMinersTransactionInputs = []string{
-	"miner_id",             //1
-	"transaction_input_id", //2
-	"uuid",                 //3
-	"name",                 //4
-	"alternate_name",       //5
-	"description",          //6
-	"created_by",           //7
-	"created_at",           //8
-	"updated_by",           //9
-	"updated_at",           //10
-}
+var DBColumnsTransactionInputs = GethMinerTransactionInputRepository.Columns()
+var DBColumnsInsertGethMinersTransactionInputs = GethMinerTransactionInputRepository.InsertColumns()
 
 var TestData1MinerTransactionInput = GethMinerTransactionInput{
 	MinerID:            utils.Ptr[int](1),
@@ -64,22 +44,7 @@ var TestData2MinerTransactionInput = GethMinerTransactionInput{
 var TestAllDataMinerTransactionInput = []GethMinerTransactionInput{TestData1MinerTransactionInput, TestData2MinerTransactionInput}
 
 func AddGethMinerTransactionInputToMockRows(mock pgxmock.PgxPoolIface, dataList []GethMinerTransactionInput) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumnsTransactionInputs)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.MinerID,            //1
-			data.TransactionInputID, //2
-			data.UUID,               //3
-			data.Name,               //4
-			data.AlternateName,      //5
-			data.Description,        //6
-			data.CreatedBy,          //7
-			data.CreatedAt,          //8
-			data.UpdatedBy,          //9
-			data.UpdatedAt,          //10
-		)
-	}
-	return rows
+	return GethMinerTransactionInputRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetAllGethMinerTransactionInputsByMinerID(t *testing.T) {
@@ -249,8 +214,8 @@ func TestGetGethMinerTransactionInputForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumnsTransactionInputs)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WithArgs(gethMinerID, transactionInputID).WillReturnRows(noRows)
 	foundGethMinerTransactionInput, err := GetGethMinerTransactionInput(mock, &gethMinerID, &transactionInputID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethMinerTransactionInput", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethMinerTransactionInput != nil {
 		t.Errorf("Expected GethMinerTransactionInput From Method GetGethMinerTransactionInput: to be empty but got this: %v", foundGethMinerTransactionInput)
@@ -370,9 +335,9 @@ func TestGetGethMinerTransactionInputList(t *testing.T) {
 	defer mock.Close()
 	dataList := []GethMinerTransactionInput{TestData1MinerTransactionInput, TestData2MinerTransactionInput}
 	mockRows := AddGethMinerTransactionInputToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WillReturnRows(mockRows)
 	minerIDs := []int{1, 2}
 	transactionInputIDs := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WithArgs(minerIDs, transactionInputIDs).WillReturnRows(mockRows)
 	foundGethMinerTransactionInputList, err := GetGethMinerTransactionInputList(mock, minerIDs, transactionInputIDs)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethMinerTransactionInputList", err)
@@ -686,10 +651,10 @@ func TestGetMinerTransactionInputListByPagination(t *testing.T) {
 	mockRows := AddGethMinerTransactionInputToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "miner_id"
 	_order := "ASC"
-	filters := []string{"transaction_input_id = 1", "miner_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("transaction_input_id", "1"), filter.Equal("miner_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethMinerTransactionInputList, err := GetMinerTransactionInputListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetMinerTransactionInputListByPagination", err)
@@ -712,10 +677,10 @@ func TestGetMinerTransactionInputListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "miner_id"
 	_order := "ASC"
-	filters := []string{"miner_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("miner_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethMinerTransactionInputList, err := GetMinerTransactionInputListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMinerTransactionInputListByPagination", err)
@@ -736,11 +701,11 @@ func TestGetMinerTransactionInputListByPaginationForCollectRowsErr(t *testing.T)
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "miner_id"
 	_order := "ASC"
-	filters := []string{"miner_id = 1"}
+	filters := filter.All(filter.Equal("miner_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transaction_inputs").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethMinerTransactionInputList, err := GetMinerTransactionInputListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMinerTransactionInputListByPagination", err)
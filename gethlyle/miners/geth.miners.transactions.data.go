@@ -2,51 +2,38 @@ package gethlyleminers
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
 	"time"
 
-	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 )
 
+// GethMinerTransactionRepository implements the standard queries on the
+// geth_miners_transactions table, keyed on the miner and the transaction.
+var GethMinerTransactionRepository = repository.New[GethMinerTransaction]("geth_miners_transactions",
+	repository.Key("miner_id", "transaction_id"),
+)
+
 func GetAllGethMinerTransactionsByMinerID(dbConnPgx utils.PgxIface, minerID *int) ([]GethMinerTransaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `
-	SELECT 
-		miner_id,
-		transaction_id,
-		uuid,
-		name,
-		alternate_name,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-	FROM geth_miners_transactions 
-	WHERE 
-	miner_id = $1
-	`, *minerID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAllGethMinerTransactionsByMinerIDCtx(ctx, dbConnPgx, minerID)
+}
 
-	gethMinerTransactions, err := pgx.CollectRows(results, pgx.RowToStructByName[GethMinerTransaction])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return gethMinerTransactions, nil
+func GetAllGethMinerTransactionsByMinerIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerID *int) ([]GethMinerTransaction, error) {
+	return GethMinerTransactionRepository.Select(ctx, dbConnPgx, `WHERE miner_id = $1`, *minerID)
 }
 
 func GetMinAndMaxDatesFromTransactionsByMinerID(dbConnPgx utils.PgxIface, minerID *int) (*time.Time, *time.Time, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetMinAndMaxDatesFromTransactionsByMinerIDCtx(ctx, dbConnPgx, minerID)
+}
+
+func GetMinAndMaxDatesFromTransactionsByMinerIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerID *int) (*time.Time, *time.Time, error) {
 	row := dbConnPgx.QueryRow(ctx, `
 	SELECT 
 		MIN(gt.txn_date) as min_date,
@@ -61,17 +48,19 @@ func GetMinAndMaxDatesFromTransactionsByMinerID(dbConnPgx utils.PgxIface, minerI
 		&minDate,
 		&maxDate,
 	)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, nil, err
+	if err != nil {
+		logging.ReturnedError(ctx, "GetMinAndMaxDatesFromTransactionsByMinerID", err, logging.Entity("geth_miners_transactions"))
+		return nil, nil, dberrors.Wrap(err)
 	}
 	return minDate, maxDate, nil
 }
 func GetDistinctAddressesFromGethTransactionsByMinerIDAndBeforeDate(dbConnPgx utils.PgxIface, minerID *int, beforeDate *time.Time) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetDistinctAddressesFromGethTransactionsByMinerIDAndBeforeDateCtx(ctx, dbConnPgx, minerID, beforeDate)
+}
+
+func GetDistinctAddressesFromGethTransactionsByMinerIDAndBeforeDateCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerID *int, beforeDate *time.Time) ([]string, error) {
 	results, err := dbConnPgx.Query(ctx, `
 	SELECT 
 		DISTINCT
@@ -84,8 +73,8 @@ func GetDistinctAddressesFromGethTransactionsByMinerIDAndBeforeDate(dbConnPgx ut
 	gt.txn_date <= $2
 	`, *minerID, beforeDate.Format(utils.LayoutPostgres))
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetDistinctAddressesFromGethTransactionsByMinerIDAndBeforeDate", err, logging.Entity("geth_miners_transactions"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	addressesStr := make([]string, 0)
@@ -101,355 +90,120 @@ func GetDistinctAddressesFromGethTransactionsByMinerIDAndBeforeDate(dbConnPgx ut
 }
 
 func GetAllGethMinerTransactionsByTransactionID(dbConnPgx utils.PgxIface, transactionID *int) ([]GethMinerTransaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `
-	SELECT 
-		miner_id,
-		transaction_id,
-		uuid,
-		name,
-		alternate_name,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-	FROM geth_miners_transactions 
-	WHERE 
-	transaction_id = $1
-	`, *transactionID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetAllGethMinerTransactionsByTransactionIDCtx(ctx, dbConnPgx, transactionID)
+}
 
-	gethMinerTransactions, err := pgx.CollectRows(results, pgx.RowToStructByName[GethMinerTransaction])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return gethMinerTransactions, nil
+func GetAllGethMinerTransactionsByTransactionIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionID *int) ([]GethMinerTransaction, error) {
+	return GethMinerTransactionRepository.Select(ctx, dbConnPgx, `WHERE transaction_id = $1`, *transactionID)
 }
 
 func GetGethMinerTransaction(dbConnPgx utils.PgxIface, minerID, transactionID *int) (*GethMinerTransaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `
-	SELECT 
-		miner_id,
-		transaction_id,
-		uuid,
-		name,
-		alternate_name,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-	FROM geth_miners_transactions 
-	WHERE miner_id = $1
-	AND transaction_id = $2
-	`, *minerID, *transactionID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	gethMinerTransaction, err := pgx.CollectOneRow(row, pgx.RowToStructByName[GethMinerTransaction])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &gethMinerTransaction, nil
+	return GetGethMinerTransactionCtx(ctx, dbConnPgx, minerID, transactionID)
+}
+
+func GetGethMinerTransactionCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerID, transactionID *int) (*GethMinerTransaction, error) {
+	return GethMinerTransactionRepository.Get(ctx, dbConnPgx, repository.CompositeKey{*minerID, *transactionID})
 }
 
 func RemoveGethMinerTransaction(dbConnPgx utils.PgxIface, minerID, transactionID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveGethMinerTransaction DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM geth_miners_transactions WHERE miner_id = $1 AND transaction_id =$2`
+	return RemoveGethMinerTransactionCtx(ctx, dbConnPgx, minerID, transactionID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *minerID, *transactionID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveGethMinerTransactionCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerID, transactionID *int) error {
+	return GethMinerTransactionRepository.Remove(ctx, dbConnPgx, repository.CompositeKey{*minerID, *transactionID})
 }
 
 func GetGethMinerTransactionList(dbConnPgx utils.PgxIface, minerIDs, transactionIDs []int) ([]GethMinerTransaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `
-	SELECT 
-		miner_id,
-		transaction_id,
-		uuid,
-		name,
-		alternate_name,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-	FROM geth_miners_transactions `
-	if len(minerIDs) > 0 || len(transactionIDs) > 0 {
-		additionalQuery := ` WHERE`
-		if len(minerIDs) > 0 {
-			strIds := utils.SplitToString(minerIDs, ",")
-			additionalQuery += fmt.Sprintf(`miner_id IN (%s)`, strIds)
-		}
-		if len(transactionIDs) > 0 {
-			if len(minerIDs) > 0 {
-				additionalQuery += `AND `
-			}
-			strIds := utils.SplitToString(transactionIDs, ",")
-			additionalQuery += fmt.Sprintf(`transaction_id IN (%s)`, strIds)
-		}
-		sql += additionalQuery
-	}
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethMinerTransactionListCtx(ctx, dbConnPgx, minerIDs, transactionIDs)
+}
 
-	gethMinerTransactions, err := pgx.CollectRows(results, pgx.RowToStructByName[GethMinerTransaction])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return gethMinerTransactions, nil
+func GetGethMinerTransactionListCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerIDs, transactionIDs []int) ([]GethMinerTransaction, error) {
+	clause, args := repository.WhereAny([]string{"miner_id", "transaction_id"}, minerIDs, transactionIDs)
+	return GethMinerTransactionRepository.Select(ctx, dbConnPgx, clause, args...)
 }
 
 func UpdateGethMinerTransaction(dbConnPgx utils.PgxIface, minerTransactionInput *GethMinerTransaction) error {
-	// if the minerTransactionInput id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if (minerTransactionInput.MinerID == nil || *minerTransactionInput.MinerID == 0) || (minerTransactionInput.TransactionID == nil || *minerTransactionInput.TransactionID == 0) {
-		return errors.New("minerTransactionInput has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateGethMinerTransaction DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `UPDATE geth_miners_transactions SET 
-		name=$1,  
-		alternate_name=$2, 
-		description=$3,
-		updated_by=$4, 
-		updated_at=current_timestamp at time zone 'UTC'
-		WHERE miner_id=$5 AND transaction_id=$6`
+	return UpdateGethMinerTransactionCtx(ctx, dbConnPgx, minerTransactionInput)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		minerTransactionInput.Name,          //1
-		minerTransactionInput.AlternateName, //2
-		minerTransactionInput.Description,   //3
-		minerTransactionInput.UpdatedBy,     //4
-		minerTransactionInput.MinerID,       //5
-		minerTransactionInput.TransactionID, //6
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func UpdateGethMinerTransactionCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerTransactionInput *GethMinerTransaction) error {
+	return GethMinerTransactionRepository.Update(ctx, dbConnPgx, minerTransactionInput)
 }
 
 func InsertGethMinerTransaction(dbConnPgx utils.PgxIface, minerTransactionInput *GethMinerTransaction) (int, int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertGethMinerTransaction DbConn.Begin   %s", err.Error())
-		return -1, -1, err
-	}
-	var MinerID int
-	var TransactionID int
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO geth_miners_transactions  
-	(
-		miner_id,
-		transaction_id,
-		uuid,	 
-		name, 
-		alternate_name,  
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at 
-		) VALUES (
-			$1,
-			$2,
-			uuid_generate_v4(),
-			$3,
-			$4,
-			$5,
-			$6,
-			current_timestamp at time zone 'UTC',
-			$6,
-			current_timestamp at time zone 'UTC'
-		)
-		RETURNING miner_id, transaction_id`,
-		minerTransactionInput.MinerID,       //1
-		minerTransactionInput.TransactionID, //2
-		minerTransactionInput.Name,          //3
-		minerTransactionInput.AlternateName, //4
-		minerTransactionInput.Description,   //5
-		minerTransactionInput.CreatedBy,     //6
-	).Scan(&MinerID, &TransactionID)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, -1, err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
+	return InsertGethMinerTransactionCtx(ctx, dbConnPgx, minerTransactionInput)
+}
+
+func InsertGethMinerTransactionCtx(ctx context.Context, dbConnPgx utils.PgxIface, minerTransactionInput *GethMinerTransaction) (int, int, error) {
+	var minerID, transactionID int
+	if err := GethMinerTransactionRepository.InsertReturningKey(ctx, dbConnPgx, minerTransactionInput, &minerID, &transactionID); err != nil {
 		return -1, -1, err
 	}
-	return int(MinerID), int(TransactionID), nil
+	return minerID, transactionID, nil
 }
 
 func InsertGethMinersTransactions(dbConnPgx utils.PgxIface, gethMinersTransaction []GethMinerTransaction) error {
-	// need to supply uuid
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range gethMinersTransaction {
-		gethTransactionInput := gethMinersTransaction[i]
-		uuidString := pgtype.UUID{}
-		uuidString.Set(gethTransactionInput.UUID)
-		row := []interface{}{
-			gethTransactionInput.MinerID,       //1
-			gethTransactionInput.TransactionID, //2
-			uuidString,                         //3
-			gethTransactionInput.Name,          //4
-			gethTransactionInput.AlternateName, //5
-			gethTransactionInput.Description,   //6
-			gethTransactionInput.CreatedBy,     //7
-			now,                                //8
-			gethTransactionInput.CreatedBy,     //9
-			now,                                //10
+	return InsertGethMinersTransactionsCtx(ctx, dbConnPgx, gethMinersTransaction)
+}
 
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"geth_miners_transactions"},
-		[]string{
-			"miner_id",       //1
-			"transaction_id", //2
-			"uuid",           //3
-			"name",           //4
-			"alternate_name", //5
-			"description",    //6
-			"created_by",     //7
-			"created_at",     //8
-			"updated_by",     //9
-			"updated_at",     //10
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertGethMinersTransactions: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
-	return nil
+func InsertGethMinersTransactionsCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethMinersTransaction []GethMinerTransaction) error {
+	_, err := GethMinerTransactionRepository.InsertMany(ctx, dbConnPgx, gethMinersTransaction)
+	return dberrors.Wrap(err)
 }
 
 func RemoveAllTransactionsAndTransactionInputs(dbConnPgx utils.PgxIface) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return RemoveAllTransactionsAndTransactionInputsCtx(ctx, dbConnPgx)
+}
+
+func RemoveAllTransactionsAndTransactionInputsCtx(ctx context.Context, dbConnPgx utils.PgxIface) error {
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in RemoveAllTransactionsAndTransactionInputs DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "RemoveAllTransactionsAndTransactionInputs: begin", err, logging.Entity("geth_miners_transactions"))
+		return dberrors.Wrap(err)
 	}
 	sql := `DELETE FROM geth_miners_transactions;
 		DELETE FROM geth_transactions;`
 
-	if _, err := dbConnPgx.Exec(ctx, sql); err != nil {
+	if _, err := tx.Exec(ctx, sql); err != nil {
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 
 // for refinedev
-func GetMinerTransactionListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]GethMinerTransaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetMinerTransactionListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]GethMinerTransaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetMinerTransactionListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
+}
 
-	sql := `SELECT 
-	miner_id,
-	transaction_id,
-	uuid, 
-	name, 
-	alternate_name, 
-	description,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at 
-	FROM geth_miners_transactions 
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-
-	gethMinerTransactions, err := pgx.CollectRows(results, pgx.RowToStructByName[GethMinerTransaction])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return gethMinerTransactions, nil
+func GetMinerTransactionListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]GethMinerTransaction, error) {
+	return GethMinerTransactionRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
 }
 
 func GetTotalMinerTransactionCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalMinerTransactionCountCtx(ctx, dbConnPgx)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT 
-	COUNT(*)
-	FROM geth_miners_transactions
-	`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalMinerTransactionCountCtx(ctx context.Context, dbConnPgx utils.PgxIface) (*int, error) {
+	return GethMinerTransactionRepository.Count(ctx, dbConnPgx)
 }
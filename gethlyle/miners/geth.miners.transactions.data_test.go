@@ -7,34 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumnsTransactions = []string{
-	"miner_id",       //1
-	"transaction_id", //2
-	"uuid",           //3
-	"name",           //4
-	"alternate_name", //5
-	"description",    //6
-	"created_by",     //7
-	"created_at",     //8
-	"updated_by",     //9
-	"updated_at",     //10
-}
-var DBColumnsInsertGethMinersTransactions = []string{
-	"miner_id",       //1
-	"transaction_id", //2
-	"uuid",           //3
-	"name",           //4
-	"alternate_name", //5
-	"description",    //6
-	"created_by",     //7
-	"created_at",     //8
-	"updated_by",     //9
-	"updated_at",     //10
-}
+var DBColumnsTransactions = GethMinerTransactionRepository.Columns()
+var DBColumnsInsertGethMinersTransactions = GethMinerTransactionRepository.InsertColumns()
 
 var TestData1MinerTransaction = GethMinerTransaction{
 	MinerID:       utils.Ptr[int](1),
@@ -64,22 +44,7 @@ var TestData2MinerTransaction = GethMinerTransaction{
 var TestAllDataMinerTransaction = []GethMinerTransaction{TestData1MinerTransaction, TestData2MinerTransaction}
 
 func AddGethMinerTransactionToMockRows(mock pgxmock.PgxPoolIface, dataList []GethMinerTransaction) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumnsTransactions)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.MinerID,       //1
-			data.TransactionID, //2
-			data.UUID,          //3
-			data.Name,          //4
-			data.AlternateName, //5
-			data.Description,   //6
-			data.CreatedBy,     //7
-			data.CreatedAt,     //8
-			data.UpdatedBy,     //9
-			data.UpdatedAt,     //10
-		)
-	}
-	return rows
+	return GethMinerTransactionRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetAllGethMinerTransactionsByMinerID(t *testing.T) {
@@ -185,8 +150,8 @@ func TestGetMinAndMaxDatesFromTransactionsByMinerIDForErrNoRows(t *testing.T) {
 	noRows := mock.NewRows([]string{"min_date", "max_date"})
 	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WithArgs(minerID).WillReturnRows(noRows)
 	startDateResult, endDateResult, err := GetMinAndMaxDatesFromTransactionsByMinerID(mock, &minerID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetMinAndMaxDatesFromTransactionsByMinerID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if startDateResult != nil {
 		t.Errorf("startDateResult From Method GetMinAndMaxDatesFromTransactionsByMinerID: to be empty but got this: %v", startDateResult)
@@ -373,8 +338,8 @@ func TestGetGethMinerTransactionForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumnsTransactions)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WithArgs(gethMinerID, transactionInputID).WillReturnRows(noRows)
 	foundGethMinerTransaction, err := GetGethMinerTransaction(mock, &gethMinerID, &transactionInputID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethMinerTransaction", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethMinerTransaction != nil {
 		t.Errorf("Expected GethMinerTransaction From Method GetGethMinerTransaction: to be empty but got this: %v", foundGethMinerTransaction)
@@ -494,9 +459,9 @@ func TestGetGethMinerTransactionList(t *testing.T) {
 	defer mock.Close()
 	dataList := []GethMinerTransaction{TestData1MinerTransaction, TestData2MinerTransaction}
 	mockRows := AddGethMinerTransactionToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WillReturnRows(mockRows)
 	minerIDs := []int{1, 2}
 	transactionInputIDs := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WithArgs(minerIDs, transactionInputIDs).WillReturnRows(mockRows)
 	foundGethMinerTransactionList, err := GetGethMinerTransactionList(mock, minerIDs, transactionInputIDs)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethMinerTransactionList", err)
@@ -863,10 +828,10 @@ func TestGetMinerTransactionListByPagination(t *testing.T) {
 	mockRows := AddGethMinerTransactionToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "miner_id"
 	_order := "ASC"
-	filters := []string{"miner_id = 1", "transaction_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("miner_id", "1"), filter.Equal("transaction_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethMinerTransactionList, err := GetMinerTransactionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetMinerTransactionListByPagination", err)
@@ -889,10 +854,10 @@ func TestGetMinerTransactionListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "miner_id"
 	_order := "ASC"
-	filters := []string{"miner_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("miner_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethMinerTransactionList, err := GetMinerTransactionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMinerTransactionListByPagination", err)
@@ -913,11 +878,11 @@ func TestGetMinerTransactionListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "miner_id"
 	_order := "ASC"
-	filters := []string{"miner_id = 1"}
+	filters := filter.All(filter.Equal("miner_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_miners_transactions").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethMinerTransactionList, err := GetMinerTransactionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMinerTransactionListByPagination", err)
@@ -88,3 +88,17 @@ START TRANSACTION;
 UPDATE geth_swaps SET
 oracle_price_usd = token1_price_usd
 oracle_price_asset_id = token1_asset_id
+
+
+-- natural key for UpsertGethSwaps 2026-10-18
+ROLLBACK
+START TRANSACTION;
+DELETE FROM geth_swaps a USING geth_swaps b
+WHERE a.chain_id = b.chain_id
+  AND a.txn_hash = b.txn_hash
+  AND a.block_number = b.block_number
+  AND a.index_number = b.index_number
+  AND a.id < b.id;
+CREATE UNIQUE INDEX geth_swaps_natural_key ON geth_swaps(chain_id, txn_hash, block_number, index_number);
+COMMIT
+-- end
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -55,8 +57,8 @@ func TestGetGethSwapByBlockChainForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WithArgs(txnHash, *blockNumber, *indexNumber, makerAddressID, *liquidityPoolID).WillReturnRows(noRows)
 	foundGethSwap, err := GetGethSwapByBlockChain(mock, txnHash, blockNumber, indexNumber, &makerAddressID, liquidityPoolID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethSwapByBlockChain", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethSwap != nil {
 		t.Errorf("Expected GethSwap From Method GetGethSwapByBlockChain: to be empty but got this: %v", foundGethSwap)
@@ -151,8 +153,8 @@ func TestGetGethSwapForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WithArgs(gethSwapID).WillReturnRows(noRows)
 	foundGethSwap, err := GetGethSwap(mock, &gethSwapID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethSwap", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethSwap != nil {
 		t.Errorf("Expected GethSwap From Method GetGethSwap: to be empty but got this: %v", foundGethSwap)
@@ -1076,35 +1078,35 @@ func TestUpdateGethSwap(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_swaps").WithArgs(
-		targetData.ChainID,             //1
-		targetData.ExchangeID,          //2
-		targetData.BlockNumber,         //3
-		targetData.IndexNumber,         //4
-		targetData.SwapDate,            //5
-		targetData.TradeTypeID,         //6
-		targetData.TxnHash,             //7
-		targetData.MakerAddress,        //8
-		targetData.MakerAddressID,      //9
-		targetData.IsBuy,               //10
-		targetData.Price,               //11
-		targetData.PriceUSD,            //12
-		targetData.Token1PriceUSD,      //13
-		targetData.TotalAmountUSD,      //14
-		targetData.PairAddress,         //15
-		targetData.LiquidityPoolID,     //16
-		targetData.Token0AssetId,       //17
-		targetData.Token1AssetId,       //18
-		targetData.Token0Amount,        //19
-		targetData.Token1Amount,        //20
-		targetData.Description,         //21
-		targetData.UpdatedBy,           //22
-		targetData.GethProcessJobID,    //23
-		pq.Array(targetData.TopicsStr), //24
-		targetData.StatusID,            //25
-		targetData.BaseAssetID,         //26
-		targetData.OraclePriceUSD,      //27
-		targetData.OraclePriceAssetID,  //28
-		targetData.ID,                  //29
+		targetData.ChainID,            //1
+		targetData.ExchangeID,         //2
+		targetData.BlockNumber,        //3
+		targetData.IndexNumber,        //4
+		targetData.SwapDate,           //5
+		targetData.TradeTypeID,        //6
+		targetData.TxnHash,            //7
+		targetData.MakerAddress,       //8
+		targetData.MakerAddressID,     //9
+		targetData.IsBuy,              //10
+		targetData.Price,              //11
+		targetData.PriceUSD,           //12
+		targetData.Token1PriceUSD,     //13
+		targetData.TotalAmountUSD,     //14
+		targetData.PairAddress,        //15
+		targetData.LiquidityPoolID,    //16
+		targetData.Token0AssetId,      //17
+		targetData.Token1AssetId,      //18
+		targetData.Token0Amount,       //19
+		targetData.Token1Amount,       //20
+		targetData.Description,        //21
+		targetData.UpdatedBy,          //22
+		targetData.GethProcessJobID,   //23
+		targetData.TopicsStr,          //24
+		targetData.StatusID,           //25
+		targetData.BaseAssetID,        //26
+		targetData.OraclePriceUSD,     //27
+		targetData.OraclePriceAssetID, //28
+		targetData.ID,                 //29
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateGethSwap(mock, &targetData)
@@ -1160,35 +1162,35 @@ func TestUpdateGethSwapOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_swaps").WithArgs(
-		targetData.ChainID,             //1
-		targetData.ExchangeID,          //2
-		targetData.BlockNumber,         //3
-		targetData.IndexNumber,         //4
-		targetData.SwapDate,            //5
-		targetData.TradeTypeID,         //6
-		targetData.TxnHash,             //7
-		targetData.MakerAddress,        //8
-		targetData.MakerAddressID,      //9
-		targetData.IsBuy,               //10
-		targetData.Price,               //11
-		targetData.PriceUSD,            //12
-		targetData.Token1PriceUSD,      //13
-		targetData.TotalAmountUSD,      //14
-		targetData.PairAddress,         //15
-		targetData.LiquidityPoolID,     //16
-		targetData.Token0AssetId,       //17
-		targetData.Token1AssetId,       //18
-		targetData.Token0Amount,        //19
-		targetData.Token1Amount,        //20
-		targetData.Description,         //21
-		targetData.UpdatedBy,           //22
-		targetData.GethProcessJobID,    //23
-		pq.Array(targetData.TopicsStr), //24
-		targetData.StatusID,            //25
-		targetData.BaseAssetID,         //26
-		targetData.OraclePriceUSD,      //27
-		targetData.OraclePriceAssetID,  //28
-		targetData.ID,                  //29
+		targetData.ChainID,            //1
+		targetData.ExchangeID,         //2
+		targetData.BlockNumber,        //3
+		targetData.IndexNumber,        //4
+		targetData.SwapDate,           //5
+		targetData.TradeTypeID,        //6
+		targetData.TxnHash,            //7
+		targetData.MakerAddress,       //8
+		targetData.MakerAddressID,     //9
+		targetData.IsBuy,              //10
+		targetData.Price,              //11
+		targetData.PriceUSD,           //12
+		targetData.Token1PriceUSD,     //13
+		targetData.TotalAmountUSD,     //14
+		targetData.PairAddress,        //15
+		targetData.LiquidityPoolID,    //16
+		targetData.Token0AssetId,      //17
+		targetData.Token1AssetId,      //18
+		targetData.Token0Amount,       //19
+		targetData.Token1Amount,       //20
+		targetData.Description,        //21
+		targetData.UpdatedBy,          //22
+		targetData.GethProcessJobID,   //23
+		targetData.TopicsStr,          //24
+		targetData.StatusID,           //25
+		targetData.BaseAssetID,        //26
+		targetData.OraclePriceUSD,     //27
+		targetData.OraclePriceAssetID, //28
+		targetData.ID,                 //29
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -1211,34 +1213,34 @@ func TestInsertGethSwap(t *testing.T) {
 	uuid := "01ef85e8-2c26-441e-8c7f-71d79518ad72"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_swaps").WithArgs(
-		targetData.ChainID,             //1
-		targetData.ExchangeID,          //2
-		targetData.BlockNumber,         //3
-		targetData.IndexNumber,         //4
-		targetData.SwapDate,            //5
-		targetData.TradeTypeID,         //6
-		targetData.TxnHash,             //7
-		targetData.MakerAddress,        //8
-		targetData.MakerAddressID,      //9
-		targetData.IsBuy,               //10
-		targetData.Price,               //11
-		targetData.PriceUSD,            //12
-		targetData.Token1PriceUSD,      //13
-		targetData.TotalAmountUSD,      //14
-		targetData.PairAddress,         //15
-		targetData.LiquidityPoolID,     //16
-		targetData.Token0AssetId,       //17
-		targetData.Token1AssetId,       //18
-		targetData.Token0Amount,        //19
-		targetData.Token1Amount,        //20
-		targetData.Description,         //21
-		targetData.CreatedBy,           //22
-		targetData.GethProcessJobID,    //23
-		pq.Array(targetData.TopicsStr), //24
-		targetData.StatusID,            //25
-		targetData.BaseAssetID,         //26
-		targetData.OraclePriceUSD,      //27
-		targetData.OraclePriceAssetID,  //28
+		targetData.ChainID,            //1
+		targetData.ExchangeID,         //2
+		targetData.BlockNumber,        //3
+		targetData.IndexNumber,        //4
+		targetData.SwapDate,           //5
+		targetData.TradeTypeID,        //6
+		targetData.TxnHash,            //7
+		targetData.MakerAddress,       //8
+		targetData.MakerAddressID,     //9
+		targetData.IsBuy,              //10
+		targetData.Price,              //11
+		targetData.PriceUSD,           //12
+		targetData.Token1PriceUSD,     //13
+		targetData.TotalAmountUSD,     //14
+		targetData.PairAddress,        //15
+		targetData.LiquidityPoolID,    //16
+		targetData.Token0AssetId,      //17
+		targetData.Token1AssetId,      //18
+		targetData.Token0Amount,       //19
+		targetData.Token1Amount,       //20
+		targetData.Description,        //21
+		targetData.CreatedBy,          //22
+		targetData.GethProcessJobID,   //23
+		targetData.TopicsStr,          //24
+		targetData.StatusID,           //25
+		targetData.BaseAssetID,        //26
+		targetData.OraclePriceUSD,     //27
+		targetData.OraclePriceAssetID, //28
 	).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(1, uuid))
 	mock.ExpectCommit()
 	gethSwapID, newUUID, err := InsertGethSwap(mock, &targetData)
@@ -1285,34 +1287,34 @@ func TestInsertGethSwapOnFailure(t *testing.T) {
 	targetData.BlockNumber = nil
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_swaps").WithArgs(
-		targetData.ChainID,             //1
-		targetData.ExchangeID,          //2
-		targetData.BlockNumber,         //3
-		targetData.IndexNumber,         //4
-		targetData.SwapDate,            //5
-		targetData.TradeTypeID,         //6
-		targetData.TxnHash,             //7
-		targetData.MakerAddress,        //8
-		targetData.MakerAddressID,      //9
-		targetData.IsBuy,               //10
-		targetData.Price,               //11
-		targetData.PriceUSD,            //12
-		targetData.Token1PriceUSD,      //13
-		targetData.TotalAmountUSD,      //14
-		targetData.PairAddress,         //15
-		targetData.LiquidityPoolID,     //16
-		targetData.Token0AssetId,       //17
-		targetData.Token1AssetId,       //18
-		targetData.Token0Amount,        //19
-		targetData.Token1Amount,        //20
-		targetData.Description,         //21
-		targetData.CreatedBy,           //22
-		targetData.GethProcessJobID,    //23
-		pq.Array(targetData.TopicsStr), //24
-		targetData.StatusID,            //25
-		targetData.BaseAssetID,         //26
-		targetData.OraclePriceUSD,      //27
-		targetData.OraclePriceAssetID,  //28
+		targetData.ChainID,            //1
+		targetData.ExchangeID,         //2
+		targetData.BlockNumber,        //3
+		targetData.IndexNumber,        //4
+		targetData.SwapDate,           //5
+		targetData.TradeTypeID,        //6
+		targetData.TxnHash,            //7
+		targetData.MakerAddress,       //8
+		targetData.MakerAddressID,     //9
+		targetData.IsBuy,              //10
+		targetData.Price,              //11
+		targetData.PriceUSD,           //12
+		targetData.Token1PriceUSD,     //13
+		targetData.TotalAmountUSD,     //14
+		targetData.PairAddress,        //15
+		targetData.LiquidityPoolID,    //16
+		targetData.Token0AssetId,      //17
+		targetData.Token1AssetId,      //18
+		targetData.Token0Amount,       //19
+		targetData.Token1Amount,       //20
+		targetData.Description,        //21
+		targetData.CreatedBy,          //22
+		targetData.GethProcessJobID,   //23
+		targetData.TopicsStr,          //24
+		targetData.StatusID,           //25
+		targetData.BaseAssetID,        //26
+		targetData.OraclePriceUSD,     //27
+		targetData.OraclePriceAssetID, //28
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	gethSwapID, newUUID, err := InsertGethSwap(mock, &targetData)
@@ -1341,34 +1343,34 @@ func TestInsertGethSwapOnFailureOnCommit(t *testing.T) {
 	uuid := "01ef85e8-2c26-441e-8c7f-71d79518ad72"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_swaps").WithArgs(
-		targetData.ChainID,             //1
-		targetData.ExchangeID,          //2
-		targetData.BlockNumber,         //3
-		targetData.IndexNumber,         //4
-		targetData.SwapDate,            //5
-		targetData.TradeTypeID,         //6
-		targetData.TxnHash,             //7
-		targetData.MakerAddress,        //8
-		targetData.MakerAddressID,      //9
-		targetData.IsBuy,               //10
-		targetData.Price,               //11
-		targetData.PriceUSD,            //12
-		targetData.Token1PriceUSD,      //13
-		targetData.TotalAmountUSD,      //14
-		targetData.PairAddress,         //15
-		targetData.LiquidityPoolID,     //16
-		targetData.Token0AssetId,       //17
-		targetData.Token1AssetId,       //18
-		targetData.Token0Amount,        //19
-		targetData.Token1Amount,        //20
-		targetData.Description,         //21
-		targetData.CreatedBy,           //22
-		targetData.GethProcessJobID,    //23
-		pq.Array(targetData.TopicsStr), //24
-		targetData.StatusID,            //25
-		targetData.BaseAssetID,         //26
-		targetData.OraclePriceUSD,      //27
-		targetData.OraclePriceAssetID,  //28
+		targetData.ChainID,            //1
+		targetData.ExchangeID,         //2
+		targetData.BlockNumber,        //3
+		targetData.IndexNumber,        //4
+		targetData.SwapDate,           //5
+		targetData.TradeTypeID,        //6
+		targetData.TxnHash,            //7
+		targetData.MakerAddress,       //8
+		targetData.MakerAddressID,     //9
+		targetData.IsBuy,              //10
+		targetData.Price,              //11
+		targetData.PriceUSD,           //12
+		targetData.Token1PriceUSD,     //13
+		targetData.TotalAmountUSD,     //14
+		targetData.PairAddress,        //15
+		targetData.LiquidityPoolID,    //16
+		targetData.Token0AssetId,      //17
+		targetData.Token1AssetId,      //18
+		targetData.Token0Amount,       //19
+		targetData.Token1Amount,       //20
+		targetData.Description,        //21
+		targetData.CreatedBy,          //22
+		targetData.GethProcessJobID,   //23
+		targetData.TopicsStr,          //24
+		targetData.StatusID,           //25
+		targetData.BaseAssetID,        //26
+		targetData.OraclePriceUSD,     //27
+		targetData.OraclePriceAssetID, //28
 	).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(1, uuid))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -1421,6 +1423,49 @@ func TestInsertGethSwapsOnFailure(t *testing.T) {
 	}
 }
 
+func TestUpsertGethSwaps(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^CREATE TEMP TABLE tmp_geth_swaps").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"tmp_geth_swaps"}, DBColumnsInsertGethSwaps).WillReturnResult(2)
+	mock.ExpectQuery("^WITH merged AS (.+) ON CONFLICT \\(chain_id, txn_hash, block_number, index_number\\)").WillReturnRows(pgxmock.NewRows([]string{"inserted", "updated"}).AddRow(int64(1), int64(1)))
+	mock.ExpectExec("^DROP TABLE tmp_geth_swaps").WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mock.ExpectCommit()
+	inserted, updated, err := UpsertGethSwaps(mock, TestAllData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpsertGethSwaps", err)
+	}
+	if inserted != 1 || updated != 1 {
+		t.Errorf("Expected 1 inserted and 1 updated, got %d and %d", inserted, updated)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpsertGethSwapsOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^CREATE TEMP TABLE tmp_geth_swaps").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"tmp_geth_swaps"}, DBColumnsInsertGethSwaps).WillReturnError(fmt.Errorf("Random SQL Error"))
+	mock.ExpectRollback()
+	_, _, err = UpsertGethSwaps(mock, TestAllData)
+	if err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetNullAddressStrsFromSwaps(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -1531,8 +1576,8 @@ func TestGetGethSwapListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"trade_type_id = 1", "chain_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("trade_type_id", "1"), filter.Equal("chain_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethSwapList, err := GetGethSwapListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethSwapListByPagination", err)
@@ -1557,8 +1602,8 @@ func TestGetGethSwapListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"trade_type_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("trade_type_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethSwapList, err := GetGethSwapListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethSwapListByPagination", err)
@@ -1581,9 +1626,9 @@ func TestGetGethSwapListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"trade_type_id = 1"}
+	filters := filter.All(filter.Equal("trade_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_swaps").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethSwapList, err := GetGethSwapListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethSwapListByPagination", err)
@@ -8,75 +8,8 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-var DBColumns = []string{
-	"id",                    //1
-	"uuid",                  //2
-	"chain_id",              //3
-	"exchange_id",           //4
-	"block_number",          //5
-	"index_number",          //6
-	"swap_date",             //7
-	"trade_type_id",         //8
-	"txn_hash",              //9
-	"maker_address",         //10
-	"maker_address_id",      //11
-	"is_buy",                //12
-	"price",                 //13
-	"price_usd",             //14
-	"token1_price_usd",      //15
-	"total_amount_usd",      //16
-	"pair_address",          //17
-	"liquidity_pool_id",     //18
-	"token0_asset_id",       //19
-	"token1_asset_id",       //20
-	"token0_amount",         //21
-	"token1_amount",         //22
-	"description",           //23
-	"created_by",            //24
-	"created_at",            //25
-	"updated_by",            //26
-	"updated_at",            //27
-	"geth_process_job_id",   //28
-	"topics_str",            //29
-	"status_id",             //30
-	"base_asset_id",         //31
-	"oracle_price_usd",      //32
-	"oracle_price_asset_id", //33
-}
-var DBColumnsInsertGethSwaps = []string{
-	"uuid",                  //1
-	"chain_id",              //2
-	"exchange_id",           //3
-	"block_number",          //4
-	"index_number",          //5
-	"swap_date",             //6
-	"trade_type_id",         //7
-	"txn_hash",              //8
-	"maker_address",         //9
-	"maker_address_id",      //10
-	"is_buy",                //11
-	"price",                 //12
-	"price_usd",             //13
-	"token1_price_usd",      //14
-	"total_amount_usd",      //15
-	"pair_address",          //16
-	"liquidity_pool_id",     //17
-	"token0_asset_id",       //18
-	"token1_asset_id",       //19
-	"token0_amount",         //20
-	"token1_amount",         //21
-	"description",           //22
-	"created_by",            //23
-	"created_at",            //24
-	"updated_by",            //25
-	"updated_at",            //26
-	"geth_process_job_id",   //27
-	"topics_str",            //28
-	"status_id",             //29
-	"base_asset_id",         //30
-	"oracle_price_usd",      //31
-	"oracle_price_asset_id", //32
-}
+var DBColumns = GethSwapRepository.Columns()
+var DBColumnsInsertGethSwaps = GethSwapRepository.InsertColumns()
 
 var TestData1 = GethSwap{
 	ID:                 utils.Ptr[int](1),                                                                                                              //1
@@ -152,43 +85,5 @@ var TestData2 = GethSwap{
 var TestAllData = []GethSwap{TestData1, TestData2}
 
 func AddGethSwapToMockRows(mock pgxmock.PgxPoolIface, dataList []GethSwap) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                 //1
-			data.UUID,               //2
-			data.ChainID,            //3
-			data.ExchangeID,         //4
-			data.BlockNumber,        //5
-			data.IndexNumber,        //6
-			data.SwapDate,           //7
-			data.TradeTypeID,        //8
-			data.TxnHash,            //9
-			data.MakerAddress,       //10
-			data.MakerAddressID,     //11
-			data.IsBuy,              //12
-			data.Price,              //13
-			data.PriceUSD,           //14
-			data.Token1PriceUSD,     //15
-			data.TotalAmountUSD,     //16
-			data.PairAddress,        //17
-			data.LiquidityPoolID,    //18
-			data.Token0AssetId,      //19
-			data.Token1AssetId,      //20
-			data.Token0Amount,       //21
-			data.Token1Amount,       //22
-			data.Description,        //23
-			data.CreatedBy,          //24
-			data.CreatedAt,          //25
-			data.UpdatedBy,          //26
-			data.UpdatedAt,          //27
-			data.GethProcessJobID,   //28
-			data.TopicsStr,          //29
-			data.StatusID,           //30
-			data.BaseAssetID,        //31
-			data.OraclePriceUSD,     //32
-			data.OraclePriceAssetID, //33
-		)
-	}
-	return rows
+	return GethSwapRepository.AddToMockRows(mock, dataList)
 }
@@ -9,75 +9,16 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
 	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/shopspring/decimal"
 )
 
-var DBColumns = []string{
-	"id",                        //1
-	"uuid",                      //2
-	"name",                      //3
-	"alternate_name",            //4
-	"address_str",               //5
-	"address_id",                //6
-	"trade_date",                //7
-	"txn_hash",                  //8
-	"token0_amount",             //9
-	"token0_amount_decimal_adj", //10
-	"token1_amount",             //11
-	"token1_amount_decimal_adj", //12
-	"is_buy",                    //13
-	"price",                     //14
-	"price_usd",                 //15
-	"lp_token1_price_usd",       //16
-	"total_amount_usd",          //17
-	"token0_asset_id",           //18
-	"token1_asset_id",           //19
-	"geth_process_job_id",       //20
-	"status_id",                 //21
-	"trade_type_id",             //22
-	"description",               //23
-	"created_by",                //24
-	"created_at",                //25
-	"updated_by",                //26
-	"updated_at",                //27
-	"base_asset_id",             //28
-	"oracle_price_usd",          //29
-	"oracle_price_asset_id",     //30
-}
-var DBColumnsInsertGethTrades = []string{
-	"uuid",                      //1
-	"name",                      //2
-	"alternate_name",            //3
-	"address_str",               //4
-	"address_id",                //5
-	"trade_date",                //6
-	"txn_hash",                  //7
-	"token0_amount",             //8
-	"token0_amount_decimal_adj", //9
-	"token1_amount",             //10
-	"token1_amount_decimal_adj", //11
-	"is_buy",                    //12
-	"price",                     //13
-	"price_usd",                 //14
-	"lp_token1_price_usd",       //15
-	"total_amount_usd",          //16
-	"token0_asset_id",           //17
-	"token1_asset_id",           //18
-	"geth_process_job_id",       //19
-	"status_id",                 //20
-	"trade_type_id",             //21
-	"description",               //22
-	"created_by",                //23
-	"created_at",                //24
-	"updated_by",                //25
-	"updated_at",                //26
-	"base_asset_id",             //27
-	"oracle_price_usd",          //28
-	"oracle_price_asset_id",     //29
-}
+var DBColumns = GethTradeRepository.Columns()
+var DBColumnsInsertGethTrades = GethTradeRepository.InsertColumns()
 
 var TestData1 = GethTrade{
 	ID:                     utils.Ptr[int](1),                                                                   //1
@@ -147,42 +88,7 @@ var TestData2 = GethTrade{
 var TestAllData = []GethTrade{TestData1, TestData2}
 
 func AddGethTradeToMockRows(mock pgxmock.PgxPoolIface, dataList []GethTrade) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                     //1
-			data.UUID,                   //2
-			data.Name,                   //3
-			data.AlternateName,          //4
-			data.AddressStr,             //5
-			data.AddressID,              //6
-			data.TradeDate,              //7
-			data.TxnHash,                //8
-			data.Token0Amount,           //9
-			data.Token0AmountDecimalAdj, //10
-			data.Token1Amount,           //11
-			data.Token1AmountDecimalAdj, //12
-			data.IsBuy,                  //13
-			data.Price,                  //14
-			data.PriceUSD,               //15
-			data.LPToken1PriceUSD,       //16
-			data.TotalAmountUSD,         //17
-			data.Token0AssetId,          //18
-			data.Token1AssetId,          //19
-			data.GethProcessJobID,       //20
-			data.StatusID,               //21
-			data.TradeTypeID,            //22
-			data.Description,            //23
-			data.CreatedBy,              //24
-			data.CreatedAt,              //25
-			data.UpdatedBy,              //26
-			data.UpdatedAt,              //27
-			data.BaseAssetID,            //28
-			data.OraclePriceUSD,         //29
-			data.OraclePriceAssetID,     //30
-		)
-	}
-	return rows
+	return GethTradeRepository.AddToMockRows(mock, dataList)
 }
 
 var DBColumnsNetTransferByAddress = []string{
@@ -309,8 +215,8 @@ func TestGetGethTradeForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(gethTradeID).WillReturnRows(noRows)
 	foundGethTrade, err := GetGethTrade(mock, &gethTradeID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTrade", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTrade != nil {
 		t.Errorf("Expected GethTrade From Method GetGethTrade: to be empty but got this: %v", foundGethTrade)
@@ -762,8 +668,8 @@ func TestGetStartAndEndBlockForNewTradesByBaseAssetIDForErrNoRows(t *testing.T)
 	noRows := mock.NewRows([]string{"start_block_number", "end_block_number"})
 	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(baseAssetID).WillReturnRows(noRows)
 	startBlockNumberResult, endBlockNumberResult, err := GetStartAndEndBlockForNewTradesByBaseAssetID(mock, &baseAssetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStartAndEndBlockForNewTradesByBaseAssetID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if startBlockNumberResult != nil {
 		t.Errorf("Expected startBlockNumberResult From Method GetStartAndEndBlockForNewTradesByBaseAssetID: to be empty but got this: %v", startBlockNumberResult)
@@ -1349,8 +1255,8 @@ func TestGetLatestGethTradeFromAssetIDAnDateForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(assetID, asOfDate.Format(utils.LayoutPostgres)).WillReturnRows(noRows)
 	foundGethTrade, err := GetLatestGethTradeFromAssetIDAnDate(mock, &assetID, asOfDate, &isBefore)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetLatestGethTradeFromAssetIDAnDate", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTrade != nil {
 		t.Errorf("Expected GethTrade From Method GetLatestGethTradeFromAssetIDAnDate: to be empty but got this: %v", foundGethTrade)
@@ -1417,8 +1323,8 @@ func TestGetGethTradeListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"import_type_id = 1", "status_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("address_id", "1"), filter.Equal("status_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethTradeList, err := GetGethTradeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethTradeListByPagination", err)
@@ -1443,8 +1349,8 @@ func TestGetGethTradeListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"import_type_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("address_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTradeList, err := GetGethTradeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTradeListByPagination", err)
@@ -1467,9 +1373,9 @@ func TestGetGethTradeListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"import_type_id = 1"}
+	filters := filter.All(filter.Equal("address_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethTradeList, err := GetGethTradeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTradeListByPagination", err)
@@ -1520,3 +1426,95 @@ func TestGetTotalTradesForErr(t *testing.T) {
 		t.Errorf("There awere unfulfilled expectations: %s", err)
 	}
 }
+
+func TestInsertGethTradeWithDetails(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	swaps := []GethTradeSwap{TestData1GethTradeSwap, TestData2GethTradeSwap}
+	taxTransfers := []GethTradeTaxTransfer{TestData1GethTradeTaxTransfer}
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO geth_trades").WithArgs(insertGethTradeArgs(targetData)...).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(7, "01ef85e8-2c26-441e-8c7f-71d79518ad72"))
+	mock.ExpectCommit()
+	mock.ExpectCopyFrom(pgx.Identifier{"geth_trade_swaps"}, DBColumnsInsertGethTradeSwaps).WillReturnResult(2)
+	mock.ExpectCopyFrom(pgx.Identifier{"geth_trade_transfers"}, DBColumnsInsertGethTradeTaxTransfers).WillReturnResult(1)
+	mock.ExpectCommit()
+	gethTradeID, _, err := InsertGethTradeWithDetails(mock, &targetData, swaps, taxTransfers)
+	if err != nil {
+		t.Fatalf("an error '%s' in InsertGethTradeWithDetails", err)
+	}
+	if gethTradeID != 7 {
+		t.Errorf("Expected gethTradeID 7, got %d", gethTradeID)
+	}
+	for _, swap := range swaps {
+		if *swap.GethTradeID != gethTradeID {
+			t.Errorf("Expected swap to reference trade %d, got %d", gethTradeID, *swap.GethTradeID)
+		}
+	}
+	if *taxTransfers[0].GethTradeID != gethTradeID {
+		t.Errorf("Expected tax transfer to reference trade %d, got %d", gethTradeID, *taxTransfers[0].GethTradeID)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInsertGethTradeWithDetailsOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	swaps := []GethTradeSwap{TestData1GethTradeSwap, TestData2GethTradeSwap}
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO geth_trades").WithArgs(insertGethTradeArgs(targetData)...).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(7, "01ef85e8-2c26-441e-8c7f-71d79518ad72"))
+	mock.ExpectCommit()
+	mock.ExpectCopyFrom(pgx.Identifier{"geth_trade_swaps"}, DBColumnsInsertGethTradeSwaps).WillReturnError(fmt.Errorf("Random SQL Error"))
+	mock.ExpectRollback()
+	gethTradeID, _, err := InsertGethTradeWithDetails(mock, &targetData, swaps, nil)
+	if err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if gethTradeID != -1 {
+		t.Errorf("Expected gethTradeID -1, got %d", gethTradeID)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func insertGethTradeArgs(data GethTrade) []interface{} {
+	return []interface{}{
+		data.Name,                   //1
+		data.AlternateName,          //2
+		data.AddressStr,             //3
+		data.AddressID,              //4
+		data.TradeDate,              //5
+		data.TxnHash,                //6
+		data.Token0Amount,           //7
+		data.Token0AmountDecimalAdj, //8
+		data.Token1Amount,           //9
+		data.Token1AmountDecimalAdj, //10
+		data.IsBuy,                  //11
+		data.Price,                  //12
+		data.PriceUSD,               //13
+		data.LPToken1PriceUSD,       //14
+		data.TotalAmountUSD,         //15
+		data.Token0AssetId,          //16
+		data.Token1AssetId,          //17
+		data.GethProcessJobID,       //18
+		data.StatusID,               //19
+		data.TradeTypeID,            //20
+		data.Description,            //21
+		data.CreatedBy,              //22
+		data.BaseAssetID,            //23
+		data.OraclePriceUSD,         //24
+		data.OraclePriceAssetID,     //25
+	}
+}
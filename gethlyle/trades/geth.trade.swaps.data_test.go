@@ -7,35 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	gethlyleswaps "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/swaps"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumnsGethTradeSwaps = []string{
-	"geth_trade_id",  //1
-	"geth_swap_id",   //2
-	"uuid",           //3
-	"name",           //4
-	"alternate_name", //5
-	"description",    //6
-	"created_by",     //7
-	"created_at",     //8
-	"updated_by",     //9
-	"updated_at",     //10
-}
-var DBColumnsInsertGethTradeSwaps = []string{
-	"geth_trade_id",  //1
-	"geth_swap_id",   //2
-	"uuid",           //3
-	"name",           //4
-	"alternate_name", //5
-	"description",    //6
-	"created_by",     //7
-	"created_at",     //8
-	"updated_by",     //9
-	"updated_at",     //10
-}
+var DBColumnsGethTradeSwaps = GethTradeSwapRepository.Columns()
+var DBColumnsInsertGethTradeSwaps = GethTradeSwapRepository.InsertColumns()
 
 var TestData1GethTradeSwap = GethTradeSwap{
 	GethTradeID:   utils.Ptr[int](1),
@@ -65,22 +45,7 @@ var TestData2GethTradeSwap = GethTradeSwap{
 var TestAllDataGethTradeSwap = []GethTradeSwap{TestData1GethTradeSwap, TestData2GethTradeSwap}
 
 func AddGethTradeSwapToMockRows(mock pgxmock.PgxPoolIface, dataList []GethTradeSwap) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumnsGethTradeSwaps)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.GethTradeID,   //1
-			data.GethSwapID,    //2
-			data.UUID,          //3
-			data.Name,          //4
-			data.AlternateName, //5
-			data.Description,   //6
-			data.CreatedBy,     //7
-			data.CreatedAt,     //8
-			data.UpdatedBy,     //9
-			data.UpdatedAt,     //10
-		)
-	}
-	return rows
+	return GethTradeSwapRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetAllGethTradeSwapsByTradeID(t *testing.T) {
@@ -92,7 +57,7 @@ func TestGetAllGethTradeSwapsByTradeID(t *testing.T) {
 	dataList := []GethTradeSwap{TestData1GethTradeSwap}
 	mockRows := AddGethTradeSwapToMockRows(mock, dataList)
 	gethTradeID := TestData1GethTradeSwap.GethTradeID
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(*gethTradeID).WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(*gethTradeID).WillReturnRows(mockRows)
 	foundGethTradeSwapList, err := GetAllGethTradeSwapsByTradeID(mock, gethTradeID)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAllGethTradeSwapsByTradeID", err)
@@ -115,7 +80,7 @@ func TestGetAllGethTradeSwapsByTradeIDForErr(t *testing.T) {
 	}
 	defer mock.Close()
 	gethTradeID := -999
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(gethTradeID).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(gethTradeID).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTradeSwapList, err := GetAllGethTradeSwapsByTradeID(mock, &gethTradeID)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAllGethTradeSwapsByTradeID", err)
@@ -137,7 +102,7 @@ func TestGetAllGethTradeSwapsByTradeIDForCollectRowsErr(t *testing.T) {
 	gethTradeID := -1
 
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(gethTradeID).WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(gethTradeID).WillReturnRows(differentModelRows)
 	foundGethTradeSwapList, err := GetAllGethTradeSwapsByTradeID(mock, &gethTradeID)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAllGethTradeSwapsByTradeID", err)
@@ -185,8 +150,8 @@ func TestGetGethTradeSwapForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumnsGethTradeSwaps)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(gethTradeID, gethSwapID).WillReturnRows(noRows)
 	foundGethTradeSwap, err := GetGethTradeSwap(mock, &gethSwapID, &gethTradeID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTradeSwap", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTradeSwap != nil {
 		t.Errorf("Expected GethTradeSwap From Method GetGethTradeSwap: to be empty but got this: %v", foundGethTradeSwap)
@@ -308,9 +273,9 @@ func TestGetGethTradeSwapList(t *testing.T) {
 	defer mock.Close()
 	dataList := []GethTradeSwap{TestData1GethTradeSwap, TestData2GethTradeSwap}
 	mockRows := AddGethTradeSwapToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WillReturnRows(mockRows)
 	gethTradeIds := []int{1, 2}
 	gethSwapIDs := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(gethTradeIds, gethSwapIDs).WillReturnRows(mockRows)
 	foundGethTradeSwapList, err := GetGethTradeSwapList(mock, gethTradeIds, gethSwapIDs)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethTradeSwapList", err)
@@ -832,10 +797,10 @@ func TestGetGethTradeSwapListByPagination(t *testing.T) {
 	mockRows := AddGethTradeSwapToMockRows(mock, dataList)
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "geth_trade_id"
 	_order := "ASC"
-	filters := []string{"name = 'test", "alternate_name='test'"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("name", "test"), filter.Equal("alternate_name", "test"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs("test", "test").WillReturnRows(mockRows)
 	foundGethTradeSwapList, err := GetGethTradeSwapListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethTradeSwapListByPagination", err)
@@ -858,10 +823,10 @@ func TestGetGethTradeSwapListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "geth_trade_id"
 	_order := "ASC"
-	filters := []string{"geth_swap_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("geth_swap_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTradeSwapList, err := GetGethTradeSwapListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTradeSwapListByPagination", err)
@@ -882,11 +847,11 @@ func TestGetGethTradeSwapListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "geth_trade_id"
 	_order := "ASC"
-	filters := []string{"geth_swap_id = 1"}
+	filters := filter.All(filter.Equal("geth_swap_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_swaps").WithArgs(int64(1), _start, _end-_start).WillReturnRows(differentModelRows)
 	foundGethTradeSwapList, err := GetGethTradeSwapListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTradeSwapListByPagination", err)
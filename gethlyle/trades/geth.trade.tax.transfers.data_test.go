@@ -7,36 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumnsGethTradeTaxTransfers = []string{
-	"geth_trade_id",    //1
-	"geth_transfer_id", //2
-	"tax_id",           //3
-	"uuid",             //4
-	"name",             //5
-	"alternate_name",   //6
-	"description",      //7
-	"created_by",       //8
-	"created_at",       //9
-	"updated_by",       //10
-	"updated_at",       //11
-}
-var DBColumnsInsertGethTradeTaxTransfers = []string{
-	"geth_trade_id",    //1
-	"geth_transfer_id", //2
-	"tax_id",           //3
-	"uuid",             //4
-	"name",             //5
-	"alternate_name",   //6
-	"description",      //7
-	"created_by",       //8
-	"created_at",       //9
-	"updated_by",       //10
-	"updated_at",       //11
-}
+var DBColumnsGethTradeTaxTransfers = GethTradeTaxTransferRepository.Columns()
+var DBColumnsInsertGethTradeTaxTransfers = GethTradeTaxTransferRepository.InsertColumns()
 
 var TestData1GethTradeTaxTransfer = GethTradeTaxTransfer{
 	GethTradeID:    utils.Ptr[int](1),
@@ -68,23 +46,7 @@ var TestData2GethTradeTaxTransfer = GethTradeTaxTransfer{
 var TestAllDataGethTradeTaxTransfer = []GethTradeTaxTransfer{TestData1GethTradeTaxTransfer, TestData2GethTradeTaxTransfer}
 
 func AddGethTradeTaxTransferToMockRows(mock pgxmock.PgxPoolIface, dataList []GethTradeTaxTransfer) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumnsGethTradeTaxTransfers)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.GethTradeID,    //1
-			data.GethTransferID, //2
-			data.TaxID,          //3
-			data.UUID,           //4
-			data.Name,           //5
-			data.AlternateName,  //6
-			data.Description,    //7
-			data.CreatedBy,      //8
-			data.CreatedAt,      //9
-			data.UpdatedBy,      //10
-			data.UpdatedAt,      //11
-		)
-	}
-	return rows
+	return GethTradeTaxTransferRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetAllGethTradeTaxTransfersByTradeID(t *testing.T) {
@@ -96,7 +58,7 @@ func TestGetAllGethTradeTaxTransfersByTradeID(t *testing.T) {
 	dataList := []GethTradeTaxTransfer{TestData1GethTradeTaxTransfer}
 	mockRows := AddGethTradeTaxTransferToMockRows(mock, dataList)
 	gethTradeID := TestData1GethTradeTaxTransfer.GethTradeID
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(*gethTradeID).WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(*gethTradeID).WillReturnRows(mockRows)
 	foundGethTradeTaxTransferList, err := GetAllGethTradeTaxTransfersByTradeID(mock, gethTradeID)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetAllGethTradeTaxTransfersByTradeID", err)
@@ -119,7 +81,7 @@ func TestGetAllGethTradeTaxTransfersByTradeIDForErr(t *testing.T) {
 	}
 	defer mock.Close()
 	gethTradeID := -999
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(gethTradeID).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(gethTradeID).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTradeTaxTransferList, err := GetAllGethTradeTaxTransfersByTradeID(mock, &gethTradeID)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAllGethTradeTaxTransfersByTradeID", err)
@@ -140,7 +102,7 @@ func TestGetAllGethTradeTaxTransfersByTradeIDForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	gethTradeID := -999
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trades").WithArgs(gethTradeID).WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(gethTradeID).WillReturnRows(differentModelRows)
 	foundGethTradeList, err := GetAllGethTradeTaxTransfersByTradeID(mock, &gethTradeID)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetAllGethTradeTaxTransfersByTradeID", err)
@@ -188,8 +150,8 @@ func TestGetGethTradeTaxTransferForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumnsGethTradeTaxTransfers)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(gethTradeID, gethTransferID).WillReturnRows(noRows)
 	foundGethTradeTaxTransfer, err := GetGethTradeTaxTransfer(mock, &gethTradeID, &gethTransferID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTradeTaxTransfer", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTradeTaxTransfer != nil {
 		t.Errorf("Expected GethTradeTaxTransfer From Method GetGethTradeTaxTransfer: to be empty but got this: %v", foundGethTradeTaxTransfer)
@@ -308,9 +270,9 @@ func TestGetGethTradeTaxTransferList(t *testing.T) {
 	defer mock.Close()
 	dataList := []GethTradeTaxTransfer{TestData1GethTradeTaxTransfer, TestData2GethTradeTaxTransfer}
 	mockRows := AddGethTradeTaxTransferToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WillReturnRows(mockRows)
 	gethTradeIds := []int{1, 2}
 	gethTransferIDs := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(gethTradeIds, gethTransferIDs).WillReturnRows(mockRows)
 	foundGethTradeTaxTransferList, err := GetGethTradeTaxTransferList(mock, gethTradeIds, gethTransferIDs)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethTradeTaxTransferList", err)
@@ -631,10 +593,10 @@ func TestGetGethTradeTaxTransferListByPagination(t *testing.T) {
 	mockRows := AddGethTradeTaxTransferToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "geth_trade_id"
 	_order := "ASC"
-	filters := []string{"tax_id = 1", "description='test'"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("tax_id", "1"), filter.Equal("description", "test"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(int64(1), "test", _start, _end-_start).WillReturnRows(mockRows)
 	foundGethTradeTaxTransferList, err := GetGethTradeTaxTransferListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethTradeTaxTransferListByPagination", err)
@@ -657,10 +619,10 @@ func TestGetGethTradeTaxTransferListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "geth_trade_id"
 	_order := "ASC"
-	filters := []string{"tax_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("tax_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTradeTaxTransferList, err := GetGethTradeTaxTransferListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTradeTaxTransferListByPagination", err)
@@ -681,11 +643,11 @@ func TestGetGethTradeTaxTransferListByPaginationForCollectRowsErr(t *testing.T)
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "geth_trade_id"
 	_order := "ASC"
-	filters := []string{"tax_id = 1"}
+	filters := filter.All(filter.Equal("tax_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_trade_transfers").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethTradeTaxTransferList, err := GetGethTradeTaxTransferListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTradeTaxTransferListByPagination", err)
@@ -8,63 +8,16 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/shopspring/decimal"
 )
 
-var DBColumns = []string{
-	"id",                             //1
-	"uuid",                           //2
-	"chain_id",                       //3
-	"exchange_id",                    //4
-	"block_number",                   //5
-	"index_number",                   //6
-	"txn_date",                       //7
-	"txn_hash",                       //8
-	"from_address",                   //9
-	"from_address_id",                //10
-	"to_address",                     //11
-	"to_address_id",                  //12
-	"interacted_contract_address",    //13
-	"interacted_contract_address_id", //14
-	"native_asset_id",                //15
-	"geth_process_job_id",            //16
-	"value",                          //17
-	"geth_transaction_input_id",      //18
-	"status_id",                      //19
-	"description",                    //20
-	"created_by",                     //21
-	"created_at",                     //22
-	"updated_by",                     //23
-	"updated_at",                     //24
-}
-var DBColumnsInsertGethTransactions = []string{
-	"uuid",                           //1
-	"chain_id",                       //2
-	"exchange_id",                    //3
-	"block_number",                   //4
-	"index_number",                   //5
-	"txn_date",                       //6
-	"txn_hash",                       //7
-	"from_address",                   //8
-	"from_address_id",                //9
-	"to_address",                     //10
-	"to_address_id",                  //11
-	"interacted_contract_address",    //12
-	"interacted_contract_address_id", //13
-	"native_asset_id",                //14
-	"geth_process_job_id",            //15
-	"value",                          //16
-	"geth_transaction_input_id",      //17
-	"status_id",                      //18
-	"description",                    //19
-	"created_by",                     //20
-	"created_at",                     //21
-	"updated_by",                     //22
-	"updated_at",                     //23
-}
+var DBColumns = GethTransactionRepository.Columns()
+var DBColumnsInsertGethTransactions = GethTransactionRepository.InsertColumns()
 
 var TestData1 = GethTransaction{
 	ID:                          utils.Ptr[int](1),
@@ -122,36 +75,7 @@ var TestData2 = GethTransaction{
 var TestAllData = []GethTransaction{TestData1, TestData2}
 
 func AddGethTransactionToMockRows(mock pgxmock.PgxPoolIface, dataList []GethTransaction) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                          //1
-			data.UUID,                        //2
-			data.ChainID,                     //3
-			data.ExchangeID,                  //4
-			data.BlockNumber,                 //5
-			data.IndexNumber,                 //6
-			data.TxnDate,                     //7
-			data.TxnHash,                     //8
-			data.FromAddress,                 //9
-			data.FromAddressID,               //10
-			data.ToAddress,                   //11
-			data.ToAddressID,                 //12
-			data.InteractedContractAddress,   //13
-			data.InteractedContractAddressID, //14
-			data.NativeAssetID,               //15
-			data.GethProcessJobID,            //16
-			data.Value,                       //17
-			data.GethTransctionInputId,       //18
-			data.StatusID,                    //19
-			data.Description,                 //20
-			data.CreatedBy,                   //21
-			data.CreatedAt,                   //22
-			data.UpdatedBy,                   //23
-			data.UpdatedAt,                   //24
-		)
-	}
-	return rows
+	return GethTransactionRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetGethTransaction(t *testing.T) {
@@ -187,8 +111,8 @@ func TestGetGethTransactionForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WithArgs(gethTransactionID).WillReturnRows(noRows)
 	foundGethTransaction, err := GetGethTransaction(mock, &gethTransactionID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTransaction", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTransaction != nil {
 		t.Errorf("Expected GethTransaction From Method GetGethTransaction: to be empty but got this: %v", foundGethTransaction)
@@ -409,8 +333,8 @@ func TestGetGethTransactionByTxnHashForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WithArgs(txnHash).WillReturnRows(noRows)
 	foundGethTransaction, err := GetGethTransactionByTxnHash(mock, txnHash)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTransactionByTxnHash", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTransaction != nil {
 		t.Errorf("Expected GethTransaction From Method GetGethTransactionByTxnHash: to be empty but got this: %v", foundGethTransaction)
@@ -1269,8 +1193,8 @@ func TestGetGethTransactionListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1", "exchange_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("chain_id", "1"), filter.Equal("exchange_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethTransactionList, err := GetGethTransactionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethTransactionListByPagination", err)
@@ -1295,8 +1219,8 @@ func TestGetGethTransactionListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("chain_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTransactionList, err := GetGethTransactionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTransactionListByPagination", err)
@@ -1319,9 +1243,9 @@ func TestGetGethTransactionListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1"}
+	filters := filter.All(filter.Equal("chain_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transactions").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethTransactionList, err := GetGethTransactionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTransactionListByPagination", err)
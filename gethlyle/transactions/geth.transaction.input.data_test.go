@@ -7,37 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumnsTransactionInputs = []string{
-	"id",                //1
-	"uuid",              //2
-	"name",              //3
-	"alternate_name",    //4
-	"function_name",     //5
-	"method_id_str",     //6
-	"num_of_parameters", //7
-	"description",       //8
-	"created_by",        //9
-	"created_at",        //10
-	"updated_by",        //11
-	"updated_at",        //12
-}
-var DBColumnsInsertGethTransactionInputs = []string{
-	"uuid",              //1
-	"name",              //2
-	"alternate_name",    //3
-	"function_name",     //4
-	"method_id_str",     //5
-	"num_of_parameters", //6
-	"description",       //7
-	"created_by",        //8
-	"created_at",        //9
-	"updated_by",        //10
-	"updated_at",        //11
-}
+var DBColumnsTransactionInputs = GethTransactionInputRepository.Columns()
+var DBColumnsInsertGethTransactionInputs = GethTransactionInputRepository.InsertColumns()
 
 var TestData1TransactionInput = GethTransactionInput{
 	ID:              utils.Ptr[int](1),
@@ -71,24 +48,7 @@ var TestData2TransactionInput = GethTransactionInput{
 var TestAllDataTransactionInput = []GethTransactionInput{TestData1TransactionInput, TestData2TransactionInput}
 
 func AddGethTransactionInputToMockRows(mock pgxmock.PgxPoolIface, dataList []GethTransactionInput) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumnsTransactionInputs)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,              //1
-			data.UUID,            //2
-			data.Name,            //3
-			data.AlternateName,   //4
-			data.FunctionName,    //5
-			data.MethodIDStr,     //6
-			data.NumOfParameters, //7
-			data.Description,     //8
-			data.CreatedBy,       //9
-			data.CreatedAt,       //10
-			data.UpdatedBy,       //11
-			data.UpdatedAt,       //12
-		)
-	}
-	return rows
+	return GethTransactionInputRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetGethTransactionInput(t *testing.T) {
@@ -124,8 +84,8 @@ func TestGetGethTransactionInputForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumnsTransactionInputs)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_transaction_inputs").WithArgs(gethTransactionInputID).WillReturnRows(noRows)
 	foundGethTransactionInput, err := GetGethTransactionInput(mock, &gethTransactionInputID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTransactionInput", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTransactionInput != nil {
 		t.Errorf("Expected GethTransactionInput From Method GetGethTransactionInput: to be empty but got this: %v", foundGethTransactionInput)
@@ -627,8 +587,8 @@ func TestGetTransactionInputListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"method_id_str = 'test", "num_of_parameters=2"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transaction_inputs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("method_id_str", "test"), filter.Equal("num_of_parameters", "2"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transaction_inputs").WithArgs("test", int64(2), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethTransactionInputList, err := GetTransactionInputListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTransactionInputListByPagination", err)
@@ -653,8 +613,8 @@ func TestGetTransactionInputListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"num_of_parameters = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transaction_inputs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("num_of_parameters", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transaction_inputs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTransactionInputList, err := GetTransactionInputListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionInputListByPagination", err)
@@ -677,9 +637,9 @@ func TestGetTransactionInputListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"num_of_parameters = 1"}
+	filters := filter.All(filter.Equal("num_of_parameters", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transaction_inputs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transaction_inputs").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethTransactionInputList, err := GetTransactionInputListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionInputListByPagination", err)
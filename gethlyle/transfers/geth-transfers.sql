@@ -71,4 +71,17 @@ ALTER TABLE geth_transfers
   ADD  transfer_type_id INT NULL,
   ADD CONSTRAINT fk_transfer_types FOREIGN KEY(transfer_type_id) REFERENCES structured_values(id)
   COMMIT
--- end
\ No newline at end of file
+-- end
+
+-- natural key for UpsertGethTransfers 2026-10-18
+ROLLBACK
+START TRANSACTION;
+DELETE FROM geth_transfers a USING geth_transfers b
+WHERE a.chain_id = b.chain_id
+  AND a.txn_hash = b.txn_hash
+  AND a.block_number = b.block_number
+  AND a.index_number = b.index_number
+  AND a.id < b.id;
+CREATE UNIQUE INDEX geth_transfers_natural_key ON geth_transfers(chain_id, txn_hash, block_number, index_number);
+COMMIT
+-- end
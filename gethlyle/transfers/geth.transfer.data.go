@@ -2,115 +2,55 @@ package gethlyletransfers
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
 	"time"
 
-	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	gethlyleaddresses "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/address"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 )
 
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// GethTransferRepository implements the standard queries on the geth_transfers
+// table.
+var GethTransferRepository = repository.New[GethTransfer]("geth_transfers")
+
 func GetGethTransfer(dbConnPgx utils.PgxIface, gethTransferID *int) (*GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-	FROM geth_transfers
-	WHERE id = $1
-	`, *gethTransferID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	gethTransfer, err := pgx.CollectOneRow(row, pgx.RowToStructByName[GethTransfer])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &gethTransfer, nil
+	return GetGethTransferCtx(ctx, dbConnPgx, gethTransferID)
+}
+
+func GetGethTransferCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethTransferID *int) (*GethTransfer, error) {
+	return GethTransferRepository.Get(ctx, dbConnPgx, *gethTransferID)
 }
 
 func GetGethTransferByBlockChain(dbConnPgx utils.PgxIface, txnHash string, blockNumber *uint64, indexNumber *uint) (*GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-	FROM geth_transfers
-	WHERE txn_hash = $1
+	return GetGethTransferByBlockChainCtx(ctx, dbConnPgx, txnHash, blockNumber, indexNumber)
+}
+
+func GetGethTransferByBlockChainCtx(ctx context.Context, dbConnPgx utils.PgxIface, txnHash string, blockNumber *uint64, indexNumber *uint) (*GethTransfer, error) {
+	return GethTransferRepository.SelectOne(ctx, dbConnPgx, `WHERE txn_hash = $1
 	AND block_number = $2
-	AND index_number = $3
-	`, txnHash, *blockNumber, *indexNumber)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	gethTransfer, err := pgx.CollectOneRow(row, pgx.RowToStructByName[GethTransfer])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &gethTransfer, nil
+	AND index_number = $3`, txnHash, *blockNumber, *indexNumber)
 }
 
 func GetTransfersTransactionHashByUserAddress(dbConnPgx utils.PgxIface, userAddressID, assetID *int, blockNumber *uint64) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTransfersTransactionHashByUserAddressCtx(ctx, dbConnPgx, userAddressID, assetID, blockNumber)
+}
+
+func GetTransfersTransactionHashByUserAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, userAddressID, assetID *int, blockNumber *uint64) ([]string, error) {
 	results, err := dbConnPgx.Query(ctx, `SELECT DISTINCT txn_hash FROM geth_transfers
 	WHERE
 	(to_address_id =$1 OR sender_address_id = $1)
@@ -119,8 +59,8 @@ func GetTransfersTransactionHashByUserAddress(dbConnPgx utils.PgxIface, userAddr
 	`,
 		*userAddressID, *assetID, *blockNumber)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetTransfersTransactionHashByUserAddress", err, logging.Entity("geth_transfers"), logging.BlockNumber(blockNumber))
+		return nil, dberrors.Wrap(err)
 	}
 
 	txnAddresses := make([]string, 0)
@@ -136,8 +76,12 @@ func GetTransfersTransactionHashByUserAddress(dbConnPgx utils.PgxIface, userAddr
 }
 
 func GetDistinctAddressesFromAssetId(dbConnPgx utils.PgxIface, assetID *int) ([]gethlyleaddresses.GethAddress, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetDistinctAddressesFromAssetIdCtx(ctx, dbConnPgx, assetID)
+}
+
+func GetDistinctAddressesFromAssetIdCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int) ([]gethlyleaddresses.GethAddress, error) {
 	results, err := dbConnPgx.Query(ctx, `
 	WITH sender_table as(
 		SELECT DISTINCT sender_address_id as address  
@@ -169,22 +113,26 @@ func GetDistinctAddressesFromAssetId(dbConnPgx utils.PgxIface, assetID *int) ([]
 	`,
 		*assetID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetDistinctAddressesFromAssetId", err, logging.Entity("geth_transfers"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	gethAddresses, err := pgx.CollectRows(results, pgx.RowToStructByName[gethlyleaddresses.GethAddress])
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetDistinctAddressesFromAssetId", err, logging.Entity("geth_transfers"))
+		return nil, dberrors.Wrap(err)
 	}
 	return gethAddresses, nil
 
 }
 
 func GetDistinctTransactionHashesFromAssetIdAndStartingBlock(dbConnPgx utils.PgxIface, assetID *int, startingBlock *uint64) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetDistinctTransactionHashesFromAssetIdAndStartingBlockCtx(ctx, dbConnPgx, assetID, startingBlock)
+}
+
+func GetDistinctTransactionHashesFromAssetIdAndStartingBlockCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int, startingBlock *uint64) ([]string, error) {
 	results, err := dbConnPgx.Query(ctx, `
 	SELECT
 		DISTINCT txn_hash 
@@ -195,8 +143,8 @@ func GetDistinctTransactionHashesFromAssetIdAndStartingBlock(dbConnPgx utils.Pgx
 	`,
 		*startingBlock, *assetID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetDistinctTransactionHashesFromAssetIdAndStartingBlock", err, logging.Entity("geth_transfers"), logging.BlockNumber(startingBlock))
+		return nil, dberrors.Wrap(err)
 	}
 
 	txnHashes := make([]string, 0)
@@ -212,8 +160,12 @@ func GetDistinctTransactionHashesFromAssetIdAndStartingBlock(dbConnPgx utils.Pgx
 }
 
 func GetHighestBlockFromBaseAssetId(dbConnPgx utils.PgxIface, baseAssetID *int) (*uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetHighestBlockFromBaseAssetIdCtx(ctx, dbConnPgx, baseAssetID)
+}
+
+func GetHighestBlockFromBaseAssetIdCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int) (*uint64, error) {
 	row := dbConnPgx.QueryRow(ctx, `SELECT COALESCE (MAX(block_number), 0) FROM geth_transfers
 	WHERE base_asset_id=$1
 		`,
@@ -222,657 +174,211 @@ func GetHighestBlockFromBaseAssetId(dbConnPgx utils.PgxIface, baseAssetID *int)
 	err := row.Scan(
 		&maxBlockNumber)
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		logging.ReturnedError(ctx, "GetHighestBlockFromBaseAssetId", err, logging.Entity("geth_transfers"))
+		return nil, dberrors.Wrap(err)
 	}
 	return &maxBlockNumber, nil
 }
 
 func GetGethTransferByFromTokenAddress(dbConnPgx utils.PgxIface, tokenAddressID *int) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-		FROM geth_transfers
-		WHERE
-		token_address_id = $1
-		`,
-		*tokenAddressID,
-	)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransferByFromTokenAddressCtx(ctx, dbConnPgx, tokenAddressID)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransferByFromTokenAddressCtx(ctx context.Context, dbConnPgx utils.PgxIface, tokenAddressID *int) ([]GethTransfer, error) {
+	return GethTransferRepository.Select(ctx, dbConnPgx, `WHERE
+	token_address_id = $1`, *tokenAddressID)
 }
 
 func GetGethTransferByFromMakerAddressAndTokenAddressID(dbConnPgx utils.PgxIface, makerAddressID, tokenAddressID *int) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-		FROM geth_transfers
-		WHERE
-		token_address_id = $1
-		AND (sender_address_id =$2 OR 
-			to_address_id = $2)
-		`,
-		*tokenAddressID, *makerAddressID,
-	)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransferByFromMakerAddressAndTokenAddressIDCtx(ctx, dbConnPgx, makerAddressID, tokenAddressID)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransferByFromMakerAddressAndTokenAddressIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, makerAddressID, tokenAddressID *int) ([]GethTransfer, error) {
+	return GethTransferRepository.Select(ctx, dbConnPgx, `WHERE
+	token_address_id = $1
+	AND (sender_address_id =$2 OR
+	to_address_id = $2)`, *tokenAddressID, *makerAddressID)
 }
 
 func GetGethTransferByFromMakerAddressAndTokenAddressIDAndBeforeBlockNumber(dbConnPgx utils.PgxIface, makerAddressID, baseAssetID *int, blockNumber *uint64) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-		FROM geth_transfers
-		WHERE
-		asset_id = $1
-		AND
-		base_asset_id =$1
-		AND (sender_address_id =$2 OR 
-			to_address_id = $2)
-		AND block_number <= $3
-		`,
-		*baseAssetID, *makerAddressID, *blockNumber,
-	)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransferByFromMakerAddressAndTokenAddressIDAndBeforeBlockNumberCtx(ctx, dbConnPgx, makerAddressID, baseAssetID, blockNumber)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransferByFromMakerAddressAndTokenAddressIDAndBeforeBlockNumberCtx(ctx context.Context, dbConnPgx utils.PgxIface, makerAddressID, baseAssetID *int, blockNumber *uint64) ([]GethTransfer, error) {
+	return GethTransferRepository.Select(ctx, dbConnPgx, `WHERE
+	asset_id = $1
+	AND
+	base_asset_id =$1
+	AND (sender_address_id =$2 OR
+	to_address_id = $2)
+	AND block_number <= $3`, *baseAssetID, *makerAddressID, *blockNumber)
 }
 
 func GetGethTransferByFromBaseAssetIDAndBeforeBlockNumber(dbConnPgx utils.PgxIface, baseAssetID *int, blockNumber *uint64) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-		FROM geth_transfers
-		WHERE
-		asset_id = $1
-		AND
-		base_asset_id =$1
-		AND block_number <= $2
-		AND sender_address_id IS NOT NULL
-		AND to_address_id IS NOT NULL
-		`,
-		*baseAssetID, *blockNumber,
-	)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransferByFromBaseAssetIDAndBeforeBlockNumberCtx(ctx, dbConnPgx, baseAssetID, blockNumber)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransferByFromBaseAssetIDAndBeforeBlockNumberCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int, blockNumber *uint64) ([]GethTransfer, error) {
+	return GethTransferRepository.Select(ctx, dbConnPgx, `WHERE
+	asset_id = $1
+	AND
+	base_asset_id =$1
+	AND block_number <= $2
+	AND sender_address_id IS NOT NULL
+	AND to_address_id IS NOT NULL`, *baseAssetID, *blockNumber)
 }
 
 func GetGethTransfersByTxnHash(dbConnPgx utils.PgxIface, txnHash string, baseAssetID *int) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-		FROM geth_transfers
-		WHERE
-		txn_hash = $1
-		AND base_asset_id = $2
-		`,
-		txnHash, *baseAssetID,
-	)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransfersByTxnHashCtx(ctx, dbConnPgx, txnHash, baseAssetID)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransfersByTxnHashCtx(ctx context.Context, dbConnPgx utils.PgxIface, txnHash string, baseAssetID *int) ([]GethTransfer, error) {
+	return GethTransferRepository.Select(ctx, dbConnPgx, `WHERE
+	txn_hash = $1
+	AND base_asset_id = $2`, txnHash, *baseAssetID)
 }
 
 func GetGethTransfersByTxnHashes(dbConnPgx utils.PgxIface, txnHashes []string, baseAssetID *int) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-		FROM geth_transfers
-		WHERE
-		txn_hash = ANY($1)
-		AND base_asset_id = $2
-		`,
-		pq.Array(txnHashes), *baseAssetID,
-	)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransfersByTxnHashesCtx(ctx, dbConnPgx, txnHashes, baseAssetID)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransfersByTxnHashesCtx(ctx context.Context, dbConnPgx utils.PgxIface, txnHashes []string, baseAssetID *int) ([]GethTransfer, error) {
+	return GethTransferRepository.Select(ctx, dbConnPgx, `WHERE
+	txn_hash = ANY($1)
+	AND base_asset_id = $2`, pq.Array(txnHashes), *baseAssetID)
 }
 
 func RemoveGethTransfer(dbConnPgx utils.PgxIface, gethTransferID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveGethTransfer DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM geth_transfers WHERE id = $1`
+	return RemoveGethTransferCtx(ctx, dbConnPgx, gethTransferID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *gethTransferID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveGethTransferCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethTransferID *int) error {
+	return GethTransferRepository.Remove(ctx, dbConnPgx, *gethTransferID)
 }
 
 func RemoveGethTransfersFromBaseAssetIDAndStartBlockNumber(dbConnPgx utils.PgxIface, baseAssetID *int, startBlockNumber *uint64) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return RemoveGethTransfersFromBaseAssetIDAndStartBlockNumberCtx(ctx, dbConnPgx, baseAssetID, startBlockNumber)
+}
+
+func RemoveGethTransfersFromBaseAssetIDAndStartBlockNumberCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int, startBlockNumber *uint64) error {
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in RemoveGethTransfersFromBaseAssetIDAndStartBlockNumber DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "RemoveGethTransfersFromBaseAssetIDAndStartBlockNumber: begin", err, logging.Entity("geth_transfers"), logging.BlockNumber(startBlockNumber))
+		return dberrors.Wrap(err)
 	}
 	sql := `DELETE FROM geth_transfers WHERE base_asset_id = $1 AND block_number >=  $2`
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *baseAssetID, *startBlockNumber); err != nil {
+	if _, err := tx.Exec(ctx, sql, *baseAssetID, *startBlockNumber); err != nil {
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 
 func RemoveGethTransfersFromBaseAssetID(dbConnPgx utils.PgxIface, baseAssetID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return RemoveGethTransfersFromBaseAssetIDCtx(ctx, dbConnPgx, baseAssetID)
+}
+
+func RemoveGethTransfersFromBaseAssetIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int) error {
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in RemoveGethTransfersFromBaseAssetID DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "RemoveGethTransfersFromBaseAssetID: begin", err, logging.Entity("geth_transfers"))
+		return dberrors.Wrap(err)
 	}
 	sql := `DELETE FROM geth_transfers WHERE base_asset_id = $1`
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *baseAssetID); err != nil {
+	if _, err := tx.Exec(ctx, sql, *baseAssetID); err != nil {
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 
 func GetGethTransferList(dbConnPgx utils.PgxIface) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-	FROM geth_transfers `)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransferListCtx(ctx, dbConnPgx)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransferListCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]GethTransfer, error) {
+	return GethTransferRepository.GetList(ctx, dbConnPgx, nil)
 }
 
 func UpdateGethTransfer(dbConnPgx utils.PgxIface, gethTransfer *GethTransfer) error {
-	// if the gethTransfer id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if gethTransfer.ID == nil {
-		return errors.New("gethTransfer has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateGethTransfer DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `UPDATE geth_transfers SET 
-		chain_id=$1,
-		token_address=$2,
-		token_address_id = $3,
-		asset_id=$4,
-		block_number=$5,
-		index_number=$6,
-		transfer_date=$7,
-		txn_hash=$8,
-		sender_address=$9,
-		sender_address_id=$10,
-		to_address=$11,
-		to_address_id=$12,
-		amount=$13,
-		description=$14,
-		updated_by=$15,
-		updated_at=current_timestamp at time zone 'UTC',
-		geth_process_job_id =$16,
-		topics_str=$17,
-		status_id=$18,
-		base_asset_id=$19,
-		transfer_type_id=$20
-		WHERE id=$21`
-
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		gethTransfer.ChainID,             //1
-		gethTransfer.TokenAddress,        //2
-		gethTransfer.TokenAddressID,      //3
-		gethTransfer.AssetID,             //4
-		gethTransfer.BlockNumber,         //5
-		gethTransfer.IndexNumber,         //6
-		gethTransfer.TransferDate,        //7
-		gethTransfer.TxnHash,             //8
-		gethTransfer.SenderAddress,       //9
-		gethTransfer.SenderAddressID,     //10
-		gethTransfer.ToAddress,           //11
-		gethTransfer.ToAddressID,         //12
-		gethTransfer.Amount,              //13
-		gethTransfer.Description,         //14
-		gethTransfer.UpdatedBy,           //15
-		gethTransfer.GethProcessJobID,    //16
-		pq.Array(gethTransfer.TopicsStr), //17
-		gethTransfer.StatusID,            //18
-		gethTransfer.BaseAssetID,         //19
-		gethTransfer.TransferTypeID,      //20
-		gethTransfer.ID,                  //21
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+	return UpdateGethTransferCtx(ctx, dbConnPgx, gethTransfer)
+}
+
+func UpdateGethTransferCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethTransfer *GethTransfer) error {
+	return GethTransferRepository.Update(ctx, dbConnPgx, gethTransfer)
 }
 
 func InsertGethTransfer(dbConnPgx utils.PgxIface, gethTransfer *GethTransfer) (int, string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertGethTransfer DbConn.Begin   %s", err.Error())
-		return -1, "", err
-	}
-	var gethTransferID int
-	var gethTransferUUID string
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO geth_transfers
-	(
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-		) VALUES (
-		uuid_generate_v4(),
-		$1,
-		$2,
-		$3,
-		$4,
-		$5,
-		$6,
-		$7,
-		$8,
-		$9,
-		$10,
-		$11,
-		$12,
-		$13,
-		$14,
-		$15,
-		current_timestamp at time zone 'UTC',
-		$15,
-		current_timestamp at time zone 'UTC', 
-		$16,
-		$17,
-		$18,
-		$19,
-		$20
-		)
-		RETURNING id, uuid`,
-		gethTransfer.ChainID,             //1
-		gethTransfer.TokenAddress,        //2
-		gethTransfer.TokenAddressID,      //3
-		gethTransfer.AssetID,             //4
-		gethTransfer.BlockNumber,         //5
-		gethTransfer.IndexNumber,         //6
-		gethTransfer.TransferDate,        //7
-		gethTransfer.TxnHash,             //8
-		gethTransfer.SenderAddress,       //9
-		gethTransfer.SenderAddressID,     //10
-		gethTransfer.ToAddress,           //11
-		gethTransfer.ToAddressID,         //12
-		gethTransfer.Amount,              //13
-		gethTransfer.Description,         //14
-		gethTransfer.CreatedBy,           //15
-		gethTransfer.GethProcessJobID,    //16
-		pq.Array(gethTransfer.TopicsStr), //17
-		gethTransfer.StatusID,            //18
-		gethTransfer.BaseAssetID,         //19
-		gethTransfer.TransferTypeID,      //20
-	).Scan(&gethTransferID, &gethTransferUUID)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, "", err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, "", err
-	}
-	return int(gethTransferID), gethTransferUUID, nil
+	return InsertGethTransferCtx(ctx, dbConnPgx, gethTransfer)
+}
+
+func InsertGethTransferCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethTransfer *GethTransfer) (int, string, error) {
+	return GethTransferRepository.InsertReturningUUID(ctx, dbConnPgx, gethTransfer)
 }
 func InsertGethTransfers(dbConnPgx utils.PgxIface, gethTransfers []GethTransfer) error {
-	// need to supply uuid
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range gethTransfers {
-		gethTransfer := gethTransfers[i]
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(gethTransfer.UUID)
-		row := []interface{}{
-			uuidString,                       //1
-			gethTransfer.ChainID,             //2
-			gethTransfer.TokenAddress,        //3
-			gethTransfer.TokenAddressID,      //4
-			gethTransfer.AssetID,             //5
-			gethTransfer.BlockNumber,         //6
-			gethTransfer.IndexNumber,         //7
-			gethTransfer.TransferDate,        //8
-			gethTransfer.TxnHash,             //9
-			gethTransfer.SenderAddress,       //10
-			gethTransfer.SenderAddressID,     //11
-			gethTransfer.ToAddress,           //12
-			gethTransfer.ToAddressID,         //13
-			gethTransfer.Amount,              //14
-			gethTransfer.Description,         //15
-			gethTransfer.CreatedBy,           //16
-			&now,                             //17
-			gethTransfer.CreatedBy,           //18
-			&now,                             //19
-			gethTransfer.GethProcessJobID,    //20
-			pq.Array(gethTransfer.TopicsStr), //21
-			gethTransfer.StatusID,            //22
-			gethTransfer.BaseAssetID,         //23
-			gethTransfer.TransferTypeID,      //24
-
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"geth_transfers"},
-		[]string{
-			"uuid",                //1
-			"chain_id",            //2
-			"token_address",       //3
-			"token_address_id",    //4
-			"asset_id",            //5
-			"block_number",        //6
-			"index_number",        //7
-			"transfer_date",       //8
-			"txn_hash",            //9
-			"sender_address",      //10
-			"sender_address_id",   //11
-			"to_address",          //12
-			"to_address_id",       //13
-			"amount",              //14
-			"description",         //15
-			"created_by",          //16
-			"created_at",          //17
-			"updated_by",          //18
-			"updated_at",          //19
-			"geth_process_job_id", //20
-			"topics_str",          //21
-			"status_id",           //22
-			"base_asset_id",       //23
-			"transfer_type_id",    //24
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertGethTransfers: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
-	return nil
+	return InsertGethTransfersCtx(ctx, dbConnPgx, gethTransfers)
+}
+
+func InsertGethTransfersCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethTransfers []GethTransfer) error {
+	_, err := GethTransferRepository.InsertMany(ctx, dbConnPgx, gethTransfers)
+	return dberrors.Wrap(err)
+}
+
+// UpsertGethTransfers writes gethTransfers keyed on (chain_id, txn_hash,
+// block_number, transfer_type_id, index_number), so a block range can be
+// replayed without removing its transfers first. The transfer type is part of
+// the key as native, internal and ERC-20 transfers are numbered differently.
+// It returns the number of inserted and updated transfers.
+func UpsertGethTransfers(dbConnPgx utils.PgxIface, gethTransfers []GethTransfer) (int64, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return UpsertGethTransfersCtx(ctx, dbConnPgx, gethTransfers)
+}
+
+func UpsertGethTransfersCtx(ctx context.Context, dbConnPgx utils.PgxIface, gethTransfers []GethTransfer) (int64, int64, error) {
+	return GethTransferRepository.UpsertMany(ctx, dbConnPgx, []string{"chain_id", "txn_hash", "block_number", "transfer_type_id", "index_number"}, gethTransfers)
 }
 
 func UpdateGethTransferAddresses(dbConnPgx utils.PgxIface, baseAssetID *int) error {
-	// update address ids from existing addresses in geth_addresses
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return UpdateGethTransferAddressesCtx(ctx, dbConnPgx, baseAssetID)
+}
+
+func UpdateGethTransferAddressesCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int) error {
+	// update address ids from existing addresses in geth_addresses
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in UpdateGethTransferAddresses DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "UpdateGethTransferAddresses: begin", err, logging.Entity("geth_transfers"))
+		return dberrors.Wrap(err)
 	}
 	sql := `UPDATE geth_transfers as gt SET
 			sender_address_id = ga.id from geth_addresses as ga
@@ -882,10 +388,10 @@ func UpdateGethTransferAddresses(dbConnPgx utils.PgxIface, baseAssetID *int) err
 				AND LOWER(gt.sender_address) = LOWER(ga.address_str)
 			`
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *baseAssetID); err != nil {
-		log.Println(fmt.Printf("UpdateGethTransferAddresses: Error at sql1 %v", err))
+	if _, err := tx.Exec(ctx, sql, *baseAssetID); err != nil {
+		logging.ReturnedError(ctx, "UpdateGethTransferAddresses", err, logging.Entity("geth_transfers"))
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
 	sql2 := `UPDATE geth_transfers as gt SET
 			to_address_id = ga.id from geth_addresses as ga
@@ -894,10 +400,10 @@ func UpdateGethTransferAddresses(dbConnPgx utils.PgxIface, baseAssetID *int) err
 				AND	gt.base_asset_id = $1
 				AND LOWER(gt.to_address) = LOWER(ga.address_str)
 			`
-	if _, err := dbConnPgx.Exec(ctx, sql2, *baseAssetID); err != nil {
-		log.Println(fmt.Printf("UpdateGethTransferAddresses: Error at sql2 %v", err))
+	if _, err := tx.Exec(ctx, sql2, *baseAssetID); err != nil {
+		logging.ReturnedError(ctx, "UpdateGethTransferAddresses", err, logging.Entity("geth_transfers"))
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
 	sql3 := `UPDATE geth_transfers as gt SET
 			asset_id = assets.id
@@ -907,17 +413,21 @@ func UpdateGethTransferAddresses(dbConnPgx utils.PgxIface, baseAssetID *int) err
 				AND	gt.base_asset_id = $1
 				AND LOWER(gt.token_address) = LOWER(assets.contract_address)
 	`
-	if _, err := dbConnPgx.Exec(ctx, sql3, *baseAssetID); err != nil {
-		log.Println(fmt.Printf("UpdateGethTransferAddresses: Error at sql3 %v", err))
+	if _, err := tx.Exec(ctx, sql3, *baseAssetID); err != nil {
+		logging.ReturnedError(ctx, "UpdateGethTransferAddresses", err, logging.Entity("geth_transfers"))
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 
 func GetNullAddressStrsFromTransfers(dbConnPgx utils.PgxIface, baseAssetID *int) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetNullAddressStrsFromTransfersCtx(ctx, dbConnPgx, baseAssetID)
+}
+
+func GetNullAddressStrsFromTransfersCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int) ([]string, error) {
 	results, err := dbConnPgx.Query(ctx, `
 		WITH sender_table as(
 			SELECT DISTINCT LOWER(gt.sender_address) as address  
@@ -943,8 +453,8 @@ func GetNullAddressStrsFromTransfers(dbConnPgx utils.PgxIface, baseAssetID *int)
 		`, *baseAssetID,
 	)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetNullAddressStrsFromTransfers", err, logging.Entity("geth_transfers"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	gethNullAddressStrs := make([]string, 0)
@@ -959,13 +469,17 @@ func GetNullAddressStrsFromTransfers(dbConnPgx utils.PgxIface, baseAssetID *int)
 }
 
 func UpdateGethTransfersAssetIDs(dbConnPgx utils.PgxIface) error {
-	// update address ids from existing addresses in geth_addresses
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return UpdateGethTransfersAssetIDsCtx(ctx, dbConnPgx)
+}
+
+func UpdateGethTransfersAssetIDsCtx(ctx context.Context, dbConnPgx utils.PgxIface) error {
+	// update address ids from existing addresses in geth_addresses
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in UpdateGethSwap DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "UpdateGethTransfersAssetIDs: begin", err, logging.Entity("geth_transfers"))
+		return dberrors.Wrap(err)
 	}
 	sql := `UPDATE geth_transfers as gt SET
 		asset_id = assets.id from assets as assets
@@ -973,153 +487,47 @@ func UpdateGethTransfersAssetIDs(dbConnPgx utils.PgxIface) error {
 			gt.asset_id is NULL
 	`
 
-	if _, err := dbConnPgx.Exec(ctx, sql); err != nil {
+	if _, err := tx.Exec(ctx, sql); err != nil {
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 
 // for refinedev
-func GetGethTransferListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetGethTransferListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]GethTransfer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `
-	SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-	FROM geth_transfers 
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetGethTransferListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
+}
 
-	gethTransfers, err := pgx.CollectRows(results, pgx.RowToStructByName[GethTransfer])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return gethTransfers, nil
+func GetGethTransferListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]GethTransfer, error) {
+	return GethTransferRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
 }
 
 func GetTotalTransfersCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalTransfersCountCtx(ctx, dbConnPgx)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT 
-	COUNT(*)
-	FROM geth_transfers
-	`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalTransfersCountCtx(ctx context.Context, dbConnPgx utils.PgxIface) (*int, error) {
+	return GethTransferRepository.Count(ctx, dbConnPgx)
 }
 
 func GetClosestBlockNumberFromGethTransferFromChainAndDate(dbConnPgx utils.PgxIface, chainID *int, asOfDate *time.Time, isBefore *bool) (*GethTransfer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetClosestBlockNumberFromGethTransferFromChainAndDateCtx(ctx, dbConnPgx, chainID, asOfDate, isBefore)
+}
 
-	selectSQL := `SELECT
-		id,
-		uuid,
-		chain_id,
-		token_address,
-		token_address_id,
-		asset_id,
-		block_number,
-		index_number,
-		transfer_date,
-		txn_hash,
-		sender_address,
-		sender_address_id,
-		to_address,
-		to_address_id,
-		amount,
-		description,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-		geth_process_job_id,
-		topics_str,
-		status_id,
-		base_asset_id,
-		transfer_type_id
-	FROM geth_transfers 
-	WHERE 
-	`
-	tradeDateSQL := ``
+func GetClosestBlockNumberFromGethTransferFromChainAndDateCtx(ctx context.Context, dbConnPgx utils.PgxIface, chainID *int, asOfDate *time.Time, isBefore *bool) (*GethTransfer, error) {
+	clause := `WHERE chain_id = $1
+	AND block_number IS NOT NULL`
 	if *isBefore {
-		tradeDateSQL = ` transfer_date <= $2 
-		AND chain_id = $1
-		AND block_number IS NOT NULL 
-		ORDER BY transfer_date desc`
+		clause += ` AND transfer_date <= $2 ORDER BY transfer_date desc`
 	} else {
-		tradeDateSQL = `  transfer_date >= $2
-		AND chain_id = $1
-		AND block_number IS NOT NULL 
-		 ORDER BY transfer_date asc`
-	}
-	sql := selectSQL + tradeDateSQL + ` LIMIT 1`
-	row, err := dbConnPgx.Query(ctx, sql, *chainID, asOfDate.Format(utils.LayoutPostgres))
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	gethTrade, err := pgx.CollectOneRow(row, pgx.RowToStructByName[GethTransfer])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
+		clause += ` AND transfer_date >= $2 ORDER BY transfer_date asc`
 	}
-	return &gethTrade, nil
+	return GethTransferRepository.SelectOne(ctx, dbConnPgx, clause+` LIMIT 1`, *chainID, asOfDate.Format(utils.LayoutPostgres))
 }
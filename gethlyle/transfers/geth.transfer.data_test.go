@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	gethlyleaddresses "github.com/kfukue/lyle-labs-libraries/v2/gethlyle/address"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
@@ -15,59 +17,7 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-var DBColumns = []string{
-	"id",                  //1
-	"uuid",                //2
-	"chain_id",            //3
-	"token_address",       //4
-	"token_address_id",    //5
-	"asset_id",            //6
-	"block_number",        //7
-	"index_number",        //8
-	"transfer_date",       //9
-	"txn_hash",            //10
-	"sender_address",      //11
-	"sender_address_id",   //12
-	"to_address",          //13
-	"to_address_id",       //14
-	"amount",              //15
-	"description",         //16
-	"created_by",          //17
-	"created_at",          //18
-	"updated_by",          //19
-	"updated_at",          //20
-	"geth_process_job_id", //21
-	"topics_str",          //22
-	"status_id",           //23
-	"base_asset_id",       //24
-	"transfer_type_id",    //25
-}
-var DBColumnsInsertGethTransfers = []string{
-	"uuid",                //1
-	"chain_id",            //2
-	"token_address",       //3
-	"token_address_id",    //4
-	"asset_id",            //5
-	"block_number",        //6
-	"index_number",        //7
-	"transfer_date",       //8
-	"txn_hash",            //9
-	"sender_address",      //10
-	"sender_address_id",   //11
-	"to_address",          //12
-	"to_address_id",       //13
-	"amount",              //14
-	"description",         //15
-	"created_by",          //16
-	"created_at",          //17
-	"updated_by",          //18
-	"updated_at",          //19
-	"geth_process_job_id", //20
-	"topics_str",          //21
-	"status_id",           //22
-	"base_asset_id",       //23
-	"transfer_type_id",    //24
-}
+var DBColumns = GethTransferRepository.Columns()
 
 var TestData1 = GethTransfer{
 	ID:               utils.Ptr[int](1),                                                              //1
@@ -128,37 +78,7 @@ var TestData2 = GethTransfer{
 var TestAllData = []GethTransfer{TestData1, TestData2}
 
 func AddGethTransferToMockRows(mock pgxmock.PgxPoolIface, dataList []GethTransfer) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,               //1
-			data.UUID,             //2
-			data.ChainID,          //3
-			data.TokenAddress,     //4
-			data.TokenAddressID,   //5
-			data.AssetID,          //6
-			data.BlockNumber,      //7
-			data.IndexNumber,      //8
-			data.TransferDate,     //9
-			data.TxnHash,          //10
-			data.SenderAddress,    //11
-			data.SenderAddressID,  //12
-			data.ToAddress,        //13
-			data.ToAddressID,      //14
-			data.Amount,           //15
-			data.Description,      //16
-			data.CreatedBy,        //17
-			data.CreatedAt,        //18
-			data.UpdatedBy,        //19
-			data.UpdatedAt,        //20
-			data.GethProcessJobID, //21
-			data.TopicsStr,        //22
-			data.StatusID,         //23
-			data.BaseAssetID,      //24
-			data.TransferTypeID,   //25
-		)
-	}
-	return rows
+	return GethTransferRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetGethTransfer(t *testing.T) {
@@ -194,8 +114,8 @@ func TestGetGethTransferForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WithArgs(gethTransferID).WillReturnRows(noRows)
 	foundGethTransfer, err := GetGethTransfer(mock, &gethTransferID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTransfer", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTransfer != nil {
 		t.Errorf("Expected GethTransfer From Method GetGethTransfer: to be empty but got this: %v", foundGethTransfer)
@@ -286,8 +206,8 @@ func TestGetGethTransferByBlockChainForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WithArgs(txnHash, *blockNumber, *indexNumber).WillReturnRows(noRows)
 	foundGethTransfer, err := GetGethTransferByBlockChain(mock, txnHash, blockNumber, indexNumber)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetGethTransferByBlockChain", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundGethTransfer != nil {
 		t.Errorf("Expected GethTransfer From Method GetGethTransferByBlockChain: to be empty but got this: %v", foundGethTransfer)
@@ -1205,27 +1125,27 @@ func TestUpdateGethTransfer(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_transfers").WithArgs(
-		targetData.ChainID,             //1
-		targetData.TokenAddress,        //2
-		targetData.TokenAddressID,      //3
-		targetData.AssetID,             //4
-		targetData.BlockNumber,         //5
-		targetData.IndexNumber,         //6
-		targetData.TransferDate,        //7
-		targetData.TxnHash,             //8
-		targetData.SenderAddress,       //9
-		targetData.SenderAddressID,     //10
-		targetData.ToAddress,           //11
-		targetData.ToAddressID,         //12
-		targetData.Amount,              //13
-		targetData.Description,         //14
-		targetData.UpdatedBy,           //15
-		targetData.GethProcessJobID,    //16
-		pq.Array(targetData.TopicsStr), //17
-		targetData.StatusID,            //18
-		targetData.BaseAssetID,         //19
-		targetData.TransferTypeID,      //20
-		targetData.ID,                  //21
+		targetData.ChainID,          //1
+		targetData.TokenAddress,     //2
+		targetData.TokenAddressID,   //3
+		targetData.AssetID,          //4
+		targetData.BlockNumber,      //5
+		targetData.IndexNumber,      //6
+		targetData.TransferDate,     //7
+		targetData.TxnHash,          //8
+		targetData.SenderAddress,    //9
+		targetData.SenderAddressID,  //10
+		targetData.ToAddress,        //11
+		targetData.ToAddressID,      //12
+		targetData.Amount,           //13
+		targetData.Description,      //14
+		targetData.UpdatedBy,        //15
+		targetData.GethProcessJobID, //16
+		targetData.TopicsStr,        //17
+		targetData.StatusID,         //18
+		targetData.BaseAssetID,      //19
+		targetData.TransferTypeID,   //20
+		targetData.ID,               //21
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateGethTransfer(mock, &targetData)
@@ -1281,27 +1201,27 @@ func TestUpdateGethTransferOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE geth_transfers").WithArgs(
-		targetData.ChainID,             //1
-		targetData.TokenAddress,        //2
-		targetData.TokenAddressID,      //3
-		targetData.AssetID,             //4
-		targetData.BlockNumber,         //5
-		targetData.IndexNumber,         //6
-		targetData.TransferDate,        //7
-		targetData.TxnHash,             //8
-		targetData.SenderAddress,       //9
-		targetData.SenderAddressID,     //10
-		targetData.ToAddress,           //11
-		targetData.ToAddressID,         //12
-		targetData.Amount,              //13
-		targetData.Description,         //14
-		targetData.UpdatedBy,           //15
-		targetData.GethProcessJobID,    //16
-		pq.Array(targetData.TopicsStr), //17
-		targetData.StatusID,            //18
-		targetData.BaseAssetID,         //19
-		targetData.TransferTypeID,      //20
-		targetData.ID,                  //21
+		targetData.ChainID,          //1
+		targetData.TokenAddress,     //2
+		targetData.TokenAddressID,   //3
+		targetData.AssetID,          //4
+		targetData.BlockNumber,      //5
+		targetData.IndexNumber,      //6
+		targetData.TransferDate,     //7
+		targetData.TxnHash,          //8
+		targetData.SenderAddress,    //9
+		targetData.SenderAddressID,  //10
+		targetData.ToAddress,        //11
+		targetData.ToAddressID,      //12
+		targetData.Amount,           //13
+		targetData.Description,      //14
+		targetData.UpdatedBy,        //15
+		targetData.GethProcessJobID, //16
+		targetData.TopicsStr,        //17
+		targetData.StatusID,         //18
+		targetData.BaseAssetID,      //19
+		targetData.TransferTypeID,   //20
+		targetData.ID,               //21
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -1324,26 +1244,26 @@ func TestInsertGethTransfer(t *testing.T) {
 	uuid := "01ef85e8-2c26-441e-8c7f-71d79518ad72"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_transfers").WithArgs(
-		targetData.ChainID,             //1
-		targetData.TokenAddress,        //2
-		targetData.TokenAddressID,      //3
-		targetData.AssetID,             //4
-		targetData.BlockNumber,         //5
-		targetData.IndexNumber,         //6
-		targetData.TransferDate,        //7
-		targetData.TxnHash,             //8
-		targetData.SenderAddress,       //9
-		targetData.SenderAddressID,     //10
-		targetData.ToAddress,           //11
-		targetData.ToAddressID,         //12
-		targetData.Amount,              //13
-		targetData.Description,         //14
-		targetData.CreatedBy,           //15
-		targetData.GethProcessJobID,    //16
-		pq.Array(targetData.TopicsStr), //17
-		targetData.StatusID,            //18
-		targetData.BaseAssetID,         //19
-		targetData.TransferTypeID,      //20
+		targetData.ChainID,          //1
+		targetData.TokenAddress,     //2
+		targetData.TokenAddressID,   //3
+		targetData.AssetID,          //4
+		targetData.BlockNumber,      //5
+		targetData.IndexNumber,      //6
+		targetData.TransferDate,     //7
+		targetData.TxnHash,          //8
+		targetData.SenderAddress,    //9
+		targetData.SenderAddressID,  //10
+		targetData.ToAddress,        //11
+		targetData.ToAddressID,      //12
+		targetData.Amount,           //13
+		targetData.Description,      //14
+		targetData.CreatedBy,        //15
+		targetData.GethProcessJobID, //16
+		targetData.TopicsStr,        //17
+		targetData.StatusID,         //18
+		targetData.BaseAssetID,      //19
+		targetData.TransferTypeID,   //20
 	).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(1, uuid))
 	mock.ExpectCommit()
 	gethTransferID, newUUID, err := InsertGethTransfer(mock, &targetData)
@@ -1390,26 +1310,26 @@ func TestInsertGethTransferOnFailure(t *testing.T) {
 	targetData.BlockNumber = nil
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_transfers").WithArgs(
-		targetData.ChainID,             //1
-		targetData.TokenAddress,        //2
-		targetData.TokenAddressID,      //3
-		targetData.AssetID,             //4
-		targetData.BlockNumber,         //5
-		targetData.IndexNumber,         //6
-		targetData.TransferDate,        //7
-		targetData.TxnHash,             //8
-		targetData.SenderAddress,       //9
-		targetData.SenderAddressID,     //10
-		targetData.ToAddress,           //11
-		targetData.ToAddressID,         //12
-		targetData.Amount,              //13
-		targetData.Description,         //14
-		targetData.CreatedBy,           //15
-		targetData.GethProcessJobID,    //16
-		pq.Array(targetData.TopicsStr), //17
-		targetData.StatusID,            //18
-		targetData.BaseAssetID,         //19
-		targetData.TransferTypeID,      //20
+		targetData.ChainID,          //1
+		targetData.TokenAddress,     //2
+		targetData.TokenAddressID,   //3
+		targetData.AssetID,          //4
+		targetData.BlockNumber,      //5
+		targetData.IndexNumber,      //6
+		targetData.TransferDate,     //7
+		targetData.TxnHash,          //8
+		targetData.SenderAddress,    //9
+		targetData.SenderAddressID,  //10
+		targetData.ToAddress,        //11
+		targetData.ToAddressID,      //12
+		targetData.Amount,           //13
+		targetData.Description,      //14
+		targetData.CreatedBy,        //15
+		targetData.GethProcessJobID, //16
+		targetData.TopicsStr,        //17
+		targetData.StatusID,         //18
+		targetData.BaseAssetID,      //19
+		targetData.TransferTypeID,   //20
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	gethTransferID, newUUID, err := InsertGethTransfer(mock, &targetData)
@@ -1438,26 +1358,26 @@ func TestInsertGethTransferOnFailureOnCommit(t *testing.T) {
 	uuid := "01ef85e8-2c26-441e-8c7f-71d79518ad72"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO geth_transfers").WithArgs(
-		targetData.ChainID,             //1
-		targetData.TokenAddress,        //2
-		targetData.TokenAddressID,      //3
-		targetData.AssetID,             //4
-		targetData.BlockNumber,         //5
-		targetData.IndexNumber,         //6
-		targetData.TransferDate,        //7
-		targetData.TxnHash,             //8
-		targetData.SenderAddress,       //9
-		targetData.SenderAddressID,     //10
-		targetData.ToAddress,           //11
-		targetData.ToAddressID,         //12
-		targetData.Amount,              //13
-		targetData.Description,         //14
-		targetData.CreatedBy,           //15
-		targetData.GethProcessJobID,    //16
-		pq.Array(targetData.TopicsStr), //17
-		targetData.StatusID,            //18
-		targetData.BaseAssetID,         //19
-		targetData.TransferTypeID,      //20
+		targetData.ChainID,          //1
+		targetData.TokenAddress,     //2
+		targetData.TokenAddressID,   //3
+		targetData.AssetID,          //4
+		targetData.BlockNumber,      //5
+		targetData.IndexNumber,      //6
+		targetData.TransferDate,     //7
+		targetData.TxnHash,          //8
+		targetData.SenderAddress,    //9
+		targetData.SenderAddressID,  //10
+		targetData.ToAddress,        //11
+		targetData.ToAddressID,      //12
+		targetData.Amount,           //13
+		targetData.Description,      //14
+		targetData.CreatedBy,        //15
+		targetData.GethProcessJobID, //16
+		targetData.TopicsStr,        //17
+		targetData.StatusID,         //18
+		targetData.BaseAssetID,      //19
+		targetData.TransferTypeID,   //20
 	).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(1, uuid))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -1510,6 +1430,49 @@ func TestInsertGethTransfersOnFailure(t *testing.T) {
 	}
 }
 
+func TestUpsertGethTransfers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^CREATE TEMP TABLE tmp_geth_transfers").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"tmp_geth_transfers"}, DBColumnsInsertGethTransfers).WillReturnResult(2)
+	mock.ExpectQuery("^WITH merged AS (.+) ON CONFLICT \\(chain_id, txn_hash, block_number, transfer_type_id, index_number\\)").WillReturnRows(pgxmock.NewRows([]string{"inserted", "updated"}).AddRow(int64(1), int64(1)))
+	mock.ExpectExec("^DROP TABLE tmp_geth_transfers").WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mock.ExpectCommit()
+	inserted, updated, err := UpsertGethTransfers(mock, TestAllData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpsertGethTransfers", err)
+	}
+	if inserted != 1 || updated != 1 {
+		t.Errorf("Expected 1 inserted and 1 updated, got %d and %d", inserted, updated)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpsertGethTransfersOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^CREATE TEMP TABLE tmp_geth_transfers").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"tmp_geth_transfers"}, DBColumnsInsertGethTransfers).WillReturnError(fmt.Errorf("Random SQL Error"))
+	mock.ExpectRollback()
+	_, _, err = UpsertGethTransfers(mock, TestAllData)
+	if err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestUpdateGethTransferAddresses(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -1716,8 +1679,8 @@ func TestGetGethTransferListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1", "asset_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("chain_id", "1"), filter.Equal("asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundGethTransferList, err := GetGethTransferListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetGethTransferListByPagination", err)
@@ -1742,8 +1705,8 @@ func TestGetGethTransferListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("chain_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundGethTransferList, err := GetGethTransferListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTransferListByPagination", err)
@@ -1766,9 +1729,9 @@ func TestGetGethTransferListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1"}
+	filters := filter.All(filter.Equal("chain_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM geth_transfers").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundGethTransferList, err := GetGethTransferListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetGethTransferListByPagination", err)
@@ -0,0 +1,3 @@
+package gethlyletransfers
+
+var DBColumnsInsertGethTransfers = GethTransferRepository.InsertColumns()
@@ -0,0 +1,194 @@
+// Package instrument wraps a utils.PgxIface so every query records Prometheus
+// metrics, can be traced with OpenTelemetry and is logged when slow.
+//
+//	db := instrument.Wrap(pool, instrument.WithSlowQueryThreshold(time.Second))
+//	asset, err := asset.GetAssetCtx(ctx, db, &assetID)
+//
+// Metrics are labelled by the data function that ran the query, e.g.
+// "asset.GetAssetCtx", and by operation (query, query_row, exec, copy_from,
+// begin). Transactions started through the wrapper are instrumented as well.
+package instrument
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Operations used as the operation label.
+const (
+	OperationQuery    = "query"
+	OperationQueryRow = "query_row"
+	OperationExec     = "exec"
+	OperationCopyFrom = "copy_from"
+	OperationBegin    = "begin"
+)
+
+// DB is a utils.PgxIface that instruments the calls to the one it wraps.
+type DB struct {
+	db utils.PgxIface
+	*config
+}
+
+type config struct {
+	metrics       *Metrics
+	tracer        trace.Tracer
+	slowThreshold time.Duration
+}
+
+// Option customises a DB created with Wrap.
+type Option func(*config)
+
+// WithMetrics records into m instead of DefaultMetrics().
+func WithMetrics(m *Metrics) Option {
+	return func(c *config) { c.metrics = m }
+}
+
+// WithTracer creates a span for every call. There are no spans without it.
+func WithTracer(t trace.Tracer) Option {
+	return func(c *config) { c.tracer = t }
+}
+
+// WithSlowQueryThreshold logs the calls that take at least d at warn level.
+// Zero, the default, disables the slow query log.
+func WithSlowQueryThreshold(d time.Duration) Option {
+	return func(c *config) { c.slowThreshold = d }
+}
+
+// Wrap returns db instrumented as described by opts.
+func Wrap(db utils.PgxIface, opts ...Option) *DB {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.metrics == nil {
+		c.metrics = DefaultMetrics()
+	}
+	return &DB{db: db, config: c}
+}
+
+// Unwrap returns the wrapped connection.
+func (d *DB) Unwrap() utils.PgxIface {
+	return d.db
+}
+
+func (d *DB) Begin(ctx context.Context) (pgx.Tx, error) {
+	call := d.start(ctx, OperationBegin, "BEGIN")
+	tx, err := d.db.Begin(call.ctx)
+	call.end(0, err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTx{Tx: tx, config: d.config}, nil
+}
+
+func (d *DB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return copyFrom(ctx, d.config, d.db.CopyFrom, tableName, columnNames, rowSrc)
+}
+
+func (d *DB) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return exec(ctx, d.config, d.db.Exec, sql, arguments...)
+}
+
+func (d *DB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return queryRow(ctx, d.config, d.db.QueryRow, sql, args...)
+}
+
+func (d *DB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return query(ctx, d.config, d.db.Query, sql, args...)
+}
+
+func (d *DB) Ping(ctx context.Context) error {
+	return d.db.Ping(ctx)
+}
+
+func (d *DB) Close() {
+	d.db.Close()
+}
+
+// call is one instrumented call from start to end.
+type call struct {
+	*config
+	ctx       context.Context
+	caller    string
+	operation string
+	sql       string
+	started   time.Time
+	span      trace.Span
+}
+
+func (c *config) start(ctx context.Context, operation, sql string) *call {
+	cl := &call{config: c, ctx: ctx, caller: callerName(), operation: operation, sql: sql, started: time.Now()}
+	if c.tracer != nil {
+		cl.ctx, cl.span = c.tracer.Start(ctx, "db."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", sql),
+			attribute.String("code.function", cl.caller),
+		))
+	}
+	return cl
+}
+
+// end records the call, which read or wrote rows rows and failed with err.
+func (cl *call) end(rows int64, err error) time.Duration {
+	elapsed := time.Since(cl.started)
+	cl.metrics.observe(cl.caller, cl.operation, elapsed, rows, err)
+	if cl.span != nil {
+		cl.span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+		if err != nil {
+			cl.span.RecordError(err)
+			cl.span.SetStatus(codes.Error, err.Error())
+		}
+		cl.span.End()
+	}
+	if cl.slowThreshold > 0 && elapsed >= cl.slowThreshold {
+		logging.FromContext(cl.ctx).WarnContext(cl.ctx, "slow query",
+			slog.String("caller", cl.caller),
+			slog.String("operation", cl.operation),
+			slog.String("sql", cl.sql),
+			logging.Duration(elapsed),
+			logging.Rows(rows),
+		)
+	}
+	return elapsed
+}
+
+func copyFrom(ctx context.Context, c *config, next func(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error), tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	call := c.start(ctx, OperationCopyFrom, "COPY "+tableName.Sanitize())
+	copyCount, err := next(call.ctx, tableName, columnNames, rowSrc)
+	elapsed := call.end(copyCount, err)
+	if err == nil {
+		c.metrics.observeCopy(call.caller, tableName.Sanitize(), copyCount, elapsed)
+	}
+	return copyCount, err
+}
+
+func exec(ctx context.Context, c *config, next func(context.Context, string, ...interface{}) (pgconn.CommandTag, error), sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	call := c.start(ctx, OperationExec, sql)
+	tag, err := next(call.ctx, sql, arguments...)
+	call.end(tag.RowsAffected(), err)
+	return tag, err
+}
+
+func queryRow(ctx context.Context, c *config, next func(context.Context, string, ...interface{}) pgx.Row, sql string, args ...interface{}) pgx.Row {
+	call := c.start(ctx, OperationQueryRow, sql)
+	return &instrumentedRow{row: next(call.ctx, sql, args...), call: call}
+}
+
+func query(ctx context.Context, c *config, next func(context.Context, string, ...interface{}) (pgx.Rows, error), sql string, args ...interface{}) (pgx.Rows, error) {
+	call := c.start(ctx, OperationQuery, sql)
+	rows, err := next(call.ctx, sql, args...)
+	if err != nil {
+		call.end(0, err)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, call: call}, nil
+}
@@ -0,0 +1,143 @@
+package instrument
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors a DB records into.
+type Metrics struct {
+	Duration       *prometheus.HistogramVec
+	Rows           *prometheus.CounterVec
+	Errors         *prometheus.CounterVec
+	CopyRows       *prometheus.CounterVec
+	CopyRowsPerSec *prometheus.HistogramVec
+}
+
+// NewMetrics creates the collectors and registers them with reg. A nil reg
+// leaves them unregistered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	labels := []string{"caller", "operation"}
+	m := &Metrics{
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "lyle",
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of database calls.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, labels),
+		Rows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "lyle",
+			Subsystem: "db",
+			Name:      "rows_total",
+			Help:      "Rows read, affected or copied by database calls.",
+		}, labels),
+		Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "lyle",
+			Subsystem: "db",
+			Name:      "errors_total",
+			Help:      "Database calls that returned an error.",
+		}, labels),
+		CopyRows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "lyle",
+			Subsystem: "db",
+			Name:      "copy_rows_total",
+			Help:      "Rows written with COPY FROM.",
+		}, []string{"caller", "table"}),
+		CopyRowsPerSec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "lyle",
+			Subsystem: "db",
+			Name:      "copy_rows_per_second",
+			Help:      "Throughput of COPY FROM calls.",
+			Buckets:   prometheus.ExponentialBuckets(100, 4, 8),
+		}, []string{"caller", "table"}),
+	}
+	if reg != nil {
+		reg.MustRegister(m.Duration, m.Rows, m.Errors, m.CopyRows, m.CopyRowsPerSec)
+	}
+	return m
+}
+
+var (
+	defaultMetrics     *Metrics
+	defaultMetricsOnce sync.Once
+)
+
+// DefaultMetrics returns the Metrics registered with
+// prometheus.DefaultRegisterer, creating them on first use.
+func DefaultMetrics() *Metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = NewMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+func (m *Metrics) observe(caller, operation string, elapsed time.Duration, rows int64, err error) {
+	m.Duration.WithLabelValues(caller, operation).Observe(elapsed.Seconds())
+	if rows > 0 {
+		m.Rows.WithLabelValues(caller, operation).Add(float64(rows))
+	}
+	if err != nil {
+		m.Errors.WithLabelValues(caller, operation).Inc()
+	}
+}
+
+func (m *Metrics) observeCopy(caller, table string, rows int64, elapsed time.Duration) {
+	m.CopyRows.WithLabelValues(caller, table).Add(float64(rows))
+	if elapsed > 0 {
+		m.CopyRowsPerSec.WithLabelValues(caller, table).Observe(float64(rows) / elapsed.Seconds())
+	}
+}
+
+const modulePrefix = "github.com/kfukue/lyle-labs-libraries/v2/"
+
+// skippedPackages are passed over when looking for the caller, as they only
+// forward queries on behalf of the data functions.
+var skippedPackages = []string{
+	modulePrefix + "instrument.",
+	modulePrefix + "repository.",
+	modulePrefix + "utils.",
+	"github.com/jackc/pgx/",
+}
+
+// callerName returns the first function outside skippedPackages on the stack,
+// shortened to package.Function, or "unknown".
+func callerName() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !skipped(frame.Function) {
+			return shortName(frame.Function)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+func skipped(function string) bool {
+	for _, p := range skippedPackages {
+		if strings.HasPrefix(function, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortName turns github.com/x/y/pkg.Func into pkg.Func. Generic and method
+// receivers are kept, closures are reported as their enclosing function.
+func shortName(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		function = function[i+1:]
+	}
+	if i := strings.Index(function, ".func"); i >= 0 {
+		function = function[:i]
+	}
+	return function
+}
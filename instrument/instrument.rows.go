@@ -0,0 +1,94 @@
+package instrument
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// instrumentedRows counts the rows read and ends the call when closed, so the
+// recorded duration covers reading the results as well as running the query.
+type instrumentedRows struct {
+	pgx.Rows
+	call   *call
+	count  int64
+	closed bool
+}
+
+func (r *instrumentedRows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+	// pgx closes the rows once Next returns false, usually without a Close
+	// call from pgx.CollectRows and friends.
+	r.finish()
+	return false
+}
+
+func (r *instrumentedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *instrumentedRows) finish() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.call.end(r.count, r.Rows.Err())
+}
+
+// instrumentedRow ends the call when scanned. pgx.ErrNoRows is an expected
+// result rather than a failed query, so it is not counted as an error.
+type instrumentedRow struct {
+	row  pgx.Row
+	call *call
+}
+
+func (r *instrumentedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	switch {
+	case err == nil:
+		r.call.end(1, nil)
+	case errors.Is(err, pgx.ErrNoRows):
+		r.call.end(0, nil)
+	default:
+		r.call.end(0, err)
+	}
+	return err
+}
+
+// instrumentedTx instruments the statements run inside a transaction.
+type instrumentedTx struct {
+	pgx.Tx
+	*config
+}
+
+func (t *instrumentedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	call := t.start(ctx, OperationBegin, "SAVEPOINT")
+	tx, err := t.Tx.Begin(call.ctx)
+	call.end(0, err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTx{Tx: tx, config: t.config}, nil
+}
+
+func (t *instrumentedTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return copyFrom(ctx, t.config, t.Tx.CopyFrom, tableName, columnNames, rowSrc)
+}
+
+func (t *instrumentedTx) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return exec(ctx, t.config, t.Tx.Exec, sql, arguments...)
+}
+
+func (t *instrumentedTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return query(ctx, t.config, t.Tx.Query, sql, args...)
+}
+
+func (t *instrumentedTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return queryRow(ctx, t.config, t.Tx.QueryRow, sql, args...)
+}
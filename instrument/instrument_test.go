@@ -0,0 +1,133 @@
+package instrument_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/instrument"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newDB(t *testing.T, opts ...instrument.Option) (pgxmock.PgxPoolIface, *instrument.DB, *instrument.Metrics) {
+	t.Helper()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := instrument.NewMetrics(prometheus.NewRegistry())
+	return mock, instrument.Wrap(mock, append([]instrument.Option{instrument.WithMetrics(metrics)}, opts...)...), metrics
+}
+
+func TestQuery(t *testing.T) {
+	mock, db, metrics := newDB(t)
+	defer mock.Close()
+	mock.ExpectQuery("^SELECT id FROM widgets$").WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	rows, err := db.Query(context.Background(), "SELECT id FROM widgets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(ids))
+	}
+	if got := testutil.ToFloat64(metrics.Rows.WithLabelValues("instrument_test.TestQuery", instrument.OperationQuery)); got != 3 {
+		t.Errorf("expected 3 rows recorded, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.Duration); got != 1 {
+		t.Errorf("expected 1 duration series, got %d", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecError(t *testing.T) {
+	mock, db, metrics := newDB(t)
+	defer mock.Close()
+	mock.ExpectExec("^DELETE FROM widgets").WithArgs(1).WillReturnError(errors.New("boom"))
+	if _, err := db.Exec(context.Background(), "DELETE FROM widgets WHERE id = $1", 1); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := testutil.ToFloat64(metrics.Errors.WithLabelValues("instrument_test.TestExecError", instrument.OperationExec)); got != 1 {
+		t.Errorf("expected 1 error recorded, got %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestQueryRowNoRows(t *testing.T) {
+	mock, db, metrics := newDB(t)
+	defer mock.Close()
+	mock.ExpectQuery("^SELECT id FROM widgets").WithArgs(1).WillReturnError(pgx.ErrNoRows)
+	var id int
+	err := db.QueryRow(context.Background(), "SELECT id FROM widgets WHERE id = $1", 1).Scan(&id)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+	}
+	if got := testutil.CollectAndCount(metrics.Errors); got != 0 {
+		t.Errorf("expected no errors recorded, got %d", got)
+	}
+	if got := testutil.CollectAndCount(metrics.Duration); got != 1 {
+		t.Errorf("expected 1 duration series, got %d", got)
+	}
+}
+
+func TestTxCopyFrom(t *testing.T) {
+	mock, db, metrics := newDB(t)
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectCopyFrom(pgx.Identifier{"widgets"}, []string{"id"}).WillReturnResult(2)
+	mock.ExpectCommit()
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"widgets"}, []string{"id"}, pgx.CopyFromRows([][]interface{}{{1}, {2}})); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(metrics.CopyRows.WithLabelValues("instrument_test.TestTxCopyFrom", `"widgets"`)); got != 2 {
+		t.Errorf("expected 2 copied rows recorded, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.Rows.WithLabelValues("instrument_test.TestTxCopyFrom", instrument.OperationCopyFrom)); got != 2 {
+		t.Errorf("expected 2 rows recorded, got %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	mock, db, _ := newDB(t, instrument.WithSlowQueryThreshold(10*time.Millisecond))
+	defer mock.Close()
+	mock.ExpectExec("^UPDATE widgets").WillReturnResult(pgxmock.NewResult("UPDATE", 1)).WillDelayFor(20 * time.Millisecond)
+	mock.ExpectExec("^UPDATE widgets").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	var buf bytes.Buffer
+	ctx := logging.NewContext(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+	if _, err := db.Exec(ctx, "UPDATE widgets SET name = 'slow'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(ctx, "UPDATE widgets SET name = 'fast'"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Count(out, "slow query") != 1 || !strings.Contains(out, "caller=instrument_test.TestSlowQueryLog") {
+		t.Errorf("expected one slow query log line, got %q", out)
+	}
+}
@@ -7,52 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                 //1
-	"uuid",               //2
-	"name",               //3
-	"alternate_name",     //4
-	"start_date",         //5
-	"end_date",           //6
-	"description",        //7
-	"status_id",          //8
-	"response_status",    //9
-	"request_url",        //10
-	"request_body",       //11
-	"request_method",     //12
-	"response_data",      //13
-	"response_data_json", //14
-	"job_category_id",    //15
-	"created_by",         //16
-	"created_at",         //17
-	"updated_by",         //18
-	"updated_at",         //19
-}
-var DBColumnsInsertJobs = []string{
-	"uuid",               //1
-	"name",               //2
-	"alternate_name",     //3
-	"start_date",         //4
-	"end_date",           //5
-	"description",        //6
-	"status_id",          //7
-	"response_status",    //8
-	"request_url",        //9
-	"request_body",       //10
-	"request_method",     //11
-	"response_data",      //12
-	"response_data_json", //13
-	"job_category_id",    //14
-	"created_by",         //15
-	"created_at",         //16
-	"updated_by",         //17
-	"updated_at",         //18
-}
+var DBColumns = JobRepository.Columns()
+var DBColumnsInsertJobs = JobRepository.InsertColumns()
 
 var TestData1 = Job{
 	ID:               utils.Ptr[int](1),                                                                                                   //1
@@ -101,31 +64,7 @@ var TestData2 = Job{
 var TestAllData = []Job{TestData1, TestData2}
 
 func AddJobToMockRows(mock pgxmock.PgxPoolIface, dataList []Job) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,               //1
-			data.UUID,             //2
-			data.Name,             //3
-			data.AlternateName,    //4
-			data.StartDate,        //5
-			data.EndDate,          //6
-			data.Description,      //7
-			data.StatusID,         //8
-			data.ResponseStatus,   //9
-			data.RequestUrl,       //10
-			data.RequestBody,      //11
-			data.RequestMethod,    //12
-			data.ResponseData,     //13
-			data.ResponseDataJson, //14
-			data.JobCategoryID,    //15
-			data.CreatedBy,        //16
-			data.CreatedAt,        //17
-			data.UpdatedBy,        //18
-			data.UpdatedAt,        //19
-		)
-	}
-	return rows
+	return JobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetJob(t *testing.T) {
@@ -161,8 +100,8 @@ func TestGetJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM jobs").WithArgs(jobID).WillReturnRows(noRows)
 	foundJob, err := GetJob(mock, &jobID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundJob != nil {
 		t.Errorf("Expected Job From Method GetJob: to be empty but got this: %v", foundJob)
@@ -279,8 +218,8 @@ func TestGetJobList(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddJobToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM jobs").WillReturnRows(mockRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM jobs").WithArgs(ids).WillReturnRows(mockRows)
 	foundJobList, err := GetJobList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetJobList", err)
@@ -301,8 +240,8 @@ func TestGetJobListForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM jobs").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundJobList, err := GetJobList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetJobList", err)
@@ -322,8 +261,8 @@ func TestGetJobListForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM jobs").WillReturnRows(differentModelRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM jobs").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundJobList, err := GetJobList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetJobList", err)
@@ -477,21 +416,21 @@ func TestUpdateJob(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE jobs").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.Description,                            //5
-		targetData.StatusID,                               //6
-		targetData.ResponseStatus,                         //7
-		targetData.RequestUrl,                             //8
-		targetData.RequestBody,                            //9
-		targetData.RequestMethod,                          //10
-		targetData.ResponseData,                           //11
-		targetData.ResponseDataJson,                       //12
-		targetData.JobCategoryID,                          //13
-		targetData.UpdatedBy,                              //14
-		targetData.ID,                                     //15
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.JobCategoryID,    //13
+		targetData.UpdatedBy,        //14
+		targetData.ID,               //15
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateJob(mock, &targetData)
@@ -547,21 +486,21 @@ func TestUpdateJobOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE jobs").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.Description,                            //5
-		targetData.StatusID,                               //6
-		targetData.ResponseStatus,                         //7
-		targetData.RequestUrl,                             //8
-		targetData.RequestBody,                            //9
-		targetData.RequestMethod,                          //10
-		targetData.ResponseData,                           //11
-		targetData.ResponseDataJson,                       //12
-		targetData.JobCategoryID,                          //13
-		targetData.UpdatedBy,                              //14
-		targetData.ID,                                     //15
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.JobCategoryID,    //13
+		targetData.UpdatedBy,        //14
+		targetData.ID,               //15
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -584,21 +523,21 @@ func TestInsertJob(t *testing.T) {
 	uuid := "01ef85e8-2c26-441e-8c7f-71d79518ad72"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO jobs").WithArgs(
-		targetData.UUID,          //1
-		targetData.Name,          //2
-		targetData.AlternateName, //3
-		targetData.StartDate.Format(utils.LayoutPostgres), //4
-		targetData.EndDate.Format(utils.LayoutPostgres),   //5
-		targetData.Description,                            //6
-		targetData.StatusID,                               //7
-		targetData.ResponseStatus,                         //8
-		targetData.RequestUrl,                             //9
-		targetData.RequestBody,                            //10
-		targetData.RequestMethod,                          //11
-		targetData.ResponseData,                           //12
-		targetData.ResponseDataJson,                       //13
-		targetData.JobCategoryID,                          //14
-		targetData.CreatedBy,                              //15
+		targetData.UUID,             //1
+		targetData.Name,             //2
+		targetData.AlternateName,    //3
+		targetData.StartDate,        //4
+		targetData.EndDate,          //5
+		targetData.Description,      //6
+		targetData.StatusID,         //7
+		targetData.ResponseStatus,   //8
+		targetData.RequestUrl,       //9
+		targetData.RequestBody,      //10
+		targetData.RequestMethod,    //11
+		targetData.ResponseData,     //12
+		targetData.ResponseDataJson, //13
+		targetData.JobCategoryID,    //14
+		targetData.CreatedBy,        //15
 	).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(1, uuid))
 	mock.ExpectCommit()
 	jobID, newUUID, err := InsertJob(mock, &targetData)
@@ -644,21 +583,21 @@ func TestInsertJobOnFailure(t *testing.T) {
 	targetData.StatusID = nil
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO jobs").WithArgs(
-		targetData.UUID,          //1
-		targetData.Name,          //2
-		targetData.AlternateName, //3
-		targetData.StartDate.Format(utils.LayoutPostgres), //4
-		targetData.EndDate.Format(utils.LayoutPostgres),   //5
-		targetData.Description,                            //6
-		targetData.StatusID,                               //7
-		targetData.ResponseStatus,                         //8
-		targetData.RequestUrl,                             //9
-		targetData.RequestBody,                            //10
-		targetData.RequestMethod,                          //11
-		targetData.ResponseData,                           //12
-		targetData.ResponseDataJson,                       //13
-		targetData.JobCategoryID,                          //14
-		targetData.CreatedBy,                              //15
+		targetData.UUID,             //1
+		targetData.Name,             //2
+		targetData.AlternateName,    //3
+		targetData.StartDate,        //4
+		targetData.EndDate,          //5
+		targetData.Description,      //6
+		targetData.StatusID,         //7
+		targetData.ResponseStatus,   //8
+		targetData.RequestUrl,       //9
+		targetData.RequestBody,      //10
+		targetData.RequestMethod,    //11
+		targetData.ResponseData,     //12
+		targetData.ResponseDataJson, //13
+		targetData.JobCategoryID,    //14
+		targetData.CreatedBy,        //15
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	jobID, newUUID, err := InsertJob(mock, &targetData)
@@ -687,21 +626,21 @@ func TestInsertJobOnFailureOnCommit(t *testing.T) {
 	uuid := "01ef85e8-2c26-441e-8c7f-71d79518ad72"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO jobs").WithArgs(
-		targetData.UUID,          //1
-		targetData.Name,          //2
-		targetData.AlternateName, //3
-		targetData.StartDate.Format(utils.LayoutPostgres), //4
-		targetData.EndDate.Format(utils.LayoutPostgres),   //5
-		targetData.Description,                            //6
-		targetData.StatusID,                               //7
-		targetData.ResponseStatus,                         //8
-		targetData.RequestUrl,                             //9
-		targetData.RequestBody,                            //10
-		targetData.RequestMethod,                          //11
-		targetData.ResponseData,                           //12
-		targetData.ResponseDataJson,                       //13
-		targetData.JobCategoryID,                          //14
-		targetData.CreatedBy,                              //15
+		targetData.UUID,             //1
+		targetData.Name,             //2
+		targetData.AlternateName,    //3
+		targetData.StartDate,        //4
+		targetData.EndDate,          //5
+		targetData.Description,      //6
+		targetData.StatusID,         //7
+		targetData.ResponseStatus,   //8
+		targetData.RequestUrl,       //9
+		targetData.RequestBody,      //10
+		targetData.RequestMethod,    //11
+		targetData.ResponseData,     //12
+		targetData.ResponseDataJson, //13
+		targetData.JobCategoryID,    //14
+		targetData.CreatedBy,        //15
 	).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(1, uuid))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -766,8 +705,8 @@ func TestGetJobListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"status_id = 1", "job_category_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("status_id", "1"), filter.Equal("job_category_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundJobList, err := GetJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetJobListByPagination", err)
@@ -792,8 +731,8 @@ func TestGetJobListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"status_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("status_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM jobs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundJobList, err := GetJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetJobListByPagination", err)
@@ -816,9 +755,9 @@ func TestGetJobListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"status_id = 1"}
+	filters := filter.All(filter.Equal("status_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM jobs").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundJobList, err := GetJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetJobListByPagination", err)
@@ -2,128 +2,73 @@ package liquiditypool
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
-	"time"
 
-	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 )
 
-func GetLiquidityPool(dbConnPgx utils.PgxIface, liquidityPoolID *int) (*LiquidityPool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// LiquidityPoolRepository implements the standard queries on the
+// liquidity_pools table. Only the query that joins the pool tokens is hand
+// written.
+var LiquidityPoolRepository = repository.New[LiquidityPool]("liquidity_pools", repository.SoftDelete())
+
+func GetLiquidityPool(dbConnPgx utils.PgxIface, liquidityPoolID *int, opts ...repository.ReadOption) (*LiquidityPool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		uuid,
-		name,
-		alternate_name,
-		pair_address,
-		chain_id,
-		exchange_id,
-		liquidity_pool_type_id,
-		token0_id,
-		token1_id,
-		url,
-		start_block,
-		latest_block_synced,
-		created_txn_hash,
-		IsActive,
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		base_asset_id,
-		quote_asset_id,
-		quote_asset_chainlink_address_usd
+	return GetLiquidityPoolCtx(ctx, dbConnPgx, liquidityPoolID, opts...)
+}
 
-	FROM liquidity_pools 
-	WHERE id = $1`, *liquidityPoolID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	liquidityPool, err := pgx.CollectOneRow(row, pgx.RowToStructByName[LiquidityPool])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &liquidityPool, nil
+func GetLiquidityPoolCtx(ctx context.Context, dbConnPgx utils.PgxIface, liquidityPoolID *int, opts ...repository.ReadOption) (*LiquidityPool, error) {
+	return LiquidityPoolRepository.Get(ctx, dbConnPgx, *liquidityPoolID, opts...)
 }
 
+// RemoveLiquidityPool marks the liquidityPool deleted, leaving the rows that reference it
+// intact; RestoreLiquidityPool undoes it.
 func RemoveLiquidityPool(dbConnPgx utils.PgxIface, liquidityPoolID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveJob DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM liquidity_pools WHERE id = $1`
+	return RemoveLiquidityPoolCtx(ctx, dbConnPgx, liquidityPoolID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *liquidityPoolID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveLiquidityPoolCtx(ctx context.Context, dbConnPgx utils.PgxIface, liquidityPoolID *int) error {
+	return LiquidityPoolRepository.Remove(ctx, dbConnPgx, *liquidityPoolID)
 }
 
-func GetLiquidityPoolList(dbConnPgx utils.PgxIface, ids []int) ([]LiquidityPool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func RestoreLiquidityPool(dbConnPgx utils.PgxIface, liquidityPoolID *int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT 
-		id,
-		uuid,
-		name,
-		alternate_name,
-		pair_address,
-		chain_id,
-		exchange_id,
-		liquidity_pool_type_id,
-		token0_id,
-		token1_id,
-		url,
-		start_block,
-		latest_block_synced,
-		created_txn_hash,
-		IsActive,
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		base_asset_id,
-		quote_asset_id,
-		quote_asset_chainlink_address_usd
-	FROM liquidity_pools`
-	if len(ids) > 0 {
-		strIds := utils.SplitToString(ids, ",")
-		additionalQuery := fmt.Sprintf(` WHERE id IN (%s)`, strIds)
-		sql += additionalQuery
-	}
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return RestoreLiquidityPoolCtx(ctx, dbConnPgx, liquidityPoolID)
+}
 
-	liquidityPools, err := pgx.CollectRows(results, pgx.RowToStructByName[LiquidityPool])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return liquidityPools, nil
+func RestoreLiquidityPoolCtx(ctx context.Context, dbConnPgx utils.PgxIface, liquidityPoolID *int) error {
+	return LiquidityPoolRepository.Restore(ctx, dbConnPgx, *liquidityPoolID)
+}
+
+func GetLiquidityPoolList(dbConnPgx utils.PgxIface, ids []int, opts ...repository.ReadOption) ([]LiquidityPool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetLiquidityPoolListCtx(ctx, dbConnPgx, ids, opts...)
+}
+
+func GetLiquidityPoolListCtx(ctx context.Context, dbConnPgx utils.PgxIface, ids []int, opts ...repository.ReadOption) ([]LiquidityPool, error) {
+	return LiquidityPoolRepository.GetList(ctx, dbConnPgx, ids, opts...)
 }
 
 func GetLiquidityPoolListByBaseAssetID(dbConnPgx utils.PgxIface, baseAssetID *int) ([]LiquidityPoolWithTokens, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetLiquidityPoolListByBaseAssetIDCtx(ctx, dbConnPgx, baseAssetID)
+}
+
+func GetLiquidityPoolListByBaseAssetIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, baseAssetID *int) ([]LiquidityPoolWithTokens, error) {
 	sql := `SELECT 
 	lp.id,
 	lp.uuid,
@@ -208,11 +153,12 @@ func GetLiquidityPoolListByBaseAssetID(dbConnPgx utils.PgxIface, baseAssetID *in
 	LEFT JOIN assets token0 ON lp.token0_id = token0.id
 	LEFT JOIN assets token1 ON lp.token1_id = token1.id
 	WHERE lp.base_asset_id = $1
+	AND lp.deleted_at IS NULL
 	`
 	results, err := dbConnPgx.Query(ctx, sql, *baseAssetID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetLiquidityPoolListByBaseAssetID", err, logging.Entity("liquidity_pools"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	liquidityPoolsWithTokens := make([]LiquidityPoolWithTokens, 0)
@@ -305,348 +251,75 @@ func GetLiquidityPoolListByBaseAssetID(dbConnPgx utils.PgxIface, baseAssetID *in
 }
 
 func GetLiquidityPoolsByUUIDs(dbConnPgx utils.PgxIface, UUIDList []string) ([]LiquidityPool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-		id,
-		uuid,
-		name,
-		alternate_name,
-		pair_address,
-		chain_id,
-		exchange_id,
-		liquidity_pool_type_id,
-		token0_id,
-		token1_id,
-		url,
-		start_block,
-		latest_block_synced,
-		created_txn_hash,
-		IsActive,
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		base_asset_id,
-		quote_asset_id,
-		quote_asset_chainlink_address_usd
-	FROM liquidity_pools
-	WHERE text(uuid) = ANY($1)
-	`, pq.Array(UUIDList))
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetLiquidityPoolsByUUIDsCtx(ctx, dbConnPgx, UUIDList)
+}
 
-	liquidityPools, err := pgx.CollectRows(results, pgx.RowToStructByName[LiquidityPool])
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	return liquidityPools, nil
+func GetLiquidityPoolsByUUIDsCtx(ctx context.Context, dbConnPgx utils.PgxIface, UUIDList []string) ([]LiquidityPool, error) {
+	return LiquidityPoolRepository.Select(ctx, dbConnPgx, `WHERE text(uuid) = ANY($1)
+	AND deleted_at IS NULL`, pq.Array(UUIDList))
 }
 func UpdateLiquidityPool(dbConnPgx utils.PgxIface, liquidityPool *LiquidityPool) error {
-	// if the liquidityPool id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if liquidityPool.ID == nil || *liquidityPool.ID == 0 {
-		return errors.New("liquidityPool has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateLiquidityPool DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `UPDATE liquidity_pools SET 
-		name=$1,
-		alternate_name=$2,
-		pair_address=$3,
-		chain_id=$4,
-		exchange_id=$5,
-		liquidity_pool_type_id=$6,
-		token0_id=$7,
-		token1_id=$8,
-		url=$9,
-		start_block=$10,
-		latest_block_synced=$11,
-		created_txn_hash=$12,
-		IsActive=$13,
-		description=$14,
-		updated_by=$15, 
-		updated_at=current_timestamp at time zone 'UTC',
-		base_asset_id=$16,
-		quote_asset_id=$17,
-		quote_asset_chainlink_address_usd=$18
-		WHERE id=$19`
+	return UpdateLiquidityPoolCtx(ctx, dbConnPgx, liquidityPool)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		liquidityPool.Name,                       //1
-		liquidityPool.AlternateName,              //2
-		liquidityPool.PairAddress,                //3
-		liquidityPool.ChainID,                    //4
-		liquidityPool.ExchangeID,                 //5
-		liquidityPool.LiquidityPoolTypeID,        //6
-		liquidityPool.Token0ID,                   //7
-		liquidityPool.Token1ID,                   //8
-		liquidityPool.Url,                        //9
-		liquidityPool.StartBlock,                 //10
-		liquidityPool.LatestBlockSynced,          //11
-		liquidityPool.CreatedTxnHash,             //12
-		liquidityPool.IsActive,                   //13
-		liquidityPool.Description,                //14
-		liquidityPool.UpdatedBy,                  //15
-		liquidityPool.BaseAssetID,                //16
-		liquidityPool.QuoteAssetID,               //17
-		liquidityPool.QuoteAssetChainlinkAddress, //18
-		liquidityPool.ID,                         //19
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func UpdateLiquidityPoolCtx(ctx context.Context, dbConnPgx utils.PgxIface, liquidityPool *LiquidityPool) error {
+	return LiquidityPoolRepository.Update(ctx, dbConnPgx, liquidityPool)
+}
+
+// UpdateLiquidityPoolIfUnchanged updates liquidityPool only if the row still has the UpdatedAt
+// liquidityPool was read with. Otherwise it returns a *dberrors.ConflictError[LiquidityPool]
+// holding the current row.
+func UpdateLiquidityPoolIfUnchanged(dbConnPgx utils.PgxIface, liquidityPool *LiquidityPool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return UpdateLiquidityPoolIfUnchangedCtx(ctx, dbConnPgx, liquidityPool)
+}
+
+func UpdateLiquidityPoolIfUnchangedCtx(ctx context.Context, dbConnPgx utils.PgxIface, liquidityPool *LiquidityPool) error {
+	return LiquidityPoolRepository.UpdateIfUnchanged(ctx, dbConnPgx, liquidityPool)
 }
 
 func InsertLiquidityPool(dbConnPgx utils.PgxIface, liquidityPool *LiquidityPool) (int, string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertLiquidityPool DbConn.Begin   %s", err.Error())
-		return -1, "", err
-	}
-	var insertID int
-	var insertUUID string
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO liquidity_pools 
-	(
-		uuid,
-		name,
-		alternate_name,
-		pair_address,
-		chain_id,
-		exchange_id,
-		liquidity_pool_type_id,
-		token0_id,
-		token1_id,
-		url,
-		start_block,
-		latest_block_synced,
-		created_txn_hash,
-		IsActive,
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		base_asset_id,
-		quote_asset_id,
-		quote_asset_chainlink_address_usd
-		) VALUES (
-			uuid_generate_v4(),
-			$1,
-			$2, 
-			$3, 
-			$4, 
-			$5, 
-			$6,
-			$7,
-			$8,
-			$9,
-			$10,
-			$11,
-			$12,
-			$13,
-			$14,
-			$15,
-			current_timestamp at time zone 'UTC',
-			$15,
-			current_timestamp at time zone 'UTC',
-			$16,
-			$17,
-			$18
-		)
-		RETURNING id, uuid`,
-		liquidityPool.Name,                       //1
-		liquidityPool.AlternateName,              //2
-		liquidityPool.PairAddress,                //3
-		liquidityPool.ChainID,                    //4
-		liquidityPool.ExchangeID,                 //5
-		liquidityPool.LiquidityPoolTypeID,        //6
-		liquidityPool.Token0ID,                   //7
-		liquidityPool.Token1ID,                   //8
-		liquidityPool.Url,                        //9
-		liquidityPool.StartBlock,                 //10
-		liquidityPool.LatestBlockSynced,          //11
-		liquidityPool.CreatedTxnHash,             //12
-		liquidityPool.IsActive,                   //13
-		liquidityPool.Description,                //14
-		liquidityPool.CreatedBy,                  //15
-		liquidityPool.BaseAssetID,                //16
-		liquidityPool.QuoteAssetID,               //17
-		liquidityPool.QuoteAssetChainlinkAddress, //18
-	).Scan(&insertID, &insertUUID)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, "", err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, "", err
-	}
-	return int(insertID), insertUUID, nil
+	return InsertLiquidityPoolCtx(ctx, dbConnPgx, liquidityPool)
+}
+
+func InsertLiquidityPoolCtx(ctx context.Context, dbConnPgx utils.PgxIface, liquidityPool *LiquidityPool) (int, string, error) {
+	return LiquidityPoolRepository.InsertReturningUUID(ctx, dbConnPgx, liquidityPool)
 }
 func InsertLiquidityPools(dbConnPgx utils.PgxIface, liquidityPools []LiquidityPool) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range liquidityPools {
-		liquidityPool := liquidityPools[i]
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(liquidityPool.UUID)
-		row := []interface{}{
-			uuidString,                               //1
-			liquidityPool.Name,                       //2
-			liquidityPool.AlternateName,              //3
-			liquidityPool.PairAddress,                //4
-			liquidityPool.ChainID,                    //5
-			liquidityPool.ExchangeID,                 //6
-			liquidityPool.LiquidityPoolTypeID,        //7
-			liquidityPool.Token0ID,                   //8
-			liquidityPool.Token1ID,                   //9
-			liquidityPool.Url,                        //10
-			liquidityPool.StartBlock,                 //11
-			liquidityPool.LatestBlockSynced,          //12
-			liquidityPool.CreatedTxnHash,             //13
-			liquidityPool.IsActive,                   //14
-			liquidityPool.Description,                //15
-			liquidityPool.CreatedBy,                  //16
-			&now,                                     //17
-			liquidityPool.CreatedBy,                  //18
-			&now,                                     //19
-			liquidityPool.BaseAssetID,                //20
-			liquidityPool.QuoteAssetID,               //21
-			liquidityPool.QuoteAssetChainlinkAddress, //22
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"liquidity_pools"},
-		[]string{
-			"uuid",                              //1
-			"name",                              //2
-			"alternate_name",                    //3
-			"pair_address",                      //4
-			"chain_id",                          //5
-			"exchange_id",                       //6
-			"liquidity_pool_type_id",            //7
-			"token0_id",                         //8
-			"token1_id",                         //9
-			"url",                               //10
-			"start_block",                       //11
-			"latest_block_synced",               //12
-			"created_txn_hash",                  //13
-			"IsActive",                          //14
-			"description",                       //15
-			"created_by",                        //16
-			"created_at",                        //17
-			"updated_by",                        //18
-			"updated_at",                        //19
-			"base_asset_id",                     //20
-			"quote_asset_id",                    //21
-			"quote_asset_chainlink_address_usd", //22
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertLiquidityPools: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
-	return nil
+	return InsertLiquidityPoolsCtx(ctx, dbConnPgx, liquidityPools)
+}
+
+func InsertLiquidityPoolsCtx(ctx context.Context, dbConnPgx utils.PgxIface, liquidityPools []LiquidityPool) error {
+	_, err := LiquidityPoolRepository.InsertMany(ctx, dbConnPgx, liquidityPools)
+	return dberrors.Wrap(err)
 }
 
 // for refinedev
-func GetLiquidityPoolListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]LiquidityPool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetLiquidityPoolListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group, opts ...repository.ReadOption) ([]LiquidityPool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT 
-		id,
-		uuid,
-		name,
-		alternate_name,
-		pair_address,
-		chain_id,
-		exchange_id,
-		liquidity_pool_type_id,
-		token0_id,
-		token1_id,
-		url,
-		start_block,
-		latest_block_synced,
-		created_txn_hash,
-		IsActive,
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-		base_asset_id,
-		quote_asset_id,
-		quote_asset_chainlink_address_usd
-	FROM liquidity_pools
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetLiquidityPoolListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters, opts...)
+}
 
-	liquidityPools, err := pgx.CollectRows(results, pgx.RowToStructByName[LiquidityPool])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return liquidityPools, nil
+func GetLiquidityPoolListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group, opts ...repository.ReadOption) ([]LiquidityPool, error) {
+	return LiquidityPoolRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters, opts...)
 }
 
-func GetTotalLiquidityPoolCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetTotalLiquidityPoolCount(dbConnPgx utils.PgxIface, opts ...repository.ReadOption) (*int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalLiquidityPoolCountCtx(ctx, dbConnPgx, opts...)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT 
-	COUNT(*)
-	FROM liquidity_pools
-	`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalLiquidityPoolCountCtx(ctx context.Context, dbConnPgx utils.PgxIface, opts ...repository.ReadOption) (*int, error) {
+	return LiquidityPoolRepository.Count(ctx, dbConnPgx, opts...)
 }
@@ -8,60 +8,15 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
 	"github.com/kfukue/lyle-labs-libraries/v2/asset"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                                //1
-	"uuid",                              //2
-	"name",                              //3
-	"alternate_name",                    //4
-	"pair_address",                      //5
-	"chain_id",                          //6
-	"exchange_id",                       //7
-	"liquidity_pool_type_id",            //8
-	"token0_id",                         //9
-	"token1_id",                         //10
-	"url",                               //11
-	"start_block",                       //12
-	"latest_block_synced",               //13
-	"created_txn_hash",                  //14
-	"IsActive",                          //15
-	"description",                       //16
-	"created_by",                        //17
-	"created_at",                        //18
-	"updated_by",                        //19
-	"updated_at",                        //20
-	"base_asset_id",                     //21
-	"quote_asset_id",                    //22
-	"quote_asset_chainlink_address_usd", //23
-}
-var DBColumnsInsertLiquidityPools = []string{
-	"uuid",                              //1
-	"name",                              //2
-	"alternate_name",                    //3
-	"pair_address",                      //4
-	"chain_id",                          //5
-	"exchange_id",                       //6
-	"liquidity_pool_type_id",            //7
-	"token0_id",                         //8
-	"token1_id",                         //9
-	"url",                               //10
-	"start_block",                       //11
-	"latest_block_synced",               //12
-	"created_txn_hash",                  //13
-	"IsActive",                          //14
-	"description",                       //15
-	"created_by",                        //16
-	"created_at",                        //17
-	"updated_by",                        //18
-	"updated_at",                        //19
-	"base_asset_id",                     //20
-	"quote_asset_id",                    //21
-	"quote_asset_chainlink_address_usd", //22
-}
+var DBColumns = LiquidityPoolRepository.Columns()
+var DBColumnsInsertLiquidityPools = LiquidityPoolRepository.InsertColumns()
 
 var TestData1 = LiquidityPool{
 	ID:                         utils.Ptr[int](1),                                                    //1
@@ -117,35 +72,7 @@ var TestData2 = LiquidityPool{
 var TestAllData = []LiquidityPool{TestData1, TestData2}
 
 func AddLiquidityPoolToMockRows(mock pgxmock.PgxPoolIface, dataList []LiquidityPool) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                         //1
-			data.UUID,                       //2
-			data.Name,                       //3
-			data.AlternateName,              //4
-			data.PairAddress,                //5
-			data.ChainID,                    //6
-			data.ExchangeID,                 //7
-			data.LiquidityPoolTypeID,        //8
-			data.Token0ID,                   //9
-			data.Token1ID,                   //10
-			data.Url,                        //11
-			data.StartBlock,                 //12
-			data.LatestBlockSynced,          //13
-			data.CreatedTxnHash,             //14
-			data.IsActive,                   //15
-			data.Description,                //16
-			data.CreatedBy,                  //17
-			data.CreatedAt,                  //18
-			data.UpdatedBy,                  //19
-			data.UpdatedAt,                  //20
-			data.BaseAssetID,                //21
-			data.QuoteAssetID,               //22
-			data.QuoteAssetChainlinkAddress, //23
-		)
-	}
-	return rows
+	return LiquidityPoolRepository.AddToMockRows(mock, dataList)
 }
 
 var DBColumnsLiquidityPoolWithTokens = []string{
@@ -347,8 +274,8 @@ func TestGetLiquidityPoolForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WithArgs(liquidityPoolID).WillReturnRows(noRows)
 	foundLiquidityPool, err := GetLiquidityPool(mock, &liquidityPoolID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetLiquidityPool", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundLiquidityPool != nil {
 		t.Errorf("Expected LiquidityPool From Method GetLiquidityPool: to be empty but got this: %v", foundLiquidityPool)
@@ -409,7 +336,7 @@ func TestRemoveLiquidityPool(t *testing.T) {
 	targetData := TestData1
 	liquidityPoolID := targetData.ID
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM liquidity_pools").WithArgs(*liquidityPoolID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("^UPDATE liquidity_pools SET deleted_at").WithArgs(*liquidityPoolID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = RemoveLiquidityPool(mock, liquidityPoolID)
 	if err != nil {
@@ -445,7 +372,7 @@ func TestRemoveLiquidityPoolOnFailure(t *testing.T) {
 	defer mock.Close()
 	liquidityPoolID := -1
 	mock.ExpectBegin()
-	mock.ExpectExec("^DELETE FROM liquidity_pools").WithArgs(liquidityPoolID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
+	mock.ExpectExec("^UPDATE liquidity_pools SET deleted_at").WithArgs(liquidityPoolID).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 	mock.ExpectRollback()
 	err = RemoveLiquidityPool(mock, &liquidityPoolID)
 	if err == nil {
@@ -456,6 +383,25 @@ func TestRemoveLiquidityPoolOnFailure(t *testing.T) {
 	}
 }
 
+func TestRestoreLiquidityPool(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE liquidity_pools SET deleted_at = NULL").WithArgs(*targetData.ID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	err = RestoreLiquidityPool(mock, targetData.ID)
+	if err != nil {
+		t.Fatalf("an error '%s' in RestoreLiquidityPool", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetLiquidityPoolList(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -465,8 +411,8 @@ func TestGetLiquidityPoolList(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddLiquidityPoolToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WillReturnRows(mockRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WithArgs(ids).WillReturnRows(mockRows)
 	foundLiquidityPoolList, err := GetLiquidityPoolList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetLiquidityPoolList", err)
@@ -952,8 +898,8 @@ func TestGetLiquidityPoolListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1", "exchange_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("chain_id", "1"), filter.Equal("exchange_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundLiquidityPoolList, err := GetLiquidityPoolListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetLiquidityPoolListByPagination", err)
@@ -978,8 +924,8 @@ func TestGetLiquidityPoolListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("chain_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundLiquidityPoolList, err := GetLiquidityPoolListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetLiquidityPoolListByPagination", err)
@@ -1002,9 +948,9 @@ func TestGetLiquidityPoolListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1"}
+	filters := filter.All(filter.Equal("chain_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM liquidity_pools").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundLiquidityPoolList, err := GetLiquidityPoolListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetLiquidityPoolListByPagination", err)
@@ -0,0 +1,160 @@
+// Package logging holds the *slog.Logger used by the library.
+//
+// Nothing is written through the standard log package. Applications install
+// their logger once with SetLogger, or per request with NewContext, and every
+// package logs through FromContext. Errors that a function also returns are
+// left to the caller and only logged after SetLogReturnedErrors(true).
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Attribute keys shared by every package, so the same field always has the
+// same name in the log pipeline.
+const (
+	KeyEntity      = "entity"
+	KeyID          = "id"
+	KeyJobID       = "job_id"
+	KeyBlockNumber = "block_number"
+	KeyTxnHash     = "txn_hash"
+	KeyDuration    = "duration"
+	KeyRows        = "rows"
+	KeyError       = "error"
+)
+
+var (
+	logger            atomic.Pointer[slog.Logger]
+	logReturnedErrors atomic.Bool
+)
+
+// Discard is a logger that drops every record.
+var Discard = slog.New(discardHandler{})
+
+// SetLogger sets the package-level logger used when the context carries none.
+// A nil logger restores slog.Default().
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// Logger returns the package-level logger.
+func Logger() *slog.Logger {
+	if l := logger.Load(); l != nil {
+		return l
+	}
+	return slog.Default()
+}
+
+// SetLogReturnedErrors controls whether errors that are returned to the
+// caller are logged as well. It is off by default.
+func SetLogReturnedErrors(on bool) {
+	logReturnedErrors.Store(on)
+}
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying l, e.g. a logger with request
+// attributes already attached.
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the package-level logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok && l != nil {
+			return l
+		}
+	}
+	return Logger()
+}
+
+// Debug logs at debug level through the logger of ctx.
+func Debug(ctx context.Context, msg string, args ...any) {
+	FromContext(ctx).DebugContext(ctx, msg, args...)
+}
+
+// Info logs at info level through the logger of ctx.
+func Info(ctx context.Context, msg string, args ...any) {
+	FromContext(ctx).InfoContext(ctx, msg, args...)
+}
+
+// Error logs err at error level through the logger of ctx.
+func Error(ctx context.Context, msg string, err error, args ...any) {
+	FromContext(ctx).ErrorContext(ctx, msg, append(args, Err(err))...)
+}
+
+// ReturnedError logs err, which the calling function also returns, when
+// SetLogReturnedErrors(true) has been called and does nothing otherwise.
+func ReturnedError(ctx context.Context, msg string, err error, args ...any) {
+	if err == nil || !logReturnedErrors.Load() {
+		return
+	}
+	Error(ctx, msg, err, args...)
+}
+
+// Entity names the table or type a record is about.
+func Entity(name string) slog.Attr {
+	return slog.String(KeyEntity, name)
+}
+
+// ID is the id or uuid of the row a record is about. Pointers are
+// dereferenced; a nil pointer is logged as null.
+func ID(id any) slog.Attr {
+	return slog.Any(KeyID, deref(id))
+}
+
+// JobID is the id of the job a record is about.
+func JobID(id any) slog.Attr {
+	return slog.Any(KeyJobID, deref(id))
+}
+
+// BlockNumber is the block a record is about.
+func BlockNumber(number any) slog.Attr {
+	return slog.Any(KeyBlockNumber, deref(number))
+}
+
+// TxnHash is the hash of the transaction a record is about.
+func TxnHash(hash string) slog.Attr {
+	return slog.String(KeyTxnHash, hash)
+}
+
+// Duration is how long an operation took or still has to go.
+func Duration(d time.Duration) slog.Attr {
+	return slog.Duration(KeyDuration, d)
+}
+
+// Rows is a number of rows read or written.
+func Rows(n int64) slog.Attr {
+	return slog.Int64(KeyRows, n)
+}
+
+// Err is the error of a failed operation.
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+func deref(v any) any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newBufferLogger() (*slog.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), buf
+}
+
+func TestSetLogger(t *testing.T) {
+	l, buf := newBufferLogger()
+	SetLogger(l)
+	defer SetLogger(nil)
+	Info(context.Background(), "InsertAssets: copied rows", Entity("assets"), Rows(3))
+	got := buf.String()
+	if !strings.Contains(got, "entity=assets") || !strings.Contains(got, "rows=3") {
+		t.Errorf("unexpected log output %q", got)
+	}
+	SetLogger(nil)
+	if Logger() != slog.Default() {
+		t.Errorf("expected SetLogger(nil) to restore slog.Default()")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	packageLogger, packageBuf := newBufferLogger()
+	SetLogger(packageLogger)
+	defer SetLogger(nil)
+	ctxLogger, ctxBuf := newBufferLogger()
+	ctx := NewContext(context.Background(), ctxLogger)
+	Debug(ctx, "GetAsset", ID(1))
+	if packageBuf.Len() != 0 {
+		t.Errorf("expected the package logger to be unused, got %q", packageBuf.String())
+	}
+	if !strings.Contains(ctxBuf.String(), "id=1") {
+		t.Errorf("unexpected log output %q", ctxBuf.String())
+	}
+	if FromContext(context.Background()) != packageLogger {
+		t.Errorf("expected the package logger without a context logger")
+	}
+}
+
+func TestReturnedError(t *testing.T) {
+	l, buf := newBufferLogger()
+	ctx := NewContext(context.Background(), l)
+	err := errors.New("Random SQL Error")
+	ReturnedError(ctx, "GetAsset", err, Entity("assets"))
+	if buf.Len() != 0 {
+		t.Fatalf("expected returned errors not to be logged by default, got %q", buf.String())
+	}
+	SetLogReturnedErrors(true)
+	defer SetLogReturnedErrors(false)
+	ReturnedError(ctx, "GetAsset", err, Entity("assets"))
+	got := buf.String()
+	if !strings.Contains(got, "level=ERROR") || !strings.Contains(got, `error="Random SQL Error"`) {
+		t.Errorf("unexpected log output %q", got)
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	id := 7
+	var missing *int
+	tests := map[string]struct {
+		attr slog.Attr
+		want string
+	}{
+		"id pointer":   {ID(&id), "id=7"},
+		"nil id":       {ID(missing), "id=<nil>"},
+		"job id":       {JobID(&id), "job_id=7"},
+		"block number": {BlockNumber(uint64(19000000)), "block_number=19000000"},
+		"txn hash":     {TxnHash("0xabc"), "txn_hash=0xabc"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.attr.String(); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	if Discard.Enabled(context.Background(), slog.LevelError) {
+		t.Errorf("expected Discard to drop every record")
+	}
+}
@@ -7,68 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                  //1
-	"uuid",                //2
-	"name",                //3
-	"alternate_name",      //4
-	"start_date",          //5
-	"end_date",            //6
-	"asset_id",            //7
-	"open_usd",            //8
-	"close_usd",           //9
-	"high_usd",            //10
-	"low_usd",             //11
-	"price_usd",           //12
-	"volume_usd",          //13
-	"market_cap_usd",      //14
-	"ticker",              //15
-	"description",         //16
-	"interval_id",         //17
-	"market_data_type_id", //18
-	"source_id",           //19
-	"total_supply",        //20
-	"max_supply",          //21
-	"circulating_supply",  //22
-	"sparkline_7d",        //23
-	"created_by",          //24
-	"created_at",          //25
-	"updated_by",          //26
-	"updated_at",          //27
-}
-var DBColumnsInsertMarketDataList = []string{
-	"name",                //1
-	"uuid",                //2
-	"alternate_name",      //3
-	"start_date",          //4
-	"end_date",            //5
-	"asset_id",            //6
-	"open_usd",            //7
-	"close_usd",           //8
-	"high_usd",            //9
-	"low_usd",             //10
-	"price_usd",           //11
-	"volume_usd",          //12
-	"market_cap_usd",      //13
-	"ticker",              //14
-	"description",         //15
-	"interval_id",         //16
-	"market_data_type_id", //17
-	"source_id",           //18
-	"total_supply",        //19
-	"max_supply",          //20
-	"circulating_supply",  //21
-	"sparkline_7d",        //22
-	"created_by",          //23
-	"created_at",          //24
-	"updated_by",          //25
-	"updated_at",          //26
-}
+var DBColumns = MarketDataRepository.Columns()
+var DBColumnsInsertMarketDataList = MarketDataRepository.InsertColumns()
 var fakeSparklineData = []float64{
 	float64(1.0),
 	float64(2.0),
@@ -141,39 +88,7 @@ var TestData2 = MarketData{
 var TestAllData = []MarketData{TestData1, TestData2}
 
 func AddMarketDataToMockRows(mock pgxmock.PgxPoolIface, dataList []MarketData) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                //1
-			data.UUID,              //2
-			data.Name,              //3
-			data.AlternateName,     //4
-			data.StartDate,         //5
-			data.EndDate,           //6
-			data.AssetID,           //7
-			data.OpenUSD,           //8
-			data.CloseUSD,          //9
-			data.HighUSD,           //10
-			data.LowUSD,            //11
-			data.PriceUSD,          //12
-			data.VolumeUSD,         //13
-			data.MarketCapUSD,      //14
-			data.Ticker,            //15
-			data.Description,       //16
-			data.IntervalID,        //17
-			data.MarketDataTypeID,  //18
-			data.SourceID,          //19
-			data.TotalSupply,       //20
-			data.MaxSupply,         //21
-			data.CirculatingSupply, //22
-			data.Sparkline7d,       //23
-			data.CreatedBy,         //24
-			data.CreatedAt,         //25
-			data.UpdatedBy,         //26
-			data.UpdatedAt,         //27
-		)
-	}
-	return rows
+	return MarketDataRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetMarketData(t *testing.T) {
@@ -209,8 +124,8 @@ func TestGetMarketDataForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM market_data").WithArgs(marketDataID).WillReturnRows(noRows)
 	foundMarketData, err := GetMarketData(mock, &marketDataID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetMarketData", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundMarketData != nil {
 		t.Errorf("Expected MarketData From Method GetMarketData: to be empty but got this: %v", foundMarketData)
@@ -424,8 +339,8 @@ func TestGetMarketDataList(t *testing.T) {
 	defer mock.Close()
 	dataList := []MarketData{TestData1, TestData2}
 	mockRows := AddMarketDataToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM market_data").WillReturnRows(mockRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM market_data").WithArgs(ids).WillReturnRows(mockRows)
 	foundMarketDataList, err := GetMarketDataList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetMarketDataList", err)
@@ -621,29 +536,29 @@ func TestUpdateMarketData(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.UpdatedBy,             //22
-		targetData.ID,                    //23
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.UpdatedBy,         //22
+		targetData.ID,                //23
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateMarketData(mock, &targetData)
@@ -702,29 +617,29 @@ func TestUpdateMarketDataOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.UpdatedBy,             //22
-		targetData.ID,                    //23
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.UpdatedBy,         //22
+		targetData.ID,                //23
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -747,28 +662,28 @@ func TestInsertMarketData(t *testing.T) {
 	targetData.Name = "New Name"
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.CreatedBy,             //22
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.CreatedBy,         //22
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 	marketDataID, err := InsertMarketData(mock, &targetData)
@@ -813,28 +728,28 @@ func TestInsertMarketDataOnFailure(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.CreatedBy,             //22
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.CreatedBy,         //22
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	marketDataID, err := InsertMarketData(mock, &targetData)
@@ -860,28 +775,28 @@ func TestInsertMarketDataOnFailureOnCommit(t *testing.T) {
 	targetData.Name = ""
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO market_data").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.AssetID,               //5
-		targetData.OpenUSD,               //6
-		targetData.CloseUSD,              //7
-		targetData.HighUSD,               //8
-		targetData.LowUSD,                //9
-		targetData.PriceUSD,              //10
-		targetData.VolumeUSD,             //11
-		targetData.MarketCapUSD,          //12
-		targetData.Ticker,                //13
-		targetData.Description,           //14
-		targetData.IntervalID,            //15
-		targetData.MarketDataTypeID,      //16
-		targetData.SourceID,              //17
-		targetData.TotalSupply,           //18
-		targetData.MaxSupply,             //19
-		targetData.CirculatingSupply,     //20
-		pq.Array(targetData.Sparkline7d), //21
-		targetData.CreatedBy,             //22
+		targetData.Name,              //1
+		targetData.AlternateName,     //2
+		targetData.StartDate,         //3
+		targetData.EndDate,           //4
+		targetData.AssetID,           //5
+		targetData.OpenUSD,           //6
+		targetData.CloseUSD,          //7
+		targetData.HighUSD,           //8
+		targetData.LowUSD,            //9
+		targetData.PriceUSD,          //10
+		targetData.VolumeUSD,         //11
+		targetData.MarketCapUSD,      //12
+		targetData.Ticker,            //13
+		targetData.Description,       //14
+		targetData.IntervalID,        //15
+		targetData.MarketDataTypeID,  //16
+		targetData.SourceID,          //17
+		targetData.TotalSupply,       //18
+		targetData.MaxSupply,         //19
+		targetData.CirculatingSupply, //20
+		targetData.Sparkline7d,       //21
+		targetData.CreatedBy,         //22
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -943,8 +858,8 @@ func TestGetMarketDataListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"market_data_type_id = 1", "source_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM market_data").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("market_data_type_id", "1"), filter.Equal("source_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM market_data").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundMarketDataList, err := GetMarketDataListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetMarketDataListByPagination", err)
@@ -969,8 +884,8 @@ func TestGetMarketDataListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"market_data_type_id = 1", "source_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM market_data").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("market_data_type_id", "1"), filter.Equal("source_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM market_data").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundMarketDataList, err := GetMarketDataListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMarketDataListByPagination", err)
@@ -993,9 +908,9 @@ func TestGetMarketDataListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"market_data_type_id = 1"}
+	filters := filter.All(filter.Equal("market_data_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM market_data").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM market_data").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundMarketDataList, err := GetMarketDataListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMarketDataListByPagination", err)
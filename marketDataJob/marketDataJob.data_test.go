@@ -7,52 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"market_data_id",     //1
-	"jobid",              //2
-	"uuid",               //3
-	"name",               //4
-	"alternate_name",     //5
-	"start_date",         //6
-	"end_date",           //7
-	"description",        //8
-	"status_id",          //9
-	"response_status",    //10
-	"request_url",        //11
-	"request_body",       //12
-	"request_method",     //13
-	"response_data",      //14
-	"response_data_json", //15
-	"created_by",         //16
-	"created_at",         //17
-	"updated_by",         //18
-	"updated_at",         //19
-}
-var DBColumnsInsertMarketDataJobs = []string{
-	"market_data_id",     //1
-	"job_id",             //2
-	"uuid",               //3
-	"name",               //4
-	"alternate_name",     //5
-	"start_date",         //6
-	"end_date",           //7
-	"description",        //8
-	"status_id",          //9
-	"response_status",    //10
-	"request_url",        //11
-	"request_body",       //12
-	"request_method",     //13
-	"response_data",      //14
-	"response_data_json", //15
-	"created_by",         //16
-	"created_at",         //17
-	"updated_by",         //18
-	"updated_at",         //19
-}
+var DBColumns = MarketDataJobRepository.Columns()
+var DBColumnsInsertMarketDataJobs = MarketDataJobRepository.InsertColumns()
 
 var TestData1 = MarketDataJob{
 	MarketDataID:     utils.Ptr[int](1),                                                                                                   //1
@@ -101,31 +63,7 @@ var TestData2 = MarketDataJob{
 var TestAllData = []MarketDataJob{TestData1, TestData2}
 
 func AddMarketDataJobToMockRows(mock pgxmock.PgxPoolIface, dataList []MarketDataJob) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.MarketDataID,     //1
-			data.JobID,            //2
-			data.UUID,             //3
-			data.Name,             //4
-			data.AlternateName,    //5
-			data.StartDate,        //6
-			data.EndDate,          //7
-			data.Description,      //8
-			data.StatusID,         //9
-			data.ResponseStatus,   //10
-			data.RequestUrl,       //11
-			data.RequestBody,      //12
-			data.RequestMethod,    //13
-			data.ResponseData,     //14
-			data.ResponseDataJson, //15
-			data.CreatedBy,        //16
-			data.CreatedAt,        //17
-			data.UpdatedBy,        //18
-			data.UpdatedAt,        //19
-		)
-	}
-	return rows
+	return MarketDataJobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetMarketDataJob(t *testing.T) {
@@ -163,8 +101,8 @@ func TestGetMarketDataJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WithArgs(marketDataID, jobID).WillReturnRows(noRows)
 	foundMarketDataJob, err := GetMarketDataJob(mock, &marketDataID, &jobID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetMarketDataJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundMarketDataJob != nil {
 		t.Errorf("Expected MarketDataJob From Method GetMarketDataJob: to be empty but got this: %v", foundMarketDataJob)
@@ -251,8 +189,8 @@ func TestGetMarketDataJobByMarketDataIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WithArgs(marketDataID).WillReturnRows(noRows)
 	foundMarketDataJob, err := GetMarketDataJobByMarketDataID(mock, &marketDataID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetMarketDataJobByMarketDataID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundMarketDataJob != nil {
 		t.Errorf("Expected MarketDataJob From Method GetMarketDataJobByMarketDataID: to be empty but got this: %v", foundMarketDataJob)
@@ -434,20 +372,21 @@ func TestUpdateMarketDataJob(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE market_data_jobs").WithArgs(
-		targetData.Name,           //1
-		targetData.AlternateName,  //2
-		targetData.StartDate,      //3
-		targetData.EndDate,        //4
-		targetData.Description,    //5
-		targetData.StatusID,       //6
-		targetData.ResponseStatus, //7
-		targetData.RequestUrl,     //8
-		targetData.RequestBody,    //9
-		targetData.RequestMethod,  //10
-		targetData.ResponseData,   //11
-		targetData.UpdatedBy,      //12
-		targetData.MarketDataID,   //13
-		targetData.JobID,          //14
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.UpdatedBy,        //13
+		targetData.MarketDataID,     //14
+		targetData.JobID,            //15
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateMarketDataJob(mock, &targetData)
@@ -502,20 +441,21 @@ func TestUpdateMarketDataJobOnFailure(t *testing.T) {
 	targetData.MarketDataID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE market_data_jobs").WithArgs(
-		targetData.Name,           //1
-		targetData.AlternateName,  //2
-		targetData.StartDate,      //3
-		targetData.EndDate,        //4
-		targetData.Description,    //5
-		targetData.StatusID,       //6
-		targetData.ResponseStatus, //7
-		targetData.RequestUrl,     //8
-		targetData.RequestBody,    //9
-		targetData.RequestMethod,  //10
-		targetData.ResponseData,   //11
-		targetData.UpdatedBy,      //12
-		targetData.MarketDataID,   //13
-		targetData.JobID,          //14
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.UpdatedBy,        //13
+		targetData.MarketDataID,     //14
+		targetData.JobID,            //15
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -537,21 +477,21 @@ func TestInsertMarketDataJob(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO market_data_jobs").WithArgs(
-		targetData.MarketDataID,  //1
-		targetData.JobID,         //2
-		targetData.Name,          //3
-		targetData.AlternateName, //4
-		targetData.StartDate.Format(utils.LayoutPostgres), //5
-		targetData.EndDate.Format(utils.LayoutPostgres),   //6
-		targetData.Description,                            //7
-		targetData.StatusID,                               //8
-		targetData.ResponseStatus,                         //9
-		targetData.RequestUrl,                             //10
-		targetData.RequestBody,                            //11
-		targetData.RequestMethod,                          //12
-		targetData.ResponseData,                           //13
-		targetData.ResponseDataJson,                       //14
-		targetData.CreatedBy,                              //15
+		targetData.MarketDataID,     //1
+		targetData.JobID,            //2
+		targetData.Name,             //3
+		targetData.AlternateName,    //4
+		targetData.StartDate,        //5
+		targetData.EndDate,          //6
+		targetData.Description,      //7
+		targetData.StatusID,         //8
+		targetData.ResponseStatus,   //9
+		targetData.RequestUrl,       //10
+		targetData.RequestBody,      //11
+		targetData.RequestMethod,    //12
+		targetData.ResponseData,     //13
+		targetData.ResponseDataJson, //14
+		targetData.CreatedBy,        //15
 	).WillReturnRows(pgxmock.NewRows([]string{"market_data_id", "job_id"}).AddRow(1, 2))
 	mock.ExpectCommit()
 	marketDataID, jobID, err := InsertMarketDataJob(mock, &targetData)
@@ -598,21 +538,21 @@ func TestInsertMarketDataJobOnFailure(t *testing.T) {
 	targetData.StatusID = nil
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO market_data_jobs").WithArgs(
-		targetData.MarketDataID,  //1
-		targetData.JobID,         //2
-		targetData.Name,          //3
-		targetData.AlternateName, //4
-		targetData.StartDate.Format(utils.LayoutPostgres), //5
-		targetData.EndDate.Format(utils.LayoutPostgres),   //6
-		targetData.Description,                            //7
-		targetData.StatusID,                               //8
-		targetData.ResponseStatus,                         //9
-		targetData.RequestUrl,                             //10
-		targetData.RequestBody,                            //11
-		targetData.RequestMethod,                          //12
-		targetData.ResponseData,                           //13
-		targetData.ResponseDataJson,                       //14
-		targetData.CreatedBy,                              //15
+		targetData.MarketDataID,     //1
+		targetData.JobID,            //2
+		targetData.Name,             //3
+		targetData.AlternateName,    //4
+		targetData.StartDate,        //5
+		targetData.EndDate,          //6
+		targetData.Description,      //7
+		targetData.StatusID,         //8
+		targetData.ResponseStatus,   //9
+		targetData.RequestUrl,       //10
+		targetData.RequestBody,      //11
+		targetData.RequestMethod,    //12
+		targetData.ResponseData,     //13
+		targetData.ResponseDataJson, //14
+		targetData.CreatedBy,        //15
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	marketDataID, jobID, err := InsertMarketDataJob(mock, &targetData)
@@ -640,21 +580,21 @@ func TestInsertMarketDataJobOnFailureOnCommit(t *testing.T) {
 	// name can't be nil
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO market_data_jobs").WithArgs(
-		targetData.MarketDataID,  //1
-		targetData.JobID,         //2
-		targetData.Name,          //3
-		targetData.AlternateName, //4
-		targetData.StartDate.Format(utils.LayoutPostgres), //5
-		targetData.EndDate.Format(utils.LayoutPostgres),   //6
-		targetData.Description,                            //7
-		targetData.StatusID,                               //8
-		targetData.ResponseStatus,                         //9
-		targetData.RequestUrl,                             //10
-		targetData.RequestBody,                            //11
-		targetData.RequestMethod,                          //12
-		targetData.ResponseData,                           //13
-		targetData.ResponseDataJson,                       //14
-		targetData.CreatedBy,                              //15
+		targetData.MarketDataID,     //1
+		targetData.JobID,            //2
+		targetData.Name,             //3
+		targetData.AlternateName,    //4
+		targetData.StartDate,        //5
+		targetData.EndDate,          //6
+		targetData.Description,      //7
+		targetData.StatusID,         //8
+		targetData.ResponseStatus,   //9
+		targetData.RequestUrl,       //10
+		targetData.RequestBody,      //11
+		targetData.RequestMethod,    //12
+		targetData.ResponseData,     //13
+		targetData.ResponseDataJson, //14
+		targetData.CreatedBy,        //15
 	).WillReturnRows(pgxmock.NewRows([]string{"market_data_id", "job_id"}).AddRow(1, 2))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -717,10 +657,10 @@ func TestGetMarketDataJobListByPagination(t *testing.T) {
 	mockRows := AddMarketDataJobToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "market_data_id"
 	_order := "ASC"
-	filters := []string{"job_id = 1", "status_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("job_id", "1"), filter.Equal("status_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundMarketDataJobList, err := GetMarketDataJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetMarketDataJobListByPagination", err)
@@ -743,10 +683,10 @@ func TestGetMarketDataJobListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "market_data_id"
 	_order := "ASC"
-	filters := []string{"job_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("job_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundMarketDataJobList, err := GetMarketDataJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMarketDataJobListByPagination", err)
@@ -767,11 +707,11 @@ func TestGetMarketDataJobListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "market_data_id"
 	_order := "ASC"
-	filters := []string{"job_id = -1"}
+	filters := filter.All(filter.Equal("job_id", "-1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM market_data_jobs").WithArgs(int64(-1)).WillReturnRows(differentModelRows)
 	foundMarketDataJobList, err := GetMarketDataJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMarketDataJobListByPagination", err)
@@ -2,18 +2,34 @@ package marketdataquote
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"time"
 
-	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 )
 
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// MarketDataQuoteRepository implements the standard queries on the
+// market_data_quotes table, keyed on the market data and its base and quote
+// assets.
+var MarketDataQuoteRepository = repository.New[MarketDataQuote]("market_data_quotes",
+	repository.Key("market_data_id", "base_asset_id", "quote_asset_id"),
+	repository.KeepUUID(),
+)
+
 func GetLatestLiveMarketData(dbConnPgx utils.PgxIface) ([]MarketDataQuoteResults, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetLatestLiveMarketDataCtx(ctx, dbConnPgx)
+}
+
+func GetLatestLiveMarketDataCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]MarketDataQuoteResults, error) {
 	results, err := dbConnPgx.Query(ctx, `
 	SELECT 
 
@@ -72,8 +88,8 @@ func GetLatestLiveMarketData(dbConnPgx utils.PgxIface) ([]MarketDataQuoteResults
 	AND mq.quote_asset_id = 34
 	ORDER BY baseA.ticker, m.start_date desc`)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetLatestLiveMarketData", err, logging.Entity("market_data_quotes"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	MarketDataQuoteResultsList := make([]MarketDataQuoteResults, 0)
@@ -126,12 +142,16 @@ func GetLatestLiveMarketData(dbConnPgx utils.PgxIface) ([]MarketDataQuoteResults
 }
 
 func RemoveMarketDataQuoteFromBaseAssetBetweenDates(dbConnPgx utils.PgxIface, assetID *int, startDate, endDate time.Time) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return RemoveMarketDataQuoteFromBaseAssetBetweenDatesCtx(ctx, dbConnPgx, assetID, startDate, endDate)
+}
+
+func RemoveMarketDataQuoteFromBaseAssetBetweenDatesCtx(ctx context.Context, dbConnPgx utils.PgxIface, assetID *int, startDate, endDate time.Time) error {
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in RemoveGethMarketData DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "RemoveMarketDataQuoteFromBaseAssetBetweenDates: begin", err, logging.Entity("market_data_quotes"))
+		return dberrors.Wrap(err)
 	}
 	sql := `DELETE
 		FROM market_data_quotes
@@ -142,15 +162,19 @@ func RemoveMarketDataQuoteFromBaseAssetBetweenDates(dbConnPgx utils.PgxIface, as
 			AND start_date BETWEEN $2 and $3
 		);`
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *assetID, startDate, endDate); err != nil {
+	if _, err := tx.Exec(ctx, sql, *assetID, startDate, endDate); err != nil {
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 func GetAllMarketDataFromStrategyID(dbConnPgx utils.PgxIface, strategyID *int) ([]MarketDataQuoteResults, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetAllMarketDataFromStrategyIDCtx(ctx, dbConnPgx, strategyID)
+}
+
+func GetAllMarketDataFromStrategyIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyID *int) ([]MarketDataQuoteResults, error) {
 	results, err := dbConnPgx.Query(ctx, `
 	SELECT 
 		m.start_date,
@@ -207,8 +231,8 @@ func GetAllMarketDataFromStrategyID(dbConnPgx utils.PgxIface, strategyID *int) (
 	-- 	AND mq.quote_asset_id = 34 ignore usd since need ecd ptp / ptp where base is ptp
 	ORDER BY m.start_date desc , baseA.ticker`, *strategyID)
 	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+		logging.ReturnedError(ctx, "GetAllMarketDataFromStrategyID", err, logging.Entity("market_data_quotes"))
+		return nil, dberrors.Wrap(err)
 	}
 
 	MarketDataQuoteResultsList := make([]MarketDataQuoteResults, 0)
@@ -261,303 +285,43 @@ func GetAllMarketDataFromStrategyID(dbConnPgx utils.PgxIface, strategyID *int) (
 }
 
 func InsertMarketDataQuote(dbConnPgx utils.PgxIface, marketDataQuote *MarketDataQuote) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertMarketDataQuote DbConn.Begin   %s", err.Error())
-		return err
-	}
-	_, err = dbConnPgx.Query(ctx, `INSERT INTO market_data_quotes 
-	(
-		"market_data_id",        
-		"base_asset_id",         
-		"quote_asset_id",        
-		"uuid",                  
-		"name",                  
-		"alternate_name",        
-		"open",                  
-		"close",                 
-		"high_24h",              
-		"low_24h",               
-		"price",                 
-		"volume",                
-		"market_cap",            
-		"ticker",                
-		"description",           
-		"source_id",             
-		"fully_diluted_valution", 
-		"ath",                    
-		"ath_date",               
-		"atl",                    
-		"atl_date",               
-		"price_change_1h",        
-		"price_change_24h",       
-		"price_change_7d",        
-		"price_change_30d",       
-		"price_change_60d",       
-		"price_change_200d",      
-		"price_change_1y",        
-		"created_by",             
-		"created_at",             
-		"updated_by",             
-		"updated_at",             
-		) VALUES (
-			$1,
-			$2,
-			$3, 
-			$4, 
-			$5, 
-			$6, 
-			$7,
-			$8,
-			$9,
-			$10,
-			$11,
-			$12,
-			$13,
-			$14,
-			$15,
-			$16,
-			$17,
-			$18,
-			$19,
-			$20,
-			$21,
-			$22,
-			$23,
-			$24,
-			$25,
-			$26,
-			$27,
-			$28,
-			$29,
-			current_timestamp at time zone 'UTC',
-			$30,
-			current_timestamp at time zone 'UTC',
-		)`,
-		marketDataQuote.MarketDataID,         //1
-		marketDataQuote.BaseAssetID,          //2
-		marketDataQuote.QuoteAssetID,         //3
-		marketDataQuote.UUID,                 //4
-		marketDataQuote.Name,                 //5
-		marketDataQuote.AlternateName,        //6
-		marketDataQuote.Open,                 //7
-		marketDataQuote.Close,                //8
-		marketDataQuote.High24h,              //9
-		marketDataQuote.Low24h,               //10
-		marketDataQuote.Price,                //11
-		marketDataQuote.Volume,               //12
-		marketDataQuote.MarketCap,            //13
-		marketDataQuote.Ticker,               //14
-		marketDataQuote.Description,          //15
-		marketDataQuote.SourceID,             //16
-		marketDataQuote.FullyDilutedValution, //17
-		marketDataQuote.Ath,                  //18
-		marketDataQuote.AthDate,              //19
-		marketDataQuote.Atl,                  //20
-		marketDataQuote.AtlDate,              //21
-		marketDataQuote.PriceChange1h,        //22
-		marketDataQuote.PriceChange24h,       //23
-		marketDataQuote.PriceChange7d,        //24
-		marketDataQuote.PriceChange30d,       //25
-		marketDataQuote.PriceChange60d,       //26
-		marketDataQuote.PriceChange200d,      //27
-		marketDataQuote.PriceChange1y,        //28
-		marketDataQuote.CreatedBy,            //29
-		marketDataQuote.CreatedBy,            //30
-	)
+	return InsertMarketDataQuoteCtx(ctx, dbConnPgx, marketDataQuote)
+}
 
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return err
-	}
-	return nil
+func InsertMarketDataQuoteCtx(ctx context.Context, dbConnPgx utils.PgxIface, marketDataQuote *MarketDataQuote) error {
+	var marketDataID, baseAssetID, quoteAssetID int
+	return MarketDataQuoteRepository.InsertReturningKey(ctx, dbConnPgx, marketDataQuote, &marketDataID, &baseAssetID, &quoteAssetID)
 }
 func InsertMarketDataQuoteList(dbConnPgx utils.PgxIface, marketDataQuoteList []MarketDataQuote) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range marketDataQuoteList {
-		marketDataQuote := marketDataQuoteList[i]
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(marketDataQuote.UUID)
-		row := []interface{}{
-			*marketDataQuote.MarketDataID,                                     //1
-			*marketDataQuote.BaseAssetID,                                      //2
-			*marketDataQuote.QuoteAssetID,                                     //3
-			uuidString,                                                        //4
-			marketDataQuote.Name,                                              //5
-			marketDataQuote.AlternateName,                                     //6
-			utils.ConvertFloatToDecimal(marketDataQuote.Open),                 //7
-			utils.ConvertFloatToDecimal(marketDataQuote.Close),                //8
-			utils.ConvertFloatToDecimal(marketDataQuote.High24h),              //9
-			utils.ConvertFloatToDecimal(marketDataQuote.Low24h),               //10
-			utils.ConvertFloatToDecimal(marketDataQuote.Price),                //11
-			utils.ConvertFloatToDecimal(marketDataQuote.Volume),               //12
-			utils.ConvertFloatToDecimal(marketDataQuote.MarketCap),            //13
-			marketDataQuote.Ticker,                                            //14
-			marketDataQuote.Description,                                       //15
-			*marketDataQuote.SourceID,                                         //16
-			utils.ConvertFloatToDecimal(marketDataQuote.FullyDilutedValution), //17
-			utils.ConvertFloatToDecimal(marketDataQuote.Ath),                  //18
-			&marketDataQuote.AthDate,                                          //19
-			utils.ConvertFloatToDecimal(marketDataQuote.Atl),                  //20
-			&marketDataQuote.AtlDate,                                          //21
-			utils.ConvertFloatToDecimal(marketDataQuote.PriceChange1h),        //22
-			utils.ConvertFloatToDecimal(marketDataQuote.PriceChange24h),       //23
-			utils.ConvertFloatToDecimal(marketDataQuote.PriceChange7d),        //24
-			utils.ConvertFloatToDecimal(marketDataQuote.PriceChange30d),       //25
-			utils.ConvertFloatToDecimal(marketDataQuote.PriceChange60d),       //26
-			utils.ConvertFloatToDecimal(marketDataQuote.PriceChange200d),      //27
-			utils.ConvertFloatToDecimal(marketDataQuote.PriceChange1y),        //28
-			marketDataQuote.CreatedBy,                                         //29
-			&now,                                                              //30
-			marketDataQuote.CreatedBy,                                         //31
-			&now,                                                              //32
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"market_data_quotes"},
-		[]string{
-			"market_data_id",         //1
-			"base_asset_id",          //2
-			"quote_asset_id",         //3
-			"uuid",                   //4
-			"name",                   //5
-			"alternate_name",         //6
-			"open",                   //7
-			"close",                  //8
-			"high_24h",               //9
-			"low_24h",                //10
-			"price",                  //11
-			"volume",                 //12
-			"market_cap",             //13
-			"ticker",                 //14
-			"description",            //15
-			"source_id",              //16
-			"fully_diluted_valution", //17
-			"ath",                    //18
-			"ath_date",               //19
-			"atl",                    //20
-			"atl_date",               //21
-			"price_change_1h",        //22
-			"price_change_24h",       //23
-			"price_change_7d",        //24
-			"price_change_30d",       //25
-			"price_change_60d",       //26
-			"price_change_200d",      //27
-			"price_change_1y",        //28
-			"created_by",             //29
-			"created_at",             //30
-			"updated_by",             //31
-			"updated_at",             //32
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertMarketDataQuoteList: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
-	return nil
+	return InsertMarketDataQuoteListCtx(ctx, dbConnPgx, marketDataQuoteList)
+}
+
+func InsertMarketDataQuoteListCtx(ctx context.Context, dbConnPgx utils.PgxIface, marketDataQuoteList []MarketDataQuote) error {
+	_, err := MarketDataQuoteRepository.InsertMany(ctx, dbConnPgx, marketDataQuoteList)
+	return dberrors.Wrap(err)
 }
 
 // for refinedev
-func GetMarketDataQuoteListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]MarketDataQuote, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetMarketDataQuoteListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]MarketDataQuote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT 
-		market_data_id,
-		base_asset_id,
-		quote_asset_id ,
-  		uuid,
-		name,
-		alternate_name,
-		open,
-		close,
-		high_24h,
-		low_24h,
-		price,
-		volume,
-		market_cap,
-		ticker,
-		description,
-		source_id,
-		fully_diluted_valution,
-		ath,
-		ath_date date,
-		atl,
-		atl_date date,
-		rename to high_24h,
-		rename to low_24h,
-		price_change_1h,
-		price_change_24h,
-		price_change_7d,
-		price_change_30d,
-		price_change_60d,
-		price_change_200d,
-		price_change_1y,
-		created_by,
-		created_at,
-		updated_by,
-		updated_at,
-	FROM market_data_quotes
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetMarketDataQuoteListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
+}
 
-	marketDataQuoteList, err := pgx.CollectRows(results, pgx.RowToStructByName[MarketDataQuote])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return marketDataQuoteList, nil
+func GetMarketDataQuoteListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]MarketDataQuote, error) {
+	return MarketDataQuoteRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
 }
 
 func GetTotalMarketDataQuoteCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalMarketDataQuoteCountCtx(ctx, dbConnPgx)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT COUNT(*) FROM market_data_quotes`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalMarketDataQuoteCountCtx(ctx context.Context, dbConnPgx utils.PgxIface) (*int, error) {
+	return MarketDataQuoteRepository.Count(ctx, dbConnPgx)
 }
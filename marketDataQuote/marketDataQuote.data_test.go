@@ -7,78 +7,13 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"market_data_id",         //1
-	"base_asset_id",          //2
-	"quote_asset_id",         //3
-	"uuid",                   //4
-	"name",                   //5
-	"alternate_name",         //6
-	"open",                   //7
-	"close",                  //8
-	"high_24h",               //9
-	"low_24h",                //10
-	"price",                  //11
-	"volume",                 //12
-	"market_cap",             //13
-	"ticker",                 //14
-	"description",            //15
-	"source_id",              //16
-	"fully_diluted_valution", //17
-	"ath",                    //18
-	"ath_date",               //19
-	"atl",                    //20
-	"atl_date",               //21
-	"price_change_1h",        //22
-	"price_change_24h",       //23
-	"price_change_7d",        //24
-	"price_change_30d",       //25
-	"price_change_60d",       //26
-	"price_change_200d",      //27
-	"price_change_1y",        //28
-	"created_by",             //29
-	"created_at",             //30
-	"updated_by",             //31
-	"updated_at",             //32
-}
-var DBColumnsInsertMarketDataQuoteList = []string{
-	"market_data_id",         //1
-	"base_asset_id",          //2
-	"quote_asset_id",         //3
-	"uuid",                   //4
-	"name",                   //5
-	"alternate_name",         //6
-	"open",                   //7
-	"close",                  //8
-	"high_24h",               //9
-	"low_24h",                //10
-	"price",                  //11
-	"volume",                 //12
-	"market_cap",             //13
-	"ticker",                 //14
-	"description",            //15
-	"source_id",              //16
-	"fully_diluted_valution", //17
-	"ath",                    //18
-	"ath_date",               //19
-	"atl",                    //20
-	"atl_date",               //21
-	"price_change_1h",        //22
-	"price_change_24h",       //23
-	"price_change_7d",        //24
-	"price_change_30d",       //25
-	"price_change_60d",       //26
-	"price_change_200d",      //27
-	"price_change_1y",        //28
-	"created_by",             //29
-	"created_at",             //30
-	"updated_by",             //31
-	"updated_at",             //32
-}
+var DBColumns = MarketDataQuoteRepository.Columns()
+var DBColumnsInsertMarketDataQuoteList = MarketDataQuoteRepository.InsertColumns()
 var fakeSparklineData = []float64{
 	float64(1.0),
 	float64(2.0),
@@ -161,44 +96,7 @@ var TestData2 = MarketDataQuote{
 var TestAllData = []MarketDataQuote{TestData1, TestData2}
 
 func AddMarketDataQuoteToMockRows(mock pgxmock.PgxPoolIface, dataList []MarketDataQuote) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.MarketDataID,         //1
-			data.BaseAssetID,          //2
-			data.QuoteAssetID,         //3
-			data.UUID,                 //4
-			data.Name,                 //5
-			data.AlternateName,        //6
-			data.Open,                 //7
-			data.Close,                //8
-			data.High24h,              //9
-			data.Low24h,               //10
-			data.Price,                //11
-			data.Volume,               //12
-			data.MarketCap,            //13
-			data.Ticker,               //14
-			data.Description,          //15
-			data.SourceID,             //16
-			data.FullyDilutedValution, //17
-			data.Ath,                  //18
-			data.AthDate,              //19
-			data.Atl,                  //20
-			data.AtlDate,              //21
-			data.PriceChange1h,        //22
-			data.PriceChange24h,       //23
-			data.PriceChange7d,        //24
-			data.PriceChange30d,       //25
-			data.PriceChange60d,       //26
-			data.PriceChange200d,      //27
-			data.PriceChange1y,        //28
-			data.CreatedBy,            //29
-			data.CreatedAt,            //30
-			data.UpdatedBy,            //31
-			data.UpdatedAt,            //32
-		)
-	}
-	return rows
+	return MarketDataQuoteRepository.AddToMockRows(mock, dataList)
 }
 
 var DBColumnsMarketDataQuoteResults = []string{
@@ -511,7 +409,6 @@ func TestInsertMarketDataQuote(t *testing.T) {
 		targetData.PriceChange200d,      //27
 		targetData.PriceChange1y,        //28
 		targetData.CreatedBy,            //29
-		targetData.CreatedBy,            //30
 	).WillReturnRows(pgxmock.NewRows([]string{""}).AddRow(1))
 	mock.ExpectCommit()
 	err = InsertMarketDataQuote(mock, &targetData)
@@ -582,7 +479,6 @@ func TestInsertMarketDataQuoteOnFailure(t *testing.T) {
 		targetData.PriceChange200d,      //27
 		targetData.PriceChange1y,        //28
 		targetData.CreatedBy,            //29
-		targetData.CreatedBy,            //30
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	err = InsertMarketDataQuote(mock, &targetData)
@@ -634,7 +530,6 @@ func TestInsertMarketDataQuoteOnFailureOnCommit(t *testing.T) {
 		targetData.PriceChange200d,      //27
 		targetData.PriceChange1y,        //28
 		targetData.CreatedBy,            //29
-		targetData.CreatedBy,            //30
 	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -692,10 +587,10 @@ func TestGetMarketDataQuoteListByPagination(t *testing.T) {
 	mockRows := AddMarketDataQuoteToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "market_data_id"
 	_order := "ASC"
-	filters := []string{"quote_asset_id = 1", "source_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM market_data_quotes").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("quote_asset_id", "1"), filter.Equal("source_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM market_data_quotes").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundMarketDataQuoteList, err := GetMarketDataQuoteListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetMarketDataQuoteListByPagination", err)
@@ -718,10 +613,10 @@ func TestGetMarketDataQuoteListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "market_data_id"
 	_order := "ASC"
-	filters := []string{"quote_asset_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM market_data_quotes").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("quote_asset_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM market_data_quotes").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundMarketDataQuoteList, err := GetMarketDataQuoteListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMarketDataQuoteListByPagination", err)
@@ -742,11 +637,11 @@ func TestGetMarketDataQuoteListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "market_data_id"
 	_order := "ASC"
-	filters := []string{"quote_asset_id = 1"}
+	filters := filter.All(filter.Equal("quote_asset_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM market_data_quotes").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM market_data_quotes").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundMarketDataQuoteList, err := GetMarketDataQuoteListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetMarketDataQuoteListByPagination", err)
@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// SchemaTable records the applied migration versions.
+const SchemaTable = "schema_migrations"
+
+// lockID is the pg_advisory_xact_lock key serialising concurrent runs, e.g.
+// several services or test packages starting against the same database.
+const lockID int64 = 4_711_020_060
+
+// MigrationStatus reports whether a migration has been applied. Versions
+// found in the database but not embedded in this build are reported with an
+// empty Name.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt"`
+}
+
+// Migrate brings the database up to the latest embedded version.
+func Migrate(ctx context.Context, dbConnPgx utils.PgxIface) error {
+	latest, err := Latest()
+	if err != nil {
+		logging.ReturnedError(ctx, "Migrate", err, logging.Entity(SchemaTable))
+		return err
+	}
+	return MigrateTo(ctx, dbConnPgx, latest)
+}
+
+// MigrateTo applies the up migrations up to and including version and reverts,
+// newest first, the applied migrations above it; version 0 reverts everything.
+// All steps run in one transaction holding an advisory lock, so a failure
+// leaves the schema untouched and concurrent callers wait for each other.
+func MigrateTo(ctx context.Context, dbConnPgx utils.PgxIface, version int64) error {
+	migrations, err := All()
+	if err != nil {
+		logging.ReturnedError(ctx, "MigrateTo", err, logging.Entity(SchemaTable))
+		return err
+	}
+	known := make(map[int64]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+	}
+	if version != 0 && !known[version] {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	err = utils.WithTx(ctx, dbConnPgx, func(tx utils.PgxIface) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, lockID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, createSchemaTableSQL); err != nil {
+			return err
+		}
+		applied, err := appliedVersions(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for v := range applied {
+			if !known[v] {
+				return fmt.Errorf("%w: %d", ErrDatabaseAhead, v)
+			}
+		}
+		for _, migration := range migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := up(ctx, tx, migration); err != nil {
+				return err
+			}
+		}
+		for i := len(migrations) - 1; i >= 0; i-- {
+			migration := migrations[i]
+			if migration.Version <= version {
+				break
+			}
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := down(ctx, tx, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.ReturnedError(ctx, "MigrateTo", err, logging.Entity(SchemaTable))
+		return err
+	}
+	return nil
+}
+
+// Status lists every embedded migration and whether it has been applied.
+func Status(ctx context.Context, dbConnPgx utils.PgxIface) ([]MigrationStatus, error) {
+	migrations, err := All()
+	if err != nil {
+		logging.ReturnedError(ctx, "Status", err, logging.Entity(SchemaTable))
+		return nil, err
+	}
+	var exists bool
+	if err := dbConnPgx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, SchemaTable).Scan(&exists); err != nil {
+		logging.ReturnedError(ctx, "Status", err, logging.Entity(SchemaTable))
+		return nil, err
+	}
+	applied := map[int64]time.Time{}
+	if exists {
+		applied, err = appliedVersions(ctx, dbConnPgx)
+		if err != nil {
+			logging.ReturnedError(ctx, "Status", err, logging.Entity(SchemaTable))
+			return nil, err
+		}
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for v, appliedAt := range applied {
+		statuses = append(statuses, MigrationStatus{Version: v, Applied: true, AppliedAt: &appliedAt})
+	}
+	return statuses, nil
+}
+
+var createSchemaTableSQL = `CREATE TABLE IF NOT EXISTS ` + SchemaTable + `
+	(
+		version BIGINT NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		applied_at timestamp NOT NULL,
+		PRIMARY KEY(version)
+	)`
+
+func appliedVersions(ctx context.Context, dbConnPgx utils.PgxIface) (map[int64]time.Time, error) {
+	rows, err := dbConnPgx.Query(ctx, `SELECT version, applied_at FROM `+SchemaTable+` ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func up(ctx context.Context, tx utils.PgxIface, migration Migration) error {
+	if _, err := tx.Exec(ctx, migration.Up); err != nil {
+		return fmt.Errorf("migrations: up %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	_, err := tx.Exec(ctx, `INSERT INTO `+SchemaTable+` (version, name, applied_at) VALUES ($1, $2, current_timestamp at time zone 'UTC')`,
+		migration.Version, migration.Name)
+	if err != nil {
+		return err
+	}
+	logging.Info(ctx, "Migrate: applied", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+	return nil
+}
+
+func down(ctx context.Context, tx utils.PgxIface, migration Migration) error {
+	if _, err := tx.Exec(ctx, migration.Down); err != nil {
+		return fmt.Errorf("migrations: down %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM `+SchemaTable+` WHERE version = $1`, migration.Version); err != nil {
+		return err
+	}
+	logging.Info(ctx, "Migrate: reverted", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+	return nil
+}
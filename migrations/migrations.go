@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// sql holds the migrations as NNNN_name.up.sql / NNNN_name.down.sql pairs.
+// Versions are applied in ascending order and must never be renumbered once
+// released; add a new pair to change the schema.
+//
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+var (
+	ErrInvalidFile     = errors.New("migrations: invalid migration file")
+	ErrMissingDown     = errors.New("migrations: missing down migration")
+	ErrMissingUp       = errors.New("migrations: missing up migration")
+	ErrUnknownVersion  = errors.New("migrations: unknown version")
+	ErrDuplicateFile   = errors.New("migrations: duplicate migration file")
+	ErrDatabaseAhead   = errors.New("migrations: database has versions this build does not know")
+	migrationFileRegex = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// All returns the embedded migrations ordered by version.
+func All() ([]Migration, error) {
+	return load(sqlFiles, "sql")
+}
+
+// Latest returns the highest embedded version, the schema the Go code expects.
+func Latest() (int64, error) {
+	migrations, err := All()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d is used by %s and %s", ErrDuplicateFile, version, migration.Name, match[2])
+		}
+		target := &migration.Up
+		if match[3] == "down" {
+			target = &migration.Down
+		}
+		if *target != "" {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateFile, entry.Name())
+		}
+		*target = string(content)
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, migration.Version, migration.Name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingDown, migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
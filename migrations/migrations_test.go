@@ -0,0 +1,215 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestAll(t *testing.T) {
+	migrations, err := All()
+	if err != nil {
+		t.Fatalf("an error '%s' in All", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("expected embedded migrations")
+	}
+	for i, migration := range migrations {
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			t.Errorf("migrations out of order: %d after %d", migration.Version, migrations[i-1].Version)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			t.Errorf("migration %d_%s needs both up and down", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"sql/0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"sql/0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"sql/0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+	}
+	migrations, err := load(fsys, "sql")
+	if err != nil {
+		t.Fatalf("an error '%s' in load", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Name != "second" {
+		t.Errorf("unexpected migrations %+v", migrations)
+	}
+}
+
+func TestLoadOnInvalidFiles(t *testing.T) {
+	tests := map[string]struct {
+		fsys fstest.MapFS
+		want error
+	}{
+		"bad name": {fstest.MapFS{"sql/first.up.sql": {}}, ErrInvalidFile},
+		"no down":  {fstest.MapFS{"sql/0001_first.up.sql": {Data: []byte("SELECT 1;")}}, ErrMissingDown},
+		"no up":    {fstest.MapFS{"sql/0001_first.down.sql": {Data: []byte("SELECT 1;")}}, ErrMissingUp},
+		"duplicate version": {fstest.MapFS{
+			"sql/0001_first.up.sql":  {Data: []byte("SELECT 1;")},
+			"sql/0001_second.up.sql": {Data: []byte("SELECT 1;")},
+		}, ErrDuplicateFile},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(test.fsys, "sql")
+			if !errors.Is(err, test.want) {
+				t.Errorf("expected error '%s', got '%v'", test.want, err)
+			}
+		})
+	}
+}
+
+func newMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	return mock
+}
+
+func expectLock(mock pgxmock.PgxPoolIface) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock($1)`).WithArgs(lockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec(createSchemaTableSQL).WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+}
+
+func appliedRows(versions ...int64) *pgxmock.Rows {
+	rows := pgxmock.NewRows([]string{"version", "applied_at"})
+	for _, v := range versions {
+		rows.AddRow(v, time.Now())
+	}
+	return rows
+}
+
+const selectAppliedSQL = `SELECT version, applied_at FROM schema_migrations ORDER BY version`
+const insertAppliedSQL = `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, current_timestamp at time zone 'UTC')`
+
+func TestMigrate(t *testing.T) {
+	mock := newMock(t)
+	defer mock.Close()
+	migrations, _ := All()
+	expectLock(mock)
+	mock.ExpectQuery(selectAppliedSQL).WillReturnRows(appliedRows(migrations[0].Version))
+	for _, migration := range migrations[1:] {
+		mock.ExpectExec(migration.Up).WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+		mock.ExpectExec(insertAppliedSQL).WithArgs(migration.Version, migration.Name).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mock.ExpectCommit()
+	if err := Migrate(context.Background(), mock); err != nil {
+		t.Fatalf("an error '%s' in Migrate", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrateToRevertsNewestFirst(t *testing.T) {
+	mock := newMock(t)
+	defer mock.Close()
+	migrations, _ := All()
+	versions := make([]int64, 0, len(migrations))
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	expectLock(mock)
+	mock.ExpectQuery(selectAppliedSQL).WillReturnRows(appliedRows(versions...))
+	for i := len(migrations) - 1; i >= 1; i-- {
+		mock.ExpectExec(migrations[i].Down).WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+		mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = $1`).WithArgs(migrations[i].Version).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	}
+	mock.ExpectCommit()
+	if err := MigrateTo(context.Background(), mock, migrations[0].Version); err != nil {
+		t.Fatalf("an error '%s' in MigrateTo", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrateToUnknownVersion(t *testing.T) {
+	mock := newMock(t)
+	defer mock.Close()
+	if err := MigrateTo(context.Background(), mock, 9999); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("expected error '%s', got '%v'", ErrUnknownVersion, err)
+	}
+}
+
+func TestMigrateOnDatabaseAhead(t *testing.T) {
+	mock := newMock(t)
+	defer mock.Close()
+	expectLock(mock)
+	mock.ExpectQuery(selectAppliedSQL).WillReturnRows(appliedRows(9999))
+	mock.ExpectRollback()
+	if err := Migrate(context.Background(), mock); !errors.Is(err, ErrDatabaseAhead) {
+		t.Fatalf("expected error '%s', got '%v'", ErrDatabaseAhead, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrateOnFailure(t *testing.T) {
+	mock := newMock(t)
+	defer mock.Close()
+	migrations, _ := All()
+	expectLock(mock)
+	mock.ExpectQuery(selectAppliedSQL).WillReturnRows(appliedRows())
+	mock.ExpectExec(migrations[0].Up).WillReturnError(errors.New("Random SQL Error"))
+	mock.ExpectRollback()
+	if err := Migrate(context.Background(), mock); err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	mock := newMock(t)
+	defer mock.Close()
+	migrations, _ := All()
+	mock.ExpectQuery(`SELECT to_regclass($1) IS NOT NULL`).WithArgs(SchemaTable).WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(selectAppliedSQL).WillReturnRows(appliedRows(migrations[0].Version))
+	statuses, err := Status(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("an error '%s' in Status", err)
+	}
+	if len(statuses) != len(migrations) {
+		t.Fatalf("expected %d statuses, got %d", len(migrations), len(statuses))
+	}
+	if !statuses[0].Applied || statuses[0].AppliedAt == nil {
+		t.Errorf("expected %d to be applied", statuses[0].Version)
+	}
+	if len(statuses) > 1 && statuses[1].Applied {
+		t.Errorf("expected %d to be pending", statuses[1].Version)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStatusOnFreshDatabase(t *testing.T) {
+	mock := newMock(t)
+	defer mock.Close()
+	mock.ExpectQuery(`SELECT to_regclass($1) IS NOT NULL`).WithArgs(SchemaTable).WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	statuses, err := Status(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("an error '%s' in Status", err)
+	}
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("expected %d to be pending", status.Version)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ai_models;
+DROP TABLE IF EXISTS sources;
+DROP TABLE IF EXISTS structured_values;
+DROP TABLE IF EXISTS structured_value_types;
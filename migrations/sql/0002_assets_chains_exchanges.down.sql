@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS liquidity_pool_assets;
+DROP TABLE IF EXISTS liquidity_pools;
+DROP TABLE IF EXISTS exchange_chains;
+DROP TABLE IF EXISTS exchanges;
+DROP TABLE IF EXISTS asset_chains;
+DROP TABLE IF EXISTS asset_sources;
+ALTER TABLE IF EXISTS chains DROP CONSTRAINT IF EXISTS fk_base_asset;
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS chains;
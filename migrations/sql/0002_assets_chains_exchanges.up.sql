@@ -0,0 +1,195 @@
+CREATE TABLE chains
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  base_asset_id INT NULL,
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  address VARCHAR(255) NULL,
+  chain_type_id INT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  rpc_url VARCHAR(255) NULL,
+  chain_id INT NULL,
+  block_explorer_url VARCHAR(255) NULL,
+  rpc_url_dev VARCHAR(255) NULL,
+  rpc_url_prod VARCHAR(255) NULL,
+  rpc_url_archive VARCHAR(255) NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_chain_type FOREIGN KEY(chain_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE assets
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name TEXT NOT NULL,
+  alternate_name TEXT NULL,
+  cusip VARCHAR(255) NULL,
+  ticker VARCHAR(255) NULL,
+  base_asset_id INT NULL,
+  quote_asset_id INT NULL,
+  description TEXT NULL,
+  asset_type_id INT NOT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  chain_id INT NULL,
+  category_id INT NULL,
+  sub_category_id INT NULL,
+  is_default_quote BOOLEAN NOT NULL,
+  ignore_market_data BOOLEAN NOT NULL,
+  decimals INT NULL,
+  contract_address VARCHAR(255) NULL,
+  starting_block_number NUMERIC NULL,
+  import_geth BOOLEAN NOT NULL DEFAULT FALSE,
+  import_geth_initial BOOLEAN NOT NULL DEFAULT FALSE,
+  chainlink_usd_address VARCHAR(255) NULL,
+  chainlink_usd_chain_id INT NULL,
+  total_supply NUMERIC NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_base_asset FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_quote_asset FOREIGN KEY(quote_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_structured_value_asset_type FOREIGN KEY(asset_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_structured_value_category FOREIGN KEY(category_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_structured_value__sub_category FOREIGN KEY(sub_category_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_chainlink_usd_chain FOREIGN KEY(chainlink_usd_chain_id) REFERENCES chains(id)
+);
+
+-- chains and assets reference each other
+ALTER TABLE chains
+  ADD CONSTRAINT fk_base_asset FOREIGN KEY(base_asset_id) REFERENCES assets(id);
+
+CREATE INDEX assets_address ON assets(contract_address);
+CREATE INDEX assets_base_asset ON assets(base_asset_id);
+CREATE INDEX assets_quote_asset ON assets(quote_asset_id);
+CREATE INDEX assets_chains ON assets(chain_id);
+CREATE INDEX assets_asset_type ON assets(asset_type_id);
+
+CREATE TABLE asset_sources
+(
+  source_id INT NOT NULL,
+  asset_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  source_identifier VARCHAR(255) NOT NULL,
+  description TEXT NULL,
+  source_data jsonb NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(source_id, asset_id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id),
+  CONSTRAINT fk_asset FOREIGN KEY(asset_id) REFERENCES assets(id)
+);
+
+CREATE TABLE asset_chains
+(
+  asset_id INT NOT NULL,
+  chain_id INT NOT NULL,
+  chainlink_data_feed_contract_address TEXT NOT NULL,
+  created_by TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_by TEXT NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(asset_id, chain_id),
+  CONSTRAINT fk_asset FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
+
+CREATE INDEX asset_chain_asset_id ON asset_chains(asset_id);
+CREATE INDEX asset_chain_chain_id ON asset_chains(chain_id);
+
+CREATE TABLE exchanges
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  exchange_type_id INT NULL,
+  url VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_structured_value_exchange_type FOREIGN KEY(exchange_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE exchange_chains
+(
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  exchange_id INT NOT NULL,
+  chain_id INT NOT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(exchange_id, chain_id),
+  CONSTRAINT fk_exchanged_id FOREIGN KEY(exchange_id) REFERENCES exchanges(id),
+  CONSTRAINT fk_chain_id FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
+
+CREATE TABLE liquidity_pools
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  pair_address VARCHAR(255) NULL,
+  chain_id INT NULL,
+  exchange_id INT NULL,
+  liquidity_pool_type_id INT NULL,
+  token0_id INT NULL,
+  token1_id INT NULL,
+  url VARCHAR(255) NULL,
+  start_block INT NULL,
+  latest_block_synced INT NULL,
+  created_txn_hash VARCHAR(255) NULL,
+  is_active BOOLEAN NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  base_asset_id INT NULL,
+  quote_asset_id INT NULL,
+  quote_asset_chainlink_address_usd VARCHAR(255) NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_chain_id FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_exchange_id FOREIGN KEY(exchange_id) REFERENCES exchanges(id),
+  CONSTRAINT fk_liquidity_pool_type_id FOREIGN KEY(liquidity_pool_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_token0_id FOREIGN KEY(token0_id) REFERENCES assets(id),
+  CONSTRAINT fk_token1_id FOREIGN KEY(token1_id) REFERENCES assets(id),
+  CONSTRAINT fk_base_asset_id FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_quote_asset_id FOREIGN KEY(quote_asset_id) REFERENCES assets(id)
+);
+
+CREATE TABLE liquidity_pool_assets
+(
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  liquidity_pool_id INT NOT NULL,
+  asset_id INT NOT NULL,
+  token_number INT NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(liquidity_pool_id, asset_id, token_number),
+  CONSTRAINT fk_liquidity_pool_id FOREIGN KEY(liquidity_pool_id) REFERENCES liquidity_pools(id),
+  CONSTRAINT fk_asset_id FOREIGN KEY(asset_id) REFERENCES assets(id)
+);
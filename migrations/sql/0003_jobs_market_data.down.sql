@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS dex_txn_jobs;
+DROP TABLE IF EXISTS market_data_jobs;
+DROP TABLE IF EXISTS market_data_quotes;
+DROP TABLE IF EXISTS market_data;
+DROP TABLE IF EXISTS source_jobs;
+DROP TABLE IF EXISTS jobs;
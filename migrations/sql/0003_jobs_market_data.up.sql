@@ -0,0 +1,169 @@
+CREATE TABLE jobs
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  response_status TEXT NULL,
+  request_url TEXT NULL,
+  request_body TEXT NULL,
+  request_method TEXT NULL,
+  response_data TEXT NULL,
+  response_data_json jsonb NULL,
+  job_category_id INT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_status_id FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_job_category_id FOREIGN KEY(job_category_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE source_jobs
+(
+  source_id INT NOT NULL,
+  job_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(source_id, job_id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id),
+  CONSTRAINT fk_jobs FOREIGN KEY(job_id) REFERENCES jobs(id)
+);
+
+CREATE TABLE market_data
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  asset_id INT NOT NULL,
+  open_usd NUMERIC NULL,
+  close_usd NUMERIC NULL,
+  high_usd NUMERIC NULL,
+  low_usd NUMERIC NULL,
+  price_usd NUMERIC NULL,
+  volume_usd NUMERIC NULL,
+  market_cap_usd NUMERIC NULL,
+  ticker VARCHAR(255) NULL,
+  description TEXT NULL,
+  interval_id INT NOT NULL,
+  market_data_type_id INT NOT NULL,
+  source_id INT NOT NULL,
+  total_supply NUMERIC NULL,
+  max_supply NUMERIC NULL,
+  circulating_supply NUMERIC NULL,
+  sparkline_7d NUMERIC[] NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_asset FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_structured_value_interval FOREIGN KEY(interval_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id),
+  CONSTRAINT fk_structured_value_market_data_type FOREIGN KEY(market_data_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE market_data_quotes
+(
+  market_data_id INT NOT NULL,
+  base_asset_id INT NOT NULL,
+  quote_asset_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  open NUMERIC NULL,
+  close NUMERIC NULL,
+  high_24h NUMERIC NULL,
+  low_24h NUMERIC NULL,
+  price NUMERIC NULL,
+  volume NUMERIC NULL,
+  market_cap NUMERIC NULL,
+  ticker VARCHAR(255) NULL,
+  description TEXT NULL,
+  source_id INT NOT NULL,
+  fully_diluted_valution NUMERIC NULL,
+  ath NUMERIC NULL,
+  ath_date date NULL,
+  atl NUMERIC NULL,
+  atl_date date NULL,
+  price_change_1h NUMERIC NULL,
+  price_change_24h NUMERIC NULL,
+  price_change_7d NUMERIC NULL,
+  price_change_30d NUMERIC NULL,
+  price_change_60d NUMERIC NULL,
+  price_change_200d NUMERIC NULL,
+  price_change_1y NUMERIC NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(market_data_id, base_asset_id, quote_asset_id),
+  CONSTRAINT fk_market_data FOREIGN KEY(market_data_id) REFERENCES market_data(id),
+  CONSTRAINT fk_base_asset_id FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_quote_asset_id FOREIGN KEY(quote_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id)
+);
+
+CREATE TABLE market_data_jobs
+(
+  market_data_id INT NOT NULL,
+  job_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  response_status TEXT NULL,
+  request_url TEXT NULL,
+  request_body TEXT NULL,
+  request_method TEXT NULL,
+  response_data TEXT NULL,
+  response_data_json jsonb NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(market_data_id, job_id),
+  CONSTRAINT fk_market_data FOREIGN KEY(market_data_id) REFERENCES market_data(id),
+  CONSTRAINT fk_jobs FOREIGN KEY(job_id) REFERENCES jobs(id),
+  CONSTRAINT fk_status_id FOREIGN KEY(status_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE dex_txn_jobs
+(
+  id SERIAL,
+  job_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  chain_id INT NULL,
+  exchange_id INT NULL,
+  transaction_hashes VARCHAR[] NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_jobs FOREIGN KEY(job_id) REFERENCES jobs(id),
+  CONSTRAINT fk_status_id FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_chains FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_exchanges FOREIGN KEY(exchange_id) REFERENCES exchanges(id)
+);
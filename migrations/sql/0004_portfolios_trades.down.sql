@@ -0,0 +1,7 @@
+DROP VIEW IF EXISTS get_default_quotes;
+DROP VIEW IF EXISTS get_current_assets;
+DROP TABLE IF EXISTS trades;
+DROP TABLE IF EXISTS position_jobs;
+DROP TABLE IF EXISTS positions;
+DROP TABLE IF EXISTS accounts;
+DROP TABLE IF EXISTS portfolios;
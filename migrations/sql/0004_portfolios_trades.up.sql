@@ -0,0 +1,170 @@
+CREATE TABLE portfolios
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  user_email VARCHAR(255) NOT NULL,
+  description TEXT NULL,
+  base_asset_id INT NOT NULL,
+  portfolio_type_id INT NOT NULL,
+  parent_id INT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_asset_base_asset_id FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_portfolio_parent_id FOREIGN KEY(parent_id) REFERENCES portfolios(id),
+  CONSTRAINT fk_structured_value_portfolio_type FOREIGN KEY(portfolio_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE accounts
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  address VARCHAR(255) NULL,
+  name_from_source VARCHAR(255) NULL,
+  portfolio_id INT NULL,
+  source_id INT NULL,
+  account_type_id INT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  chain_id INT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id),
+  CONSTRAINT fk_portfolios FOREIGN KEY(portfolio_id) REFERENCES portfolios(id),
+  CONSTRAINT fk_account_type FOREIGN KEY(account_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
+
+CREATE TABLE positions
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  account_id INT NULL,
+  portfolio_id INT NULL,
+  frequency_id INT NOT NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  base_asset_id INT NOT NULL,
+  quote_asset_id INT NOT NULL,
+  quantity NUMERIC NULL,
+  cost_basis NUMERIC NULL,
+  profit NUMERIC NULL,
+  total_amount NUMERIC NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_asset_base_asset_id FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_asset_quote_asset_id FOREIGN KEY(quote_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_structured_value_position_frequency FOREIGN KEY(frequency_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE position_jobs
+(
+  position_id INT NOT NULL,
+  job_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  response_status TEXT NULL,
+  request_url TEXT NULL,
+  request_body TEXT NULL,
+  request_method TEXT NULL,
+  response_data TEXT NULL,
+  response_data_json jsonb NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(position_id, job_id),
+  CONSTRAINT fk_position FOREIGN KEY(position_id) REFERENCES positions(id),
+  CONSTRAINT fk_jobs FOREIGN KEY(job_id) REFERENCES jobs(id),
+  CONSTRAINT fk_status_id FOREIGN KEY(status_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE trades
+(
+  id SERIAL,
+  parent_trade_id INT NULL,
+  from_account_id INT NULL,
+  to_account_id INT NULL,
+  asset_id INT NOT NULL,
+  source_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  transaction_id VARCHAR(4000) NOT NULL,
+  order_id VARCHAR(255) NULL,
+  trade_id VARCHAR(255) NULL,
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  trade_type_id INT NULL,
+  trade_date timestamp NOT NULL,
+  settle_date timestamp NULL,
+  transfer_date timestamp NULL,
+  from_quantity NUMERIC NULL,
+  to_quantity NUMERIC NULL,
+  price NUMERIC NULL,
+  total_amount NUMERIC NULL,
+  fees_amount NUMERIC NULL,
+  fees_asset_id INT NULL,
+  realized_return_amount NUMERIC NULL,
+  realized_return_asset_id INT NULL,
+  cost_basis_amount NUMERIC NULL,
+  cost_basis_trade_id INT NULL,
+  description TEXT NULL,
+  is_active BOOLEAN NOT NULL,
+  source_data jsonb NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_parent_trade FOREIGN KEY(parent_trade_id) REFERENCES trades(id),
+  CONSTRAINT fk_from_account FOREIGN KEY(from_account_id) REFERENCES accounts(id),
+  CONSTRAINT fk_from_to_account FOREIGN KEY(to_account_id) REFERENCES accounts(id),
+  CONSTRAINT fk_asset FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id),
+  CONSTRAINT fk_trade_type FOREIGN KEY(trade_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_fees_asset FOREIGN KEY(fees_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_realized_return_asset FOREIGN KEY(realized_return_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_cost_basis_trade FOREIGN KEY(cost_basis_trade_id) REFERENCES trades(id)
+);
+
+-- assets held in active trades, including the legs of pair assets
+CREATE VIEW get_current_assets AS
+SELECT assets.*
+  FROM assets
+  WHERE assets.id IN (SELECT DISTINCT assets_1.id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.base_asset_id IS NULL AND assets_1.quote_asset_id IS NULL)
+    OR assets.id IN (SELECT DISTINCT assets_1.base_asset_id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.base_asset_id IS NOT NULL)
+    OR assets.id IN (SELECT DISTINCT assets_1.quote_asset_id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.quote_asset_id IS NOT NULL);
+
+CREATE VIEW get_default_quotes AS
+SELECT assets.*
+  FROM assets
+  WHERE assets.is_default_quote = true;
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS transaction_steps;
+DROP TABLE IF EXISTS transaction_jobs;
+DROP TABLE IF EXISTS transaction_assets;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS step_assets;
+DROP TABLE IF EXISTS steps;
+DROP TABLE IF EXISTS pools;
+DROP TABLE IF EXISTS strategy_market_data_assets;
+DROP TABLE IF EXISTS strategy_jobs;
+DROP TABLE IF EXISTS strategies;
@@ -0,0 +1,237 @@
+CREATE TABLE strategies
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  description TEXT NULL,
+  strategy_type_id INT NOT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_strategy_type_id FOREIGN KEY(strategy_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE strategy_jobs
+(
+  strategy_id INT NOT NULL,
+  job_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  response_status TEXT NULL,
+  request_url TEXT NULL,
+  request_body TEXT NULL,
+  request_method TEXT NULL,
+  response_data TEXT NULL,
+  response_data_json jsonb NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(strategy_id, job_id),
+  CONSTRAINT fk_strategy FOREIGN KEY(strategy_id) REFERENCES strategies(id),
+  CONSTRAINT fk_jobs FOREIGN KEY(job_id) REFERENCES jobs(id),
+  CONSTRAINT fk_status_id FOREIGN KEY(status_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE strategy_market_data_assets
+(
+  id SERIAL,
+  strategy_id INT NOT NULL,
+  base_asset_id INT NOT NULL,
+  quote_asset_id INT NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  ticker VARCHAR(255) NULL,
+  description TEXT NULL,
+  source_id INT NOT NULL,
+  frequency_id INT NOT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_strategy_id FOREIGN KEY(strategy_id) REFERENCES strategies(id),
+  CONSTRAINT fk_base_asset_id FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_quote_asset_id FOREIGN KEY(quote_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id),
+  CONSTRAINT fk_frequency_id FOREIGN KEY(frequency_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE pools
+(
+  id SERIAL,
+  target_asset_id INT NOT NULL,
+  strategy_id INT NOT NULL,
+  account_id INT NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  description TEXT NULL,
+  chain_id INT NOT NULL,
+  frequency_id INT NOT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_target_asset_id FOREIGN KEY(target_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_strategy_id FOREIGN KEY(strategy_id) REFERENCES strategies(id),
+  CONSTRAINT fk_account_id FOREIGN KEY(account_id) REFERENCES accounts(id),
+  CONSTRAINT fk_frequency_id FOREIGN KEY(frequency_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
+
+CREATE TABLE steps
+(
+  id SERIAL,
+  pool_id INT NOT NULL,
+  parent_step_id INT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  description TEXT NULL,
+  action_type_id INT NULL,
+  function_name VARCHAR(255) NOT NULL,
+  step_order INT NOT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_pool_id FOREIGN KEY(pool_id) REFERENCES pools(id),
+  CONSTRAINT fk_parent_step_id FOREIGN KEY(parent_step_id) REFERENCES steps(id),
+  CONSTRAINT fk_action_type_id FOREIGN KEY(action_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE step_assets
+(
+  id SERIAL,
+  step_id INT NOT NULL,
+  asset_id INT NOT NULL,
+  swap_asset_id INT NULL,
+  target_pool_id INT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  description TEXT NULL,
+  action_parameter NUMERIC NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_asset_id FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_step_id FOREIGN KEY(step_id) REFERENCES steps(id),
+  CONSTRAINT fk_swap_asset_id FOREIGN KEY(swap_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_pool_id FOREIGN KEY(target_pool_id) REFERENCES pools(id)
+);
+
+CREATE TABLE transactions
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  tx_hash VARCHAR(255) NULL,
+  status_id INT NOT NULL,
+  from_account_id INT NULL,
+  to_account_id INT NULL,
+  chain_id INT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_status_id FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_from_account_id FOREIGN KEY(from_account_id) REFERENCES accounts(id),
+  CONSTRAINT fk_to_account_id FOREIGN KEY(to_account_id) REFERENCES accounts(id),
+  CONSTRAINT fk_chain_id FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
+
+CREATE TABLE transaction_assets
+(
+  transaction_id INT NOT NULL,
+  asset_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  quantity NUMERIC NULL,
+  quantity_usd NUMERIC NULL,
+  market_data_id INT NULL,
+  manual_exchange_rate_usd NUMERIC NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(transaction_id, asset_id),
+  CONSTRAINT fk_transaction FOREIGN KEY(transaction_id) REFERENCES transactions(id),
+  CONSTRAINT fk_asset FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_market_data FOREIGN KEY(market_data_id) REFERENCES market_data(id)
+);
+
+CREATE TABLE transaction_jobs
+(
+  transaction_id INT NOT NULL,
+  job_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  response_status TEXT NULL,
+  request_url TEXT NULL,
+  request_body TEXT NULL,
+  request_method TEXT NULL,
+  response_data TEXT NULL,
+  response_data_json jsonb NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(transaction_id, job_id),
+  CONSTRAINT fk_transaction FOREIGN KEY(transaction_id) REFERENCES transactions(id),
+  CONSTRAINT fk_jobs FOREIGN KEY(job_id) REFERENCES jobs(id),
+  CONSTRAINT fk_status_id FOREIGN KEY(status_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE transaction_steps
+(
+  transaction_id INT NOT NULL,
+  step_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(transaction_id, step_id),
+  CONSTRAINT fk_transaction FOREIGN KEY(transaction_id) REFERENCES transactions(id),
+  CONSTRAINT fk_step FOREIGN KEY(step_id) REFERENCES steps(id)
+);
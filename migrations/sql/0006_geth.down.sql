@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS geth_trade_transfers;
+DROP TABLE IF EXISTS geth_trade_swaps;
+DROP TABLE IF EXISTS geth_trades;
+DROP TABLE IF EXISTS geth_transfers;
+DROP TABLE IF EXISTS geth_swaps;
+DROP TABLE IF EXISTS geth_miners_transactions;
+DROP TABLE IF EXISTS geth_miners_transaction_inputs;
+DROP TABLE IF EXISTS geth_miners;
+DROP TABLE IF EXISTS geth_transactions;
+DROP TABLE IF EXISTS geth_transaction_inputs;
+DROP TABLE IF EXISTS geth_market_data;
+DROP TABLE IF EXISTS geth_process_vlog_jobs;
+DROP TABLE IF EXISTS geth_process_job_topics;
+DROP TABLE IF EXISTS geth_process_jobs;
+DROP TABLE IF EXISTS asset_taxes;
+DROP TABLE IF EXISTS taxes;
+DROP TABLE IF EXISTS geth_addresses;
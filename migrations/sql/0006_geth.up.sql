@@ -0,0 +1,529 @@
+CREATE TABLE geth_addresses
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  address_str VARCHAR(255) UNIQUE NOT NULL,
+  address_type_id INT NOT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_address_type FOREIGN KEY(address_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE taxes
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NULL,
+  end_date timestamp NULL,
+  start_block INT NULL,
+  end_block INT NULL,
+  tax_rate NUMERIC NOT NULL,
+  tax_rate_type_id INT NULL,
+  contract_address_str VARCHAR(255) UNIQUE NULL,
+  contract_address_id INT NULL,
+  tax_type_id INT NOT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_tax_rate_type_id FOREIGN KEY(tax_rate_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_contract_address_id FOREIGN KEY(contract_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_tax_type_id FOREIGN KEY(tax_type_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE asset_taxes
+(
+  tax_id INT NOT NULL,
+  asset_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  tax_rate_override NUMERIC NULL,
+  tax_rate_type_id INT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(tax_id, asset_id),
+  CONSTRAINT fk_tax_rate_type_id FOREIGN KEY(tax_rate_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_tax FOREIGN KEY(tax_id) REFERENCES taxes(id),
+  CONSTRAINT fk_asset FOREIGN KEY(asset_id) REFERENCES assets(id)
+);
+
+CREATE TABLE geth_process_jobs
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  job_category_id INT NULL,
+  import_type_id INT NULL,
+  chain_id INT NULL,
+  start_block_number INT NULL,
+  end_block_number INT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  asset_id INT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_statuses FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_job_categories FOREIGN KEY(job_category_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_import_type FOREIGN KEY(import_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_chains FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
+
+CREATE INDEX geth_process_jobs_asset_id ON geth_process_jobs(asset_id);
+CREATE INDEX geth_process_jobs_start_date ON geth_process_jobs(start_date);
+CREATE INDEX geth_process_jobs_end_date ON geth_process_jobs(end_date);
+CREATE INDEX geth_process_jobs_status_id ON geth_process_jobs(status_id);
+CREATE INDEX geth_process_jobs_import_type_id ON geth_process_jobs(import_type_id);
+
+CREATE TABLE geth_process_job_topics
+(
+  id SERIAL,
+  geth_process_job_id INT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  topic_str TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_geth_process_jobs FOREIGN KEY(geth_process_job_id) REFERENCES geth_process_jobs(id),
+  CONSTRAINT fk_statuses FOREIGN KEY(status_id) REFERENCES structured_values(id)
+);
+
+CREATE INDEX geth_process_job_topics_geth_process_job_id ON geth_process_job_topics(geth_process_job_id);
+CREATE INDEX geth_process_job_topics_topic_str ON geth_process_job_topics(topic_str);
+
+CREATE TABLE geth_process_vlog_jobs
+(
+  id SERIAL,
+  geth_process_job_id INT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  description TEXT NULL,
+  status_id INT NOT NULL,
+  job_category_id INT NULL,
+  asset_id INT NULL,
+  chain_id INT NULL,
+  txn_hash VARCHAR(255) NULL,
+  address_id INT NULL,
+  block_number NUMERIC NULL,
+  index_number NUMERIC NULL,
+  topics_str TEXT[] NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_geth_process_jobs FOREIGN KEY(geth_process_job_id) REFERENCES geth_process_jobs(id),
+  CONSTRAINT fk_statuses FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_job_categories FOREIGN KEY(job_category_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_assets FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_chains FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_addresses FOREIGN KEY(address_id) REFERENCES geth_addresses(id)
+);
+
+CREATE TABLE geth_market_data
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  asset_id INT NOT NULL,
+  open_usd NUMERIC NULL,
+  close_usd NUMERIC NULL,
+  high_usd NUMERIC NULL,
+  low_usd NUMERIC NULL,
+  price_usd NUMERIC NULL,
+  volume_usd NUMERIC NULL,
+  market_cap_usd NUMERIC NULL,
+  ticker VARCHAR(255) NULL,
+  description TEXT NULL,
+  interval_id INT NOT NULL,
+  market_data_type_id INT NOT NULL,
+  source_id INT NOT NULL,
+  total_supply NUMERIC NULL,
+  max_supply NUMERIC NULL,
+  circulating_supply NUMERIC NULL,
+  sparkline_7d NUMERIC[] NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  geth_process_job_id INT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_asset FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_structured_value_interval FOREIGN KEY(interval_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_source FOREIGN KEY(source_id) REFERENCES sources(id),
+  CONSTRAINT fk_structured_value_market_data_type FOREIGN KEY(market_data_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_geth_process_jobs FOREIGN KEY(geth_process_job_id) REFERENCES geth_process_jobs(id)
+);
+
+CREATE INDEX geth_market_data_asset_id ON geth_market_data(asset_id);
+CREATE INDEX geth_market_data_market_data_type_id ON geth_market_data(market_data_type_id);
+CREATE INDEX geth_market_data_start_date ON geth_market_data(start_date);
+CREATE INDEX geth_market_data_interval ON geth_market_data(interval_id);
+CREATE INDEX geth_market_data_source ON geth_market_data(source_id);
+CREATE INDEX geth_market_data_geth_process_job ON geth_market_data(geth_process_job_id);
+
+CREATE TABLE geth_transaction_inputs
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  function_name VARCHAR(255) NULL,
+  method_id_str VARCHAR(255) NULL,
+  num_of_parameters INT NOT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id)
+);
+
+CREATE TABLE geth_transactions
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  chain_id INT NOT NULL,
+  exchange_id INT NULL,
+  block_number NUMERIC NULL,
+  index_number NUMERIC NULL,
+  txn_date timestamp NULL,
+  txn_hash VARCHAR(255) NOT NULL,
+  from_address VARCHAR(255) NOT NULL,
+  from_address_id INT NULL,
+  to_address VARCHAR(255) NOT NULL,
+  to_address_id INT NULL,
+  interacted_contract_address VARCHAR(255) NULL,
+  interacted_contract_address_id INT NULL,
+  native_asset_id INT NOT NULL,
+  geth_process_job_id INT NULL,
+  value NUMERIC NULL,
+  geth_transaction_input_id INT NULL,
+  status_id INT NOT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_exchange FOREIGN KEY(exchange_id) REFERENCES exchanges(id),
+  CONSTRAINT fk_from_address FOREIGN KEY(from_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_to_address FOREIGN KEY(to_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_interacted_contract_address FOREIGN KEY(interacted_contract_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_native_asset FOREIGN KEY(native_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_geth_process_jobs FOREIGN KEY(geth_process_job_id) REFERENCES geth_process_jobs(id),
+  CONSTRAINT fk_geth_transaction_input FOREIGN KEY(geth_transaction_input_id) REFERENCES geth_transaction_inputs(id),
+  CONSTRAINT fk_statuses FOREIGN KEY(status_id) REFERENCES structured_values(id)
+);
+
+CREATE TABLE geth_miners
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  chain_id INT NOT NULL,
+  exchange_id INT NULL,
+  starting_block_number NUMERIC NULL,
+  created_txn_hash VARCHAR(255) NOT NULL,
+  last_block_number NUMERIC NULL,
+  contract_address VARCHAR(255) NOT NULL,
+  contract_address_id INT NULL,
+  developer_address VARCHAR(255) NOT NULL,
+  developer_address_id INT NULL,
+  mining_asset_id INT NOT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_exchange FOREIGN KEY(exchange_id) REFERENCES exchanges(id),
+  CONSTRAINT fk_contract_address FOREIGN KEY(contract_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_developer_address FOREIGN KEY(developer_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_mining_asset FOREIGN KEY(mining_asset_id) REFERENCES assets(id)
+);
+
+CREATE INDEX geth_miners_contract_address_id ON geth_miners(contract_address_id);
+CREATE INDEX geth_miners_developer_address_id ON geth_miners(developer_address_id);
+CREATE INDEX geth_miners_mining_asset ON geth_miners(mining_asset_id);
+CREATE INDEX geth_miners_fk_chain ON geth_miners(chain_id);
+
+CREATE TABLE geth_miners_transaction_inputs
+(
+  miner_id INT NOT NULL,
+  transaction_input_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(miner_id, transaction_input_id),
+  CONSTRAINT fk_miner_id FOREIGN KEY(miner_id) REFERENCES geth_miners(id),
+  CONSTRAINT fk_transaction_input_id FOREIGN KEY(transaction_input_id) REFERENCES geth_transaction_inputs(id)
+);
+
+CREATE INDEX geth_miners_transaction_inputs_miner_id ON geth_miners_transaction_inputs(miner_id);
+CREATE INDEX geth_miners_transaction_inputs_transaction_input_id ON geth_miners_transaction_inputs(transaction_input_id);
+
+CREATE TABLE geth_miners_transactions
+(
+  miner_id INT NOT NULL,
+  transaction_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(miner_id, transaction_id),
+  CONSTRAINT fk_miner_id FOREIGN KEY(miner_id) REFERENCES geth_miners(id),
+  CONSTRAINT fk_transaction_id FOREIGN KEY(transaction_id) REFERENCES geth_transactions(id)
+);
+
+CREATE INDEX geth_miners_transactions_miner_id ON geth_miners_transactions(miner_id);
+CREATE INDEX geth_miners_transactions_transaction_id ON geth_miners_transactions(transaction_id);
+
+CREATE TABLE geth_swaps
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  chain_id INT NOT NULL,
+  exchange_id INT NULL,
+  block_number NUMERIC NULL,
+  index_number NUMERIC NULL,
+  swap_date timestamp NULL,
+  trade_type_id INT NULL,
+  txn_hash VARCHAR(255) NOT NULL,
+  maker_address VARCHAR(255) NOT NULL,
+  maker_address_id INT NULL,
+  is_buy BOOLEAN NULL,
+  price NUMERIC NULL,
+  price_usd NUMERIC NULL,
+  token1_price_usd NUMERIC NULL,
+  total_amount_usd NUMERIC NULL,
+  pair_address VARCHAR(255) NULL,
+  liquidity_pool_id INT NULL,
+  token0_asset_id INT NULL,
+  token1_asset_id INT NULL,
+  token0_amount NUMERIC NULL,
+  token1_amount NUMERIC NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  geth_process_job_id INT NULL,
+  topics_str TEXT[] NULL,
+  status_id INT NOT NULL,
+  base_asset_id INT NOT NULL,
+  oracle_price_usd NUMERIC NULL,
+  oracle_price_asset_id INT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_exchange FOREIGN KEY(exchange_id) REFERENCES exchanges(id),
+  CONSTRAINT fk_trade_type FOREIGN KEY(trade_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_liquidity_pool FOREIGN KEY(liquidity_pool_id) REFERENCES liquidity_pools(id),
+  CONSTRAINT fk_maker_address FOREIGN KEY(maker_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_geth_process_jobs FOREIGN KEY(geth_process_job_id) REFERENCES geth_process_jobs(id),
+  CONSTRAINT fk_statuses FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_base_asset FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_oracle_price_asset FOREIGN KEY(oracle_price_asset_id) REFERENCES assets(id)
+);
+
+CREATE INDEX geth_swaps_maker_address_id ON geth_swaps(maker_address_id);
+CREATE INDEX geth_swaps_txn_hash ON geth_swaps(txn_hash);
+CREATE INDEX geth_swaps_maker_address ON geth_swaps(maker_address);
+CREATE INDEX geth_swaps_token0_asset_id ON geth_swaps(token0_asset_id);
+CREATE INDEX geth_swaps_fk_chain ON geth_swaps(chain_id);
+CREATE INDEX geth_swaps_fk_exchange ON geth_swaps(exchange_id);
+CREATE INDEX geth_swaps_fk_trade_type ON geth_swaps(trade_type_id);
+CREATE INDEX geth_swaps_fk_liquidity_pool ON geth_swaps(liquidity_pool_id);
+CREATE INDEX geth_swaps_fk_statuses ON geth_swaps(status_id);
+CREATE INDEX geth_swaps_fk_base_asset ON geth_swaps(base_asset_id);
+CREATE INDEX geth_swaps_fk_oracle_price_asset ON geth_swaps(oracle_price_asset_id);
+CREATE UNIQUE INDEX geth_swaps_natural_key ON geth_swaps(chain_id, txn_hash, block_number, index_number);
+
+CREATE TABLE geth_transfers
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  chain_id INT NOT NULL,
+  token_address VARCHAR(255) NULL,
+  token_address_id INT NULL,
+  asset_id INT NULL,
+  block_number NUMERIC NULL,
+  index_number NUMERIC NULL,
+  transfer_date timestamp NULL,
+  txn_hash VARCHAR(70) NOT NULL,
+  sender_address VARCHAR(255) NOT NULL,
+  sender_address_id INT NULL,
+  to_address VARCHAR(255) NOT NULL,
+  to_address_id INT NULL,
+  amount NUMERIC NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  geth_process_job_id INT NULL,
+  topics_str TEXT[] NULL,
+  status_id INT NOT NULL,
+  base_asset_id INT NOT NULL,
+  transfer_type_id INT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_chains FOREIGN KEY(chain_id) REFERENCES chains(id),
+  CONSTRAINT fk_assets FOREIGN KEY(asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_token_address FOREIGN KEY(token_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_sender_address FOREIGN KEY(sender_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_to_address FOREIGN KEY(to_address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_geth_process_jobs FOREIGN KEY(geth_process_job_id) REFERENCES geth_process_jobs(id),
+  CONSTRAINT fk_statuses FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_base_asset FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_transfer_types FOREIGN KEY(transfer_type_id) REFERENCES structured_values(id)
+);
+
+CREATE INDEX geth_transfers_to_address_id ON geth_transfers(to_address_id);
+CREATE INDEX geth_transfers_sender_address_id ON geth_transfers(sender_address_id);
+CREATE INDEX geth_transfers_to_address ON geth_transfers(to_address);
+CREATE INDEX geth_transfers_sender_address ON geth_transfers(sender_address);
+CREATE INDEX geth_transfers_asset_id ON geth_transfers(asset_id);
+CREATE INDEX geth_transfers_base_asset_id ON geth_transfers(base_asset_id);
+CREATE INDEX geth_transfers_chain_id ON geth_transfers(chain_id);
+CREATE INDEX geth_transfers_block_number ON geth_transfers(block_number);
+CREATE INDEX geth_transfers_transfer_date ON geth_transfers(transfer_date);
+CREATE INDEX geth_transfers_txn_hash ON geth_transfers(txn_hash);
+CREATE INDEX geth_transfers_fk_geth_process_jobs ON geth_transfers(geth_process_job_id);
+CREATE INDEX geth_transfers_fk_transfer_types ON geth_transfers(transfer_type_id);
+CREATE UNIQUE INDEX geth_transfers_natural_key ON geth_transfers(chain_id, txn_hash, block_number, index_number);
+
+CREATE TABLE geth_trades
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  address_str VARCHAR(255) NOT NULL,
+  address_id INT NOT NULL,
+  trade_date timestamp NULL,
+  txn_hash VARCHAR(255) NULL,
+  is_buy BOOLEAN NULL,
+  token0_amount NUMERIC NULL,
+  token0_amount_decimal_adj NUMERIC NULL,
+  token1_amount NUMERIC NULL,
+  token1_amount_decimal_adj NUMERIC NULL,
+  price NUMERIC NULL,
+  price_usd NUMERIC NULL,
+  lp_token1_price_usd NUMERIC NULL,
+  total_amount_usd NUMERIC NULL,
+  token0_asset_id INT NOT NULL,
+  token1_asset_id INT NULL,
+  geth_process_job_id INT NULL,
+  status_id INT NOT NULL,
+  trade_type_id INT NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  base_asset_id INT NOT NULL,
+  oracle_price_usd NUMERIC NULL,
+  oracle_price_asset_id INT NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT fk_address FOREIGN KEY(address_id) REFERENCES geth_addresses(id),
+  CONSTRAINT fk_token0_asset FOREIGN KEY(token0_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_token1_asset FOREIGN KEY(token1_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_geth_process_jobs FOREIGN KEY(geth_process_job_id) REFERENCES geth_process_jobs(id),
+  CONSTRAINT fk_status FOREIGN KEY(status_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_trade_type FOREIGN KEY(trade_type_id) REFERENCES structured_values(id),
+  CONSTRAINT fk_base_asset FOREIGN KEY(base_asset_id) REFERENCES assets(id),
+  CONSTRAINT fk_oracle_price_asset FOREIGN KEY(oracle_price_asset_id) REFERENCES assets(id)
+);
+
+CREATE INDEX geth_trades_address_str ON geth_trades(address_str);
+CREATE INDEX geth_trades_address_id ON geth_trades(address_id);
+CREATE INDEX geth_trades_txn_hash ON geth_trades(txn_hash);
+CREATE INDEX geth_trades_fk_geth_process_jobs ON geth_trades(geth_process_job_id);
+CREATE INDEX geth_trades_fk_statuses ON geth_trades(status_id);
+CREATE INDEX geth_trades_fk_base_asset ON geth_trades(base_asset_id);
+CREATE INDEX geth_trades_fk_oracle_price_asset ON geth_trades(oracle_price_asset_id);
+
+CREATE TABLE geth_trade_swaps
+(
+  geth_trade_id INT NOT NULL,
+  geth_swap_id INT NOT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(geth_trade_id, geth_swap_id),
+  CONSTRAINT fk_geth_trade FOREIGN KEY(geth_trade_id) REFERENCES geth_trades(id),
+  CONSTRAINT fk_geth_swap FOREIGN KEY(geth_swap_id) REFERENCES geth_swaps(id)
+);
+
+CREATE INDEX geth_trade_swaps_swap_id ON geth_trade_swaps(geth_swap_id);
+CREATE INDEX geth_trade_swaps_trade_id ON geth_trade_swaps(geth_trade_id);
+
+CREATE TABLE geth_trade_transfers
+(
+  geth_trade_id INT NOT NULL,
+  geth_transfer_id INT NOT NULL,
+  tax_id INT NULL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  alternate_name VARCHAR(255) NULL,
+  description TEXT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  created_at timestamp NOT NULL,
+  updated_by VARCHAR(255) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY(geth_trade_id, geth_transfer_id),
+  CONSTRAINT fk_geth_trade FOREIGN KEY(geth_trade_id) REFERENCES geth_trades(id),
+  CONSTRAINT fk_geth_transfers FOREIGN KEY(geth_transfer_id) REFERENCES geth_transfers(id),
+  CONSTRAINT fk_tax FOREIGN KEY(tax_id) REFERENCES taxes(id)
+);
+
+CREATE INDEX geth_trade_transfers_transfer_id ON geth_trade_transfers(geth_transfer_id);
+CREATE INDEX geth_trade_transfers_trade_id ON geth_trade_transfers(geth_trade_id);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS exchanges_audit ON exchanges;
+DROP TRIGGER IF EXISTS chains_audit ON chains;
+DROP TRIGGER IF EXISTS liquidity_pools_audit ON liquidity_pools;
+DROP TRIGGER IF EXISTS asset_taxes_audit ON asset_taxes;
+DROP TRIGGER IF EXISTS taxes_audit ON taxes;
+DROP TRIGGER IF EXISTS assets_audit ON assets;
+DROP FUNCTION IF EXISTS audit_row();
+DROP TABLE IF EXISTS audits;
@@ -0,0 +1,95 @@
+-- Audit trail of the reference tables. Every INSERT, UPDATE and DELETE writes
+-- an audits row from an AFTER trigger, so InsertMany's COPY and hand written
+-- statements are recorded too.
+CREATE TABLE audits
+(
+  id SERIAL,
+  uuid uuid NOT NULL DEFAULT uuid_generate_v4(),
+  table_name VARCHAR(255) NOT NULL,
+  entity_id INT NULL,
+  entity_uuid uuid NULL,
+  audit_type_id INT NOT NULL,
+  operation VARCHAR(10) NOT NULL,
+  actor VARCHAR(255) NOT NULL,
+  before_values JSONB NULL,
+  after_values JSONB NULL,
+  created_at timestamp NOT NULL DEFAULT (current_timestamp at time zone 'UTC'),
+  PRIMARY KEY(id)
+);
+
+CREATE INDEX audits_entity_id ON audits(table_name, entity_id);
+CREATE INDEX audits_entity_uuid ON audits(table_name, entity_uuid);
+
+-- audit_row(create_audit_type_id, update_audit_type_id) records the changed
+-- columns of a row. Inserts store the new row in after_values, deletes the old
+-- row in before_values and updates only the columns whose value changed.
+-- Deletes use the update audit type; operation tells them apart.
+--
+-- The actor is the lyle.audit_actor setting of the transaction (see
+-- audit.SetActor), falling back to updated_by / created_by of the row and, for
+-- deletes, to the database user.
+CREATE OR REPLACE FUNCTION audit_row() RETURNS trigger AS $$
+DECLARE
+  old_row JSONB;
+  new_row JSONB;
+  before_diff JSONB;
+  after_diff JSONB;
+  audit_actor TEXT;
+  audit_type INT;
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    old_row := to_jsonb(OLD);
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    new_row := to_jsonb(NEW);
+  END IF;
+  audit_actor := NULLIF(current_setting('lyle.audit_actor', true), '');
+  IF TG_OP = 'INSERT' THEN
+    audit_type := TG_ARGV[0]::INT;
+    before_diff := NULL;
+    after_diff := new_row;
+    audit_actor := COALESCE(audit_actor, new_row ->> 'created_by');
+  ELSIF TG_OP = 'UPDATE' THEN
+    audit_type := TG_ARGV[1]::INT;
+    SELECT jsonb_object_agg(o.key, o.value), jsonb_object_agg(o.key, new_row -> o.key)
+      INTO before_diff, after_diff
+      FROM jsonb_each(old_row) o
+      WHERE new_row -> o.key IS DISTINCT FROM o.value;
+    IF before_diff IS NULL THEN
+      RETURN NULL;
+    END IF;
+    audit_actor := COALESCE(audit_actor, new_row ->> 'updated_by');
+  ELSE
+    audit_type := TG_ARGV[1]::INT;
+    before_diff := old_row;
+    after_diff := NULL;
+  END IF;
+  INSERT INTO audits (table_name, entity_id, entity_uuid, audit_type_id, operation, actor, before_values, after_values)
+  VALUES (
+    TG_TABLE_NAME,
+    (COALESCE(new_row, old_row) ->> 'id')::INT,
+    (COALESCE(new_row, old_row) ->> 'uuid')::uuid,
+    audit_type,
+    TG_OP,
+    COALESCE(audit_actor, current_user),
+    before_diff,
+    after_diff
+  );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- 86 and 87 are utils.CREATE_AUDIT_TYPE_STRUCTURED_VALUE_ID and
+-- utils.UPDATE_AUDIT_TYPE_STRUCTURED_VALUE_ID.
+CREATE TRIGGER assets_audit AFTER INSERT OR UPDATE OR DELETE ON assets
+  FOR EACH ROW EXECUTE FUNCTION audit_row('86', '87');
+CREATE TRIGGER taxes_audit AFTER INSERT OR UPDATE OR DELETE ON taxes
+  FOR EACH ROW EXECUTE FUNCTION audit_row('86', '87');
+CREATE TRIGGER asset_taxes_audit AFTER INSERT OR UPDATE OR DELETE ON asset_taxes
+  FOR EACH ROW EXECUTE FUNCTION audit_row('86', '87');
+CREATE TRIGGER liquidity_pools_audit AFTER INSERT OR UPDATE OR DELETE ON liquidity_pools
+  FOR EACH ROW EXECUTE FUNCTION audit_row('86', '87');
+CREATE TRIGGER chains_audit AFTER INSERT OR UPDATE OR DELETE ON chains
+  FOR EACH ROW EXECUTE FUNCTION audit_row('86', '87');
+CREATE TRIGGER exchanges_audit AFTER INSERT OR UPDATE OR DELETE ON exchanges
+  FOR EACH ROW EXECUTE FUNCTION audit_row('86', '87');
@@ -0,0 +1,28 @@
+DROP VIEW IF EXISTS get_default_quotes;
+DROP VIEW IF EXISTS get_current_assets;
+
+ALTER TABLE liquidity_pools DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE exchanges DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE chains DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE assets DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+
+CREATE VIEW get_current_assets AS
+SELECT assets.*
+  FROM assets
+  WHERE assets.id IN (SELECT DISTINCT assets_1.id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.base_asset_id IS NULL AND assets_1.quote_asset_id IS NULL)
+    OR assets.id IN (SELECT DISTINCT assets_1.base_asset_id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.base_asset_id IS NOT NULL)
+    OR assets.id IN (SELECT DISTINCT assets_1.quote_asset_id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.quote_asset_id IS NOT NULL);
+
+CREATE VIEW get_default_quotes AS
+SELECT assets.*
+  FROM assets
+  WHERE assets.is_default_quote = true;
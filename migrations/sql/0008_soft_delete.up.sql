@@ -0,0 +1,41 @@
+-- Reference rows are referenced by the on-chain history (geth_swaps,
+-- geth_transfers, ...), so Remove marks them deleted instead of deleting them.
+-- deleted_by is the actor of the transaction, see repository.ActorSetting.
+ALTER TABLE assets
+  ADD COLUMN deleted_at timestamp NULL,
+  ADD COLUMN deleted_by VARCHAR(255) NULL;
+
+ALTER TABLE chains
+  ADD COLUMN deleted_at timestamp NULL,
+  ADD COLUMN deleted_by VARCHAR(255) NULL;
+
+ALTER TABLE exchanges
+  ADD COLUMN deleted_at timestamp NULL,
+  ADD COLUMN deleted_by VARCHAR(255) NULL;
+
+ALTER TABLE liquidity_pools
+  ADD COLUMN deleted_at timestamp NULL,
+  ADD COLUMN deleted_by VARCHAR(255) NULL;
+
+-- the views select assets.*, so they pick up the new columns when replaced
+CREATE OR REPLACE VIEW get_current_assets AS
+SELECT assets.*
+  FROM assets
+  WHERE assets.deleted_at IS NULL
+    AND (assets.id IN (SELECT DISTINCT assets_1.id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.base_asset_id IS NULL AND assets_1.quote_asset_id IS NULL)
+      OR assets.id IN (SELECT DISTINCT assets_1.base_asset_id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.base_asset_id IS NOT NULL)
+      OR assets.id IN (SELECT DISTINCT assets_1.quote_asset_id
+      FROM trades
+      LEFT JOIN assets assets_1 ON trades.asset_id = assets_1.id
+      WHERE trades.is_active = true AND assets_1.quote_asset_id IS NOT NULL));
+
+CREATE OR REPLACE VIEW get_default_quotes AS
+SELECT assets.*
+  FROM assets
+  WHERE assets.is_default_quote = true AND assets.deleted_at IS NULL;
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS jobs_notify ON jobs;
+DROP TRIGGER IF EXISTS market_data_notify ON market_data;
+DROP TRIGGER IF EXISTS geth_trades_notify ON geth_trades;
+DROP TRIGGER IF EXISTS geth_transfers_notify ON geth_transfers;
+DROP TRIGGER IF EXISTS geth_swaps_notify ON geth_swaps;
+DROP FUNCTION IF EXISTS notify_change();
+DROP TABLE IF EXISTS change_events;
@@ -0,0 +1,66 @@
+-- Change events of the on-chain tables. notify_change() keeps every INSERT,
+-- UPDATE and DELETE in change_events and announces it on the lyle_changes
+-- channel, so events.Subscriber can replay what it missed while disconnected.
+CREATE TABLE change_events
+(
+  id BIGSERIAL,
+  entity VARCHAR(255) NOT NULL,
+  operation VARCHAR(10) NOT NULL,
+  entity_id INT NULL,
+  entity_uuid uuid NULL,
+  base_asset_id INT NULL,
+  block_number NUMERIC NULL,
+  created_at timestamp NOT NULL DEFAULT (current_timestamp at time zone 'UTC'),
+  PRIMARY KEY(id)
+);
+
+CREATE INDEX change_events_created_at ON change_events(created_at);
+
+-- notify_change(base_asset_column) records the changed row. The optional
+-- argument names the column holding the base asset (asset_id for
+-- market_data); block_number is taken from the row when it has one.
+CREATE OR REPLACE FUNCTION notify_change() RETURNS trigger AS $$
+DECLARE
+  changed_row JSONB;
+  event change_events%ROWTYPE;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    changed_row := to_jsonb(OLD);
+  ELSE
+    changed_row := to_jsonb(NEW);
+  END IF;
+  INSERT INTO change_events (entity, operation, entity_id, entity_uuid, base_asset_id, block_number)
+  VALUES (
+    TG_TABLE_NAME,
+    TG_OP,
+    (changed_row ->> 'id')::INT,
+    (changed_row ->> 'uuid')::uuid,
+    CASE WHEN TG_NARGS > 0 THEN (changed_row ->> TG_ARGV[0])::INT END,
+    (changed_row ->> 'block_number')::NUMERIC
+  )
+  RETURNING * INTO event;
+  -- the keys match the json tags of events.Event
+  PERFORM pg_notify('lyle_changes', json_build_object(
+    'id', event.id,
+    'entity', event.entity,
+    'operation', event.operation,
+    'entityId', event.entity_id,
+    'entityUuid', event.entity_uuid,
+    'baseAssetId', event.base_asset_id,
+    'blockNumber', event.block_number,
+    'createdAt', event.created_at AT TIME ZONE 'UTC'
+  )::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER geth_swaps_notify AFTER INSERT OR UPDATE OR DELETE ON geth_swaps
+  FOR EACH ROW EXECUTE FUNCTION notify_change('base_asset_id');
+CREATE TRIGGER geth_transfers_notify AFTER INSERT OR UPDATE OR DELETE ON geth_transfers
+  FOR EACH ROW EXECUTE FUNCTION notify_change('base_asset_id');
+CREATE TRIGGER geth_trades_notify AFTER INSERT OR UPDATE OR DELETE ON geth_trades
+  FOR EACH ROW EXECUTE FUNCTION notify_change('base_asset_id');
+CREATE TRIGGER market_data_notify AFTER INSERT OR UPDATE OR DELETE ON market_data
+  FOR EACH ROW EXECUTE FUNCTION notify_change('asset_id');
+CREATE TRIGGER jobs_notify AFTER INSERT OR UPDATE OR DELETE ON jobs
+  FOR EACH ROW EXECUTE FUNCTION notify_change();
@@ -0,0 +1,2 @@
+-- merged duplicates are not restored
+DROP INDEX IF EXISTS geth_addresses_lower_address_str;
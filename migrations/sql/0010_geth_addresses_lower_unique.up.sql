@@ -0,0 +1,38 @@
+-- geth_addresses.address_str is unique as written, so the checksummed and the
+-- lower case form of an address could both be inserted. Merge such duplicates
+-- into the oldest row, then enforce uniqueness regardless of case; the index
+-- is also the conflict target of gethlyleaddresses.ResolveGethAddresses.
+CREATE TEMPORARY TABLE geth_address_merges ON COMMIT DROP AS
+SELECT id, keep_id
+  FROM (SELECT id, min(id) OVER (PARTITION BY lower(address_str)) AS keep_id
+    FROM geth_addresses) addresses
+  WHERE id <> keep_id;
+
+UPDATE taxes t SET contract_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.contract_address_id = m.id;
+UPDATE geth_process_vlog_jobs t SET address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.address_id = m.id;
+UPDATE geth_transactions t SET from_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.from_address_id = m.id;
+UPDATE geth_transactions t SET to_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.to_address_id = m.id;
+UPDATE geth_transactions t SET interacted_contract_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.interacted_contract_address_id = m.id;
+UPDATE geth_miners t SET contract_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.contract_address_id = m.id;
+UPDATE geth_miners t SET developer_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.developer_address_id = m.id;
+UPDATE geth_swaps t SET maker_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.maker_address_id = m.id;
+UPDATE geth_transfers t SET token_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.token_address_id = m.id;
+UPDATE geth_transfers t SET sender_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.sender_address_id = m.id;
+UPDATE geth_transfers t SET to_address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.to_address_id = m.id;
+UPDATE geth_trades t SET address_id = m.keep_id
+  FROM geth_address_merges m WHERE t.address_id = m.id;
+
+DELETE FROM geth_addresses a USING geth_address_merges m WHERE a.id = m.id;
+
+CREATE UNIQUE INDEX geth_addresses_lower_address_str ON geth_addresses(lower(address_str));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS geth_blocks;
+
+ALTER TABLE chains
+  DROP COLUMN IF EXISTS confirmation_depth;
@@ -0,0 +1,17 @@
+-- The hashes of indexed blocks, so an indexer can tell the chain was
+-- reorganized under its checkpoint, see gethlyleblocks. Blocks deeper than the
+-- chain's confirmation_depth are final and their rows are pruned.
+ALTER TABLE chains
+  ADD COLUMN confirmation_depth INT NULL;
+
+CREATE TABLE geth_blocks
+(
+  chain_id INT NOT NULL,
+  block_number NUMERIC NOT NULL,
+  block_hash VARCHAR(66) NOT NULL,
+  parent_hash VARCHAR(66) NOT NULL,
+  block_date timestamp NULL,
+  created_at timestamp NOT NULL,
+  PRIMARY KEY(chain_id, block_number),
+  CONSTRAINT fk_chain FOREIGN KEY(chain_id) REFERENCES chains(id)
+);
@@ -0,0 +1,71 @@
+CREATE OR REPLACE FUNCTION audit_row() RETURNS trigger AS $$
+DECLARE
+  old_row JSONB;
+  new_row JSONB;
+  before_diff JSONB;
+  after_diff JSONB;
+  audit_actor TEXT;
+  audit_type INT;
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    old_row := to_jsonb(OLD);
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    new_row := to_jsonb(NEW);
+  END IF;
+  audit_actor := NULLIF(current_setting('lyle.audit_actor', true), '');
+  IF TG_OP = 'INSERT' THEN
+    audit_type := TG_ARGV[0]::INT;
+    before_diff := NULL;
+    after_diff := new_row;
+    audit_actor := COALESCE(audit_actor, new_row ->> 'created_by');
+  ELSIF TG_OP = 'UPDATE' THEN
+    audit_type := TG_ARGV[1]::INT;
+    SELECT jsonb_object_agg(o.key, o.value), jsonb_object_agg(o.key, new_row -> o.key)
+      INTO before_diff, after_diff
+      FROM jsonb_each(old_row) o
+      WHERE new_row -> o.key IS DISTINCT FROM o.value;
+    IF before_diff IS NULL THEN
+      RETURN NULL;
+    END IF;
+    audit_actor := COALESCE(audit_actor, new_row ->> 'updated_by');
+  ELSE
+    audit_type := TG_ARGV[1]::INT;
+    before_diff := old_row;
+    after_diff := NULL;
+  END IF;
+  INSERT INTO audits (table_name, entity_id, entity_uuid, audit_type_id, operation, actor, before_values, after_values)
+  VALUES (
+    TG_TABLE_NAME,
+    (COALESCE(new_row, old_row) ->> 'id')::INT,
+    (COALESCE(new_row, old_row) ->> 'uuid')::uuid,
+    audit_type,
+    TG_OP,
+    COALESCE(audit_actor, current_user),
+    before_diff,
+    after_diff
+  );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The 0007 triggers take the audit type ids as arguments; they are looked up
+-- by name so that the triggers fit this database. The seeded audit types are
+-- kept, audits may refer to them.
+DO $$
+DECLARE
+  audit_table TEXT;
+  create_id INT := audit_type_id('Create');
+  update_id INT := audit_type_id('Update');
+BEGIN
+  FOREACH audit_table IN ARRAY ARRAY['assets', 'taxes', 'asset_taxes', 'liquidity_pools', 'chains', 'exchanges'] LOOP
+    EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', audit_table || '_audit', audit_table);
+    EXECUTE format(
+      'CREATE TRIGGER %I AFTER INSERT OR UPDATE OR DELETE ON %I FOR EACH ROW EXECUTE FUNCTION audit_row(%L, %L)',
+      audit_table || '_audit', audit_table, create_id, update_id
+    );
+  END LOOP;
+END;
+$$;
+
+DROP FUNCTION IF EXISTS audit_type_id(TEXT);
@@ -0,0 +1,108 @@
+-- audit_row() used to take the Create and Update audit type ids as trigger
+-- arguments, which tied the triggers to the ids of one database. The ids are
+-- now looked up by name in structured_values, and deletes get their own
+-- Delete audit type.
+--
+-- The audit types are seeded here when missing, so that a database built by
+-- the migrations alone accepts writes to the audited tables.
+INSERT INTO structured_value_types (name, alternate_name, created_by, created_at, updated_by, updated_at)
+SELECT 'Audit Type', 'Audit Type', 'SYSTEM', current_timestamp at time zone 'UTC', 'SYSTEM', current_timestamp at time zone 'UTC'
+WHERE NOT EXISTS (SELECT 1 FROM structured_value_types WHERE name = 'Audit Type');
+
+INSERT INTO structured_values (name, alternate_name, structured_value_type_id, created_by, created_at, updated_by, updated_at)
+SELECT v.name, v.name, svt.id, 'SYSTEM', current_timestamp at time zone 'UTC', 'SYSTEM', current_timestamp at time zone 'UTC'
+  FROM (VALUES ('Create'), ('Update'), ('Delete')) v(name)
+  CROSS JOIN LATERAL (
+    SELECT id FROM structured_value_types WHERE name = 'Audit Type' ORDER BY id LIMIT 1
+  ) svt
+  WHERE NOT EXISTS (
+    SELECT 1 FROM structured_values sv
+      WHERE sv.structured_value_type_id = svt.id AND sv.name = v.name
+  );
+
+CREATE OR REPLACE FUNCTION audit_type_id(value_name TEXT) RETURNS INT AS $$
+DECLARE
+  type_id INT;
+BEGIN
+  SELECT sv.id INTO type_id
+    FROM structured_values sv
+    JOIN structured_value_types svt ON svt.id = sv.structured_value_type_id
+    WHERE svt.name = 'Audit Type' AND sv.name = value_name
+    ORDER BY sv.id
+    LIMIT 1;
+  IF type_id IS NULL THEN
+    RAISE EXCEPTION 'structured value Audit Type/% not found', value_name;
+  END IF;
+  RETURN type_id;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+CREATE OR REPLACE FUNCTION audit_row() RETURNS trigger AS $$
+DECLARE
+  old_row JSONB;
+  new_row JSONB;
+  before_diff JSONB;
+  after_diff JSONB;
+  audit_actor TEXT;
+  audit_type INT;
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    old_row := to_jsonb(OLD);
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    new_row := to_jsonb(NEW);
+  END IF;
+  audit_actor := NULLIF(current_setting('lyle.audit_actor', true), '');
+  IF TG_OP = 'INSERT' THEN
+    audit_type := audit_type_id('Create');
+    before_diff := NULL;
+    after_diff := new_row;
+    audit_actor := COALESCE(audit_actor, new_row ->> 'created_by');
+  ELSIF TG_OP = 'UPDATE' THEN
+    audit_type := audit_type_id('Update');
+    SELECT jsonb_object_agg(o.key, o.value), jsonb_object_agg(o.key, new_row -> o.key)
+      INTO before_diff, after_diff
+      FROM jsonb_each(old_row) o
+      WHERE new_row -> o.key IS DISTINCT FROM o.value;
+    IF before_diff IS NULL THEN
+      RETURN NULL;
+    END IF;
+    audit_actor := COALESCE(audit_actor, new_row ->> 'updated_by');
+  ELSE
+    audit_type := audit_type_id('Delete');
+    before_diff := old_row;
+    after_diff := NULL;
+  END IF;
+  INSERT INTO audits (table_name, entity_id, entity_uuid, audit_type_id, operation, actor, before_values, after_values)
+  VALUES (
+    TG_TABLE_NAME,
+    (COALESCE(new_row, old_row) ->> 'id')::INT,
+    (COALESCE(new_row, old_row) ->> 'uuid')::uuid,
+    audit_type,
+    TG_OP,
+    COALESCE(audit_actor, current_user),
+    before_diff,
+    after_diff
+  );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS assets_audit ON assets;
+CREATE TRIGGER assets_audit AFTER INSERT OR UPDATE OR DELETE ON assets
+  FOR EACH ROW EXECUTE FUNCTION audit_row();
+DROP TRIGGER IF EXISTS taxes_audit ON taxes;
+CREATE TRIGGER taxes_audit AFTER INSERT OR UPDATE OR DELETE ON taxes
+  FOR EACH ROW EXECUTE FUNCTION audit_row();
+DROP TRIGGER IF EXISTS asset_taxes_audit ON asset_taxes;
+CREATE TRIGGER asset_taxes_audit AFTER INSERT OR UPDATE OR DELETE ON asset_taxes
+  FOR EACH ROW EXECUTE FUNCTION audit_row();
+DROP TRIGGER IF EXISTS liquidity_pools_audit ON liquidity_pools;
+CREATE TRIGGER liquidity_pools_audit AFTER INSERT OR UPDATE OR DELETE ON liquidity_pools
+  FOR EACH ROW EXECUTE FUNCTION audit_row();
+DROP TRIGGER IF EXISTS chains_audit ON chains;
+CREATE TRIGGER chains_audit AFTER INSERT OR UPDATE OR DELETE ON chains
+  FOR EACH ROW EXECUTE FUNCTION audit_row();
+DROP TRIGGER IF EXISTS exchanges_audit ON exchanges;
+CREATE TRIGGER exchanges_audit AFTER INSERT OR UPDATE OR DELETE ON exchanges
+  FOR EACH ROW EXECUTE FUNCTION audit_row();
@@ -0,0 +1,3 @@
+-- fails while transfers of different types share an index number
+DROP INDEX IF EXISTS geth_transfers_natural_key;
+CREATE UNIQUE INDEX geth_transfers_natural_key ON geth_transfers(chain_id, txn_hash, block_number, index_number);
@@ -0,0 +1,6 @@
+-- Native transfers are numbered by the index of their transaction, internal
+-- transfers by their position in the call trace and ERC-20 transfers by their
+-- log index, so the numbers of different transfer types of a transaction
+-- collide. The transfer type is part of the natural key.
+DROP INDEX IF EXISTS geth_transfers_natural_key;
+CREATE UNIQUE INDEX geth_transfers_natural_key ON geth_transfers(chain_id, txn_hash, block_number, transfer_type_id, index_number);
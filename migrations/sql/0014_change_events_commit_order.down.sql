@@ -0,0 +1,39 @@
+CREATE OR REPLACE FUNCTION notify_change() RETURNS trigger AS $$
+DECLARE
+  changed_row JSONB;
+  event change_events%ROWTYPE;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    changed_row := to_jsonb(OLD);
+  ELSE
+    changed_row := to_jsonb(NEW);
+  END IF;
+  INSERT INTO change_events (entity, operation, entity_id, entity_uuid, base_asset_id, block_number)
+  VALUES (
+    TG_TABLE_NAME,
+    TG_OP,
+    (changed_row ->> 'id')::INT,
+    (changed_row ->> 'uuid')::uuid,
+    CASE WHEN TG_NARGS > 0 THEN (changed_row ->> TG_ARGV[0])::INT END,
+    (changed_row ->> 'block_number')::NUMERIC
+  )
+  RETURNING * INTO event;
+  -- the keys match the json tags of events.Event
+  PERFORM pg_notify('lyle_changes', json_build_object(
+    'id', event.id,
+    'entity', event.entity,
+    'operation', event.operation,
+    'entityId', event.entity_id,
+    'entityUuid', event.entity_uuid,
+    'baseAssetId', event.base_asset_id,
+    'blockNumber', event.block_number,
+    'createdAt', event.created_at AT TIME ZONE 'UTC'
+  )::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS change_events_xact_id;
+
+ALTER TABLE change_events
+  DROP COLUMN IF EXISTS xact_id;
@@ -0,0 +1,46 @@
+-- Event ids are taken when the row is written, not when its transaction
+-- commits, so a subscriber reading "id > last seen" skipped the events of
+-- transactions that committed after a later id. Events now record the id of
+-- their transaction; events.GetEventsSince orders by (xact_id, id) and only
+-- returns the events of transactions older than the xmin of its snapshot,
+-- none of which can still be running.
+ALTER TABLE change_events
+  ADD COLUMN xact_id BIGINT NOT NULL DEFAULT (pg_current_xact_id()::text::BIGINT);
+
+CREATE INDEX change_events_xact_id ON change_events(xact_id, id);
+
+CREATE OR REPLACE FUNCTION notify_change() RETURNS trigger AS $$
+DECLARE
+  changed_row JSONB;
+  event change_events%ROWTYPE;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    changed_row := to_jsonb(OLD);
+  ELSE
+    changed_row := to_jsonb(NEW);
+  END IF;
+  INSERT INTO change_events (entity, operation, entity_id, entity_uuid, base_asset_id, block_number)
+  VALUES (
+    TG_TABLE_NAME,
+    TG_OP,
+    (changed_row ->> 'id')::INT,
+    (changed_row ->> 'uuid')::uuid,
+    CASE WHEN TG_NARGS > 0 THEN (changed_row ->> TG_ARGV[0])::INT END,
+    (changed_row ->> 'block_number')::NUMERIC
+  )
+  RETURNING * INTO event;
+  -- the keys match the json tags of events.Event
+  PERFORM pg_notify('lyle_changes', json_build_object(
+    'id', event.id,
+    'xactId', event.xact_id,
+    'entity', event.entity,
+    'operation', event.operation,
+    'entityId', event.entity_id,
+    'entityUuid', event.entity_uuid,
+    'baseAssetId', event.base_asset_id,
+    'blockNumber', event.block_number,
+    'createdAt', event.created_at AT TIME ZONE 'UTC'
+  )::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS geth_transfers_notify_delete ON geth_transfers;
+DROP TRIGGER IF EXISTS geth_transfers_notify_update ON geth_transfers;
+DROP TRIGGER IF EXISTS geth_transfers_notify_insert ON geth_transfers;
+CREATE TRIGGER geth_transfers_notify AFTER INSERT OR UPDATE OR DELETE ON geth_transfers
+  FOR EACH ROW EXECUTE FUNCTION notify_change('base_asset_id');
+
+DROP TRIGGER IF EXISTS geth_swaps_notify_delete ON geth_swaps;
+DROP TRIGGER IF EXISTS geth_swaps_notify_update ON geth_swaps;
+DROP TRIGGER IF EXISTS geth_swaps_notify_insert ON geth_swaps;
+CREATE TRIGGER geth_swaps_notify AFTER INSERT OR UPDATE OR DELETE ON geth_swaps
+  FOR EACH ROW EXECUTE FUNCTION notify_change('base_asset_id');
+
+DROP FUNCTION IF EXISTS notify_changes();
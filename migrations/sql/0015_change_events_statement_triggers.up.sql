@@ -0,0 +1,79 @@
+-- geth_swaps and geth_transfers are written in bulk by COPY and upserts, which
+-- called notify_change() once per row. notify_changes(base_asset_column)
+-- records the rows of a whole statement from its transition tables with one
+-- INSERT, skips updated rows that did not change, and announces only the last
+-- event of each base asset, which is enough for events.Subscriber to catch up.
+-- Transition tables need one trigger per operation.
+CREATE OR REPLACE FUNCTION notify_changes() RETURNS trigger AS $$
+DECLARE
+  changed JSONB;
+  event change_events%ROWTYPE;
+  latest JSONB := '{}';
+  payload JSONB;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    SELECT jsonb_agg(to_jsonb(n)) INTO changed FROM new_rows n;
+  ELSIF TG_OP = 'UPDATE' THEN
+    SELECT jsonb_agg(to_jsonb(n)) INTO changed
+      FROM new_rows n
+      JOIN old_rows o ON o.id = n.id
+      WHERE to_jsonb(n) IS DISTINCT FROM to_jsonb(o);
+  ELSE
+    SELECT jsonb_agg(to_jsonb(o)) INTO changed FROM old_rows o;
+  END IF;
+  IF changed IS NULL THEN
+    RETURN NULL;
+  END IF;
+  FOR event IN
+    INSERT INTO change_events (entity, operation, entity_id, entity_uuid, base_asset_id, block_number)
+    SELECT
+      TG_TABLE_NAME,
+      TG_OP,
+      (c.changed_row ->> 'id')::INT,
+      (c.changed_row ->> 'uuid')::uuid,
+      CASE WHEN TG_NARGS > 0 THEN (c.changed_row ->> TG_ARGV[0])::INT END,
+      (c.changed_row ->> 'block_number')::NUMERIC
+    FROM jsonb_array_elements(changed) AS c(changed_row)
+    RETURNING *
+  LOOP
+    -- the keys match the json tags of events.Event
+    latest := latest || jsonb_build_object(COALESCE(event.base_asset_id::TEXT, ''), jsonb_build_object(
+      'id', event.id,
+      'xactId', event.xact_id,
+      'entity', event.entity,
+      'operation', event.operation,
+      'entityId', event.entity_id,
+      'entityUuid', event.entity_uuid,
+      'baseAssetId', event.base_asset_id,
+      'blockNumber', event.block_number,
+      'createdAt', event.created_at AT TIME ZONE 'UTC'
+    ));
+  END LOOP;
+  FOR payload IN SELECT value FROM jsonb_each(latest) LOOP
+    PERFORM pg_notify('lyle_changes', payload::text);
+  END LOOP;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS geth_swaps_notify ON geth_swaps;
+CREATE TRIGGER geth_swaps_notify_insert AFTER INSERT ON geth_swaps
+  REFERENCING NEW TABLE AS new_rows
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_changes('base_asset_id');
+CREATE TRIGGER geth_swaps_notify_update AFTER UPDATE ON geth_swaps
+  REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_changes('base_asset_id');
+CREATE TRIGGER geth_swaps_notify_delete AFTER DELETE ON geth_swaps
+  REFERENCING OLD TABLE AS old_rows
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_changes('base_asset_id');
+
+DROP TRIGGER IF EXISTS geth_transfers_notify ON geth_transfers;
+CREATE TRIGGER geth_transfers_notify_insert AFTER INSERT ON geth_transfers
+  REFERENCING NEW TABLE AS new_rows
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_changes('base_asset_id');
+CREATE TRIGGER geth_transfers_notify_update AFTER UPDATE ON geth_transfers
+  REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_changes('base_asset_id');
+CREATE TRIGGER geth_transfers_notify_delete AFTER DELETE ON geth_transfers
+  REFERENCING OLD TABLE AS old_rows
+  FOR EACH STATEMENT EXECUTE FUNCTION notify_changes('base_asset_id');
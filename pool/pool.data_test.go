@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -31,23 +33,7 @@ var DBColumns = []string{
 	"updated_by",      //15
 	"updated_at",      //16
 }
-var DBColumnsInsertPools = []string{
-	"target_asset_id", //1
-	"strategy_id",     //2
-	"account_id",      //3
-	"uuid",            //5
-	"name",            //6
-	"alternate_name",  //6
-	"start_date",      //7
-	"end_date",        //8
-	"description",     //9
-	"chain_id",        //10
-	"frequency_id",    //11
-	"created_by",      //12
-	"created_at",      //13
-	"updated_by",      //14
-	"updated_at",      //15
-}
+var DBColumnsInsertPools = PoolRepository.InsertColumns()
 
 var TestData1 = Pool{
 	ID:            utils.Ptr[int](1),                                     //1
@@ -90,28 +76,7 @@ var TestData2 = Pool{
 var TestAllData = []Pool{TestData1, TestData2}
 
 func AddPoolToMockRows(mock pgxmock.PgxPoolIface, dataList []Pool) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.TargetAssetID, //2
-			data.StrategyID,    //3
-			data.AccountID,     //4
-			data.UUID,          //5
-			data.Name,          //6
-			data.AlternateName, //7
-			data.StartDate,     //8
-			data.EndDate,       //9
-			data.Description,   //10
-			data.ChainID,       //11
-			data.FrequencyID,   //12
-			data.CreatedBy,     //13
-			data.CreatedAt,     //14
-			data.UpdatedBy,     //15
-			data.UpdatedAt,     //16
-		)
-	}
-	return rows
+	return PoolRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetPool(t *testing.T) {
@@ -147,8 +112,8 @@ func TestGetPoolForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM pools").WithArgs(poolID).WillReturnRows(noRows)
 	foundPool, err := GetPool(mock, &poolID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetPool", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundPool != nil {
 		t.Errorf("Expected Pool From Method GetPool: to be empty but got this: %v", foundPool)
@@ -265,8 +230,8 @@ func TestGetPools(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddPoolToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM pools").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM pools").WithArgs(ids).WillReturnRows(mockRows)
 	foundPoolList, err := GetPools(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetPools", err)
@@ -287,8 +252,8 @@ func TestGetPoolsForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM pools").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM pools").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundPoolList, err := GetPools(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPools", err)
@@ -308,8 +273,8 @@ func TestGetPoolsForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM pools").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM pools").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundPool, err := GetPools(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPools", err)
@@ -530,16 +495,15 @@ func TestUpdatePool(t *testing.T) {
 		targetData.TargetAssetID, //1
 		targetData.StrategyID,    //2
 		targetData.AccountID,     //3
-		targetData.UUID,          //4
-		targetData.Name,          //5
-		targetData.AlternateName, //6
-		targetData.StartDate,     //7
-		targetData.EndDate,       //8
-		targetData.Description,   //9
-		targetData.ChainID,       //10
-		targetData.FrequencyID,   //11
-		targetData.UpdatedBy,     //12
-		targetData.ID,            //13
+		targetData.Name,          //4
+		targetData.AlternateName, //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.Description,   //8
+		targetData.ChainID,       //9
+		targetData.FrequencyID,   //10
+		targetData.UpdatedBy,     //11
+		targetData.ID,            //12
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdatePool(mock, &targetData)
@@ -597,16 +561,15 @@ func TestUpdatePoolOnFailure(t *testing.T) {
 		targetData.TargetAssetID, //1
 		targetData.StrategyID,    //2
 		targetData.AccountID,     //3
-		targetData.UUID,          //4
-		targetData.Name,          //5
-		targetData.AlternateName, //6
-		targetData.StartDate,     //7
-		targetData.EndDate,       //8
-		targetData.Description,   //9
-		targetData.ChainID,       //10
-		targetData.FrequencyID,   //11
-		targetData.UpdatedBy,     //12
-		targetData.ID,            //13
+		targetData.Name,          //4
+		targetData.AlternateName, //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.Description,   //8
+		targetData.ChainID,       //9
+		targetData.FrequencyID,   //10
+		targetData.UpdatedBy,     //11
+		targetData.ID,            //12
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -631,7 +594,6 @@ func TestInsertPool(t *testing.T) {
 		targetData.TargetAssetID, //1
 		targetData.StrategyID,    //2
 		targetData.AccountID,     //3
-		// &targetData.UUID, //
 		targetData.Name,          //4
 		targetData.AlternateName, //5
 		targetData.StartDate,     //6
@@ -688,7 +650,6 @@ func TestInsertPoolOnFailure(t *testing.T) {
 		targetData.TargetAssetID, //1
 		targetData.StrategyID,    //2
 		targetData.AccountID,     //3
-		// &targetData.UUID, //
 		targetData.Name,          //4
 		targetData.AlternateName, //5
 		targetData.StartDate,     //6
@@ -726,7 +687,6 @@ func TestInsertPoolOnFailureOnCommit(t *testing.T) {
 		targetData.TargetAssetID, //1
 		targetData.StrategyID,    //2
 		targetData.AccountID,     //3
-		// &targetData.UUID, //
 		targetData.Name,          //4
 		targetData.AlternateName, //5
 		targetData.StartDate,     //6
@@ -799,8 +759,8 @@ func TestGetPoolListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"target_asset_id = 1", "strategy_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM pools").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("target_asset_id", "1"), filter.Equal("strategy_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM pools").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundPoolList, err := GetPoolListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetPoolListByPagination", err)
@@ -825,8 +785,8 @@ func TestGetPoolListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"strategy_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM pools").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("strategy_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM pools").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundPoolList, err := GetPoolListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPoolListByPagination", err)
@@ -849,9 +809,9 @@ func TestGetPoolListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"strategy_id = -1"}
+	filters := filter.All(filter.Equal("strategy_id", "-1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM pools").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM pools").WithArgs(int64(-1)).WillReturnRows(differentModelRows)
 	foundPoolList, err := GetPoolListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPoolListByPagination", err)
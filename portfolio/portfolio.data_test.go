@@ -8,43 +8,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                //1
-	"uuid",              //2
-	"name",              //3
-	"alternate_name",    //4
-	"start_date",        //5
-	"end_date",          //6
-	"user_email",        //7
-	"description",       //8
-	"base_asset_id",     //9
-	"portfolio_type_id", //10
-	"parent_id",         //11
-	"created_by",        //12
-	"created_at",        //13
-	"updated_by",        //14
-	"updated_at",        //15
-}
-var DBColumnsInsertPortfolios = []string{
-	"uuid",              //1
-	"name",              //2
-	"alternate_name",    //3
-	"start_date",        //4
-	"end_date",          //5
-	"user_email",        //6
-	"description",       //7
-	"base_asset_id",     //8
-	"portfolio_type_id", //9
-	"parent_id",         //10
-	"created_by",        //11
-	"created_at",        //12
-	"updated_by",        //13
-	"updated_at",        //14
-}
+var DBColumns = PortfolioRepository.Columns()
+var DBColumnsInsertPortfolios = PortfolioRepository.InsertColumns()
 
 var TestData1 = Portfolio{
 	ID:              utils.Ptr[int](1),                               //1
@@ -85,27 +56,7 @@ var TestData2 = Portfolio{
 var TestAllData = []Portfolio{TestData1, TestData2}
 
 func AddPortfolioToMockRows(mock pgxmock.PgxPoolIface, dataList []Portfolio) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,              //1
-			data.UUID,            //2
-			data.Name,            //3
-			data.AlternateName,   //4
-			data.StartDate,       //5
-			data.EndDate,         //6
-			data.UserEmail,       //7
-			data.Description,     //8
-			data.BaseAssetID,     //9
-			data.PortfolioTypeID, //10
-			data.ParentID,        //11
-			data.CreatedBy,       //12
-			data.CreatedAt,       //13
-			data.UpdatedBy,       //14
-			data.UpdatedAt,       //15
-		)
-	}
-	return rows
+	return PortfolioRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetPortfolio(t *testing.T) {
@@ -141,8 +92,8 @@ func TestGetPortfolioForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WithArgs(portfolioID).WillReturnRows(noRows)
 	foundPortfolio, err := GetPortfolio(mock, &portfolioID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetPortfolio", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundPortfolio != nil {
 		t.Errorf("Expected Portfolio From Method GetPortfolio: to be empty but got this: %v", foundPortfolio)
@@ -259,8 +210,8 @@ func TestGetPortfolios(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddPortfolioToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WithArgs(ids).WillReturnRows(mockRows)
 	foundPortfolioList, err := GetPortfolios(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetPortfolios", err)
@@ -281,8 +232,8 @@ func TestGetPortfoliosForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundPortfolioList, err := GetPortfolios(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPortfolios", err)
@@ -302,8 +253,8 @@ func TestGetPortfoliosForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundPortfolio, err := GetPortfolios(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPortfolios", err)
@@ -413,6 +364,82 @@ func TestUpdatePortfolioOnFailure(t *testing.T) {
 	}
 }
 
+func TestUpdatePortfolioIfUnchanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	newUpdatedAt := targetData.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE portfolios").WithArgs(
+		targetData.Name,            //1
+		targetData.AlternateName,   //2
+		targetData.StartDate,       //3
+		targetData.EndDate,         //4
+		targetData.UserEmail,       //5
+		targetData.Description,     //6
+		targetData.BaseAssetID,     //7
+		targetData.PortfolioTypeID, //8
+		targetData.ParentID,        //9
+		targetData.UpdatedBy,       //10
+		targetData.ID,              //11
+		targetData.UpdatedAt,       //12
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}).AddRow(newUpdatedAt))
+	mock.ExpectCommit()
+	err = UpdatePortfolioIfUnchanged(mock, &targetData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpdatePortfolioIfUnchanged", err)
+	}
+	if !targetData.UpdatedAt.Equal(newUpdatedAt) {
+		t.Errorf("expected UpdatedAt to be %v, got %v", newUpdatedAt, targetData.UpdatedAt)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdatePortfolioIfUnchangedOnConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	current := TestData1
+	current.Description = "edited elsewhere"
+	current.UpdatedAt = TestData1.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE portfolios").WithArgs(
+		targetData.Name,            //1
+		targetData.AlternateName,   //2
+		targetData.StartDate,       //3
+		targetData.EndDate,         //4
+		targetData.UserEmail,       //5
+		targetData.Description,     //6
+		targetData.BaseAssetID,     //7
+		targetData.PortfolioTypeID, //8
+		targetData.ParentID,        //9
+		targetData.UpdatedBy,       //10
+		targetData.ID,              //11
+		targetData.UpdatedAt,       //12
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}))
+	mock.ExpectQuery("^SELECT (.+) FROM portfolios(.+)FOR SHARE$").WithArgs(targetData.ID).WillReturnRows(AddPortfolioToMockRows(mock, []Portfolio{current}))
+	mock.ExpectRollback()
+	err = UpdatePortfolioIfUnchanged(mock, &targetData)
+	var conflict *dberrors.ConflictError[Portfolio]
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a *dberrors.ConflictError[Portfolio], got '%v'", err)
+	}
+	if cmp.Equal(*conflict.Current, current) == false {
+		t.Errorf("Expected the current Portfolio %v, got %v", current, conflict.Current)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestInsertPortfolio(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -587,8 +614,8 @@ func TestGetPortfolioListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"target_asset_id = 1", "strategy_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("base_asset_id", "1"), filter.Equal("portfolio_type_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundPortfolioList, err := GetPortfolioListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetPortfolioListByPagination", err)
@@ -613,8 +640,8 @@ func TestGetPortfolioListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"strategy_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("base_asset_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundPortfolioList, err := GetPortfolioListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPortfolioListByPagination", err)
@@ -637,9 +664,9 @@ func TestGetPortfolioListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"strategy_id = -1"}
+	filters := filter.All(filter.Equal("base_asset_id", "-1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM portfolios").WithArgs(int64(-1)).WillReturnRows(differentModelRows)
 	foundPortfolioList, err := GetPortfolioListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPortfolioListByPagination", err)
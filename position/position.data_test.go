@@ -7,53 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",             //1
-	"uuid",           //2
-	"name",           //3
-	"alternate_name", //4
-	"account_id",     //5
-	"portfolio_id",   //6
-	"frequency_id",   //7
-	"start_date",     //8
-	"end_date",       //9
-	"base_asset_id",  //10
-	"quote_asset_id", //11
-	"quantity",       //12
-	"cost_basis",     //13
-	"profit",         //14
-	"total_amount",   //15
-	"description",    //16
-	"created_by",     //17
-	"created_at",     //18
-	"updated_by",     //19
-	"updated_at",     //20
-}
-var DBColumnsInsertPositions = []string{
-	"uuid",           //1
-	"name",           //2
-	"alternate_name", //3
-	"account_id",     //4
-	"portfolio_id",   //5
-	"frequency_id",   //6
-	"start_date",     //7
-	"end_date",       //8
-	"base_asset_id",  //9
-	"quote_asset_id", //10
-	"quantity",       //11
-	"cost_basis",     //12
-	"profit",         //13
-	"total_amount",   //14
-	"description",    //15
-	"created_by",     //16
-	"created_at",     //17
-	"updated_by",     //18
-	"updated_at",     //19
-}
+var DBColumns = PositionRepository.Columns()
+var DBColumnsInsertPositions = PositionRepository.InsertColumns()
 
 var TestData1 = Position{
 	ID:            utils.Ptr[int](1),                                     //1
@@ -104,32 +65,7 @@ var TestData2 = Position{
 var TestAllData = []Position{TestData1, TestData2}
 
 func AddPositionToMockRows(mock pgxmock.PgxPoolIface, dataList []Position) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.UUID,          //2
-			data.Name,          //3
-			data.AlternateName, //4
-			data.AccountID,     //5
-			data.PortfolioID,   //6
-			data.FrequnecyID,   //7
-			data.StartDate,     //8
-			data.EndDate,       //9
-			data.BaseAssetID,   //10
-			data.QuoteAssetID,  //11
-			data.Quantity,      //12
-			data.CostBasis,     //13
-			data.Profit,        //14
-			data.TotalAmount,   //15
-			data.Description,   //16
-			data.CreatedBy,     //17
-			data.CreatedAt,     //18
-			data.UpdatedBy,     //19
-			data.UpdatedAt,     //20
-		)
-	}
-	return rows
+	return PositionRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetPosition(t *testing.T) {
@@ -165,8 +101,8 @@ func TestGetPositionForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(positionID).WillReturnRows(noRows)
 	foundPosition, err := GetPosition(mock, &positionID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetPosition", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundPosition != nil {
 		t.Errorf("Expected Position From Method GetPosition: to be empty but got this: %v", foundPosition)
@@ -261,8 +197,8 @@ func TestGetPositionByDatesAndAccountForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(startDate.Format(utils.LayoutPostgres), endDate.Format(utils.LayoutPostgres), frequencyID, baseAssetID, quoteAssetID, accountID).WillReturnRows(noRows)
 	foundPosition, err := GetPositionByDatesAndAccount(mock, startDate, endDate, &frequencyID, &baseAssetID, &quoteAssetID, &accountID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetPositionByDatesAndAccount", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundPosition != nil {
 		t.Errorf("Expected Position From Method GetPositionByDatesAndAccount: to be empty but got this: %v", foundPosition)
@@ -663,8 +599,8 @@ func TestGetPositions(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddPositionToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM positions").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(ids).WillReturnRows(mockRows)
 	foundPositionList, err := GetPositions(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetPositions", err)
@@ -685,8 +621,8 @@ func TestGetPositionsForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM positions").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundPositionList, err := GetPositions(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPositions", err)
@@ -706,8 +642,8 @@ func TestGetPositionsForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM positions").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundPosition, err := GetPositions(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPositions", err)
@@ -816,22 +752,22 @@ func TestUpdatePosition(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE positions").WithArgs(
-		targetData.Name,                                   //1
-		targetData.AlternateName,                          //2
-		targetData.AccountID,                              //3
-		targetData.PortfolioID,                            //4
-		targetData.FrequnecyID,                            //5
-		targetData.StartDate.Format(utils.LayoutPostgres), //6
-		targetData.EndDate.Format(utils.LayoutPostgres),   //7
-		targetData.BaseAssetID,                            //8
-		targetData.QuoteAssetID,                           //9
-		targetData.Quantity,                               //10
-		targetData.CostBasis,                              //11
-		targetData.Profit,                                 //12
-		targetData.TotalAmount,                            //13
-		targetData.Description,                            //14
-		targetData.UpdatedBy,                              //15
-		targetData.ID,                                     //16
+		targetData.Name,          //1
+		targetData.AlternateName, //2
+		targetData.AccountID,     //3
+		targetData.PortfolioID,   //4
+		targetData.FrequnecyID,   //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.BaseAssetID,   //8
+		targetData.QuoteAssetID,  //9
+		targetData.Quantity,      //10
+		targetData.CostBasis,     //11
+		targetData.Profit,        //12
+		targetData.TotalAmount,   //13
+		targetData.Description,   //14
+		targetData.UpdatedBy,     //15
+		targetData.ID,            //16
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdatePosition(mock, &targetData)
@@ -886,22 +822,22 @@ func TestUpdatePositionOnFailure(t *testing.T) {
 	targetData.ID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE positions").WithArgs(
-		targetData.Name,                                   //1
-		targetData.AlternateName,                          //2
-		targetData.AccountID,                              //3
-		targetData.PortfolioID,                            //4
-		targetData.FrequnecyID,                            //5
-		targetData.StartDate.Format(utils.LayoutPostgres), //6
-		targetData.EndDate.Format(utils.LayoutPostgres),   //7
-		targetData.BaseAssetID,                            //8
-		targetData.QuoteAssetID,                           //9
-		targetData.Quantity,                               //10
-		targetData.CostBasis,                              //11
-		targetData.Profit,                                 //12
-		targetData.TotalAmount,                            //13
-		targetData.Description,                            //14
-		targetData.UpdatedBy,                              //15
-		targetData.ID,                                     //16
+		targetData.Name,          //1
+		targetData.AlternateName, //2
+		targetData.AccountID,     //3
+		targetData.PortfolioID,   //4
+		targetData.FrequnecyID,   //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.BaseAssetID,   //8
+		targetData.QuoteAssetID,  //9
+		targetData.Quantity,      //10
+		targetData.CostBasis,     //11
+		targetData.Profit,        //12
+		targetData.TotalAmount,   //13
+		targetData.Description,   //14
+		targetData.UpdatedBy,     //15
+		targetData.ID,            //16
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -923,21 +859,21 @@ func TestInsertPosition(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO positions").WithArgs(
-		targetData.Name,                                   //1
-		targetData.AlternateName,                          //2
-		targetData.AccountID,                              //3
-		targetData.PortfolioID,                            //4
-		targetData.FrequnecyID,                            //5
-		targetData.StartDate.Format(utils.LayoutPostgres), //6
-		targetData.EndDate.Format(utils.LayoutPostgres),   //7
-		targetData.BaseAssetID,                            //8
-		targetData.QuoteAssetID,                           //9
-		targetData.Quantity,                               //10
-		targetData.CostBasis,                              //11
-		targetData.Profit,                                 //12
-		targetData.TotalAmount,                            //13
-		targetData.Description,                            //14
-		targetData.CreatedBy,                              //15
+		targetData.Name,          //1
+		targetData.AlternateName, //2
+		targetData.AccountID,     //3
+		targetData.PortfolioID,   //4
+		targetData.FrequnecyID,   //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.BaseAssetID,   //8
+		targetData.QuoteAssetID,  //9
+		targetData.Quantity,      //10
+		targetData.CostBasis,     //11
+		targetData.Profit,        //12
+		targetData.TotalAmount,   //13
+		targetData.Description,   //14
+		targetData.CreatedBy,     //15
 	).WillReturnRows(pgxmock.NewRows([]string{"position_id", "job_id"}).AddRow(1, "return-uuid"))
 	mock.ExpectCommit()
 	positionID, uuid, err := InsertPosition(mock, &targetData)
@@ -983,21 +919,21 @@ func TestInsertPositionOnFailure(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO positions").WithArgs(
-		targetData.Name,                                   //1
-		targetData.AlternateName,                          //2
-		targetData.AccountID,                              //3
-		targetData.PortfolioID,                            //4
-		targetData.FrequnecyID,                            //5
-		targetData.StartDate.Format(utils.LayoutPostgres), //6
-		targetData.EndDate.Format(utils.LayoutPostgres),   //7
-		targetData.BaseAssetID,                            //8
-		targetData.QuoteAssetID,                           //9
-		targetData.Quantity,                               //10
-		targetData.CostBasis,                              //11
-		targetData.Profit,                                 //12
-		targetData.TotalAmount,                            //13
-		targetData.Description,                            //14
-		targetData.CreatedBy,                              //15
+		targetData.Name,          //1
+		targetData.AlternateName, //2
+		targetData.AccountID,     //3
+		targetData.PortfolioID,   //4
+		targetData.FrequnecyID,   //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.BaseAssetID,   //8
+		targetData.QuoteAssetID,  //9
+		targetData.Quantity,      //10
+		targetData.CostBasis,     //11
+		targetData.Profit,        //12
+		targetData.TotalAmount,   //13
+		targetData.Description,   //14
+		targetData.CreatedBy,     //15
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	positionID, uuid, err := InsertPosition(mock, &targetData)
@@ -1024,21 +960,21 @@ func TestInsertPositionOnFailureOnCommit(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectQuery("^INSERT INTO positions").WithArgs(
-		targetData.Name,                                   //1
-		targetData.AlternateName,                          //2
-		targetData.AccountID,                              //3
-		targetData.PortfolioID,                            //4
-		targetData.FrequnecyID,                            //5
-		targetData.StartDate.Format(utils.LayoutPostgres), //6
-		targetData.EndDate.Format(utils.LayoutPostgres),   //7
-		targetData.BaseAssetID,                            //8
-		targetData.QuoteAssetID,                           //9
-		targetData.Quantity,                               //10
-		targetData.CostBasis,                              //11
-		targetData.Profit,                                 //12
-		targetData.TotalAmount,                            //13
-		targetData.Description,                            //14
-		targetData.CreatedBy,                              //15
+		targetData.Name,          //1
+		targetData.AlternateName, //2
+		targetData.AccountID,     //3
+		targetData.PortfolioID,   //4
+		targetData.FrequnecyID,   //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.BaseAssetID,   //8
+		targetData.QuoteAssetID,  //9
+		targetData.Quantity,      //10
+		targetData.CostBasis,     //11
+		targetData.Profit,        //12
+		targetData.TotalAmount,   //13
+		targetData.Description,   //14
+		targetData.CreatedBy,     //15
 	).WillReturnRows(pgxmock.NewRows([]string{"position_id", "job_id"}).AddRow(1, "return-uuid"))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -1103,8 +1039,8 @@ func TestGetPositionListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"base_asset_id = 1", "frequency_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM positions").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("base_asset_id", "1"), filter.Equal("frequency_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundPositionList, err := GetPositionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetPositionListByPagination", err)
@@ -1129,8 +1065,8 @@ func TestGetPositionListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"frequency_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM positions").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("frequency_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundPositionList, err := GetPositionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPositionListByPagination", err)
@@ -1153,9 +1089,9 @@ func TestGetPositionListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"frequency_id = -1"}
+	filters := filter.All(filter.Equal("frequency_id", "-1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM positions").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM positions").WithArgs(int64(-1)).WillReturnRows(differentModelRows)
 	foundPositionList, err := GetPositionListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPositionListByPagination", err)
@@ -7,53 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"position_id",        //1
-	"job_id",             //2
-	"uuid",               //3
-	"name",               //4
-	"alternate_name",     //5
-	"start_date",         //6
-	"end_date",           //7
-	"description",        //8
-	"status_id",          //9
-	"response_status",    //10
-	"request_url",        //11
-	"request_body",       //12
-	"request_method",     //13
-	"response_data",      //14
-	"response_data_json", //15
-	"created_by",         //16
-	"created_at",         //17
-	"updated_by",         //18
-	"updated_at",         //19
-}
-var DBColumnsInsertPositionJobs = []string{
-	"position_id",        //1
-	"job_id",             //2
-	"uuid",               //3
-	"name",               //4
-	"alternate_name",     //5
-	"start_date",         //6
-	"end_date",           //7
-	"description",        //8
-	"status_id",          //9
-	"response_status",    //10
-	"request_url",        //11
-	"request_body",       //12
-	"request_method",     //13
-	"response_data",      //14
-	"response_data_json", //15
-	"created_by",         //16
-	"created_at",         //17
-	"updated_by",         //18
-	"updated_at",         //19
-}
+var DBColumns = PositionJobRepository.Columns()
+var DBColumnsInsertPositionJobs = PositionJobRepository.InsertColumns()
 
 var TestData1 = PositionJob{
 	PositionID:       utils.Ptr[int](1),                      //1
@@ -102,31 +64,7 @@ var TestData2 = PositionJob{
 var TestAllData = []PositionJob{TestData1, TestData2}
 
 func AddPositionJobToMockRows(mock pgxmock.PgxPoolIface, dataList []PositionJob) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.PositionID,       //1
-			data.JobID,            //2
-			data.UUID,             //3
-			data.Name,             //4
-			data.AlternateName,    //5
-			data.StartDate,        //6
-			data.EndDate,          //7
-			data.Description,      //8
-			data.StatusID,         //9
-			data.ResponseStatus,   //10
-			data.RequestUrl,       //11
-			data.RequestBody,      //12
-			data.RequestMethod,    //13
-			data.ResponseData,     //14
-			data.ResponseDataJson, //15
-			data.CreatedBy,        //16
-			data.CreatedAt,        //17
-			data.UpdatedBy,        //18
-			data.UpdatedAt,        //19
-		)
-	}
-	return rows
+	return PositionJobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetPositionJob(t *testing.T) {
@@ -164,8 +102,8 @@ func TestGetPositionJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WithArgs(positionID, jobID).WillReturnRows(noRows)
 	foundPositionJob, err := GetPositionJob(mock, &positionID, &jobID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetPositionJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundPositionJob != nil {
 		t.Errorf("Expected PositionJob From Method GetPositionJob: to be empty but got this: %v", foundPositionJob)
@@ -252,8 +190,8 @@ func TestGetPositionJobByUUIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WithArgs(positionJobUUID).WillReturnRows(noRows)
 	foundPositionJob, err := GetPositionJobByUUID(mock, positionJobUUID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetPositionJobByUUID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundPositionJob != nil {
 		t.Errorf("Expected PositionJob From Method GetPositionJobByUUID: to be empty but got this: %v", foundPositionJob)
@@ -558,20 +496,21 @@ func TestUpdatePositionJob(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE position_jobs").WithArgs(
-		targetData.Name,           //1
-		targetData.AlternateName,  //2
-		targetData.StartDate,      //3
-		targetData.EndDate,        //4
-		targetData.Description,    //5
-		targetData.StatusID,       //6
-		targetData.ResponseStatus, //7
-		targetData.RequestUrl,     //8
-		targetData.RequestBody,    //9
-		targetData.RequestMethod,  //10
-		targetData.ResponseData,   //11
-		targetData.UpdatedBy,      //12
-		targetData.PositionID,     //13
-		targetData.JobID,          //14
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.UpdatedBy,        //13
+		targetData.PositionID,       //14
+		targetData.JobID,            //15
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdatePositionJob(mock, &targetData)
@@ -626,20 +565,21 @@ func TestUpdatePositionJobOnFailure(t *testing.T) {
 	targetData.PositionID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE position_jobs").WithArgs(
-		targetData.Name,           //1
-		targetData.AlternateName,  //2
-		targetData.StartDate,      //3
-		targetData.EndDate,        //4
-		targetData.Description,    //5
-		targetData.StatusID,       //6
-		targetData.ResponseStatus, //7
-		targetData.RequestUrl,     //8
-		targetData.RequestBody,    //9
-		targetData.RequestMethod,  //10
-		targetData.ResponseData,   //11
-		targetData.UpdatedBy,      //12
-		targetData.PositionID,     //13
-		targetData.JobID,          //14
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.UpdatedBy,        //13
+		targetData.PositionID,       //14
+		targetData.JobID,            //15
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -940,10 +880,10 @@ func TestGetPositionJobListByPagination(t *testing.T) {
 	mockRows := AddPositionJobToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "position_id"
 	_order := "ASC"
-	filters := []string{"base_asset_id = 1", "frequency_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("position_id", "1"), filter.Equal("job_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundPositionJobList, err := GetPositionJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetPositionJobListByPagination", err)
@@ -966,10 +906,10 @@ func TestGetPositionJobListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "position_id"
 	_order := "ASC"
-	filters := []string{"frequency_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("position_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundPositionJobList, err := GetPositionJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPositionJobListByPagination", err)
@@ -990,11 +930,11 @@ func TestGetPositionJobListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "position_id"
 	_order := "ASC"
-	filters := []string{"frequency_id = -1"}
+	filters := filter.All(filter.Equal("position_id", "-1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM position_jobs").WithArgs(int64(-1)).WillReturnRows(differentModelRows)
 	foundPositionJobList, err := GetPositionJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetPositionJobListByPagination", err)
@@ -0,0 +1,509 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// ErrNoKey is returned by the key based operations of a repository created
+// with Key(""), and by GetList, Insert and InsertReturningUUID of a
+// repository with a composite key.
+var ErrNoKey = errors.New("repository: entity has no key column")
+
+// ErrNoUpdatedAt is returned by UpdateIfUnchanged for entities without an
+// updated_at column.
+var ErrNoUpdatedAt = errors.New("repository: entity has no updated_at column")
+
+// ErrNoSoftDelete is returned by Restore for repositories created without
+// SoftDelete.
+var ErrNoSoftDelete = errors.New("repository: entity is not soft deleted")
+
+// Get returns the row whose key equals id, or dberrors.ErrNotFound when there
+// is none.
+func (r *Repository[T]) Get(ctx context.Context, dbConnPgx utils.PgxIface, id interface{}, opts ...ReadOption) (*T, error) {
+	clause, args, err := r.keyClause(id)
+	if err != nil {
+		return nil, err
+	}
+	clause = "WHERE " + clause
+	if notDeleted := r.NotDeleted(opts); notDeleted != "" {
+		clause += " AND " + notDeleted
+	}
+	return r.SelectOne(ctx, dbConnPgx, clause, args...)
+}
+
+// GetBy returns the first row where column equals value, or
+// dberrors.ErrNotFound when there is none.
+func (r *Repository[T]) GetBy(ctx context.Context, dbConnPgx utils.PgxIface, column string, value interface{}, opts ...ReadOption) (*T, error) {
+	if !r.meta.has(column) {
+		return nil, fmt.Errorf("%w: %q", filter.ErrUnknownField, column)
+	}
+	clause := fmt.Sprintf("WHERE %s = $1", column)
+	if notDeleted := r.NotDeleted(opts); notDeleted != "" {
+		clause += " AND " + notDeleted
+	}
+	return r.SelectOne(ctx, dbConnPgx, clause, value)
+}
+
+// SelectOne runs SelectSQL followed by clause and returns the first row, or
+// dberrors.ErrNotFound when there is none. clause is trusted SQL written by the entity package,
+// as for Select.
+func (r *Repository[T]) SelectOne(ctx context.Context, dbConnPgx utils.PgxIface, clause string, args ...interface{}) (*T, error) {
+	row, err := dbConnPgx.Query(ctx, r.SelectSQL()+"\n\t"+clause, args...)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.SelectOne", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	entity, err := pgx.CollectOneRow(row, pgx.RowToStructByName[T])
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.SelectOne", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	return &entity, nil
+}
+
+// Select runs SelectSQL followed by clause and returns all rows. clause is
+// trusted SQL written by the entity package; it has to exclude soft deleted
+// rows itself, see NotDeleted.
+func (r *Repository[T]) Select(ctx context.Context, dbConnPgx utils.PgxIface, clause string, args ...interface{}) ([]T, error) {
+	sql := r.SelectSQL()
+	if clause != "" {
+		sql += "\n\t" + clause
+	}
+	results, err := dbConnPgx.Query(ctx, sql, args...)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Select", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	entities, err := pgx.CollectRows(results, pgx.RowToStructByName[T])
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Select", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	return entities, nil
+}
+
+// GetList returns the rows whose key is in ids, or every row when ids is
+// empty.
+func (r *Repository[T]) GetList(ctx context.Context, dbConnPgx utils.PgxIface, ids []int, opts ...ReadOption) ([]T, error) {
+	notDeleted := r.NotDeleted(opts)
+	if len(ids) == 0 {
+		if notDeleted == "" {
+			return r.Select(ctx, dbConnPgx, "")
+		}
+		return r.Select(ctx, dbConnPgx, "WHERE "+notDeleted)
+	}
+	if len(r.keys) != 1 {
+		return nil, ErrNoKey
+	}
+	clause := fmt.Sprintf("WHERE %s = ANY($1)", r.keys[0])
+	if notDeleted != "" {
+		clause += " AND " + notDeleted
+	}
+	return r.Select(ctx, dbConnPgx, clause, ids)
+}
+
+// Pagination returns one page of rows filtered and sorted as described in
+// filter.Paginate.
+func (r *Repository[T]) Pagination(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group, opts ...ReadOption) ([]T, error) {
+	clause, args, err := filter.PaginateScoped(r.Filterable(), r.NotDeleted(opts), _start, _end, _order, _sort, _filters)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Pagination", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	return r.Select(ctx, dbConnPgx, clause, args...)
+}
+
+// Count returns the number of rows in the table.
+func (r *Repository[T]) Count(ctx context.Context, dbConnPgx utils.PgxIface, opts ...ReadOption) (*int, error) {
+	sql := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, r.table)
+	if notDeleted := r.NotDeleted(opts); notDeleted != "" {
+		sql += " WHERE " + notDeleted
+	}
+	row := dbConnPgx.QueryRow(ctx, sql)
+	totalCount := 0
+	err := row.Scan(
+		&totalCount,
+	)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Count", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	return &totalCount, nil
+}
+
+// Insert adds entity and returns the generated key.
+func (r *Repository[T]) Insert(ctx context.Context, dbConnPgx utils.PgxIface, entity *T) (int, error) {
+	if len(r.keys) != 1 {
+		return -1, ErrNoKey
+	}
+	var ID int
+	if err := r.insert(ctx, dbConnPgx, entity, r.keys[0], &ID); err != nil {
+		return -1, dberrors.Wrap(err)
+	}
+	return ID, nil
+}
+
+// InsertReturningUUID adds entity and returns the generated key and uuid.
+func (r *Repository[T]) InsertReturningUUID(ctx context.Context, dbConnPgx utils.PgxIface, entity *T) (int, string, error) {
+	if len(r.keys) != 1 {
+		return -1, "", ErrNoKey
+	}
+	var ID int
+	var uuid string
+	if err := r.insert(ctx, dbConnPgx, entity, r.keys[0]+", uuid", &ID, &uuid); err != nil {
+		return -1, "", dberrors.Wrap(err)
+	}
+	return ID, uuid, nil
+}
+
+// InsertReturningKey adds entity and scans its key columns, in Key order,
+// into dest. It is the Insert of repositories with a composite key.
+func (r *Repository[T]) InsertReturningKey(ctx context.Context, dbConnPgx utils.PgxIface, entity *T, dest ...interface{}) error {
+	return dberrors.Wrap(r.insert(ctx, dbConnPgx, entity, strings.Join(r.keys, ", "), dest...))
+}
+
+func (r *Repository[T]) insert(ctx context.Context, dbConnPgx utils.PgxIface, entity *T, returning string, dest ...interface{}) error {
+	if len(r.keys) == 0 {
+		return ErrNoKey
+	}
+	sql, args := r.insertSQL(entity, returning)
+	tx, err := dbConnPgx.Begin(ctx)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Insert: begin", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	err = tx.QueryRow(ctx, sql, args...).Scan(dest...)
+	if err != nil {
+		tx.Rollback(ctx)
+		logging.ReturnedError(ctx, "Repository.Insert", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		logging.ReturnedError(ctx, "Repository.Insert", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	return nil
+}
+
+func (r *Repository[T]) insertSQL(entity *T, returning string) (string, []interface{}) {
+	v := reflect.ValueOf(entity).Elem()
+	args := []interface{}{}
+	values := make([]string, 0, len(r.insertColumns))
+	createdBy := ""
+	for _, name := range r.insertColumns {
+		switch {
+		case name == "uuid" && !r.keepUUID:
+			values = append(values, generateUUID)
+		case name == "created_at" || name == "updated_at":
+			values = append(values, currentTimestampUTC)
+		case name == "updated_by" && createdBy != "":
+			values = append(values, createdBy)
+		default:
+			args = append(args, r.meta.value(v, name))
+			values = append(values, fmt.Sprintf("$%d", len(args)))
+			if name == "created_by" {
+				createdBy = values[len(values)-1]
+			}
+		}
+	}
+	sql := fmt.Sprintf("INSERT INTO %s\n\t(\n\t\t%s\n\t) VALUES (\n\t\t%s\n\t)\n\tRETURNING %s",
+		r.table, strings.Join(r.insertColumns, ",\n\t\t"), strings.Join(values, ",\n\t\t"), returning)
+	return sql, args
+}
+
+// InsertMany copies entities into the table in one COPY statement and returns
+// the number of rows written. created_at and updated_at are set to now and
+// updated_by to created_by.
+func (r *Repository[T]) InsertMany(ctx context.Context, dbConnPgx utils.PgxIface, entities []T) (int64, error) {
+	rows := r.copyRows(entities)
+	copyCount, err := dbConnPgx.CopyFrom(
+		ctx,
+		pgx.Identifier{r.table},
+		r.copyColumns,
+		pgx.CopyFromRows(rows),
+	)
+	logging.Debug(ctx, "Repository.InsertMany: copied rows", logging.Entity(r.table), logging.Rows(copyCount))
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.InsertMany", err, logging.Entity(r.table))
+		return copyCount, dberrors.Wrap(err)
+	}
+	return copyCount, nil
+}
+
+// UpsertMany merges entities into the table keyed on the conflict columns, see
+// Upsert, and returns the number of inserted and updated rows.
+func (r *Repository[T]) UpsertMany(ctx context.Context, dbConnPgx utils.PgxIface, conflict []string, entities []T) (int64, int64, error) {
+	upsert := Upsert{Table: r.table, Columns: r.copyColumns, Conflict: conflict}
+	return upsert.CopyFrom(ctx, dbConnPgx, r.copyRows(entities))
+}
+
+func (r *Repository[T]) copyRows(entities []T) [][]interface{} {
+	loc, _ := time.LoadLocation("UTC")
+	now := time.Now().In(loc)
+	rows := make([][]interface{}, 0, len(entities))
+	for i := range entities {
+		v := reflect.ValueOf(&entities[i]).Elem()
+		row := make([]interface{}, 0, len(r.copyColumns))
+		for _, name := range r.copyColumns {
+			switch name {
+			case "uuid":
+				uuidString := &pgtype.UUID{}
+				uuidString.Set(r.meta.value(v, name))
+				row = append(row, uuidString)
+			case "created_at", "updated_at":
+				row = append(row, &now)
+			case "updated_by":
+				if r.meta.has("created_by") {
+					row = append(row, r.meta.value(v, "created_by"))
+				} else {
+					row = append(row, r.meta.value(v, name))
+				}
+			default:
+				row = append(row, r.meta.value(v, name))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Update writes every updatable column of entity, identified by its key, and
+// stamps updated_at.
+func (r *Repository[T]) Update(ctx context.Context, dbConnPgx utils.PgxIface, entity *T) error {
+	v := reflect.ValueOf(entity).Elem()
+	if _, err := r.keyOf(v); err != nil {
+		return err
+	}
+	sql, args := r.updateSQL(v)
+	tx, err := dbConnPgx.Begin(ctx)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Update: begin", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		tx.Rollback(ctx)
+		return dberrors.Wrap(err)
+	}
+	return dberrors.Wrap(tx.Commit(ctx))
+}
+
+// UpdateIfUnchanged is Update with optimistic concurrency control: the row is
+// only written while its updated_at still equals entity's UpdatedAt, i.e. the
+// value the caller read. When another writer got there first nothing is
+// written and a *dberrors.ConflictError[T] carrying the current row, read in
+// the same transaction and soft deleted or not, is returned;
+// dberrors.ErrNotFound is returned when the row was removed. On
+// success entity's UpdatedAt is set to the new value, so it can be updated
+// again. The entity packages offer it for the reference data users edit; the
+// rows only importers and jobs write, e.g. market data, positions, trades,
+// transactions, the job link tables and the gethlyle tables, are updated with
+// Update.
+func (r *Repository[T]) UpdateIfUnchanged(ctx context.Context, dbConnPgx utils.PgxIface, entity *T) error {
+	if !r.meta.has("updated_at") {
+		return ErrNoUpdatedAt
+	}
+	v := reflect.ValueOf(entity).Elem()
+	key, err := r.keyOf(v)
+	if err != nil {
+		return err
+	}
+	sql, args := r.updateSQL(v)
+	args = append(args, r.meta.value(v, "updated_at"))
+	sql = fmt.Sprintf("%s AND updated_at=$%d\n\t\tRETURNING updated_at", sql, len(args))
+	updatedAt := r.meta.field(v, "updated_at")
+	newUpdatedAt := reflect.New(updatedAt.Type())
+	tx, err := dbConnPgx.Begin(ctx)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.UpdateIfUnchanged: begin", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	err = tx.QueryRow(ctx, sql, args...).Scan(newUpdatedAt.Interface())
+	if errors.Is(err, pgx.ErrNoRows) {
+		current, err := r.current(ctx, tx, key)
+		tx.Rollback(ctx)
+		if err != nil {
+			return err
+		}
+		return &dberrors.ConflictError[T]{Current: current}
+	}
+	if err != nil {
+		tx.Rollback(ctx)
+		logging.ReturnedError(ctx, "Repository.UpdateIfUnchanged", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return dberrors.Wrap(err)
+	}
+	updatedAt.Set(newUpdatedAt.Elem())
+	return nil
+}
+
+// current reads the row whose key equals id within tx, soft deleted or not,
+// and locks it until tx ends, so that a conflict reports the row the guarded
+// UPDATE did not match.
+func (r *Repository[T]) current(ctx context.Context, tx pgx.Tx, id interface{}) (*T, error) {
+	clause, args, err := r.keyClause(id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, r.SelectSQL()+"\n\tWHERE "+clause+"\n\tFOR SHARE", args...)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.UpdateIfUnchanged: current", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	entity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.UpdateIfUnchanged: current", err, logging.Entity(r.table))
+		return nil, dberrors.Wrap(err)
+	}
+	return &entity, nil
+}
+
+func (r *Repository[T]) updateSQL(v reflect.Value) (string, []interface{}) {
+	args := make([]interface{}, 0, len(r.updateColumns)+1)
+	sets := make([]string, 0, len(r.updateColumns))
+	for _, name := range r.updateColumns {
+		if name == "updated_at" {
+			sets = append(sets, name+"="+currentTimestampUTC)
+			continue
+		}
+		args = append(args, r.meta.value(v, name))
+		sets = append(sets, fmt.Sprintf("%s=$%d", name, len(args)))
+	}
+	where := make([]string, 0, len(r.keys))
+	for _, name := range r.keys {
+		args = append(args, r.meta.value(v, name))
+		where = append(where, fmt.Sprintf("%s=$%d", name, len(args)))
+	}
+	sql := fmt.Sprintf("UPDATE %s SET \n\t\t%s\n\t\tWHERE %s", r.table, strings.Join(sets, ",\n\t\t"), strings.Join(where, " AND "))
+	return sql, args
+}
+
+// Remove deletes the row whose key equals id. A soft deleting repository
+// sets deleted_at and deleted_by instead, taking the actor from ActorSetting
+// and falling back to the database user.
+func (r *Repository[T]) Remove(ctx context.Context, dbConnPgx utils.PgxIface, id interface{}) error {
+	where, args, err := r.keyClause(id)
+	if err != nil {
+		return err
+	}
+	tx, err := dbConnPgx.Begin(ctx)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Remove: begin", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	sql := fmt.Sprintf(`DELETE FROM %s WHERE %s`, r.table, where)
+	if r.softDelete {
+		sql = fmt.Sprintf(`UPDATE %s SET
+		deleted_at = %s,
+		deleted_by = COALESCE(NULLIF(current_setting('%s', true), ''), current_user)
+		WHERE %s AND deleted_at IS NULL`, r.table, currentTimestampUTC, ActorSetting, where)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		tx.Rollback(ctx)
+		return dberrors.Wrap(err)
+	}
+	return dberrors.Wrap(tx.Commit(ctx))
+}
+
+// Restore undoes the soft delete of the row whose key equals id. It returns
+// dberrors.ErrNotFound when there is no such row.
+func (r *Repository[T]) Restore(ctx context.Context, dbConnPgx utils.PgxIface, id interface{}) error {
+	where, args, err := r.keyClause(id)
+	if err != nil {
+		return err
+	}
+	if !r.softDelete {
+		return ErrNoSoftDelete
+	}
+	tx, err := dbConnPgx.Begin(ctx)
+	if err != nil {
+		logging.ReturnedError(ctx, "Repository.Restore: begin", err, logging.Entity(r.table))
+		return dberrors.Wrap(err)
+	}
+	set := "deleted_at = NULL,\n\t\tdeleted_by = NULL"
+	if r.meta.has("updated_at") {
+		set += ",\n\t\tupdated_at = " + currentTimestampUTC
+	}
+	sql := fmt.Sprintf("UPDATE %s SET\n\t\t%s\n\t\tWHERE %s", r.table, set, where)
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		tx.Rollback(ctx)
+		logging.ReturnedError(ctx, "Repository.Restore", err, logging.Entity(r.table), logging.ID(id))
+		return dberrors.Wrap(err)
+	}
+	if tag.RowsAffected() == 0 {
+		tx.Rollback(ctx)
+		return dberrors.ErrNotFound
+	}
+	return dberrors.Wrap(tx.Commit(ctx))
+}
+
+// keyClause returns the condition matching the row whose key equals id, with
+// its bind parameters numbered from $1. id is a CompositeKey for composite
+// keys.
+func (r *Repository[T]) keyClause(id interface{}) (string, []interface{}, error) {
+	if len(r.keys) == 0 {
+		return "", nil, ErrNoKey
+	}
+	args := []interface{}{id}
+	if composite, ok := id.(CompositeKey); ok {
+		args = composite
+	}
+	if len(args) != len(r.keys) {
+		return "", nil, dberrors.InvalidInput("%s key has %d columns, got %d values", r.entity, len(r.keys), len(args))
+	}
+	where := make([]string, 0, len(r.keys))
+	for i, name := range r.keys {
+		where = append(where, fmt.Sprintf("%s = $%d", name, i+1))
+	}
+	return strings.Join(where, " AND "), args, nil
+}
+
+// keyOf returns the key of the entity held by v, as accepted by Get, or an
+// invalid input error when part of it is unset.
+func (r *Repository[T]) keyOf(v reflect.Value) (interface{}, error) {
+	if len(r.keys) == 0 {
+		return nil, ErrNoKey
+	}
+	key := make(CompositeKey, 0, len(r.keys))
+	for _, name := range r.keys {
+		value := r.meta.value(v, name)
+		if isZeroKey(value) {
+			return nil, dberrors.InvalidInput("%s has invalid ID", r.entity)
+		}
+		key = append(key, value)
+	}
+	if len(key) == 1 {
+		return key[0], nil
+	}
+	return key, nil
+}
+
+func isZeroKey(key interface{}) bool {
+	v := reflect.ValueOf(key)
+	if !v.IsValid() {
+		return true
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+)
+
+const (
+	currentTimestampUTC = "current_timestamp at time zone 'UTC'"
+	generateUUID        = "uuid_generate_v4()"
+)
+
+// ActorSetting is the transaction setting naming the user behind the changes
+// of a transaction. The audit trigger and soft deletes read it; see
+// audit.WithActor.
+const ActorSetting = "lyle.audit_actor"
+
+// Repository provides the Get/GetList/Insert/InsertMany/Update/Remove/
+// Pagination/Count operations every entity package used to write by hand. The
+// column lists come from the `db` struct tags of T, so the SELECT, INSERT,
+// UPDATE and COPY statements cannot drift apart.
+//
+// The usual bookkeeping columns are recognised by name: the key column (id)
+// is generated by the database, uuid is generated on Insert, created_at and
+// updated_at are set to the current UTC time and updated_by mirrors
+// created_by on insert.
+type Repository[T any] struct {
+	table      string
+	entity     string
+	keys       []string
+	meta       *meta
+	coalesce   map[string]bool
+	readOnly   map[string]bool
+	keepUUID   bool
+	softDelete bool
+
+	selectList    string
+	insertColumns []string
+	updateColumns []string
+	copyColumns   []string
+}
+
+// Option customises a Repository created with New.
+type Option func(*options)
+
+type options struct {
+	keys       []string
+	coalesce   []string
+	readOnly   []string
+	keepUUID   bool
+	softDelete bool
+}
+
+// Key sets the primary key columns, "id" by default. A single key column is
+// generated by the database; the columns of a composite key, e.g. the two
+// foreign keys of a link table, are written by Insert and InsertMany like any
+// other column. No key (or an empty one) disables Get, GetList, Update and
+// Remove.
+func Key(names ...string) Option {
+	return func(o *options) {
+		o.keys = o.keys[:0]
+		for _, name := range names {
+			if name != "" {
+				o.keys = append(o.keys, name)
+			}
+		}
+	}
+}
+
+// CompositeKey holds the values of a composite key in Key order. It is passed
+// as the id of Get, Remove and Restore.
+type CompositeKey []interface{}
+
+// Coalesce selects the given nullable text columns through COALESCE with an
+// empty string so they scan into plain string fields.
+func Coalesce(columns ...string) Option {
+	return func(o *options) { o.coalesce = append(o.coalesce, columns...) }
+}
+
+// ReadOnly excludes columns from INSERT, UPDATE and COPY, e.g. columns
+// maintained by triggers.
+func ReadOnly(columns ...string) Option {
+	return func(o *options) { o.readOnly = append(o.readOnly, columns...) }
+}
+
+// KeepUUID makes Insert write the entity's UUID instead of generating one.
+func KeepUUID() Option {
+	return func(o *options) { o.keepUUID = true }
+}
+
+// SoftDelete makes Remove set the deleted_at and deleted_by columns of the
+// table instead of deleting the row, and makes Get, GetBy, GetList,
+// Pagination and Count skip such rows unless they are passed IncludeDeleted.
+// The columns are not part of T.
+func SoftDelete() Option {
+	return func(o *options) { o.softDelete = true }
+}
+
+// ReadOption customises a single read of a soft deleting repository.
+type ReadOption func(*readOptions)
+
+type readOptions struct {
+	includeDeleted bool
+}
+
+// IncludeDeleted makes a read return soft deleted rows as well.
+func IncludeDeleted() ReadOption {
+	return func(o *readOptions) { o.includeDeleted = true }
+}
+
+// IncludesDeleted reports whether opts contain IncludeDeleted, for entity
+// packages that write their own queries.
+func IncludesDeleted(opts []ReadOption) bool {
+	o := readOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.includeDeleted
+}
+
+// New returns the repository of T stored in table.
+func New[T any](table string, opts ...Option) *Repository[T] {
+	o := options{keys: []string{"id"}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	r := &Repository[T]{
+		table:      table,
+		entity:     lowerFirst(t.Name()),
+		keys:       o.keys,
+		meta:       newMeta(t),
+		coalesce:   toSet(o.coalesce),
+		readOnly:   toSet(o.readOnly),
+		keepUUID:   o.keepUUID,
+		softDelete: o.softDelete,
+	}
+	for _, name := range append(append([]string{}, o.coalesce...), o.readOnly...) {
+		if !r.meta.has(name) {
+			panic(fmt.Sprintf("repository: %s has no column %s", t.Name(), name))
+		}
+	}
+	for _, name := range r.keys {
+		if !r.meta.has(name) {
+			panic(fmt.Sprintf("repository: %s has no key column %s", t.Name(), name))
+		}
+	}
+	r.build()
+	return r
+}
+
+func (r *Repository[T]) build() {
+	selectList := make([]string, 0, len(r.meta.columns))
+	for _, c := range r.meta.columns {
+		if r.coalesce[c.name] {
+			selectList = append(selectList, fmt.Sprintf("COALESCE(%s, '') as %s", c.name, c.name))
+		} else {
+			selectList = append(selectList, c.name)
+		}
+		if r.readOnly[c.name] || (len(r.keys) == 1 && c.name == r.keys[0]) {
+			continue
+		}
+		r.copyColumns = append(r.copyColumns, c.name)
+		r.insertColumns = append(r.insertColumns, c.name)
+		switch {
+		case c.name == "uuid", c.name == "created_by", c.name == "created_at", r.isKey(c.name):
+		default:
+			r.updateColumns = append(r.updateColumns, c.name)
+		}
+	}
+	r.selectList = strings.Join(selectList, ",\n\t")
+}
+
+// Table returns the table name the repository reads and writes.
+func (r *Repository[T]) Table() string {
+	return r.table
+}
+
+// Columns returns every column in struct order, the shape of the rows the
+// SELECT statements return (the DBColumns of the entity).
+func (r *Repository[T]) Columns() []string {
+	return r.meta.names()
+}
+
+// InsertColumns returns the columns written by InsertMany's COPY, i.e. every
+// column except a generated key and read only columns.
+func (r *Repository[T]) InsertColumns() []string {
+	return append([]string{}, r.copyColumns...)
+}
+
+// UpdateColumns returns the columns set by Update, in the order of its bind
+// parameters; the key is bound last.
+func (r *Repository[T]) UpdateColumns() []string {
+	return append([]string{}, r.updateColumns...)
+}
+
+// Filterable returns the whitelist used to validate pagination filters and
+// sorting.
+func (r *Repository[T]) Filterable() filter.Columns {
+	columns := filter.Columns{}
+	for _, c := range r.meta.columns {
+		columns[c.name] = c.kind
+	}
+	return columns
+}
+
+// NotDeleted returns the condition matching rows that are not soft deleted,
+// or "" when the repository does not soft delete or opts include
+// IncludeDeleted.
+func (r *Repository[T]) NotDeleted(opts []ReadOption) string {
+	if !r.softDelete || IncludesDeleted(opts) {
+		return ""
+	}
+	return "deleted_at IS NULL"
+}
+
+// SelectSQL returns "SELECT <columns> FROM <table>" for custom queries that
+// only need to add their own WHERE/ORDER BY.
+func (r *Repository[T]) SelectSQL() string {
+	return fmt.Sprintf("SELECT \n\t%s\n\tFROM %s", r.selectList, r.table)
+}
+
+func (r *Repository[T]) isKey(name string) bool {
+	for _, key := range r.keys {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WhereAny returns the clause restricting each of columns to the ids given
+// for it at the same position, with bind parameters numbered from $1, for the
+// list queries of link tables. Columns without ids are not restricted; the
+// clause is empty when none has ids.
+func WhereAny(columns []string, ids ...[]int) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	for i, column := range columns {
+		if i >= len(ids) || len(ids[i]) == 0 {
+			continue
+		}
+		args = append(args, ids[i])
+		conditions = append(conditions, fmt.Sprintf("%s = ANY($%d)", column, len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+)
+
+// column describes one `db` tagged field of an entity.
+type column struct {
+	name  string
+	index []int
+	kind  reflect.Type
+}
+
+// meta is the column layout of an entity, read once from its struct tags in
+// declaration order.
+type meta struct {
+	columns []column
+	byName  map[string]int
+}
+
+func newMeta(t reflect.Type) *meta {
+	m := &meta{byName: map[string]int{}}
+	m.collect(t, nil)
+	return m
+}
+
+func (m *meta) collect(t reflect.Type, parent []int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		index := append(append([]int{}, parent...), i)
+		tag, tagged := field.Tag.Lookup("db")
+		if field.Anonymous && !tagged && field.Type.Kind() == reflect.Struct {
+			m.collect(field.Type, index)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		m.byName[name] = len(m.columns)
+		m.columns = append(m.columns, column{name: name, index: index, kind: field.Type})
+	}
+}
+
+func (m *meta) has(name string) bool {
+	_, ok := m.byName[name]
+	return ok
+}
+
+func (m *meta) names() []string {
+	names := make([]string, 0, len(m.columns))
+	for _, c := range m.columns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func (m *meta) value(v reflect.Value, name string) interface{} {
+	return v.FieldByIndex(m.columns[m.byName[name]].index).Interface()
+}
+
+func (m *meta) field(v reflect.Value, name string) reflect.Value {
+	return v.FieldByIndex(m.columns[m.byName[name]].index)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"reflect"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// Values returns the column values of entity in Columns order.
+func (r *Repository[T]) Values(entity *T) []interface{} {
+	v := reflect.ValueOf(entity).Elem()
+	values := make([]interface{}, 0, len(r.meta.columns))
+	for _, c := range r.meta.columns {
+		values = append(values, v.FieldByIndex(c.index).Interface())
+	}
+	return values
+}
+
+// AddToMockRows builds the pgxmock rows a SELECT of dataList would return,
+// for the Add*ToMockRows helpers of the entity tests.
+func (r *Repository[T]) AddToMockRows(mock pgxmock.PgxPoolIface, dataList []T) *pgxmock.Rows {
+	rows := mock.NewRows(r.Columns())
+	for i := range dataList {
+		rows.AddRow(r.Values(&dataList[i])...)
+	}
+	return rows
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// Upsert describes an idempotent batch write: rows are copied into a
+// temporary table and merged into Table with INSERT ... ON CONFLICT on the
+// natural key, so replaying the same rows updates them instead of creating
+// duplicates. Existing rows whose columns, apart from updated_at and
+// updated_by, already hold the new values are left alone, so a replay does
+// not fire their UPDATE triggers. The target table needs a unique index on
+// Conflict.
+type Upsert struct {
+	// Table is the target table.
+	Table string
+	// Columns are the columns of every row, in order.
+	Columns []string
+	// Conflict is the natural key identifying a row.
+	Conflict []string
+	// Keep lists the columns left untouched when an existing row is
+	// updated. It defaults to uuid, created_by and created_at.
+	Keep []string
+}
+
+var defaultKeep = []string{"uuid", "created_by", "created_at"}
+
+// unguarded are the columns a changed row is not recognised by, as every
+// write sets them.
+var unguarded = []string{"updated_at", "updated_by"}
+
+// CopyFrom merges rows into the table in a single transaction (a savepoint
+// when dbConnPgx already is one) and returns how many rows were inserted and
+// how many existing rows were changed. When a batch holds the same natural
+// key more than once the last row wins.
+func (u Upsert) CopyFrom(ctx context.Context, dbConnPgx utils.PgxIface, rows [][]interface{}) (int64, int64, error) {
+	if len(rows) == 0 {
+		return 0, 0, nil
+	}
+	var inserted, updated int64
+	temp := "tmp_" + u.Table
+	err := utils.WithTx(ctx, dbConnPgx, func(tx utils.PgxIface) error {
+		if _, err := tx.Exec(ctx, u.createTempSQL(temp)); err != nil {
+			return err
+		}
+		copyCount, err := tx.CopyFrom(ctx, pgx.Identifier{temp}, u.Columns, pgx.CopyFromRows(rows))
+		logging.Debug(ctx, "Upsert.CopyFrom: copied rows", logging.Entity(u.Table), logging.Rows(copyCount))
+		if err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, u.mergeSQL(temp)).Scan(&inserted, &updated); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, fmt.Sprintf(`DROP TABLE %s`, temp))
+		return err
+	})
+	if err != nil {
+		logging.ReturnedError(ctx, "Upsert.CopyFrom", err, logging.Entity(u.Table))
+		return 0, 0, err
+	}
+	return inserted, updated, nil
+}
+
+func (u Upsert) createTempSQL(temp string) string {
+	return fmt.Sprintf(`CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA`,
+		temp, strings.Join(u.Columns, ", "), u.Table)
+}
+
+// mergeSQL keeps the last row of each natural key (DISTINCT ON needs an
+// ORDER BY, ctid follows the COPY order), generates missing uuids, skips
+// unchanged rows and tells inserts from updates with xmax, which is 0 only for
+// freshly inserted tuples.
+func (u Upsert) mergeSQL(temp string) string {
+	keep := u.Keep
+	if keep == nil {
+		keep = defaultKeep
+	}
+	skip := toSet(append(append([]string{}, keep...), u.Conflict...))
+	sets := []string{}
+	current, excluded := []string{}, []string{}
+	ignored := toSet(unguarded)
+	for _, c := range u.Columns {
+		if skip[c] {
+			continue
+		}
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+		if !ignored[c] {
+			current = append(current, u.Table+"."+c)
+			excluded = append(excluded, "EXCLUDED."+c)
+		}
+	}
+	guard := ""
+	if len(current) > 0 {
+		guard = fmt.Sprintf("\n\t\tWHERE (%s) IS DISTINCT FROM (%s)", strings.Join(current, ", "), strings.Join(excluded, ", "))
+	}
+	selectList := make([]string, 0, len(u.Columns))
+	for _, c := range u.Columns {
+		if c == "uuid" {
+			c = "COALESCE(uuid, uuid_generate_v4()) AS uuid"
+		}
+		selectList = append(selectList, c)
+	}
+	columns := strings.Join(u.Columns, ", ")
+	conflict := strings.Join(u.Conflict, ", ")
+	return fmt.Sprintf(`WITH merged AS (
+		INSERT INTO %s (%s)
+		SELECT DISTINCT ON (%s) %s FROM %s ORDER BY %s, ctid DESC
+		ON CONFLICT (%s) DO UPDATE SET
+		%s%s
+		RETURNING (xmax = 0) AS inserted
+	)
+	SELECT
+		COUNT(*) FILTER (WHERE inserted),
+		COUNT(*) FILTER (WHERE NOT inserted)
+	FROM merged`,
+		u.Table, columns,
+		conflict, strings.Join(selectList, ", "), temp, conflict,
+		conflict,
+		strings.Join(sets, ",\n\t\t"), guard)
+}
@@ -0,0 +1,717 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+type base struct {
+	CreatedBy string    `json:"createdBy" db:"created_by"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedBy string    `json:"updatedBy" db:"updated_by"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+type widget struct {
+	ID      *int   `json:"id" db:"id"`
+	UUID    string `json:"uuid" db:"uuid"`
+	Name    string `json:"name" db:"name"`
+	URL     string `json:"url" db:"url,omitempty"`
+	Ignored string `json:"ignored" db:"-"`
+	base
+}
+
+var widgetRepository = New[widget]("widgets", Coalesce("url"))
+
+var TestData1 = widget{
+	ID:   utils.Ptr[int](1),
+	UUID: "880607ab-2833-4ad7-a231-b983a61c7b39",
+	Name: "first",
+	URL:  "https://example.com",
+	base: base{
+		CreatedBy: "SYSTEM",
+		CreatedAt: utils.SampleCreatedAtTime,
+		UpdatedBy: "SYSTEM",
+		UpdatedAt: utils.SampleCreatedAtTime,
+	},
+}
+
+var TestData2 = widget{
+	ID:   utils.Ptr[int](2),
+	UUID: "880607ab-2833-4ad7-a231-b983a61c7b40",
+	Name: "second",
+	base: base{
+		CreatedBy: "SYSTEM",
+		CreatedAt: utils.SampleCreatedAtTime,
+		UpdatedBy: "SYSTEM",
+		UpdatedAt: utils.SampleCreatedAtTime,
+	},
+}
+
+var TestAllData = []widget{TestData1, TestData2}
+
+func TestColumns(t *testing.T) {
+	want := []string{"id", "uuid", "name", "url", "created_by", "created_at", "updated_by", "updated_at"}
+	if diff := cmp.Diff(want, widgetRepository.Columns()); diff != "" {
+		t.Errorf("Columns mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want[1:], widgetRepository.InsertColumns()); diff != "" {
+		t.Errorf("InsertColumns mismatch (-want +got):\n%s", diff)
+	}
+	wantUpdate := []string{"name", "url", "updated_by", "updated_at"}
+	if diff := cmp.Diff(wantUpdate, widgetRepository.UpdateColumns()); diff != "" {
+		t.Errorf("UpdateColumns mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(widgetRepository.SelectSQL(), "COALESCE(url, '') as url") {
+		t.Errorf("expected url to be coalesced in %s", widgetRepository.SelectSQL())
+	}
+}
+
+func TestNewPanicsOnUnknownColumn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected New to panic for an unknown column")
+		}
+	}()
+	New[widget]("widgets", Coalesce("missing"))
+}
+
+func TestGet(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mockRows := widgetRepository.AddToMockRows(mock, []widget{TestData1})
+	mock.ExpectQuery("^SELECT (.+) FROM widgets WHERE id = \\$1").WithArgs(1).WillReturnRows(mockRows)
+	found, err := widgetRepository.Get(context.Background(), mock, 1)
+	if err != nil {
+		t.Fatalf("an error '%s' in Get", err)
+	}
+	if cmp.Equal(*found, TestData1, cmp.AllowUnexported(widget{})) == false {
+		t.Errorf("Expected widget From Method Get: %v is different from actual %v", found, TestData1)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetForErrNoRows(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectQuery("^SELECT (.+) FROM widgets").WithArgs(-1).WillReturnRows(mock.NewRows(widgetRepository.Columns()))
+	found, err := widgetRepository.Get(context.Background(), mock, -1)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
+	}
+	if found != nil {
+		t.Errorf("Expected nil, got: %v", found)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetByForUnknownColumn(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	if _, err := widgetRepository.GetBy(context.Background(), mock, "name; DROP TABLE widgets", "x"); err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+}
+
+func TestGetList(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	ids := []int{1, 2}
+	mockRows := widgetRepository.AddToMockRows(mock, TestAllData)
+	mock.ExpectQuery("^SELECT (.+) FROM widgets WHERE id = ANY").WithArgs(ids).WillReturnRows(mockRows)
+	found, err := widgetRepository.GetList(context.Background(), mock, ids)
+	if err != nil {
+		t.Fatalf("an error '%s' in GetList", err)
+	}
+	if len(found) != len(TestAllData) {
+		t.Errorf("Expected %d widgets, got %d", len(TestAllData), len(found))
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	_start, _end := 5, 15
+	mockRows := widgetRepository.AddToMockRows(mock, TestAllData)
+	mock.ExpectQuery("^SELECT (.+) FROM widgets ORDER BY name DESC OFFSET").WithArgs(_start, _end-_start).WillReturnRows(mockRows)
+	found, err := widgetRepository.Pagination(context.Background(), mock, &_start, &_end, "DESC", "name", nil)
+	if err != nil {
+		t.Fatalf("an error '%s' in Pagination", err)
+	}
+	if len(found) != len(TestAllData) {
+		t.Errorf("Expected %d widgets, got %d", len(TestAllData), len(found))
+	}
+	if _, err := widgetRepository.Pagination(context.Background(), mock, &_start, &_end, "DESC", "ignored", nil); err == nil {
+		t.Errorf("was expecting an error for a column without db tag, but there was none")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCount(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectQuery("^SELECT COUNT\\(\\*\\) FROM widgets").WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+	count, err := widgetRepository.Count(context.Background(), mock)
+	if err != nil {
+		t.Fatalf("an error '%s' in Count", err)
+	}
+	if *count != 2 {
+		t.Errorf("Expected 2, got %d", *count)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO widgets").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.CreatedBy, //3
+	).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
+	ID, err := widgetRepository.Insert(context.Background(), mock, &targetData)
+	if err != nil {
+		t.Fatalf("an error '%s' in Insert", err)
+	}
+	if ID != 7 {
+		t.Errorf("Expected ID 7, got %d", ID)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInsertReturningUUID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO widgets (.+) RETURNING id, uuid").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.CreatedBy, //3
+	).WillReturnRows(pgxmock.NewRows([]string{"id", "uuid"}).AddRow(7, "return-uuid"))
+	mock.ExpectCommit()
+	ID, uuid, err := widgetRepository.InsertReturningUUID(context.Background(), mock, &targetData)
+	if err != nil {
+		t.Fatalf("an error '%s' in InsertReturningUUID", err)
+	}
+	if ID != 7 || uuid != "return-uuid" {
+		t.Errorf("Expected 7 and return-uuid, got %d and %s", ID, uuid)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInsertOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO widgets").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.CreatedBy, //3
+	).WillReturnError(errors.New("Random SQL Error"))
+	mock.ExpectRollback()
+	ID, err := widgetRepository.Insert(context.Background(), mock, &targetData)
+	if err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if ID != -1 {
+		t.Errorf("Expected ID -1, got %d", ID)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInsertMany(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectCopyFrom(pgx.Identifier{"widgets"}, widgetRepository.InsertColumns()).WillReturnResult(2)
+	count, err := widgetRepository.InsertMany(context.Background(), mock, TestAllData)
+	if err != nil {
+		t.Fatalf("an error '%s' in InsertMany", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected copy count 2, got %d", count)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE widgets SET (.+) WHERE id=\\$4").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.UpdatedBy, //3
+		targetData.ID,        //4
+	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	if err = widgetRepository.Update(context.Background(), mock, &targetData); err != nil {
+		t.Fatalf("an error '%s' in Update", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateOnFailureAtParameter(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	targetData.ID = nil
+	if err = widgetRepository.Update(context.Background(), mock, &targetData); err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateIfUnchanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	newUpdatedAt := targetData.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE widgets SET (.+) WHERE id=\\$4 AND updated_at=\\$5").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.UpdatedBy, //3
+		targetData.ID,        //4
+		targetData.UpdatedAt, //5
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}).AddRow(newUpdatedAt))
+	mock.ExpectCommit()
+	if err = widgetRepository.UpdateIfUnchanged(context.Background(), mock, &targetData); err != nil {
+		t.Fatalf("an error '%s' in UpdateIfUnchanged", err)
+	}
+	if !targetData.UpdatedAt.Equal(newUpdatedAt) {
+		t.Errorf("expected UpdatedAt to be %v, got %v", newUpdatedAt, targetData.UpdatedAt)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateIfUnchangedOnConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	targetData.Name = "edited"
+	current := TestData1
+	current.Name = "edited elsewhere"
+	current.UpdatedAt = TestData1.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE widgets SET").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.UpdatedBy, //3
+		targetData.ID,        //4
+		targetData.UpdatedAt, //5
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}))
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE id = \\$1\\s+FOR SHARE$").WithArgs(targetData.ID).WillReturnRows(widgetRepository.AddToMockRows(mock, []widget{current}))
+	mock.ExpectRollback()
+	err = widgetRepository.UpdateIfUnchanged(context.Background(), mock, &targetData)
+	if !errors.Is(err, dberrors.ErrConflict) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrConflict, err)
+	}
+	var conflict *dberrors.ConflictError[widget]
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a *dberrors.ConflictError[widget], got %T", err)
+	}
+	if cmp.Equal(*conflict.Current, current, cmp.AllowUnexported(widget{})) == false {
+		t.Errorf("Expected the current row %v, got %v", current, conflict.Current)
+	}
+	if !targetData.UpdatedAt.Equal(TestData1.UpdatedAt) {
+		t.Errorf("expected UpdatedAt to be left alone, got %v", targetData.UpdatedAt)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateIfUnchangedOnRemovedRow(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE widgets SET").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.UpdatedBy, //3
+		targetData.ID,        //4
+		targetData.UpdatedAt, //5
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}))
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE id = \\$1\\s+FOR SHARE$").WithArgs(targetData.ID).WillReturnRows(mock.NewRows(widgetRepository.Columns()))
+	mock.ExpectRollback()
+	err = widgetRepository.UpdateIfUnchanged(context.Background(), mock, &targetData)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+// A soft deleted row still conflicts, and comes back as the current row.
+func TestUpdateIfUnchangedOnSoftDeletedRow(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	current := TestData1
+	current.UpdatedAt = TestData1.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE widgets SET").WithArgs(
+		targetData.Name,      //1
+		targetData.URL,       //2
+		targetData.UpdatedBy, //3
+		targetData.ID,        //4
+		targetData.UpdatedAt, //5
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}))
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE id = \\$1\\s+FOR SHARE$").WithArgs(targetData.ID).WillReturnRows(softWidgetRepository.AddToMockRows(mock, []widget{current}))
+	mock.ExpectRollback()
+	err = softWidgetRepository.UpdateIfUnchanged(context.Background(), mock, &targetData)
+	var conflict *dberrors.ConflictError[widget]
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a *dberrors.ConflictError[widget], got '%v'", err)
+	}
+	if cmp.Equal(*conflict.Current, current, cmp.AllowUnexported(widget{})) == false {
+		t.Errorf("Expected the current row %v, got %v", current, conflict.Current)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^DELETE FROM widgets WHERE id = \\$1").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+	if err = widgetRepository.Remove(context.Background(), mock, 1); err != nil {
+		t.Fatalf("an error '%s' in Remove", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+var softWidgetRepository = New[widget]("widgets", Coalesce("url"), SoftDelete())
+
+func TestSoftRemove(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE widgets SET\\s+deleted_at = (.+),\\s+deleted_by = (.+)lyle.audit_actor(.+) WHERE id = \\$1 AND deleted_at IS NULL").WithArgs(1).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	if err = softWidgetRepository.Remove(context.Background(), mock, 1); err != nil {
+		t.Fatalf("an error '%s' in Remove", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE widgets SET\\s+deleted_at = NULL,\\s+deleted_by = NULL,\\s+updated_at = (.+) WHERE id = \\$1").WithArgs(1).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	if err = softWidgetRepository.Restore(context.Background(), mock, 1); err != nil {
+		t.Fatalf("an error '%s' in Restore", err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE widgets SET").WithArgs(2).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+	if err = softWidgetRepository.Restore(context.Background(), mock, 2); !errors.Is(err, dberrors.ErrNotFound) {
+		t.Errorf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
+	}
+	if err = widgetRepository.Restore(context.Background(), mock, 1); !errors.Is(err, ErrNoSoftDelete) {
+		t.Errorf("Expected ErrNoSoftDelete, got %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSoftDeleteReads(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	ctx := context.Background()
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE id = \\$1 AND deleted_at IS NULL$").WithArgs(1).WillReturnRows(widgetRepository.AddToMockRows(mock, []widget{TestData1}))
+	if _, err = softWidgetRepository.Get(ctx, mock, 1); err != nil {
+		t.Fatalf("an error '%s' in Get", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE id = \\$1$").WithArgs(1).WillReturnRows(widgetRepository.AddToMockRows(mock, []widget{TestData1}))
+	if _, err = softWidgetRepository.Get(ctx, mock, 1, IncludeDeleted()); err != nil {
+		t.Fatalf("an error '%s' in Get", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE deleted_at IS NULL$").WillReturnRows(widgetRepository.AddToMockRows(mock, TestAllData))
+	if _, err = softWidgetRepository.GetList(ctx, mock, nil); err != nil {
+		t.Fatalf("an error '%s' in GetList", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE id = ANY\\(\\$1\\) AND deleted_at IS NULL$").WithArgs([]int{1, 2}).WillReturnRows(widgetRepository.AddToMockRows(mock, TestAllData))
+	if _, err = softWidgetRepository.GetList(ctx, mock, []int{1, 2}); err != nil {
+		t.Fatalf("an error '%s' in GetList", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM widgets\\s+WHERE deleted_at IS NULL AND \\(name = \\$1\\)").WithArgs("first").WillReturnRows(widgetRepository.AddToMockRows(mock, []widget{TestData1}))
+	if _, err = softWidgetRepository.Pagination(ctx, mock, nil, nil, "", "", filter.All(filter.Equal("name", "first"))); err != nil {
+		t.Fatalf("an error '%s' in Pagination", err)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM widgets$").WillReturnRows(widgetRepository.AddToMockRows(mock, TestAllData))
+	if _, err = softWidgetRepository.Pagination(ctx, mock, nil, nil, "", "", nil, IncludeDeleted()); err != nil {
+		t.Fatalf("an error '%s' in Pagination", err)
+	}
+	mock.ExpectQuery("^SELECT COUNT\\(\\*\\) FROM widgets WHERE deleted_at IS NULL$").WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+	if _, err = softWidgetRepository.Count(ctx, mock); err != nil {
+		t.Fatalf("an error '%s' in Count", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestKeylessRepository(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	links := New[widget]("widget_links", Key(""))
+	if _, err := links.Get(context.Background(), mock, 1); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey from Get, got %v", err)
+	}
+	if err := links.Remove(context.Background(), mock, 1); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey from Remove, got %v", err)
+	}
+	if diff := cmp.Diff(links.Columns(), links.InsertColumns()); diff != "" {
+		t.Errorf("Expected every column to be inserted (-want +got):\n%s", diff)
+	}
+}
+
+type widgetLink struct {
+	WidgetID *int   `json:"widgetId" db:"widget_id"`
+	OtherID  *int   `json:"otherId" db:"other_id"`
+	Note     string `json:"note" db:"note"`
+	base
+}
+
+var widgetLinkRepository = New[widgetLink]("widget_links", Key("widget_id", "other_id"))
+
+func TestCompositeKeyRepository(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := widgetLink{WidgetID: utils.Ptr[int](1), OtherID: utils.Ptr[int](2), Note: "note", base: TestData1.base}
+	if diff := cmp.Diff(widgetLinkRepository.Columns(), widgetLinkRepository.InsertColumns()); diff != "" {
+		t.Errorf("Expected the key columns to be inserted (-want +got):\n%s", diff)
+	}
+	mockRows := widgetLinkRepository.AddToMockRows(mock, []widgetLink{targetData})
+	mock.ExpectQuery("^SELECT (.+) FROM widget_links WHERE widget_id = \\$1 AND other_id = \\$2").WithArgs(1, 2).WillReturnRows(mockRows)
+	found, err := widgetLinkRepository.Get(context.Background(), mock, CompositeKey{1, 2})
+	if err != nil {
+		t.Fatalf("an error '%s' in Get", err)
+	}
+	if cmp.Equal(*found, targetData, cmp.AllowUnexported(widgetLink{})) == false {
+		t.Errorf("Expected widgetLink From Method Get: %v is different from actual %v", found, targetData)
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO widget_links (.+) RETURNING widget_id, other_id").WithArgs(
+		targetData.WidgetID,  //1
+		targetData.OtherID,   //2
+		targetData.Note,      //3
+		targetData.CreatedBy, //4
+	).WillReturnRows(pgxmock.NewRows([]string{"widget_id", "other_id"}).AddRow(1, 2))
+	mock.ExpectCommit()
+	var widgetID, otherID int
+	if err = widgetLinkRepository.InsertReturningKey(context.Background(), mock, &targetData, &widgetID, &otherID); err != nil {
+		t.Fatalf("an error '%s' in InsertReturningKey", err)
+	}
+	if widgetID != 1 || otherID != 2 {
+		t.Errorf("Expected key (1, 2), got (%d, %d)", widgetID, otherID)
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE widget_links SET (.+) WHERE widget_id=\\$3 AND other_id=\\$4").WithArgs(
+		targetData.Note,      //1
+		targetData.UpdatedBy, //2
+		targetData.WidgetID,  //3
+		targetData.OtherID,   //4
+	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	if err = widgetLinkRepository.Update(context.Background(), mock, &targetData); err != nil {
+		t.Fatalf("an error '%s' in Update", err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("^DELETE FROM widget_links WHERE widget_id = \\$1 AND other_id = \\$2").WithArgs(1, 2).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+	if err = widgetLinkRepository.Remove(context.Background(), mock, CompositeKey{1, 2}); err != nil {
+		t.Fatalf("an error '%s' in Remove", err)
+	}
+	if err = widgetLinkRepository.Remove(context.Background(), mock, 1); !errors.Is(err, dberrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a partial key, got %v", err)
+	}
+	if _, err = widgetLinkRepository.Insert(context.Background(), mock, &targetData); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey from Insert, got %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWhereAny(t *testing.T) {
+	clause, args := WhereAny([]string{"widget_id", "other_id"}, nil, []int{1, 2})
+	if clause != "WHERE other_id = ANY($1)" {
+		t.Errorf("Expected only other_id to be restricted, got %q", clause)
+	}
+	if diff := cmp.Diff([]interface{}{[]int{1, 2}}, args); diff != "" {
+		t.Errorf("Unexpected args (-want +got):\n%s", diff)
+	}
+	if clause, args = WhereAny([]string{"widget_id", "other_id"}); clause != "" || args != nil {
+		t.Errorf("Expected no clause without ids, got %q %v", clause, args)
+	}
+}
+
+func TestUpsertMergeSQL(t *testing.T) {
+	upsert := Upsert{Table: "widgets", Columns: widgetRepository.InsertColumns(), Conflict: []string{"name"}}
+	sql := upsert.mergeSQL("tmp_widgets")
+	for _, want := range []string{
+		"INSERT INTO widgets (uuid, name, url, created_by, created_at, updated_by, updated_at)",
+		"SELECT DISTINCT ON (name) COALESCE(uuid, uuid_generate_v4()) AS uuid, name,",
+		"ON CONFLICT (name) DO UPDATE SET",
+		"url = EXCLUDED.url",
+		"updated_at = EXCLUDED.updated_at",
+		"WHERE (widgets.url) IS DISTINCT FROM (EXCLUDED.url)",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %s", want, sql)
+		}
+	}
+	for _, unwanted := range []string{"uuid = EXCLUDED", "created_at = EXCLUDED", "name = EXCLUDED"} {
+		if strings.Contains(sql, unwanted) {
+			t.Errorf("did not expect %q in %s", unwanted, sql)
+		}
+	}
+}
+
+func TestUpsertMany(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^CREATE TEMP TABLE tmp_widgets").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectCopyFrom(pgx.Identifier{"tmp_widgets"}, widgetRepository.InsertColumns()).WillReturnResult(2)
+	mock.ExpectQuery("^WITH merged AS").WillReturnRows(pgxmock.NewRows([]string{"inserted", "updated"}).AddRow(int64(2), int64(0)))
+	mock.ExpectExec("^DROP TABLE tmp_widgets").WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mock.ExpectCommit()
+	inserted, updated, err := widgetRepository.UpsertMany(context.Background(), mock, []string{"name"}, TestAllData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpsertMany", err)
+	}
+	if inserted != 2 || updated != 0 {
+		t.Errorf("Expected 2 inserted and 0 updated, got %d and %d", inserted, updated)
+	}
+	if inserted, updated, err = widgetRepository.UpsertMany(context.Background(), mock, []string{"name"}, nil); err != nil || inserted != 0 || updated != 0 {
+		t.Errorf("Expected an empty batch to be a no-op, got %d, %d, %v", inserted, updated, err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
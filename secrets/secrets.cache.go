@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache is a SecretProvider that keeps the values of another for a TTL, so a
+// rotated secret is picked up without a restart. Errors are not cached.
+type Cache struct {
+	provider SecretProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// NewCache caches the secrets of p for ttl. A ttl of 0 caches them forever.
+func NewCache(p SecretProvider, ttl time.Duration) *Cache {
+	return &Cache{provider: p, ttl: ttl, now: time.Now, entries: map[string]cacheEntry{}}
+}
+
+func (c *Cache) GetSecret(ctx context.Context, ref string) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[ref]
+	c.mu.Unlock()
+	if ok && (c.ttl == 0 || c.now().Before(entry.expires)) {
+		return entry.value, nil
+	}
+	value, err := c.provider.GetSecret(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.entries[ref] = cacheEntry{value: value, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// Invalidate drops ref from the cache, or every secret when ref is empty.
+func (c *Cache) Invalidate(ref string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ref == "" {
+		c.entries = map[string]cacheEntry{}
+		return
+	}
+	delete(c.entries, ref)
+}
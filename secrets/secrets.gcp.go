@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
+)
+
+// GCPProvider reads secrets from GCP Secret Manager. ref is the full resource
+// name of a version, e.g. "projects/p/secrets/db-pass/versions/latest". The
+// client is created on first use and kept until Close.
+type GCPProvider struct {
+	mu     sync.Mutex
+	client *secretmanager.Client
+}
+
+func (p *GCPProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	result, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: ref})
+	if err != nil {
+		return "", fmt.Errorf("secrets: failed to access secret version: %w", err)
+	}
+	return string(result.Payload.Data), nil
+}
+
+func (p *GCPProvider) getClient(ctx context.Context) (*secretmanager.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		client, err := secretmanager.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("secrets: failed to create secretmanager client: %w", err)
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+// Close closes the client, if one was created.
+func (p *GCPProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		return nil
+	}
+	err := p.client.Close()
+	p.client = nil
+	return err
+}
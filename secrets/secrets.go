@@ -0,0 +1,223 @@
+// Package secrets reads secrets such as database passwords and bot tokens from
+// a pluggable SecretProvider.
+//
+// Callers keep referring to secrets by their GCP Secret Manager resource name,
+// e.g. "projects/p/secrets/db-pass/versions/latest". The other providers look
+// the secret up by its name, "db-pass": as the DB_PASS config key (see the
+// config package, which reads .env and the environment), as the file db-pass
+// in a directory of mounted secrets, or as a key of a local encrypted JSON
+// file. So local development only needs SECRET_PROVIDER=env and the values in
+// .env, not GCP credentials.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+)
+
+// SecretProvider returns the value of the secret ref refers to.
+type SecretProvider interface {
+	GetSecret(ctx context.Context, ref string) (string, error)
+}
+
+// ErrNotFound is returned, wrapped, for a secret the provider does not have.
+var ErrNotFound = errors.New("secret not found")
+
+// Providers selected by Config.Provider.
+const (
+	ProviderGCP   = "gcp"
+	ProviderEnv   = "env"
+	ProviderFile  = "file"
+	ProviderLocal = "local"
+)
+
+// DefaultTTL is how long secrets are cached unless SECRET_CACHE_TTL says
+// otherwise.
+const DefaultTTL = 5 * time.Minute
+
+// Config selects and configures a provider.
+type Config struct {
+	Provider string
+	// EnvPrefix is prepended to the environment variable names of ProviderEnv.
+	EnvPrefix string
+	// Dir holds one file per secret for ProviderFile.
+	Dir string
+	// File and Key are the encrypted JSON file of ProviderLocal and the base64
+	// AES-256 key it was encrypted with.
+	File string
+	Key  string
+	// TTL is how long values are cached. Negative disables the cache.
+	TTL time.Duration
+	// Values are read by ProviderEnv, config.Default() when nil.
+	Values *config.Values
+}
+
+// ConfigFromEnv returns ConfigFromValues of config.Default().
+func ConfigFromEnv() (Config, error) {
+	values, err := config.Default()
+	if err != nil {
+		return Config{}, err
+	}
+	return ConfigFromValues(values)
+}
+
+// ConfigFromValues reads SECRET_PROVIDER (gcp by default), SECRET_ENV_PREFIX,
+// SECRET_DIR, SECRET_FILE, SECRET_KEY and SECRET_CACHE_TTL from values.
+// ProviderEnv then looks secrets up in values too.
+func ConfigFromValues(values *config.Values) (Config, error) {
+	cfg := Config{
+		Provider:  values.Get("SECRET_PROVIDER"),
+		EnvPrefix: values.Get("SECRET_ENV_PREFIX"),
+		Dir:       values.Get("SECRET_DIR"),
+		File:      values.Get("SECRET_FILE"),
+		Key:       values.Get("SECRET_KEY"),
+		TTL:       DefaultTTL,
+		Values:    values,
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderGCP
+	}
+	if s := values.Get("SECRET_CACHE_TTL"); s != "" {
+		ttl, err := time.ParseDuration(s)
+		if err != nil {
+			return Config{}, fmt.Errorf("secrets: SECRET_CACHE_TTL: %w", err)
+		}
+		cfg.TTL = ttl
+	}
+	return cfg, nil
+}
+
+// New returns the provider described by cfg, cached unless cfg.TTL is negative.
+func New(cfg Config) (SecretProvider, error) {
+	var p SecretProvider
+	switch cfg.Provider {
+	case ProviderGCP, "":
+		p = &GCPProvider{}
+	case ProviderEnv:
+		p = EnvProvider{Prefix: cfg.EnvPrefix, Values: cfg.Values}
+	case ProviderFile:
+		if cfg.Dir == "" {
+			return nil, errors.New("secrets: file provider needs a directory")
+		}
+		p = FileProvider{Dir: cfg.Dir}
+	case ProviderLocal:
+		local, err := OpenLocalProvider(cfg.File, cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		p = local
+	default:
+		return nil, fmt.Errorf("secrets: unknown provider %q", cfg.Provider)
+	}
+	if cfg.TTL < 0 {
+		return p, nil
+	}
+	return NewCache(p, cfg.TTL), nil
+}
+
+var (
+	defaultMu       sync.Mutex
+	defaultProvider SecretProvider
+)
+
+// Default returns the provider set with SetDefault, or else the one described
+// by ConfigFromEnv.
+func Default() (SecretProvider, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultProvider == nil {
+		cfg, err := ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		p, err := New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		defaultProvider = p
+	}
+	return defaultProvider, nil
+}
+
+// SetDefault replaces the provider used by Get. A nil p goes back to
+// ConfigFromEnv on the next call.
+func SetDefault(p SecretProvider) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultProvider = p
+}
+
+// Get returns the secret ref refers to from the default provider.
+func Get(ctx context.Context, ref string) (string, error) {
+	p, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return p.GetSecret(ctx, ref)
+}
+
+// Name returns the secret name in a Secret Manager resource name, or ref
+// itself when it is not one.
+func Name(ref string) string {
+	parts := strings.Split(ref, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "secrets" {
+			return parts[i+1]
+		}
+	}
+	return ref
+}
+
+// EnvProvider reads secrets from the config keys named after the secret in
+// upper case, with dashes and dots as underscores: the environment variables
+// and the .env file.
+type EnvProvider struct {
+	Prefix string
+	// Values are the config the keys are looked up in, config.Default() when
+	// nil.
+	Values *config.Values
+}
+
+func (p EnvProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	key := p.Prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(Name(ref)))
+	var value string
+	var ok bool
+	if p.Values != nil {
+		value, ok = p.Values.Lookup(key)
+	} else {
+		value, ok = config.Lookup(key)
+	}
+	if !ok {
+		return "", fmt.Errorf("secrets: config key %s: %w", key, ErrNotFound)
+	}
+	return value, nil
+}
+
+// FileProvider reads secrets from the files in Dir named after the secret, as
+// mounted by Kubernetes or Docker. Trailing newlines are trimmed.
+type FileProvider struct {
+	Dir string
+}
+
+func (p FileProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	name := Name(ref)
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("secrets: invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(p.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("secrets: file %s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("secrets: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LocalProvider serves secrets from a JSON object of names to values,
+// encrypted with AES-256-GCM so the file can sit next to the code. Write it
+// with EncryptJSON. Secrets are looked up by their full ref first, then by
+// name.
+type LocalProvider struct {
+	secrets map[string]string
+}
+
+// encryptedFile is the layout of the file on disk.
+type encryptedFile struct {
+	Nonce string `json:"nonce"`
+	Data  string `json:"data"`
+}
+
+// OpenLocalProvider decrypts file with the base64 encoded 32 byte key.
+func OpenLocalProvider(file, key string) (*LocalProvider, error) {
+	if file == "" || key == "" {
+		return nil, errors.New("secrets: local provider needs a file and a key")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: %w", err)
+	}
+	secrets, err := DecryptJSON(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalProvider{secrets: secrets}, nil
+}
+
+func (p *LocalProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	if value, ok := p.secrets[ref]; ok {
+		return value, nil
+	}
+	if value, ok := p.secrets[Name(ref)]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("secrets: %s: %w", Name(ref), ErrNotFound)
+}
+
+// NewKey returns a random base64 encoded key for EncryptJSON.
+func NewKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptJSON encrypts secrets with the base64 encoded 32 byte key into the
+// file format read by OpenLocalProvider.
+func EncryptJSON(secrets map[string]string, key string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(encryptedFile{
+		Nonce: base64.StdEncoding.EncodeToString(nonce),
+		Data:  base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil)),
+	}, "", "  ")
+}
+
+// DecryptJSON reverses EncryptJSON.
+func DecryptJSON(data []byte, key string) (map[string]string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("secrets: invalid secrets file: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(file.Nonce)
+	if err != nil || len(nonce) != gcm.NonceSize() {
+		return nil, errors.New("secrets: invalid secrets file nonce")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: invalid secrets file data: %w", err)
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("secrets: cannot decrypt secrets file, wrong key?")
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("secrets: invalid secrets file content: %w", err)
+	}
+	return secrets, nil
+}
+
+func newGCM(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 32 {
+		return nil, errors.New("secrets: key must be 32 bytes, base64 encoded")
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,172 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+)
+
+const dbPassRef = "projects/lyle/secrets/db-pass/versions/latest"
+
+func TestName(t *testing.T) {
+	tests := map[string]string{
+		dbPassRef:                     "db-pass",
+		"projects/lyle/secrets/token": "token",
+		"DISCORD_TOKEN":               "DISCORD_TOKEN",
+	}
+	for ref, want := range tests {
+		if got := Name(ref); got != want {
+			t.Errorf("Name(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("LYLE_DB_PASS", "secret")
+	config.Reset()
+	defer config.Reset()
+	p := EnvProvider{Prefix: "LYLE_"}
+	got, err := p.GetSecret(context.Background(), dbPassRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret" {
+		t.Errorf("expected secret, got %q", got)
+	}
+	if _, err := p.GetSecret(context.Background(), "projects/lyle/secrets/missing/versions/1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestEnvProviderReadsDotEnv(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("SECRET_PROVIDER=env\nDB_PASS=secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	values, err := config.Load(config.Options{EnvFile: envFile, Environ: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ConfigFromValues(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.TTL = -1
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.GetSecret(context.Background(), dbPassRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret" {
+		t.Errorf("expected secret, got %q", got)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db-pass"), []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := FileProvider{Dir: dir}
+	got, err := p.GetSecret(context.Background(), dbPassRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret" {
+		t.Errorf("expected secret, got %q", got)
+	}
+	if _, err := p.GetSecret(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := p.GetSecret(context.Background(), ".."); err == nil {
+		t.Error("expected an error for a name outside the directory")
+	}
+}
+
+func TestLocalProvider(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := EncryptJSON(map[string]string{"db-pass": "secret"}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "secrets.json")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(Config{Provider: ProviderLocal, File: file, Key: key, TTL: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.GetSecret(context.Background(), dbPassRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret" {
+		t.Errorf("expected secret, got %q", got)
+	}
+
+	otherKey, _ := NewKey()
+	if _, err := OpenLocalProvider(file, otherKey); err == nil {
+		t.Error("expected an error decrypting with the wrong key")
+	}
+}
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	p.calls++
+	return ref, nil
+}
+
+func TestCache(t *testing.T) {
+	provider := &countingProvider{}
+	cache := NewCache(provider, time.Minute)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	cache.GetSecret(ctx, "a")
+	cache.GetSecret(ctx, "a")
+	if provider.calls != 1 {
+		t.Errorf("expected 1 call while cached, got %d", provider.calls)
+	}
+	now = now.Add(2 * time.Minute)
+	cache.GetSecret(ctx, "a")
+	if provider.calls != 2 {
+		t.Errorf("expected 2 calls after the ttl, got %d", provider.calls)
+	}
+	cache.Invalidate("a")
+	cache.GetSecret(ctx, "a")
+	if provider.calls != 3 {
+		t.Errorf("expected 3 calls after invalidating, got %d", provider.calls)
+	}
+}
+
+func TestGetUsesDefault(t *testing.T) {
+	t.Setenv("SECRET_PROVIDER", ProviderEnv)
+	t.Setenv("DB_PASS", "secret")
+	config.Reset()
+	defer config.Reset()
+	SetDefault(nil)
+	defer SetDefault(nil)
+	got, err := Get(context.Background(), dbPassRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret" {
+		t.Errorf("expected secret, got %q", got)
+	}
+}
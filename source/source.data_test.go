@@ -4,38 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",             //1
-	"uuid",           //2
-	"name",           //3
-	"alternate_name", //4
-	"url",            //5
-	"ticker",         //6
-	"description",    //7
-	"created_by",     //8
-	"created_at",     //9
-	"updated_by",     //10
-	"updated_at",     //11
-}
-var DBColumnsInsertSources = []string{
-	"uuid",           //1
-	"name",           //2
-	"alternate_name", //3
-	"url",            //4
-	"ticker",         //5
-	"description",    //6
-	"created_by",     //7
-	"created_at",     //8
-	"updated_by",     //9
-	"updated_at",     //10
-}
+var DBColumns = SourceRepository.Columns()
+var DBColumnsInsertSources = SourceRepository.InsertColumns()
 
 var TestData1 = Source{
 	ID:            utils.Ptr[int](1),                      //1
@@ -68,23 +48,7 @@ var TestData2 = Source{
 var TestAllData = []Source{TestData1, TestData2}
 
 func AddSourceToMockRows(mock pgxmock.PgxPoolIface, dataList []Source) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.UUID,          //2
-			data.Name,          //3
-			data.AlternateName, //4
-			data.URL,           //5
-			data.Ticker,        //6
-			data.Description,   //7
-			data.CreatedBy,     //8
-			data.CreatedAt,     //9
-			data.UpdatedBy,     //10
-			data.UpdatedAt,     //11
-		)
-	}
-	return rows
+	return SourceRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetSource(t *testing.T) {
@@ -120,8 +84,8 @@ func TestGetSourceForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM sources").WithArgs(sourceID).WillReturnRows(noRows)
 	foundSource, err := GetSource(mock, &sourceID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetSource", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundSource != nil {
 		t.Errorf("Expected Source From Method GetSource: to be empty but got this: %v", foundSource)
@@ -237,7 +201,7 @@ func TestGetSourceList(t *testing.T) {
 	dataList := TestAllData
 	ids := []int{1, 2}
 	mockRows := AddSourceToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM sources").WillReturnRows(mockRows)
+	mock.ExpectQuery("^SELECT (.+) FROM sources").WithArgs(ids).WillReturnRows(mockRows)
 	foundSourceList, err := GetSourceList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetSourceList", err)
@@ -259,7 +223,7 @@ func TestGetSourceListForErr(t *testing.T) {
 	}
 	defer mock.Close()
 	ids := []int{1, 2}
-	mock.ExpectQuery("^SELECT (.+) FROM sources").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	mock.ExpectQuery("^SELECT (.+) FROM sources").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundSourceList, err := GetSourceList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSourceList", err)
@@ -280,7 +244,7 @@ func TestGetSourceListForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	ids := []int{1, 2}
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM sources").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM sources").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundSource, err := GetSourceList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSourceList", err)
@@ -382,6 +346,74 @@ func TestUpdateSourceOnFailure(t *testing.T) {
 	}
 }
 
+func TestUpdateSourceIfUnchanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	newUpdatedAt := targetData.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE sources").WithArgs(
+		targetData.Name,          //1
+		targetData.AlternateName, //2
+		targetData.URL,           //3
+		targetData.Ticker,        //4
+		targetData.Description,   //5
+		targetData.UpdatedBy,     //6
+		targetData.ID,            //7
+		targetData.UpdatedAt,     //8
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}).AddRow(newUpdatedAt))
+	mock.ExpectCommit()
+	err = UpdateSourceIfUnchanged(mock, &targetData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpdateSourceIfUnchanged", err)
+	}
+	if !targetData.UpdatedAt.Equal(newUpdatedAt) {
+		t.Errorf("expected UpdatedAt to be %v, got %v", newUpdatedAt, targetData.UpdatedAt)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateSourceIfUnchangedOnConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	current := TestData1
+	current.Description = "edited elsewhere"
+	current.UpdatedAt = TestData1.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE sources").WithArgs(
+		targetData.Name,          //1
+		targetData.AlternateName, //2
+		targetData.URL,           //3
+		targetData.Ticker,        //4
+		targetData.Description,   //5
+		targetData.UpdatedBy,     //6
+		targetData.ID,            //7
+		targetData.UpdatedAt,     //8
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}))
+	mock.ExpectQuery("^SELECT (.+) FROM sources(.+)FOR SHARE$").WithArgs(targetData.ID).WillReturnRows(AddSourceToMockRows(mock, []Source{current}))
+	mock.ExpectRollback()
+	err = UpdateSourceIfUnchanged(mock, &targetData)
+	var conflict *dberrors.ConflictError[Source]
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a *dberrors.ConflictError[Source], got '%v'", err)
+	}
+	if cmp.Equal(*conflict.Current, current) == false {
+		t.Errorf("Expected the current Source %v, got %v", current, conflict.Current)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestInsertSource(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -398,7 +430,6 @@ func TestInsertSource(t *testing.T) {
 		targetData.Ticker,        //4
 		targetData.Description,   //5
 		targetData.CreatedBy,     //6
-		targetData.CreatedBy,     //7
 	).WillReturnRows(pgxmock.NewRows([]string{"source_id"}).AddRow(1))
 	mock.ExpectCommit()
 	sourceID, err := InsertSource(mock, &targetData)
@@ -447,7 +478,6 @@ func TestInsertSourceOnFailure(t *testing.T) {
 		targetData.Ticker,        //4
 		targetData.Description,   //5
 		targetData.CreatedBy,     //6
-		targetData.CreatedBy,     //7
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	sourceID, err := InsertSource(mock, &targetData)
@@ -477,7 +507,6 @@ func TestInsertSourceOnFailureOnCommit(t *testing.T) {
 		targetData.Ticker,        //4
 		targetData.Description,   //5
 		targetData.CreatedBy,     //6
-		targetData.CreatedBy,     //7
 	).WillReturnRows(pgxmock.NewRows([]string{"source_id"}).AddRow(-1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -539,8 +568,8 @@ func TestGetSourceListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"ticker = 'COIN'", "name = 'coingecko'"}
-	mock.ExpectQuery("^SELECT (.+) FROM sources").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("ticker", "COIN"), filter.Equal("name", "coingecko"))
+	mock.ExpectQuery("^SELECT (.+) FROM sources").WithArgs("COIN", "coingecko", _start, _end-_start).WillReturnRows(mockRows)
 	foundSourceList, err := GetSourceListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetSourceListByPagination", err)
@@ -565,8 +594,8 @@ func TestGetSourceListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"ticker = 'COIN'", "name = 'coingecko'"}
-	mock.ExpectQuery("^SELECT (.+) FROM sources").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("ticker", "COIN"), filter.Equal("name", "coingecko"))
+	mock.ExpectQuery("^SELECT (.+) FROM sources").WithArgs("COIN", "coingecko").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundSourceList, err := GetSourceListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSourceListByPagination", err)
@@ -589,9 +618,9 @@ func TestGetSourceListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"ticker = 'COIN'", "name = 'coingecko'"}
+	filters := filter.All(filter.Equal("ticker", "COIN"), filter.Equal("name", "coingecko"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM sources").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM sources").WithArgs("COIN", "coingecko").WillReturnRows(differentModelRows)
 	foundSourceList, err := GetSourceListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSourceListByPagination", err)
@@ -7,30 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"source_id",   //1
-	"job_id",      //2
-	"uuid",        //3
-	"description", //4
-	"created_by",  //5
-	"created_at",  //6
-	"updated_by",  //7
-	"updated_at",  //8
-}
-var DBColumnsInsertSourceJobs = []string{
-	"source_id",   //1
-	"job_id",      //2
-	"uuid",        //3
-	"description", //4
-	"created_by",  //5
-	"created_at",  //6
-	"updated_by",  //7
-	"updated_at",  //8
-}
+var DBColumns = SourceJobRepository.Columns()
+var DBColumnsInsertSourceJobs = SourceJobRepository.InsertColumns()
 
 var TestData1 = SourceJob{
 	SourceID:    utils.Ptr[int](1),                      //1
@@ -57,20 +41,7 @@ var TestData2 = SourceJob{
 var TestAllData = []SourceJob{TestData1, TestData2}
 
 func AddSourceJobToMockRows(mock pgxmock.PgxPoolIface, dataList []SourceJob) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.SourceID,    //1
-			data.JobID,       //2
-			data.UUID,        //3
-			data.Description, //4
-			data.CreatedBy,   //5
-			data.CreatedAt,   //6
-			data.UpdatedBy,   //7
-			data.UpdatedAt,   //8
-		)
-	}
-	return rows
+	return SourceJobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetSourceJob(t *testing.T) {
@@ -108,8 +79,8 @@ func TestGetSourceJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WithArgs(sourceID, jobID).WillReturnRows(noRows)
 	foundSourceJob, err := GetSourceJob(mock, &sourceID, &jobID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetSourceJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundSourceJob != nil {
 		t.Errorf("Expected SourceJob From Method GetSourceJob: to be empty but got this: %v", foundSourceJob)
@@ -196,8 +167,8 @@ func TestGetSourceJobBySourceIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WithArgs(sourceID).WillReturnRows(noRows)
 	foundSourceJob, err := GetSourceJobBySourceID(mock, &sourceID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetSourceJobBySourceID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundSourceJob != nil {
 		t.Errorf("Expected SourceJob From Method GetSourceJobBySourceID: to be empty but got this: %v", foundSourceJob)
@@ -609,10 +580,10 @@ func TestGetSourceJobListByPagination(t *testing.T) {
 	mockRows := AddSourceJobToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "source_id"
 	_order := "ASC"
-	filters := []string{"source_id = 1", "job_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("source_id", "1"), filter.Equal("job_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundSourceJobList, err := GetSourceJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetSourceJobListByPagination", err)
@@ -635,10 +606,10 @@ func TestGetSourceJobListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "source_id"
 	_order := "ASC"
-	filters := []string{"source_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("source_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundSourceJobList, err := GetSourceJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSourceJobListByPagination", err)
@@ -659,11 +630,11 @@ func TestGetSourceJobListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "source_id"
 	_order := "ASC"
-	filters := []string{"source_id = 1"}
+	filters := filter.All(filter.Equal("source_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM source_jobs").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundSourceJobList, err := GetSourceJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSourceJobListByPagination", err)
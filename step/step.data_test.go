@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -31,23 +33,7 @@ var DBColumns = []string{
 	"updated_by",     //15
 	"updated_at",     //16
 }
-var DBColumnsInsertSteps = []string{
-	"pool_id",        //1
-	"parent_step_id", //2
-	"uuid",           //3
-	"name",           //4
-	"alternate_name", //5
-	"start_date",     //6
-	"end_date",       //7
-	"description",    //8
-	"action_type_id", //9
-	"function_name",  //10
-	"step_order",     //11
-	"created_by",     //12
-	"created_at",     //13
-	"updated_by",     //14
-	"updated_at",     //15
-}
+var DBColumnsInsertSteps = StepRepository.InsertColumns()
 
 var TestData1 = Step{
 	ID:            utils.Ptr[int](1),                               //1
@@ -90,28 +76,7 @@ var TestData2 = Step{
 var TestAllData = []Step{TestData1, TestData2}
 
 func AddStepToMockRows(mock pgxmock.PgxPoolIface, dataList []Step) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.PoolID,        //2
-			data.ParentStepId,  //3
-			data.UUID,          //4
-			data.Name,          //5
-			data.AlternateName, //6
-			data.StartDate,     //7
-			data.EndDate,       //8
-			data.Description,   //9
-			data.ActionTypeID,  //10
-			data.FunctionName,  //11
-			data.StepOrder,     //12
-			data.CreatedBy,     //13
-			data.CreatedAt,     //14
-			data.UpdatedBy,     //15
-			data.UpdatedAt,     //16
-		)
-	}
-	return rows
+	return StepRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetStep(t *testing.T) {
@@ -147,8 +112,8 @@ func TestGetStepForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM steps").WithArgs(stepID).WillReturnRows(noRows)
 	foundStep, err := GetStep(mock, &stepID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStep", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStep != nil {
 		t.Errorf("Expected Step From Method GetStep: to be empty but got this: %v", foundStep)
@@ -265,8 +230,8 @@ func TestGetSteps(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddStepToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM steps").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM steps").WithArgs(ids).WillReturnRows(mockRows)
 	foundStepList, err := GetSteps(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetSteps", err)
@@ -287,8 +252,8 @@ func TestGetStepsForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM steps").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM steps").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStepList, err := GetSteps(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSteps", err)
@@ -308,8 +273,8 @@ func TestGetStepsForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM steps").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM steps").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundStep, err := GetSteps(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetSteps", err)
@@ -531,17 +496,16 @@ func TestUpdateStep(t *testing.T) {
 	mock.ExpectExec("^UPDATE steps").WithArgs(
 		targetData.PoolID,        //1
 		targetData.ParentStepId,  //2
-		targetData.UUID,          //3
-		targetData.Name,          //4
-		targetData.AlternateName, //5
-		targetData.StartDate,     //6
-		targetData.EndDate,       //7
-		targetData.Description,   //8
-		targetData.ActionTypeID,  //9
-		targetData.FunctionName,  //10
-		targetData.StepOrder,     //11
-		targetData.UpdatedBy,     //12
-		targetData.ID,            //13
+		targetData.Name,          //3
+		targetData.AlternateName, //4
+		targetData.StartDate,     //5
+		targetData.EndDate,       //6
+		targetData.Description,   //7
+		targetData.ActionTypeID,  //8
+		targetData.FunctionName,  //9
+		targetData.StepOrder,     //10
+		targetData.UpdatedBy,     //11
+		targetData.ID,            //12
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateStep(mock, &targetData)
@@ -598,17 +562,16 @@ func TestUpdateStepOnFailure(t *testing.T) {
 	mock.ExpectExec("^UPDATE steps").WithArgs(
 		targetData.PoolID,        //1
 		targetData.ParentStepId,  //2
-		targetData.UUID,          //3
-		targetData.Name,          //4
-		targetData.AlternateName, //5
-		targetData.StartDate,     //6
-		targetData.EndDate,       //7
-		targetData.Description,   //8
-		targetData.ActionTypeID,  //9
-		targetData.FunctionName,  //10
-		targetData.StepOrder,     //11
-		targetData.UpdatedBy,     //12
-		targetData.ID,            //13
+		targetData.Name,          //3
+		targetData.AlternateName, //4
+		targetData.StartDate,     //5
+		targetData.EndDate,       //6
+		targetData.Description,   //7
+		targetData.ActionTypeID,  //8
+		targetData.FunctionName,  //9
+		targetData.StepOrder,     //10
+		targetData.UpdatedBy,     //11
+		targetData.ID,            //12
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -789,8 +752,8 @@ func TestGetStepListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1", "parent_step_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM steps").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("pool_id", "1"), filter.Equal("parent_step_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM steps").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundStepList, err := GetStepListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStepListByPagination", err)
@@ -815,8 +778,8 @@ func TestGetStepListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM steps").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("pool_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM steps").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStepList, err := GetStepListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStepListByPagination", err)
@@ -839,9 +802,9 @@ func TestGetStepListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1"}
+	filters := filter.All(filter.Equal("pool_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM steps").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM steps").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundStepList, err := GetStepListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStepListByPagination", err)
@@ -8,46 +8,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",               //1
-	"step_id",          //2
-	"asset_id",         //3
-	"swap_asset_id",    //4
-	"target_pool_id",   //5
-	"uuid",             //6
-	"name",             //7
-	"alternate_name",   //8
-	"start_date",       //9
-	"end_date",         //10
-	"description",      //11
-	"action_parameter", //12
-	"created_by",       //13
-	"created_at",       //14
-	"updated_by",       //15
-	"updated_at",       //16
-}
-var DBColumnsInsertStepAssets = []string{
-	"step_id",          //1
-	"asset_id",         //2
-	"swap_asset_id",    //3
-	"target_pool_id",   //4
-	"uuid",             //5
-	"name",             //6
-	"alternate_name",   //7
-	"start_date",       //8
-	"end_date",         //9
-	"description",      //10
-	"action_parameter", //11
-	"created_by",       //12
-	"created_at",       //13
-	"updated_by",       //14
-	"updated_at",       //15
-}
+var DBColumns = StepAssetRepository.Columns()
+var DBColumnsInsertStepAssets = StepAssetRepository.InsertColumns()
 
 var TestData1 = StepAsset{
 	ID:              utils.Ptr[int](1),                                   //1
@@ -90,28 +59,7 @@ var TestData2 = StepAsset{
 var TestAllData = []StepAsset{TestData1, TestData2}
 
 func AddStepAssetToMockRows(mock pgxmock.PgxPoolIface, dataList []StepAsset) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,              //1
-			data.StepID,          //2
-			data.AssetID,         //3
-			data.SwapAssetID,     //4
-			data.TargetPoolID,    //5
-			data.UUID,            //6
-			data.Name,            //7
-			data.AlternateName,   //8
-			data.StartDate,       //9
-			data.EndDate,         //10
-			data.Description,     //11
-			data.ActionParameter, //12
-			data.CreatedBy,       //13
-			data.CreatedAt,       //14
-			data.UpdatedBy,       //15
-			data.UpdatedAt,       //16
-		)
-	}
-	return rows
+	return StepAssetRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetStepAsset(t *testing.T) {
@@ -147,8 +95,8 @@ func TestGetStepAssetForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WithArgs(stepAssetID).WillReturnRows(noRows)
 	foundStepAsset, err := GetStepAsset(mock, &stepAssetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStepAsset", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStepAsset != nil {
 		t.Errorf("Expected StepAsset From Method GetStepAsset: to be empty but got this: %v", foundStepAsset)
@@ -265,8 +213,8 @@ func TestGetStepAssets(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddStepAssetToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WithArgs(ids).WillReturnRows(mockRows)
 	foundStepAssetList, err := GetStepAssets(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStepAssets", err)
@@ -287,8 +235,8 @@ func TestGetStepAssetsForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStepAssetList, err := GetStepAssets(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStepAssets", err)
@@ -308,8 +256,8 @@ func TestGetStepAssetsForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundStepAsset, err := GetStepAssets(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStepAssets", err)
@@ -467,15 +415,14 @@ func TestUpdateStepAsset(t *testing.T) {
 		targetData.AssetID,         //2
 		targetData.SwapAssetID,     //3
 		targetData.TargetPoolID,    //4
-		targetData.UUID,            //5
-		targetData.Name,            //6
-		targetData.AlternateName,   //7
-		targetData.StartDate,       //8
-		targetData.EndDate,         //9
-		targetData.Description,     //10
-		targetData.ActionParameter, //11
-		targetData.UpdatedBy,       //12
-		targetData.ID,              //13
+		targetData.Name,            //5
+		targetData.AlternateName,   //6
+		targetData.StartDate,       //7
+		targetData.EndDate,         //8
+		targetData.Description,     //9
+		targetData.ActionParameter, //10
+		targetData.UpdatedBy,       //11
+		targetData.ID,              //12
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateStepAsset(mock, &targetData)
@@ -534,15 +481,14 @@ func TestUpdateStepAssetOnFailure(t *testing.T) {
 		targetData.AssetID,         //2
 		targetData.SwapAssetID,     //3
 		targetData.TargetPoolID,    //4
-		targetData.UUID,            //5
-		targetData.Name,            //6
-		targetData.AlternateName,   //7
-		targetData.StartDate,       //8
-		targetData.EndDate,         //9
-		targetData.Description,     //10
-		targetData.ActionParameter, //11
-		targetData.UpdatedBy,       //12
-		targetData.ID,              //13
+		targetData.Name,            //5
+		targetData.AlternateName,   //6
+		targetData.StartDate,       //7
+		targetData.EndDate,         //8
+		targetData.Description,     //9
+		targetData.ActionParameter, //10
+		targetData.UpdatedBy,       //11
+		targetData.ID,              //12
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -723,8 +669,8 @@ func TestGetStepAssetListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1", "parent_step_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("step_id", "1"), filter.Equal("asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundStepAssetList, err := GetStepAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStepAssetListByPagination", err)
@@ -749,8 +695,8 @@ func TestGetStepAssetListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("step_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStepAssetList, err := GetStepAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStepAssetListByPagination", err)
@@ -773,9 +719,9 @@ func TestGetStepAssetListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1"}
+	filters := filter.All(filter.Equal("step_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM step_assets").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundStepAssetList, err := GetStepAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStepAssetListByPagination", err)
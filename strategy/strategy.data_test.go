@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -27,19 +29,7 @@ var DBColumns = []string{
 	"updated_by",       //11
 	"updated_at",       //12
 }
-var DBColumnsInsertStrategies = []string{
-	"uuid",             //1
-	"name",             //2
-	"alternate_name",   //3
-	"start_date",       //4
-	"end_date",         //5
-	"description",      //6
-	"strategy_type_id", //7
-	"created_by",       //8
-	"created_at",       //9
-	"updated_by",       //10
-	"updated_at",       //11
-}
+var DBColumnsInsertStrategies = StrategyRepository.InsertColumns()
 
 var TestData1 = Strategy{
 	ID:             utils.Ptr[int](1),                               //1
@@ -74,24 +64,7 @@ var TestData2 = Strategy{
 var TestAllData = []Strategy{TestData1, TestData2}
 
 func AddStrategyToMockRows(mock pgxmock.PgxPoolIface, dataList []Strategy) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,             //1
-			data.UUID,           //2
-			data.Name,           //3
-			data.AlternateName,  //4
-			data.StartDate,      //5
-			data.EndDate,        //6
-			data.Description,    //7
-			data.StrategyTypeID, //8
-			data.CreatedBy,      //9
-			data.CreatedAt,      //10
-			data.UpdatedBy,      //11
-			data.UpdatedAt,      //12
-		)
-	}
-	return rows
+	return StrategyRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetStrategy(t *testing.T) {
@@ -127,8 +100,8 @@ func TestGetStrategyForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM strategies").WithArgs(strategyID).WillReturnRows(noRows)
 	foundStrategy, err := GetStrategy(mock, &strategyID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStrategy", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStrategy != nil {
 		t.Errorf("Expected Strategy From Method GetStrategy: to be empty but got this: %v", foundStrategy)
@@ -247,8 +220,8 @@ func TestGetStrategies(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddStrategyToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM strategies").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM strategies").WithArgs(ids).WillReturnRows(mockRows)
 	foundStrategyList, err := GetStrategies(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStrategies", err)
@@ -269,8 +242,8 @@ func TestGetStrategiesForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM strategies").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM strategies").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStrategyList, err := GetStrategies(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategies", err)
@@ -290,8 +263,8 @@ func TestGetStrategiesForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM strategies").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM strategies").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundStrategy, err := GetStrategies(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategies", err)
@@ -683,8 +656,8 @@ func TestGetStrategyListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1", "parent_strategy_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM strategies").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("strategy_type_id", "1"), filter.Equal("id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM strategies").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundStrategyList, err := GetStrategyListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStrategyListByPagination", err)
@@ -709,8 +682,8 @@ func TestGetStrategyListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM strategies").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("strategy_type_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM strategies").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStrategyList, err := GetStrategyListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyListByPagination", err)
@@ -733,9 +706,9 @@ func TestGetStrategyListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"pool_id = 1"}
+	filters := filter.All(filter.Equal("strategy_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM strategies").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM strategies").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundStrategyList, err := GetStrategyListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyListByPagination", err)
@@ -7,52 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"strategy_id",        //1
-	"job_id",             //2
-	"uuid",               //3
-	"name",               //4
-	"alternate_name",     //5
-	"start_date",         //6
-	"end_date",           //7
-	"description",        //8
-	"status_id",          //9
-	"response_status",    //10
-	"request_url",        //11
-	"request_body",       //12
-	"request_method",     //13
-	"response_data",      //14
-	"response_data_json", //15
-	"created_by",         //16
-	"created_at",         //17
-	"updated_by",         //18
-	"updated_at",         //19
-}
-var DBColumnsInsertStrategyJobList = []string{
-	"strategy_id",        //1
-	"job_id",             //2
-	"uuid",               //3
-	"name",               //4
-	"alternate_name",     //5
-	"start_date",         //6
-	"end_date",           //7
-	"description",        //8
-	"status_id",          //9
-	"response_status",    //10
-	"request_url",        //11
-	"request_body",       //12
-	"request_method",     //13
-	"response_data",      //14
-	"response_data_json", //15
-	"created_by",         //16
-	"created_at",         //17
-	"updated_by",         //18
-	"updated_at",         //19
-}
+var DBColumns = StrategyJobRepository.Columns()
+var DBColumnsInsertStrategyJobList = StrategyJobRepository.InsertColumns()
 
 var TestData1 = StrategyJob{
 	StrategyID:       utils.Ptr[int](1),                                                                //1
@@ -101,31 +63,7 @@ var TestData2 = StrategyJob{
 var TestAllData = []StrategyJob{TestData1, TestData2}
 
 func AddStrategyJobToMockRows(mock pgxmock.PgxPoolIface, dataList []StrategyJob) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.StrategyID,       //1
-			data.JobID,            //2
-			data.UUID,             //3
-			data.Name,             //4
-			data.AlternateName,    //5
-			data.StartDate,        //6
-			data.EndDate,          //7
-			data.Description,      //8
-			data.StatusID,         //9
-			data.ResponseStatus,   //10
-			data.RequestUrl,       //11
-			data.RequestBody,      //12
-			data.RequestMethod,    //13
-			data.ResponseData,     //14
-			data.ResponseDataJson, //15
-			data.CreatedBy,        //16
-			data.CreatedAt,        //17
-			data.UpdatedBy,        //18
-			data.UpdatedAt,        //19
-		)
-	}
-	return rows
+	return StrategyJobRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetStrategyJob(t *testing.T) {
@@ -163,8 +101,8 @@ func TestGetStrategyJobForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WithArgs(strategyID, jobID).WillReturnRows(noRows)
 	foundStrategyJob, err := GetStrategyJob(mock, &strategyID, &jobID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStrategyJob", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStrategyJob != nil {
 		t.Errorf("Expected StrategyJob From Method GetStrategyJob: to be empty but got this: %v", foundStrategyJob)
@@ -251,8 +189,8 @@ func TestGetStrategyJobByStrategyIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WithArgs(strategyID).WillReturnRows(noRows)
 	foundStrategyJob, err := GetStrategyJobByStrategyID(mock, &strategyID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStrategyJobByStrategyID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStrategyJob != nil {
 		t.Errorf("Expected StrategyJob From Method GetStrategyJobByStrategyID: to be empty but got this: %v", foundStrategyJob)
@@ -434,21 +372,21 @@ func TestUpdateStrategyJob(t *testing.T) {
 	targetData := TestData1
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE strategy_jobs").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.Description,                            //5
-		targetData.StatusID,                               //6
-		targetData.ResponseStatus,                         //7
-		targetData.RequestUrl,                             //8
-		targetData.RequestBody,                            //9
-		targetData.RequestMethod,                          //10
-		targetData.ResponseData,                           //11
-		targetData.ResponseDataJson,                       //12
-		targetData.UpdatedBy,                              //13
-		targetData.StrategyID,                             //14
-		targetData.JobID,                                  //15
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.UpdatedBy,        //13
+		targetData.StrategyID,       //14
+		targetData.JobID,            //15
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateStrategyJob(mock, &targetData)
@@ -503,21 +441,21 @@ func TestUpdateStrategyJobOnFailure(t *testing.T) {
 	targetData.StrategyID = utils.Ptr[int](-1)
 	mock.ExpectBegin()
 	mock.ExpectExec("^UPDATE strategy_jobs").WithArgs(
-		targetData.Name,          //1
-		targetData.AlternateName, //2
-		targetData.StartDate.Format(utils.LayoutPostgres), //3
-		targetData.EndDate.Format(utils.LayoutPostgres),   //4
-		targetData.Description,                            //5
-		targetData.StatusID,                               //6
-		targetData.ResponseStatus,                         //7
-		targetData.RequestUrl,                             //8
-		targetData.RequestBody,                            //9
-		targetData.RequestMethod,                          //10
-		targetData.ResponseData,                           //11
-		targetData.ResponseDataJson,                       //12
-		targetData.UpdatedBy,                              //13
-		targetData.StrategyID,                             //14
-		targetData.JobID,                                  //15
+		targetData.Name,             //1
+		targetData.AlternateName,    //2
+		targetData.StartDate,        //3
+		targetData.EndDate,          //4
+		targetData.Description,      //5
+		targetData.StatusID,         //6
+		targetData.ResponseStatus,   //7
+		targetData.RequestUrl,       //8
+		targetData.RequestBody,      //9
+		targetData.RequestMethod,    //10
+		targetData.ResponseData,     //11
+		targetData.ResponseDataJson, //12
+		targetData.UpdatedBy,        //13
+		targetData.StrategyID,       //14
+		targetData.JobID,            //15
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -542,20 +480,19 @@ func TestInsertStrategyJob(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO strategy_jobs").WithArgs(
 		targetData.StrategyID,       //1
 		targetData.JobID,            //2
-		targetData.UUID,             //3
-		targetData.Name,             //4
-		targetData.AlternateName,    //5
-		targetData.StartDate,        //6
-		targetData.EndDate,          //7
-		targetData.Description,      //8
-		targetData.StatusID,         //9
-		targetData.ResponseStatus,   //10
-		targetData.RequestUrl,       //11
-		targetData.RequestBody,      //12
-		targetData.RequestMethod,    //13
-		targetData.ResponseData,     //14
-		targetData.ResponseDataJson, //15
-		targetData.CreatedBy,        //16
+		targetData.Name,             //3
+		targetData.AlternateName,    //4
+		targetData.StartDate,        //5
+		targetData.EndDate,          //6
+		targetData.Description,      //7
+		targetData.StatusID,         //8
+		targetData.ResponseStatus,   //9
+		targetData.RequestUrl,       //10
+		targetData.RequestBody,      //11
+		targetData.RequestMethod,    //12
+		targetData.ResponseData,     //13
+		targetData.ResponseDataJson, //14
+		targetData.CreatedBy,        //15
 	).WillReturnRows(pgxmock.NewRows([]string{"strategy_id", "job_id"}).AddRow(1, 1))
 	mock.ExpectCommit()
 	strategyID, jobID, err := InsertStrategyJob(mock, &targetData)
@@ -603,20 +540,19 @@ func TestInsertStrategyJobOnFailure(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO strategy_jobs").WithArgs(
 		targetData.StrategyID,       //1
 		targetData.JobID,            //2
-		targetData.UUID,             //3
-		targetData.Name,             //4
-		targetData.AlternateName,    //5
-		targetData.StartDate,        //6
-		targetData.EndDate,          //7
-		targetData.Description,      //8
-		targetData.StatusID,         //9
-		targetData.ResponseStatus,   //10
-		targetData.RequestUrl,       //11
-		targetData.RequestBody,      //12
-		targetData.RequestMethod,    //13
-		targetData.ResponseData,     //14
-		targetData.ResponseDataJson, //15
-		targetData.CreatedBy,        //16
+		targetData.Name,             //3
+		targetData.AlternateName,    //4
+		targetData.StartDate,        //5
+		targetData.EndDate,          //6
+		targetData.Description,      //7
+		targetData.StatusID,         //8
+		targetData.ResponseStatus,   //9
+		targetData.RequestUrl,       //10
+		targetData.RequestBody,      //11
+		targetData.RequestMethod,    //12
+		targetData.ResponseData,     //13
+		targetData.ResponseDataJson, //14
+		targetData.CreatedBy,        //15
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	strategyID, jobID, err := InsertStrategyJob(mock, &targetData)
@@ -645,20 +581,19 @@ func TestInsertStrategyJobOnFailureOnCommit(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO strategy_jobs").WithArgs(
 		targetData.StrategyID,       //1
 		targetData.JobID,            //2
-		targetData.UUID,             //3
-		targetData.Name,             //4
-		targetData.AlternateName,    //5
-		targetData.StartDate,        //6
-		targetData.EndDate,          //7
-		targetData.Description,      //8
-		targetData.StatusID,         //9
-		targetData.ResponseStatus,   //10
-		targetData.RequestUrl,       //11
-		targetData.RequestBody,      //12
-		targetData.RequestMethod,    //13
-		targetData.ResponseData,     //14
-		targetData.ResponseDataJson, //15
-		targetData.CreatedBy,        //16
+		targetData.Name,             //3
+		targetData.AlternateName,    //4
+		targetData.StartDate,        //5
+		targetData.EndDate,          //6
+		targetData.Description,      //7
+		targetData.StatusID,         //8
+		targetData.ResponseStatus,   //9
+		targetData.RequestUrl,       //10
+		targetData.RequestBody,      //11
+		targetData.RequestMethod,    //12
+		targetData.ResponseData,     //13
+		targetData.ResponseDataJson, //14
+		targetData.CreatedBy,        //15
 	).WillReturnRows(pgxmock.NewRows([]string{"strategy_id", "job_id"}).AddRow(-1, -1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -721,10 +656,10 @@ func TestGetStrategyJobListByPagination(t *testing.T) {
 	mockRows := AddStrategyJobToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "strategy_id"
 	_order := "ASC"
-	filters := []string{"base_asset_id = 1", "frequency_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("strategy_id", "1"), filter.Equal("job_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundStrategyJobList, err := GetStrategyJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStrategyJobListByPagination", err)
@@ -747,10 +682,10 @@ func TestGetStrategyJobListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "strategy_id"
 	_order := "ASC"
-	filters := []string{"frequency_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("strategy_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStrategyJobList, err := GetStrategyJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyJobListByPagination", err)
@@ -771,11 +706,11 @@ func TestGetStrategyJobListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "strategy_id"
 	_order := "ASC"
-	filters := []string{"frequency_id = -1"}
+	filters := filter.All(filter.Equal("strategy_id", "-1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_jobs").WithArgs(int64(-1)).WillReturnRows(differentModelRows)
 	foundStrategyJobList, err := GetStrategyJobListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyJobListByPagination", err)
@@ -2,479 +2,143 @@ package strategymarketdataasset
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
-	"time"
 
-	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 )
 
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// StrategyMarketDataAssetRepository implements the standard queries on the
+// strategy_market_data_assets table.
+var StrategyMarketDataAssetRepository = repository.New[StrategyMarketDataAsset]("strategy_market_data_assets")
+
 func GetStrategyMarketDataAsset(dbConnPgx utils.PgxIface, strategyMarketDataAssetID *int) (*StrategyMarketDataAsset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT
-		id,
-		strategy_id,
-		base_asset_id,
-		quote_asset_id,
-		name,
-		uuid,
-		alternate_name,
-		start_date,
-		end_date,
-		ticker,
-		description,
-		source_id ,
-		frequency_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at
-	FROM strategy_market_data_assets 
-	WHERE id = $1`, *strategyMarketDataAssetID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	strategyMarketDataAsset, err := pgx.CollectOneRow(row, pgx.RowToStructByName[StrategyMarketDataAsset])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &strategyMarketDataAsset, nil
+	return GetStrategyMarketDataAssetCtx(ctx, dbConnPgx, strategyMarketDataAssetID)
+}
+
+func GetStrategyMarketDataAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyMarketDataAssetID *int) (*StrategyMarketDataAsset, error) {
+	return StrategyMarketDataAssetRepository.Get(ctx, dbConnPgx, *strategyMarketDataAssetID)
 }
 
 func RemoveStrategyMarketDataAsset(dbConnPgx utils.PgxIface, strategyMarketDataAssetID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveStrategyMarketDataAsset DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM strategy_market_data_assets WHERE id = $1`
+	return RemoveStrategyMarketDataAssetCtx(ctx, dbConnPgx, strategyMarketDataAssetID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *strategyMarketDataAssetID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveStrategyMarketDataAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyMarketDataAssetID *int) error {
+	return StrategyMarketDataAssetRepository.Remove(ctx, dbConnPgx, *strategyMarketDataAssetID)
 }
 
 func GetStrategyMarketDataAssets(dbConnPgx utils.PgxIface, ids []int) ([]StrategyMarketDataAsset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT 
-		id,
-		strategy_id,
-		base_asset_id,
-		quote_asset_id,
-		name,
-		uuid,
-		alternate_name,
-		start_date,
-		end_date,
-		ticker,
-		description,
-		source_id ,
-		frequency_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at 
-	FROM strategy_market_data_assets`
-	if len(ids) > 0 {
-		strIds := utils.SplitToString(ids, ",")
-		additionalQuery := fmt.Sprintf(` WHERE id IN (%s)`, strIds)
-		sql += additionalQuery
-	}
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStrategyMarketDataAssetsCtx(ctx, dbConnPgx, ids)
+}
 
-	strategyMarketDataAssets, err := pgx.CollectRows(results, pgx.RowToStructByName[StrategyMarketDataAsset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return strategyMarketDataAssets, nil
+func GetStrategyMarketDataAssetsCtx(ctx context.Context, dbConnPgx utils.PgxIface, ids []int) ([]StrategyMarketDataAsset, error) {
+	return StrategyMarketDataAssetRepository.GetList(ctx, dbConnPgx, ids)
 }
 
 func GetStrategyMarketDataAssetsByUUIDs(dbConnPgx utils.PgxIface, UUIDList []string) ([]StrategyMarketDataAsset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-		id,
-		strategy_id,
-		base_asset_id,
-		quote_asset_id,
-		name,
-		uuid,
-		alternate_name,
-		start_date,
-		end_date,
-		ticker,
-		description,
-		source_id ,
-		frequency_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-	FROM strategy_market_data_assets
-	WHERE text(uuid) = ANY($1)
-	`, pq.Array(UUIDList))
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStrategyMarketDataAssetsByUUIDsCtx(ctx, dbConnPgx, UUIDList)
+}
 
-	strategyMarketDataAssets, err := pgx.CollectRows(results, pgx.RowToStructByName[StrategyMarketDataAsset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return strategyMarketDataAssets, nil
+func GetStrategyMarketDataAssetsByUUIDsCtx(ctx context.Context, dbConnPgx utils.PgxIface, UUIDList []string) ([]StrategyMarketDataAsset, error) {
+	return StrategyMarketDataAssetRepository.Select(ctx, dbConnPgx, `WHERE text(uuid) = ANY($1)`, pq.Array(UUIDList))
 }
 
 func GetStrategyMarketDataAssetsByStrategyID(dbConnPgx utils.PgxIface, strategyID *int) ([]StrategyMarketDataAsset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-		id,
-		strategy_id,
-		base_asset_id,
-		quote_asset_id,
-		name,
-		uuid,
-		alternate_name,
-		start_date,
-		end_date,
-		ticker,
-		description,
-		source_id ,
-		frequency_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at
-	FROM strategy_market_data_assets
-	WHERE strategy_id = $1
-	`, *strategyID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStrategyMarketDataAssetsByStrategyIDCtx(ctx, dbConnPgx, strategyID)
+}
 
-	strategyMarketDataAssets, err := pgx.CollectRows(results, pgx.RowToStructByName[StrategyMarketDataAsset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return strategyMarketDataAssets, nil
+func GetStrategyMarketDataAssetsByStrategyIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyID *int) ([]StrategyMarketDataAsset, error) {
+	return StrategyMarketDataAssetRepository.Select(ctx, dbConnPgx, `WHERE strategy_id = $1`, *strategyID)
 }
 
 func GetStartAndEndDateDiffStrategyMarketDataAssets(dbConnPgx utils.PgxIface, diffInDate *int) ([]StrategyMarketDataAsset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-		id,
-		strategy_id,
-		base_asset_id,
-		quote_asset_id,
-		name,
-		uuid,
-		alternate_name,
-		start_date,
-		end_date,
-		ticker,
-		description,
-		source_id ,
-		frequency_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at,
-	FROM strategy_market_data_assets
-	WHERE DATE_PART('day', AGE(start_date, end_date)) =$1
-	`, *diffInDate)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStartAndEndDateDiffStrategyMarketDataAssetsCtx(ctx, dbConnPgx, diffInDate)
+}
 
-	strategyMarketDataAssets, err := pgx.CollectRows(results, pgx.RowToStructByName[StrategyMarketDataAsset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return strategyMarketDataAssets, nil
+func GetStartAndEndDateDiffStrategyMarketDataAssetsCtx(ctx context.Context, dbConnPgx utils.PgxIface, diffInDate *int) ([]StrategyMarketDataAsset, error) {
+	return StrategyMarketDataAssetRepository.Select(ctx, dbConnPgx, `WHERE DATE_PART('day', AGE(start_date, end_date)) =$1`, *diffInDate)
 }
 
 func UpdateStrategyMarketDataAsset(dbConnPgx utils.PgxIface, strategyMarketDataAsset *StrategyMarketDataAsset) error {
-	// if the strategyMarketDataAsset id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if strategyMarketDataAsset.ID == nil || *strategyMarketDataAsset.ID == 0 {
-		return errors.New("strategyMarketDataAsset has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateStrategyMarketDataAsset DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `UPDATE strategy_market_data_assets SET 
-		strategy_id=$1, 
-		base_asset_id=$2, 
-		quote_asset_id=$3, 
-		uuid=$4, 
-		name=$5, 
-		alternate_name=$6, 
-		start_date=$7, 
-		end_date=$8, 
-		ticker=$9, 
-		description=$10, 
-		source_id=$11, 
-		frequency_id=$12, 
-		updated_by=$13, 
-		updated_at=current_timestamp at time zone 'UTC'
-		WHERE id=$14`
+	return UpdateStrategyMarketDataAssetCtx(ctx, dbConnPgx, strategyMarketDataAsset)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		strategyMarketDataAsset.StrategyID,    //1
-		strategyMarketDataAsset.BaseAssetID,   //2
-		strategyMarketDataAsset.QuoteAssetID,  //3
-		strategyMarketDataAsset.UUID,          //4
-		strategyMarketDataAsset.Name,          //5
-		strategyMarketDataAsset.AlternateName, //6
-		strategyMarketDataAsset.StartDate,     //7
-		strategyMarketDataAsset.EndDate,       //8
-		strategyMarketDataAsset.Ticker,        //9
-		strategyMarketDataAsset.Description,   //10
-		strategyMarketDataAsset.SourceID,      //11
-		strategyMarketDataAsset.FrequencyID,   //12
-		strategyMarketDataAsset.UpdatedBy,     //13
-		strategyMarketDataAsset.ID,            //14
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func UpdateStrategyMarketDataAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyMarketDataAsset *StrategyMarketDataAsset) error {
+	return StrategyMarketDataAssetRepository.Update(ctx, dbConnPgx, strategyMarketDataAsset)
+}
+
+// UpdateStrategyMarketDataAssetIfUnchanged updates strategyMarketDataAsset only
+// if the row still has the UpdatedAt strategyMarketDataAsset was read with.
+// Otherwise it returns a *dberrors.ConflictError[StrategyMarketDataAsset]
+// holding the current row.
+func UpdateStrategyMarketDataAssetIfUnchanged(dbConnPgx utils.PgxIface, strategyMarketDataAsset *StrategyMarketDataAsset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return UpdateStrategyMarketDataAssetIfUnchangedCtx(ctx, dbConnPgx, strategyMarketDataAsset)
+}
+
+func UpdateStrategyMarketDataAssetIfUnchangedCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyMarketDataAsset *StrategyMarketDataAsset) error {
+	return StrategyMarketDataAssetRepository.UpdateIfUnchanged(ctx, dbConnPgx, strategyMarketDataAsset)
 }
 
 func InsertStrategyMarketDataAsset(dbConnPgx utils.PgxIface, strategyMarketDataAsset *StrategyMarketDataAsset) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
-	tx, err := dbConnPgx.Begin(ctx)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if err != nil {
-		log.Printf("Error in InsertStrategyMarketDataAsset DbConn.Begin   %s", err.Error())
-		return -1, err
-	}
-	var insertID int
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO strategy_market_data_assets 
-	(
-		strategy_id,
-		base_asset_id,
-		quote_asset_id,
-		uuid,
-		name,
-		alternate_name,
-		start_date,
-		end_date,
-		ticker,
-		description,
-		source_id ,
-		frequency_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at
-		) VALUES (
-			$1,
-			$2, 
-			$3, 
-			uuid_generate_v4(), 
-			$4, 
-			$5, 
-			$6,
-			$7,
-			$8,
-			$9,
-			$10,
-			$11,
-			$12,
-			current_timestamp at time zone 'UTC',
-			$12,
-			current_timestamp at time zone 'UTC'
-		)
-		RETURNING id`,
-		strategyMarketDataAsset.StrategyID,    //1
-		strategyMarketDataAsset.BaseAssetID,   //2
-		strategyMarketDataAsset.QuoteAssetID,  //3
-		strategyMarketDataAsset.Name,          //4
-		strategyMarketDataAsset.AlternateName, //5
-		strategyMarketDataAsset.StartDate,     //6
-		strategyMarketDataAsset.EndDate,       //7
-		strategyMarketDataAsset.Ticker,        //8
-		strategyMarketDataAsset.Description,   //9
-		strategyMarketDataAsset.SourceID,      //10
-		strategyMarketDataAsset.FrequencyID,   //11
-		strategyMarketDataAsset.CreatedBy,     //12
-	).Scan(&insertID)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	return int(insertID), nil
+	return InsertStrategyMarketDataAssetCtx(ctx, dbConnPgx, strategyMarketDataAsset)
+}
+
+func InsertStrategyMarketDataAssetCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyMarketDataAsset *StrategyMarketDataAsset) (int, error) {
+	return StrategyMarketDataAssetRepository.Insert(ctx, dbConnPgx, strategyMarketDataAsset)
 }
 func InsertStrategyMarketDataAssets(dbConnPgx utils.PgxIface, strategyMarketDataAssets []StrategyMarketDataAsset) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range strategyMarketDataAssets {
-		strategyMarketDataAsset := strategyMarketDataAssets[i]
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(strategyMarketDataAsset.UUID)
-		row := []interface{}{
-			strategyMarketDataAsset.StrategyID,    //1
-			strategyMarketDataAsset.BaseAssetID,   //2
-			strategyMarketDataAsset.QuoteAssetID,  //3
-			uuidString,                            //4
-			strategyMarketDataAsset.Name,          //5
-			strategyMarketDataAsset.AlternateName, //6
-			&strategyMarketDataAsset.StartDate,    //7
-			&strategyMarketDataAsset.EndDate,      //8
-			strategyMarketDataAsset.Ticker,        //9
-			strategyMarketDataAsset.Description,   //10
-			strategyMarketDataAsset.SourceID,      //11
-			strategyMarketDataAsset.FrequencyID,   //12
-			strategyMarketDataAsset.CreatedBy,     //13
-			&now,                                  //14
-			strategyMarketDataAsset.CreatedBy,     //15
-			&now,                                  //16
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"strategy_market_data_assets"},
-		[]string{
-			"strategy_id",    //1
-			"base_asset_id",  //2
-			"quote_asset_id", //3
-			"uuid",           //4
-			"name",           //5
-			"alternate_name", //6
-			"start_date",     //7
-			"end_date",       //8
-			"ticker",         //9
-			"description",    //10
-			"source_id",      //11
-			"frequency_id",   //12
-			"created_by",     //13
-			"created_at",     //14
-			"updated_by",     //15
-			"updated_at",     //16
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertStrategyMarketDataAssets: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
+	return InsertStrategyMarketDataAssetsCtx(ctx, dbConnPgx, strategyMarketDataAssets)
+}
 
-	return nil
+func InsertStrategyMarketDataAssetsCtx(ctx context.Context, dbConnPgx utils.PgxIface, strategyMarketDataAssets []StrategyMarketDataAsset) error {
+	_, err := StrategyMarketDataAssetRepository.InsertMany(ctx, dbConnPgx, strategyMarketDataAssets)
+	return dberrors.Wrap(err)
 }
 
 // for refinedev
-func GetStrategyMarketDataAssetListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]StrategyMarketDataAsset, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetStrategyMarketDataAssetListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]StrategyMarketDataAsset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `
-	SELECT
-		id,
-		strategy_id,
-		base_asset_id,
-		quote_asset_id,
-		name,
-		uuid,
-		alternate_name,
-		start_date,
-		end_date,
-		ticker,
-		description,
-		source_id ,
-		frequency_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at
-	FROM strategy_market_data_assets 
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStrategyMarketDataAssetListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
+}
 
-	strategyList, err := pgx.CollectRows(results, pgx.RowToStructByName[StrategyMarketDataAsset])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return strategyList, nil
+func GetStrategyMarketDataAssetListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]StrategyMarketDataAsset, error) {
+	return StrategyMarketDataAssetRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
 }
 
 func GetTotalStrategyMarketDataAssetsCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalStrategyMarketDataAssetsCountCtx(ctx, dbConnPgx)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT 
-	COUNT(*)
-	FROM strategy_market_data_assets
-	`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalStrategyMarketDataAssetsCountCtx(ctx context.Context, dbConnPgx utils.PgxIface) (*int, error) {
+	return StrategyMarketDataAssetRepository.Count(ctx, dbConnPgx)
 }
@@ -8,48 +8,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",             //1
-	"strategy_id",    //2
-	"base_asset_id",  //3
-	"quote_asset_id", //4
-	"uuid",           //5
-	"name",           //6
-	"alternate_name", //7
-	"start_date",     //8
-	"end_date",       //9
-	"ticker",         //10
-	"description",    //11
-	"source_id",      //12
-	"frequency_id",   //13
-	"created_by",     //14
-	"created_at",     //15
-	"updated_by",     //16
-	"updated_at",     //17
-}
-var DBColumnsInsertStrategyMarketDataAssets = []string{
-	"strategy_id",    //1
-	"base_asset_id",  //2
-	"quote_asset_id", //3
-	"uuid",           //4
-	"name",           //5
-	"alternate_name", //6
-	"start_date",     //7
-	"end_date",       //8
-	"ticker",         //9
-	"description",    //10
-	"source_id",      //11
-	"frequency_id",   //12
-	"created_by",     //13
-	"created_at",     //14
-	"updated_by",     //15
-	"updated_at",     //16
-}
+var DBColumns = StrategyMarketDataAssetRepository.Columns()
+var DBColumnsInsertStrategyMarketDataAssets = StrategyMarketDataAssetRepository.InsertColumns()
 
 var TestData1 = StrategyMarketDataAsset{
 	ID:            utils.Ptr[int](1),                               //1
@@ -94,29 +61,7 @@ var TestData2 = StrategyMarketDataAsset{
 var TestAllData = []StrategyMarketDataAsset{TestData1, TestData2}
 
 func AddStrategyMarketDataAssetToMockRows(mock pgxmock.PgxPoolIface, dataList []StrategyMarketDataAsset) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.StrategyID,    //2
-			data.BaseAssetID,   //3
-			data.QuoteAssetID,  //4
-			data.UUID,          //5
-			data.Name,          //6
-			data.AlternateName, //7
-			data.StartDate,     //8
-			data.EndDate,       //9
-			data.Ticker,        //10
-			data.Description,   //11
-			data.SourceID,      //12
-			data.FrequencyID,   //13
-			data.CreatedBy,     //14
-			data.CreatedAt,     //15
-			data.UpdatedBy,     //16
-			data.UpdatedAt,     //17
-		)
-	}
-	return rows
+	return StrategyMarketDataAssetRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetStrategyMarketDataAsset(t *testing.T) {
@@ -152,8 +97,8 @@ func TestGetStrategyMarketDataAssetForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WithArgs(strategyMarketDataAssetID).WillReturnRows(noRows)
 	foundStrategyMarketDataAsset, err := GetStrategyMarketDataAsset(mock, &strategyMarketDataAssetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStrategyMarketDataAsset", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStrategyMarketDataAsset != nil {
 		t.Errorf("Expected StrategyMarketDataAsset From Method GetStrategyMarketDataAsset: to be empty but got this: %v", foundStrategyMarketDataAsset)
@@ -270,8 +215,8 @@ func TestGetStrategyMarketDataAssets(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddStrategyMarketDataAssetToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WithArgs(ids).WillReturnRows(mockRows)
 	foundStrategyMarketDataAssetList, err := GetStrategyMarketDataAssets(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStrategyMarketDataAssets", err)
@@ -292,8 +237,8 @@ func TestGetStrategyMarketDataAssetsForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStrategyMarketDataAssetList, err := GetStrategyMarketDataAssets(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyMarketDataAssets", err)
@@ -313,8 +258,8 @@ func TestGetStrategyMarketDataAssetsForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundStrategyMarketDataAsset, err := GetStrategyMarketDataAssets(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyMarketDataAssets", err)
@@ -537,17 +482,16 @@ func TestUpdateStrategyMarketDataAsset(t *testing.T) {
 		targetData.StrategyID,    //1
 		targetData.BaseAssetID,   //2
 		targetData.QuoteAssetID,  //3
-		targetData.UUID,          //4
-		targetData.Name,          //5
-		targetData.AlternateName, //6
-		targetData.StartDate,     //7
-		targetData.EndDate,       //8
-		targetData.Ticker,        //9
-		targetData.Description,   //10
-		targetData.SourceID,      //11
-		targetData.FrequencyID,   //12
-		targetData.UpdatedBy,     //13
-		targetData.ID,            //14
+		targetData.Name,          //4
+		targetData.AlternateName, //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.Ticker,        //8
+		targetData.Description,   //9
+		targetData.SourceID,      //10
+		targetData.FrequencyID,   //11
+		targetData.UpdatedBy,     //12
+		targetData.ID,            //13
 	).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 	err = UpdateStrategyMarketDataAsset(mock, &targetData)
@@ -605,17 +549,16 @@ func TestUpdateStrategyMarketDataAssetOnFailure(t *testing.T) {
 		targetData.StrategyID,    //1
 		targetData.BaseAssetID,   //2
 		targetData.QuoteAssetID,  //3
-		targetData.UUID,          //4
-		targetData.Name,          //5
-		targetData.AlternateName, //6
-		targetData.StartDate,     //7
-		targetData.EndDate,       //8
-		targetData.Ticker,        //9
-		targetData.Description,   //10
-		targetData.SourceID,      //11
-		targetData.FrequencyID,   //12
-		targetData.UpdatedBy,     //13
-		targetData.ID,            //14
+		targetData.Name,          //4
+		targetData.AlternateName, //5
+		targetData.StartDate,     //6
+		targetData.EndDate,       //7
+		targetData.Ticker,        //8
+		targetData.Description,   //9
+		targetData.SourceID,      //10
+		targetData.FrequencyID,   //11
+		targetData.UpdatedBy,     //12
+		targetData.ID,            //13
 	).WillReturnError(fmt.Errorf("Cannot have -1 as ID"))
 
 	mock.ExpectRollback()
@@ -799,8 +742,8 @@ func TestGetStrategyMarketDataAssetListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"strategy_id = 1", "quote_asset_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("strategy_id", "1"), filter.Equal("quote_asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundStrategyMarketDataAssetList, err := GetStrategyMarketDataAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStrategyMarketDataAssetListByPagination", err)
@@ -825,8 +768,8 @@ func TestGetStrategyMarketDataAssetListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"strategy_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("strategy_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStrategyMarketDataAssetList, err := GetStrategyMarketDataAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyMarketDataAssetListByPagination", err)
@@ -849,9 +792,9 @@ func TestGetStrategyMarketDataAssetListByPaginationForCollectRowsErr(t *testing.
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"strategy_id = 1"}
+	filters := filter.All(filter.Equal("strategy_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM strategy_market_data_assets").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundStrategyMarketDataAssetList, err := GetStrategyMarketDataAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStrategyMarketDataAssetListByPagination", err)
@@ -2,305 +2,137 @@ package structuredvalue
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
-	"time"
 
-	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 )
 
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// StructuredValueRepository implements the standard queries on the
+// structured_values table.
+var StructuredValueRepository = repository.New[StructuredValue]("structured_values")
+
 func GetStructuredValue(dbConnPgx utils.PgxIface, structuredValueID *int) (*StructuredValue, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	structured_value_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at 
-	FROM structured_values 
-	WHERE id = $1`, *structuredValueID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	structuredValue, err := pgx.CollectOneRow(row, pgx.RowToStructByName[StructuredValue])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &structuredValue, nil
+	return GetStructuredValueCtx(ctx, dbConnPgx, structuredValueID)
+}
+
+func GetStructuredValueCtx(ctx context.Context, dbConnPgx utils.PgxIface, structuredValueID *int) (*StructuredValue, error) {
+	return StructuredValueRepository.Get(ctx, dbConnPgx, *structuredValueID)
 }
 
 func RemoveStructuredValue(dbConnPgx utils.PgxIface, structuredValueID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveStructuredValue DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM structured_values WHERE id = $1`
+	return RemoveStructuredValueCtx(ctx, dbConnPgx, structuredValueID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *structuredValueID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveStructuredValueCtx(ctx context.Context, dbConnPgx utils.PgxIface, structuredValueID *int) error {
+	return StructuredValueRepository.Remove(ctx, dbConnPgx, *structuredValueID)
 }
 
 func GetStructuredValueByStructuredValueTypeIDList(dbConnPgx utils.PgxIface, structuredValueTypeID *int) ([]StructuredValue, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return GetStructuredValueByStructuredValueTypeIDListCtx(ctx, dbConnPgx, structuredValueTypeID)
+}
+
+func GetStructuredValueByStructuredValueTypeIDListCtx(ctx context.Context, dbConnPgx utils.PgxIface, structuredValueTypeID *int) ([]StructuredValue, error) {
+	return StructuredValueRepository.Select(ctx, dbConnPgx, `WHERE structured_value_type_id = $1`, *structuredValueTypeID)
+}
+
+func GetStructuredValueByTypeAndName(dbConnPgx utils.PgxIface, typeName, name string) (*StructuredValue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `
-	SELECT 
-		id,
-		uuid, 
-		name, 
-		alternate_name, 
-		structured_value_type_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at 
-	FROM structured_values
-	WHERE structured_value_type_id =$1`, *structuredValueTypeID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStructuredValueByTypeAndNameCtx(ctx, dbConnPgx, typeName, name)
+}
 
-	structuredValues, err := pgx.CollectRows(results, pgx.RowToStructByName[StructuredValue])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return structuredValues, nil
+// GetStructuredValueByTypeAndNameCtx matches the names of the value and of its
+// type, or their alternate names, case-insensitively.
+func GetStructuredValueByTypeAndNameCtx(ctx context.Context, dbConnPgx utils.PgxIface, typeName, name string) (*StructuredValue, error) {
+	return StructuredValueRepository.SelectOne(ctx, dbConnPgx, `WHERE structured_value_type_id IN (
+		SELECT id FROM structured_value_types
+		WHERE LOWER(name) = LOWER($1) OR LOWER(alternate_name) = LOWER($1))
+	AND (LOWER(name) = LOWER($2) OR LOWER(alternate_name) = LOWER($2))
+	ORDER BY (LOWER(name) = LOWER($2)) DESC, id
+	LIMIT 1`, typeName, name)
 }
 
 func GetStructuredValueList(dbConnPgx utils.PgxIface, ids []int) ([]StructuredValue, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT  
-	id,
-	uuid, 
-	name, 
-	alternate_name, 
-	structured_value_type_id,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at 
-	FROM structured_values`
-	if len(ids) > 0 {
-		strIds := utils.SplitToString(ids, ",")
-		additionalQuery := fmt.Sprintf(` WHERE id IN (%s)`, strIds)
-		sql += additionalQuery
-	}
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStructuredValueListCtx(ctx, dbConnPgx, ids)
+}
 
-	structuredValues, err := pgx.CollectRows(results, pgx.RowToStructByName[StructuredValue])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return structuredValues, nil
+func GetStructuredValueListCtx(ctx context.Context, dbConnPgx utils.PgxIface, ids []int) ([]StructuredValue, error) {
+	return StructuredValueRepository.GetList(ctx, dbConnPgx, ids)
 }
 
 func UpdateStructuredValue(dbConnPgx utils.PgxIface, structuredValue *StructuredValue) error {
-	// if the structuredValue id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if structuredValue.ID == nil || *structuredValue.ID == 0 {
-		return errors.New("structuredValue has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateStructuredValue DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `UPDATE structured_values SET 
-		name=$1,  
-		alternate_name=$2, 
-		structured_value_type_id=$3,
-		updated_by=$4, 
-		updated_at=current_timestamp at time zone 'UTC'
-		WHERE id=$5`
+	return UpdateStructuredValueCtx(ctx, dbConnPgx, structuredValue)
+}
+
+func UpdateStructuredValueCtx(ctx context.Context, dbConnPgx utils.PgxIface, structuredValue *StructuredValue) error {
+	return StructuredValueRepository.Update(ctx, dbConnPgx, structuredValue)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		structuredValue.Name,                  //1
-		structuredValue.AlternateName,         //2
-		structuredValue.StructuredValueTypeID, //3
-		structuredValue.UpdatedBy,             //4
-		structuredValue.ID,                    //5
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+// UpdateStructuredValueIfUnchanged updates structuredValue only if the row
+// still has the UpdatedAt structuredValue was read with. Otherwise it returns a
+// *dberrors.ConflictError[StructuredValue] holding the current row.
+func UpdateStructuredValueIfUnchanged(dbConnPgx utils.PgxIface, structuredValue *StructuredValue) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	return UpdateStructuredValueIfUnchangedCtx(ctx, dbConnPgx, structuredValue)
+}
 
+func UpdateStructuredValueIfUnchangedCtx(ctx context.Context, dbConnPgx utils.PgxIface, structuredValue *StructuredValue) error {
+	return StructuredValueRepository.UpdateIfUnchanged(ctx, dbConnPgx, structuredValue)
 }
 
 func InsertStructuredValue(dbConnPgx utils.PgxIface, structuredValue *StructuredValue) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertStructuredValue DbConn.Begin   %s", err.Error())
-		return -1, err
-	}
-	var insertID int
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO structured_values  
-	(
-		name, 
-		uuid,
-		alternate_name, 
-		structured_value_type_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at 
-		) VALUES ($1,uuid_generate_v4(), $2, $3,$4, current_timestamp at time zone 'UTC', $5, current_timestamp at time zone 'UTC')
-		RETURNING id`,
-		structuredValue.Name,                  //1
-		structuredValue.AlternateName,         //2
-		structuredValue.StructuredValueTypeID, //3
-		structuredValue.CreatedBy,             //4
-		structuredValue.CreatedBy,             //5
-	).Scan(&insertID)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, err
-	}
-	return int(insertID), nil
+	return InsertStructuredValueCtx(ctx, dbConnPgx, structuredValue)
+}
+
+func InsertStructuredValueCtx(ctx context.Context, dbConnPgx utils.PgxIface, structuredValue *StructuredValue) (int, error) {
+	return StructuredValueRepository.Insert(ctx, dbConnPgx, structuredValue)
 }
 func InsertStructuredValues(dbConnPgx utils.PgxIface, structuredValues []StructuredValue) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range structuredValues {
-		structuredValue := structuredValues[i]
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(structuredValue.UUID)
-		row := []interface{}{
-			uuidString,                            //1
-			structuredValue.Name,                  //2
-			structuredValue.AlternateName,         //3
-			structuredValue.StructuredValueTypeID, //4
-			structuredValue.CreatedBy,             //5
-			&now,                                  //6
-			structuredValue.CreatedBy,             //7
-			&now,                                  //8
-		}
-		rows = append(rows, row)
-	}
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"structured_values"},
-		[]string{
-			"uuid",                     //1
-			"name",                     //2
-			"alternate_name",           //3
-			"structured_value_type_id", //4
-			"created_by",               //5
-			"created_at",               //6
-			"updated_by",               //7
-			"updated_at",               //8
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertStructuredValues: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
+	return InsertStructuredValuesCtx(ctx, dbConnPgx, structuredValues)
+}
 
-	return nil
+func InsertStructuredValuesCtx(ctx context.Context, dbConnPgx utils.PgxIface, structuredValues []StructuredValue) error {
+	_, err := StructuredValueRepository.InsertMany(ctx, dbConnPgx, structuredValues)
+	return dberrors.Wrap(err)
 }
 
-func GetStructuredValueListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]StructuredValue, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetStructuredValueListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]StructuredValue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `SELECT 
-		id,
-		uuid, 
-		name, 
-		alternate_name, 
-		structured_value_type_id,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at 
-	FROM structured_values
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " AND "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetStructuredValueListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
+}
 
-	structuredValues, err := pgx.CollectRows(results, pgx.RowToStructByName[StructuredValue])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return structuredValues, nil
+func GetStructuredValueListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]StructuredValue, error) {
+	return StructuredValueRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
 }
 
 func GetTotalStructuredValueCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalStructuredValueCountCtx(ctx, dbConnPgx)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT 
-	COUNT(*)
-	FROM structured_values
-	`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalStructuredValueCountCtx(ctx context.Context, dbConnPgx utils.PgxIface) (*int, error) {
+	return StructuredValueRepository.Count(ctx, dbConnPgx)
 }
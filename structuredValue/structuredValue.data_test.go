@@ -7,31 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                       //1
-	"uuid",                     //2
-	"name",                     //3
-	"alternate_name",           //4
-	"structured_value_type_id", //5
-	"created_by",               //6
-	"created_at",               //7
-	"updated_by",               //8
-	"updated_at",               //9
-}
-var DBColumnsInsertStructuredValues = []string{
-	"uuid",                     //1
-	"name",                     //2
-	"alternate_name",           //3
-	"structured_value_type_id", //4
-	"created_by",               //5
-	"created_at",               //6
-	"updated_by",               //7
-	"updated_at",               //8
-}
+var DBColumns = StructuredValueRepository.Columns()
+var DBColumnsInsertStructuredValues = StructuredValueRepository.InsertColumns()
 
 var TestData1 = StructuredValue{
 	ID:                    utils.Ptr[int](1),                      //1
@@ -60,21 +43,7 @@ var TestData2 = StructuredValue{
 var TestAllData = []StructuredValue{TestData1, TestData2}
 
 func AddStructuredValueToMockRows(mock pgxmock.PgxPoolIface, dataList []StructuredValue) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                    //1
-			data.UUID,                  //2
-			data.Name,                  //3
-			data.AlternateName,         //4
-			data.StructuredValueTypeID, //5
-			data.CreatedBy,             //6
-			data.CreatedAt,             //7
-			data.UpdatedBy,             //8
-			data.UpdatedAt,             //9
-		)
-	}
-	return rows
+	return StructuredValueRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetStructuredValue(t *testing.T) {
@@ -110,8 +79,8 @@ func TestGetStructuredValueForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WithArgs(structuredValueID).WillReturnRows(noRows)
 	foundStructuredValue, err := GetStructuredValue(mock, &structuredValueID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStructuredValue", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStructuredValue != nil {
 		t.Errorf("Expected StructuredValue From Method GetStructuredValue: to be empty but got this: %v", foundStructuredValue)
@@ -294,8 +263,8 @@ func TestGetStructuredValueList(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddStructuredValueToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WithArgs(ids).WillReturnRows(mockRows)
 	foundStructuredValueList, err := GetStructuredValueList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStructuredValueList", err)
@@ -316,8 +285,8 @@ func TestGetStructuredValueListForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStructuredValueList, err := GetStructuredValueList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueList", err)
@@ -337,8 +306,8 @@ func TestGetStructuredValueListForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundStructuredValue, err := GetStructuredValueList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueList", err)
@@ -449,7 +418,6 @@ func TestInsertStructuredValue(t *testing.T) {
 		targetData.AlternateName,         //2
 		targetData.StructuredValueTypeID, //3
 		targetData.CreatedBy,             //4
-		targetData.CreatedBy,             //5
 	).WillReturnRows(pgxmock.NewRows([]string{"structured_value_id"}).AddRow(1))
 	mock.ExpectCommit()
 	structuredValueID, err := InsertStructuredValue(mock, &targetData)
@@ -496,7 +464,6 @@ func TestInsertStructuredValueOnFailure(t *testing.T) {
 		targetData.AlternateName,         //2
 		targetData.StructuredValueTypeID, //3
 		targetData.CreatedBy,             //4
-		targetData.CreatedBy,             //5
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	structuredValueID, err := InsertStructuredValue(mock, &targetData)
@@ -524,7 +491,6 @@ func TestInsertStructuredValueOnFailureOnCommit(t *testing.T) {
 		targetData.AlternateName,         //2
 		targetData.StructuredValueTypeID, //3
 		targetData.CreatedBy,             //4
-		targetData.CreatedBy,             //5
 	).WillReturnRows(pgxmock.NewRows([]string{"structured_value_id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -586,8 +552,8 @@ func TestGetStructuredValueListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"alternate_name = 'test", "structured_value_type_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("alternate_name", "test"), filter.Equal("structured_value_type_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WithArgs("test", int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundStructuredValueList, err := GetStructuredValueListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStructuredValueListByPagination", err)
@@ -612,8 +578,8 @@ func TestGetStructuredValueListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"structured_value_type_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("structured_value_type_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStructuredValueList, err := GetStructuredValueListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueListByPagination", err)
@@ -636,9 +602,9 @@ func TestGetStructuredValueListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"structured_value_type_id = 1"}
+	filters := filter.All(filter.Equal("structured_value_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundStructuredValueList, err := GetStructuredValueListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueListByPagination", err)
@@ -0,0 +1,336 @@
+package structuredvalue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	structuredvaluetype "github.com/kfukue/lyle-labs-libraries/v2/structuredValueType"
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+)
+
+// Key identifies a structured value by the name of its type and its own name.
+// Names are matched case-insensitively, against the alternate names too.
+type Key struct {
+	Type string
+	Name string
+}
+
+func (k Key) String() string {
+	return k.Type + "/" + k.Name
+}
+
+func (k Key) normalize() Key {
+	return Key{Type: normalizeName(k.Type), Name: normalizeName(k.Name)}
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Types of the structured values the library relies on.
+const (
+	TypeJobStatus   = "Job Status"
+	TypeJobCategory = "Job Category"
+	TypeAddressType = "Address Type"
+	TypeAuditType   = "Audit Type"
+	TypeRateType    = "Rate Type"
+	TypeTaxType     = "Tax Type"
+	TypeTransfer    = "Transfer Type"
+)
+
+// Structured values the library relies on.
+var (
+	JobStatusSuccess     = Key{TypeJobStatus, "Success"}
+	JobStatusRunning     = Key{TypeJobStatus, "Running"}
+	JobStatusWarning     = Key{TypeJobStatus, "Warning"}
+	JobStatusFailed      = Key{TypeJobStatus, "Failed"}
+	JobCategoryLive      = Key{TypeJobCategory, "Live"}
+	JobCategoryEOD       = Key{TypeJobCategory, "EOD"}
+	AddressTypeEOA       = Key{TypeAddressType, "EOA"}
+	AddressTypeContract  = Key{TypeAddressType, "Contract"}
+	AuditTypeCreate      = Key{TypeAuditType, "Create"}
+	AuditTypeUpdate      = Key{TypeAuditType, "Update"}
+	AuditTypeDelete      = Key{TypeAuditType, "Delete"}
+	RateTypeFixed        = Key{TypeRateType, "Fixed"}
+	RateTypePercentage   = Key{TypeRateType, "Percentage"}
+	TaxTypeSmartContract = Key{TypeTaxType, "Smart Contract Tax"}
+	TransferTypeERC20    = Key{TypeTransfer, "ERC20"}
+	TransferTypeInternal = Key{TypeTransfer, "Internal"}
+	TransferTypeNative   = Key{TypeTransfer, "Native"}
+)
+
+// Definition is a structured value the library relies on and the ID it has in
+// the reference database, which the utils constants hold.
+type Definition struct {
+	Key
+	ID int
+}
+
+// Definitions are validated by Boot and created by Seed.
+var Definitions = []Definition{
+	{JobStatusSuccess, utils.SUCCESS_STRUCTURED_VALUE_ID},
+	{JobStatusRunning, utils.RUNNING_STRUCTURED_VALUE_ID},
+	{JobStatusWarning, utils.WARNING_STRUCTURED_VALUE_ID},
+	{JobStatusFailed, utils.FAILED_STRUCTURED_VALUE_ID},
+	{JobCategoryLive, utils.LIVE_JOB_CATEGORY_STRUCTURED_VALUE_ID},
+	{JobCategoryEOD, utils.EOD_JOB_CATEGORY_STRUCTURED_VALUE_ID},
+	{AddressTypeEOA, utils.EOA_ADDRESS_TYPE_STRUCTURED_VALUE_ID},
+	{AddressTypeContract, utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID},
+	{AuditTypeCreate, utils.CREATE_AUDIT_TYPE_STRUCTURED_VALUE_ID},
+	{AuditTypeUpdate, utils.UPDATE_AUDIT_TYPE_STRUCTURED_VALUE_ID},
+	{AuditTypeDelete, 0},
+	{RateTypeFixed, utils.FIXED_STRUCTURED_VALUE_ID},
+	{RateTypePercentage, utils.PERCENTAGE_STRUCTURED_VALUE_ID},
+	{TaxTypeSmartContract, utils.SMART_CONTRACT_TAX_STRUCTURED_VALUE_ID},
+	{TransferTypeERC20, utils.TRANSFER_TYPE_ERC20_STRUCTURED_VALUE_ID},
+	{TransferTypeInternal, utils.TRANSFER_TYPE_INTERNAL_STRUCTURED_VALUE_ID},
+	{TransferTypeNative, utils.TRANSFER_TYPE_NATIVE_STRUCTURED_VALUE_ID},
+}
+
+// Mismatch is a structured value found under another ID than its Definition.
+type Mismatch struct {
+	Definition
+	ActualID int
+}
+
+// ValidationError lists the Definitions the database does not satisfy.
+type ValidationError struct {
+	Missing    []Key
+	Mismatched []Mismatch
+}
+
+func (e *ValidationError) Error() string {
+	var parts []string
+	for _, k := range e.Missing {
+		parts = append(parts, "missing "+k.String())
+	}
+	for _, m := range e.Mismatched {
+		parts = append(parts, fmt.Sprintf("%s has id %d, expected %d", m.Key, m.ActualID, m.ID))
+	}
+	return "structured values: " + strings.Join(parts, "; ")
+}
+
+// Registry caches structured values by Key and by ID.
+type Registry struct {
+	mu     sync.RWMutex
+	loaded bool
+	types  map[string]int
+	byKey  map[Key]StructuredValue
+	byID   map[int]StructuredValue
+}
+
+// NewRegistry returns an empty Registry. Load fills it.
+func NewRegistry() *Registry {
+	return &Registry{types: map[string]int{}, byKey: map[Key]StructuredValue{}, byID: map[int]StructuredValue{}}
+}
+
+// DefaultRegistry is the Registry used by ID and Boot.
+var DefaultRegistry = NewRegistry()
+
+// Load replaces the cache with every structured value type and value.
+func (r *Registry) Load(ctx context.Context, dbConnPgx utils.PgxIface) error {
+	types, err := structuredvaluetype.GetStructuredValueTypeListCtx(ctx, dbConnPgx, nil)
+	if err != nil {
+		return err
+	}
+	values, err := GetStructuredValueListCtx(ctx, dbConnPgx, nil)
+	if err != nil {
+		return err
+	}
+	typeNames := map[int][]string{}
+	typeIDs := map[string]int{}
+	for _, t := range types {
+		if t.ID == nil {
+			continue
+		}
+		typeNames[*t.ID] = names(t.Name, t.AlternateName)
+		for _, n := range typeNames[*t.ID] {
+			if _, ok := typeIDs[n]; !ok || n == normalizeName(t.Name) {
+				typeIDs[n] = *t.ID
+			}
+		}
+	}
+	byKey := map[Key]StructuredValue{}
+	byID := map[int]StructuredValue{}
+	// Alternate names go in first so that a name always wins over another
+	// value's alternate name.
+	for _, alternates := range []bool{true, false} {
+		for _, v := range values {
+			if v.ID == nil || v.StructuredValueTypeID == nil {
+				continue
+			}
+			byID[*v.ID] = v
+			name := v.Name
+			if alternates {
+				name = v.AlternateName
+			}
+			for _, typeName := range typeNames[*v.StructuredValueTypeID] {
+				if k := (Key{Type: typeName, Name: normalizeName(name)}); k.Name != "" {
+					byKey[k] = v
+				}
+			}
+		}
+	}
+	r.mu.Lock()
+	r.loaded, r.types, r.byKey, r.byID = true, typeIDs, byKey, byID
+	r.mu.Unlock()
+	return nil
+}
+
+func names(name, alternateName string) []string {
+	result := []string{normalizeName(name)}
+	if alt := normalizeName(alternateName); alt != "" && alt != result[0] {
+		result = append(result, alt)
+	}
+	return result
+}
+
+// Loaded reports whether Load has succeeded.
+func (r *Registry) Loaded() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaded
+}
+
+// Lookup returns the cached structured value for key.
+func (r *Registry) Lookup(key Key) (*StructuredValue, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.byKey[key.normalize()]
+	if !ok {
+		return nil, false
+	}
+	return &v, true
+}
+
+// ByID returns the cached structured value with id.
+func (r *Registry) ByID(id int) (*StructuredValue, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.byID[id]
+	if !ok {
+		return nil, false
+	}
+	return &v, true
+}
+
+// Resolve returns the structured value for key from the cache, or else from
+// the database, caching it. It returns dberrors.ErrNotFound when there is none.
+func (r *Registry) Resolve(ctx context.Context, dbConnPgx utils.PgxIface, key Key) (*StructuredValue, error) {
+	if v, ok := r.Lookup(key); ok {
+		return v, nil
+	}
+	v, err := GetStructuredValueByTypeAndNameCtx(ctx, dbConnPgx, key.Type, key.Name)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.byKey[key.normalize()] = *v
+	r.byID[*v.ID] = *v
+	r.mu.Unlock()
+	return v, nil
+}
+
+// ID returns the ID of key, or fallback when the registry has not been loaded
+// or has no such value.
+func (r *Registry) ID(key Key, fallback int) int {
+	if v, ok := r.Lookup(key); ok {
+		return *v.ID
+	}
+	return fallback
+}
+
+// ID returns the ID of key in DefaultRegistry, or fallback.
+func ID(key Key, fallback int) int {
+	return DefaultRegistry.ID(key, fallback)
+}
+
+// Validate checks that every definition is in the cache under its ID. A zero
+// Definition.ID only has to exist.
+func (r *Registry) Validate(definitions []Definition) error {
+	var verr ValidationError
+	for _, d := range definitions {
+		v, ok := r.Lookup(d.Key)
+		switch {
+		case !ok:
+			verr.Missing = append(verr.Missing, d.Key)
+		case d.ID != 0 && *v.ID != d.ID:
+			verr.Mismatched = append(verr.Mismatched, Mismatch{Definition: d, ActualID: *v.ID})
+		}
+	}
+	if len(verr.Missing) > 0 || len(verr.Mismatched) > 0 {
+		return &verr
+	}
+	return nil
+}
+
+// Seed inserts the types and values of definitions that are missing from the
+// cache, then reloads it. Seeded values get new IDs, which Validate reports if
+// they differ from the definitions. It returns the number of values inserted.
+func (r *Registry) Seed(ctx context.Context, dbConnPgx utils.PgxIface, definitions []Definition) (int, error) {
+	inserted := 0
+	for _, d := range definitions {
+		if _, ok := r.Lookup(d.Key); ok {
+			continue
+		}
+		r.mu.RLock()
+		typeID, ok := r.types[normalizeName(d.Type)]
+		r.mu.RUnlock()
+		if !ok {
+			id, err := structuredvaluetype.InsertStructuredValueTypeCtx(ctx, dbConnPgx, &structuredvaluetype.StructuredValueType{
+				Name:          d.Type,
+				AlternateName: d.Type,
+				CreatedBy:     utils.SYSTEM_NAME,
+			})
+			if err != nil {
+				return inserted, err
+			}
+			typeID = id
+			r.mu.Lock()
+			r.types[normalizeName(d.Type)] = typeID
+			r.mu.Unlock()
+		}
+		id, err := InsertStructuredValueCtx(ctx, dbConnPgx, &StructuredValue{
+			Name:                  d.Name,
+			AlternateName:         d.Name,
+			StructuredValueTypeID: &typeID,
+			CreatedBy:             utils.SYSTEM_NAME,
+		})
+		if err != nil {
+			return inserted, err
+		}
+		inserted++
+		logging.Info(ctx, "seeded structured value", slog.String("key", d.Key.String()), logging.ID(id))
+	}
+	if inserted == 0 {
+		return 0, nil
+	}
+	return inserted, r.Load(ctx, dbConnPgx)
+}
+
+// Boot loads DefaultRegistry, seeds the missing Definitions when seed is set,
+// and validates them. Mismatched IDs are logged rather than returned: the
+// library resolves its values through the registry, so they only matter to
+// callers still using the utils constants.
+func Boot(ctx context.Context, dbConnPgx utils.PgxIface, seed bool) error {
+	if err := DefaultRegistry.Load(ctx, dbConnPgx); err != nil {
+		return err
+	}
+	if seed {
+		if _, err := DefaultRegistry.Seed(ctx, dbConnPgx, Definitions); err != nil {
+			return err
+		}
+	}
+	err := DefaultRegistry.Validate(Definitions)
+	var verr *ValidationError
+	if errors.As(err, &verr) && len(verr.Missing) == 0 {
+		logging.Logger().WarnContext(ctx, "structured value ids differ from the utils constants", logging.Err(err))
+		return nil
+	}
+	return err
+}
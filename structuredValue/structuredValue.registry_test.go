@@ -0,0 +1,154 @@
+package structuredvalue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/utils"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var typeColumns = []string{"id", "uuid", "name", "alternate_name", "created_by", "created_at", "updated_by", "updated_at"}
+
+func expectRegistryLoad(mock pgxmock.PgxPoolIface, withValues bool) {
+	types := mock.NewRows(typeColumns).
+		AddRow(utils.Ptr(14), "uuid-14", "Job Status", "Job Status", "SYSTEM", utils.SampleCreatedAtTime, "SYSTEM", utils.SampleCreatedAtTime).
+		AddRow(utils.Ptr(22), "uuid-22", "Address Type", "Address", "SYSTEM", utils.SampleCreatedAtTime, "SYSTEM", utils.SampleCreatedAtTime)
+	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types$").WillReturnRows(types)
+	values := mock.NewRows(DBColumns)
+	if withValues {
+		values.
+			AddRow(utils.Ptr(52), "uuid-52", "Success", "Succeeded", utils.Ptr(14), "SYSTEM", utils.SampleCreatedAtTime, "SYSTEM", utils.SampleCreatedAtTime).
+			AddRow(utils.Ptr(84), "uuid-84", "EOA", "Externally Owned Account", utils.Ptr(22), "SYSTEM", utils.SampleCreatedAtTime, "SYSTEM", utils.SampleCreatedAtTime).
+			AddRow(utils.Ptr(185), "uuid-185", "Contract", "Contract", utils.Ptr(22), "SYSTEM", utils.SampleCreatedAtTime, "SYSTEM", utils.SampleCreatedAtTime)
+	}
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values$").WillReturnRows(values)
+}
+
+func TestRegistryLoad(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	expectRegistryLoad(mock, true)
+	r := NewRegistry()
+	if got := r.ID(JobStatusSuccess, 52); got != 52 {
+		t.Errorf("expected the fallback before Load, got %d", got)
+	}
+	if err := r.Load(context.Background(), mock); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.ID(Key{"job status", " SUCCESS "}, 0); got != 52 {
+		t.Errorf("expected a case-insensitive match, got %d", got)
+	}
+	if got := r.ID(Key{"Address", "Externally Owned Account"}, 0); got != 84 {
+		t.Errorf("expected a match on the alternate names, got %d", got)
+	}
+	if v, ok := r.ByID(185); !ok || v.Name != "Contract" {
+		t.Errorf("expected value 185 to be cached, got %v", v)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRegistryResolve(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	ctx := context.Background()
+	r := NewRegistry()
+	mock.ExpectQuery("^SELECT (.+) FROM structured_values WHERE structured_value_type_id IN \\( SELECT id FROM structured_value_types").
+		WithArgs(TypeTransfer, "ERC20").
+		WillReturnRows(mock.NewRows(DBColumns).AddRow(utils.Ptr(99), "uuid-99", "ERC20", "ERC20", utils.Ptr(26), "SYSTEM", utils.SampleCreatedAtTime, "SYSTEM", utils.SampleCreatedAtTime))
+	for i := 0; i < 2; i++ {
+		v, err := r.Resolve(ctx, mock, TransferTypeERC20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *v.ID != 99 {
+			t.Errorf("expected id 99, got %d", *v.ID)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	expectRegistryLoad(mock, true)
+	r := NewRegistry()
+	if err := r.Load(context.Background(), mock); err != nil {
+		t.Fatal(err)
+	}
+	err = r.Validate([]Definition{
+		{JobStatusSuccess, utils.SUCCESS_STRUCTURED_VALUE_ID},
+		{AddressTypeEOA, 0},
+		{AddressTypeContract, utils.CONTRACT_ADDRESS_TYPE_STRUCTURED_VALUE_ID},
+		{JobStatusFailed, utils.FAILED_STRUCTURED_VALUE_ID},
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if len(verr.Missing) != 1 || verr.Missing[0] != JobStatusFailed {
+		t.Errorf("expected %v to be missing, got %v", JobStatusFailed, verr.Missing)
+	}
+	if len(verr.Mismatched) != 1 || verr.Mismatched[0].Key != AddressTypeContract || verr.Mismatched[0].ActualID != 185 {
+		t.Errorf("expected %v to be mismatched, got %v", AddressTypeContract, verr.Mismatched)
+	}
+}
+
+func TestRegistrySeed(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	ctx := context.Background()
+	expectRegistryLoad(mock, false)
+	r := NewRegistry()
+	if err := r.Load(ctx, mock); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO structured_values").
+		WithArgs("Success", "Success", utils.Ptr(14), utils.SYSTEM_NAME).
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(52))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO structured_value_types").
+		WithArgs("Frequency", "Frequency", utils.SYSTEM_NAME).
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(17))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("^INSERT INTO structured_values").
+		WithArgs("Daily", "Daily", utils.Ptr(17), utils.SYSTEM_NAME).
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectCommit()
+	expectRegistryLoad(mock, true)
+
+	inserted, err := r.Seed(ctx, mock, []Definition{
+		{JobStatusSuccess, utils.SUCCESS_STRUCTURED_VALUE_ID},
+		{Key{"Frequency", "Daily"}, utils.DAILY_STRUCTURED_VALUE_ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted != 2 {
+		t.Errorf("expected 2 values inserted, got %d", inserted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -7,29 +7,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",             //1
-	"uuid",           //2
-	"name",           //3
-	"alternate_name", //4
-	"created_by",     //5
-	"created_at",     //6
-	"updated_by",     //7
-	"updated_at",     //8
-}
-var DBColumnsInsertStructuredValueTypes = []string{
-	"uuid",           //1
-	"name",           //2
-	"alternate_name", //3
-	"created_by",     //4
-	"created_at",     //5
-	"updated_by",     //6
-	"updated_at",     //7
-}
+var DBColumns = StructuredValueTypeRepository.Columns()
+var DBColumnsInsertStructuredValueTypes = StructuredValueTypeRepository.InsertColumns()
 
 var TestData1 = StructuredValueType{
 	ID:            utils.Ptr[int](1),                      //1
@@ -56,20 +41,7 @@ var TestData2 = StructuredValueType{
 var TestAllData = []StructuredValueType{TestData1, TestData2}
 
 func AddStructuredValueTypeToMockRows(mock pgxmock.PgxPoolIface, dataList []StructuredValueType) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.UUID,          //2
-			data.Name,          //3
-			data.AlternateName, //4
-			data.CreatedBy,     //5
-			data.CreatedAt,     //6
-			data.UpdatedBy,     //7
-			data.UpdatedAt,     //8
-		)
-	}
-	return rows
+	return StructuredValueTypeRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetStructuredValueType(t *testing.T) {
@@ -105,8 +77,8 @@ func TestGetStructuredValueTypeForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WithArgs(structuredValueTypeID).WillReturnRows(noRows)
 	foundStructuredValueType, err := GetStructuredValueType(mock, &structuredValueTypeID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetStructuredValueType", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundStructuredValueType != nil {
 		t.Errorf("Expected StructuredValueType From Method GetStructuredValueType: to be empty but got this: %v", foundStructuredValueType)
@@ -223,8 +195,8 @@ func TestGetStructuredValueTypeList(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddStructuredValueTypeToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WithArgs(ids).WillReturnRows(mockRows)
 	foundStructuredValueTypeList, err := GetStructuredValueTypeList(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStructuredValueTypeList", err)
@@ -245,8 +217,8 @@ func TestGetStructuredValueTypeListForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStructuredValueTypeList, err := GetStructuredValueTypeList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueTypeList", err)
@@ -266,8 +238,8 @@ func TestGetStructuredValueTypeListForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundStructuredValueType, err := GetStructuredValueTypeList(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueTypeList", err)
@@ -375,7 +347,6 @@ func TestInsertStructuredValueType(t *testing.T) {
 		targetData.Name,          //1
 		targetData.AlternateName, //2
 		targetData.CreatedBy,     //3
-		targetData.CreatedBy,     //4
 	).WillReturnRows(pgxmock.NewRows([]string{"structured_value_type_id"}).AddRow(1))
 	mock.ExpectCommit()
 	structuredValueTypeID, err := InsertStructuredValueType(mock, &targetData)
@@ -421,7 +392,6 @@ func TestInsertStructuredValueTypeOnFailure(t *testing.T) {
 		targetData.Name,          //1
 		targetData.AlternateName, //2
 		targetData.CreatedBy,     //3
-		targetData.CreatedBy,     //4
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	structuredValueTypeID, err := InsertStructuredValueType(mock, &targetData)
@@ -448,7 +418,6 @@ func TestInsertStructuredValueTypeOnFailureOnCommit(t *testing.T) {
 		targetData.Name,          //1
 		targetData.AlternateName, //2
 		targetData.CreatedBy,     //3
-		targetData.CreatedBy,     //4
 	).WillReturnRows(pgxmock.NewRows([]string{"structured_value_type_id"}).AddRow(1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -510,8 +479,8 @@ func TestGetStructuredValueTypeListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"alternate_name = 'test", "structured_value_type_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("alternate_name", "test"), filter.Equal("id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WithArgs("test", int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundStructuredValueTypeList, err := GetStructuredValueTypeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetStructuredValueTypeListByPagination", err)
@@ -536,8 +505,8 @@ func TestGetStructuredValueTypeListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"structured_value_type_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundStructuredValueTypeList, err := GetStructuredValueTypeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueTypeListByPagination", err)
@@ -560,9 +529,9 @@ func TestGetStructuredValueTypeListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"structured_value_type_id = 1"}
+	filters := filter.All(filter.Equal("id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM structured_value_types").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundStructuredValueTypeList, err := GetStructuredValueTypeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetStructuredValueTypeListByPagination", err)
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
@@ -34,25 +36,7 @@ var DBColumns = []string{
 	"updated_by",           //17
 	"updated_at",           //18
 }
-var DBColumnsInsertTaxes = []string{
-	"uuid",                 //1
-	"name",                 //2
-	"alternate_name",       //3
-	"start_date",           //4
-	"end_date",             //5
-	"start_block",          //6
-	"end_block",            //7
-	"tax_rate",             //8
-	"tax_rate_type_id",     //9
-	"contract_address_str", //10
-	"contract_address_id",  //11
-	"tax_type_id",          //12
-	"description",          //13
-	"created_by",           //14
-	"created_at",           //15
-	"updated_by",           //16
-	"updated_at",           //17
-}
+var DBColumnsInsertTaxes = TaxRepository.InsertColumns()
 
 var TestData1 = Tax{
 	ID:                 utils.Ptr[int](1),                                     //1
@@ -99,30 +83,7 @@ var TestData2 = Tax{
 var TestAllData = []Tax{TestData1, TestData2}
 
 func AddTaxToMockRows(mock pgxmock.PgxPoolIface, dataList []Tax) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                 //1
-			data.UUID,               //2
-			data.Name,               //3
-			data.AlternateName,      //4
-			data.StartDate,          //5
-			data.EndDate,            //6
-			data.StartBlock,         //7
-			data.EndBlock,           //8
-			data.TaxRate,            //9
-			data.TaxRateTypeID,      //10
-			data.ContractAddressStr, //11
-			data.ContractAddressID,  //12
-			data.TaxTypeID,          //13
-			data.Description,        //14
-			data.CreatedBy,          //15
-			data.CreatedAt,          //16
-			data.UpdatedBy,          //17
-			data.UpdatedAt,          //18
-		)
-	}
-	return rows
+	return TaxRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetTax(t *testing.T) {
@@ -158,8 +119,8 @@ func TestGetTaxForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM taxes").WithArgs(taxID).WillReturnRows(noRows)
 	foundTax, err := GetTax(mock, &taxID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTax", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTax != nil {
 		t.Errorf("Expected Tax From Method GetTax: to be empty but got this: %v", foundTax)
@@ -276,8 +237,8 @@ func TestGetTaxes(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddTaxToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM taxes").WillReturnRows(mockRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM taxes").WithArgs(ids).WillReturnRows(mockRows)
 	foundTaxList, err := GetTaxes(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTaxes", err)
@@ -298,8 +259,8 @@ func TestGetTaxesForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM taxes").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM taxes").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundTaxList, err := GetTaxes(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTaxes", err)
@@ -319,8 +280,8 @@ func TestGetTaxesForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM taxes").WillReturnRows(differentModelRows)
 	ids := []int{1}
+	mock.ExpectQuery("^SELECT (.+) FROM taxes").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundTax, err := GetTaxes(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTaxes", err)
@@ -568,6 +529,88 @@ func TestUpdateTaxOnFailure(t *testing.T) {
 	}
 }
 
+func TestUpdateTaxIfUnchanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	newUpdatedAt := targetData.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE taxes").WithArgs(
+		targetData.Name,               //1
+		targetData.AlternateName,      //2
+		targetData.StartDate,          //3
+		targetData.EndDate,            //4
+		targetData.StartBlock,         //5
+		targetData.EndBlock,           //6
+		targetData.TaxRate,            //7
+		targetData.TaxRateTypeID,      //8
+		targetData.ContractAddressStr, //9
+		targetData.ContractAddressID,  //10
+		targetData.TaxTypeID,          //11
+		targetData.Description,        //12
+		targetData.UpdatedBy,          //13
+		targetData.ID,                 //14
+		targetData.UpdatedAt,          //15
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}).AddRow(newUpdatedAt))
+	mock.ExpectCommit()
+	err = UpdateTaxIfUnchanged(mock, &targetData)
+	if err != nil {
+		t.Fatalf("an error '%s' in UpdateTaxIfUnchanged", err)
+	}
+	if !targetData.UpdatedAt.Equal(newUpdatedAt) {
+		t.Errorf("expected UpdatedAt to be %v, got %v", newUpdatedAt, targetData.UpdatedAt)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateTaxIfUnchangedOnConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	targetData := TestData1
+	current := TestData1
+	current.Description = "edited elsewhere"
+	current.UpdatedAt = TestData1.UpdatedAt.Add(time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery("^UPDATE taxes").WithArgs(
+		targetData.Name,               //1
+		targetData.AlternateName,      //2
+		targetData.StartDate,          //3
+		targetData.EndDate,            //4
+		targetData.StartBlock,         //5
+		targetData.EndBlock,           //6
+		targetData.TaxRate,            //7
+		targetData.TaxRateTypeID,      //8
+		targetData.ContractAddressStr, //9
+		targetData.ContractAddressID,  //10
+		targetData.TaxTypeID,          //11
+		targetData.Description,        //12
+		targetData.UpdatedBy,          //13
+		targetData.ID,                 //14
+		targetData.UpdatedAt,          //15
+	).WillReturnRows(mock.NewRows([]string{"updated_at"}))
+	mock.ExpectQuery("^SELECT (.+) FROM taxes(.+)FOR SHARE$").WithArgs(targetData.ID).WillReturnRows(AddTaxToMockRows(mock, []Tax{current}))
+	mock.ExpectRollback()
+	err = UpdateTaxIfUnchanged(mock, &targetData)
+	var conflict *dberrors.ConflictError[Tax]
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a *dberrors.ConflictError[Tax], got '%v'", err)
+	}
+	if cmp.Equal(*conflict.Current, current) == false {
+		t.Errorf("Expected the current Tax %v, got %v", current, conflict.Current)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
 func TestInsertTax(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -742,8 +785,8 @@ func TestGetTaxListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"tax_rate_type_id = 1", "tax_type_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM taxes").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("tax_rate_type_id", "1"), filter.Equal("tax_type_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM taxes").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundTaxList, err := GetTaxListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTaxListByPagination", err)
@@ -768,8 +811,8 @@ func TestGetTaxListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"tax_type_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM taxes").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("tax_type_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM taxes").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundTaxList, err := GetTaxListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTaxListByPagination", err)
@@ -792,9 +835,9 @@ func TestGetTaxListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"tax_type_id = 1"}
+	filters := filter.All(filter.Equal("tax_type_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM taxes").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM taxes").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundTaxList, err := GetTaxListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTaxListByPagination", err)
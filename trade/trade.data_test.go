@@ -8,79 +8,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",                       //1
-	"parent_trade_id",          //2
-	"from_account_id",          //3
-	"to_account_id",            //4
-	"asset_id",                 //5
-	"source_id",                //6
-	"uuid",                     //7
-	"transaction_id",           //8
-	"order_id",                 //9
-	"trade_id",                 //10
-	"name",                     //11
-	"alternate_name",           //12
-	"trade_type_id",            //13
-	"trade_date",               //14
-	"settle_date",              //15
-	"transfer_date",            //16
-	"from_quantity",            //17
-	"to_quantity",              //18
-	"price",                    //19
-	"total_amount",             //20
-	"fees_amount",              //21
-	"fees_asset_id",            //22
-	"realized_return_amount",   //23
-	"realized_return_asset_id", //24
-	"cost_basis_amount",        //25
-	"cost_basis_trade_id",      //26
-	"description",              //27
-	"is_active",                //28
-	"source_data",              //29
-	"created_by",               //30
-	"created_at",               //31
-	"updated_by",               //32
-	"updated_at",               //33
-}
-var DBColumnsInsertTrades = []string{
-	"parent_trade_id",          //1
-	"from_account_id",          //2
-	"to_account_id",            //3
-	"asset_id",                 //4
-	"source_id",                //5
-	"uuid",                     //6
-	"transaction_id",           //7
-	"order_id",                 //8
-	"trade_id",                 //9
-	"name",                     //10
-	"alternate_name",           //11
-	"trade_type_id",            //12
-	"trade_date",               //13
-	"settle_date",              //14
-	"transfer_date",            //15
-	"from_quantity",            //16
-	"to_quantity",              //17
-	"price",                    //18
-	"total_amount",             //19
-	"fees_amount",              //20
-	"fees_asset_id",            //21
-	"realized_return_amount",   //22
-	"realized_return_asset_id", //23
-	"cost_basis_amount",        //24
-	"cost_basis_trade_id",      //25
-	"description",              //26
-	"is_active",                //27
-	"source_data",              //28
-	"created_by",               //29
-	"created_at",               //30
-	"updated_by",               //31
-	"updated_at",               //32
-}
+var DBColumns = TradeRepository.Columns()
+var DBColumnsInsertTrades = TradeRepository.InsertColumns()
 
 var TestData1 = Trade{
 	ID:                      utils.Ptr[int](1),                                                //1
@@ -156,45 +91,7 @@ var TestData2 = Trade{
 var TestAllData = []Trade{TestData1, TestData2}
 
 func AddTradeToMockRows(mock pgxmock.PgxPoolIface, dataList []Trade) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,                      //1
-			data.ParentTradeID,           //2
-			data.FromAccountID,           //3
-			data.ToAccountID,             //4
-			data.AssetID,                 //5
-			data.SourceID,                //6
-			data.UUID,                    //7
-			data.TransactionIDFromSource, //8
-			data.OrderIDFromSource,       //9
-			data.TradeIDFromSource,       //10
-			data.Name,                    //11
-			data.AlternateName,           //12
-			data.TradeTypeID,             //13
-			data.TradeDate,               //14
-			data.SettleDate,              //15
-			data.TransferDate,            //16
-			data.FromQuantity,            //17
-			data.ToQuantity,              //18
-			data.Price,                   //19
-			data.TotalAmount,             //20
-			data.FeesAmount,              //21
-			data.FeesAssetID,             //22
-			data.RealizedReturnAmount,    //23
-			data.RealizedReturnAssetID,   //24
-			data.CostBasisAmount,         //25
-			data.CostBasisTradeID,        //26
-			data.Description,             //27
-			data.IsActive,                //28
-			data.SourceData,              //29
-			data.CreatedBy,               //30
-			data.CreatedAt,               //31
-			data.UpdatedBy,               //32
-			data.UpdatedAt,               //33
-		)
-	}
-	return rows
+	return TradeRepository.AddToMockRows(mock, dataList)
 }
 
 var DBColumnsMinMaxTradeDates = []string{
@@ -258,8 +155,8 @@ func TestGetTradeForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM trades").WithArgs(tradeID).WillReturnRows(noRows)
 	foundTrade, err := GetTrade(mock, &tradeID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTrade", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTrade != nil {
 		t.Errorf("Expected Trade From Method GetTrade: to be empty but got this: %v", foundTrade)
@@ -349,8 +246,8 @@ func TestGetTradeByTransactionIDFromSourceForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM trades").WithArgs(transactionIDFromSource, fromAccountID, toAccountID).WillReturnRows(noRows)
 	foundTrade, err := GetTradeByTransactionIDFromSource(mock, transactionIDFromSource, &fromAccountID, &toAccountID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTradeByTransactionIDFromSource", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTrade != nil {
 		t.Errorf("Expected Trade From Method GetTradeByTransactionIDFromSource: to be empty but got this: %v", foundTrade)
@@ -1112,8 +1009,8 @@ func TestGetMinAndMaxTradeDatesForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumnsMinMaxTradeDates)
 	mock.ExpectQuery("^SELECT (.+) FROM trades").WillReturnRows(noRows)
 	foundDates, err := GetMinAndMaxTradeDates(mock)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetMinAndMaxTradeDates", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundDates != nil {
 		t.Errorf("Expected foundDates From Method GetMinAndMaxTradeDates: to be empty but got this: %v", foundDates)
@@ -1154,8 +1051,8 @@ func TestGetTradeListByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"from_account_id = 1", "asset_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM trades").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("from_account_id", "1"), filter.Equal("asset_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM trades").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundTradeList, err := GetTradeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTradeListByPagination", err)
@@ -1180,8 +1077,8 @@ func TestGetTradeListByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"from_account_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM trades").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("from_account_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM trades").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundTradeList, err := GetTradeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTradeListByPagination", err)
@@ -1204,9 +1101,9 @@ func TestGetTradeListByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"from_account_id = 1"}
+	filters := filter.All(filter.Equal("from_account_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM trades").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM trades").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundTaxList, err := GetTradeListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTradeListByPagination", err)
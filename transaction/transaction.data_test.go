@@ -7,46 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"id",              //1
-	"uuid",            //2
-	"name",            //3
-	"alternate_name",  //4
-	"start_date",      //5
-	"end_date",        //6
-	"description",     //7
-	"tx_hash",         //8
-	"status_id",       //9
-	"from_account_id", //10
-	"to_account_id",   //11
-	"chain_id",        //12
-	"created_by",      //13
-	"created_at",      //14
-	"updated_by",      //15
-	"updated_at",      //16
-}
-var DBColumnsInsertTransactions = []string{
-	"uuid",            //1
-	"name",            //2
-	"alternate_name",  //3
-	"start_date",      //4
-	"end_date",        //5
-	"description",     //6
-	"tx_hash",         //7
-	"status_id",       //8
-	"from_account_id", //9
-	"to_account_id",   //10
-	"chain_id",        //11
-	"created_by",      //12
-	"created_at",      //13
-	"updated_by",      //14
-	"updated_at",      //15
-}
+var DBColumns = TransactionRepository.Columns()
+var DBColumnsInsertTransactions = TransactionRepository.InsertColumns()
 
 var TestData1 = Transaction{
 	ID:            utils.Ptr[int](1),                                                                                                                                  //1
@@ -88,28 +57,7 @@ var TestData2 = Transaction{
 var TestAllData = []Transaction{TestData1, TestData2}
 
 func AddTransactionToMockRows(mock pgxmock.PgxPoolIface, dataList []Transaction) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.ID,            //1
-			data.UUID,          //2
-			data.Name,          //3
-			data.AlternateName, //4
-			data.StartDate,     //5
-			data.EndDate,       //6
-			data.Description,   //7
-			data.TxHash,        //8
-			data.StatusID,      //9
-			data.FromAccountID, //10
-			data.ToAccountID,   //11
-			data.ChainID,       //12
-			data.CreatedBy,     //13
-			data.CreatedAt,     //14
-			data.UpdatedBy,     //15
-			data.UpdatedAt,     //16
-		)
-	}
-	return rows
+	return TransactionRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetTransaction(t *testing.T) {
@@ -145,8 +93,8 @@ func TestGetTransactionForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM transactions").WithArgs(transactionID).WillReturnRows(noRows)
 	foundTransaction, err := GetTransaction(mock, &transactionID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTransaction", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTransaction != nil {
 		t.Errorf("Expected Transaction From Method GetTransaction: to be empty but got this: %v", foundTransaction)
@@ -263,8 +211,8 @@ func TestGetTransactions(t *testing.T) {
 	dataList := TestAllData
 
 	mockRows := AddTransactionToMockRows(mock, dataList)
-	mock.ExpectQuery("^SELECT (.+) FROM transactions").WillReturnRows(mockRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM transactions").WithArgs(ids).WillReturnRows(mockRows)
 	foundTransactionList, err := GetTransactions(mock, ids)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTransactions", err)
@@ -287,8 +235,8 @@ func TestGetTransactionsForCollectRowsErr(t *testing.T) {
 	}
 	defer mock.Close()
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM transactions").WillReturnRows(differentModelRows)
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM transactions").WithArgs(ids).WillReturnRows(differentModelRows)
 	foundTransactionList, err := GetTransactions(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactions", err)
@@ -307,8 +255,8 @@ func TestGetTransactionsForErr(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
 	}
 	defer mock.Close()
-	mock.ExpectQuery("^SELECT (.+) FROM transactions").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	ids := []int{1, 2}
+	mock.ExpectQuery("^SELECT (.+) FROM transactions").WithArgs(ids).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundTransactionList, err := GetTransactions(mock, ids)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactions", err)
@@ -726,8 +674,8 @@ func TestGetTransactionsByPagination(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"status_id = 1", "exchange_id=1"}
-	mock.ExpectQuery("^SELECT (.+) FROM transactions").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("status_id", "1"), filter.Equal("from_account_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM transactions").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundTransactionList, err := GetTransactionsByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTransactionsByPagination", err)
@@ -752,8 +700,8 @@ func TestGetTransactionsByPaginationForErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM transactions").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("chain_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM transactions").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundTransactionList, err := GetTransactionsByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionsByPagination", err)
@@ -776,9 +724,9 @@ func TestGetTransactionsByPaginationForCollectRowsErr(t *testing.T) {
 	_end := 10
 	_sort := "id"
 	_order := "ASC"
-	filters := []string{"chain_id = 1"}
+	filters := filter.All(filter.Equal("chain_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM transactions").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM transactions").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundTransactionList, err := GetTransactionsByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionsByPagination", err)
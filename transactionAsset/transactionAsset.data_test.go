@@ -7,43 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"transaction_id",           //1
-	"asset_id",                 //2
-	"uuid",                     //3
-	"name",                     //4
-	"alternate_name",           //5
-	"description",              //6
-	"quantity",                 //7
-	"quantity_usd",             //8
-	"market_data_id",           //9
-	"manual_exchange_rate_usd", //10
-	"created_by",               //11
-	"created_at",               //12
-	"updated_by",               //13
-	"updated_at",               //14
-}
-var DBColumnsInsertTransactionAssets = []string{
-	"transaction_id",           //1
-	"asset_id",                 //2
-	"uuid",                     //3
-	"name",                     //4
-	"alternate_name",           //5
-	"description",              //6
-	"quantity",                 //7
-	"quantity_usd",             //8
-	"market_data_id",           //9
-	"manual_exchange_rate_usd", //10
-	"created_by",               //11
-	"created_at",               //12
-	"updated_by",               //13
-	"updated_at",               //14
-}
+var DBColumns = TransactionAssetRepository.Columns()
+var DBColumnsInsertTransactionAssets = TransactionAssetRepository.InsertColumns()
 
 var TestData1 = TransactionAsset{
 	TransactionID:         utils.Ptr[int](1),                      //1
@@ -82,26 +54,7 @@ var TestData2 = TransactionAsset{
 var TestAllData = []TransactionAsset{TestData1, TestData2}
 
 func AddTransactionAssetToMockRows(mock pgxmock.PgxPoolIface, dataList []TransactionAsset) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.TransactionID,         //1
-			data.AssetID,               //2
-			data.UUID,                  //3
-			data.Name,                  //4
-			data.AlternateName,         //5
-			data.Description,           //6
-			data.Quantity,              //7
-			data.QuantityUSD,           //8
-			data.MarketDataID,          //9
-			data.ManualExchangeRateUSD, //10
-			data.CreatedBy,             //11
-			data.CreatedAt,             //12
-			data.UpdatedBy,             //13
-			data.UpdatedAt,             //14
-		)
-	}
-	return rows
+	return TransactionAssetRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetTransactionAsset(t *testing.T) {
@@ -139,8 +92,8 @@ func TestGetTransactionAssetForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WithArgs(transactionID, assetID).WillReturnRows(noRows)
 	foundTransactionAsset, err := GetTransactionAsset(mock, &transactionID, &assetID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTransactionAsset", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTransactionAsset != nil {
 		t.Errorf("Expected TransactionAsset From Method GetTransactionAsset: to be empty but got this: %v", foundTransactionAsset)
@@ -227,8 +180,8 @@ func TestGetTransactionAssetByUUIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WithArgs(uuid).WillReturnRows(noRows)
 	foundTransactionAsset, err := GetTransactionAssetByUUID(mock, uuid)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTransactionAssetByUUID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTransactionAsset != nil {
 		t.Errorf("Expected TransactionAsset From Method GetTransactionAssetByUUID: to be empty but got this: %v", foundTransactionAsset)
@@ -722,15 +675,14 @@ func TestInsertTransactionAsset(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO transaction_assets").WithArgs(
 		targetData.TransactionID,         //1
 		targetData.AssetID,               //2
-		targetData.UUID,                  //3
-		targetData.Name,                  //4
-		targetData.AlternateName,         //5
-		targetData.Description,           //6
-		targetData.Quantity,              //7
-		targetData.QuantityUSD,           //8
-		targetData.MarketDataID,          //9
-		targetData.ManualExchangeRateUSD, //10
-		targetData.CreatedBy,             //11
+		targetData.Name,                  //3
+		targetData.AlternateName,         //4
+		targetData.Description,           //5
+		targetData.Quantity,              //6
+		targetData.QuantityUSD,           //7
+		targetData.MarketDataID,          //8
+		targetData.ManualExchangeRateUSD, //9
+		targetData.CreatedBy,             //10
 	).WillReturnRows(pgxmock.NewRows([]string{"transaction_id", "asset_id"}).AddRow(1, 1))
 	mock.ExpectCommit()
 	transactionID, assetID, err := InsertTransactionAsset(mock, &targetData)
@@ -778,15 +730,14 @@ func TestInsertTransactionAssetOnFailure(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO transaction_assets").WithArgs(
 		targetData.TransactionID,         //1
 		targetData.AssetID,               //2
-		targetData.UUID,                  //3
-		targetData.Name,                  //4
-		targetData.AlternateName,         //5
-		targetData.Description,           //6
-		targetData.Quantity,              //7
-		targetData.QuantityUSD,           //8
-		targetData.MarketDataID,          //9
-		targetData.ManualExchangeRateUSD, //10
-		targetData.CreatedBy,             //11
+		targetData.Name,                  //3
+		targetData.AlternateName,         //4
+		targetData.Description,           //5
+		targetData.Quantity,              //6
+		targetData.QuantityUSD,           //7
+		targetData.MarketDataID,          //8
+		targetData.ManualExchangeRateUSD, //9
+		targetData.CreatedBy,             //10
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	transactionID, assetID, err := InsertTransactionAsset(mock, &targetData)
@@ -815,15 +766,14 @@ func TestInsertTransactionAssetOnFailureOnCommit(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO transaction_assets").WithArgs(
 		targetData.TransactionID,         //1
 		targetData.AssetID,               //2
-		targetData.UUID,                  //3
-		targetData.Name,                  //4
-		targetData.AlternateName,         //5
-		targetData.Description,           //6
-		targetData.Quantity,              //7
-		targetData.QuantityUSD,           //8
-		targetData.MarketDataID,          //9
-		targetData.ManualExchangeRateUSD, //10
-		targetData.CreatedBy,             //11
+		targetData.Name,                  //3
+		targetData.AlternateName,         //4
+		targetData.Description,           //5
+		targetData.Quantity,              //6
+		targetData.QuantityUSD,           //7
+		targetData.MarketDataID,          //8
+		targetData.ManualExchangeRateUSD, //9
+		targetData.CreatedBy,             //10
 	).WillReturnRows(pgxmock.NewRows([]string{"transaction_id", "asset_id"}).AddRow(1, 1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -886,10 +836,10 @@ func TestGetTransactionAssetListByPagination(t *testing.T) {
 	mockRows := AddTransactionAssetToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "transaction_id"
 	_order := "ASC"
-	filters := []string{"asset_id = 1", "market_data_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("asset_id", "1"), filter.Equal("market_data_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundTransactionAssetList, err := GetTransactionAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTransactionAssetListByPagination", err)
@@ -912,10 +862,10 @@ func TestGetTransactionAssetListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "transaction_id"
 	_order := "ASC"
-	filters := []string{"market_data_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("market_data_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WithArgs(int64(1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundTransactionAssetList, err := GetTransactionAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionAssetListByPagination", err)
@@ -936,11 +886,11 @@ func TestGetTransactionAssetListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "transaction_id"
 	_order := "ASC"
-	filters := []string{"market_data_id = 1"}
+	filters := filter.All(filter.Equal("market_data_id", "1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM transaction_assets").WithArgs(int64(1)).WillReturnRows(differentModelRows)
 	foundTransactionAssetList, err := GetTransactionAssetListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionAssetListByPagination", err)
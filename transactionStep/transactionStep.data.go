@@ -2,219 +2,121 @@ package transactionstep
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
-	"time"
 
-	"github.com/gofrs/uuid"
-	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
+	"github.com/kfukue/lyle-labs-libraries/v2/repository"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 )
 
+// DefaultTimeout is the deadline applied by the functions in this package that
+// do not take a context.Context.
+var DefaultTimeout = utils.DefaultQueryTimeout
+
+// TransactionStepRepository implements the standard queries on the
+// transaction_steps table, keyed on the transaction and the step.
+var TransactionStepRepository = repository.New[TransactionStep]("transaction_steps",
+	repository.Key("transaction_id", "step_id"),
+)
+
 func GetTransactionStep(dbConnPgx utils.PgxIface, transactionID, stepID *int) (*TransactionStep, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT
-		transaction_id,  
-		step_id,
-		uuid, 
-		name,
-		alternate_name,
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at 
-	FROM transaction_steps 
-	WHERE transaction_id = $1
-	AND step_id = $2
-	`, *transactionID, *stepID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	transactionStep, err := pgx.CollectOneRow(row, pgx.RowToStructByName[TransactionStep])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &transactionStep, nil
+	return GetTransactionStepCtx(ctx, dbConnPgx, transactionID, stepID)
+}
+
+func GetTransactionStepCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionID, stepID *int) (*TransactionStep, error) {
+	return TransactionStepRepository.Get(ctx, dbConnPgx, repository.CompositeKey{*transactionID, *stepID})
 }
 
 func GetTransactionStepByUUID(dbConnPgx utils.PgxIface, transactionStepUUID string) (*TransactionStep, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	row, err := dbConnPgx.Query(ctx, `SELECT
-	transaction_id,  
-	step_id,
-	uuid, 
-	name,
-	alternate_name,
-	description,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at 
-	FROM transaction_steps 
-	WHERE text(uuid) = $1
-	`, transactionStepUUID)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
-	transactionStep, err := pgx.CollectOneRow(row, pgx.RowToStructByName[TransactionStep])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &transactionStep, nil
+	return GetTransactionStepByUUIDCtx(ctx, dbConnPgx, transactionStepUUID)
+}
+
+func GetTransactionStepByUUIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionStepUUID string) (*TransactionStep, error) {
+	return TransactionStepRepository.SelectOne(ctx, dbConnPgx, `WHERE text(uuid) = $1`, transactionStepUUID)
 }
 
 func GetTransactionStepsByUUIDs(dbConnPgx utils.PgxIface, UUIDList []string) ([]TransactionStep, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-	transaction_id,  
-	step_id,
-	uuid, 
-	name,
-	alternate_name,
-	description,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at 
-	FROM transaction_steps
-	WHERE text(uuid) = ANY($1)
-	`, pq.Array(UUIDList))
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetTransactionStepsByUUIDsCtx(ctx, dbConnPgx, UUIDList)
+}
 
-	transactionStepList, err := pgx.CollectRows(results, pgx.RowToStructByName[TransactionStep])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return transactionStepList, nil
+func GetTransactionStepsByUUIDsCtx(ctx context.Context, dbConnPgx utils.PgxIface, UUIDList []string) ([]TransactionStep, error) {
+	return TransactionStepRepository.Select(ctx, dbConnPgx, `WHERE text(uuid) = ANY($1)`, pq.Array(UUIDList))
 }
 
 func RemoveTransactionStep(dbConnPgx utils.PgxIface, transactionID, stepID *int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in RemoveTransactionStep DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `DELETE FROM transaction_steps WHERE 
-		transaction_id = $1 AND step_id =$2`
+	return RemoveTransactionStepCtx(ctx, dbConnPgx, transactionID, stepID)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql, *transactionID, *stepID); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func RemoveTransactionStepCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionID, stepID *int) error {
+	return TransactionStepRepository.Remove(ctx, dbConnPgx, repository.CompositeKey{*transactionID, *stepID})
 }
 
 func RemoveTransactionStepByUUID(dbConnPgx utils.PgxIface, transactionStepUUID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return RemoveTransactionStepByUUIDCtx(ctx, dbConnPgx, transactionStepUUID)
+}
+
+func RemoveTransactionStepByUUIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionStepUUID string) error {
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in RemoveTransactionStepByUUID DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "RemoveTransactionStepByUUID: begin", err, logging.Entity("transaction_steps"), logging.ID(transactionStepUUID))
+		return dberrors.Wrap(err)
 	}
 	sql := `DELETE FROM transaction_steps WHERE text(uuid) = $1`
 
-	if _, err := dbConnPgx.Exec(ctx, sql, transactionStepUUID); err != nil {
+	if _, err := tx.Exec(ctx, sql, transactionStepUUID); err != nil {
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 
 func GetTransactionStepList(dbConnPgx utils.PgxIface) ([]TransactionStep, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	results, err := dbConnPgx.Query(ctx, `SELECT 
-	transaction_id,  
-	step_id,
-	uuid, 
-	name,
-	alternate_name,
-	description,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at 
-	FROM transaction_steps`)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetTransactionStepListCtx(ctx, dbConnPgx)
+}
 
-	transactionSteps, err := pgx.CollectRows(results, pgx.RowToStructByName[TransactionStep])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return transactionSteps, nil
+func GetTransactionStepListCtx(ctx context.Context, dbConnPgx utils.PgxIface) ([]TransactionStep, error) {
+	return TransactionStepRepository.Select(ctx, dbConnPgx, "")
 }
 
 func UpdateTransactionStep(dbConnPgx utils.PgxIface, transactionStep *TransactionStep) error {
-	// if the transactionStep id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	if (transactionStep.TransactionID == nil || *transactionStep.TransactionID == 0) || (transactionStep.StepID == nil || *transactionStep.StepID == 0) {
-		return errors.New("transactionStep has invalid ID")
-	}
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in UpdateTransactionStep DbConn.Begin   %s", err.Error())
-		return err
-	}
-	sql := `UPDATE transaction_steps SET 
-		name=$1,
-		alternate_name=$2,
-		description=$3,
-		updated_by=$4, 
-		updated_at=current_timestamp at time zone 'UTC'
-		WHERE transaction_id=$5 AND step_id=$6`
+	return UpdateTransactionStepCtx(ctx, dbConnPgx, transactionStep)
+}
 
-	if _, err := dbConnPgx.Exec(ctx, sql,
-		transactionStep.Name,          //1
-		transactionStep.AlternateName, //2
-		transactionStep.Description,   //3
-		transactionStep.UpdatedBy,     //4
-		transactionStep.TransactionID, //5
-		transactionStep.StepID,        //6
-	); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
-	return tx.Commit(ctx)
+func UpdateTransactionStepCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionStep *TransactionStep) error {
+	return TransactionStepRepository.Update(ctx, dbConnPgx, transactionStep)
 }
 
 func UpdateTransactionStepByUUID(dbConnPgx utils.PgxIface, transactionStep *TransactionStep) error {
-	// if the transactionStep id is set, update, otherwise add
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return UpdateTransactionStepByUUIDCtx(ctx, dbConnPgx, transactionStep)
+}
+
+func UpdateTransactionStepByUUIDCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionStep *TransactionStep) error {
+	// if the transactionStep id is set, update, otherwise add
 	if (transactionStep.TransactionID == nil || *transactionStep.TransactionID == 0) || (transactionStep.StepID == nil || *transactionStep.StepID == 0) {
-		return errors.New("transactionStep has invalid ID")
+		return dberrors.InvalidInput("transactionStep has invalid ID")
 	}
 	tx, err := dbConnPgx.Begin(ctx)
 	if err != nil {
-		log.Printf("Error in UpdateTransactionStepByUUID DbConn.Begin   %s", err.Error())
-		return err
+		logging.ReturnedError(ctx, "UpdateTransactionStepByUUID: begin", err, logging.Entity("transaction_steps"), logging.ID(transactionStep.UUID))
+		return dberrors.Wrap(err)
 	}
 	sql := `UPDATE transaction_steps SET 
 		name=$1,
@@ -225,7 +127,7 @@ func UpdateTransactionStepByUUID(dbConnPgx utils.PgxIface, transactionStep *Tran
 		WHERE text(uuid) = $5
 		`
 
-	if _, err := dbConnPgx.Exec(ctx, sql,
+	if _, err := tx.Exec(ctx, sql,
 		transactionStep.Name,          //1
 		transactionStep.AlternateName, //2
 		transactionStep.Description,   //3
@@ -233,191 +135,53 @@ func UpdateTransactionStepByUUID(dbConnPgx utils.PgxIface, transactionStep *Tran
 		transactionStep.UUID,          //5
 	); err != nil {
 		tx.Rollback(ctx)
-		return err
+		return dberrors.Wrap(err)
 	}
-	return tx.Commit(ctx)
+	return dberrors.Wrap(tx.Commit(ctx))
 }
 
 func InsertTransactionStep(dbConnPgx utils.PgxIface, transactionStep *TransactionStep) (int, int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	tx, err := dbConnPgx.Begin(ctx)
-	if err != nil {
-		log.Printf("Error in InsertTransactionStep DbConn.Begin   %s", err.Error())
-		return -1, -1, err
-	}
-	var MarketDataID int
-	var StepID int
-	transactionStepUUID, err := uuid.NewV4()
-	if err != nil {
-		log.Fatalf("failed to generate UUID: %v", err)
-	}
-	if transactionStep.UUID == "" {
-		transactionStep.UUID = transactionStepUUID.String()
-	}
-	err = dbConnPgx.QueryRow(ctx, `INSERT INTO transaction_steps  
-	(
-		transaction_id,  
-		step_id,
-		uuid, 
-		name,
-		alternate_name,
-		description,
-		created_by, 
-		created_at, 
-		updated_by, 
-		updated_at 
-		) VALUES (
-			$1,
-			$2,
-			$3,
-			$4,
-			$5,
-			$6,
-			$7,
-			current_timestamp at time zone 'UTC',
-			$7,
-			current_timestamp at time zone 'UTC'
-		)
-		RETURNING transaction_id, step_id`,
-		transactionStep.TransactionID, //1
-		transactionStep.StepID,        //2
-		transactionStep.UUID,          //3
-		transactionStep.Name,          //4
-		transactionStep.AlternateName, //5
-		transactionStep.Description,   //6
-		transactionStep.CreatedBy,     //7
-	).Scan(&MarketDataID, &StepID)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
-		return -1, -1, err
-	}
-	err = tx.Commit(ctx)
-	if err != nil {
-		tx.Rollback(ctx)
-		log.Println(err.Error())
+	return InsertTransactionStepCtx(ctx, dbConnPgx, transactionStep)
+}
+
+func InsertTransactionStepCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionStep *TransactionStep) (int, int, error) {
+	var transactionID, stepID int
+	if err := TransactionStepRepository.InsertReturningKey(ctx, dbConnPgx, transactionStep, &transactionID, &stepID); err != nil {
 		return -1, -1, err
 	}
-	return int(MarketDataID), int(StepID), nil
+	return transactionID, stepID, nil
 }
 
 func InsertTransactionSteps(dbConnPgx utils.PgxIface, transactionSteps []TransactionStep) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	rows := [][]interface{}{}
-	for i := range transactionSteps {
-		transactionStep := transactionSteps[i]
-		uuidString := &pgtype.UUID{}
-		uuidString.Set(transactionStep.UUID)
-		row := []interface{}{
-			transactionStep.TransactionID, //1
-			transactionStep.StepID,        //2
-			uuidString,                    //3
-			transactionStep.Name,          //4
-			transactionStep.AlternateName, //5
-			transactionStep.Description,   //6
-			transactionStep.CreatedBy,     //7
-			&transactionStep.CreatedAt,    //8
-			transactionStep.CreatedBy,     //9
-			&now,                          //10
-		}
-		rows = append(rows, row)
-	}
-	// Given db is a *sql.DB
-
-	copyCount, err := dbConnPgx.CopyFrom(
-		ctx,
-		pgx.Identifier{"transaction_steps"},
-		[]string{
-			"transaction_id", //1
-			"step_id",        //2
-			"uuid",           //3
-			"name",           //4
-			"alternate_name", //5
-			"description",    //6
-			"created_by",     //7
-			"created_at",     //8
-			"updated_by",     //9
-			"updated_at",     //10
-		},
-		pgx.CopyFromRows(rows),
-	)
-	log.Println(fmt.Printf("InsertTransactionSteps: copy count: %d", copyCount))
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
+	return InsertTransactionStepsCtx(ctx, dbConnPgx, transactionSteps)
+}
 
-	return nil
+func InsertTransactionStepsCtx(ctx context.Context, dbConnPgx utils.PgxIface, transactionSteps []TransactionStep) error {
+	_, err := TransactionStepRepository.InsertMany(ctx, dbConnPgx, transactionSteps)
+	return dberrors.Wrap(err)
 }
 
 // for refinedev
-func GetTransactionStepListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters []string) ([]TransactionStep, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+func GetTransactionStepListByPagination(dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]TransactionStep, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
-	sql := `
-	SELECT 
-	transaction_id,  
-	step_id,
-	uuid, 
-	name,
-	alternate_name,
-	description,
-	created_by, 
-	created_at, 
-	updated_by, 
-	updated_at 
-	FROM transaction_steps 
-	`
-	if len(_filters) > 0 {
-		sql += "WHERE "
-		for i, filter := range _filters {
-			sql += filter
-			if i < len(_filters)-1 {
-				sql += " OR "
-			}
-		}
-	}
-	if _order != "" && _sort != "" {
-		sql += fmt.Sprintf(" ORDER BY %s %s ", _sort, _order)
-	}
-	if (_start != nil && *_start > 0) && (_end != nil && *_end > 0) {
-		pageSize := *_end - *_start
-		sql += fmt.Sprintf(" OFFSET %d LIMIT %d ", *_start, pageSize)
-	}
-
-	results, err := dbConnPgx.Query(ctx, sql)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
-	}
+	return GetTransactionStepListByPaginationCtx(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
+}
 
-	transactionSteps, err := pgx.CollectRows(results, pgx.RowToStructByName[TransactionStep])
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return transactionSteps, nil
+func GetTransactionStepListByPaginationCtx(ctx context.Context, dbConnPgx utils.PgxIface, _start, _end *int, _order, _sort string, _filters *filter.Group) ([]TransactionStep, error) {
+	return TransactionStepRepository.Pagination(ctx, dbConnPgx, _start, _end, _order, _sort, _filters)
 }
 
 func GetTotalTransactionStepsCount(dbConnPgx utils.PgxIface) (*int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
+	return GetTotalTransactionStepsCountCtx(ctx, dbConnPgx)
+}
 
-	row := dbConnPgx.QueryRow(ctx, `SELECT 
-	COUNT(*)
-	FROM transaction_steps
-	`)
-	totalCount := 0
-	err := row.Scan(
-		&totalCount,
-	)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	return &totalCount, nil
+func GetTotalTransactionStepsCountCtx(ctx context.Context, dbConnPgx utils.PgxIface) (*int, error) {
+	return TransactionStepRepository.Count(ctx, dbConnPgx)
 }
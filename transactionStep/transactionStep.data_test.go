@@ -7,35 +7,15 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jackc/pgx/v5"
+	"github.com/kfukue/lyle-labs-libraries/v2/dberrors"
+	"github.com/kfukue/lyle-labs-libraries/v2/filter"
 	"github.com/kfukue/lyle-labs-libraries/v2/utils"
 	"github.com/lib/pq"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
-var DBColumns = []string{
-	"transaction_id", //1
-	"step_id",        //2
-	"uuid",           //3
-	"name",           //4
-	"alternate_name", //5
-	"description",    //6
-	"created_by",     //7
-	"created_at",     //8
-	"updated_by",     //9
-	"updated_at",     //10
-}
-var DBColumnsInsertTransactionSteps = []string{
-	"transaction_id", //1
-	"step_id",        //2
-	"uuid",           //3
-	"name",           //4
-	"alternate_name", //5
-	"description",    //6
-	"created_by",     //7
-	"created_at",     //8
-	"updated_by",     //9
-	"updated_at",     //10
-}
+var DBColumns = TransactionStepRepository.Columns()
+var DBColumnsInsertTransactionSteps = TransactionStepRepository.InsertColumns()
 
 var TestData1 = TransactionStep{
 	TransactionID: utils.Ptr[int](1),                                                                            //1
@@ -66,22 +46,7 @@ var TestData2 = TransactionStep{
 var TestAllData = []TransactionStep{TestData1, TestData2}
 
 func AddTransactionStepToMockRows(mock pgxmock.PgxPoolIface, dataList []TransactionStep) *pgxmock.Rows {
-	rows := mock.NewRows(DBColumns)
-	for _, data := range dataList {
-		rows.AddRow(
-			data.TransactionID, //1
-			data.StepID,        //2
-			data.UUID,          //3
-			data.Name,          //4
-			data.AlternateName, //5
-			data.Description,   //6
-			data.CreatedBy,     //7
-			data.CreatedAt,     //8
-			data.UpdatedBy,     //9
-			data.UpdatedAt,     //10
-		)
-	}
-	return rows
+	return TransactionStepRepository.AddToMockRows(mock, dataList)
 }
 
 func TestGetTransactionStep(t *testing.T) {
@@ -119,8 +84,8 @@ func TestGetTransactionStepForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WithArgs(transactionID, stepID).WillReturnRows(noRows)
 	foundTransactionStep, err := GetTransactionStep(mock, &transactionID, &stepID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTransactionStep", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTransactionStep != nil {
 		t.Errorf("Expected TransactionStep From Method GetTransactionStep: to be empty but got this: %v", foundTransactionStep)
@@ -207,8 +172,8 @@ func TestGetTransactionStepByUUIDForErrNoRows(t *testing.T) {
 	noRows := pgxmock.NewRows(DBColumns)
 	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WithArgs(transactionStepUUID).WillReturnRows(noRows)
 	foundTransactionStep, err := GetTransactionStepByUUID(mock, transactionStepUUID)
-	if err != nil {
-		t.Fatalf("an error '%s' in GetTransactionStepByUUID", err)
+	if !errors.Is(err, dberrors.ErrNotFound) {
+		t.Fatalf("expected error '%s', got '%v'", dberrors.ErrNotFound, err)
 	}
 	if foundTransactionStep != nil {
 		t.Errorf("Expected TransactionStep From Method GetTransactionStepByUUID: to be empty but got this: %v", foundTransactionStep)
@@ -686,11 +651,10 @@ func TestInsertTransactionStep(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO transaction_steps").WithArgs(
 		targetData.TransactionID, //1
 		targetData.StepID,        //2
-		targetData.UUID,          //3
-		targetData.Name,          //4
-		targetData.AlternateName, //5
-		targetData.Description,   //6
-		targetData.CreatedBy,     //7
+		targetData.Name,          //3
+		targetData.AlternateName, //4
+		targetData.Description,   //5
+		targetData.CreatedBy,     //6
 	).WillReturnRows(pgxmock.NewRows([]string{"transaction_id", "step_id"}).AddRow(1, 1))
 	mock.ExpectCommit()
 	transactionID, stepID, err := InsertTransactionStep(mock, &targetData)
@@ -738,11 +702,10 @@ func TestInsertTransactionStepOnFailure(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO transaction_steps").WithArgs(
 		targetData.TransactionID, //1
 		targetData.StepID,        //2
-		targetData.UUID,          //3
-		targetData.Name,          //4
-		targetData.AlternateName, //5
-		targetData.Description,   //6
-		targetData.CreatedBy,     //7
+		targetData.Name,          //3
+		targetData.AlternateName, //4
+		targetData.Description,   //5
+		targetData.CreatedBy,     //6
 	).WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
 	transactionID, stepID, err := InsertTransactionStep(mock, &targetData)
@@ -771,11 +734,10 @@ func TestInsertTransactionStepOnFailureOnCommit(t *testing.T) {
 	mock.ExpectQuery("^INSERT INTO transaction_steps").WithArgs(
 		targetData.TransactionID, //1
 		targetData.StepID,        //2
-		targetData.UUID,          //3
-		targetData.Name,          //4
-		targetData.AlternateName, //5
-		targetData.Description,   //6
-		targetData.CreatedBy,     //7
+		targetData.Name,          //3
+		targetData.AlternateName, //4
+		targetData.Description,   //5
+		targetData.CreatedBy,     //6
 	).WillReturnRows(pgxmock.NewRows([]string{"transaction_id", "step_id"}).AddRow(-1, -1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("Random SQL Error"))
 	mock.ExpectRollback()
@@ -838,10 +800,10 @@ func TestGetTransactionStepListByPagination(t *testing.T) {
 	mockRows := AddTransactionStepToMockRows(mock, dataList)
 	_start := 1
 	_end := 10
-	_sort := "id"
+	_sort := "transaction_id"
 	_order := "ASC"
-	filters := []string{"step_id = 1", "transaction_id = 1"}
-	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WillReturnRows(mockRows)
+	filters := filter.All(filter.Equal("step_id", "1"), filter.Equal("transaction_id", "1"))
+	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WithArgs(int64(1), int64(1), _start, _end-_start).WillReturnRows(mockRows)
 	foundTransactionStepList, err := GetTransactionStepListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err != nil {
 		t.Fatalf("an error '%s' in GetTransactionStepListByPagination", err)
@@ -864,10 +826,10 @@ func TestGetTransactionStepListByPaginationForErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "transaction_id"
 	_order := "ASC"
-	filters := []string{"transaction_id = -1"}
-	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
+	filters := filter.All(filter.Equal("transaction_id", "-1"))
+	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WithArgs(int64(-1)).WillReturnError(pgx.ScanArgError{Err: errors.New("Random SQL Error")})
 	foundTransactionStepList, err := GetTransactionStepListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionStepListByPagination", err)
@@ -888,11 +850,11 @@ func TestGetTransactionStepListByPaginationForCollectRowsErr(t *testing.T) {
 	defer mock.Close()
 	_start := 0
 	_end := 10
-	_sort := "id"
+	_sort := "transaction_id"
 	_order := "ASC"
-	filters := []string{"transaction_id = -1"}
+	filters := filter.All(filter.Equal("transaction_id", "-1"))
 	differentModelRows := mock.NewRows([]string{"diff_model_id"}).AddRow(1)
-	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WillReturnRows(differentModelRows)
+	mock.ExpectQuery("^SELECT (.+) FROM transaction_steps").WithArgs(int64(-1)).WillReturnRows(differentModelRows)
 	foundTransactionStepList, err := GetTransactionStepListByPagination(mock, &_start, &_end, _order, _sort, filters)
 	if err == nil {
 		t.Fatalf("expected an error '%s' in GetTransactionStepListByPagination", err)
@@ -1,10 +1,10 @@
 package utils
 
 import (
-	"log"
-	"os"
+	"log/slog"
 
-	"github.com/joho/godotenv"
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
 )
 
 const (
@@ -13,7 +13,8 @@ const (
 	COINGECKO_SOURCE_ID = 3
 	USD_ID              = 34
 	ETH_ID              = 35
-	// structured value id
+	// structured value ids of the reference database. Other databases may
+	// differ: resolve values with structuredvalue.ID or a structuredvalue.Registry.
 	ASSET_TYPE_CRYPTO_STRUCTURED_VALUE_ID                    = 1
 	LIVE_INTERVAL_STRUCTURED_VALUE_ID                        = 58
 	DAILY_STRUCTURED_VALUE_ID                                = 5
@@ -55,7 +56,6 @@ const (
 	VIRTUAL_EXCHANGE_STRUCTURED_VALUE_ID                     = 104
 	VITRUAL_PROTOTYPE_EXCHANGE_STRUCTURED_VALUE_ID           = 105
 	VIRTUAL_ASSET_STRUCTURED_VALUE_ID                        = 106
-	DAILY_
 	// structured value type ids
 	JOB_STATUS_STRUCTURED_VALUE_TYPE_ID          = 14
 	JOB_CATEGORY_STRUCTURED_VALUE_TYPE_ID        = 15
@@ -71,38 +71,49 @@ const (
 	MINER_IMPORT_TYPE_STRUCTURED_VALUE_TYPE_ID   = 27
 )
 
+// GetEnv returns the active config profile, selected by APP_ENV.
 func GetEnv() string {
-	return os.Getenv("APP_ENV")
+	values, err := config.Default()
+	if err != nil {
+		return config.Get(config.ProfileKey)
+	}
+	return values.Profile()
 }
 
+// LookupEnv returns k from the environment, .env or the config file.
+//
+// Deprecated: use config.Get, or config.Bind to check required keys.
 func LookupEnv(k string) string {
-	var envValue string
-	if GetEnv() == "production" {
-		envValue = MustGetenv(k)
-	} else {
-		envValue = GoDotEnvVariable(k)
-	}
-	return envValue
+	return config.Get(k)
 }
 
-func MustGetenv(k string) string {
-	v := os.Getenv(k)
+// RequiredEnv returns k from the environment, .env or the config file, or a
+// *config.MissingKeysError when it is unset or empty.
+func RequiredEnv(k string) (string, error) {
+	v := config.Get(k)
 	if v == "" {
-		log.Fatalf("Warning: %s environment variable not set.", k)
+		return "", &config.MissingKeysError{Keys: []string{k}}
 	}
-	return v
+	return v, nil
 }
 
-// use godot package to load/read the .env file and
-// return the value of the key
-func GoDotEnvVariable(key string) string {
-
-	// load .env file
-	err := godotenv.Load(".env")
-
+// MustGetenv returns k from the environment, .env or the config file. It used
+// to exit the process when k was unset; it now logs a warning and returns "".
+//
+// Deprecated: use RequiredEnv, or bind a struct with a required key using
+// config.Bind.
+func MustGetenv(k string) string {
+	v, err := RequiredEnv(k)
 	if err != nil {
-		log.Fatalf("Error loading .env file")
+		logging.Logger().Warn("MustGetenv: config key not set", slog.String("key", k))
 	}
+	return v
+}
 
-	return os.Getenv(key)
+// GoDotEnvVariable returns key from the environment or .env, which is read
+// once and may be missing.
+//
+// Deprecated: use config.Get.
+func GoDotEnvVariable(key string) string {
+	return config.Get(key)
 }
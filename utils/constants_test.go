@@ -1,15 +1,44 @@
 package utils
 
 import (
-	"os"
+	"errors"
 	"testing"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/config"
 )
 
 func TestGetEnv(t *testing.T) {
 	expected := "production"
-	os.Setenv("APP_ENV", expected)
+	t.Setenv("APP_ENV", expected)
+	config.Reset()
+	defer config.Reset()
 	result := GetEnv()
 	if result != expected {
 		t.Errorf("GetEnv() = %s; want %s", result, expected)
 	}
 }
+
+func TestRequiredEnv(t *testing.T) {
+	t.Setenv("LYLE_TEST_KEY", "value")
+	config.Reset()
+	defer config.Reset()
+	if v, err := RequiredEnv("LYLE_TEST_KEY"); err != nil || v != "value" {
+		t.Errorf("RequiredEnv() = %q, %v; want value", v, err)
+	}
+	var missing *config.MissingKeysError
+	if _, err := RequiredEnv("LYLE_TEST_MISSING_KEY"); !errors.As(err, &missing) {
+		t.Errorf("expected a *config.MissingKeysError, got %v", err)
+	}
+}
+
+func TestMustGetenv(t *testing.T) {
+	t.Setenv("LYLE_TEST_KEY", "value")
+	config.Reset()
+	defer config.Reset()
+	if v := MustGetenv("LYLE_TEST_KEY"); v != "value" {
+		t.Errorf("MustGetenv() = %q; want value", v)
+	}
+	if v := MustGetenv("LYLE_TEST_MISSING_KEY"); v != "" {
+		t.Errorf("MustGetenv() = %q; want empty", v)
+	}
+}
@@ -1,9 +1,11 @@
 package utils
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
 	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
 )
 
 const (
@@ -38,7 +40,7 @@ func RangeDateMonthly(start, end time.Time) func() time.Time {
 	initialStartDate := start
 	y, m, d = end.Date()
 	end = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
-	log.Println(fmt.Sprintf("RangeDateMonthly, start: %s, end : %s", start.Format(LayoutPostgres), end.Format(LayoutPostgres)))
+	logging.Logger().Debug("RangeDateMonthly", slog.String("start", start.Format(LayoutPostgres)), slog.String("end", end.Format(LayoutPostgres)))
 	return func() time.Time {
 		// log.Println(fmt.Sprintf("start initial: %s,  start: %s, end initial : %s,  end : %s", initialStartDate.Format(LayoutPostgres), start.Format(LayoutPostgres), end.Format(LayoutPostgres), initialEndDate.Format(LayoutPostgres)))
 		if start.After(end) {
@@ -61,7 +63,7 @@ func RangeDateOffsetByDate(start, end time.Time, offSetDays *int) func() time.Ti
 	initialStartDate := start
 	y, m, d = end.Date()
 	end = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
-	log.Println(fmt.Sprintf("RangeDateMonthly, start: %s, end : %s", start.Format(LayoutPostgres), end.Format(LayoutPostgres)))
+	logging.Logger().Debug("RangeDateOffsetByDate", slog.String("start", start.Format(LayoutPostgres)), slog.String("end", end.Format(LayoutPostgres)))
 	return func() time.Time {
 		// log.Println(fmt.Sprintf("start initial: %s,  start: %s, end initial : %s,  end : %s", initialStartDate.Format(LayoutPostgres), start.Format(LayoutPostgres), end.Format(LayoutPostgres), initialEndDate.Format(LayoutPostgres)))
 		if start.After(end) {
@@ -108,7 +110,7 @@ func EndDate(t time.Time) time.Time {
 func ParseStringToDate(s string, layout string) (time.Time, error) {
 	result, err := time.Parse(layout, s)
 	if err != nil {
-		log.Println(err.Error())
+		logging.ReturnedError(context.Background(), "ParseStringToDate", err)
 		return time.Time{}, err
 	}
 	return result, err
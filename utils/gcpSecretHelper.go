@@ -11,6 +11,9 @@ import (
 // accessSecretVersion accesses the payload for the given secret version if one
 // exists. The version can be a version number as a string (e.g. "5") or an
 // alias (e.g. "latest").
+//
+// Deprecated: use secrets.Get, which can also read secrets from the
+// environment, files or a local encrypted file and caches them.
 func AccessSecretVersion(name string) (string, error) {
 	// name := "projects/my-project/secrets/my-secret/versions/5"
 	// name := "projects/my-project/secrets/my-secret/versions/latest"
@@ -1,20 +1,21 @@
 package utils
 
 import (
-	"fmt"
 	"time"
+
+	"github.com/kfukue/lyle-labs-libraries/v2/logging"
 )
 
+// SleepLog sleeps for totalDuration, logging the time remaining every second.
 func SleepLog(msg string, totalDuration time.Duration) {
 	interval := time.Second * 1
 	currentTime := time.Now()
 	endTime := currentTime.Add(totalDuration)
 	for currentTime.Before(endTime) {
 		diff := endTime.Sub(currentTime)
-		second := int(diff.Seconds())
-		fmt.Printf("msg : %s, %d secs remaining...\n", msg, second)
+		logging.Logger().Info(msg, logging.Duration(diff.Truncate(time.Second)))
 		time.Sleep(interval)
 		currentTime = currentTime.Add(interval)
 	}
-	fmt.Printf("msg : %s, done\n", msg)
+	logging.Logger().Info(msg + ": done")
 }
@@ -1,7 +1,15 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
+// GetFiltersFromQueryStrings builds raw SQL fragments from the query values.
+//
+// Deprecated: the fragments are not escaped and the Get*ListByPagination
+// functions no longer accept them. Use filter.Parse, which produces a
+// *filter.Group that is validated and bound as $n parameters.
 func GetFiltersFromQueryStrings(query map[string][]string, filterKeys []string) []string {
 	filters := make([]string, 0)
 	for _, value := range filterKeys {
@@ -18,3 +26,7 @@ func GetFiltersFromQueryStrings(query map[string][]string, filterKeys []string)
 	}
 	return filters
 }
+
+// DefaultQueryTimeout is the deadline the data packages apply when a caller uses
+// one of the functions that does not accept a context.Context.
+const DefaultQueryTimeout = 600 * time.Second
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"context"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// txConn adapts a pgx.Tx to PgxIface so the data functions can run inside a
+// transaction. Begin on it starts a savepoint, which lets functions that open
+// their own transaction be composed into a larger one.
+type txConn struct {
+	pgx.Tx
+}
+
+// TxConn returns tx as a PgxIface.
+func TxConn(tx pgx.Tx) PgxIface {
+	if conn, ok := tx.(PgxIface); ok {
+		return conn
+	}
+	return txConn{tx}
+}
+
+func (t txConn) Ping(ctx context.Context) error {
+	return t.Conn().Ping(ctx)
+}
+
+// Close is a no-op: the transaction is ended by WithTx, and the connection
+// belongs to the pool it was acquired from.
+func (t txConn) Close() {}
+
+// WithTx runs fn in a transaction started on dbConnPgx. The transaction is
+// committed when fn returns nil and rolled back when it returns an error or
+// panics. When dbConnPgx is itself a transaction a savepoint is used, so
+// calls nest.
+func WithTx(ctx context.Context, dbConnPgx PgxIface, fn func(tx PgxIface) error) error {
+	tx, err := dbConnPgx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+	if err := fn(TxConn(tx)); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
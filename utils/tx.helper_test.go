@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestWithTx(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("^DELETE FROM chains").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+	err = WithTx(context.Background(), mock, func(tx PgxIface) error {
+		_, err := tx.Exec(context.Background(), "DELETE FROM chains WHERE id = $1", 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("an error '%s' in WithTx", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTxOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	failure := errors.New("Random SQL Error")
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err = WithTx(context.Background(), mock, func(tx PgxIface) error {
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected error '%s', got '%v'", failure, err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTxOnFailureAtBegin(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin().WillReturnError(errors.New("Failure at begin"))
+	called := false
+	err = WithTx(context.Background(), mock, func(tx PgxIface) error {
+		called = true
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("was expecting an error, but there was none")
+	}
+	if called {
+		t.Errorf("fn should not run when Begin fails")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There awere unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTxOnPanic(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub databse connection", err)
+	}
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected the panic to be re-raised")
+		}
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("There awere unfulfilled expectations: %s", err)
+		}
+	}()
+	WithTx(context.Background(), mock, func(tx PgxIface) error {
+		panic("boom")
+	})
+}